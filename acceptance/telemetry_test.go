@@ -1,6 +1,7 @@
 package acceptance
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -34,6 +35,15 @@ var _ = Describe("hyperkit starts and telemetry", func() {
 		session.Kill()
 	})
 
+	readConsent := func() map[string]interface{} {
+		contents, err := ioutil.ReadFile(filepath.Join(cfdevHome, "analytics", "consent.json"))
+		Expect(err).ToNot(HaveOccurred())
+
+		var consent map[string]interface{}
+		Expect(json.Unmarshal(contents, &consent)).To(Succeed())
+		return consent
+	}
+
 	XIt("optout", func() {
 		cmd := exec.Command(GetCfPluginPath(), "dev", "start")
 		inWriter, _ := cmd.StdinPipe()
@@ -45,7 +55,7 @@ var _ = Describe("hyperkit starts and telemetry", func() {
 		fmt.Fprintln(inWriter, "no")
 
 		Eventually(func() ([]byte, error) {
-			return ioutil.ReadFile(filepath.Join(cfdevHome, "analytics", "analytics.txt"))
+			return ioutil.ReadFile(filepath.Join(cfdevHome, "analytics", "consent.json"))
 		}).Should(MatchJSON(`{"enabled":false, "props":{"type":"cf"}}`))
 
 	})
@@ -61,7 +71,7 @@ var _ = Describe("hyperkit starts and telemetry", func() {
 		fmt.Fprintln(inWriter, "yes")
 
 		Eventually(func() ([]byte, error) {
-			return ioutil.ReadFile(filepath.Join(cfdevHome, "analytics", "analytics.txt"))
+			return ioutil.ReadFile(filepath.Join(cfdevHome, "analytics", "consent.json"))
 		}).Should(MatchJSON(`{"enabled":true, "props":{"type":"cf"}}`))
 	})
 
@@ -80,7 +90,7 @@ var _ = Describe("hyperkit starts and telemetry", func() {
 		//TODO wait 'till after deps.iso download to have test value
 		Consistently(session, 2*time.Second).ShouldNot(gbytes.Say("Are you ok with CF Dev periodically capturing anonymized telemetry"))
 
-		Expect(ioutil.ReadFile(filepath.Join(cfdevHome, "analytics", "analytics.txt"))).Should(MatchJSON([]byte(`{"enabled":true, "props":{"type":"cf"}}`)))
+		Expect(ioutil.ReadFile(filepath.Join(cfdevHome, "analytics", "consent.json"))).Should(MatchJSON([]byte(`{"enabled":true, "props":{"type":"cf"}}`)))
 	})
 
 	It("allows noninteractive telemetry --off command", func() {
@@ -90,7 +100,11 @@ var _ = Describe("hyperkit starts and telemetry", func() {
 
 		Consistently(session, 2*time.Second).ShouldNot(gbytes.Say("Are you ok with CF Dev periodically capturing anonymized telemetry"))
 
-		Expect(ioutil.ReadFile(filepath.Join(cfdevHome, "analytics", "analytics.txt"))).Should(MatchJSON([]byte(`{"cfAnalyticsEnabled": false,"customAnalyticsEnabled": false,"props":{}}`)))
+		consent := readConsent()
+		Expect(consent).To(HaveKeyWithValue("cfAnalyticsEnabled", false))
+		Expect(consent).To(HaveKeyWithValue("customAnalyticsEnabled", false))
+		Expect(consent).To(HaveKeyWithValue("props", map[string]interface{}{}))
+		Expect(consent).To(HaveKey("decidedAt"))
 	})
 
 	It("allows noninteractive telemetry --on command", func() {
@@ -100,6 +114,10 @@ var _ = Describe("hyperkit starts and telemetry", func() {
 
 		Consistently(session, 2*time.Second).ShouldNot(gbytes.Say("Are you ok with CF Dev periodically capturing anonymized telemetry"))
 
-		Expect(ioutil.ReadFile(filepath.Join(cfdevHome, "analytics", "analytics.txt"))).Should(MatchJSON([]byte(`{"cfAnalyticsEnabled": true,"customAnalyticsEnabled": false,"props":{}}`)))
+		consent := readConsent()
+		Expect(consent).To(HaveKeyWithValue("cfAnalyticsEnabled", true))
+		Expect(consent).To(HaveKeyWithValue("customAnalyticsEnabled", false))
+		Expect(consent).To(HaveKeyWithValue("props", map[string]interface{}{}))
+		Expect(consent).To(HaveKey("decidedAt"))
 	})
 })
@@ -1,11 +1,13 @@
 package cloud_controller
 
 import (
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	"context"
 	"encoding/json"
 	"fmt"
 	"gopkg.in/segmentio/analytics-go.v3"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
 	"runtime"
@@ -15,18 +17,32 @@ import (
 
 const ccTimeStampFormat = "2006-01-02T15:04:05Z"
 
+// DefaultTimeout bounds each request to the Cloud Controller, so that one
+// that hangs, e.g. while the vm shuts down, does not hold up a poll.
+const DefaultTimeout = 30 * time.Second
+
 //go:generate mockgen -package mocks -destination mocks/analytics.go gopkg.in/segmentio/analytics-go.v3 Client
 
 type Client struct {
 	host            string
-	logger          *log.Logger
+	logger          *logging.Logger
 	httpClient      *http.Client
-	analyticsClient analytics.Client
+	analyticsClient sink.Sink
 	userUUID        string
 	version         string
+	// EventTypes are the types of events fetched.
+	EventTypes []string
+	// Timeout bounds each request.
+	Timeout time.Duration
+	// ctx cancels the requests in flight, e.g. when the daemon stops.
+	ctx context.Context
+	// v3 is set when the Cloud Controller no longer has /v2/events, so
+	// that events are polled from /v3/audit_events instead.
+	v3 bool
 }
 
 type Event struct {
+	GUID      string
 	Type      string
 	Timestamp time.Time
 	Metadata  json.RawMessage
@@ -35,6 +51,9 @@ type Event struct {
 type eventResponse struct {
 	NextURL   *string `json:"next_url"`
 	Resources []struct {
+		Metadata struct {
+			Guid string
+		}
 		Entity struct {
 			Type      string
 			Timestamp string
@@ -43,20 +62,7 @@ type eventResponse struct {
 	}
 }
 
-var eventTypes = []string{
-	"audit.app.create",
-	"audit.app.restage",
-	"app.crash",
-	"audit.organization.create",
-	"audit.space.create",
-	"audit.service_instance.create",
-	"audit.service_binding.create",
-	"audit.service_broker.create",
-	"audit.user_provided_service_instance.create",
-	"audit.route.create",
-}
-
-func New(host string, logger *log.Logger, httpClient *http.Client, analyticsClient analytics.Client, userUUID string, version string) *Client {
+func New(host string, logger *logging.Logger, httpClient *http.Client, analyticsClient sink.Sink, userUUID string, version string) *Client {
 	return &Client{
 		host:            host,
 		logger:          logger,
@@ -64,9 +70,18 @@ func New(host string, logger *log.Logger, httpClient *http.Client, analyticsClie
 		analyticsClient: analyticsClient,
 		userUUID:        userUUID,
 		version:         version,
+		Timeout:         DefaultTimeout,
 	}
 }
 
+// WithContext returns a copy of the client whose requests are cancelled
+// along with ctx.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	client := *c
+	client.ctx = ctx
+	return &client
+}
+
 func (c *Client) FetchLatestTime() time.Time {
 	params := url.Values{}
 	params.Add("order-by", "timestamp")
@@ -81,9 +96,17 @@ func (c *Client) FetchLatestTime() time.Time {
 		}
 	}
 
-	c.logger.Println("Fetching latest timestamp from Cloud Controller...")
+	c.logger.Info("Fetching the latest event time from the Cloud Controller")
 
-	err := c.Fetch("/v2/events", params, &result)
+	status, contents, err := c.get("/v2/events", params)
+	if err == nil && status == http.StatusNotFound {
+		c.logger.Info("The Cloud Controller has no v2 events, using v3 audit events")
+		c.v3 = true
+		return c.fetchLatestTimeV3()
+	}
+	if err == nil {
+		err = c.decode(status, contents, &result)
+	}
 	if err != nil {
 		return time.Now().UTC()
 	}
@@ -93,17 +116,24 @@ func (c *Client) FetchLatestTime() time.Time {
 	}
 
 	t, _ := time.Parse(time.RFC3339, result.Resources[0].Entity.Timestamp)
-	c.logger.Printf("Using timestamp of %v to mark new events\n", t)
+	c.logger.Info("Marking new events", "after", t)
 	return t
 }
 
+// FetchEvents returns the events from the second of timeStamp on, which
+// includes those from that second already seen, as the Cloud Controller has
+// no finer timestamps to tell them apart by.
 func (c *Client) FetchEvents(timeStamp time.Time) ([]Event, error) {
+	if c.v3 {
+		return c.fetchEventsV3(timeStamp)
+	}
+
 	var (
 		events  []Event
 		nextURL *string = nil
 		fetch           = func(params url.Values) error {
 			var response eventResponse
-			err := c.Fetch("/v2/events", params, &response)
+			err := c.fetchPage("/v2/events", params, &response)
 			if err != nil {
 				return err
 			}
@@ -112,6 +142,7 @@ func (c *Client) FetchEvents(timeStamp time.Time) ([]Event, error) {
 				t, _ := time.Parse(time.RFC3339, resource.Entity.Timestamp)
 
 				events = append(events, Event{
+					GUID:      resource.Metadata.Guid,
 					Type:      resource.Entity.Type,
 					Timestamp: t,
 					Metadata:  resource.Entity.Metadata,
@@ -124,8 +155,11 @@ func (c *Client) FetchEvents(timeStamp time.Time) ([]Event, error) {
 	)
 
 	params := url.Values{}
-	params.Add("q", "type IN "+strings.Join(eventTypes, ","))
-	params.Add("q", "timestamp>"+timeStamp.Format(ccTimeStampFormat))
+	params.Add("q", "type IN "+strings.Join(c.EventTypes, ","))
+	params.Add("q", "timestamp>="+timeStamp.Format(ccTimeStampFormat))
+	params.Add("order-by", "timestamp")
+	params.Add("order-direction", "asc")
+	params.Add("results-per-page", "100")
 
 	err := fetch(params)
 	if err != nil {
@@ -148,44 +182,86 @@ func (c *Client) FetchEvents(timeStamp time.Time) ([]Event, error) {
 }
 
 func (c *Client) Fetch(path string, params url.Values, dest interface{}) error {
+	status, contents, err := c.get(path, params)
+	if err != nil {
+		return err
+	}
+
+	return c.decode(status, contents, dest)
+}
+
+// fetchPage is Fetch for a page of events. Unlike Fetch, a page that
+// cannot be fetched fails, so that the poll does not move past events it
+// has not seen.
+func (c *Client) fetchPage(path string, params url.Values, dest interface{}) error {
+	status, contents, err := c.get(path, params)
+	if err != nil {
+		return err
+	}
+
+	if err := c.decode(status, contents, dest); err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("failed to fetch events from %s: %d %s", path, status, http.StatusText(status))
+	}
+	return nil
+}
+
+func (c *Client) get(path string, params url.Values) (int, []byte, error) {
 	url := c.host + path
 
-	c.logger.Printf("Making request to %q with params: %v...\n", url, params)
+	c.logger.Debug("Making request", "url", url, "params", params.Encode())
 
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 
 	req.URL.RawQuery = params.Encode()
 
-	resp, err := c.httpClient.Do(req)
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
 	if err != nil {
-		return fmt.Errorf("failed to query cloud controller: %s", err)
+		return 0, nil, fmt.Errorf("failed to query cloud controller: %s", err)
 	}
+	defer resp.Body.Close()
 
 	contents, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 
-	c.logger.Printf("Received status code [%s] from url: %q\n", resp.Status, url)
+	c.logger.Debug("Received response", "url", url, "status", resp.Status)
+
+	return resp.StatusCode, contents, nil
+}
 
-	if resp.StatusCode == http.StatusOK {
+func (c *Client) decode(status int, contents []byte, dest interface{}) error {
+	if status == http.StatusOK {
 		return json.Unmarshal(contents, dest)
 	}
 
 	var properties = analytics.Properties{
-		"message": fmt.Sprintf("failed to contact cc api: [%v] %s", resp.Status, contents),
+		"message": fmt.Sprintf("failed to contact cc api: [%d %s] %s", status, http.StatusText(status), contents),
 		"os":      runtime.GOOS,
 		"version": c.version,
 	}
 
-	c.logger.Println("Sending an error to segment.io...")
+	c.logger.Warn("The Cloud Controller request failed, sending an analytics error", "status", status)
 
 	// Still not sure if sending every error to segment
 	// is preferred behavior
-	err = c.analyticsClient.Enqueue(analytics.Track{
+	err := c.analyticsClient.Enqueue(analytics.Track{
 		UserId:     c.userUUID,
 		Event:      "analytics error",
 		Timestamp:  time.Now().UTC(),
@@ -193,7 +269,7 @@ func (c *Client) Fetch(path string, params url.Values, dest interface{}) error {
 	})
 
 	if err != nil {
-		c.logger.Printf("Failed to send analytics error: %v\n", err)
+		c.logger.Error("Failed to send analytics error", "error", err)
 	}
 
 	return nil
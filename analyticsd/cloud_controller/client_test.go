@@ -3,14 +3,14 @@ package cloud_controller_test
 import (
 	"code.cloudfoundry.org/cfdev/analyticsd/cloud_controller"
 	"code.cloudfoundry.org/cfdev/analyticsd/cloud_controller/mocks"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"context"
 	"encoding/json"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/ghttp"
 	"gopkg.in/segmentio/analytics-go.v3"
-	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
 	"time"
@@ -31,12 +31,13 @@ var _ = Describe("Cloud Controller Client", func() {
 
 		client = cloud_controller.New(
 			server.URL(),
-			log.New(ioutil.Discard, "", log.LstdFlags),
+			logging.Discard(),
 			&http.Client{},
 			mockAnalytics,
 			"some-user-id",
 			"some-version",
 		)
+		client.EventTypes = []string{"audit.app.create", "app.crash"}
 	})
 
 	AfterEach(func() {
@@ -145,6 +146,8 @@ var _ = Describe("Cloud Controller Client", func() {
 							values := req.URL.Query()
 							Expect(values["q"][0]).To(ContainSubstring("type IN"))
 							Expect(values["q"][1]).To(ContainSubstring("timestamp"))
+							Expect(values.Get("order-by")).To(Equal("timestamp"))
+							Expect(values.Get("order-direction")).To(Equal("asc"))
 						},
 						ghttp.RespondWith(http.StatusOK, `
 						{
@@ -196,6 +199,112 @@ var _ = Describe("Cloud Controller Client", func() {
 				}))
 			})
 		})
+
+		Context("when a page cannot be fetched", func() {
+			It("fails rather than returning the events before it", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+						ghttp.RespondWith(http.StatusOK, `
+						{
+							"next_url": "/v2/events?page=2",
+							"resources": [{
+								"entity": {
+									"type": "some-event-type",
+									"timestamp": "2016-06-06T06:06:06Z"
+								}
+							}]
+						}
+					`)),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest(http.MethodGet, "/v2/events", "page=2"),
+						ghttp.RespondWith(http.StatusServiceUnavailable, `some-error`),
+					),
+				)
+				mockAnalytics.EXPECT().Enqueue(gomock.Any())
+
+				_, err := client.FetchEvents(time.Time{})
+				Expect(err).To(MatchError("failed to fetch events from /v2/events: 503 Service Unavailable"))
+			})
+		})
+	})
+
+	Describe("when the Cloud Controller has no v2 events", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					ghttp.RespondWith(http.StatusNotFound, `{"description": "Unknown request"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v3/audit_events", "order_by=-created_at&per_page=1"),
+					ghttp.RespondWith(http.StatusOK, `
+					{
+						"pagination": {"next": null},
+						"resources": [{"type": "audit.app.create", "created_at": "2016-06-06T06:06:06Z"}]
+					}
+					`),
+				),
+			)
+		})
+
+		It("polls the v3 audit events instead", func() {
+			t := client.FetchLatestTime()
+			Expect(t).To(BeTemporally("==", time.Date(2016, 6, 6, 6, 6, 6, 0, time.UTC)))
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v3/audit_events"),
+					func(w http.ResponseWriter, req *http.Request) {
+						values := req.URL.Query()
+						Expect(values.Get("types")).To(ContainSubstring("audit.app.create"))
+						Expect(values.Get("types")).To(ContainSubstring("audit.app.process.crash"))
+						Expect(values.Get("types")).NotTo(ContainSubstring(",app.crash"))
+						Expect(values.Get("created_ats[gte]")).To(Equal("2016-06-06T06:06:06Z"))
+						Expect(values.Get("order_by")).To(Equal("created_at"))
+					},
+					ghttp.RespondWith(http.StatusOK, `
+					{
+						"pagination": {"next": {"href": "https://api.example.com/v3/audit_events?page=2&per_page=50"}},
+						"resources": [{
+							"type": "audit.app.create",
+							"created_at": "2016-06-06T06:06:07Z",
+							"data": {"request": {"buildpack": "go_buildpack"}}
+						}]
+					}
+					`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v3/audit_events", "page=2&per_page=50"),
+					ghttp.RespondWith(http.StatusOK, `
+					{
+						"pagination": {"next": null},
+						"resources": [{
+							"type": "audit.app.process.crash",
+							"created_at": "2016-07-07T07:07:07Z",
+							"data": {"reason": "CRASHED"}
+						}]
+					}
+					`),
+				),
+			)
+
+			events, err := client.FetchEvents(t)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(events).To(Equal([]cloud_controller.Event{
+				{
+					Type:      "audit.app.create",
+					Timestamp: time.Date(2016, 6, 6, 6, 6, 7, 0, time.UTC),
+					Metadata:  json.RawMessage(`{"request": {"buildpack": "go_buildpack"}}`),
+				},
+				{
+					Type:      "app.crash",
+					Timestamp: time.Date(2016, 7, 7, 7, 7, 7, 0, time.UTC),
+					Metadata:  json.RawMessage(`{"reason": "CRASHED"}`),
+				},
+			}))
+		})
 	})
 
 	Describe("Fetch", func() {
@@ -239,5 +348,35 @@ var _ = Describe("Cloud Controller Client", func() {
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
+
+		Context("when cloud controller hangs", func() {
+			var release chan struct{}
+
+			BeforeEach(func() {
+				release = make(chan struct{})
+				server.AppendHandlers(func(w http.ResponseWriter, req *http.Request) {
+					<-release
+				})
+			})
+
+			AfterEach(func() {
+				close(release)
+			})
+
+			It("gives up after the timeout", func() {
+				client.Timeout = 50 * time.Millisecond
+
+				err := client.Fetch("/v2/some-endpoint", nil, nil)
+				Expect(err).To(MatchError(ContainSubstring("failed to query cloud controller")))
+			})
+
+			It("gives up when the context is cancelled", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(50*time.Millisecond, cancel)
+
+				err := client.WithContext(ctx).Fetch("/v2/some-endpoint", nil, nil)
+				Expect(err).To(MatchError(ContainSubstring("context canceled")))
+			})
+		})
 	})
 })
@@ -0,0 +1,105 @@
+package cloud_controller
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// v3EventTypes translates the types of v3 audit events that are named
+// differently from their v2 events, so that the same handlers see them.
+var v3EventTypes = map[string]string{
+	"audit.app.process.crash": "app.crash",
+}
+
+type auditEventsResponse struct {
+	Pagination struct {
+		Next *struct {
+			Href string
+		}
+	}
+	Resources []struct {
+		Guid      string
+		Type      string
+		CreatedAt string `json:"created_at"`
+		Data      json.RawMessage
+	}
+}
+
+// v3Types are the types of audit events to poll for.
+func (c *Client) v3Types() []string {
+	translated := map[string]bool{}
+	var types []string
+	for v3Type, v2Type := range v3EventTypes {
+		translated[v2Type] = true
+		types = append(types, v3Type)
+	}
+
+	for _, eventType := range c.EventTypes {
+		if !translated[eventType] {
+			types = append(types, eventType)
+		}
+	}
+	return types
+}
+
+func (c *Client) fetchLatestTimeV3() time.Time {
+	params := url.Values{}
+	params.Add("order_by", "-created_at")
+	params.Add("per_page", "1")
+
+	var response auditEventsResponse
+	err := c.Fetch("/v3/audit_events", params, &response)
+	if err != nil || len(response.Resources) == 0 {
+		return time.Now().UTC()
+	}
+
+	t, _ := time.Parse(time.RFC3339, response.Resources[0].CreatedAt)
+	c.logger.Info("Marking new events", "after", t)
+	return t
+}
+
+// fetchEventsV3 pages through the audit events from timeStamp on, following
+// the cursor each page links to the next.
+func (c *Client) fetchEventsV3(timeStamp time.Time) ([]Event, error) {
+	params := url.Values{}
+	params.Add("types", strings.Join(c.v3Types(), ","))
+	params.Add("created_ats[gte]", timeStamp.Format(ccTimeStampFormat))
+	params.Add("order_by", "created_at")
+	params.Add("per_page", "100")
+
+	var events []Event
+	for {
+		var response auditEventsResponse
+		if err := c.fetchPage("/v3/audit_events", params, &response); err != nil {
+			return nil, err
+		}
+
+		for _, resource := range response.Resources {
+			t, _ := time.Parse(time.RFC3339, resource.CreatedAt)
+
+			eventType := resource.Type
+			if v2Type, ok := v3EventTypes[eventType]; ok {
+				eventType = v2Type
+			}
+
+			events = append(events, Event{
+				GUID:      resource.Guid,
+				Type:      eventType,
+				Timestamp: t,
+				Metadata:  resource.Data,
+			})
+		}
+
+		if response.Pagination.Next == nil {
+			return events, nil
+		}
+
+		next, err := url.Parse(response.Pagination.Next.Href)
+		if err != nil {
+			return nil, err
+		}
+		params = next.Query()
+	}
+}
@@ -1,22 +1,41 @@
 package command
 
 import (
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
 	"encoding/json"
 	"fmt"
 	"gopkg.in/segmentio/analytics-go.v3"
-	"log"
 	"runtime"
 	"time"
 )
 
 type AppCrash struct {
 	CCClient        CloudControllerClient
-	AnalyticsClient analytics.Client
+	AnalyticsClient sink.Sink
 	TimeStamp       time.Time
 	UUID            string
 	Version         string
 	OSVersion       string
-	Logger          *log.Logger
+	Logger          *logging.Logger
+}
+
+func init() {
+	Register(Handler{
+		Name:   "AppCrash",
+		Events: []string{"app.crash"},
+		New: func(_ string, deps Deps) Command {
+			return &AppCrash{
+				CCClient:        deps.CCClient,
+				AnalyticsClient: deps.AnalyticsClient,
+				TimeStamp:       deps.TimeStamp,
+				UUID:            deps.UUID,
+				Version:         deps.Version,
+				OSVersion:       deps.OSVersion,
+				Logger:          deps.Logger,
+			}
+		},
+	})
 }
 
 func (c *AppCrash) HandleResponse(body json.RawMessage) error {
@@ -26,7 +45,7 @@ func (c *AppCrash) HandleResponse(body json.RawMessage) error {
 		"os_version":     c.OSVersion,
 	}
 
-	err := c.AnalyticsClient.Enqueue(analytics.Track{
+	err := enqueue(c.AnalyticsClient, analytics.Track{
 		UserId:     c.UUID,
 		Event:      "app push failed",
 		Timestamp:  c.TimeStamp,
@@ -3,11 +3,10 @@ package command_test
 import (
 	"code.cloudfoundry.org/cfdev/analyticsd/command"
 	"code.cloudfoundry.org/cfdev/analyticsd/command/mocks"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	"gopkg.in/segmentio/analytics-go.v3"
-	"io/ioutil"
-	"log"
 	"runtime"
 	"time"
 )
@@ -24,7 +23,7 @@ var _ = Describe("AppCreate", func() {
 		mockAnalytics = mocks.NewMockClient(mockController)
 
 		cmd = &command.AppCreate{
-			Logger:          log.New(ioutil.Discard, "", log.LstdFlags),
+			Logger:          logging.Discard(),
 			AnalyticsClient: mockAnalytics,
 			TimeStamp:       time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
 			UUID:            "some-user-uuid",
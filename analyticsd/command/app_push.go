@@ -0,0 +1,72 @@
+package command
+
+import (
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/segmentio/analytics-go.v3"
+	"runtime"
+	"time"
+)
+
+// AppPush counts pushes. The v2 api records a push as a single event,
+// whereas the v3 api records the build and then the droplet it staged,
+// so the event the push came from is sent along to tell them apart.
+type AppPush struct {
+	CCClient        CloudControllerClient
+	AnalyticsClient sink.Sink
+	TimeStamp       time.Time
+	UUID            string
+	Version         string
+	OSVersion       string
+	Logger          *logging.Logger
+	Source          string
+}
+
+func init() {
+	Register(Handler{
+		Name:   "AppPush",
+		Events: []string{"audit.app.push", "audit.app.build.create", "audit.app.droplet.create"},
+		New: func(event string, deps Deps) Command {
+			return &AppPush{
+				CCClient:        deps.CCClient,
+				AnalyticsClient: deps.AnalyticsClient,
+				TimeStamp:       deps.TimeStamp,
+				UUID:            deps.UUID,
+				Version:         deps.Version,
+				OSVersion:       deps.OSVersion,
+				Logger:          deps.Logger,
+				Source:          event,
+			}
+		},
+	})
+}
+
+var appPushSources = map[string]string{
+	"audit.app.push":           "push",
+	"audit.app.build.create":   "build",
+	"audit.app.droplet.create": "droplet",
+}
+
+func (c *AppPush) HandleResponse(body json.RawMessage) error {
+	var properties = analytics.Properties{
+		"source":         appPushSources[c.Source],
+		"os":             runtime.GOOS,
+		"plugin_version": c.Version,
+		"os_version":     c.OSVersion,
+	}
+
+	err := enqueue(c.AnalyticsClient, analytics.Track{
+		UserId:     c.UUID,
+		Event:      "app pushed",
+		Timestamp:  c.TimeStamp,
+		Properties: properties,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to send analytics: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package command_test
+
+import (
+	"code.cloudfoundry.org/cfdev/analyticsd/command"
+	"code.cloudfoundry.org/cfdev/analyticsd/command/mocks"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/segmentio/analytics-go.v3"
+	"runtime"
+	"time"
+)
+
+var _ = Describe("AppPush", func() {
+	var (
+		mockController *gomock.Controller
+		mockAnalytics  *mocks.MockClient
+		logger         *logging.Logger
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockAnalytics = mocks.NewMockClient(mockController)
+		logger = logging.Discard()
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	expectPush := func(source string) {
+		mockAnalytics.EXPECT().Enqueue(analytics.Track{
+			UserId:    "some-user-uuid",
+			Event:     "app pushed",
+			Timestamp: time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
+			Properties: map[string]interface{}{
+				"source":         source,
+				"os":             runtime.GOOS,
+				"plugin_version": "some-version",
+				"os_version":     "some-os-version",
+			},
+		})
+	}
+
+	handle := func(event string) error {
+		cmd, ok := command.New(event, nil, mockAnalytics, time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC), "some-user-uuid", "some-version", "some-os-version", nil, nil, logger)
+		Expect(ok).To(BeTrue())
+		return cmd.HandleResponse([]byte(`{"app_guid": "some-app-guid"}`))
+	}
+
+	It("sends v2 pushes to segment.io", func() {
+		expectPush("push")
+		Expect(handle("audit.app.push")).To(Succeed())
+	})
+
+	It("sends v3 builds and droplets to segment.io", func() {
+		expectPush("build")
+		Expect(handle("audit.app.build.create")).To(Succeed())
+
+		expectPush("droplet")
+		Expect(handle("audit.app.droplet.create")).To(Succeed())
+	})
+})
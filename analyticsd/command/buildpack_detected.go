@@ -0,0 +1,97 @@
+package command
+
+import (
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/segmentio/analytics-go.v3"
+	"runtime"
+	"time"
+)
+
+// BuildpackDetected counts the buildpacks apps were staged with, as
+// detected rather than asked for, once the droplet is mapped to the app.
+// Buildpacks cfdev does not ship are counted as custom, so that their
+// names are not sent.
+type BuildpackDetected struct {
+	CCClient        CloudControllerClient
+	AnalyticsClient sink.Sink
+	TimeStamp       time.Time
+	UUID            string
+	Version         string
+	OSVersion       string
+	Logger          *logging.Logger
+}
+
+func init() {
+	Register(Handler{
+		Name:   "BuildpackDetected",
+		Events: []string{"audit.app.droplet.mapped"},
+		New: func(_ string, deps Deps) Command {
+			return &BuildpackDetected{
+				CCClient:        deps.CCClient,
+				AnalyticsClient: deps.AnalyticsClient,
+				TimeStamp:       deps.TimeStamp,
+				UUID:            deps.UUID,
+				Version:         deps.Version,
+				OSVersion:       deps.OSVersion,
+				Logger:          deps.Logger,
+			}
+		},
+	})
+}
+
+func (c *BuildpackDetected) HandleResponse(body json.RawMessage) error {
+	var metadata struct {
+		Request struct {
+			DropletGuid string `json:"droplet_guid"`
+		}
+	}
+
+	json.Unmarshal(body, &metadata)
+
+	var droplet struct {
+		Buildpacks []struct {
+			Name string
+		}
+	}
+
+	path := "/v3/droplets/" + metadata.Request.DropletGuid
+	err := c.CCClient.Fetch(path, nil, &droplet)
+	if err != nil {
+		return fmt.Errorf("failed to make request to: %s: %s", path, err)
+	}
+
+	// Docker droplets have no buildpacks.
+	if len(droplet.Buildpacks) == 0 {
+		return nil
+	}
+
+	// With several buildpacks, the last is the one that runs the app.
+	buildpack, ok := buildpackWhitelist[droplet.Buildpacks[len(droplet.Buildpacks)-1].Name]
+	if !ok {
+		buildpack = "custom"
+	}
+
+	var properties = analytics.Properties{
+		"buildpack":       buildpack,
+		"buildpack_count": len(droplet.Buildpacks),
+		"os":              runtime.GOOS,
+		"plugin_version":  c.Version,
+		"os_version":      c.OSVersion,
+	}
+
+	err = enqueue(c.AnalyticsClient, analytics.Track{
+		UserId:     c.UUID,
+		Event:      "buildpack detected",
+		Timestamp:  c.TimeStamp,
+		Properties: properties,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to send analytics: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package command_test
+
+import (
+	"code.cloudfoundry.org/cfdev/analyticsd/command"
+	"code.cloudfoundry.org/cfdev/analyticsd/command/mocks"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/segmentio/analytics-go.v3"
+	"runtime"
+	"time"
+)
+
+var _ = Describe("BuildpackDetected", func() {
+	var (
+		cmd            *command.BuildpackDetected
+		mockController *gomock.Controller
+		mockAnalytics  *mocks.MockClient
+		mockCCClient   *mocks.MockCloudControllerClient
+		body           = []byte(`{"request": {"droplet_guid": "some-droplet-guid"}}`)
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockAnalytics = mocks.NewMockClient(mockController)
+		mockCCClient = mocks.NewMockCloudControllerClient(mockController)
+
+		cmd = &command.BuildpackDetected{
+			Logger:          logging.Discard(),
+			CCClient:        mockCCClient,
+			AnalyticsClient: mockAnalytics,
+			TimeStamp:       time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
+			UUID:            "some-user-uuid",
+			Version:         "some-version",
+			OSVersion:       "some-os-version",
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	expectBuildpack := func(buildpack string, count int) {
+		mockAnalytics.EXPECT().Enqueue(analytics.Track{
+			UserId:    "some-user-uuid",
+			Event:     "buildpack detected",
+			Timestamp: time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
+			Properties: map[string]interface{}{
+				"buildpack":       buildpack,
+				"buildpack_count": count,
+				"os":              runtime.GOOS,
+				"plugin_version":  "some-version",
+				"os_version":      "some-os-version",
+			},
+		})
+	}
+
+	Context("when the droplet was staged with a buildpack cfdev ships", func() {
+		It("sends the buildpack", func() {
+			MatchFetch(mockCCClient, "/v3/droplets/some-droplet-guid", `{"buildpacks": [{"name": "go_buildpack", "detect_output": "go"}]}`)
+			expectBuildpack("go", 1)
+
+			Expect(cmd.HandleResponse(body)).To(Succeed())
+		})
+	})
+
+	Context("when the droplet was staged with several buildpacks", func() {
+		It("sends the last one, which runs the app", func() {
+			MatchFetch(mockCCClient, "/v3/droplets/some-droplet-guid", `{"buildpacks": [{"name": "nodejs_buildpack"}, {"name": "python_buildpack"}]}`)
+			expectBuildpack("python", 2)
+
+			Expect(cmd.HandleResponse(body)).To(Succeed())
+		})
+	})
+
+	Context("when the droplet was staged with a custom buildpack", func() {
+		It("does not send its name", func() {
+			MatchFetch(mockCCClient, "/v3/droplets/some-droplet-guid", `{"buildpacks": [{"name": "https://github.com/me/secret-buildpack"}]}`)
+			expectBuildpack("custom", 1)
+
+			Expect(cmd.HandleResponse(body)).To(Succeed())
+		})
+	})
+
+	Context("when the droplet has no buildpacks", func() {
+		It("sends nothing", func() {
+			MatchFetch(mockCCClient, "/v3/droplets/some-droplet-guid", `{"buildpacks": []}`)
+
+			Expect(cmd.HandleResponse(body)).To(Succeed())
+		})
+	})
+})
@@ -1,10 +1,9 @@
 package command
 
 import (
-	"code.cloudfoundry.org/cfdev/analyticsd/config"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
 	"encoding/json"
-	"gopkg.in/segmentio/analytics-go.v3"
-	"log"
 	"net/url"
 	"strings"
 	"time"
@@ -24,141 +23,36 @@ type CloudControllerClient interface {
 func New(
 	event string,
 	ccClient CloudControllerClient,
-	analyticsClient analytics.Client,
+	analyticsClient sink.Sink,
 	timeStamp time.Time,
 	UUID string,
 	version string,
 	osVersion string,
-	logger *log.Logger) (Command, bool) {
+	serviceLabels *ServiceLabels,
+	serviceWhitelist []string,
+	logger *logging.Logger) (Command, bool) {
 
-	switch event {
-	case "audit.app.restage":
-		logger.Printf("Detected event for %q\n", event)
-
-		return &AppRestage{
-			CCClient:        ccClient,
-			AnalyticsClient: analyticsClient,
-			TimeStamp:       timeStamp,
-			UUID:            UUID,
-			Version:         version,
-			OSVersion:       osVersion,
-			Logger:          logger,
-		}, true
-	case "audit.app.create":
-		logger.Printf("Detected event for %q\n", event)
-
-		return &AppCreate{
-			CCClient:        ccClient,
-			AnalyticsClient: analyticsClient,
-			TimeStamp:       timeStamp,
-			UUID:            UUID,
-			Version:         version,
-			OSVersion:       osVersion,
-			Logger:          logger,
-		}, true
-	case "app.crash":
-		logger.Printf("Detected event for %q\n", event)
-
-		return &AppCrash{
-			CCClient:        ccClient,
-			AnalyticsClient: analyticsClient,
-			TimeStamp:       timeStamp,
-			UUID:            UUID,
-			Version:         version,
-			OSVersion:       osVersion,
-			Logger:          logger,
-		}, true
-	case "audit.organization.create":
-		logger.Printf("Detected event for %q\n", event)
-
-		return &OrgCreate{
-			CCClient:        ccClient,
-			AnalyticsClient: analyticsClient,
-			TimeStamp:       timeStamp,
-			UUID:            UUID,
-			Version:         version,
-			OSVersion:       osVersion,
-			Logger:          logger,
-		}, true
-	case "audit.space.create":
-		logger.Printf("Detected event for %q\n", event)
-
-		return &SpaceCreate{
-			CCClient:        ccClient,
-			AnalyticsClient: analyticsClient,
-			TimeStamp:       timeStamp,
-			UUID:            UUID,
-			Version:         version,
-			OSVersion:       osVersion,
-			Logger:          logger,
-		}, true
-	case "audit.service_instance.create":
-		logger.Printf("Detected event for %q\n", event)
-
-		return &ServiceCreate{
-			CCClient:        ccClient,
-			AnalyticsClient: analyticsClient,
-			TimeStamp:       timeStamp,
-			UUID:            UUID,
-			Version:         version,
-			OSVersion:       osVersion,
-			Logger:          logger,
-		}, true
-	case "audit.service_binding.create":
-		logger.Printf("Detected event for %q\n", event)
-
-		return &ServiceBind{
-			CCClient:        ccClient,
-			AnalyticsClient: analyticsClient,
-			TimeStamp:       timeStamp,
-			UUID:            UUID,
-			Version:         version,
-			OSVersion:       osVersion,
-			Logger:          logger,
-		}, true
-	case "audit.service_broker.create":
-		logger.Printf("Detected event for %q\n", event)
-
-		return &ServiceBrokerCreate{
-			CCClient:        ccClient,
-			AnalyticsClient: analyticsClient,
-			TimeStamp:       timeStamp,
-			UUID:            UUID,
-			Version:         version,
-			OSVersion:       osVersion,
-			Logger:          logger,
-		}, true
-	case "audit.user_provided_service_instance.create":
-		logger.Printf("Detected event for %q\n", event)
-
-		return &UserProvidedServiceCreate{
-			CCClient:        ccClient,
-			AnalyticsClient: analyticsClient,
-			TimeStamp:       timeStamp,
-			UUID:            UUID,
-			Version:         version,
-			OSVersion:       osVersion,
-			Logger:          logger,
-		}, true
-	case "audit.route.create":
-		logger.Printf("Detected event for %q\n", event)
-
-		return &RouteCreate{
-			CCClient:        ccClient,
-			AnalyticsClient: analyticsClient,
-			TimeStamp:       timeStamp,
-			UUID:            UUID,
-			Version:         version,
-			OSVersion:       osVersion,
-			Logger:          logger,
-		}, true
-	default:
+	handler, ok := Lookup(event)
+	if !ok {
 		return nil, false
 	}
+	logger.Info("Detected event", "event", event)
+
+	return handler.New(event, Deps{
+		CCClient:         ccClient,
+		AnalyticsClient:  analyticsClient,
+		TimeStamp:        timeStamp,
+		UUID:             UUID,
+		Version:          version,
+		OSVersion:        osVersion,
+		ServiceLabels:    serviceLabels,
+		ServiceWhitelist: serviceWhitelist,
+		Logger:           logger,
+	}), true
 }
 
-func serviceIsWhiteListed(serviceLabel string) bool {
-	for _, listedLabel := range config.SERVICE_WHITELIST {
+func serviceIsWhiteListed(whitelist []string, serviceLabel string) bool {
+	for _, listedLabel := range whitelist {
 		sl, ll := strings.ToLower(serviceLabel), strings.ToLower(listedLabel)
 		if sl == ll {
 			return true
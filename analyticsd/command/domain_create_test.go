@@ -0,0 +1,61 @@
+package command_test
+
+import (
+	"code.cloudfoundry.org/cfdev/analyticsd/command"
+	"code.cloudfoundry.org/cfdev/analyticsd/command/mocks"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/segmentio/analytics-go.v3"
+	"runtime"
+	"time"
+)
+
+var _ = Describe("DomainCreate", func() {
+	var (
+		cmd            *command.DomainCreate
+		mockController *gomock.Controller
+		mockAnalytics  *mocks.MockClient
+		mockCCClient   *mocks.MockCloudControllerClient
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockAnalytics = mocks.NewMockClient(mockController)
+		mockCCClient = mocks.NewMockCloudControllerClient(mockController)
+
+		cmd = &command.DomainCreate{
+			Logger:          logging.Discard(),
+			CCClient:        mockCCClient,
+			AnalyticsClient: mockAnalytics,
+			TimeStamp:       time.Date(2018, 7, 7, 7, 7, 7, 0, time.UTC),
+			UUID:            "some-user-uuid",
+			Version:         "some-version",
+			OSVersion:       "some-os-version",
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	Context("when a domain is created", func() {
+		It("sends the event to segment.io without the domain name", func() {
+			mockAnalytics.EXPECT().Enqueue(analytics.Track{
+				UserId:    "some-user-uuid",
+				Event:     "created domain",
+				Timestamp: time.Date(2018, 7, 7, 7, 7, 7, 0, time.UTC),
+				Properties: map[string]interface{}{
+					"os":             runtime.GOOS,
+					"plugin_version": "some-version",
+					"os_version":     "some-os-version",
+				},
+			})
+
+			body := []byte(`{"request": {"name": "some-domain.com", "internal": false}}`)
+
+			Expect(cmd.HandleResponse(body)).NotTo(HaveOccurred())
+		})
+	})
+})
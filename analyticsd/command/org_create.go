@@ -1,22 +1,41 @@
 package command
 
 import (
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
 	"encoding/json"
 	"fmt"
 	"gopkg.in/segmentio/analytics-go.v3"
-	"log"
 	"runtime"
 	"time"
 )
 
 type OrgCreate struct {
 	CCClient        CloudControllerClient
-	AnalyticsClient analytics.Client
+	AnalyticsClient sink.Sink
 	TimeStamp       time.Time
 	UUID            string
 	Version         string
 	OSVersion       string
-	Logger          *log.Logger
+	Logger          *logging.Logger
+}
+
+func init() {
+	Register(Handler{
+		Name:   "OrgCreate",
+		Events: []string{"audit.organization.create"},
+		New: func(_ string, deps Deps) Command {
+			return &OrgCreate{
+				CCClient:        deps.CCClient,
+				AnalyticsClient: deps.AnalyticsClient,
+				TimeStamp:       deps.TimeStamp,
+				UUID:            deps.UUID,
+				Version:         deps.Version,
+				OSVersion:       deps.OSVersion,
+				Logger:          deps.Logger,
+			}
+		},
+	})
 }
 
 func (c *OrgCreate) HandleResponse(body json.RawMessage) error {
@@ -26,7 +45,7 @@ func (c *OrgCreate) HandleResponse(body json.RawMessage) error {
 		"os_version":     c.OSVersion,
 	}
 
-	err := c.AnalyticsClient.Enqueue(analytics.Track{
+	err := enqueue(c.AnalyticsClient, analytics.Track{
 		UserId:     c.UUID,
 		Event:      "org created",
 		Timestamp:  c.TimeStamp,
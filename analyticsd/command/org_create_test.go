@@ -3,12 +3,11 @@ package command_test
 import (
 	"code.cloudfoundry.org/cfdev/analyticsd/command"
 	"code.cloudfoundry.org/cfdev/analyticsd/command/mocks"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"gopkg.in/segmentio/analytics-go.v3"
-	"io/ioutil"
-	"log"
 	"runtime"
 	"time"
 )
@@ -27,7 +26,7 @@ var _ = Describe("OrgCreate", func() {
 		mockCCClient = mocks.NewMockCloudControllerClient(mockController)
 
 		cmd = &command.OrgCreate{
-			Logger:          log.New(ioutil.Discard, "", log.LstdFlags),
+			Logger:          logging.Discard(),
 			CCClient:        mockCCClient,
 			AnalyticsClient: mockAnalytics,
 			TimeStamp:       time.Date(2018, 7, 7, 7, 7, 7, 0, time.UTC),
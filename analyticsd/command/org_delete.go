@@ -0,0 +1,60 @@
+package command
+
+import (
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/segmentio/analytics-go.v3"
+	"runtime"
+	"time"
+)
+
+type OrgDelete struct {
+	CCClient        CloudControllerClient
+	AnalyticsClient sink.Sink
+	TimeStamp       time.Time
+	UUID            string
+	Version         string
+	OSVersion       string
+	Logger          *logging.Logger
+}
+
+func init() {
+	Register(Handler{
+		Name:   "OrgDelete",
+		Events: []string{"audit.organization.delete-request"},
+		New: func(_ string, deps Deps) Command {
+			return &OrgDelete{
+				CCClient:        deps.CCClient,
+				AnalyticsClient: deps.AnalyticsClient,
+				TimeStamp:       deps.TimeStamp,
+				UUID:            deps.UUID,
+				Version:         deps.Version,
+				OSVersion:       deps.OSVersion,
+				Logger:          deps.Logger,
+			}
+		},
+	})
+}
+
+func (c *OrgDelete) HandleResponse(body json.RawMessage) error {
+	var properties = analytics.Properties{
+		"os":             runtime.GOOS,
+		"plugin_version": c.Version,
+		"os_version":     c.OSVersion,
+	}
+
+	err := enqueue(c.AnalyticsClient, analytics.Track{
+		UserId:     c.UUID,
+		Event:      "org deleted",
+		Timestamp:  c.TimeStamp,
+		Properties: properties,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to send analytics: %v", err)
+	}
+
+	return nil
+}
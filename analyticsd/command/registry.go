@@ -0,0 +1,76 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+)
+
+// Deps are what a handler is built from for each event.
+type Deps struct {
+	CCClient        CloudControllerClient
+	AnalyticsClient sink.Sink
+	TimeStamp       time.Time
+	UUID            string
+	Version         string
+	OSVersion       string
+	ServiceLabels   *ServiceLabels
+	// ServiceWhitelist is the labels of the services whose events are sent.
+	ServiceWhitelist []string
+	Logger           *logging.Logger
+}
+
+// Handler is a tracked kind of event. New is given the Cloud Controller
+// event type, for handlers that track more than one.
+type Handler struct {
+	Name   string
+	Events []string
+	New    func(event string, deps Deps) Command
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Handler{}
+)
+
+// Register adds a handler for its events, typically from an init func in
+// the file that defines it. Registering a second handler for an event
+// panics, as it is a programming error.
+func Register(handler Handler) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, event := range handler.Events {
+		if existing, ok := registry[event]; ok {
+			panic(fmt.Sprintf("command: %q is already handled by %s", event, existing.Name))
+		}
+		registry[event] = handler
+	}
+}
+
+// Lookup returns the handler registered for the event.
+func Lookup(event string) (Handler, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	handler, ok := registry[event]
+	return handler, ok
+}
+
+// Events returns the Cloud Controller event types a handler is registered
+// for, in order.
+func Events() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	events := make([]string, 0, len(registry))
+	for event := range registry {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	return events
+}
@@ -0,0 +1,57 @@
+package command_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/command"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type customCommand struct {
+	event string
+	deps  command.Deps
+}
+
+func (c *customCommand) HandleResponse(body json.RawMessage) error { return nil }
+
+var _ = Describe("Registry", func() {
+	It("has a handler for each tracked event", func() {
+		Expect(command.Events()).To(ContainElement("audit.app.create"))
+		Expect(command.Events()).To(ContainElement("audit.app.droplet.create"))
+		Expect(command.Events()).To(ContainElement("app.crash"))
+
+		handler, ok := command.Lookup("audit.service_binding.delete")
+		Expect(ok).To(BeTrue())
+		Expect(handler.Name).To(Equal("ServiceUnbind"))
+	})
+
+	It("builds the commands of handlers registered elsewhere", func() {
+		command.Register(command.Handler{
+			Name:   "Custom",
+			Events: []string{"audit.custom.create"},
+			New: func(event string, deps command.Deps) command.Command {
+				return &customCommand{event: event, deps: deps}
+			},
+		})
+		Expect(command.Events()).To(ContainElement("audit.custom.create"))
+
+		cmd, ok := command.New("audit.custom.create", nil, nil, time.Date(2018, 7, 7, 7, 7, 7, 0, time.UTC), "some-user-uuid", "some-version", "some-os-version", nil, nil, logging.Discard())
+		Expect(ok).To(BeTrue())
+		Expect(cmd.(*customCommand).event).To(Equal("audit.custom.create"))
+		Expect(cmd.(*customCommand).deps.UUID).To(Equal("some-user-uuid"))
+	})
+
+	It("refuses a second handler for an event", func() {
+		Expect(func() {
+			command.Register(command.Handler{Name: "Duplicate", Events: []string{"audit.app.create"}})
+		}).To(Panic())
+	})
+
+	It("does not build commands for untracked events", func() {
+		_, ok := command.New("audit.untracked", nil, nil, time.Now(), "", "", "", nil, nil, logging.Discard())
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,56 @@
+package command
+
+import (
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	"gopkg.in/segmentio/analytics-go.v3"
+	"math/rand"
+	"time"
+)
+
+// Retrier enqueues events, retrying failures with a jittered exponential
+// backoff so that a blip in the network does not fail the handler.
+type Retrier struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Sleep      func(time.Duration)
+}
+
+// DefaultRetrier is shared by every handler.
+var DefaultRetrier = &Retrier{
+	Attempts:   4,
+	Backoff:    500 * time.Millisecond,
+	MaxBackoff: 5 * time.Second,
+	Sleep:      time.Sleep,
+}
+
+// Enqueue sends the event, trying up to Attempts times. Events that are
+// malformed are not retried.
+func (r *Retrier) Enqueue(client sink.Sink, msg analytics.Message) error {
+	var err error
+	for attempt := 0; attempt < r.Attempts; attempt++ {
+		if attempt > 0 {
+			r.Sleep(r.backoff(attempt))
+		}
+
+		err = client.Enqueue(msg)
+		if _, malformed := err.(analytics.FieldError); err == nil || malformed {
+			return err
+		}
+	}
+	return err
+}
+
+// backoff doubles with each attempt, up to MaxBackoff, and is randomized
+// between half and all of that so retries do not line up.
+func (r *Retrier) backoff(attempt int) time.Duration {
+	d := r.Backoff << uint(attempt-1)
+	if d > r.MaxBackoff || d <= 0 {
+		d = r.MaxBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func enqueue(client sink.Sink, msg analytics.Message) error {
+	return DefaultRetrier.Enqueue(client, msg)
+}
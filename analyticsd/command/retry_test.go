@@ -0,0 +1,65 @@
+package command_test
+
+import (
+	"code.cloudfoundry.org/cfdev/analyticsd/command"
+	"code.cloudfoundry.org/cfdev/analyticsd/command/mocks"
+	"errors"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/segmentio/analytics-go.v3"
+	"time"
+)
+
+var _ = Describe("Retrier", func() {
+	var (
+		mockController *gomock.Controller
+		mockAnalytics  *mocks.MockClient
+		retrier        *command.Retrier
+		sleeps         []time.Duration
+		track          analytics.Track
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockAnalytics = mocks.NewMockClient(mockController)
+		sleeps = nil
+		retrier = &command.Retrier{
+			Attempts:   3,
+			Backoff:    time.Second,
+			MaxBackoff: 90 * time.Second,
+			Sleep:      func(d time.Duration) { sleeps = append(sleeps, d) },
+		}
+		track = analytics.Track{UserId: "some-user-uuid", Event: "app pushed"}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("retries failures with a growing backoff", func() {
+		gomock.InOrder(
+			mockAnalytics.EXPECT().Enqueue(track).Return(errors.New("network blip")),
+			mockAnalytics.EXPECT().Enqueue(track).Return(errors.New("network blip")),
+			mockAnalytics.EXPECT().Enqueue(track).Return(nil),
+		)
+
+		Expect(retrier.Enqueue(mockAnalytics, track)).To(Succeed())
+		Expect(sleeps).To(HaveLen(2))
+		Expect(sleeps[0]).To(BeNumerically("~", 750*time.Millisecond, 250*time.Millisecond))
+		Expect(sleeps[1]).To(BeNumerically("~", 1500*time.Millisecond, 500*time.Millisecond))
+	})
+
+	It("gives up after the last attempt", func() {
+		mockAnalytics.EXPECT().Enqueue(track).Times(3).Return(errors.New("offline"))
+
+		Expect(retrier.Enqueue(mockAnalytics, track)).To(MatchError("offline"))
+	})
+
+	It("does not retry malformed events", func() {
+		mockAnalytics.EXPECT().Enqueue(track).Return(analytics.FieldError{Type: "analytics.Track", Name: "Event"})
+
+		Expect(retrier.Enqueue(mockAnalytics, track)).NotTo(Succeed())
+		Expect(sleeps).To(BeEmpty())
+	})
+})
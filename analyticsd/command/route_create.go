@@ -1,22 +1,41 @@
 package command
 
 import (
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
 	"encoding/json"
 	"fmt"
 	"gopkg.in/segmentio/analytics-go.v3"
-	"log"
 	"runtime"
 	"time"
 )
 
 type RouteCreate struct {
 	CCClient        CloudControllerClient
-	AnalyticsClient analytics.Client
+	AnalyticsClient sink.Sink
 	TimeStamp       time.Time
 	UUID            string
 	Version         string
 	OSVersion       string
-	Logger          *log.Logger
+	Logger          *logging.Logger
+}
+
+func init() {
+	Register(Handler{
+		Name:   "RouteCreate",
+		Events: []string{"audit.route.create"},
+		New: func(_ string, deps Deps) Command {
+			return &RouteCreate{
+				CCClient:        deps.CCClient,
+				AnalyticsClient: deps.AnalyticsClient,
+				TimeStamp:       deps.TimeStamp,
+				UUID:            deps.UUID,
+				Version:         deps.Version,
+				OSVersion:       deps.OSVersion,
+				Logger:          deps.Logger,
+			}
+		},
+	})
 }
 
 func (c *RouteCreate) HandleResponse(body json.RawMessage) error {
@@ -26,7 +45,7 @@ func (c *RouteCreate) HandleResponse(body json.RawMessage) error {
 		"os_version":     c.OSVersion,
 	}
 
-	err := c.AnalyticsClient.Enqueue(analytics.Track{
+	err := enqueue(c.AnalyticsClient, analytics.Track{
 		UserId:     c.UUID,
 		Event:      "created route",
 		Timestamp:  c.TimeStamp,
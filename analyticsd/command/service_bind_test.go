@@ -3,11 +3,11 @@ package command_test
 import (
 	"code.cloudfoundry.org/cfdev/analyticsd/command"
 	"code.cloudfoundry.org/cfdev/analyticsd/command/mocks"
+	"code.cloudfoundry.org/cfdev/analyticsd/config"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	"gopkg.in/segmentio/analytics-go.v3"
-	"io/ioutil"
-	"log"
 	"runtime"
 	"time"
 )
@@ -26,13 +26,15 @@ var _ = Describe("ServiceBind", func() {
 		mockCCClient = mocks.NewMockCloudControllerClient(mockController)
 
 		cmd = &command.ServiceBind{
-			Logger:          log.New(ioutil.Discard, "", log.LstdFlags),
-			CCClient:        mockCCClient,
-			AnalyticsClient: mockAnalytics,
-			TimeStamp:       time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
-			UUID:            "some-user-uuid",
-			Version:         "some-version",
-			OSVersion:       "some-os-version",
+			Logger:           logging.Discard(),
+			CCClient:         mockCCClient,
+			AnalyticsClient:  mockAnalytics,
+			TimeStamp:        time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
+			UUID:             "some-user-uuid",
+			Version:          "some-version",
+			OSVersion:        "some-os-version",
+			ServiceLabels:    command.NewServiceLabels(mockCCClient, time.Minute),
+			ServiceWhitelist: config.DefaultServiceWhitelist,
 		}
 	})
 
@@ -44,6 +46,14 @@ var _ = Describe("ServiceBind", func() {
 		It("sends the service information to segment.io", func() {
 			MatchFetch(mockCCClient, "/v2/service_instances/some-service-instance-guid", `
 				{
+            		"entity": {
+						"service_plan_guid": "some-service-plan-guid"
+                    }
+				}
+				`)
+
+			MatchFetch(mockCCClient, "/v2/service_plans/some-service-plan-guid", `
+				{
             		"entity": {
 						"service_url": "/v2/some_service_url"
                     }
@@ -60,7 +70,7 @@ var _ = Describe("ServiceBind", func() {
 
 			mockAnalytics.EXPECT().Enqueue(analytics.Track{
 				UserId:    "some-user-uuid",
-				Event:     "app bound to service",
+				Event:     "service bound",
 				Timestamp: time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
 				Properties: map[string]interface{}{
 					"service":        "mysql",
@@ -91,6 +101,14 @@ var _ = Describe("ServiceBind", func() {
 		It("does not send the service information to segment.io", func() {
 			MatchFetch(mockCCClient, "/v2/service_instances/some-service-instance-guid", `
 				{
+            		"entity": {
+						"service_plan_guid": "some-service-plan-guid"
+                    }
+				}
+				`)
+
+			MatchFetch(mockCCClient, "/v2/service_plans/some-service-plan-guid", `
+				{
             		"entity": {
 						"service_url": "/v2/some_service_url"
                     }
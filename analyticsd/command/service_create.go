@@ -1,22 +1,45 @@
 package command
 
 import (
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
 	"encoding/json"
 	"fmt"
 	"gopkg.in/segmentio/analytics-go.v3"
-	"log"
 	"runtime"
 	"time"
 )
 
 type ServiceCreate struct {
-	CCClient        CloudControllerClient
-	AnalyticsClient analytics.Client
-	TimeStamp       time.Time
-	UUID            string
-	Version         string
-	OSVersion       string
-	Logger          *log.Logger
+	CCClient         CloudControllerClient
+	AnalyticsClient  sink.Sink
+	TimeStamp        time.Time
+	UUID             string
+	Version          string
+	OSVersion        string
+	Logger           *logging.Logger
+	ServiceLabels    *ServiceLabels
+	ServiceWhitelist []string
+}
+
+func init() {
+	Register(Handler{
+		Name:   "ServiceCreate",
+		Events: []string{"audit.service_instance.create"},
+		New: func(_ string, deps Deps) Command {
+			return &ServiceCreate{
+				CCClient:         deps.CCClient,
+				AnalyticsClient:  deps.AnalyticsClient,
+				TimeStamp:        deps.TimeStamp,
+				UUID:             deps.UUID,
+				Version:          deps.Version,
+				OSVersion:        deps.OSVersion,
+				Logger:           deps.Logger,
+				ServiceLabels:    deps.ServiceLabels,
+				ServiceWhitelist: deps.ServiceWhitelist,
+			}
+		},
+	})
 }
 
 func (c *ServiceCreate) HandleResponse(body json.RawMessage) error {
@@ -28,42 +51,23 @@ func (c *ServiceCreate) HandleResponse(body json.RawMessage) error {
 
 	json.Unmarshal(body, &metadata)
 
-	var urlResp struct {
-		Entity struct {
-			ServiceURL string `json:"service_url"`
-		}
-	}
-
-	path := "/v2/service_plans/" + metadata.Request.ServicePlanGuid
-	err := c.CCClient.Fetch(path, nil, &urlResp)
-	if err != nil {
-		return fmt.Errorf("failed to make request to: %s: %s", path, err)
-	}
-
-	var labelResp struct {
-		Entity struct {
-			Label string
-		}
-	}
-
-	path = urlResp.Entity.ServiceURL
-	err = c.CCClient.Fetch(path, nil, &labelResp)
+	label, err := c.ServiceLabels.ForPlan(metadata.Request.ServicePlanGuid)
 	if err != nil {
-		return fmt.Errorf("failed to make request to: %s: %s", path, err)
+		return err
 	}
 
-	if !serviceIsWhiteListed(labelResp.Entity.Label) {
+	if !serviceIsWhiteListed(c.ServiceWhitelist, label) {
 		return nil
 	}
 
 	var properties = analytics.Properties{
-		"service":        labelResp.Entity.Label,
+		"service":        label,
 		"os":             runtime.GOOS,
 		"plugin_version": c.Version,
 		"os_version":     c.OSVersion,
 	}
 
-	err = c.AnalyticsClient.Enqueue(analytics.Track{
+	err = enqueue(c.AnalyticsClient, analytics.Track{
 		UserId:     c.UUID,
 		Event:      "created service",
 		Timestamp:  c.TimeStamp,
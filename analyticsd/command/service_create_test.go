@@ -3,11 +3,11 @@ package command_test
 import (
 	"code.cloudfoundry.org/cfdev/analyticsd/command"
 	"code.cloudfoundry.org/cfdev/analyticsd/command/mocks"
+	"code.cloudfoundry.org/cfdev/analyticsd/config"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	"gopkg.in/segmentio/analytics-go.v3"
-	"io/ioutil"
-	"log"
 	"runtime"
 	"time"
 )
@@ -26,13 +26,15 @@ var _ = Describe("ServiceCreate", func() {
 		mockCCClient = mocks.NewMockCloudControllerClient(mockController)
 
 		cmd = &command.ServiceCreate{
-			Logger:          log.New(ioutil.Discard, "", log.LstdFlags),
-			CCClient:        mockCCClient,
-			AnalyticsClient: mockAnalytics,
-			TimeStamp:       time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
-			UUID:            "some-user-uuid",
-			Version:         "some-version",
-			OSVersion:       "some-os-version",
+			Logger:           logging.Discard(),
+			CCClient:         mockCCClient,
+			AnalyticsClient:  mockAnalytics,
+			TimeStamp:        time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
+			UUID:             "some-user-uuid",
+			Version:          "some-version",
+			OSVersion:        "some-os-version",
+			ServiceLabels:    command.NewServiceLabels(mockCCClient, time.Minute),
+			ServiceWhitelist: config.DefaultServiceWhitelist,
 		}
 	})
 
@@ -109,4 +111,47 @@ var _ = Describe("ServiceCreate", func() {
 			cmd.HandleResponse(body)
 		})
 	})
+
+	Context("when the service is added to the whitelist", func() {
+		It("sends the service information to segment.io", func() {
+			cmd.ServiceWhitelist = []string{"my-special-sql"}
+
+			MatchFetch(mockCCClient, "/v2/service_plans/some-service-plan-guid", `
+				{
+					"entity": {
+						"service_url": "/v2/some_service_url"
+					}
+				}
+				`)
+
+			MatchFetch(mockCCClient, "/v2/some_service_url", `
+				{
+					"entity": {
+						"label": "my-special-sql"
+					}
+				}
+				`)
+
+			mockAnalytics.EXPECT().Enqueue(analytics.Track{
+				UserId:    "some-user-uuid",
+				Event:     "created service",
+				Timestamp: time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
+				Properties: map[string]interface{}{
+					"service":        "my-special-sql",
+					"os":             runtime.GOOS,
+					"plugin_version": "some-version",
+					"os_version":     "some-os-version",
+				},
+			})
+
+			body := []byte(`
+			{
+				"request": {
+					"service_plan_guid": "some-service-plan-guid"
+				}
+			}`)
+
+			cmd.HandleResponse(body)
+		})
+	})
 })
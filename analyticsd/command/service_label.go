@@ -0,0 +1,98 @@
+package command
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ServiceLabelTTL is how long the label of a plan's service is reused
+// before it is looked up again.
+const ServiceLabelTTL = 10 * time.Minute
+
+// ServiceLabels looks up the label of the service offering a plan, e.g.
+// p-mysql, which is all that is sent about a service. Labels are kept for
+// TTL, so that every service created from the same plan does not cost two
+// more requests to the Cloud Controller. It is shared by the handlers of
+// every event.
+type ServiceLabels struct {
+	CCClient CloudControllerClient
+	TTL      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]serviceLabelEntry
+}
+
+type serviceLabelEntry struct {
+	label   string
+	expires time.Time
+}
+
+func NewServiceLabels(ccClient CloudControllerClient, ttl time.Duration) *ServiceLabels {
+	return &ServiceLabels{
+		CCClient: ccClient,
+		TTL:      ttl,
+		entries:  map[string]serviceLabelEntry{},
+	}
+}
+
+// ForPlan returns the label of the service offering the plan.
+func (s *ServiceLabels) ForPlan(servicePlanGuid string) (string, error) {
+	s.mu.Lock()
+	entry, ok := s.entries[servicePlanGuid]
+	s.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.label, nil
+	}
+
+	var urlResp struct {
+		Entity struct {
+			ServiceURL string `json:"service_url"`
+		}
+	}
+
+	path := "/v2/service_plans/" + servicePlanGuid
+	err := s.CCClient.Fetch(path, nil, &urlResp)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request to: %s: %s", path, err)
+	}
+
+	var labelResp struct {
+		Entity struct {
+			Label string
+		}
+	}
+
+	path = urlResp.Entity.ServiceURL
+	err = s.CCClient.Fetch(path, nil, &labelResp)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request to: %s: %s", path, err)
+	}
+
+	s.mu.Lock()
+	s.entries[servicePlanGuid] = serviceLabelEntry{
+		label:   labelResp.Entity.Label,
+		expires: time.Now().Add(s.TTL),
+	}
+	s.mu.Unlock()
+
+	return labelResp.Entity.Label, nil
+}
+
+// ForInstance returns the label of the service a service instance was
+// created from. Only the instance's plan is looked up every time.
+func (s *ServiceLabels) ForInstance(serviceInstanceGuid string) (string, error) {
+	var planResp struct {
+		Entity struct {
+			ServicePlanGuid string `json:"service_plan_guid"`
+		}
+	}
+
+	path := "/v2/service_instances/" + serviceInstanceGuid
+	err := s.CCClient.Fetch(path, nil, &planResp)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request to: %s: %s", path, err)
+	}
+
+	return s.ForPlan(planResp.Entity.ServicePlanGuid)
+}
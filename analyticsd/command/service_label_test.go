@@ -0,0 +1,73 @@
+package command_test
+
+import (
+	"code.cloudfoundry.org/cfdev/analyticsd/command"
+	"code.cloudfoundry.org/cfdev/analyticsd/command/mocks"
+	"errors"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("ServiceLabels", func() {
+	var (
+		mockController *gomock.Controller
+		mockCCClient   *mocks.MockCloudControllerClient
+		labels         *command.ServiceLabels
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockCCClient = mocks.NewMockCloudControllerClient(mockController)
+		labels = command.NewServiceLabels(mockCCClient, time.Minute)
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	expectLookup := func(planGuid, label string) {
+		MatchFetch(mockCCClient, "/v2/service_plans/"+planGuid, `{"entity": {"service_url": "/v2/services/`+planGuid+`"}}`)
+		MatchFetch(mockCCClient, "/v2/services/"+planGuid, `{"entity": {"label": "`+label+`"}}`)
+	}
+
+	It("looks up each plan's label only once", func() {
+		expectLookup("some-plan-guid", "p-mysql")
+		expectLookup("other-plan-guid", "p-redis")
+
+		Expect(labels.ForPlan("some-plan-guid")).To(Equal("p-mysql"))
+		Expect(labels.ForPlan("other-plan-guid")).To(Equal("p-redis"))
+		Expect(labels.ForPlan("some-plan-guid")).To(Equal("p-mysql"))
+	})
+
+	It("looks up labels again once they expire", func() {
+		labels.TTL = time.Millisecond
+
+		expectLookup("some-plan-guid", "p-mysql")
+		Expect(labels.ForPlan("some-plan-guid")).To(Equal("p-mysql"))
+
+		time.Sleep(5 * time.Millisecond)
+
+		expectLookup("some-plan-guid", "p.mysql")
+		Expect(labels.ForPlan("some-plan-guid")).To(Equal("p.mysql"))
+	})
+
+	It("does not keep failed lookups", func() {
+		mockCCClient.EXPECT().Fetch("/v2/service_plans/some-plan-guid", nil, gomock.Any()).Return(errors.New("some-error"))
+		_, err := labels.ForPlan("some-plan-guid")
+		Expect(err).To(MatchError(ContainSubstring("some-error")))
+
+		expectLookup("some-plan-guid", "p-mysql")
+		Expect(labels.ForPlan("some-plan-guid")).To(Equal("p-mysql"))
+	})
+
+	It("looks up the plan of service instances", func() {
+		MatchFetch(mockCCClient, "/v2/service_instances/some-instance-guid", `{"entity": {"service_plan_guid": "some-plan-guid"}}`)
+		expectLookup("some-plan-guid", "p-mysql")
+		Expect(labels.ForInstance("some-instance-guid")).To(Equal("p-mysql"))
+
+		MatchFetch(mockCCClient, "/v2/service_instances/some-instance-guid", `{"entity": {"service_plan_guid": "some-plan-guid"}}`)
+		Expect(labels.ForInstance("some-instance-guid")).To(Equal("p-mysql"))
+	})
+})
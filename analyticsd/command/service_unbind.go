@@ -0,0 +1,82 @@
+package command
+
+import (
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/segmentio/analytics-go.v3"
+	"runtime"
+	"time"
+)
+
+type ServiceUnbind struct {
+	CCClient         CloudControllerClient
+	AnalyticsClient  sink.Sink
+	TimeStamp        time.Time
+	UUID             string
+	Version          string
+	OSVersion        string
+	Logger           *logging.Logger
+	ServiceLabels    *ServiceLabels
+	ServiceWhitelist []string
+}
+
+func init() {
+	Register(Handler{
+		Name:   "ServiceUnbind",
+		Events: []string{"audit.service_binding.delete"},
+		New: func(_ string, deps Deps) Command {
+			return &ServiceUnbind{
+				CCClient:         deps.CCClient,
+				AnalyticsClient:  deps.AnalyticsClient,
+				TimeStamp:        deps.TimeStamp,
+				UUID:             deps.UUID,
+				Version:          deps.Version,
+				OSVersion:        deps.OSVersion,
+				Logger:           deps.Logger,
+				ServiceLabels:    deps.ServiceLabels,
+				ServiceWhitelist: deps.ServiceWhitelist,
+			}
+		},
+	})
+}
+
+func (c *ServiceUnbind) HandleResponse(body json.RawMessage) error {
+	var metadata struct {
+		Request struct {
+			ServiceInstanceGuid string `json:"service_instance_guid"`
+		}
+	}
+
+	json.Unmarshal(body, &metadata)
+
+	label, err := c.ServiceLabels.ForInstance(metadata.Request.ServiceInstanceGuid)
+	if err != nil {
+		return err
+	}
+
+	if !serviceIsWhiteListed(c.ServiceWhitelist, label) {
+		return nil
+	}
+
+	var properties = analytics.Properties{
+		"service":        label,
+		"os":             runtime.GOOS,
+		"plugin_version": c.Version,
+		"os_version":     c.OSVersion,
+	}
+
+	err = enqueue(c.AnalyticsClient, analytics.Track{
+		UserId:     c.UUID,
+		Event:      "service unbound",
+		Timestamp:  c.TimeStamp,
+		Properties: properties,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to send analytics: %v", err)
+	}
+
+	return nil
+}
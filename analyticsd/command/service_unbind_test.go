@@ -0,0 +1,132 @@
+package command_test
+
+import (
+	"code.cloudfoundry.org/cfdev/analyticsd/command"
+	"code.cloudfoundry.org/cfdev/analyticsd/command/mocks"
+	"code.cloudfoundry.org/cfdev/analyticsd/config"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"gopkg.in/segmentio/analytics-go.v3"
+	"runtime"
+	"time"
+)
+
+var _ = Describe("ServiceUnbind", func() {
+	var (
+		cmd            *command.ServiceUnbind
+		mockController *gomock.Controller
+		mockAnalytics  *mocks.MockClient
+		mockCCClient   *mocks.MockCloudControllerClient
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockAnalytics = mocks.NewMockClient(mockController)
+		mockCCClient = mocks.NewMockCloudControllerClient(mockController)
+
+		cmd = &command.ServiceUnbind{
+			Logger:           logging.Discard(),
+			CCClient:         mockCCClient,
+			AnalyticsClient:  mockAnalytics,
+			TimeStamp:        time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
+			UUID:             "some-user-uuid",
+			Version:          "some-version",
+			OSVersion:        "some-os-version",
+			ServiceLabels:    command.NewServiceLabels(mockCCClient, time.Minute),
+			ServiceWhitelist: config.DefaultServiceWhitelist,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	Context("when the service instance is whitelisted", func() {
+		It("sends the service information to segment.io", func() {
+			MatchFetch(mockCCClient, "/v2/service_instances/some-service-instance-guid", `
+				{
+            		"entity": {
+						"service_plan_guid": "some-service-plan-guid"
+                    }
+				}
+				`)
+
+			MatchFetch(mockCCClient, "/v2/service_plans/some-service-plan-guid", `
+				{
+            		"entity": {
+						"service_url": "/v2/some_service_url"
+                    }
+				}
+				`)
+
+			MatchFetch(mockCCClient, "/v2/some_service_url", `
+				{
+            		"entity": {
+						"label": "mysql"
+                    }
+				}
+				`)
+
+			mockAnalytics.EXPECT().Enqueue(analytics.Track{
+				UserId:    "some-user-uuid",
+				Event:     "service unbound",
+				Timestamp: time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
+				Properties: map[string]interface{}{
+					"service":        "mysql",
+					"os":             runtime.GOOS,
+					"plugin_version": "some-version",
+					"os_version":     "some-os-version",
+				},
+			})
+
+			body := []byte(`
+			{
+				"request": {
+					"app_guid": "some-app-guid",
+					"service_instance_guid": "some-service-instance-guid"
+				}
+			}`)
+
+			cmd.HandleResponse(body)
+		})
+	})
+
+	Context("when the service instance is NOT whitelisted", func() {
+		It("does not send the service information to segment.io", func() {
+			MatchFetch(mockCCClient, "/v2/service_instances/some-service-instance-guid", `
+				{
+            		"entity": {
+						"service_plan_guid": "some-service-plan-guid"
+                    }
+				}
+				`)
+
+			MatchFetch(mockCCClient, "/v2/service_plans/some-service-plan-guid", `
+				{
+            		"entity": {
+						"service_url": "/v2/some_service_url"
+                    }
+				}
+				`)
+
+			MatchFetch(mockCCClient, "/v2/some_service_url", `
+				{
+            		"entity": {
+						"label": "non-white-listed-service"
+                    }
+				}
+				`)
+
+			body := []byte(`
+			{
+				"request": {
+					"app_guid": "some-app-guid",
+					"service_instance_guid": "some-service-instance-guid"
+				}
+			}`)
+
+			cmd.HandleResponse(body)
+		})
+	})
+})
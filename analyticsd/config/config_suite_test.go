@@ -1,13 +1,13 @@
-package toggle_test
+package config_test
 
 import (
+	"testing"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
-
-	"testing"
 )
 
-func TestToggle(t *testing.T) {
+func TestConfig(t *testing.T) {
 	RegisterFailHandler(Fail)
-	RunSpecs(t, "Toggle Suite")
+	RunSpecs(t, "Config Suite")
 }
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CFDevHome is where cf dev keeps its state, which analyticsd reads its
+// configuration from. It matches the plugin's CFDEV_HOME handling,
+// following the home to where cf dev config set home moved it.
+func CFDevHome() string {
+	cfdevHome := os.Getenv("CFDEV_HOME")
+	if cfdevHome == "" && runtime.GOOS == "windows" {
+		cfdevHome = filepath.Join(os.Getenv("HOMEDRIVE"), os.Getenv("HOMEPATH"), ".cfdev")
+	} else if cfdevHome == "" {
+		cfdevHome = filepath.Join(os.Getenv("HOME"), ".cfdev")
+	}
+	return movedHome(cfdevHome)
+}
+
+// movedHome is where the config file in cfdevHome says the home was
+// moved to, if anywhere.
+func movedHome(cfdevHome string) string {
+	contents, err := ioutil.ReadFile(filepath.Join(cfdevHome, "config.yml"))
+	if err != nil {
+		return cfdevHome
+	}
+
+	var file struct {
+		Home string `yaml:"home"`
+	}
+	if err := yaml.Unmarshal(contents, &file); err != nil || file.Home == "" {
+		return cfdevHome
+	}
+	return file.Home
+}
+
+// CursorPath is where analyticsd keeps the last event it processed, so
+// that it picks up from there when restarted.
+func CursorPath(cfdevHome string) string {
+	return filepath.Join(cfdevHome, "analytics", "cursor.json")
+}
@@ -0,0 +1,38 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CFDevHome", func() {
+	var cfdevHome string
+
+	BeforeEach(func() {
+		var err error
+		cfdevHome, err = ioutil.TempDir("", "cfdev-analyticsd-home")
+		Expect(err).NotTo(HaveOccurred())
+		os.Setenv("CFDEV_HOME", cfdevHome)
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("CFDEV_HOME")
+		os.RemoveAll(cfdevHome)
+	})
+
+	It("is CFDEV_HOME", func() {
+		Expect(config.CFDevHome()).To(Equal(cfdevHome))
+	})
+
+	It("follows the home to where it was moved", func() {
+		contents := "memory: 8192\nhome: /mnt/bigger-drive/cfdev\n"
+		Expect(ioutil.WriteFile(filepath.Join(cfdevHome, "config.yml"), []byte(contents), 0644)).To(Succeed())
+
+		Expect(config.CFDevHome()).To(Equal("/mnt/bigger-drive/cfdev"))
+	})
+})
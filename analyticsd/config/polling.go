@@ -0,0 +1,86 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// PollIntervalEnv and PollJitterEnv override the polling file, e.g.
+	// CFDEV_ANALYTICS_POLL_INTERVAL=30m.
+	PollIntervalEnv = "CFDEV_ANALYTICS_POLL_INTERVAL"
+	PollJitterEnv   = "CFDEV_ANALYTICS_POLL_JITTER"
+)
+
+// Polling is how often analyticsd asks the Cloud Controller for events.
+// Each wait is lengthened by up to Jitter, so that polls do not line up
+// with other periodic load on the machine.
+type Polling struct {
+	Interval time.Duration
+	Jitter   time.Duration
+}
+
+var DefaultPolling = Polling{Interval: 10 * time.Minute, Jitter: time.Minute}
+
+// PollingPath is the file the polling is read from, e.g.
+// {"interval": "30m", "jitter": "5m"}.
+func PollingPath(cfdevHome string) string {
+	return filepath.Join(cfdevHome, "analytics-polling.json")
+}
+
+// LoadPolling returns defaults overridden by the file at path, if there is
+// one, and then by PollIntervalEnv and PollJitterEnv.
+func LoadPolling(path string, defaults Polling) (Polling, error) {
+	polling := defaults
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return Polling{}, err
+	} else if err == nil {
+		var file struct {
+			Interval string `json:"interval"`
+			Jitter   string `json:"jitter"`
+		}
+		if err := json.Unmarshal(contents, &file); err != nil {
+			return Polling{}, fmt.Errorf("parsing %s: %s", filepath.Base(path), err)
+		}
+		if err := parseDuration(file.Interval, &polling.Interval); err != nil {
+			return Polling{}, err
+		}
+		if err := parseDuration(file.Jitter, &polling.Jitter); err != nil {
+			return Polling{}, err
+		}
+	}
+
+	if err := parseDuration(os.Getenv(PollIntervalEnv), &polling.Interval); err != nil {
+		return Polling{}, err
+	}
+	if err := parseDuration(os.Getenv(PollJitterEnv), &polling.Jitter); err != nil {
+		return Polling{}, err
+	}
+
+	if polling.Interval <= 0 {
+		return Polling{}, fmt.Errorf("the polling interval must be positive, not %s", polling.Interval)
+	}
+	if polling.Jitter < 0 {
+		return Polling{}, fmt.Errorf("the polling jitter must not be negative, not %s", polling.Jitter)
+	}
+	return polling, nil
+}
+
+// parseDuration sets dest to value, unless value is empty.
+func parseDuration(value string, dest *time.Duration) error {
+	if value == "" {
+		return nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid polling duration %q: %s", value, err)
+	}
+	*dest = duration
+	return nil
+}
@@ -0,0 +1,65 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadPolling", func() {
+	var (
+		dir  string
+		path string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "polling")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "analytics-polling.json")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+		os.Unsetenv(config.PollIntervalEnv)
+		os.Unsetenv(config.PollJitterEnv)
+	})
+
+	It("defaults to the polling given", func() {
+		Expect(config.LoadPolling(path, config.DefaultPolling)).To(Equal(config.DefaultPolling))
+	})
+
+	It("reads the polling from the file", func() {
+		Expect(ioutil.WriteFile(path, []byte(`{"interval": "30m", "jitter": "5m"}`), 0644)).To(Succeed())
+
+		Expect(config.LoadPolling(path, config.DefaultPolling)).To(Equal(config.Polling{Interval: 30 * time.Minute, Jitter: 5 * time.Minute}))
+	})
+
+	It("lets the environment override the file", func() {
+		Expect(ioutil.WriteFile(path, []byte(`{"interval": "30m"}`), 0644)).To(Succeed())
+		os.Setenv(config.PollIntervalEnv, "2s")
+		os.Setenv(config.PollJitterEnv, "0s")
+
+		Expect(config.LoadPolling(path, config.DefaultPolling)).To(Equal(config.Polling{Interval: 2 * time.Second}))
+	})
+
+	It("fails for invalid durations", func() {
+		os.Setenv(config.PollIntervalEnv, "often")
+		_, err := config.LoadPolling(path, config.DefaultPolling)
+		Expect(err).To(MatchError(ContainSubstring(`invalid polling duration "often"`)))
+
+		os.Setenv(config.PollIntervalEnv, "0s")
+		_, err = config.LoadPolling(path, config.DefaultPolling)
+		Expect(err).To(MatchError(ContainSubstring("must be positive")))
+
+		os.Setenv(config.PollIntervalEnv, "1m")
+		os.Setenv(config.PollJitterEnv, "-1m")
+		_, err = config.LoadPolling(path, config.DefaultPolling)
+		Expect(err).To(MatchError(ContainSubstring("must not be negative")))
+	})
+})
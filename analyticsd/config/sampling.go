@@ -0,0 +1,64 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// EventsPerMinuteEnv overrides the cap of the sampling file, e.g.
+// CFDEV_ANALYTICS_EVENTS_PER_MINUTE=10.
+const EventsPerMinuteEnv = "CFDEV_ANALYTICS_EVENTS_PER_MINUTE"
+
+// Sampling thins out the events of a noisy workstation, e.g. one running
+// test loops against cf dev. Only one in Rates[type] of the Cloud
+// Controller events of a type are handled, and no more than PerMinute
+// events altogether. A PerMinute of 0 does not cap them.
+type Sampling struct {
+	Rates     map[string]int `json:"rates"`
+	PerMinute int            `json:"perMinute"`
+}
+
+var DefaultSampling = Sampling{PerMinute: 60}
+
+// SamplingPath is the file the sampling is read from, e.g.
+// {"rates": {"audit.app.create": 10}, "perMinute": 30}.
+func SamplingPath(cfdevHome string) string {
+	return filepath.Join(cfdevHome, "analytics-sampling.json")
+}
+
+// LoadSampling returns defaults overridden by the file at path, if there
+// is one, and then by EventsPerMinuteEnv.
+func LoadSampling(path string, defaults Sampling) (Sampling, error) {
+	sampling := defaults
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return Sampling{}, err
+	} else if err == nil {
+		if err := json.Unmarshal(contents, &sampling); err != nil {
+			return Sampling{}, fmt.Errorf("parsing %s: %s", filepath.Base(path), err)
+		}
+	}
+
+	if value := os.Getenv(EventsPerMinuteEnv); value != "" {
+		perMinute, err := strconv.Atoi(value)
+		if err != nil {
+			return Sampling{}, fmt.Errorf("invalid events per minute %q: %s", value, err)
+		}
+		sampling.PerMinute = perMinute
+	}
+
+	for eventType, rate := range sampling.Rates {
+		if rate < 1 {
+			return Sampling{}, fmt.Errorf("the sampling rate of %s must be at least 1, not %d", eventType, rate)
+		}
+	}
+	if sampling.PerMinute < 0 {
+		return Sampling{}, fmt.Errorf("the events per minute must not be negative, not %d", sampling.PerMinute)
+	}
+	return sampling, nil
+}
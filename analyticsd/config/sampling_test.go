@@ -0,0 +1,63 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadSampling", func() {
+	var (
+		dir  string
+		path string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "sampling")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "analytics-sampling.json")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+		os.Unsetenv(config.EventsPerMinuteEnv)
+	})
+
+	It("defaults to the sampling given", func() {
+		Expect(config.LoadSampling(path, config.DefaultSampling)).To(Equal(config.DefaultSampling))
+	})
+
+	It("reads the sampling from the file, which the environment overrides", func() {
+		Expect(ioutil.WriteFile(path, []byte(`{"rates": {"audit.app.create": 10}}`), 0644)).To(Succeed())
+
+		Expect(config.LoadSampling(path, config.DefaultSampling)).To(Equal(config.Sampling{
+			Rates:     map[string]int{"audit.app.create": 10},
+			PerMinute: 60,
+		}))
+
+		os.Setenv(config.EventsPerMinuteEnv, "0")
+		Expect(config.LoadSampling(path, config.DefaultSampling)).To(Equal(config.Sampling{
+			Rates: map[string]int{"audit.app.create": 10},
+		}))
+	})
+
+	It("fails for invalid rates and caps", func() {
+		Expect(ioutil.WriteFile(path, []byte(`{"rates": {"audit.app.create": 0}}`), 0644)).To(Succeed())
+		_, err := config.LoadSampling(path, config.DefaultSampling)
+		Expect(err).To(MatchError(ContainSubstring("must be at least 1")))
+
+		Expect(os.Remove(path)).To(Succeed())
+		os.Setenv(config.EventsPerMinuteEnv, "lots")
+		_, err = config.LoadSampling(path, config.DefaultSampling)
+		Expect(err).To(MatchError(ContainSubstring(`invalid events per minute "lots"`)))
+
+		os.Setenv(config.EventsPerMinuteEnv, "-1")
+		_, err = config.LoadSampling(path, config.DefaultSampling)
+		Expect(err).To(MatchError(ContainSubstring("must not be negative")))
+	})
+})
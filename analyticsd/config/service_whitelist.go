@@ -1,10 +1,61 @@
 package config
 
-var (
-	SERVICE_WHITELIST = []string{
-		"mysql", "p-mysql", "p.mysql",
-		"rabbit", "rabbitmq", "p-rabbitmq", "p.rabbitmq",
-		"redis", "p-redis", "p.redis",
-		"p-circuit-breaker-dashboard", "p-config-server", "p-service-registry",
-	}
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
 )
+
+// ServiceWhitelistEnv adds service labels to the whitelist, separated by
+// commas, e.g. CFDEV_ANALYTICS_SERVICES=my-broker,p-other.
+const ServiceWhitelistEnv = "CFDEV_ANALYTICS_SERVICES"
+
+// DefaultServiceWhitelist is the labels of the services that cfdev ships.
+// Only events about whitelisted services are sent, so that the names of
+// custom brokers are not.
+var DefaultServiceWhitelist = []string{
+	"mysql", "p-mysql", "p.mysql",
+	"rabbit", "rabbitmq", "p-rabbitmq", "p.rabbitmq",
+	"redis", "p-redis", "p.redis",
+	"p-circuit-breaker-dashboard", "p-config-server", "p-service-registry",
+}
+
+// ServiceWhitelistPath is the file operators embedding cfdev can list
+// more service labels in, one per line. Lines starting with # are
+// ignored.
+func ServiceWhitelistPath(cfdevHome string) string {
+	return filepath.Join(cfdevHome, "analytics-services")
+}
+
+// LoadServiceWhitelist returns the default whitelist along with the labels
+// from the file at path, if there is one, and from ServiceWhitelistEnv.
+func LoadServiceWhitelist(path string) ([]string, error) {
+	whitelist := append([]string{}, DefaultServiceWhitelist...)
+
+	file, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	} else if err == nil {
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				whitelist = append(whitelist, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, label := range strings.Split(os.Getenv(ServiceWhitelistEnv), ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			whitelist = append(whitelist, label)
+		}
+	}
+
+	return whitelist, nil
+}
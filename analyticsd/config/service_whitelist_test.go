@@ -0,0 +1,42 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadServiceWhitelist", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "service-whitelist")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+		os.Unsetenv(config.ServiceWhitelistEnv)
+	})
+
+	It("defaults to the services cfdev ships", func() {
+		Expect(config.LoadServiceWhitelist(filepath.Join(dir, "missing"))).To(Equal(config.DefaultServiceWhitelist))
+	})
+
+	It("adds the services listed in the file and the environment", func() {
+		path := filepath.Join(dir, "analytics-services")
+		Expect(ioutil.WriteFile(path, []byte("# custom brokers\nmy-broker\n\n  other-broker  \n"), 0644)).To(Succeed())
+		os.Setenv(config.ServiceWhitelistEnv, "env-broker, ,another-broker")
+
+		whitelist, err := config.LoadServiceWhitelist(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(whitelist).To(HaveLen(len(config.DefaultServiceWhitelist) + 4))
+		Expect(whitelist).To(ContainElement("p-mysql"))
+		Expect(whitelist[len(config.DefaultServiceWhitelist):]).To(Equal([]string{"my-broker", "other-broker", "env-broker", "another-broker"}))
+	})
+})
@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type cursor struct {
+	Timestamp time.Time `json:"timestamp"`
+	// GUIDs are the events processed from the second of Timestamp.
+	GUIDs []string `json:"guids"`
+	// GUID is the single event a cursor saved by an earlier version kept.
+	GUID string `json:"guid,omitempty"`
+}
+
+func (d *Daemon) loadCursor() (cursor, bool) {
+	if d.CursorPath == "" {
+		return cursor{}, false
+	}
+
+	contents, err := ioutil.ReadFile(d.CursorPath)
+	if err != nil {
+		return cursor{}, false
+	}
+
+	var c cursor
+	if err := json.Unmarshal(contents, &c); err != nil || c.Timestamp.IsZero() {
+		return cursor{}, false
+	}
+	if c.GUID != "" {
+		c.GUIDs = append(c.GUIDs, c.GUID)
+		c.GUID = ""
+	}
+	return c, true
+}
+
+func (d *Daemon) saveCursor() {
+	if d.CursorPath == "" {
+		return
+	}
+
+	guids := []string{}
+	for guid := range d.sentGUIDs {
+		guids = append(guids, guid)
+	}
+	sort.Strings(guids)
+
+	contents, err := json.Marshal(cursor{Timestamp: d.lastTime, GUIDs: guids})
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.CursorPath), 0755); err != nil {
+		d.logger.Warn("Failed to save the event cursor", "path", d.CursorPath, "error", err)
+		return
+	}
+	if err := ioutil.WriteFile(d.CursorPath, contents, 0644); err != nil {
+		d.logger.Warn("Failed to save the event cursor", "path", d.CursorPath, "error", err)
+	}
+}
+
+func guidSet(guids []string) map[string]bool {
+	set := map[string]bool{}
+	for _, guid := range guids {
+		set[guid] = true
+	}
+	return set
+}
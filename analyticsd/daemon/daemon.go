@@ -3,25 +3,75 @@ package daemon
 import (
 	"code.cloudfoundry.org/cfdev/analyticsd/cloud_controller"
 	"code.cloudfoundry.org/cfdev/analyticsd/command"
+	"code.cloudfoundry.org/cfdev/analyticsd/config"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	"context"
 	"gopkg.in/segmentio/analytics-go.v3"
-	"io"
-	"log"
+	"math/rand"
 	"net/http"
+	"sync"
 	"time"
 )
 
 //go:generate mockgen -package mocks -destination mocks/analytics.go gopkg.in/segmentio/analytics-go.v3 Client
 
+// Replayer is a sink that keeps the events it could not deliver, to send
+// them again once it can.
+type Replayer interface {
+	Replay()
+}
+
+// Flusher is a sink that holds events until it is flushed.
+type Flusher interface {
+	Flush() error
+}
+
+// DefaultShutdownTimeout bounds the last poll when the daemon stops.
+const DefaultShutdownTimeout = 10 * time.Second
+
 type Daemon struct {
 	UUID            string
 	pluginVersion   string
 	osVersion       string
 	ccClient        *cloud_controller.Client
-	analyticsClient analytics.Client
+	serviceLabels   *command.ServiceLabels
+	analyticsClient sink.Sink
 	pollingInterval time.Duration
-	logger          *log.Logger
+	logger          *logging.Logger
 	lastTime        time.Time
-	doneChan        chan bool
+	// sentGUIDs are the events processed from the second of lastTime. Polls
+	// take the events from that second on, as the Cloud Controller has no
+	// finer timestamps, so these are passed over rather than sent again.
+	sentGUIDs  map[string]bool
+	doneChan   chan bool
+	mu         sync.Mutex
+	cancelPoll context.CancelFunc
+	pollMu     sync.Mutex
+
+	// CursorPath is where the last event processed is kept, so that a
+	// restarted daemon neither sends events again nor misses those from
+	// while it was down. Without it, events before the start are skipped.
+	CursorPath string
+
+	// Allowed reports whether the user still consents to telemetry. While it
+	// returns false the daemon keeps its place in the events but sends and
+	// replays nothing. Without it, every event is sent.
+	Allowed func() bool
+
+	// PollingJitter lengthens each wait between polls by a random amount up
+	// to it.
+	PollingJitter time.Duration
+
+	// ShutdownTimeout bounds the last poll made when the daemon stops.
+	ShutdownTimeout time.Duration
+
+	// Sampler decides which events are handled. Without it, every event is.
+	Sampler *Sampler
+
+	// ServiceWhitelist is the labels of the services whose events are
+	// sent. It defaults to the services cfdev ships.
+	ServiceWhitelist []string
 }
 
 func New(
@@ -29,76 +79,168 @@ func New(
 	UUID string,
 	pluginVersion string,
 	osVersion string,
-	writer io.Writer,
+	logger *logging.Logger,
 	httpClient *http.Client,
-	analyticsClient analytics.Client,
+	analyticsClient sink.Sink,
 	pollingInterval time.Duration,
 ) *Daemon {
-	logger := log.New(writer, "[ANALYTICSD] ", log.LstdFlags)
 	ccClient := cloud_controller.New(ccHost, logger, httpClient, analyticsClient, UUID, pluginVersion)
+	ccClient.EventTypes = command.Events()
 
 	return &Daemon{
-		UUID:            UUID,
-		pluginVersion:   pluginVersion,
-		osVersion:       osVersion,
-		ccClient:        ccClient,
-		analyticsClient: analyticsClient,
-		pollingInterval: pollingInterval,
-		logger:          logger,
-		doneChan:        make(chan bool, 1),
+		UUID:             UUID,
+		pluginVersion:    pluginVersion,
+		osVersion:        osVersion,
+		ccClient:         ccClient,
+		serviceLabels:    command.NewServiceLabels(ccClient, command.ServiceLabelTTL),
+		analyticsClient:  analyticsClient,
+		pollingInterval:  pollingInterval,
+		logger:           logger,
+		doneChan:         make(chan bool, 1),
+		ShutdownTimeout:  DefaultShutdownTimeout,
+		ServiceWhitelist: config.DefaultServiceWhitelist,
 	}
 }
 
 func (d *Daemon) Start() {
-	t := d.ccClient.FetchLatestTime()
+	// The events up to the latest are not sent, so that the first poll
+	// takes those from the next second on.
+	t := d.ccClient.FetchLatestTime().Truncate(time.Second).Add(time.Second)
+	if c, ok := d.loadCursor(); ok {
+		d.logger.Info("Resuming after the last event processed", "timestamp", c.Timestamp, "guids", c.GUIDs)
+		t = c.Timestamp
+		d.sentGUIDs = guidSet(c.GUIDs)
+	}
 	d.saveLatestTime(t)
-
-	ticker := time.NewTicker(d.pollingInterval)
+	d.saveCursor()
 
 	for {
 		select {
 		case <-d.doneChan:
 			return
-		case <-ticker.C:
-			err := d.do()
+		case <-time.After(d.nextPoll()):
+			err := d.do(context.Background())
 			if err != nil {
-				d.logger.Println(err)
+				d.logger.Error("Polling failed", "error", err)
 			}
 		}
 	}
 }
 
 func (d *Daemon) Stop() {
-	err := d.do()
+	// A poll that is waiting on the Cloud Controller is given up, so that
+	// the last one below does not wait behind it.
+	d.mu.Lock()
+	if d.cancelPoll != nil {
+		d.cancelPoll()
+	}
+	d.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.ShutdownTimeout)
+	defer cancel()
+	err := d.do(ctx)
 	if err != nil {
-		d.logger.Println(err)
+		d.logger.Error("The last poll failed", "error", err)
 	}
 	d.doneChan <- true
 }
 
-func (d *Daemon) do() error {
-	events, err := d.ccClient.FetchEvents(d.lastTime)
+// do polls for events. Polls never overlap, so that the last one, made
+// when the daemon stops, waits for one being given up.
+func (d *Daemon) do(ctx context.Context) error {
+	d.pollMu.Lock()
+	defer d.pollMu.Unlock()
+
+	allowed := d.Allowed == nil || d.Allowed()
+	if replayer, ok := d.analyticsClient.(Replayer); ok && allowed {
+		replayer.Replay()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	d.mu.Lock()
+	d.cancelPoll = cancel
+	d.mu.Unlock()
+	ccClient := d.ccClient.WithContext(ctx)
+
+	events, err := ccClient.FetchEvents(d.lastTime)
 	if err != nil {
 		return err
 	}
 
+	// The cursor moves past an event only once it is sent, so that an event
+	// that fails to send is fetched again by the next poll, along with those
+	// sent from the same second, which are passed over.
+	lastTime, sentGUIDs := d.lastTime, guidSet(nil)
+	for guid := range d.sentGUIDs {
+		sentGUIDs[guid] = true
+	}
 	for _, event := range events {
-		d.saveLatestTime(event.Timestamp)
-
-		cmd, exists := command.New(event.Type, d.ccClient, d.analyticsClient, event.Timestamp, d.UUID, d.pluginVersion, d.osVersion, d.logger)
-		if !exists {
+		if sentGUIDs[event.GUID] {
 			continue
 		}
-
-		err = cmd.HandleResponse(event.Metadata)
-		if err != nil {
-			return err
+		if allowed {
+			err = d.send(ccClient, event)
+			if _, malformed := err.(analytics.FieldError); malformed {
+				// It would never send, so it is passed over rather than
+				// holding back the events after it.
+				d.logger.Error("Skipping malformed event", "type", event.Type, "guid", event.GUID, "error", err)
+				err = nil
+			}
+			if err != nil {
+				break
+			}
+		}
+		if event.Timestamp.After(lastTime) {
+			lastTime = event.Timestamp
+			sentGUIDs = guidSet(nil)
 		}
+		if event.GUID != "" {
+			sentGUIDs[event.GUID] = true
+		}
+	}
+
+	if flushErr := d.flush(); flushErr != nil {
+		return flushErr
+	}
+	d.saveLatestTime(lastTime)
+	d.sentGUIDs = sentGUIDs
+	d.saveCursor()
+
+	return err
+}
+
+// send sends an event, unless it is sampled out or of a type that is not
+// sent.
+func (d *Daemon) send(ccClient *cloud_controller.Client, event cloud_controller.Event) error {
+	if d.Sampler != nil && !d.Sampler.Allow(event.Type, event.Timestamp) {
+		d.logger.Debug("Skipping sampled out event", "type", event.Type, "guid", event.GUID)
+		return nil
 	}
 
+	cmd, exists := command.New(event.Type, ccClient, d.analyticsClient, event.Timestamp, d.UUID, d.pluginVersion, d.osVersion, d.serviceLabels, d.ServiceWhitelist, d.logger)
+	if !exists {
+		return nil
+	}
+
+	return cmd.HandleResponse(event.Metadata)
+}
+
+// flush sends the events of a poll together, when the sink holds them.
+func (d *Daemon) flush() error {
+	if flusher, ok := d.analyticsClient.(Flusher); ok {
+		return flusher.Flush()
+	}
 	return nil
 }
 
+func (d *Daemon) nextPoll() time.Duration {
+	if d.PollingJitter <= 0 {
+		return d.pollingInterval
+	}
+	return d.pollingInterval + time.Duration(rand.Int63n(int64(d.PollingJitter)))
+}
+
 func (d *Daemon) saveLatestTime(t time.Time) {
 	if t.After(d.lastTime) {
 		d.lastTime = t
@@ -0,0 +1,192 @@
+package daemon
+
+import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/config"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	"code.cloudfoundry.org/cfdev/cfanalytics/consent"
+	"github.com/denisbrodbeck/machineid"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+const (
+	DefaultCCHost   = "https://api.dev.cfdev.sh"
+	DefaultTokenURL = "https://uaa.dev.cfdev.sh/oauth/token"
+)
+
+// Config is what Run needs to poll a cf dev's Cloud Controller and send
+// its events. Everything else is read from the files in CFDevHome, as
+// analyticsd does.
+type Config struct {
+	CFDevHome    string
+	AnalyticsKey string
+	Version      string
+	OSVersion    string
+	// UserID defaults to the machine's id.
+	UserID string
+	// CCHost and TokenURL default to those of cf dev.
+	CCHost   string
+	TokenURL string
+	// Properties are added to every event, e.g. the host's hardware class.
+	Properties analytics.Properties
+	// Debug polls every 10 seconds, unless the polling file says otherwise.
+	Debug bool
+	// Logger defaults to discarding the logs.
+	Logger *logging.Logger
+}
+
+// Run polls for events until ctx is done, then polls once more and sends
+// the events still held before returning. It is what analyticsd runs, so
+// that a program without a service manager to hand it to can run it too.
+func Run(ctx context.Context, cfg Config) error {
+	cfg = withDefaults(cfg)
+	logger := cfg.Logger
+
+	whitelist, err := config.LoadServiceWhitelist(config.ServiceWhitelistPath(cfg.CFDevHome))
+	if err != nil {
+		logger.Warn("Failed to load the service whitelist, using the default", "error", err)
+		whitelist = config.DefaultServiceWhitelist
+	}
+
+	defaultPolling := config.DefaultPolling
+	if cfg.Debug {
+		defaultPolling = config.Polling{Interval: 10 * time.Second}
+	}
+	polling, err := config.LoadPolling(config.PollingPath(cfg.CFDevHome), defaultPolling)
+	if err != nil {
+		logger.Warn("Failed to load the polling interval, using the default", "error", err)
+		polling = defaultPolling
+	}
+
+	sampling, err := config.LoadSampling(config.SamplingPath(cfg.CFDevHome), config.DefaultSampling)
+	if err != nil {
+		logger.Warn("Failed to load the sampling, using the default", "error", err)
+		sampling = config.DefaultSampling
+	}
+
+	analyticsSink := NewSink(cfg)
+
+	oauthConfig := &clientcredentials.Config{
+		ClientID:     "analytics",
+		ClientSecret: "analytics",
+		TokenURL:     cfg.TokenURL,
+	}
+	httpClient := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+	oauthCtx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+
+	analyticsDaemon := New(
+		cfg.CCHost,
+		cfg.UserID,
+		cfg.Version,
+		cfg.OSVersion,
+		logger,
+		oauthConfig.Client(oauthCtx),
+		analyticsSink,
+		polling.Interval,
+	)
+
+	analyticsDaemon.CursorPath = config.CursorPath(cfg.CFDevHome)
+	analyticsDaemon.PollingJitter = polling.Jitter
+	rand.Seed(time.Now().UnixNano())
+	analyticsDaemon.Sampler = NewSampler(sampling)
+	analyticsDaemon.ServiceWhitelist = whitelist
+	analyticsDaemon.Allowed = func() bool {
+		return consent.New(cfg.CFDevHome).Enabled()
+	}
+
+	go func() {
+		<-ctx.Done()
+		analyticsDaemon.Stop()
+	}()
+
+	logger.Info("Starting",
+		"pollingInterval", polling.Interval,
+		"pollingJitter", polling.Jitter,
+		"eventsPerMinute", sampling.PerMinute,
+		"version", cfg.Version,
+		"userID", cfg.UserID)
+	analyticsDaemon.Start()
+
+	// Closing the sink sends the events the Segment client still holds and
+	// queues those it cannot, but a stop must not wait on it for long.
+	closed := make(chan error, 1)
+	go func() {
+		closed <- analyticsSink.Close()
+	}()
+	select {
+	case err := <-closed:
+		return err
+	case <-time.After(analyticsDaemon.ShutdownTimeout):
+		logger.Warn("Gave up sending the remaining analytics", "after", analyticsDaemon.ShutdownTimeout)
+		return nil
+	}
+}
+
+// NewSink returns the sink analytics-sink.json in CFDevHome selects,
+// queueing the events it cannot send and batching those of each poll. A
+// sink that is misconfigured sends nothing.
+func NewSink(cfg Config) sink.Sink {
+	cfg = withDefaults(cfg)
+
+	sinkConfig, err := sink.LoadConfig(cfg.CFDevHome)
+	if err != nil {
+		cfg.Logger.Error("Failed to read the analytics sink configuration, not sending analytics", "error", err)
+		return sink.NoopSink{}
+	}
+	if sinkConfig.SegmentKey == "" {
+		sinkConfig.SegmentKey = cfg.AnalyticsKey
+	}
+	// Undelivered events are queued rather than logged on every poll.
+	sinkConfig.SegmentConfig.Logger = analytics.StdLogger(log.New(ioutil.Discard, "", 0))
+	sinkConfig.QueuePath = sink.QueuePath(cfg.CFDevHome)
+	// The events of a poll are sent together once it is done.
+	sinkConfig.Batch = true
+	sinkConfig.Properties = cfg.Properties
+
+	analyticsSink, err := sink.New(sinkConfig)
+	if err != nil {
+		cfg.Logger.Error("Failed to set up the analytics sink, not sending analytics", "error", err)
+		return sink.NoopSink{}
+	}
+	return analyticsSink
+}
+
+func withDefaults(cfg Config) Config {
+	if cfg.CCHost == "" {
+		cfg.CCHost = DefaultCCHost
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = DefaultTokenURL
+	}
+	if cfg.OSVersion == "" {
+		cfg.OSVersion = "unknown-os-version"
+	}
+	if cfg.UserID == "" {
+		userID, err := machineid.ProtectedID("cfdev")
+		if err != nil {
+			userID = "UNKNOWN_ID"
+		}
+		cfg.UserID = userID
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Discard()
+	}
+	return cfg
+}
@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/config"
+)
+
+// Sampler decides which Cloud Controller events are handled, so that a
+// noisy workstation neither uses up the Segment quota nor has each of its
+// events looked up on the Cloud Controller.
+type Sampler struct {
+	sampling config.Sampling
+	mu       sync.Mutex
+	seen     map[string]int
+	window   time.Time
+	inWindow int
+}
+
+func NewSampler(sampling config.Sampling) *Sampler {
+	return &Sampler{
+		sampling: sampling,
+		seen:     map[string]int{},
+	}
+}
+
+// Allow reports whether an event of eventType, which happened at
+// timestamp, is handled. The first of every Rates[eventType] events of the
+// type is, as long as fewer than PerMinute were allowed in the minute it
+// happened in. Going by when events happened rather than when they are
+// polled keeps a backlog fetched in one poll from counting as one minute.
+func (s *Sampler) Allow(eventType string, timestamp time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := s.seen[eventType]
+	s.seen[eventType] = seen + 1
+	if rate := s.sampling.Rates[eventType]; rate > 1 && seen%rate != 0 {
+		return false
+	}
+
+	if s.sampling.PerMinute == 0 {
+		return true
+	}
+	if window := timestamp.Truncate(time.Minute); !window.Equal(s.window) {
+		s.window = window
+		s.inWindow = 0
+	}
+	if s.inWindow >= s.sampling.PerMinute {
+		return false
+	}
+	s.inWindow++
+	return true
+}
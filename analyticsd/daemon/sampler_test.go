@@ -0,0 +1,53 @@
+package daemon_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/config"
+	"code.cloudfoundry.org/cfdev/analyticsd/daemon"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sampler", func() {
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Date(2018, 8, 8, 8, 8, 0, 0, time.UTC)
+	})
+
+	allowed := func(sampler *daemon.Sampler, eventType string, times int) int {
+		count := 0
+		for i := 0; i < times; i++ {
+			if sampler.Allow(eventType, now) {
+				count++
+			}
+		}
+		return count
+	}
+
+	It("allows every event without rates or a cap", func() {
+		sampler := daemon.NewSampler(config.Sampling{})
+
+		Expect(allowed(sampler, "audit.app.create", 500)).To(Equal(500))
+	})
+
+	It("allows one in each rate of the events of a type", func() {
+		sampler := daemon.NewSampler(config.Sampling{Rates: map[string]int{"audit.app.create": 10}})
+
+		Expect(sampler.Allow("audit.app.create", now)).To(BeTrue())
+		Expect(allowed(sampler, "audit.app.create", 9)).To(Equal(0))
+		Expect(sampler.Allow("audit.app.create", now)).To(BeTrue())
+		Expect(allowed(sampler, "audit.app.restage", 5)).To(Equal(5))
+	})
+
+	It("caps the events allowed each minute", func() {
+		sampler := daemon.NewSampler(config.Sampling{PerMinute: 3})
+
+		Expect(allowed(sampler, "audit.app.create", 5)).To(Equal(3))
+		Expect(allowed(sampler, "audit.app.restage", 5)).To(Equal(0))
+
+		now = now.Add(time.Minute)
+		Expect(allowed(sampler, "audit.app.restage", 5)).To(Equal(3))
+	})
+})
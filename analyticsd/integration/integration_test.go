@@ -1,15 +1,22 @@
 package integration
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
 
+	"code.cloudfoundry.org/cfdev/analyticsd/command"
+	"code.cloudfoundry.org/cfdev/analyticsd/config"
 	"code.cloudfoundry.org/cfdev/analyticsd/daemon"
 	"code.cloudfoundry.org/cfdev/analyticsd/daemon/mocks"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -42,7 +49,7 @@ var _ = Describe("Integration", func() {
 			"some-user-uuid",
 			"some-version",
 			"some-os-version",
-			buffer,
+			logging.New(buffer, logging.Debug),
 			httpClient,
 			mockAnalytics,
 			time.Second,
@@ -90,7 +97,7 @@ var _ = Describe("Integration", func() {
 								rawQuery := req.URL.RawQuery
 								Expect(rawQuery).To(ContainSubstring("audit.app.create"))
 								Expect(rawQuery).To(ContainSubstring("audit.service_instance.create"))
-								Expect(rawQuery).To(ContainSubstring("timestamp%3E2018-08-08T08%3A08%3A08Z"))
+								Expect(rawQuery).To(ContainSubstring("timestamp%3E%3D2018-08-08T08%3A08%3A09Z"))
 							},
 							ghttp.RespondWith(http.StatusOK, fakeResponse([]string{
 								fakePushEvent("2018-08-09T08:08:08Z", "ruby_buildpack"),
@@ -103,7 +110,7 @@ var _ = Describe("Integration", func() {
 								rawQuery := req.URL.RawQuery
 								Expect(rawQuery).To(ContainSubstring("audit.app.create"))
 								Expect(rawQuery).To(ContainSubstring("audit.service_instance.create"))
-								Expect(rawQuery).To(ContainSubstring("timestamp%3E2018-08-09T08%3A08%3A08Z"))
+								Expect(rawQuery).To(ContainSubstring("timestamp%3E%3D2018-08-09T08%3A08%3A08Z"))
 							},
 							ghttp.RespondWith(http.StatusOK, fakeResponse([]string{
 								fakePushEvent("2018-08-10T08:08:08Z", "java_buildpack"),
@@ -339,6 +346,11 @@ var _ = Describe("Integration", func() {
 
 					ccServer.AppendHandlers(ghttp.CombineHandlers(
 						ghttp.VerifyRequest(http.MethodGet, "/v2/service_instances/some-guid"),
+						ghttp.RespondWith(http.StatusOK, fakePlanResponse("some-service-plan-guid")),
+					))
+
+					ccServer.AppendHandlers(ghttp.CombineHandlers(
+						ghttp.VerifyRequest(http.MethodGet, "/v2/service_plans/some-service-plan-guid"),
 						ghttp.RespondWith(http.StatusOK, fakeUrlResponse("/some-service-url")),
 					))
 
@@ -355,7 +367,7 @@ var _ = Describe("Integration", func() {
 				It("sends the service bind event", func() {
 					mockAnalytics.EXPECT().Enqueue(analytics.Track{
 						UserId:    "some-user-uuid",
-						Event:     "app bound to service",
+						Event:     "service bound",
 						Timestamp: time.Date(2018, 8, 9, 8, 8, 8, 0, time.UTC),
 						Properties: map[string]interface{}{
 							"service":        "p-circuit-breaker-dashboard",
@@ -453,8 +465,339 @@ var _ = Describe("Integration", func() {
 			})
 		})
 	})
+
+	Describe("restarts", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "analyticsd")
+			Expect(err).NotTo(HaveOccurred())
+			aDaemon.CursorPath = filepath.Join(dir, "analytics", "cursor.json")
+
+			Expect(os.MkdirAll(filepath.Dir(aDaemon.CursorPath), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(aDaemon.CursorPath, []byte(`{"timestamp": "2018-08-01T08:08:08Z", "guid": "sent-guid"}`), 0644)).To(Succeed())
+
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{fakePushEvent("2018-08-10T08:08:08Z", "go_buildpack")})),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					func(w http.ResponseWriter, req *http.Request) {
+						Expect(req.URL.Query()["q"]).To(ContainElement("timestamp>=2018-08-01T08:08:08Z"))
+					},
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{
+						fakeEventWithGUID("sent-guid", "2018-08-01T08:08:08Z", "ruby_buildpack"),
+						fakeEventWithGUID("new-guid", "2018-08-02T08:08:08Z", "go_buildpack"),
+					})),
+				),
+			)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("picks up after the last event it processed", func() {
+			mockAnalytics.EXPECT().Enqueue(analytics.Track{
+				UserId:    "some-user-uuid",
+				Event:     "app created",
+				Timestamp: time.Date(2018, 8, 2, 8, 8, 8, 0, time.UTC),
+				Properties: map[string]interface{}{
+					"buildpack":      "go",
+					"os":             runtime.GOOS,
+					"plugin_version": "some-version",
+					"os_version":     "some-os-version",
+				},
+			})
+
+			startDaemon()
+			<-time.After(1030 * time.Millisecond)
+
+			contents, err := ioutil.ReadFile(aDaemon.CursorPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contents).To(MatchJSON(`{"timestamp": "2018-08-02T08:08:08Z", "guids": ["new-guid"]}`))
+
+			ccServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+				ghttp.RespondWith(http.StatusOK, fakeResponse([]string{})),
+			))
+			aDaemon.Stop()
+		})
+	})
+
+	Describe("a send fails", func() {
+		var attempts int
+
+		BeforeEach(func() {
+			attempts = command.DefaultRetrier.Attempts
+			command.DefaultRetrier.Attempts = 1
+
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{fakePushEvent("2018-08-01T08:08:08Z", "go_buildpack")})),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{
+						fakeEventWithGUID("sent-guid", "2018-08-02T08:08:08Z", "go_buildpack"),
+						fakeEventWithGUID("failed-guid", "2018-08-03T08:08:08Z", "ruby_buildpack"),
+					})),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					func(w http.ResponseWriter, req *http.Request) {
+						Expect(req.URL.Query()["q"]).To(ContainElement("timestamp>=2018-08-02T08:08:08Z"))
+					},
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{
+						fakeEventWithGUID("sent-guid", "2018-08-02T08:08:08Z", "go_buildpack"),
+						fakeEventWithGUID("failed-guid", "2018-08-03T08:08:08Z", "ruby_buildpack"),
+					})),
+				),
+			)
+		})
+
+		AfterEach(func() {
+			command.DefaultRetrier.Attempts = attempts
+		})
+
+		It("fetches the event again rather than moving past it", func() {
+			pushed := func(buildpack string, timestamp time.Time) analytics.Track {
+				return analytics.Track{
+					UserId:    "some-user-uuid",
+					Event:     "app created",
+					Timestamp: timestamp,
+					Properties: map[string]interface{}{
+						"buildpack":      buildpack,
+						"os":             runtime.GOOS,
+						"plugin_version": "some-version",
+						"os_version":     "some-os-version",
+					},
+				}
+			}
+			mockAnalytics.EXPECT().Enqueue(pushed("go", time.Date(2018, 8, 2, 8, 8, 8, 0, time.UTC)))
+			mockAnalytics.EXPECT().Enqueue(pushed("ruby", time.Date(2018, 8, 3, 8, 8, 8, 0, time.UTC))).Return(errors.New("some-error"))
+			mockAnalytics.EXPECT().Enqueue(pushed("ruby", time.Date(2018, 8, 3, 8, 8, 8, 0, time.UTC)))
+
+			startDaemon()
+			<-time.After(1030 * time.Millisecond)
+
+			aDaemon.Stop()
+		})
+	})
+
+	Describe("a send fails after another event from the same second is sent", func() {
+		var attempts int
+
+		BeforeEach(func() {
+			attempts = command.DefaultRetrier.Attempts
+			command.DefaultRetrier.Attempts = 1
+
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{fakePushEvent("2018-08-01T08:08:08Z", "go_buildpack")})),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{
+						fakeEventWithGUID("sent-guid", "2018-08-02T08:08:08Z", "go_buildpack"),
+						fakeEventWithGUID("failed-guid", "2018-08-02T08:08:08Z", "ruby_buildpack"),
+					})),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					func(w http.ResponseWriter, req *http.Request) {
+						Expect(req.URL.Query()["q"]).To(ContainElement("timestamp>=2018-08-02T08:08:08Z"))
+					},
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{
+						fakeEventWithGUID("sent-guid", "2018-08-02T08:08:08Z", "go_buildpack"),
+						fakeEventWithGUID("failed-guid", "2018-08-02T08:08:08Z", "ruby_buildpack"),
+					})),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{})),
+				),
+			)
+		})
+
+		AfterEach(func() {
+			command.DefaultRetrier.Attempts = attempts
+		})
+
+		It("sends the failed event on the next poll, and the sent one only once", func() {
+			pushed := func(buildpack string) analytics.Track {
+				return analytics.Track{
+					UserId:    "some-user-uuid",
+					Event:     "app created",
+					Timestamp: time.Date(2018, 8, 2, 8, 8, 8, 0, time.UTC),
+					Properties: map[string]interface{}{
+						"buildpack":      buildpack,
+						"os":             runtime.GOOS,
+						"plugin_version": "some-version",
+						"os_version":     "some-os-version",
+					},
+				}
+			}
+			gomock.InOrder(
+				mockAnalytics.EXPECT().Enqueue(pushed("go")),
+				mockAnalytics.EXPECT().Enqueue(pushed("ruby")).Return(errors.New("some-error")),
+				mockAnalytics.EXPECT().Enqueue(pushed("ruby")),
+			)
+
+			startDaemon()
+			<-time.After(2100 * time.Millisecond)
+
+			aDaemon.Stop()
+		})
+	})
+
+	Describe("sampling", func() {
+		BeforeEach(func() {
+			aDaemon.Sampler = daemon.NewSampler(config.Sampling{PerMinute: 1})
+
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{fakePushEvent("2018-08-01T08:08:08Z", "go_buildpack")})),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{
+						fakeEventWithGUID("first-guid", "2018-08-02T08:08:08Z", "go_buildpack"),
+						fakeEventWithGUID("second-guid", "2018-08-02T08:09:08Z", "ruby_buildpack"),
+						fakeEventWithGUID("third-guid", "2018-08-02T08:09:38Z", "java_buildpack"),
+					})),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{})),
+				),
+			)
+		})
+
+		It("limits the events by the minute they happened in, not the one they were polled in", func() {
+			for _, sent := range []struct {
+				buildpack string
+				timestamp time.Time
+			}{
+				{"go", time.Date(2018, 8, 2, 8, 8, 8, 0, time.UTC)},
+				{"ruby", time.Date(2018, 8, 2, 8, 9, 8, 0, time.UTC)},
+			} {
+				mockAnalytics.EXPECT().Enqueue(analytics.Track{
+					UserId:    "some-user-uuid",
+					Event:     "app created",
+					Timestamp: sent.timestamp,
+					Properties: map[string]interface{}{
+						"buildpack":      sent.buildpack,
+						"os":             runtime.GOOS,
+						"plugin_version": "some-version",
+						"os_version":     "some-os-version",
+					},
+				})
+			}
+
+			startDaemon()
+			<-time.After(1030 * time.Millisecond)
+
+			aDaemon.Stop()
+		})
+	})
+
+	Describe("stopping while the Cloud Controller hangs", func() {
+		var release chan struct{}
+
+		BeforeEach(func() {
+			release = make(chan struct{})
+			aDaemon.ShutdownTimeout = 200 * time.Millisecond
+
+			hang := func(w http.ResponseWriter, req *http.Request) {
+				<-release
+			}
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{})),
+				),
+				hang,
+				hang,
+			)
+		})
+
+		AfterEach(func() {
+			close(release)
+		})
+
+		It("gives up the polls and stops within the shutdown timeout", func() {
+			startDaemon()
+			<-time.After(1100 * time.Millisecond)
+
+			stopped := make(chan struct{})
+			go func() {
+				aDaemon.Stop()
+				close(stopped)
+			}()
+			Eventually(stopped, time.Second).Should(BeClosed())
+		})
+	})
+
+	Describe("consent is withdrawn", func() {
+		BeforeEach(func() {
+			aDaemon.Allowed = func() bool { return false }
+
+			ccServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{fakePushEvent("2018-08-08T08:08:08Z", "go_buildpack")})),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{
+						fakeEventWithGUID("skipped-guid", "2018-08-09T08:08:08Z", "go_buildpack"),
+					})),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/events"),
+					func(w http.ResponseWriter, req *http.Request) {
+						Expect(req.URL.Query()["q"]).To(ContainElement("timestamp>=2018-08-09T08:08:08Z"))
+					},
+					ghttp.RespondWith(http.StatusOK, fakeResponse([]string{
+						fakeEventWithGUID("skipped-guid", "2018-08-09T08:08:08Z", "go_buildpack"),
+					})),
+				),
+			)
+		})
+
+		It("sends nothing but keeps its place", func() {
+			startDaemon()
+			<-time.After(1030 * time.Millisecond)
+
+			aDaemon.Stop()
+		})
+	})
 })
 
+var guidPushEventTemplate = `
+{
+	"metadata": {
+		"guid": "%s"
+	},
+	"entity": {
+		"type": "audit.app.create",
+		"timestamp": "%s",
+		"metadata": {
+			"request": {
+				"buildpack": "%s"
+			}
+		}
+	}
+}
+`
+
 var pushAppEventTemplate = `
 {
 	"entity": {
@@ -542,6 +885,14 @@ var urlResponseTemplate = `
 }
 `
 
+var planResponseTemplate = `
+{
+	"entity": {
+		"service_plan_guid": "%s"
+	}
+}
+`
+
 var labelResponseTemplate = `
 {
 	"entity": {
@@ -554,6 +905,10 @@ func fakeEvent(eventType, timestamp, buildpack string) string {
 	return fmt.Sprintf(pushAppEventTemplate, eventType, timestamp, buildpack)
 }
 
+func fakeEventWithGUID(guid, timestamp, buildpack string) string {
+	return fmt.Sprintf(guidPushEventTemplate, guid, timestamp, buildpack)
+}
+
 func fakePushEvent(timestamp, buildpack string) string {
 	return fakeEvent("audit.app.create", timestamp, buildpack)
 }
@@ -578,6 +933,10 @@ func fakeUrlResponse(serviceURL string) string {
 	return fmt.Sprintf(urlResponseTemplate, serviceURL)
 }
 
+func fakePlanResponse(servicePlanGUID string) string {
+	return fmt.Sprintf(planResponseTemplate, servicePlanGUID)
+}
+
 func fakeLabelResponse(label string) string {
 	return fmt.Sprintf(labelResponseTemplate, label)
 }
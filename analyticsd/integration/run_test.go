@@ -0,0 +1,92 @@
+package integration
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/daemon"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	"code.cloudfoundry.org/cfdev/cfanalytics/consent"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+var _ = Describe("Run", func() {
+	var (
+		ccServer  *ghttp.Server
+		cfdevHome string
+		polls     int32
+	)
+
+	BeforeEach(func() {
+		var err error
+		cfdevHome, err = ioutil.TempDir("", "analyticsd-run")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ioutil.WriteFile(filepath.Join(cfdevHome, "analytics-sink.json"), []byte(`{"sink": "file"}`), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(cfdevHome, "analytics-polling.json"), []byte(`{"interval": "100ms", "jitter": "0s"}`), 0644)).To(Succeed())
+		os.Unsetenv("DO_NOT_TRACK")
+		Expect(consent.New(cfdevHome).SetCFAnalyticsEnabled(true)).To(Succeed())
+
+		polls = 0
+		ccServer = ghttp.NewServer()
+		ccServer.RouteToHandler(http.MethodPost, "/oauth/token", ghttp.RespondWith(http.StatusOK,
+			`{"access_token": "some-token", "token_type": "bearer", "expires_in": 3600}`,
+			http.Header{"Content-Type": []string{"application/json"}},
+		))
+		ccServer.RouteToHandler(http.MethodGet, "/v2/events", func(w http.ResponseWriter, req *http.Request) {
+			switch atomic.AddInt32(&polls, 1) {
+			case 1:
+				w.Write([]byte(fakeResponse([]string{fakePushEvent("2018-08-08T08:08:08Z", "some-buildpack")})))
+			case 2:
+				w.Write([]byte(fakeResponse([]string{fakePushEvent("2018-08-09T08:08:08Z", "go_buildpack")})))
+			default:
+				w.Write([]byte(fakeResponse([]string{})))
+			}
+		})
+	})
+
+	AfterEach(func() {
+		ccServer.Close()
+		os.RemoveAll(cfdevHome)
+	})
+
+	It("sends the events polled until the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- daemon.Run(ctx, daemon.Config{
+				CFDevHome:  cfdevHome,
+				Version:    "some-version",
+				OSVersion:  "some-os-version",
+				UserID:     "some-user-uuid",
+				CCHost:     ccServer.URL(),
+				TokenURL:   ccServer.URL() + "/oauth/token",
+				Properties: analytics.Properties{"cpu class": "3-4"},
+			})
+		}()
+
+		eventsPath := filepath.Join(cfdevHome, "analytics", "events.jsonl")
+		Eventually(func() ([]analytics.Message, error) {
+			return sink.ReadEvents(eventsPath)
+		}, 5*time.Second).Should(HaveLen(1))
+
+		cancel()
+		Eventually(done, 5*time.Second).Should(Receive(BeNil()))
+
+		msgs, err := sink.ReadEvents(eventsPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msgs).To(HaveLen(1))
+		track := msgs[0].(analytics.Track)
+		Expect(track.Event).To(Equal("app created"))
+		Expect(track.UserId).To(Equal("some-user-uuid"))
+		Expect(track.Properties).To(HaveKeyWithValue("buildpack", "go"))
+		Expect(track.Properties).To(HaveKeyWithValue("cpu class", "3-4"))
+	})
+})
@@ -0,0 +1,142 @@
+// Package logging is analyticsd's leveled logger. Each line is written as
+// key=value pairs, so that the log can be searched by field.
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+)
+
+// LevelEnv sets the lowest level logged, e.g.
+// CFDEV_ANALYTICS_LOG_LEVEL=debug. It defaults to info.
+const LevelEnv = "CFDEV_ANALYTICS_LOG_LEVEL"
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < Debug || l > Error {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses the name of a level, defaulting to info when it is
+// empty.
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return Info, nil
+	}
+	if name == "warning" {
+		return Warn, nil
+	}
+	for i, levelName := range levelNames {
+		if name == levelName {
+			return Level(i), nil
+		}
+	}
+	return Info, fmt.Errorf("unknown log level %q", name)
+}
+
+// Logger writes the messages at or above its level, along with its fields
+// and those given with each message.
+type Logger struct {
+	out    io.Writer
+	mu     *sync.Mutex
+	level  Level
+	fields []interface{}
+	now    func() time.Time
+}
+
+func New(out io.Writer, level Level) *Logger {
+	return &Logger{
+		out:   out,
+		mu:    &sync.Mutex{},
+		level: level,
+		now:   time.Now,
+	}
+}
+
+// Discard is a logger that writes nothing, for tests.
+func Discard() *Logger {
+	return New(ioutil.Discard, Error+1)
+}
+
+// With returns a logger that adds the key value pairs to every message.
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	child := *l
+	child.fields = append(append([]interface{}{}, l.fields...), keyvals...)
+	return &child
+}
+
+func (l *Logger) Debug(msg string, keyvals ...interface{}) { l.log(Debug, msg, keyvals) }
+func (l *Logger) Info(msg string, keyvals ...interface{})  { l.log(Info, msg, keyvals) }
+func (l *Logger) Warn(msg string, keyvals ...interface{})  { l.log(Warn, msg, keyvals) }
+func (l *Logger) Error(msg string, keyvals ...interface{}) { l.log(Error, msg, keyvals) }
+
+func (l *Logger) log(level Level, msg string, keyvals []interface{}) {
+	if l == nil || level < l.level {
+		return
+	}
+
+	var line bytes.Buffer
+	writeField(&line, "time", l.now().UTC().Format(time.RFC3339))
+	writeField(&line, "level", level.String())
+	writeField(&line, "msg", msg)
+	writeFields(&line, l.fields)
+	writeFields(&line, keyvals)
+	line.WriteByte('\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(line.Bytes())
+}
+
+func writeFields(line *bytes.Buffer, keyvals []interface{}) {
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if i+1 == len(keyvals) {
+			writeField(line, key, "MISSING")
+			break
+		}
+		writeField(line, key, keyvals[i+1])
+	}
+}
+
+func writeField(line *bytes.Buffer, key string, value interface{}) {
+	if line.Len() > 0 {
+		line.WriteByte(' ')
+	}
+
+	var text string
+	switch v := value.(type) {
+	case error:
+		text = v.Error()
+	case fmt.Stringer:
+		text = v.String()
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	line.WriteString(key)
+	line.WriteByte('=')
+	if text == "" || strings.ContainsAny(text, " \"=\t\n") {
+		line.WriteString(fmt.Sprintf("%q", text))
+	} else {
+		line.WriteString(text)
+	}
+}
@@ -0,0 +1,65 @@
+package logging_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Logger", func() {
+	var (
+		buffer *gbytes.Buffer
+		logger *logging.Logger
+	)
+
+	BeforeEach(func() {
+		buffer = gbytes.NewBuffer()
+		logger = logging.New(buffer, logging.Info)
+	})
+
+	It("writes messages as key value pairs", func() {
+		logger.Info("Detected event", "event", "audit.app.create", "count", 2)
+
+		Expect(buffer).To(gbytes.Say(`^time=\S+ level=info msg="Detected event" event=audit.app.create count=2\n$`))
+	})
+
+	It("quotes values with spaces and shows errors", func() {
+		logger.Error("Polling failed", "error", errors.New("connection refused"), "empty", "")
+
+		Expect(buffer).To(gbytes.Say(`level=error msg="Polling failed" error="connection refused" empty=""`))
+	})
+
+	It("adds the fields of the logger to every message", func() {
+		logger.With("component", "daemon").Warn("Slow", "took", "5s")
+
+		Expect(buffer).To(gbytes.Say(`level=warn msg=Slow component=daemon took=5s`))
+	})
+
+	It("skips messages below its level", func() {
+		logger.Debug("Making request")
+		logger.Info("Starting")
+
+		Expect(string(buffer.Contents())).NotTo(ContainSubstring("Making request"))
+		Expect(string(buffer.Contents())).To(ContainSubstring("Starting"))
+	})
+
+	It("marks a key without a value", func() {
+		logger.Info("Odd", "key")
+
+		Expect(buffer).To(gbytes.Say(`msg=Odd key=MISSING`))
+	})
+
+	Describe("ParseLevel", func() {
+		It("parses the names of levels", func() {
+			Expect(logging.ParseLevel("DEBUG")).To(Equal(logging.Debug))
+			Expect(logging.ParseLevel("warning")).To(Equal(logging.Warn))
+			Expect(logging.ParseLevel("")).To(Equal(logging.Info))
+
+			_, err := logging.ParseLevel("chatty")
+			Expect(err).To(MatchError(`unknown log level "chatty"`))
+		})
+	})
+})
@@ -3,46 +3,34 @@ package main
 import (
 	"code.cloudfoundry.org/cfdev/host"
 	"context"
-	"crypto/tls"
-	"fmt"
 	"gopkg.in/segmentio/analytics-go.v3"
-	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"syscall"
-	"time"
 
+	"code.cloudfoundry.org/cfdev/analyticsd/config"
 	"code.cloudfoundry.org/cfdev/analyticsd/daemon"
+	"code.cloudfoundry.org/cfdev/analyticsd/logging"
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/cfanalytics/consent"
+	"code.cloudfoundry.org/cfdev/crash"
+	"code.cloudfoundry.org/cfdev/logfile"
+	"code.cloudfoundry.org/cfdev/profiler"
 	"github.com/denisbrodbeck/machineid"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/clientcredentials"
 )
 
 var (
 	analyticsKey     string
 	testAnalyticsKey string
 	version          string
-	pollingInterval  = 10 * time.Minute
 )
 
 func main() {
-	cfg := &clientcredentials.Config{
-		ClientID:     "analytics",
-		ClientSecret: "analytics",
-		TokenURL:     "https://uaa.dev.cfdev.sh/oauth/token",
-	}
-
-	httpClient := &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		},
-	}
-
-	ctx := context.Background()
-	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+	logger := newLogger()
 
 	userID, err := machineid.ProtectedID("cfdev")
 	if err != nil {
@@ -55,36 +43,116 @@ func main() {
 		osVersion = "unknown-os-version"
 	}
 
-	if len(os.Args) > 1 && os.Args[1] == "debug" {
-		pollingInterval = 10 * time.Second
-	}
-
+	debug := len(os.Args) > 1 && os.Args[1] == "debug"
 	var analytixKey string
-	if (len(os.Args) > 1 && os.Args[1] == "debug") || analyticsKey == "" {
+	if debug || analyticsKey == "" {
 		analytixKey = testAnalyticsKey
 	} else {
 		analytixKey = analyticsKey
 	}
 
-	analyticsDaemon := daemon.New(
-		"https://api.dev.cfdev.sh",
-		userID,
-		version,
-		osVersion,
-		os.Stdout,
-		cfg.Client(ctx),
-		analytics.New(analytixKey),
-		pollingInterval,
-	)
+	cfg := daemon.Config{
+		CFDevHome:    config.CFDevHome(),
+		AnalyticsKey: analytixKey,
+		Version:      version,
+		OSVersion:    osVersion,
+		UserID:       userID,
+		Properties:   hostProperties(logger),
+		Debug:        debug,
+		Logger:       logger,
+	}
+	if domain := cfDomain(os.Args[1:]); domain != "" {
+		cfg.CCHost = "https://api." + domain
+		cfg.TokenURL = "https://uaa." + domain + "/oauth/token"
+	}
+
+	// Without the key, what could identify the user is redacted instead.
+	hashKey, _ := sink.HashKey(cfg.CFDevHome)
+	reporter := &crash.Reporter{
+		Program: "analyticsd",
+		Version: version,
+		Dir:     filepath.Join(cfg.CFDevHome, "crash"),
+		HashKey: hashKey,
+		Send: func(report crash.Report) error {
+			if !consent.New(cfg.CFDevHome).Enabled() {
+				return nil
+			}
+			analyticsSink := daemon.NewSink(cfg)
+			defer analyticsSink.Close()
+			return analyticsSink.Enqueue(analytics.Track{
+				UserId:    cfg.UserID,
+				Event:     sink.CrashEvent,
+				Timestamp: report.Time,
+				Properties: analytics.Properties{
+					"program":        report.Program,
+					"panic":          report.Panic,
+					"stack":          report.Stack,
+					"os":             runtime.GOOS,
+					"plugin_version": version,
+					"os_version":     osVersion,
+				},
+			})
+		},
+	}
+	defer reporter.Recover()
 
+	ctx, cancel := context.WithCancel(context.Background())
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-sigs
-		analyticsDaemon.Stop()
+		cancel()
 	}()
 
-	fmt.Printf("[ANALYTICSD] apiKeyLoaded: %t, pollingInterval: %v, version: %q, time: %v, userID: %q\n",
-		analyticsKey != "", pollingInterval, version, time.Now(), userID)
-	analyticsDaemon.Start()
+	if err := daemon.Run(ctx, cfg); err != nil {
+		logger.Error("Failed to send the remaining analytics", "error", err)
+	}
+}
+
+// cfDomain is the domain cf dev passes with --cf-domain, so that a cf
+// deployed under CFDEV_DOMAIN is polled where it is.
+func cfDomain(args []string) string {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--cf-domain=") {
+			return strings.TrimPrefix(arg, "--cf-domain=")
+		}
+	}
+	return ""
+}
+
+// hostProperties classes the host coarsely, so that events can be told
+// apart by the hardware they ran on without identifying it.
+func hostProperties(logger *logging.Logger) analytics.Properties {
+	profiler := &profiler.SystemProfiler{}
+	totalMemory, err := profiler.GetTotalMemory()
+	if err != nil {
+		logger.Warn("Failed to read the total memory", "error", err)
+	}
+	return analytics.Properties(cfanalytics.HostClass(totalMemory, runtime.NumCPU()))
+}
+
+const (
+	logMaxBytes = 10 * 1024 * 1024
+	logBackups  = 3
+)
+
+// newLogger logs to a file in the cfdev log directory, rotating it so that
+// it does not grow without bound, or to stdout if the file cannot be
+// opened.
+func newLogger() *logging.Logger {
+	level, levelErr := logging.ParseLevel(os.Getenv(logging.LevelEnv))
+
+	var logger *logging.Logger
+	file, err := logfile.Open(filepath.Join(config.CFDevHome(), "log", "analyticsd.log"), logMaxBytes, logBackups)
+	if err != nil {
+		logger = logging.New(os.Stdout, level)
+		logger.Warn("Failed to open the log file, logging to stdout", "error", err)
+	} else {
+		logger = logging.New(file, level)
+	}
+
+	if levelErr != nil {
+		logger.Warn("Failed to parse the log level, using info", "error", levelErr)
+	}
+	return logger
 }
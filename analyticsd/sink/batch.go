@@ -0,0 +1,87 @@
+package sink
+
+import (
+	"sync"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+// maxBatch bounds how many events a batching sink holds before it sends
+// them without waiting to be flushed.
+const maxBatch = 100
+
+// BatchSink is a sink that can send several events in a single request.
+type BatchSink interface {
+	Sink
+	EnqueueBatch([]analytics.Message) error
+}
+
+// enqueueBatch sends the events in one request if the sink can, and one by
+// one otherwise.
+func enqueueBatch(sink Sink, msgs []analytics.Message) error {
+	if batch, ok := sink.(BatchSink); ok {
+		return batch.EnqueueBatch(msgs)
+	}
+
+	for _, msg := range msgs {
+		if err := sink.Enqueue(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BatchingSink holds events until it is flushed, so that those from a
+// poll are sent together rather than each in a request of its own. The
+// Segment client batches the events it is given together on its own.
+type BatchingSink struct {
+	Sink
+	Max int
+
+	mu      sync.Mutex
+	pending []analytics.Message
+}
+
+func NewBatchingSink(sink Sink) *BatchingSink {
+	return &BatchingSink{Sink: sink, Max: maxBatch}
+}
+
+func (s *BatchingSink) Enqueue(msg analytics.Message) error {
+	s.mu.Lock()
+	s.pending = append(s.pending, msg)
+	full := len(s.pending) >= s.Max
+	s.mu.Unlock()
+
+	if full {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush sends the events held.
+func (s *BatchingSink) Flush() error {
+	s.mu.Lock()
+	msgs := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(msgs) == 0 {
+		return nil
+	}
+	return enqueueBatch(s.Sink, msgs)
+}
+
+// Replay replays the events Sink queued, if it queues them.
+func (s *BatchingSink) Replay() {
+	if replayer, ok := s.Sink.(interface{ Replay() }); ok {
+		replayer.Replay()
+	}
+}
+
+func (s *BatchingSink) Close() error {
+	if err := s.Flush(); err != nil {
+		s.Sink.Close()
+		return err
+	}
+	return s.Sink.Close()
+}
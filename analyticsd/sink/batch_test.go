@@ -0,0 +1,80 @@
+package sink_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+var _ = Describe("BatchingSink", func() {
+	var (
+		recorder *recordingSink
+		subject  *sink.BatchingSink
+	)
+
+	BeforeEach(func() {
+		recorder = &recordingSink{}
+		subject = sink.NewBatchingSink(recorder)
+	})
+
+	It("holds events until it is flushed", func() {
+		Expect(subject.Enqueue(analytics.Track{Event: "first"})).To(Succeed())
+		Expect(subject.Enqueue(analytics.Track{Event: "second"})).To(Succeed())
+		Expect(recorder.messages).To(BeEmpty())
+
+		Expect(subject.Flush()).To(Succeed())
+		Expect(recorder.messages).To(Equal([]analytics.Message{
+			analytics.Track{Event: "first"},
+			analytics.Track{Event: "second"},
+		}))
+
+		Expect(subject.Flush()).To(Succeed())
+		Expect(recorder.messages).To(HaveLen(2))
+	})
+
+	It("sends the events once it holds as many as it may", func() {
+		subject.Max = 2
+
+		Expect(subject.Enqueue(analytics.Track{Event: "first"})).To(Succeed())
+		Expect(recorder.messages).To(BeEmpty())
+		Expect(subject.Enqueue(analytics.Track{Event: "second"})).To(Succeed())
+		Expect(recorder.messages).To(HaveLen(2))
+	})
+
+	It("sends the events it holds when closed", func() {
+		Expect(subject.Enqueue(analytics.Track{Event: "first"})).To(Succeed())
+		Expect(subject.Close()).To(Succeed())
+		Expect(recorder.messages).To(HaveLen(1))
+	})
+
+	It("posts the events to a webhook in a single request", func() {
+		var received []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			received = append(received, string(body))
+		}))
+		defer server.Close()
+
+		s, err := sink.New(sink.Config{Sink: sink.Webhook, URL: server.URL, Batch: true})
+		Expect(err).NotTo(HaveOccurred())
+		subject = s.(*sink.BatchingSink)
+
+		timestamp := time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC)
+		Expect(subject.Enqueue(analytics.Track{Event: "first", Timestamp: timestamp})).To(Succeed())
+		Expect(subject.Enqueue(analytics.Track{Event: "second", Timestamp: timestamp})).To(Succeed())
+		Expect(received).To(BeEmpty())
+
+		Expect(subject.Flush()).To(Succeed())
+		Expect(received).To(HaveLen(1))
+		Expect(received[0]).To(MatchJSON(`{"batch": [
+			{"type": "track", "event": "first", "timestamp": "2018-08-08T08:08:08Z"},
+			{"type": "track", "event": "second", "timestamp": "2018-08-08T08:08:08Z"}
+		]}`))
+	})
+})
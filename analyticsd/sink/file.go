@@ -0,0 +1,72 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+// FileSink appends each event to a file as a line of json.
+type FileSink struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (f *FileSink) Enqueue(msg analytics.Message) error {
+	line, err := record(msg)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+func (f *FileSink) Close() error {
+	return nil
+}
+
+// ReadEvents returns the events in a file of json lines, as the FileSink
+// and the Queue write them, oldest first. There are none when the file
+// does not exist.
+func ReadEvents(path string) ([]analytics.Message, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var msgs []analytics.Message
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		msg, err := parseRecord(line)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, scanner.Err()
+}
@@ -0,0 +1,62 @@
+package sink
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hashKeySize is the size of the key in bytes, that of the SHA-256 hashes
+// it keys.
+const hashKeySize = 32
+
+// HashKey is the install's secret key for hashing what could identify the
+// user, made the first time it is needed. It never leaves the machine, so
+// the hashes of the same email on two installs cannot be matched up, nor
+// reversed by whoever receives the events.
+func HashKey(cfdevHome string) ([]byte, error) {
+	path := filepath.Join(cfdevHome, "analytics", "hash-key")
+	if key, err := readHashKey(path); err == nil {
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key := make([]byte, hashKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	// The plugin and analyticsd may both make one at once, so the first to
+	// be written is the one both use.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return readHashKey(path)
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(hex.EncodeToString(key)); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func readHashKey(path string) ([]byte, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(contents)))
+	if err != nil || len(key) != hashKeySize {
+		return nil, fmt.Errorf("%s is not a %d byte hex key", path, hashKeySize)
+	}
+	return key, nil
+}
@@ -0,0 +1,55 @@
+package sink_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HashKey", func() {
+	var home string
+
+	BeforeEach(func() {
+		var err error
+		home, err = ioutil.TempDir("", "cfdev-hash-key")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(home)
+	})
+
+	It("makes a key private to the user the first time and keeps it", func() {
+		key, err := sink.HashKey(home)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key).To(HaveLen(32))
+
+		info, err := os.Stat(filepath.Join(home, "analytics", "hash-key"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		Expect(sink.HashKey(home)).To(Equal(key))
+	})
+
+	It("makes a different key for each install", func() {
+		other, err := ioutil.TempDir("", "cfdev-hash-key")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(other)
+
+		key, err := sink.HashKey(home)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sink.HashKey(other)).NotTo(Equal(key))
+	})
+
+	It("fails for a key that is not one it made", func() {
+		Expect(os.MkdirAll(filepath.Join(home, "analytics"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(home, "analytics", "hash-key"), []byte("not-hex"), 0600)).To(Succeed())
+
+		_, err := sink.HashKey(home)
+		Expect(err).To(MatchError(ContainSubstring("is not a 32 byte hex key")))
+	})
+})
@@ -0,0 +1,49 @@
+package sink
+
+import (
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+// PropertiesSink adds Properties to each tracked event before handing it
+// to Sink, e.g. to tell the host's hardware class with every event. The
+// event's own properties are kept when they have the same name.
+type PropertiesSink struct {
+	Sink
+	Properties analytics.Properties
+}
+
+func (p *PropertiesSink) Enqueue(msg analytics.Message) error {
+	return p.Sink.Enqueue(p.message(msg))
+}
+
+func (p *PropertiesSink) EnqueueBatch(msgs []analytics.Message) error {
+	enriched := make([]analytics.Message, len(msgs))
+	for i, msg := range msgs {
+		enriched[i] = p.message(msg)
+	}
+	return enqueueBatch(p.Sink, enriched)
+}
+
+// Replay replays the events Sink queued, if it queues them.
+func (p *PropertiesSink) Replay() {
+	if replayer, ok := p.Sink.(interface{ Replay() }); ok {
+		replayer.Replay()
+	}
+}
+
+func (p *PropertiesSink) message(msg analytics.Message) analytics.Message {
+	track, ok := msg.(analytics.Track)
+	if !ok || len(p.Properties) == 0 {
+		return msg
+	}
+
+	properties := analytics.Properties{}
+	for k, v := range p.Properties {
+		properties[k] = v
+	}
+	for k, v := range track.Properties {
+		properties[k] = v
+	}
+	track.Properties = properties
+	return track
+}
@@ -0,0 +1,53 @@
+package sink_test
+
+import (
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+var _ = Describe("PropertiesSink", func() {
+	var (
+		recorder *recordingSink
+		subject  *sink.PropertiesSink
+	)
+
+	BeforeEach(func() {
+		recorder = &recordingSink{}
+		subject = &sink.PropertiesSink{
+			Sink: recorder,
+			Properties: analytics.Properties{
+				"cpu class":  "3-4",
+				"hypervisor": "hyperkit",
+			},
+		}
+	})
+
+	It("adds the properties to each event tracked", func() {
+		properties := analytics.Properties{"os": "darwin", "hypervisor": "qemu"}
+		Expect(subject.Enqueue(analytics.Track{Event: "app pushed", Properties: properties})).To(Succeed())
+		Expect(subject.Enqueue(analytics.Identify{UserId: "some-user"})).To(Succeed())
+
+		Expect(recorder.messages).To(Equal([]analytics.Message{
+			analytics.Track{Event: "app pushed", Properties: analytics.Properties{
+				"os":         "darwin",
+				"cpu class":  "3-4",
+				"hypervisor": "qemu",
+			}},
+			analytics.Identify{UserId: "some-user"},
+		}))
+		Expect(properties).To(HaveLen(2))
+	})
+
+	It("adds the properties to batches", func() {
+		Expect(subject.EnqueueBatch([]analytics.Message{analytics.Track{Event: "app pushed"}})).To(Succeed())
+
+		Expect(recorder.messages).To(Equal([]analytics.Message{
+			analytics.Track{Event: "app pushed", Properties: analytics.Properties{
+				"cpu class":  "3-4",
+				"hypervisor": "hyperkit",
+			}},
+		}))
+	})
+})
@@ -0,0 +1,183 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+// maxQueued bounds the queue, dropping the oldest events once a machine
+// has been offline for a long time.
+const maxQueued = 1000
+
+// RetryInterval is how long after a failed delivery queued events are
+// tried again, when no delivery has succeeded in the meantime.
+var RetryInterval = 30 * time.Minute
+
+// Queue keeps events that could not be delivered in a file, so that they
+// survive analyticsd restarting until they can be sent again. It is the
+// callback the sink reports deliveries to.
+type Queue struct {
+	Path string
+	Max  int
+
+	mu       sync.Mutex
+	offline  bool
+	failedAt time.Time
+}
+
+// QueuePath is where analyticsd queues the events it could not send.
+func QueuePath(cfdevHome string) string {
+	return filepath.Join(cfdevHome, "analytics", "queue.jsonl")
+}
+
+func NewQueue(path string) *Queue {
+	return &Queue{Path: path, Max: maxQueued}
+}
+
+func (q *Queue) Success(analytics.Message) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.offline = false
+}
+
+func (q *Queue) Failure(msg analytics.Message, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.offline = true
+	q.failedAt = time.Now()
+	q.push(msg)
+}
+
+// Offline reports whether the last delivery failed, until RetryInterval
+// has passed.
+func (q *Queue) Offline() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.offline && time.Since(q.failedAt) < RetryInterval
+}
+
+// Len returns how many events are waiting to be sent.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.read())
+}
+
+// push appends the event to the queue. q.mu must be held.
+func (q *Queue) push(msg analytics.Message) error {
+	line, err := record(msg)
+	if err != nil {
+		return err
+	}
+
+	lines := append(q.read(), line)
+	if len(lines) > q.Max {
+		lines = lines[len(lines)-q.Max:]
+	}
+
+	if err := os.MkdirAll(filepath.Dir(q.Path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(q.Path, append(bytes.Join(lines, []byte("\n")), '\n'), 0644)
+}
+
+// read returns the queued events as written. q.mu must be held.
+func (q *Queue) read() [][]byte {
+	file, err := os.Open(q.Path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			lines = append(lines, append([]byte{}, line...))
+		}
+	}
+	return lines
+}
+
+// drain empties the queue, returning its events oldest first. Events that
+// cannot be read back are dropped.
+func (q *Queue) drain() []analytics.Message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var msgs []analytics.Message
+	for _, line := range q.read() {
+		if msg, err := parseRecord(line); err == nil {
+			msgs = append(msgs, msg)
+		}
+	}
+	os.Remove(q.Path)
+	return msgs
+}
+
+func (q *Queue) requeue(msgs []analytics.Message) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, msg := range msgs {
+		q.push(msg)
+	}
+}
+
+// QueuedSink is a sink whose undelivered events are kept in a queue until
+// they can be sent.
+type QueuedSink struct {
+	Sink
+	Queue *Queue
+}
+
+func (s *QueuedSink) EnqueueBatch(msgs []analytics.Message) error {
+	return enqueueBatch(s.Sink, msgs)
+}
+
+// Replay sends the queued events again, unless the last delivery failed
+// recently, so that an offline machine does not retry them on every poll.
+// The
+// events are only known to have failed once the sink reports it, so
+// those that fail again are queued again.
+func (s *QueuedSink) Replay() {
+	if s.Queue.Offline() {
+		return
+	}
+
+	msgs := s.Queue.drain()
+	for i, msg := range msgs {
+		s.Sink.Enqueue(msg)
+		if s.Queue.Offline() {
+			s.Queue.requeue(msgs[i+1:])
+			return
+		}
+	}
+}
+
+func parseRecord(line []byte) (analytics.Message, error) {
+	var kind struct {
+		Type string
+	}
+	if err := json.Unmarshal(line, &kind); err != nil {
+		return nil, err
+	}
+
+	switch kind.Type {
+	case "identify":
+		var msg analytics.Identify
+		err := json.Unmarshal(line, &msg)
+		return msg, err
+	default:
+		var msg analytics.Track
+		err := json.Unmarshal(line, &msg)
+		return msg, err
+	}
+}
@@ -0,0 +1,123 @@
+package sink_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+var _ = Describe("Queue", func() {
+	var (
+		dir   string
+		queue *sink.Queue
+	)
+
+	track := func(event string) analytics.Track {
+		return analytics.Track{
+			UserId:    "some-user-uuid",
+			Event:     event,
+			Timestamp: time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "queue")
+		Expect(err).NotTo(HaveOccurred())
+		queue = sink.NewQueue(filepath.Join(dir, "analytics", "queue.jsonl"))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("keeps failed events, dropping the oldest past its bound", func() {
+		queue.Max = 2
+		queue.Failure(track("first"), errors.New("offline"))
+		queue.Failure(track("second"), errors.New("offline"))
+		queue.Failure(track("third"), errors.New("offline"))
+
+		Expect(queue.Len()).To(Equal(2))
+		Expect(queue.Offline()).To(BeTrue())
+
+		queue.Success(track("fourth"))
+		Expect(queue.Offline()).To(BeFalse())
+	})
+
+	Context("with a webhook", func() {
+		var (
+			server    *httptest.Server
+			available bool
+			received  []string
+			subject   *sink.QueuedSink
+		)
+
+		BeforeEach(func() {
+			available = false
+			received = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !available {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				body, _ := ioutil.ReadAll(r.Body)
+				received = append(received, string(body))
+			}))
+
+			s, err := sink.New(sink.Config{Sink: sink.Webhook, URL: server.URL, QueuePath: queue.Path})
+			Expect(err).NotTo(HaveOccurred())
+			subject = s.(*sink.ScrubbedSink).Sink.(*sink.QueuedSink)
+			queue = subject.Queue
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("replays the events it could not send once it can", func() {
+			Expect(subject.Enqueue(track("first"))).To(Succeed())
+			Expect(subject.Enqueue(track("second"))).To(Succeed())
+			Expect(queue.Len()).To(Equal(2))
+
+			By("not retrying while offline")
+			subject.Replay()
+			Expect(queue.Len()).To(Equal(2))
+
+			By("replaying after a delivery succeeds")
+			available = true
+			Expect(subject.Enqueue(track("third"))).To(Succeed())
+			subject.Replay()
+
+			Expect(queue.Len()).To(Equal(0))
+			Expect(received).To(HaveLen(3))
+			Expect(received[1]).To(ContainSubstring(`"event":"first"`))
+			Expect(received[1]).To(ContainSubstring(`"timestamp":"2018-08-08T08:08:08Z"`))
+			Expect(received[2]).To(ContainSubstring(`"event":"second"`))
+		})
+
+		It("retries once the retry interval has passed", func() {
+			defer func(interval time.Duration) { sink.RetryInterval = interval }(sink.RetryInterval)
+			sink.RetryInterval = 0
+
+			Expect(subject.Enqueue(track("first"))).To(Succeed())
+			Expect(subject.Enqueue(track("second"))).To(Succeed())
+
+			subject.Replay()
+			Expect(queue.Len()).To(Equal(2))
+
+			available = true
+			subject.Replay()
+			Expect(queue.Len()).To(Equal(0))
+			Expect(received).To(HaveLen(2))
+		})
+	})
+})
@@ -0,0 +1,238 @@
+package sink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+// Rule finds something in a property that could identify the user. What
+// it finds is hashed when Hash is set, so that events can still be told
+// apart, and otherwise replaced with Replacement. Matches that are, or end
+// in a subdomain of, one of Except are left alone.
+type Rule struct {
+	Name        string
+	Pattern     *regexp.Regexp
+	Hash        bool
+	Replacement string
+	Except      []string
+}
+
+// Scrubber removes anything identifying from event properties before they
+// leave the machine. Values of the properties named in HashedKeys, such as
+// org and space names, are hashed whole; Rules apply to every other
+// string.
+type Scrubber struct {
+	Rules      []Rule
+	HashedKeys []string
+	// Key keys the hashes, so that they cannot be reversed by hashing
+	// guesses, e.g. a list of emails. Without one, what would be hashed is
+	// redacted.
+	Key []byte
+}
+
+// DefaultScrubber is applied to every event analytics sends.
+var DefaultScrubber = Scrubber{
+	Rules: []Rule{
+		{
+			Name:    "email",
+			Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+			Hash:    true,
+		},
+		{
+			// Hostnames need at least three labels, so that service labels
+			// such as p.mysql are left alone.
+			Name:    "hostname",
+			Pattern: regexp.MustCompile(`(?i)\b(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.){2,}[a-z]{2,}\b`),
+			Hash:    true,
+		},
+		{
+			// Paths must start at a root, so that APP/PROC/WEB is left alone.
+			Name:        "path",
+			Pattern:     regexp.MustCompile(`(?:\b[A-Za-z]:\\|~/|\B/)(?:[^\s\\/"':]+[\\/])+[^\s\\/"':]*`),
+			Replacement: "<path>",
+		},
+	},
+	HashedKeys: []string{"org", "org_name", "organization", "space", "space_name"},
+}
+
+// CrashEvent is the event crash reports are sent as.
+const CrashEvent = "crash"
+
+// StackScrubber is applied to the stack of crash events in place of
+// DefaultScrubber. It leaves cfdev's own domains alone, so that the stack
+// keeps its package names.
+var StackScrubber = DefaultScrubber.except("hostname", "code.cloudfoundry.org", "dev.cfdev.sh")
+
+// WithKey returns a copy of the scrubber that hashes with key.
+func (s Scrubber) WithKey(key []byte) Scrubber {
+	s.Key = key
+	return s
+}
+
+// except returns a copy of the scrubber whose rule name leaves domains
+// alone.
+func (s Scrubber) except(name string, domains ...string) Scrubber {
+	rules := make([]Rule, len(s.Rules))
+	copy(rules, s.Rules)
+	for i := range rules {
+		if rules[i].Name == name {
+			rules[i].Except = append(append([]string{}, rules[i].Except...), domains...)
+		}
+	}
+	s.Rules = rules
+	return s
+}
+
+// String scrubs a single value with the rules.
+func (s Scrubber) String(value string) string {
+	for _, rule := range s.Rules {
+		value = rule.Pattern.ReplaceAllStringFunc(value, func(match string) string {
+			if rule.excepts(match) {
+				return match
+			}
+			if rule.Hash {
+				return s.hash(match)
+			}
+			return rule.Replacement
+		})
+	}
+	return value
+}
+
+func (r Rule) excepts(match string) bool {
+	for _, except := range r.Except {
+		if strings.EqualFold(match, except) || strings.HasSuffix(strings.ToLower(match), "."+strings.ToLower(except)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Properties returns a scrubbed copy of the properties, descending into
+// nested maps and lists.
+func (s Scrubber) Properties(props map[string]interface{}) map[string]interface{} {
+	if props == nil {
+		return nil
+	}
+
+	scrubbed := make(map[string]interface{}, len(props))
+	for k, v := range props {
+		if str, ok := v.(string); ok && s.hashedKey(k) {
+			scrubbed[k] = s.hash(str)
+			continue
+		}
+		scrubbed[k] = s.value(v)
+	}
+	return scrubbed
+}
+
+func (s Scrubber) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return s.String(v)
+	case error:
+		return s.String(v.Error())
+	case fmt.Stringer:
+		return s.String(v.String())
+	case map[string]interface{}:
+		return s.Properties(v)
+	case analytics.Properties:
+		return s.Properties(v)
+	case analytics.Traits:
+		return s.Properties(v)
+	case []interface{}:
+		scrubbed := make([]interface{}, len(v))
+		for i, item := range v {
+			scrubbed[i] = s.value(item)
+		}
+		return scrubbed
+	case []string:
+		scrubbed := make([]string, len(v))
+		for i, item := range v {
+			scrubbed[i] = s.String(item)
+		}
+		return scrubbed
+	default:
+		return v
+	}
+}
+
+func (s Scrubber) hashedKey(key string) bool {
+	for _, k := range s.HashedKeys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Message returns a copy of the message with its properties or traits
+// scrubbed.
+func (s Scrubber) Message(msg analytics.Message) analytics.Message {
+	switch m := msg.(type) {
+	case analytics.Track:
+		m.Properties = s.Properties(m.Properties)
+		return m
+	case analytics.Identify:
+		m.Traits = s.Properties(m.Traits)
+		return m
+	default:
+		return msg
+	}
+}
+
+func (s Scrubber) hash(value string) string {
+	if len(s.Key) == 0 {
+		return "<redacted>"
+	}
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write([]byte(value))
+	return fmt.Sprintf("hmac:%x", mac.Sum(nil))[:len("hmac:")+16]
+}
+
+// ScrubbedSink scrubs events before handing them to Sink.
+type ScrubbedSink struct {
+	Sink
+	Scrubber Scrubber
+}
+
+func (s *ScrubbedSink) Enqueue(msg analytics.Message) error {
+	return s.Sink.Enqueue(s.scrub(msg))
+}
+
+func (s *ScrubbedSink) EnqueueBatch(msgs []analytics.Message) error {
+	scrubbed := make([]analytics.Message, len(msgs))
+	for i, msg := range msgs {
+		scrubbed[i] = s.scrub(msg)
+	}
+	return enqueueBatch(s.Sink, scrubbed)
+}
+
+// scrub scrubs msg with Scrubber, and the stack of a crash event with
+// StackScrubber.
+func (s *ScrubbedSink) scrub(msg analytics.Message) analytics.Message {
+	track, ok := msg.(analytics.Track)
+	if !ok || track.Event != CrashEvent {
+		return s.Scrubber.Message(msg)
+	}
+	stack, ok := track.Properties["stack"].(string)
+	if !ok {
+		return s.Scrubber.Message(msg)
+	}
+
+	scrubbed := s.Scrubber.Message(track).(analytics.Track)
+	scrubbed.Properties["stack"] = StackScrubber.WithKey(s.Scrubber.Key).String(stack)
+	return scrubbed
+}
+
+// Replay replays the events Sink queued, if it queues them.
+func (s *ScrubbedSink) Replay() {
+	if replayer, ok := s.Sink.(interface{ Replay() }); ok {
+		replayer.Replay()
+	}
+}
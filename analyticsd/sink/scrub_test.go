@@ -0,0 +1,162 @@
+package sink_test
+
+import (
+	"errors"
+	"regexp"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+var _ = Describe("Scrubber", func() {
+	var scrubber sink.Scrubber
+
+	BeforeEach(func() {
+		scrubber = sink.DefaultScrubber.WithKey([]byte("some-key"))
+	})
+
+	Describe("String", func() {
+		It("hashes emails", func() {
+			Expect(scrubber.String("contact jane.doe+cf@example.com")).To(MatchRegexp(`^contact hmac:[0-9a-f]{16}$`))
+		})
+
+		It("hashes hostnames", func() {
+			Expect(scrubber.String("dial tcp: lookup api.corp.example.com")).To(MatchRegexp(`^dial tcp: lookup hmac:[0-9a-f]{16}$`))
+		})
+
+		It("strips unix paths", func() {
+			Expect(scrubber.String("open /Users/jane/.cfdev/state/disk.vhdx: denied")).To(Equal("open <path>: denied"))
+		})
+
+		It("strips home paths", func() {
+			Expect(scrubber.String("reading ~/workspace/app failed")).To(Equal("reading <path> failed"))
+		})
+
+		It("strips windows paths", func() {
+			Expect(scrubber.String(`creating C:\Users\jane\.cfdev\cache failed`)).To(Equal("creating <path> failed"))
+		})
+
+		It("hashes cfdev's own domains as well", func() {
+			Expect(scrubber.String("https://api.dev.cfdev.sh")).To(Equal("https://" + scrubber.String("api.dev.cfdev.sh")))
+			Expect(scrubber.String("code.cloudfoundry.org/cfdev")).To(MatchRegexp(`^hmac:[0-9a-f]{16}/cfdev$`))
+		})
+
+		It("leaves service labels alone", func() {
+			Expect(scrubber.String("p.mysql")).To(Equal("p.mysql"))
+		})
+
+		It("leaves versions alone", func() {
+			Expect(scrubber.String("10.14.1")).To(Equal("10.14.1"))
+		})
+
+		It("leaves process types alone", func() {
+			Expect(scrubber.String("APP/PROC/WEB: Exited with status 1")).To(Equal("APP/PROC/WEB: Exited with status 1"))
+		})
+	})
+
+	It("hashes the same value the same way", func() {
+		Expect(scrubber.String("jane@example.com")).To(Equal(scrubber.String("jane@example.com")))
+		Expect(scrubber.String("jane@example.com")).NotTo(Equal(scrubber.String("john@example.com")))
+	})
+
+	It("keys the hashes, so that installs hash the same value differently", func() {
+		other := sink.DefaultScrubber.WithKey([]byte("other-key"))
+		Expect(scrubber.String("jane@example.com")).NotTo(Equal(other.String("jane@example.com")))
+	})
+
+	It("redacts what it would hash without a key", func() {
+		Expect(sink.DefaultScrubber.String("contact jane@example.com")).To(Equal("contact <redacted>"))
+		Expect(sink.DefaultScrubber.Properties(map[string]interface{}{"org": "my-org"})).To(Equal(map[string]interface{}{"org": "<redacted>"}))
+	})
+
+	It("hashes org and space names whole", func() {
+		props := scrubber.Properties(map[string]interface{}{
+			"org":       "my-org",
+			"space":     "my-space",
+			"buildpack": "go",
+		})
+
+		Expect(props["org"]).To(MatchRegexp(`^hmac:[0-9a-f]{16}$`))
+		Expect(props["space"]).To(MatchRegexp(`^hmac:[0-9a-f]{16}$`))
+		Expect(props["buildpack"]).To(Equal("go"))
+	})
+
+	It("descends into nested properties without changing the originals", func() {
+		original := map[string]interface{}{
+			"errors": errors.New("stat /home/jane/cf: no such file"),
+			"nested": map[string]interface{}{"owner": "jane@example.com"},
+			"list":   []interface{}{"/tmp/a/b", 3},
+			"memory": 8192,
+		}
+
+		props := scrubber.Properties(original)
+
+		Expect(props["errors"]).To(Equal("stat <path>: no such file"))
+		Expect(props["nested"].(map[string]interface{})["owner"]).To(MatchRegexp(`^hmac:`))
+		Expect(props["list"]).To(Equal([]interface{}{"<path>", 3}))
+		Expect(props["memory"]).To(Equal(8192))
+		Expect(original["nested"].(map[string]interface{})["owner"]).To(Equal("jane@example.com"))
+	})
+
+	It("applies custom rules", func() {
+		scrubber = sink.Scrubber{Rules: []sink.Rule{
+			{Name: "guid", Pattern: regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f-]{27}`), Replacement: "<guid>"},
+		}}
+
+		Expect(scrubber.String("app 6d3f1c2e-8f1b-4a2e-9d4c-1b2a3c4d5e6f crashed")).To(Equal("app <guid> crashed"))
+	})
+
+	Describe("ScrubbedSink", func() {
+		It("scrubs events before they are enqueued", func() {
+			recorder := &recordingSink{}
+			subject := &sink.ScrubbedSink{Sink: recorder, Scrubber: scrubber}
+
+			Expect(subject.Enqueue(analytics.Track{
+				Event:      "error",
+				Properties: analytics.Properties{"errors": "reading /var/vcap/jobs/cf: denied"},
+			})).To(Succeed())
+			Expect(subject.Enqueue(analytics.Identify{
+				Traits: analytics.Traits{"email": "jane@example.com"},
+			})).To(Succeed())
+
+			Expect(recorder.messages).To(HaveLen(2))
+			Expect(recorder.messages[0].(analytics.Track).Properties["errors"]).To(Equal("reading <path>: denied"))
+			Expect(recorder.messages[1].(analytics.Identify).Traits["email"]).To(MatchRegexp(`^hmac:`))
+		})
+
+		It("keeps cfdev's package names in the stack of crash events only", func() {
+			recorder := &recordingSink{}
+			subject := &sink.ScrubbedSink{Sink: recorder, Scrubber: scrubber}
+
+			Expect(subject.Enqueue(analytics.Track{
+				Event: sink.CrashEvent,
+				Properties: analytics.Properties{
+					"panic": "dial tcp: lookup api.corp.example.com",
+					"stack": "code.cloudfoundry.org/cfdev/cmd/start.(*Start).Execute()\n\tapi.corp.example.com",
+				},
+			})).To(Succeed())
+			Expect(subject.Enqueue(analytics.Track{
+				Event:      "error",
+				Properties: analytics.Properties{"stack": "code.cloudfoundry.org/cfdev"},
+			})).To(Succeed())
+
+			crash := recorder.messages[0].(analytics.Track).Properties
+			Expect(crash["panic"]).To(MatchRegexp(`^dial tcp: lookup hmac:[0-9a-f]{16}$`))
+			Expect(crash["stack"]).To(MatchRegexp(`^code\.cloudfoundry\.org/cfdev/cmd/start\.\(\*Start\)\.Execute\(\)\n\thmac:[0-9a-f]{16}$`))
+			Expect(recorder.messages[1].(analytics.Track).Properties["stack"]).To(MatchRegexp(`^hmac:`))
+		})
+	})
+})
+
+type recordingSink struct {
+	messages []analytics.Message
+}
+
+func (r *recordingSink) Enqueue(msg analytics.Message) error {
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func (r *recordingSink) Close() error { return nil }
@@ -0,0 +1,181 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+// Sink is where analytics events are sent. It has the same methods as the
+// Segment client, so that the client is one of the sinks.
+type Sink interface {
+	io.Closer
+	Enqueue(analytics.Message) error
+}
+
+const (
+	Segment = "segment"
+	File    = "file"
+	Webhook = "webhook"
+	None    = "none"
+)
+
+// Config selects the sink. It is read from analytics-sink.json in the
+// cfdev home, and the CFDEV_ANALYTICS_SINK, CFDEV_ANALYTICS_SINK_PATH,
+// CFDEV_ANALYTICS_SINK_URL, CFDEV_ANALYTICS_SEGMENT_KEY and
+// CFDEV_ANALYTICS_SEGMENT_ENDPOINT environment variables override it.
+type Config struct {
+	Sink string `json:"sink"`
+	// Path is the file events are appended to by the file sink.
+	Path string `json:"path"`
+	// URL is where the webhook sink posts events.
+	URL string `json:"url"`
+	// SegmentKey and SegmentEndpoint point the Segment sink at another
+	// source or collector, e.g. for a fork of cf dev. Callers only set the
+	// key they are built with when it is empty.
+	SegmentKey      string `json:"segmentKey"`
+	SegmentEndpoint string `json:"segmentEndpoint"`
+
+	SegmentConfig analytics.Config `json:"-"`
+	// Properties are added to every event tracked, e.g. the host's
+	// hardware class.
+	Properties analytics.Properties `json:"-"`
+	// Batch holds events until the sink is flushed, to send them together.
+	Batch bool `json:"-"`
+	// QueuePath is where events Segment or the webhook could not be sent
+	// are kept until they can be. They are dropped when it is empty.
+	QueuePath string `json:"-"`
+	// HashKey keys the hashes DefaultScrubber makes of what could identify
+	// the user.
+	HashKey []byte `json:"-"`
+}
+
+// LoadConfig reads the sink configuration, defaulting to Segment.
+func LoadConfig(cfdevHome string) (Config, error) {
+	cfg := Config{Sink: Segment}
+
+	contents, err := ioutil.ReadFile(filepath.Join(cfdevHome, "analytics-sink.json"))
+	if err != nil && !os.IsNotExist(err) {
+		return Config{}, err
+	} else if err == nil {
+		if err := json.Unmarshal(contents, &cfg); err != nil {
+			return Config{}, fmt.Errorf("parsing analytics-sink.json: %s", err)
+		}
+	}
+
+	if value := os.Getenv("CFDEV_ANALYTICS_SINK"); value != "" {
+		cfg.Sink = value
+	}
+	if value := os.Getenv("CFDEV_ANALYTICS_SINK_PATH"); value != "" {
+		cfg.Path = value
+	}
+	if value := os.Getenv("CFDEV_ANALYTICS_SINK_URL"); value != "" {
+		cfg.URL = value
+	}
+	if value := os.Getenv("CFDEV_ANALYTICS_SEGMENT_KEY"); value != "" {
+		cfg.SegmentKey = value
+	}
+	if value := os.Getenv("CFDEV_ANALYTICS_SEGMENT_ENDPOINT"); value != "" {
+		cfg.SegmentEndpoint = value
+	}
+
+	if cfg.Sink == File && cfg.Path == "" {
+		cfg.Path = filepath.Join(cfdevHome, "analytics", "events.jsonl")
+	}
+	// Without a key the values are redacted rather than hashed, which
+	// must not keep the events from being sent.
+	cfg.HashKey, _ = HashKey(cfdevHome)
+	return cfg, nil
+}
+
+// New returns the sink the configuration selects. Events are given
+// Properties and scrubbed with DefaultScrubber, keyed with HashKey, before
+// they reach it, and batched if asked for.
+func New(cfg Config) (Sink, error) {
+	sink, err := newSink(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := sink.(NoopSink); ok {
+		return sink, nil
+	}
+	sink = &ScrubbedSink{Sink: sink, Scrubber: DefaultScrubber.WithKey(cfg.HashKey)}
+	if len(cfg.Properties) > 0 {
+		sink = &PropertiesSink{Sink: sink, Properties: cfg.Properties}
+	}
+	if cfg.Batch {
+		sink = NewBatchingSink(sink)
+	}
+	return sink, nil
+}
+
+func newSink(cfg Config) (Sink, error) {
+	var queue *Queue
+	if cfg.QueuePath != "" {
+		queue = NewQueue(cfg.QueuePath)
+	}
+
+	switch cfg.Sink {
+	case Segment, "":
+		if queue != nil {
+			cfg.SegmentConfig.Callback = queue
+		}
+		if cfg.SegmentEndpoint != "" {
+			cfg.SegmentConfig.Endpoint = cfg.SegmentEndpoint
+		}
+		client, err := analytics.NewWithConfig(cfg.SegmentKey, cfg.SegmentConfig)
+		if err != nil {
+			return nil, err
+		}
+		return queued(client, queue), nil
+	case File:
+		return &FileSink{Path: cfg.Path}, nil
+	case Webhook:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("the webhook analytics sink needs a url")
+		}
+		webhook := NewWebhookSink(cfg.URL)
+		if queue != nil {
+			webhook.Callback = queue
+		}
+		return queued(webhook, queue), nil
+	case None:
+		return NoopSink{}, nil
+	default:
+		return nil, fmt.Errorf("unknown analytics sink %q", cfg.Sink)
+	}
+}
+
+func queued(sink Sink, queue *Queue) Sink {
+	if queue == nil {
+		return sink
+	}
+	return &QueuedSink{Sink: sink, Queue: queue}
+}
+
+// record is a message as Segment would receive it, for the sinks that
+// keep events themselves.
+func record(msg analytics.Message) ([]byte, error) {
+	switch m := msg.(type) {
+	case analytics.Track:
+		m.Type = "track"
+		return json.Marshal(m)
+	case analytics.Identify:
+		m.Type = "identify"
+		return json.Marshal(m)
+	default:
+		return nil, fmt.Errorf("unsupported analytics message %T", msg)
+	}
+}
+
+// NoopSink drops every event, for when telemetry must not leave the
+// machine at all.
+type NoopSink struct{}
+
+func (NoopSink) Enqueue(analytics.Message) error { return nil }
+func (NoopSink) Close() error                    { return nil }
@@ -0,0 +1,205 @@
+package sink_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+var _ = Describe("Sink", func() {
+	var (
+		dir   string
+		track analytics.Track
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "sink")
+		Expect(err).NotTo(HaveOccurred())
+
+		track = analytics.Track{
+			UserId:     "some-user-uuid",
+			Event:      "app pushed",
+			Timestamp:  time.Date(2018, 8, 8, 8, 8, 8, 0, time.UTC),
+			Properties: analytics.Properties{"os": "some-os"},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+		os.Unsetenv("CFDEV_ANALYTICS_SINK")
+		os.Unsetenv("CFDEV_ANALYTICS_SINK_URL")
+		os.Unsetenv("CFDEV_ANALYTICS_SEGMENT_KEY")
+		os.Unsetenv("CFDEV_ANALYTICS_SEGMENT_ENDPOINT")
+	})
+
+	Describe("LoadConfig", func() {
+		var hashKey []byte
+
+		BeforeEach(func() {
+			var err error
+			hashKey, err = sink.HashKey(dir)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("defaults to segment", func() {
+			Expect(sink.LoadConfig(dir)).To(Equal(sink.Config{Sink: sink.Segment, HashKey: hashKey}))
+		})
+
+		It("reads the config file, which the environment overrides", func() {
+			contents := `{"sink": "webhook", "url": "https://collector.example.com"}`
+			Expect(ioutil.WriteFile(filepath.Join(dir, "analytics-sink.json"), []byte(contents), 0644)).To(Succeed())
+
+			Expect(sink.LoadConfig(dir)).To(Equal(sink.Config{Sink: sink.Webhook, URL: "https://collector.example.com", HashKey: hashKey}))
+
+			os.Setenv("CFDEV_ANALYTICS_SINK", "file")
+			Expect(sink.LoadConfig(dir)).To(Equal(sink.Config{
+				Sink:    sink.File,
+				Path:    filepath.Join(dir, "analytics", "events.jsonl"),
+				URL:     "https://collector.example.com",
+				HashKey: hashKey,
+			}))
+		})
+
+		It("reads the Segment key and endpoint, which the environment overrides", func() {
+			contents := `{"segmentKey": "some-key", "segmentEndpoint": "https://segment.example.com"}`
+			Expect(ioutil.WriteFile(filepath.Join(dir, "analytics-sink.json"), []byte(contents), 0644)).To(Succeed())
+
+			Expect(sink.LoadConfig(dir)).To(Equal(sink.Config{
+				Sink:            sink.Segment,
+				SegmentKey:      "some-key",
+				SegmentEndpoint: "https://segment.example.com",
+				HashKey:         hashKey,
+			}))
+
+			os.Setenv("CFDEV_ANALYTICS_SEGMENT_KEY", "some-other-key")
+			os.Setenv("CFDEV_ANALYTICS_SEGMENT_ENDPOINT", "https://other-segment.example.com")
+			Expect(sink.LoadConfig(dir)).To(Equal(sink.Config{
+				Sink:            sink.Segment,
+				SegmentKey:      "some-other-key",
+				SegmentEndpoint: "https://other-segment.example.com",
+				HashKey:         hashKey,
+			}))
+		})
+	})
+
+	Describe("New", func() {
+		It("returns the selected sink", func() {
+			s, err := sink.New(sink.Config{Sink: sink.None})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s).To(Equal(sink.NoopSink{}))
+
+			s, err = sink.New(sink.Config{Sink: sink.File, Path: "some-path"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s).To(BeAssignableToTypeOf(&sink.ScrubbedSink{}))
+			Expect(s.(*sink.ScrubbedSink).Sink).To(BeAssignableToTypeOf(&sink.FileSink{}))
+
+			s, err = sink.New(sink.Config{Sink: sink.File, Path: "some-path", Properties: analytics.Properties{"cpu class": "1-2"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s).To(BeAssignableToTypeOf(&sink.PropertiesSink{}))
+			Expect(s.(*sink.PropertiesSink).Sink).To(BeAssignableToTypeOf(&sink.ScrubbedSink{}))
+
+			s, err = sink.New(sink.Config{Sink: sink.Segment, SegmentKey: "some-key"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Close()).To(Succeed())
+		})
+
+		It("sends Segment events to the endpoint configured", func() {
+			received := make(chan string, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				received <- req.URL.Path
+			}))
+			defer server.Close()
+
+			s, err := sink.New(sink.Config{Sink: sink.Segment, SegmentKey: "some-key", SegmentEndpoint: server.URL})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Enqueue(track)).To(Succeed())
+			Expect(s.Close()).To(Succeed())
+
+			Eventually(received).Should(Receive(Equal("/v1/batch")))
+		})
+
+		It("fails for unknown sinks and webhooks without a url", func() {
+			_, err := sink.New(sink.Config{Sink: "carrier-pigeon"})
+			Expect(err).To(MatchError(ContainSubstring(`unknown analytics sink "carrier-pigeon"`)))
+
+			_, err = sink.New(sink.Config{Sink: sink.Webhook})
+			Expect(err).To(MatchError(ContainSubstring("needs a url")))
+		})
+	})
+
+	Describe("FileSink", func() {
+		It("appends each event as a line of json", func() {
+			path := filepath.Join(dir, "analytics", "events.jsonl")
+			s := &sink.FileSink{Path: path}
+
+			Expect(s.Enqueue(track)).To(Succeed())
+			Expect(s.Enqueue(analytics.Identify{UserId: "some-user-uuid"})).To(Succeed())
+
+			contents, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+			Expect(lines).To(HaveLen(2))
+
+			var event map[string]interface{}
+			Expect(json.Unmarshal([]byte(lines[0]), &event)).To(Succeed())
+			Expect(event).To(HaveKeyWithValue("type", "track"))
+			Expect(event).To(HaveKeyWithValue("event", "app pushed"))
+			Expect(event).To(HaveKeyWithValue("userId", "some-user-uuid"))
+			Expect(event).To(HaveKeyWithValue("properties", map[string]interface{}{"os": "some-os"}))
+			Expect(lines[1]).To(ContainSubstring(`"type":"identify"`))
+		})
+
+		It("writes events that ReadEvents reads back", func() {
+			path := filepath.Join(dir, "analytics", "events.jsonl")
+			Expect(sink.ReadEvents(path)).To(BeEmpty())
+
+			s := &sink.FileSink{Path: path}
+			Expect(s.Enqueue(track)).To(Succeed())
+			Expect(s.Enqueue(analytics.Identify{UserId: "some-user-uuid"})).To(Succeed())
+
+			msgs, err := sink.ReadEvents(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(msgs).To(HaveLen(2))
+			Expect(msgs[0].(analytics.Track).Event).To(Equal("app pushed"))
+			Expect(msgs[0].(analytics.Track).Properties).To(Equal(analytics.Properties{"os": "some-os"}))
+			Expect(msgs[1].(analytics.Identify).UserId).To(Equal("some-user-uuid"))
+		})
+	})
+
+	Describe("WebhookSink", func() {
+		It("posts each event as json", func() {
+			var received map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				Expect(r.Method).To(Equal(http.MethodPost))
+				Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+				Expect(json.NewDecoder(r.Body).Decode(&received)).To(Succeed())
+			}))
+			defer server.Close()
+
+			Expect(sink.NewWebhookSink(server.URL).Enqueue(track)).To(Succeed())
+			Expect(received).To(HaveKeyWithValue("event", "app pushed"))
+			Expect(received).To(HaveKeyWithValue("type", "track"))
+		})
+
+		It("fails when the webhook does not accept the event", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			}))
+			defer server.Close()
+
+			Expect(sink.NewWebhookSink(server.URL).Enqueue(track)).To(MatchError(ContainSubstring("502")))
+		})
+	})
+})
@@ -0,0 +1,96 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+// WebhookSink posts each event as json to a url, e.g. a collector run
+// on-prem.
+type WebhookSink struct {
+	URL        string
+	HTTPClient *http.Client
+	// Callback is told whether each event was delivered, as with the
+	// Segment client. Failures are then only reported to it.
+	Callback analytics.Callback
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		URL:        url,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (w *WebhookSink) Enqueue(msg analytics.Message) error {
+	body, err := record(msg)
+	if err != nil {
+		return err
+	}
+
+	err = w.post(body)
+	if w.Callback == nil {
+		return err
+	}
+
+	if err != nil {
+		w.Callback.Failure(msg, err)
+	} else {
+		w.Callback.Success(msg)
+	}
+	return nil
+}
+
+// EnqueueBatch posts the events together as {"batch": [...]}, as with
+// Segment's batch api.
+func (w *WebhookSink) EnqueueBatch(msgs []analytics.Message) error {
+	records := make([]json.RawMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		body, err := record(msg)
+		if err != nil {
+			return err
+		}
+		records = append(records, body)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"batch": records})
+	if err != nil {
+		return err
+	}
+
+	err = w.post(body)
+	if w.Callback == nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		if err != nil {
+			w.Callback.Failure(msg, err)
+		} else {
+			w.Callback.Success(msg)
+		}
+	}
+	return nil
+}
+
+func (w *WebhookSink) post(body []byte) error {
+	resp, err := w.HTTPClient.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("posting analytics to %s: %s", w.URL, resp.Status)
+	}
+	return nil
+}
+
+func (w *WebhookSink) Close() error {
+	return nil
+}
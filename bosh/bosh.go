@@ -2,9 +2,14 @@ package bosh
 
 import (
 	"code.cloudfoundry.org/cfdev/config"
+	"context"
+	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/cfdev/errors"
@@ -14,47 +19,127 @@ import (
 
 var VMProgressInterval = 1 * time.Second
 
+var (
+	// FindDeploymentTimeout bounds how long progress reporting waits for the
+	// director to know about a deployment before giving up on it.
+	FindDeploymentTimeout = 10 * time.Minute
+	// FindDeploymentBackoff is the wait after the first failed lookup. It
+	// doubles after each failure, up to maxFindDeploymentBackoff.
+	FindDeploymentBackoff = 500 * time.Millisecond
+)
+
+const maxFindDeploymentBackoff = 10 * time.Second
+
+// DirectorUnreachableError is returned when the director did not answer
+// about a deployment before the wait for it ran out.
+type DirectorUnreachableError struct {
+	Deployment string
+	Waited     time.Duration
+	Err        error
+}
+
+func (e *DirectorUnreachableError) Error() string {
+	return fmt.Sprintf("bosh director unreachable: no answer about deployment %s after %s: %s",
+		e.Deployment, e.Waited.Round(time.Second), e.Err)
+}
+
 type Bosh struct {
-	dir boshdir.Director
+	dir     boshdir.Director
+	history *PhaseHistory
+	timings *TimingsLog
+	// config is how the bosh cli and SSH reach the director. Without it,
+	// SSH dials instances directly.
+	config Config
+
+	// mu guards phases, waiting and failingSince, which deployments run
+	// in parallel update at once.
+	mu     sync.Mutex
+	phases map[string]*phase
+	// waiting is the deployments RunLocked is waiting to be unlocked.
+	waiting map[string]bool
+	// failingSince is when instances of each deployment started failing.
+	failingSince map[string]time.Time
 }
 
 func New(cfg config.Config) (*Bosh, error) {
-	content, err := ioutil.ReadFile(filepath.Join(cfg.StateBosh, "secret"))
+	if err := ValidateProxy(os.Getenv(AllProxyEnv)); err != nil {
+		return nil, errors.SafeWrap(err, "invalid "+AllProxyEnv)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(cfg.StateBosh, "ca.crt"))
 	if err != nil {
 		return nil, err
 	}
 
-	secret := strings.TrimSpace(string(content))
+	caCert := strings.TrimSpace(string(content))
 
-	content, err = ioutil.ReadFile(filepath.Join(cfg.StateBosh, "ca.crt"))
+	factoryConfig := boshdir.FactoryConfig{
+		Host:   cfg.BoshDirectorIP,
+		Port:   25555,
+		CACert: caCert,
+	}
+
+	uaaConfig, ok, err := ReadUAAConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
+	if ok {
+		factoryConfig.TokenFunc, err = uaaConfig.TokenFunc(caCert)
+		if err != nil {
+			return nil, errors.SafeWrap(err, "failed to configure uaa")
+		}
+	} else {
+		content, err := ioutil.ReadFile(filepath.Join(cfg.StateBosh, "secret"))
+		if err != nil {
+			return nil, err
+		}
 
-	caCert := strings.TrimSpace(string(content))
+		factoryConfig.Client = "admin"
+		factoryConfig.ClientSecret = strings.TrimSpace(string(content))
+	}
 
 	f := boshdir.NewFactory(&Logger{})
-	dir, err := f.New(boshdir.FactoryConfig{
-		Host:         cfg.BoshDirectorIP,
-		Port:         25555,
-		CACert:       caCert,
-		Client:       "admin",
-		ClientSecret: secret,
-	}, &TaskReporter{}, &FileReporter{})
+	dir, err := f.New(factoryConfig, &TaskReporter{}, &FileReporter{})
 	if err != nil {
 		return nil, errors.SafeWrap(err, "failed to connect to bosh director")
 	}
-	return NewWithDirector(dir), nil
+	b := NewWithDirector(dir)
+	b.history = &PhaseHistory{Path: filepath.Join(cfg.CFDevHome, "phase-durations.json")}
+	b.timings = &TimingsLog{Path: filepath.Join(cfg.CFDevHome, "deploy-timings.json")}
+	b.config, _ = FetchConfig(cfg)
+	return b, nil
 }
 
 func NewWithDirector(dir boshdir.Director) *Bosh {
 	return &Bosh{dir: dir}
 }
 
+// EnvVars are the variables that point the bosh cli at the director.
+func (b *Bosh) EnvVars() []EnvVar {
+	return b.config.EnvVars()
+}
+
+// WithTimings has the durations of phases added to timings.
+func (b *Bosh) WithTimings(timings *TimingsLog) *Bosh {
+	b.timings = timings
+	return b
+}
+
+// WithPhaseHistory has GetVMProgress estimate the time remaining from, and
+// record phase durations to, history.
+func (b *Bosh) WithPhaseHistory(history *PhaseHistory) *Bosh {
+	b.history = history
+	return b
+}
+
 const (
 	UploadingReleases = "uploading-releases"
-	Deploying         = "deploying"
-	RunningErrand     = "running-errand"
+	// Compiling is while the director compiles the packages of new
+	// releases, on compilation vms, before updating the instances.
+	Compiling     = "compiling"
+	Deploying     = "deploying"
+	RunningErrand = "running-errand"
+	Deleting      = "deleting"
 )
 
 type VMProgress struct {
@@ -63,89 +148,309 @@ type VMProgress struct {
 	Total    int
 	Done     int
 	Duration time.Duration
+	// Instances is the state of each vm of the deployment, once there are
+	// any.
+	Instances []InstanceStatus
+	// Remaining estimates how long the deployment will take to finish from
+	// past deploys of it. It is zero when there is no estimate.
+	Remaining time.Duration
 }
 
-func (b *Bosh) VMProgress(deploymentName string) chan VMProgress {
-	start := time.Now()
-	var dep boshdir.Deployment
+// InstanceStatus is the state of one vm of a deployment.
+type InstanceStatus struct {
+	Job          string
+	Index        int
+	ProcessState string
+	// FailingProcesses are the processes on the vm that are not running.
+	FailingProcesses []string
+}
 
-	for {
-		var err error
-		dep, err = b.dir.FindDeployment(deploymentName)
-		if err == nil {
-			break
+// Name is how bosh refers to the instance, e.g. diego-cell/0.
+func (i InstanceStatus) Name() string {
+	return fmt.Sprintf("%s/%d", i.Job, i.Index)
+}
+
+func instanceStatuses(vmInfos []boshdir.VMInfo) (statuses []InstanceStatus, done int) {
+	for _, v := range vmInfos {
+		status := InstanceStatus{
+			Job:          v.JobName,
+			ProcessState: v.ProcessState,
+		}
+		if v.Index != nil {
+			status.Index = *v.Index
+		}
+		for _, p := range v.Processes {
+			if !p.IsRunning() {
+				status.FailingProcesses = append(status.FailingProcesses, p.Name)
+			}
+		}
+		statuses = append(statuses, status)
+
+		if v.ProcessState == "running" && len(v.Processes) > 0 {
+			done++
 		}
 	}
+	return statuses, done
+}
+
+// deployState tells compiling packages apart from updating instances by the
+// compilation vms the director creates for the former.
+func deployState(vmInfos []boshdir.VMInfo) string {
+	for _, v := range vmInfos {
+		if strings.HasPrefix(v.JobName, "compilation-") {
+			return Compiling
+		}
+	}
+	return Deploying
+}
+
+// VMProgress reports the progress of a deployment until all of its vms are
+// running, it has failed or ctx is done, when the channel is closed. A
+// failed deployment's last event has the Failed phase and says which
+// instances are failing.
+func (b *Bosh) VMProgress(ctx context.Context, deploymentName string) (chan ProgressEvent, error) {
+	start := time.Now()
+
+	dep, err := b.findDeployment(ctx, deploymentName)
+	if err != nil {
+		return nil, err
+	}
 
-	ch := make(chan VMProgress, 1)
+	ch := make(chan ProgressEvent, 1)
 	total := 0
 	go func() {
 		defer ginkgo.GinkgoRecover()
+		defer close(ch)
 
+		// unanswered is when the director stopped answering, which ends
+		// the progress as failed once FindDeploymentTimeout passes.
+		var unanswered time.Time
 		for {
-			time.Sleep(VMProgressInterval)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(VMProgressInterval):
+			}
 
 			vmInfos, err := dep.VMInfos()
 			if err != nil || len(vmInfos) == 0 {
+				answered := err == nil
 				if total == 0 {
-					rels, err := b.dir.Releases()
-					if err == nil {
-						ch <- VMProgress{Releases: len(rels), Duration: time.Now().Sub(start)}
+					rels, relErr := b.dir.Releases()
+					if relErr == nil {
+						answered = true
+						p := VMProgress{State: UploadingReleases, Releases: len(rels), Duration: time.Now().Sub(start)}
+						send(ctx, ch, NewProgressEvent(deploymentName, start, time.Now(), p))
 					}
 				}
+
+				if answered {
+					unanswered = time.Time{}
+					continue
+				}
+				if unanswered.IsZero() {
+					unanswered = time.Now()
+				}
+				if failure := unreachable(deploymentName, unanswered, err); failure != nil {
+					p := VMProgress{State: Failed, Total: total, Duration: time.Now().Sub(start)}
+					event := NewProgressEvent(deploymentName, start, time.Now(), p)
+					event.Message = failure.Error()
+					send(ctx, ch, event)
+					return
+				}
 				continue
 			}
+			unanswered = time.Time{}
 
 			total = len(vmInfos)
-			numDone := 0
-			for _, v := range vmInfos {
-				if v.ProcessState == "running" && len(v.Processes) > 0 {
-					numDone++
-				}
-			}
+			instances, numDone := instanceStatuses(vmInfos)
 
-			ch <- VMProgress{Total: total, Done: numDone, Duration: time.Now().Sub(start)}
+			p := VMProgress{State: deployState(vmInfos), Total: total, Done: numDone, Duration: time.Now().Sub(start), Instances: instances}
+			if err := b.checkFailing(deploymentName, instances); err != nil {
+				p.State = Failed
+				event := NewProgressEvent(deploymentName, start, time.Now(), p)
+				event.Message = err.Error()
+				send(ctx, ch, event)
+				return
+			}
+			if !send(ctx, ch, NewProgressEvent(deploymentName, start, time.Now(), p)) {
+				return
+			}
 
 			if numDone >= len(vmInfos) {
-				close(ch)
 				return
 			}
 		}
 	}()
 
-	return ch
+	return ch, nil
 }
 
-func (b *Bosh) GetVMProgress(start time.Time, deploymentName string, isErrand bool) VMProgress {
-	if isErrand {
-		return VMProgress{State: RunningErrand, Duration: time.Now().Sub(start)}
+// unreachable is the error to give up with once the director has not
+// answered since the time given for long enough, or with an error that
+// retrying will not fix.
+func unreachable(deploymentName string, since time.Time, err error) error {
+	if dirErr := directorError("deployment", err); !dirErr.Retryable() {
+		return dirErr
+	}
+	if waited := time.Since(since); waited >= FindDeploymentTimeout {
+		return &DirectorUnreachableError{Deployment: deploymentName, Waited: waited, Err: err}
 	}
+	return nil
+}
+
+// send gives up on a reader that has gone away once ctx is done.
+func send(ctx context.Context, ch chan ProgressEvent, e ProgressEvent) bool {
+	select {
+	case ch <- e:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
 
+// findDeployment looks the deployment up once the director answers.
+// Looking a deployment up makes no request by itself, so the director is
+// asked for its info, retrying as retryDirector does.
+func (b *Bosh) findDeployment(ctx context.Context, deploymentName string) (boshdir.Deployment, error) {
 	var dep boshdir.Deployment
+	err := retryDirector(ctx, deploymentName, func() error {
+		var err error
+		if dep, err = b.dir.FindDeployment(deploymentName); err != nil {
+			return err
+		}
+		_, err = b.dir.Info()
+		return err
+	})
+	return dep, err
+}
 
+// retryDirector retries call, backing off exponentially, until it
+// succeeds, ctx is done or FindDeploymentTimeout passes. Certificate and
+// credential errors are returned at once, as retrying will not fix them.
+func retryDirector(ctx context.Context, deploymentName string, call func() error) error {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, FindDeploymentTimeout)
+	defer cancel()
+
+	backoff := FindDeploymentBackoff
 	for {
-		var err error
-		dep, err = b.dir.FindDeployment(deploymentName)
+		err := call()
 		if err == nil {
-			break
+			return nil
+		}
+		if dirErr := directorError("deployment", err); !dirErr.Retryable() {
+			return dirErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return &DirectorUnreachableError{
+				Deployment: deploymentName,
+				Waited:     time.Since(start),
+				Err:        err,
+			}
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxFindDeploymentBackoff {
+			backoff = maxFindDeploymentBackoff
 		}
 	}
+}
+
+// Ready returns an error unless the director answers requests
+func (b *Bosh) Ready() error {
+	_, err := b.dir.Info()
+	return err
+}
+
+// DeploymentRunning reports whether the deployment exists and all of its vms
+// and their processes are running
+func (b *Bosh) DeploymentRunning(deploymentName string) (bool, error) {
+	dep, err := b.dir.FindDeployment(deploymentName)
+	if err != nil {
+		return false, err
+	}
 
 	vmInfos, err := dep.VMInfos()
-	if err != nil || len(vmInfos) == 0 {
-		rels, err := b.dir.Releases()
-		if err == nil {
-			return VMProgress{State: UploadingReleases, Releases: len(rels), Duration: time.Now().Sub(start)}
-		}
+	if err != nil {
+		return false, err
+	}
+
+	if len(vmInfos) == 0 {
+		return false, nil
 	}
 
-	total := len(vmInfos)
-	numDone := 0
 	for _, v := range vmInfos {
-		if v.ProcessState == "running" && len(v.Processes) > 0 {
-			numDone++
+		if !v.IsRunning() {
+			return false, nil
 		}
 	}
 
-	return VMProgress{State: Deploying, Total: total, Done: numDone, Duration: time.Now().Sub(start)}
+	return true, nil
+}
+
+// DeploymentNames lists the deployments the director has, sorted by name.
+func (b *Bosh) DeploymentNames() ([]string, error) {
+	deps, err := b.dir.Deployments()
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, dep := range deps {
+		names = append(names, dep.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// CleanUp removes releases, stemcells and orphaned disks and vms that are no
+// longer referenced by any deployment
+func (b *Bosh) CleanUp() error {
+	return b.dir.CleanUp(true)
+}
+
+func (b *Bosh) GetVMProgress(ctx context.Context, start time.Time, deploymentName string, isErrand bool) (VMProgress, error) {
+	if b.waitingForLock(deploymentName) {
+		return VMProgress{State: WaitingForLock, Duration: time.Now().Sub(start)}, nil
+	}
+
+	if isErrand {
+		p := VMProgress{State: RunningErrand, Duration: time.Now().Sub(start)}
+		p.Remaining = b.trackPhase(deploymentName, p)
+		return p, nil
+	}
+
+	dep, err := b.findDeployment(ctx, deploymentName)
+	if err != nil {
+		return VMProgress{}, err
+	}
+
+	var p VMProgress
+	vmInfos, err := dep.VMInfos()
+	if err != nil || len(vmInfos) == 0 {
+		if rels, err := b.dir.Releases(); err == nil {
+			p = VMProgress{State: UploadingReleases, Releases: len(rels), Duration: time.Now().Sub(start)}
+			p.Remaining = b.trackPhase(deploymentName, p)
+			return p, nil
+		}
+	}
+
+	instances, numDone := instanceStatuses(vmInfos)
+	p = VMProgress{
+		State:     deployState(vmInfos),
+		Total:     len(vmInfos),
+		Done:      numDone,
+		Duration:  time.Now().Sub(start),
+		Instances: instances,
+	}
+	if err := b.checkFailing(deploymentName, instances); err != nil {
+		p.State = Failed
+		return p, err
+	}
+	p.Remaining = b.trackPhase(deploymentName, p)
+	return p, nil
 }
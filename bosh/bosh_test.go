@@ -1,7 +1,12 @@
 package bosh_test
 
 import (
+	"context"
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
 
 	"code.cloudfoundry.org/cfdev/bosh"
 	"code.cloudfoundry.org/cfdev/bosh/mocks"
@@ -23,6 +28,7 @@ var _ = Describe("Bosh", func() {
 	)
 	BeforeEach(func() {
 		bosh.VMProgressInterval = 0
+		bosh.FindDeploymentBackoff = 0
 		mockController = gomock.NewController(GinkgoT())
 		mockDir = mocks.NewMockDirector(mockController)
 		mockDep = mocks.NewMockDeployment(mockController)
@@ -33,6 +39,16 @@ var _ = Describe("Bosh", func() {
 	})
 
 	Describe("VMProgress", func() {
+		var infoErr error
+
+		JustBeforeEach(func() {
+			mockDir.EXPECT().Info().AnyTimes().Return(boshdir.Info{}, infoErr)
+		})
+
+		BeforeEach(func() {
+			infoErr = nil
+		})
+
 		It("swallows finding cf errors, returns releases until vms, and then vms", func() {
 			mockDir.EXPECT().FindDeployment("cf").Return(nil, errors.New("not found"))
 			mockDir.EXPECT().FindDeployment("cf").Return(nil, errors.New("not found"))
@@ -45,21 +61,23 @@ var _ = Describe("Bosh", func() {
 				mockDepVMInfos.Return(vmInfos, nil)
 			})
 
-			ch := subject.VMProgress("cf")
-			var p bosh.VMProgress
+			ch, err := subject.VMProgress(context.Background(), "cf")
+			Expect(err).NotTo(HaveOccurred())
+			var e bosh.ProgressEvent
 
-			Eventually(ch).Should(Receive(&p))
-			Expect(p.Releases).To(Equal(0))
+			Eventually(ch).Should(Receive(&e))
+			Expect(e.Phase).To(Equal(bosh.UploadingReleases))
+			Expect(e.Progress.Releases).To(Equal(0))
 
-			Eventually(ch).Should(Receive(&p))
-			Expect(p.Releases).To(Equal(2))
+			Eventually(ch).Should(Receive(&e))
+			Expect(e.Progress.Releases).To(Equal(2))
 
 			vmInfos = []boshdir.VMInfo{
 				boshdir.VMInfo{},
 			}
 
 			Eventually(func() []int {
-				p := <-ch
+				p := (<-ch).Progress
 				return []int{p.Releases, p.Total, p.Done}
 			}).Should(Equal([]int{0, 1, 0}))
 
@@ -72,9 +90,352 @@ var _ = Describe("Bosh", func() {
 			}
 
 			Eventually(func() []int {
-				p := <-ch
+				p := (<-ch).Progress
 				return []int{p.Releases, p.Total, p.Done}
 			}).Should(Equal([]int{0, 3, 1}))
 		})
+
+		Context("when the deployment never shows up", func() {
+			var timeout time.Duration
+
+			BeforeEach(func() {
+				timeout = bosh.FindDeploymentTimeout
+				bosh.FindDeploymentTimeout = 50 * time.Millisecond
+				bosh.FindDeploymentBackoff = 10 * time.Millisecond
+			})
+
+			AfterEach(func() {
+				bosh.FindDeploymentTimeout = timeout
+			})
+
+			It("gives up with a director unreachable error", func() {
+				mockDir.EXPECT().FindDeployment("cf").AnyTimes().Return(nil, errors.New("connection refused"))
+
+				_, err := subject.VMProgress(context.Background(), "cf")
+				Expect(err).To(BeAssignableToTypeOf(&bosh.DirectorUnreachableError{}))
+				Expect(err.Error()).To(HavePrefix("bosh director unreachable: no answer about deployment cf after"))
+				Expect(err.Error()).To(HaveSuffix("connection refused"))
+			})
+
+			Context("when the director does not answer", func() {
+				BeforeEach(func() {
+					infoErr = errors.New("dial tcp 10.144.0.4:25555: connect: connection refused")
+				})
+
+				It("gives up with a director unreachable error", func() {
+					mockDir.EXPECT().FindDeployment("cf").AnyTimes().Return(mockDep, nil)
+
+					_, err := subject.VMProgress(context.Background(), "cf")
+					Expect(err).To(BeAssignableToTypeOf(&bosh.DirectorUnreachableError{}))
+					Expect(err.Error()).To(HaveSuffix("connection refused"))
+				})
+			})
+
+			It("gives up at once on certificate errors", func() {
+				mockDir.EXPECT().FindDeployment("cf").Return(nil, errors.New("x509: certificate signed by unknown authority"))
+				bosh.FindDeploymentTimeout = time.Hour
+
+				_, err := subject.VMProgress(context.Background(), "cf")
+				Expect(err).To(BeAssignableToTypeOf(&bosh.DirectorError{}))
+				Expect(err.(*bosh.DirectorError).Category).To(Equal(bosh.TLSError))
+			})
+
+			It("stops waiting when the context is cancelled", func() {
+				mockDir.EXPECT().FindDeployment("cf").AnyTimes().Return(nil, errors.New("connection refused"))
+				bosh.FindDeploymentTimeout = time.Hour
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				_, err := subject.VMProgress(ctx, "cf")
+				Expect(err).To(BeAssignableToTypeOf(&bosh.DirectorUnreachableError{}))
+			})
+		})
+
+		Context("when the director stops answering", func() {
+			var timeout time.Duration
+
+			BeforeEach(func() {
+				timeout = bosh.FindDeploymentTimeout
+				bosh.FindDeploymentTimeout = 50 * time.Millisecond
+			})
+
+			AfterEach(func() {
+				bosh.FindDeploymentTimeout = timeout
+			})
+
+			It("ends the progress as failed", func() {
+				mockDir.EXPECT().FindDeployment("cf").Return(mockDep, nil)
+				mockDep.EXPECT().VMInfos().AnyTimes().Return(nil, errors.New("connection refused"))
+				mockDir.EXPECT().Releases().AnyTimes().Return(nil, errors.New("connection refused"))
+
+				ch, err := subject.VMProgress(context.Background(), "cf")
+				Expect(err).NotTo(HaveOccurred())
+
+				var e bosh.ProgressEvent
+				Eventually(ch).Should(Receive(&e))
+				Expect(e.Phase).To(Equal(bosh.Failed))
+				Expect(e.Message).To(HavePrefix("bosh director unreachable: no answer about deployment cf after"))
+				Eventually(ch).Should(BeClosed())
+			})
+
+			It("ends the progress at once on certificate errors", func() {
+				bosh.FindDeploymentTimeout = time.Hour
+				mockDir.EXPECT().FindDeployment("cf").Return(mockDep, nil)
+				mockDep.EXPECT().VMInfos().AnyTimes().Return(nil, errors.New("x509: certificate has expired"))
+				mockDir.EXPECT().Releases().AnyTimes().Return(nil, errors.New("x509: certificate has expired"))
+
+				ch, err := subject.VMProgress(context.Background(), "cf")
+				Expect(err).NotTo(HaveOccurred())
+
+				var e bosh.ProgressEvent
+				Eventually(ch).Should(Receive(&e))
+				Expect(e.Phase).To(Equal(bosh.Failed))
+				Expect(e.Message).To(ContainSubstring("x509"))
+			})
+		})
+
+		It("closes the channel when the context is cancelled", func() {
+			mockDir.EXPECT().FindDeployment("cf").Return(mockDep, nil)
+			mockDep.EXPECT().VMInfos().AnyTimes().Return([]boshdir.VMInfo{{}}, nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			ch, err := subject.VMProgress(ctx, "cf")
+			Expect(err).NotTo(HaveOccurred())
+			cancel()
+
+			Eventually(ch).Should(BeClosed())
+		})
+	})
+
+	Describe("GetVMProgress", func() {
+		BeforeEach(func() {
+			mockDir.EXPECT().Info().AnyTimes()
+		})
+
+		It("reports the vms that are running", func() {
+			mockDir.EXPECT().FindDeployment("cf").Return(mockDep, nil)
+			mockDep.EXPECT().VMInfos().Return([]boshdir.VMInfo{
+				{ProcessState: "running", Processes: []boshdir.VMInfoProcess{{}}},
+				{ProcessState: "starting"},
+			}, nil)
+
+			p, err := subject.GetVMProgress(context.Background(), time.Now(), "cf", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.State).To(Equal(bosh.Deploying))
+			Expect(p.Total).To(Equal(2))
+			Expect(p.Done).To(Equal(1))
+		})
+
+		It("reports the state of each instance", func() {
+			zero, one := 0, 1
+			mockDir.EXPECT().FindDeployment("cf").Return(mockDep, nil)
+			mockDep.EXPECT().VMInfos().Return([]boshdir.VMInfo{
+				{JobName: "router", Index: &zero, ProcessState: "running", Processes: []boshdir.VMInfoProcess{
+					{Name: "gorouter", State: "running"},
+				}},
+				{JobName: "diego-cell", Index: &one, ProcessState: "failing", Processes: []boshdir.VMInfoProcess{
+					{Name: "rep", State: "failing"},
+					{Name: "garden", State: "running"},
+				}},
+			}, nil)
+
+			p, err := subject.GetVMProgress(context.Background(), time.Now(), "cf", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Instances).To(Equal([]bosh.InstanceStatus{
+				{Job: "router", Index: 0, ProcessState: "running"},
+				{Job: "diego-cell", Index: 1, ProcessState: "failing", FailingProcesses: []string{"rep"}},
+			}))
+			Expect(p.Instances[1].Name()).To(Equal("diego-cell/1"))
+		})
+	})
+
+	Describe("RunErrand", func() {
+		It("returns the output and exit code of each instance", func() {
+			mockDir.EXPECT().FindDeployment("cf").Return(mockDep, nil)
+			mockDep.EXPECT().RunErrand("smoke-tests", false, false, []boshdir.InstanceGroupOrInstanceSlug{}).Return([]boshdir.ErrandResult{
+				{InstanceGroup: "smoke-tests", InstanceID: "abc", ExitCode: 1, Stdout: "some-stdout", Stderr: "some-stderr"},
+			}, nil)
+
+			Expect(subject.RunErrand("cf", "smoke-tests")).To(Equal([]bosh.ErrandResult{
+				{Instance: "smoke-tests/abc", ExitCode: 1, Stdout: "some-stdout", Stderr: "some-stderr"},
+			}))
+		})
+
+		It("returns an error when the errand cannot be run", func() {
+			mockDir.EXPECT().FindDeployment("cf").Return(mockDep, nil)
+			mockDep.EXPECT().RunErrand(gomock.Any(), false, false, gomock.Any()).Return(nil, errors.New("some-error"))
+
+			_, err := subject.RunErrand("cf", "smoke-tests")
+			Expect(err).To(MatchError("some-error"))
+		})
+	})
+
+	Describe("DeleteDeployment", func() {
+		It("deletes the deployment", func() {
+			mockDir.EXPECT().FindDeployment("cf-redis").Return(mockDep, nil)
+			mockDep.EXPECT().Delete(false)
+
+			Expect(subject.DeleteDeployment("cf-redis")).To(Succeed())
+		})
+
+		It("reports the vms left", func() {
+			mockDir.EXPECT().FindDeployment("cf-redis").Return(mockDep, nil)
+			mockDep.EXPECT().VMInfos().Return([]boshdir.VMInfo{{JobName: "redis"}, {JobName: "broker"}}, nil)
+
+			p, err := subject.DeleteProgress(time.Now(), "cf-redis")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.State).To(Equal(bosh.Deleting))
+			Expect(p.Total).To(Equal(2))
+		})
+	})
+
+	Describe("UploadRelease", func() {
+		var tmpDir string
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "cfdev-upload-")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("uploads a local tarball, reporting progress", func() {
+			path := filepath.Join(tmpDir, "release.tgz")
+			Expect(ioutil.WriteFile(path, []byte("some-release"), 0600)).To(Succeed())
+
+			mockDir.EXPECT().UploadReleaseFile(gomock.Any(), false, false).Do(func(file boshdir.UploadFile, _, _ bool) {
+				ioutil.ReadAll(file)
+			})
+
+			var sent, total int64
+			Expect(subject.UploadRelease(path, func(s, t int64) { sent, total = s, t })).To(Succeed())
+			Expect(sent).To(Equal(int64(12)))
+			Expect(total).To(Equal(int64(12)))
+		})
+
+		It("has the director fetch URLs", func() {
+			mockDir.EXPECT().UploadReleaseURL("https://example.com/release.tgz", "", false, false)
+
+			Expect(subject.UploadRelease("https://example.com/release.tgz", nil)).To(Succeed())
+		})
+
+		It("returns an error when the tarball does not exist", func() {
+			Expect(subject.UploadRelease(filepath.Join(tmpDir, "missing.tgz"), nil)).NotTo(Succeed())
+		})
+	})
+
+	Describe("UploadStemcell", func() {
+		It("has the director fetch URLs", func() {
+			mockDir.EXPECT().UploadStemcellURL("https://example.com/stemcell.tgz", "", false)
+
+			Expect(subject.UploadStemcell("https://example.com/stemcell.tgz", nil)).To(Succeed())
+		})
+	})
+
+	Describe("CloudConfig", func() {
+		It("returns the latest cloud config", func() {
+			mockDir.EXPECT().LatestCloudConfig().Return(boshdir.CloudConfig{Properties: "vm_types: []"}, nil)
+
+			Expect(subject.CloudConfig()).To(Equal("vm_types: []"))
+		})
+
+		It("updates the cloud config", func() {
+			mockDir.EXPECT().UpdateCloudConfig([]byte("vm_types: []"))
+
+			Expect(subject.UpdateCloudConfig([]byte("vm_types: []"))).To(Succeed())
+		})
+	})
+
+	Describe("Diff", func() {
+		It("marks the lines that would be added and removed", func() {
+			mockDir.EXPECT().FindDeployment("cf-redis").Return(mockDep, nil)
+			mockDep.EXPECT().Diff([]byte("some-manifest"), false).Return(boshdir.NewDeploymentDiff([][]interface{}{
+				{"instance_groups:", ""},
+				{"- name: redis", ""},
+				{"  instances: 2", "added"},
+				{"  instances: 1", "removed"},
+			}, nil), nil)
+
+			Expect(subject.Diff("cf-redis", []byte("some-manifest"))).To(Equal([]string{
+				"  instance_groups:",
+				"  - name: redis",
+				"+   instances: 2",
+				"-   instances: 1",
+			}))
+		})
+
+		It("returns an error when the director cannot diff the manifest", func() {
+			mockDir.EXPECT().FindDeployment("cf-redis").Return(mockDep, nil)
+			mockDep.EXPECT().Diff(gomock.Any(), false).Return(boshdir.DeploymentDiff{}, errors.New("some-error"))
+
+			_, err := subject.Diff("cf-redis", []byte("some-manifest"))
+			Expect(err).To(MatchError("some-error"))
+		})
+	})
+
+	Describe("Ready", func() {
+		It("asks the director for its info", func() {
+			mockDir.EXPECT().Info().Return(boshdir.Info{}, nil)
+
+			Expect(subject.Ready()).To(Succeed())
+		})
+
+		It("returns an error when the director does not answer", func() {
+			mockDir.EXPECT().Info().Return(boshdir.Info{}, errors.New("some-error"))
+
+			Expect(subject.Ready()).To(MatchError("some-error"))
+		})
+	})
+
+	Describe("DeploymentNames", func() {
+		It("lists the director's deployments by name", func() {
+			mockCF := mocks.NewMockDeployment(mockController)
+			mockCF.EXPECT().Name().Return("cf")
+			mockMysql := mocks.NewMockDeployment(mockController)
+			mockMysql.EXPECT().Name().Return("cf-mysql")
+			mockDir.EXPECT().Deployments().Return([]boshdir.Deployment{mockMysql, mockCF}, nil)
+
+			Expect(subject.DeploymentNames()).To(Equal([]string{"cf", "cf-mysql"}))
+		})
+
+		It("returns an error when the director does not answer", func() {
+			mockDir.EXPECT().Deployments().Return(nil, errors.New("some-error"))
+
+			_, err := subject.DeploymentNames()
+			Expect(err).To(MatchError("some-error"))
+		})
+	})
+
+	Describe("DeploymentRunning", func() {
+		BeforeEach(func() {
+			mockDir.EXPECT().FindDeployment("mysql").Return(mockDep, nil)
+		})
+
+		It("is true when all vms are running", func() {
+			mockDep.EXPECT().VMInfos().Return([]boshdir.VMInfo{
+				{ProcessState: "running", Processes: []boshdir.VMInfoProcess{{State: "running"}}},
+				{ProcessState: "running"},
+			}, nil)
+
+			Expect(subject.DeploymentRunning("mysql")).To(BeTrue())
+		})
+
+		It("is false when a process is not running yet", func() {
+			mockDep.EXPECT().VMInfos().Return([]boshdir.VMInfo{
+				{ProcessState: "running", Processes: []boshdir.VMInfoProcess{{State: "starting"}}},
+			}, nil)
+
+			Expect(subject.DeploymentRunning("mysql")).To(BeFalse())
+		})
+
+		It("is false when there are no vms yet", func() {
+			mockDep.EXPECT().VMInfos().Return([]boshdir.VMInfo{}, nil)
+
+			Expect(subject.DeploymentRunning("mysql")).To(BeFalse())
+		})
 	})
 })
@@ -0,0 +1,17 @@
+package bosh
+
+// CloudConfig returns the director's current cloud config: the vm types,
+// networks and azs deployments are placed on.
+func (b *Bosh) CloudConfig() (string, error) {
+	cloudConfig, err := b.dir.LatestCloudConfig()
+	if err != nil {
+		return "", err
+	}
+	return cloudConfig.Properties, nil
+}
+
+// UpdateCloudConfig replaces the director's cloud config with manifest.
+// Deployments pick up the change when they are next deployed.
+func (b *Bosh) UpdateCloudConfig(manifest []byte) error {
+	return b.dir.UpdateCloudConfig(manifest)
+}
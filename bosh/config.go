@@ -19,16 +19,26 @@ type Config struct {
 	GatewayUsername   string
 }
 
+// FetchConfig returns how to reach the director. When it authenticates with
+// UAA, the UAA client stands in for the admin user, as the bosh cli finds
+// UAA through the director itself.
 func FetchConfig(cfg config.Config) (Config, error) {
-	content, err := ioutil.ReadFile(filepath.Join(cfg.StateBosh, "secret"))
-	if err != nil {
+	username, secret := "admin", ""
+	if uaaConfig, ok, err := ReadUAAConfig(cfg); err != nil {
 		return Config{}, err
-	}
+	} else if ok {
+		username, secret = uaaConfig.Client, uaaConfig.ClientSecret
+	} else {
+		content, err := ioutil.ReadFile(filepath.Join(cfg.StateBosh, "secret"))
+		if err != nil {
+			return Config{}, err
+		}
 
-	secret := strings.TrimSpace(string(content))
+		secret = strings.TrimSpace(string(content))
+	}
 
 	return Config{
-		AdminUsername:     "admin",
+		AdminUsername:     username,
 		AdminPassword:     secret,
 		CACertificate:     filepath.Join(cfg.StateBosh, "ca.crt"),
 		DirectorAddress:   cfg.BoshDirectorIP,
@@ -38,20 +48,51 @@ func FetchConfig(cfg config.Config) (Config, error) {
 	}, nil
 }
 
+// EnvVar is an environment variable the bosh cli reads.
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// EnvVars are the variables that point the bosh cli at the director, in
+// the order they are exported.
+func (c Config) EnvVars() []EnvVar {
+	return []EnvVar{
+		{"BOSH_ENVIRONMENT", c.DirectorAddress},
+		{"BOSH_CLIENT", c.AdminUsername},
+		{"BOSH_CLIENT_SECRET", c.AdminPassword},
+		{"BOSH_CA_CERT", c.CACertificate},
+		{"BOSH_GW_HOST", c.GatewayHost},
+		{"BOSH_GW_PRIVATE_KEY", c.GatewayPrivateKey},
+		{"BOSH_GW_USER", c.GatewayUsername},
+	}
+}
+
 func Envs(cfg config.Config) []string {
 	 boshConfig, _ := FetchConfig(cfg)
 
-	return []string{
-		"BOSH_ENVIRONMENT=" + boshConfig.DirectorAddress,
-		"BOSH_CLIENT=" + boshConfig.AdminUsername,
-		"BOSH_CLIENT_SECRET=" + boshConfig.AdminPassword,
-		"BOSH_CA_CERT=" + boshConfig.CACertificate,
-		"BOSH_GW_HOST=" + boshConfig.GatewayHost,
-		"BOSH_GW_USER=" + boshConfig.GatewayUsername,
-		"BOSH_GW_PRIVATE_KEY=" + boshConfig.GatewayPrivateKey,
+	var envs []string
+	for _, envVar := range boshConfig.EnvVars() {
+		envs = append(envs, envVar.Name+"="+envVar.Value)
+	}
+
+	return append(envs,
 		"SERVICES_DIR=" + cfg.ServicesDir,
 		"CACHE_DIR=" + cfg.CacheDir,
 		"BOSH_STATE=" + cfg.StateBosh,
 		"CF_DOMAIN=" + cfg.CFDomain,
+		"BOSH_DIRECTOR_IP=" + cfg.BoshDirectorIP,
+		"CF_ROUTER_IP=" + cfg.CFRouterIP,
+		"CF_SUBNET=" + cfg.CFSubnet,
+		"SERVICES_SUBNET=" + servicesSubnet(cfg),
+	)
+}
+
+// servicesSubnet is where the services are deployed, the cf subnet unless
+// the config gives them their own.
+func servicesSubnet(cfg config.Config) string {
+	if cfg.ServicesSubnet != "" {
+		return cfg.ServicesSubnet
 	}
+	return cfg.CFSubnet
 }
@@ -0,0 +1,33 @@
+package bosh
+
+import "time"
+
+// DeleteDeployment deletes the deployment along with its vms and disks,
+// and waits for the director to finish.
+func (b *Bosh) DeleteDeployment(deploymentName string) error {
+	dep, err := b.dir.FindDeployment(deploymentName)
+	if err != nil {
+		return err
+	}
+
+	return dep.Delete(false)
+}
+
+// DeleteProgress reports how many vms of a deployment being deleted are
+// left. Once the deployment is gone there are none left.
+func (b *Bosh) DeleteProgress(start time.Time, deploymentName string) (VMProgress, error) {
+	p := VMProgress{State: Deleting, Duration: time.Now().Sub(start)}
+
+	dep, err := b.dir.FindDeployment(deploymentName)
+	if err != nil {
+		return VMProgress{}, err
+	}
+
+	vmInfos, err := dep.VMInfos()
+	if err != nil {
+		return p, nil
+	}
+
+	p.Total = len(vmInfos)
+	return p, nil
+}
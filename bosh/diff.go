@@ -0,0 +1,36 @@
+package bosh
+
+import "fmt"
+
+// Diff previews the changes deploying manifest would make to the
+// deployment, as the changed lines of the manifest with some context. Lines
+// are marked "+ " when added, "- " when removed and indented otherwise.
+// Secrets are redacted. There are no lines when nothing would change.
+func (b *Bosh) Diff(deploymentName string, manifest []byte) ([]string, error) {
+	dep, err := b.dir.FindDeployment(deploymentName)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := dep.Diff(manifest, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, line := range diff.Diff {
+		if len(line) != 2 {
+			continue
+		}
+
+		marker := "  "
+		switch line[1] {
+		case "added":
+			marker = "+ "
+		case "removed":
+			marker = "- "
+		}
+		lines = append(lines, fmt.Sprintf("%s%v", marker, line[0]))
+	}
+	return lines, nil
+}
@@ -0,0 +1,41 @@
+package bosh
+
+import (
+	"fmt"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+)
+
+// ErrandResult is the outcome of an errand on one instance.
+type ErrandResult struct {
+	Instance string
+	ExitCode int
+	Stdout   string
+	Stderr   string
+}
+
+// RunErrand runs the errand of the deployment and waits for it to finish.
+// An errand that runs but exits non-zero is not an error; check the exit
+// code of each result.
+func (b *Bosh) RunErrand(deploymentName, errand string) ([]ErrandResult, error) {
+	dep, err := b.dir.FindDeployment(deploymentName)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := dep.RunErrand(errand, false, false, []boshdir.InstanceGroupOrInstanceSlug{})
+	if err != nil {
+		return nil, err
+	}
+
+	var errandResults []ErrandResult
+	for _, result := range results {
+		errandResults = append(errandResults, ErrandResult{
+			Instance: fmt.Sprintf("%s/%s", result.InstanceGroup, result.InstanceID),
+			ExitCode: result.ExitCode,
+			Stdout:   result.Stdout,
+			Stderr:   result.Stderr,
+		})
+	}
+	return errandResults, nil
+}
@@ -0,0 +1,151 @@
+package bosh
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+)
+
+// phaseSamples is how many past durations of a phase are averaged, so that
+// estimates follow changes to the host or the deployment.
+const phaseSamples = 5
+
+// PhaseHistory keeps how long each phase of each deployment took on past
+// starts, so that later starts can estimate the time remaining.
+type PhaseHistory struct {
+	Path string
+}
+
+// deployment -> phase -> most recent durations
+type phaseDurations map[string]map[string][]time.Duration
+
+// Estimate is the average duration of the phase on past deploys.
+func (h *PhaseHistory) Estimate(deployment, phase string) (time.Duration, bool) {
+	samples := h.load()[deployment][phase]
+	if len(samples) == 0 {
+		return 0, false
+	}
+
+	var total time.Duration
+	for _, sample := range samples {
+		total += sample
+	}
+	return total / time.Duration(len(samples)), true
+}
+
+// Record adds a duration of the phase, dropping the oldest once there are
+// more than phaseSamples.
+func (h *PhaseHistory) Record(deployment, phase string, duration time.Duration) error {
+	durations := h.load()
+	if durations[deployment] == nil {
+		durations[deployment] = map[string][]time.Duration{}
+	}
+
+	samples := append(durations[deployment][phase], duration)
+	if len(samples) > phaseSamples {
+		samples = samples[len(samples)-phaseSamples:]
+	}
+	durations[deployment][phase] = samples
+
+	contents, err := json.Marshal(durations)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(h.Path, contents, 0644)
+}
+
+// load treats a missing or unreadable history as empty, as it only
+// affects estimates.
+func (h *PhaseHistory) load() phaseDurations {
+	durations := phaseDurations{}
+
+	contents, err := ioutil.ReadFile(h.Path)
+	if err != nil {
+		return durations
+	}
+	json.Unmarshal(contents, &durations)
+	return durations
+}
+
+// phase is the one a deployment is in and when it began.
+type phase struct {
+	name    string
+	started time.Time
+}
+
+// trackPhase records the end of the deployment's previous phase when it
+// moves on to p.State, and estimates the time left of the deployment.
+// Deploying ends once every vm is running.
+func (b *Bosh) trackPhase(deployment string, p VMProgress) time.Duration {
+	if b.history == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.phases == nil {
+		b.phases = map[string]*phase{}
+	}
+
+	now := time.Now()
+	current := b.phases[deployment]
+	if current == nil || current.name != p.State {
+		if current != nil && current.name != "" {
+			b.record(deployment, current.name, now.Sub(current.started))
+		}
+		current = &phase{name: p.State, started: now}
+		b.phases[deployment] = current
+	}
+
+	if p.State == Deploying && p.Total > 0 && p.Done >= p.Total {
+		b.record(deployment, Deploying, now.Sub(current.started))
+		current.name = ""
+		return 0
+	}
+
+	remaining := b.remaining(deployment, current.name, now.Sub(current.started))
+	if current.name == UploadingReleases {
+		if estimate, ok := b.history.Estimate(deployment, Deploying); ok {
+			remaining += estimate
+		}
+	}
+	return remaining
+}
+
+// FinishPhase records the end of the deployment's current phase, for
+// phases such as errands whose end progress reporting cannot see.
+func (b *Bosh) FinishPhase(deployment string) {
+	if b.history == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if current := b.phases[deployment]; current != nil && current.name != "" {
+		b.record(deployment, current.name, time.Now().Sub(current.started))
+		current.name = ""
+	}
+}
+
+// record keeps the duration of a phase for estimates and for the timings
+// report. b.mu must be held.
+func (b *Bosh) record(deployment, phase string, duration time.Duration) {
+	b.history.Record(deployment, phase, duration)
+	if b.timings != nil {
+		b.timings.Add(Timing{
+			Deployment: deployment,
+			Phase:      phase,
+			Duration:   duration,
+			FinishedAt: time.Now(),
+		})
+	}
+}
+
+func (b *Bosh) remaining(deployment, phase string, elapsed time.Duration) time.Duration {
+	estimate, ok := b.history.Estimate(deployment, phase)
+	if !ok || elapsed >= estimate {
+		return 0
+	}
+	return estimate - elapsed
+}
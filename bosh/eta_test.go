@@ -0,0 +1,103 @@
+package bosh_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/bosh/mocks"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PhaseHistory", func() {
+	var (
+		dir     string
+		history *bosh.PhaseHistory
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "phase-history")
+		Expect(err).NotTo(HaveOccurred())
+		history = &bosh.PhaseHistory{Path: filepath.Join(dir, "phase-durations.json")}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("has no estimate before anything is recorded", func() {
+		_, ok := history.Estimate("cf", bosh.Deploying)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("estimates the average of the last five durations", func() {
+		for _, minutes := range []int{100, 10, 10, 10, 20, 20} {
+			Expect(history.Record("cf", bosh.Deploying, time.Duration(minutes)*time.Minute)).To(Succeed())
+		}
+
+		estimate, ok := history.Estimate("cf", bosh.Deploying)
+		Expect(ok).To(BeTrue())
+		Expect(estimate).To(Equal(14 * time.Minute))
+
+		_, ok = history.Estimate("mysql", bosh.Deploying)
+		Expect(ok).To(BeFalse())
+	})
+
+	Context("when tracking progress", func() {
+		var (
+			mockController *gomock.Controller
+			mockDir        *mocks.MockDirector
+			mockDep        *mocks.MockDeployment
+			subject        *bosh.Bosh
+		)
+
+		BeforeEach(func() {
+			mockController = gomock.NewController(GinkgoT())
+			mockDir = mocks.NewMockDirector(mockController)
+			mockDep = mocks.NewMockDeployment(mockController)
+			subject = bosh.NewWithDirector(mockDir).WithPhaseHistory(history)
+			mockDir.EXPECT().FindDeployment("cf").AnyTimes().Return(mockDep, nil)
+			mockDir.EXPECT().Info().AnyTimes()
+		})
+
+		AfterEach(func() {
+			mockController.Finish()
+		})
+
+		It("estimates the time remaining and records each phase", func() {
+			Expect(history.Record("cf", bosh.UploadingReleases, 5*time.Minute)).To(Succeed())
+			Expect(history.Record("cf", bosh.Deploying, 20*time.Minute)).To(Succeed())
+
+			mockDep.EXPECT().VMInfos().Return(nil, nil)
+			mockDir.EXPECT().Releases().Return([]boshdir.Release{nil}, nil)
+			p, err := subject.GetVMProgress(context.Background(), time.Now(), "cf", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Remaining).To(BeNumerically("~", 25*time.Minute, time.Second))
+
+			mockDep.EXPECT().VMInfos().Return([]boshdir.VMInfo{{ProcessState: "starting"}}, nil)
+			p, err = subject.GetVMProgress(context.Background(), time.Now(), "cf", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Remaining).To(BeNumerically("~", 20*time.Minute, time.Second))
+
+			mockDep.EXPECT().VMInfos().Return([]boshdir.VMInfo{
+				{ProcessState: "running", Processes: []boshdir.VMInfoProcess{{State: "running"}}},
+			}, nil)
+			p, err = subject.GetVMProgress(context.Background(), time.Now(), "cf", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Remaining).To(BeZero())
+
+			// the short phases just recorded pull the estimates down
+			estimate, _ := history.Estimate("cf", bosh.UploadingReleases)
+			Expect(estimate).To(BeNumerically("<", 3*time.Minute))
+			estimate, _ = history.Estimate("cf", bosh.Deploying)
+			Expect(estimate).To(BeNumerically("<", 11*time.Minute))
+		})
+	})
+})
@@ -0,0 +1,100 @@
+package bosh
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ProgressEventVersion is the version of the json form of ProgressEvent.
+// It changes only when a field is removed or changes meaning; new fields
+// may be added within a version.
+const ProgressEventVersion = 1
+
+// Done is the phase of the event sent once a deployment has finished.
+const Done = "done"
+
+// ProgressEvent is a point in the progress of a deployment, for showing
+// to the user or, as json, to tools driving cf dev.
+type ProgressEvent struct {
+	Deployment string
+	Phase      string
+	// Percent is how far through the deployment's vms it is. It stays at
+	// zero until the director starts creating vms.
+	Percent   int
+	Message   string
+	StartedAt time.Time
+	Time      time.Time
+	Progress  VMProgress
+}
+
+// NewProgressEvent describes p, taken at now, of the deployment that
+// started at start.
+func NewProgressEvent(deployment string, start, now time.Time, p VMProgress) ProgressEvent {
+	event := ProgressEvent{
+		Deployment: deployment,
+		Phase:      p.State,
+		StartedAt:  start,
+		Time:       now,
+		Progress:   p,
+	}
+
+	switch p.State {
+	case UploadingReleases:
+		event.Message = fmt.Sprintf("Uploaded Releases: %d", p.Releases)
+	case Compiling:
+		event.Message = "Compiling packages"
+	case Deploying:
+		event.Message = fmt.Sprintf("Progress: %d of %d", p.Done, p.Total)
+		if p.Total > 0 {
+			event.Percent = p.Done * 100 / p.Total
+		}
+	case RunningErrand:
+		event.Message = "Running errand"
+	case WaitingForLock:
+		event.Message = "Waiting for another task to release the deployment"
+	case Deleting:
+		event.Message = fmt.Sprintf("VMs left: %d", p.Total)
+	case Done:
+		event.Message = "Done"
+		event.Percent = 100
+	}
+	return event
+}
+
+func (e ProgressEvent) MarshalJSON() ([]byte, error) {
+	var waitingOn []string
+	for _, instance := range e.Progress.Instances {
+		if instance.ProcessState != "running" || len(instance.FailingProcesses) > 0 {
+			waitingOn = append(waitingOn, instance.Name())
+		}
+	}
+
+	return json.Marshal(struct {
+		Version          int       `json:"version"`
+		Deployment       string    `json:"deployment"`
+		Phase            string    `json:"phase"`
+		Percent          int       `json:"percent"`
+		Message          string    `json:"message"`
+		StartedAt        time.Time `json:"started_at"`
+		Time             time.Time `json:"time"`
+		Releases         int       `json:"releases"`
+		Done             int       `json:"done"`
+		Total            int       `json:"total"`
+		RemainingSeconds int       `json:"remaining_seconds,omitempty"`
+		WaitingOn        []string  `json:"waiting_on,omitempty"`
+	}{
+		Version:          ProgressEventVersion,
+		Deployment:       e.Deployment,
+		Phase:            e.Phase,
+		Percent:          e.Percent,
+		Message:          e.Message,
+		StartedAt:        e.StartedAt.UTC(),
+		Time:             e.Time.UTC(),
+		Releases:         e.Progress.Releases,
+		Done:             e.Progress.Done,
+		Total:            e.Progress.Total,
+		RemainingSeconds: int(e.Progress.Remaining.Seconds()),
+		WaitingOn:        waitingOn,
+	})
+}
@@ -0,0 +1,66 @@
+package bosh_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProgressEvent", func() {
+	var start time.Time
+
+	BeforeEach(func() {
+		start = time.Date(2018, 9, 1, 12, 0, 0, 0, time.UTC)
+	})
+
+	It("computes the percent of vms that are running", func() {
+		event := bosh.NewProgressEvent("cf", start, start.Add(time.Minute), bosh.VMProgress{
+			State: bosh.Deploying,
+			Done:  3,
+			Total: 12,
+		})
+
+		Expect(event.Phase).To(Equal(bosh.Deploying))
+		Expect(event.Percent).To(Equal(25))
+		Expect(event.Message).To(Equal("Progress: 3 of 12"))
+	})
+
+	It("is complete once the deployment is done", func() {
+		event := bosh.NewProgressEvent("cf", start, start, bosh.VMProgress{State: bosh.Done})
+
+		Expect(event.Percent).To(Equal(100))
+	})
+
+	It("marshals to versioned json", func() {
+		event := bosh.NewProgressEvent("cf", start, start.Add(time.Minute), bosh.VMProgress{
+			State:     bosh.Deploying,
+			Done:      1,
+			Total:     2,
+			Remaining: 90 * time.Second,
+			Instances: []bosh.InstanceStatus{
+				{Job: "api", Index: 0, ProcessState: "running"},
+				{Job: "diego-cell", Index: 0, ProcessState: "failing", FailingProcesses: []string{"rep"}},
+			},
+		})
+
+		contents, err := json.Marshal(event)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(contents).To(MatchJSON(`{
+			"version": 1,
+			"deployment": "cf",
+			"phase": "deploying",
+			"percent": 50,
+			"message": "Progress: 1 of 2",
+			"started_at": "2018-09-01T12:00:00Z",
+			"time": "2018-09-01T12:01:00Z",
+			"releases": 0,
+			"done": 1,
+			"total": 2,
+			"remaining_seconds": 90,
+			"waiting_on": ["diego-cell/0"]
+		}`))
+	})
+})
@@ -0,0 +1,73 @@
+package bosh
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FailingTimeout is how long a deployment's instances may be failing
+// before it is taken to have failed. Processes fail for a while as they
+// start, e.g. until the database they depend on is up, so a single sighting
+// means little.
+var FailingTimeout = 5 * time.Minute
+
+// DeploymentFailedError is returned once instances of a deployment have
+// been failing for longer than FailingTimeout.
+type DeploymentFailedError struct {
+	Deployment string
+	Instances  []InstanceStatus
+	Failing    time.Duration
+}
+
+func (e *DeploymentFailedError) Error() string {
+	var names []string
+	for _, instance := range e.Instances {
+		name := instance.Name()
+		if len(instance.FailingProcesses) > 0 {
+			name = fmt.Sprintf("%s [%s]", name, strings.Join(instance.FailingProcesses, ", "))
+		}
+		names = append(names, name)
+	}
+
+	return fmt.Sprintf("deployment %s failed: %s failing for %s",
+		e.Deployment, strings.Join(names, ", "), e.Failing.Round(time.Second))
+}
+
+// checkFailing returns a DeploymentFailedError once some instance of the
+// deployment has been failing for FailingTimeout. The wait starts over
+// whenever none are failing.
+func (b *Bosh) checkFailing(deploymentName string, instances []InstanceStatus) error {
+	var failing []InstanceStatus
+	for _, instance := range instances {
+		if instance.ProcessState == "failing" {
+			failing = append(failing, instance)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failingSince == nil {
+		b.failingSince = map[string]time.Time{}
+	}
+	if len(failing) == 0 {
+		delete(b.failingSince, deploymentName)
+		return nil
+	}
+
+	since, ok := b.failingSince[deploymentName]
+	if !ok {
+		b.failingSince[deploymentName] = time.Now()
+		return nil
+	}
+
+	if elapsed := time.Since(since); elapsed >= FailingTimeout {
+		return &DeploymentFailedError{
+			Deployment: deploymentName,
+			Instances:  failing,
+			Failing:    elapsed,
+		}
+	}
+	return nil
+}
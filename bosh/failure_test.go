@@ -0,0 +1,109 @@
+package bosh_test
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/bosh/mocks"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Failure detection", func() {
+	var (
+		mockController *gomock.Controller
+		mockDir        *mocks.MockDirector
+		mockDep        *mocks.MockDeployment
+		subject        *bosh.Bosh
+		zero           = 0
+		failing        []boshdir.VMInfo
+	)
+
+	BeforeEach(func() {
+		bosh.VMProgressInterval = 0
+		bosh.FailingTimeout = 20 * time.Millisecond
+		mockController = gomock.NewController(GinkgoT())
+		mockDir = mocks.NewMockDirector(mockController)
+		mockDep = mocks.NewMockDeployment(mockController)
+		subject = bosh.NewWithDirector(mockDir)
+
+		failing = []boshdir.VMInfo{
+			{JobName: "router", Index: &zero, ProcessState: "running", Processes: []boshdir.VMInfoProcess{{Name: "gorouter", State: "running"}}},
+			{JobName: "diego-cell", Index: &zero, ProcessState: "failing", Processes: []boshdir.VMInfoProcess{
+				{Name: "rep", State: "failing"},
+				{Name: "garden", State: "running"},
+			}},
+		}
+		mockDir.EXPECT().FindDeployment("cf").Return(mockDep, nil).AnyTimes()
+		mockDir.EXPECT().Info().AnyTimes()
+	})
+
+	AfterEach(func() {
+		bosh.VMProgressInterval = 1 * time.Second
+		bosh.FailingTimeout = 5 * time.Minute
+		mockController.Finish()
+	})
+
+	Describe("GetVMProgress", func() {
+		It("keeps deploying while instances have only just started failing", func() {
+			mockDep.EXPECT().VMInfos().Return(failing, nil)
+
+			p, err := subject.GetVMProgress(context.Background(), time.Now(), "cf", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.State).To(Equal(bosh.Deploying))
+		})
+
+		It("fails once instances have been failing for too long", func() {
+			mockDep.EXPECT().VMInfos().Return(failing, nil).Times(2)
+
+			_, err := subject.GetVMProgress(context.Background(), time.Now(), "cf", false)
+			Expect(err).NotTo(HaveOccurred())
+			time.Sleep(bosh.FailingTimeout)
+
+			p, err := subject.GetVMProgress(context.Background(), time.Now(), "cf", false)
+			Expect(p.State).To(Equal(bosh.Failed))
+			Expect(err).To(MatchError(ContainSubstring("deployment cf failed: diego-cell/0 [rep] failing for")))
+
+			failed, ok := err.(*bosh.DeploymentFailedError)
+			Expect(ok).To(BeTrue())
+			Expect(failed.Instances).To(Equal([]bosh.InstanceStatus{
+				{Job: "diego-cell", Index: 0, ProcessState: "failing", FailingProcesses: []string{"rep"}},
+			}))
+		})
+
+		It("starts the wait over once no instances are failing", func() {
+			recovered := []boshdir.VMInfo{failing[0]}
+			gomock.InOrder(
+				mockDep.EXPECT().VMInfos().Return(failing, nil),
+				mockDep.EXPECT().VMInfos().Return(recovered, nil),
+				mockDep.EXPECT().VMInfos().Return(failing, nil),
+			)
+
+			for i := 0; i < 3; i++ {
+				p, err := subject.GetVMProgress(context.Background(), time.Now(), "cf", false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(p.State).To(Equal(bosh.Deploying))
+				time.Sleep(bosh.FailingTimeout)
+			}
+		})
+	})
+
+	Describe("VMProgress", func() {
+		It("sends a failed event and stops once instances have been failing for too long", func() {
+			mockDep.EXPECT().VMInfos().Return(failing, nil).MinTimes(2)
+
+			ch, err := subject.VMProgress(context.Background(), "cf")
+			Expect(err).NotTo(HaveOccurred())
+
+			var last bosh.ProgressEvent
+			for event := range ch {
+				last = event
+			}
+			Expect(last.Phase).To(Equal(bosh.Failed))
+			Expect(last.Message).To(ContainSubstring("diego-cell/0 [rep]"))
+		})
+	})
+})
@@ -0,0 +1,121 @@
+package bosh
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+)
+
+// Categories of DirectorError, so the user can be pointed at the cause.
+const (
+	TLSError       = "tls"
+	AuthError      = "auth"
+	NetworkError   = "network"
+	UnhealthyError = "unhealthy"
+)
+
+var (
+	// ReadyInterval is the wait between health checks in WaitReady.
+	ReadyInterval = 2 * time.Second
+	// StalledTaskAge is how long a task may sit queued, with nothing being
+	// processed, before the director's workers are considered stuck.
+	StalledTaskAge = 5 * time.Minute
+)
+
+// DirectorError is returned when one of the director's health checks
+// fails.
+type DirectorError struct {
+	Check    string
+	Category string
+	Err      error
+}
+
+func (e *DirectorError) Error() string {
+	return fmt.Sprintf("bosh director %s check failed (%s): %s", e.Check, e.Category, e.Err)
+}
+
+// Retryable is false for errors that will not go away by waiting, such as
+// a certificate or credentials that do not match the director's.
+func (e *DirectorError) Retryable() bool {
+	return e.Category == NetworkError || e.Category == UnhealthyError
+}
+
+// Health checks that the director answers /info, accepts our credentials
+// and is working through its task queue.
+func (b *Bosh) Health() error {
+	if _, err := b.dir.Info(); err != nil {
+		return directorError("info", err)
+	}
+
+	tasks, err := b.dir.CurrentTasks(boshdir.TasksFilter{})
+	if err != nil {
+		return directorError("auth", err)
+	}
+
+	return taskQueueHealth(tasks, time.Now())
+}
+
+// WaitReady checks the director's health until it passes or ctx is done.
+// It gives up straight away on errors that waiting will not fix.
+func (b *Bosh) WaitReady(ctx context.Context) error {
+	for {
+		err := b.Health()
+		if err == nil {
+			return nil
+		}
+		if dirErr, ok := err.(*DirectorError); ok && !dirErr.Retryable() {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(ReadyInterval):
+		}
+	}
+}
+
+// taskQueueHealth fails when tasks have been queued for a long time while
+// nothing is being processed.
+func taskQueueHealth(tasks []boshdir.Task, now time.Time) error {
+	var stalled int
+	for _, task := range tasks {
+		switch task.State() {
+		case "processing":
+			return nil
+		case "queued":
+			if now.Sub(task.StartedAt()) > StalledTaskAge {
+				stalled++
+			}
+		}
+	}
+
+	if stalled > 0 {
+		return &DirectorError{
+			Check:    "task queue",
+			Category: UnhealthyError,
+			Err:      fmt.Errorf("%d tasks queued for over %s with none processing", stalled, StalledTaskAge),
+		}
+	}
+	return nil
+}
+
+// directorError categorizes a failed request. The director client wraps
+// errors as text, so only their messages are left to go by.
+func directorError(check string, err error) *DirectorError {
+	msg := strings.ToLower(err.Error())
+
+	category := NetworkError
+	switch {
+	case strings.Contains(msg, "x509:"), strings.Contains(msg, "tls:"):
+		category = TLSError
+	case strings.Contains(msg, "401"), strings.Contains(msg, "403"),
+		strings.Contains(msg, "unauthorized"), strings.Contains(msg, "invalid_client"):
+		category = AuthError
+	}
+
+	return &DirectorError{Check: check, Category: category, Err: err}
+}
@@ -0,0 +1,117 @@
+package bosh_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/bosh/mocks"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Health", func() {
+	var (
+		subject        *bosh.Bosh
+		mockController *gomock.Controller
+		mockDir        *mocks.MockDirector
+	)
+
+	BeforeEach(func() {
+		bosh.ReadyInterval = 0
+		mockController = gomock.NewController(GinkgoT())
+		mockDir = mocks.NewMockDirector(mockController)
+		subject = bosh.NewWithDirector(mockDir)
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	task := func(state string, age time.Duration) boshdir.Task {
+		t := mocks.NewMockTask(mockController)
+		t.EXPECT().State().AnyTimes().Return(state)
+		t.EXPECT().StartedAt().AnyTimes().Return(time.Now().Add(-age))
+		return t
+	}
+
+	It("passes when the director answers and is working through its tasks", func() {
+		mockDir.EXPECT().Info()
+		mockDir.EXPECT().CurrentTasks(boshdir.TasksFilter{}).Return([]boshdir.Task{
+			task("processing", time.Hour),
+			task("queued", time.Hour),
+		}, nil)
+
+		Expect(subject.Health()).To(Succeed())
+	})
+
+	It("fails when tasks are stuck in the queue", func() {
+		mockDir.EXPECT().Info()
+		mockDir.EXPECT().CurrentTasks(gomock.Any()).Return([]boshdir.Task{
+			task("queued", time.Hour),
+			task("queued", time.Second),
+		}, nil)
+
+		err := subject.Health()
+		Expect(err).To(BeAssignableToTypeOf(&bosh.DirectorError{}))
+		Expect(err.(*bosh.DirectorError).Category).To(Equal(bosh.UnhealthyError))
+		Expect(err).To(MatchError(ContainSubstring("1 tasks queued")))
+	})
+
+	It("categorizes certificate errors", func() {
+		mockDir.EXPECT().Info().Return(boshdir.Info{}, errors.New("x509: certificate signed by unknown authority"))
+
+		err := subject.Health()
+		Expect(err).To(BeAssignableToTypeOf(&bosh.DirectorError{}))
+		Expect(err.(*bosh.DirectorError).Check).To(Equal("info"))
+		Expect(err.(*bosh.DirectorError).Category).To(Equal(bosh.TLSError))
+	})
+
+	It("categorizes network errors", func() {
+		mockDir.EXPECT().Info().Return(boshdir.Info{}, errors.New("dial tcp 10.144.0.4:25555: connect: connection refused"))
+
+		err := subject.Health()
+		Expect(err).To(BeAssignableToTypeOf(&bosh.DirectorError{}))
+		Expect(err.(*bosh.DirectorError).Category).To(Equal(bosh.NetworkError))
+	})
+
+	It("categorizes rejected credentials", func() {
+		mockDir.EXPECT().Info()
+		mockDir.EXPECT().CurrentTasks(gomock.Any()).Return(nil, errors.New("Director responded with non-successful status code '401'"))
+
+		err := subject.Health()
+		Expect(err).To(BeAssignableToTypeOf(&bosh.DirectorError{}))
+		Expect(err.(*bosh.DirectorError).Check).To(Equal("auth"))
+		Expect(err.(*bosh.DirectorError).Category).To(Equal(bosh.AuthError))
+	})
+
+	Describe("WaitReady", func() {
+		It("retries until the director is healthy", func() {
+			gomock.InOrder(
+				mockDir.EXPECT().Info().Return(boshdir.Info{}, errors.New("connection refused")),
+				mockDir.EXPECT().Info(),
+				mockDir.EXPECT().CurrentTasks(gomock.Any()),
+			)
+
+			Expect(subject.WaitReady(context.Background())).To(Succeed())
+		})
+
+		It("gives up at once on credential errors", func() {
+			mockDir.EXPECT().Info()
+			mockDir.EXPECT().CurrentTasks(gomock.Any()).Return(nil, errors.New("invalid_client"))
+
+			Expect(subject.WaitReady(context.Background())).To(MatchError(ContainSubstring("(auth)")))
+		})
+
+		It("returns the last error when the context is done", func() {
+			mockDir.EXPECT().Info().AnyTimes().Return(boshdir.Info{}, errors.New("connection refused"))
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			Expect(subject.WaitReady(ctx)).To(MatchError(ContainSubstring("connection refused")))
+		})
+	})
+})
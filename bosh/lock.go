@@ -0,0 +1,164 @@
+package bosh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WaitingForLock is the state of a deployment while another task of the
+// director holds its lock, e.g. an earlier deploy that is still running.
+const WaitingForLock = "waiting-for-lock"
+
+var (
+	// LockBackoff is the wait after finding a deployment locked. It doubles
+	// after each check, up to maxLockBackoff.
+	LockBackoff = 2 * time.Second
+	// LockTimeout bounds how long RunLocked waits for other tasks to
+	// release a deployment.
+	LockTimeout = 30 * time.Minute
+)
+
+const maxLockBackoff = 30 * time.Second
+
+// lockErrors are what the director says when a deployment is locked by
+// another task.
+var lockErrors = []string{
+	"is in progress",
+	"failed to acquire lock",
+	"timed out getting lock",
+}
+
+// IsLockError reports whether err is the director refusing to work on a
+// deployment because another task holds its lock.
+func IsLockError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, lockError := range lockErrors {
+		if strings.Contains(message, lockError) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunLocked runs run, e.g. a deploy script, once no other task holds the
+// deployment's lock. If run fails because another task took the lock
+// first, it waits for that task and runs it again, backing off between
+// attempts, until ctx is done or LockTimeout passes. While waiting,
+// GetVMProgress reports WaitingForLock for the deployment.
+func (b *Bosh) RunLocked(ctx context.Context, deploymentName string, run func() error) error {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, LockTimeout)
+	defer cancel()
+
+	backoff := LockBackoff
+	for {
+		if err := b.waitForLock(ctx, deploymentName); err != nil {
+			return err
+		}
+
+		err := run()
+		if err == nil {
+			return nil
+		}
+		// Our own task has finished, so any lock left is another task's.
+		task, locked := b.lockHolder(deploymentName)
+		if !locked && !IsLockError(err) {
+			return err
+		}
+
+		// The locks may not be listed, so backing off here keeps a lock
+		// only run sees from being retried in a tight loop.
+		select {
+		case <-ctx.Done():
+			return lockedError(deploymentName, task, time.Since(start), err)
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxLockBackoff {
+			backoff = maxLockBackoff
+		}
+	}
+}
+
+// waitForLock returns once no task holds the deployment's lock, backing
+// off between checks.
+func (b *Bosh) waitForLock(ctx context.Context, deploymentName string) error {
+	start := time.Now()
+	defer b.setWaitingForLock(deploymentName, false)
+
+	backoff := LockBackoff
+	for {
+		task, locked := b.lockHolder(deploymentName)
+		if !locked {
+			return nil
+		}
+		b.setWaitingForLock(deploymentName, true)
+
+		select {
+		case <-ctx.Done():
+			return lockedError(deploymentName, task, time.Since(start), nil)
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxLockBackoff {
+			backoff = maxLockBackoff
+		}
+	}
+}
+
+// lockedError is the error to give up waiting for a deployment's lock
+// with, naming the task that holds it when it is known.
+func lockedError(deploymentName, task string, waited time.Duration, err error) error {
+	holder := ""
+	if task != "" {
+		holder = " by task " + task
+	}
+	message := fmt.Sprintf("deployment %s is still locked%s after %s", deploymentName, holder, waited.Round(time.Second))
+	if err != nil {
+		message += ": " + err.Error()
+	}
+	return errors.New(message)
+}
+
+// lockHolder returns the task holding the deployment's lock, if any. When
+// the locks cannot be listed, the deployment is taken to be unlocked, and
+// the deploy itself reports what is wrong.
+func (b *Bosh) lockHolder(deploymentName string) (string, bool) {
+	locks, err := b.dir.Locks()
+	if err != nil {
+		return "", false
+	}
+
+	for _, lock := range locks {
+		if lock.Type == "deployment" && len(lock.Resource) > 0 && lock.Resource[0] == deploymentName {
+			return lock.TaskID, true
+		}
+	}
+	return "", false
+}
+
+func (b *Bosh) setWaitingForLock(deploymentName string, waiting bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.waiting == nil {
+		b.waiting = map[string]bool{}
+	}
+	b.waiting[deploymentName] = waiting
+}
+
+func (b *Bosh) waitingForLock(deploymentName string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.waiting[deploymentName]
+}
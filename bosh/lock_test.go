@@ -0,0 +1,180 @@
+package bosh_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/bosh/mocks"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Locks", func() {
+	var (
+		mockController *gomock.Controller
+		mockDir        *mocks.MockDirector
+		subject        *bosh.Bosh
+		cfLock         []boshdir.Lock
+	)
+
+	BeforeEach(func() {
+		bosh.LockBackoff = time.Millisecond
+		mockController = gomock.NewController(GinkgoT())
+		mockDir = mocks.NewMockDirector(mockController)
+		subject = bosh.NewWithDirector(mockDir)
+		cfLock = []boshdir.Lock{{Type: "deployment", Resource: []string{"cf"}, TaskID: "42"}}
+	})
+
+	AfterEach(func() {
+		bosh.LockTimeout = 30 * time.Minute
+		mockController.Finish()
+	})
+
+	Describe("IsLockError", func() {
+		It("recognizes the director's lock errors", func() {
+			Expect(bosh.IsLockError(errors.New("Deployment 'cf' is in progress"))).To(BeTrue())
+			Expect(bosh.IsLockError(errors.New("Failed to acquire lock for lock:deployment:cf uid: 1"))).To(BeTrue())
+			Expect(bosh.IsLockError(errors.New("Timed out getting lock for lock:deployment:cf"))).To(BeTrue())
+		})
+
+		It("does not mistake other errors for them", func() {
+			Expect(bosh.IsLockError(errors.New("some-error"))).To(BeFalse())
+			Expect(bosh.IsLockError(nil)).To(BeFalse())
+		})
+	})
+
+	Describe("RunLocked", func() {
+		It("runs at once when the deployment is not locked", func() {
+			mockDir.EXPECT().Locks().Return(nil, nil)
+
+			ran := 0
+			Expect(subject.RunLocked(context.Background(), "cf", func() error {
+				ran++
+				return nil
+			})).To(Succeed())
+			Expect(ran).To(Equal(1))
+		})
+
+		It("waits for another task to release the deployment", func() {
+			gomock.InOrder(
+				mockDir.EXPECT().Locks().Return(cfLock, nil),
+				mockDir.EXPECT().Locks().Return([]boshdir.Lock{{Type: "deployment", Resource: []string{"cf-mysql"}}}, nil),
+			)
+
+			ran := 0
+			Expect(subject.RunLocked(context.Background(), "cf", func() error {
+				ran++
+				return nil
+			})).To(Succeed())
+			Expect(ran).To(Equal(1))
+		})
+
+		It("reports WaitingForLock while the deployment is locked", func() {
+			director := &lockingDirector{MockDirector: mockDir, locks: cfLock}
+			subject = bosh.NewWithDirector(director)
+
+			done := make(chan error, 1)
+			go func() {
+				done <- subject.RunLocked(context.Background(), "cf", func() error { return nil })
+			}()
+
+			Eventually(func() string {
+				p, _ := subject.GetVMProgress(context.Background(), time.Now(), "cf", true)
+				return p.State
+			}).Should(Equal(bosh.WaitingForLock))
+
+			director.release()
+			Eventually(done).Should(Receive(BeNil()))
+
+			p, _ := subject.GetVMProgress(context.Background(), time.Now(), "cf", true)
+			Expect(p.State).To(Equal(bosh.RunningErrand))
+		})
+
+		It("runs again when another task took the lock first", func() {
+			gomock.InOrder(
+				mockDir.EXPECT().Locks().Return(nil, nil),
+				mockDir.EXPECT().Locks().Return(cfLock, nil),
+				mockDir.EXPECT().Locks().Return(nil, nil),
+			)
+
+			ran := 0
+			Expect(subject.RunLocked(context.Background(), "cf", func() error {
+				ran++
+				if ran == 1 {
+					return errors.New("exit status 1")
+				}
+				return nil
+			})).To(Succeed())
+			Expect(ran).To(Equal(2))
+		})
+
+		It("returns other errors", func() {
+			mockDir.EXPECT().Locks().Return(nil, nil).Times(2)
+
+			Expect(subject.RunLocked(context.Background(), "cf", func() error {
+				return errors.New("some-error")
+			})).To(MatchError("some-error"))
+		})
+
+		It("gives up once the lock has been held too long", func() {
+			bosh.LockTimeout = 10 * time.Millisecond
+			mockDir.EXPECT().Locks().Return(cfLock, nil).AnyTimes()
+
+			err := subject.RunLocked(context.Background(), "cf", func() error {
+				Fail("should not run")
+				return nil
+			})
+			Expect(err).To(MatchError(ContainSubstring("deployment cf is still locked by task 42")))
+		})
+
+		It("backs off and gives up when run keeps finding the deployment locked", func() {
+			bosh.LockTimeout = 50 * time.Millisecond
+			mockDir.EXPECT().Locks().Return(nil, errors.New("some-error")).AnyTimes()
+
+			ran := 0
+			err := subject.RunLocked(context.Background(), "cf", func() error {
+				ran++
+				return errors.New("Deployment 'cf' is in progress")
+			})
+			Expect(err).To(MatchError(ContainSubstring("deployment cf is still locked after")))
+			Expect(err).To(MatchError(ContainSubstring("is in progress")))
+			Expect(ran).To(BeNumerically("<", 10))
+		})
+
+		It("stops when the context is done", func() {
+			mockDir.EXPECT().Locks().Return(nil, errors.New("some-error")).AnyTimes()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			err := subject.RunLocked(ctx, "cf", func() error {
+				cancel()
+				return errors.New("Deployment 'cf' is in progress")
+			})
+			Expect(err).To(MatchError(ContainSubstring("deployment cf is still locked")))
+		})
+	})
+})
+
+// lockingDirector holds a lock until it is released.
+type lockingDirector struct {
+	*mocks.MockDirector
+
+	mu    sync.Mutex
+	locks []boshdir.Lock
+}
+
+func (d *lockingDirector) Locks() ([]boshdir.Lock, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.locks, nil
+}
+
+func (d *lockingDirector) release() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.locks = nil
+}
@@ -0,0 +1,137 @@
+package bosh
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Failed is the phase of the event sent once a deployment has failed.
+const Failed = "failed"
+
+// DefaultWorkers is how many deployments the director works on at once
+// unless told otherwise. More than that only queue behind each other.
+const DefaultWorkers = 3
+
+// Job is one deployment for an Orchestrator to run. Run deploys it, e.g.
+// by running its deploy script, and returns once it is deployed.
+type Job struct {
+	Deployment string
+	IsErrand   bool
+	Run        func() error
+}
+
+// Orchestrator runs several deployments at once and reports their
+// progress on a single channel.
+type Orchestrator struct {
+	Bosh *Bosh
+	// Workers is how many jobs run at once. Zero uses DefaultWorkers.
+	Workers int
+}
+
+// Run starts the jobs, no more than Workers at a time, and sends their
+// progress events, each naming its deployment, on the first channel. That
+// channel is closed once every job has finished, and then the second
+// channel receives an error naming the jobs that failed, or nil.
+func (o *Orchestrator) Run(ctx context.Context, jobs []Job) (chan ProgressEvent, chan error) {
+	workers := o.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	events := make(chan ProgressEvent, len(jobs))
+	result := make(chan error, 1)
+	slots := make(chan struct{}, workers)
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed []string
+	)
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			// The slot is freed once the job returns, which can be after
+			// run when its deployment is found failing first, so that the next
+			// job does not start while the failed one still works on the
+			// director.
+			release := func() { <-slots }
+
+			if err := o.run(ctx, job, events, release); err != nil {
+				mu.Lock()
+				failed = append(failed, fmt.Sprintf("%s: %s", job.Deployment, err))
+				mu.Unlock()
+			}
+		}(job)
+	}
+
+	go func() {
+		wg.Wait()
+		close(events)
+
+		switch {
+		case len(failed) > 0:
+			result <- fmt.Errorf("failed to deploy %s", strings.Join(failed, ", "))
+		case ctx.Err() != nil:
+			result <- ctx.Err()
+		default:
+			result <- nil
+		}
+	}()
+
+	return events, result
+}
+
+// run deploys the job, polling its progress until it finishes or its
+// instances are stuck failing. release is called once the job returns.
+func (o *Orchestrator) run(ctx context.Context, job Job, events chan ProgressEvent, release func()) error {
+	start := time.Now()
+
+	// Finishing the job stops any wait for the deployment to show up.
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer release()
+		err := job.Run()
+		cancel()
+		done <- err
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			o.Bosh.FinishPhase(job.Deployment)
+			p := VMProgress{State: Done, Duration: time.Now().Sub(start)}
+			event := NewProgressEvent(job.Deployment, start, time.Now(), p)
+			if err != nil {
+				event.Phase = Failed
+				event.Message = err.Error()
+				event.Percent = 0
+			}
+			send(ctx, events, event)
+			return err
+		case <-time.After(VMProgressInterval):
+			p, err := o.Bosh.GetVMProgress(jobCtx, start, job.Deployment, job.IsErrand)
+			if failed, ok := err.(*DeploymentFailedError); ok {
+				event := NewProgressEvent(job.Deployment, start, time.Now(), p)
+				event.Message = failed.Error()
+				send(ctx, events, event)
+				return failed
+			}
+			if err != nil {
+				continue
+			}
+			send(jobCtx, events, NewProgressEvent(job.Deployment, start, time.Now(), p))
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package bosh_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/bosh/mocks"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Orchestrator", func() {
+	var (
+		mockController *gomock.Controller
+		mockDir        *mocks.MockDirector
+		mockDep        *mocks.MockDeployment
+		subject        *bosh.Orchestrator
+	)
+
+	BeforeEach(func() {
+		bosh.VMProgressInterval = time.Millisecond
+		mockController = gomock.NewController(GinkgoT())
+		mockDir = mocks.NewMockDirector(mockController)
+		mockDep = mocks.NewMockDeployment(mockController)
+
+		mockDir.EXPECT().FindDeployment(gomock.Any()).AnyTimes().Return(mockDep, nil)
+		mockDir.EXPECT().Info().AnyTimes()
+		mockDep.EXPECT().VMInfos().AnyTimes().Return([]boshdir.VMInfo{{ProcessState: "starting"}}, nil)
+
+		subject = &bosh.Orchestrator{Bosh: bosh.NewWithDirector(mockDir), Workers: 2}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	drain := func(events chan bosh.ProgressEvent) map[string]string {
+		final := map[string]string{}
+		for event := range events {
+			final[event.Deployment] = event.Phase
+		}
+		return final
+	}
+
+	It("deploys every job and reports each one's progress", func() {
+		release := make(chan struct{})
+		job := func() error {
+			<-release
+			return nil
+		}
+		events, result := subject.Run(context.Background(), []bosh.Job{
+			{Deployment: "cf-mysql", Run: job},
+			{Deployment: "cf-redis", Run: job},
+		})
+
+		seen := map[string]bool{}
+		Eventually(func() int {
+			event := <-events
+			seen[event.Deployment] = event.Phase == bosh.Deploying
+			return len(seen)
+		}).Should(Equal(2))
+		close(release)
+
+		final := drain(events)
+		Expect(final).To(HaveKeyWithValue("cf-mysql", bosh.Done))
+		Expect(final).To(HaveKeyWithValue("cf-redis", bosh.Done))
+		Expect(<-result).To(Succeed())
+	})
+
+	It("runs no more jobs at once than there are workers", func() {
+		var running, most int32
+		job := func() error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&most)
+				if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		}
+
+		events, result := subject.Run(context.Background(), []bosh.Job{
+			{Deployment: "a", Run: job},
+			{Deployment: "b", Run: job},
+			{Deployment: "c", Run: job},
+			{Deployment: "d", Run: job},
+		})
+		drain(events)
+
+		Expect(<-result).To(Succeed())
+		Expect(atomic.LoadInt32(&most)).To(Equal(int32(2)))
+	})
+
+	It("names the jobs that failed", func() {
+		events, result := subject.Run(context.Background(), []bosh.Job{
+			{Deployment: "cf-mysql", Run: func() error { return nil }},
+			{Deployment: "cf-redis", Run: func() error { return errors.New("some-error") }},
+		})
+
+		final := drain(events)
+		Expect(final).To(HaveKeyWithValue("cf-redis", bosh.Failed))
+		Expect(<-result).To(MatchError("failed to deploy cf-redis: some-error"))
+	})
+
+	Context("when a deployment is found failing before its job returns", func() {
+		BeforeEach(func() {
+			bosh.FailingTimeout = 0
+			zero := 0
+			failingDir := mocks.NewMockDirector(mockController)
+			failingDep := mocks.NewMockDeployment(mockController)
+			failingDir.EXPECT().FindDeployment(gomock.Any()).AnyTimes().Return(failingDep, nil)
+			failingDir.EXPECT().Info().AnyTimes()
+			failingDep.EXPECT().VMInfos().AnyTimes().Return([]boshdir.VMInfo{{JobName: "diego-cell", Index: &zero, ProcessState: "failing"}}, nil)
+
+			subject = &bosh.Orchestrator{Bosh: bosh.NewWithDirector(failingDir), Workers: 1}
+		})
+
+		AfterEach(func() {
+			bosh.FailingTimeout = 5 * time.Minute
+		})
+
+		It("does not start the next job until the failed one returns", func() {
+			var started int32
+			release := make(chan struct{})
+			job := func() error {
+				atomic.AddInt32(&started, 1)
+				<-release
+				return nil
+			}
+			events, result := subject.Run(context.Background(), []bosh.Job{
+				{Deployment: "cf-mysql", Run: job},
+				{Deployment: "cf-redis", Run: job},
+			})
+
+			var failed string
+			Eventually(func() string {
+				event := <-events
+				if event.Phase == bosh.Failed || event.Message != "" {
+					failed = event.Deployment
+				}
+				return failed
+			}).ShouldNot(BeEmpty())
+			Consistently(func() int32 { return atomic.LoadInt32(&started) }, 100*time.Millisecond).Should(Equal(int32(1)))
+
+			close(release)
+			drain(events)
+			Expect(atomic.LoadInt32(&started)).To(Equal(int32(2)))
+			Expect(<-result).To(MatchError(ContainSubstring("failing")))
+		})
+	})
+})
@@ -0,0 +1,73 @@
+package bosh
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/httpclient"
+	socks5 "github.com/cloudfoundry/socks5-proxy"
+)
+
+// AllProxyEnv names the proxy that all traffic to the director goes
+// through, as the bosh cli does. It is either a socks5:// url, or an
+// ssh+socks5://user@host:port?private-key=PATH url to tunnel through an ssh
+// jump box. HTTPS_PROXY and NO_PROXY are honored as well.
+const AllProxyEnv = "BOSH_ALL_PROXY"
+
+// ValidateProxy checks a BOSH_ALL_PROXY value up front. The director client
+// only fails on its first request when the proxy is misconfigured, with an
+// error that does not say why.
+func ValidateProxy(allProxy string) error {
+	if allProxy == "" {
+		return nil
+	}
+
+	proxyURL, err := url.Parse(strings.TrimPrefix(allProxy, "ssh+"))
+	if err != nil {
+		return err
+	}
+	if proxyURL.Scheme != "socks5" {
+		return fmt.Errorf("unsupported proxy scheme %q, expected socks5 or ssh+socks5", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return fmt.Errorf("proxy url %s has no host", allProxy)
+	}
+
+	if !strings.HasPrefix(allProxy, "ssh+") {
+		return nil
+	}
+
+	keyPath := proxyURL.Query().Get("private-key")
+	if keyPath == "" {
+		return fmt.Errorf("ssh+socks5 proxy url is missing the private-key query param")
+	}
+	if _, err := os.Stat(keyPath); err != nil {
+		return fmt.Errorf("reading proxy private key: %s", err)
+	}
+	return nil
+}
+
+// Transport is an http transport for other clients of the director vm,
+// e.g. for CredHub and UAA, that goes through the same proxy as the
+// director client.
+func Transport(pool *x509.CertPool) *http.Transport {
+	dialer := httpclient.SOCKS5DialFuncFromEnvironment((&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).Dial, socks5.NewSocks5Proxy(socks5.NewHostKey(), log.New(ioutil.Discard, "", log.LstdFlags)))
+
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: pool},
+		Proxy:           http.ProxyFromEnvironment,
+		Dial:            dialer,
+	}
+}
@@ -0,0 +1,57 @@
+package bosh_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateProxy", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-proxy-")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("allows no proxy", func() {
+		Expect(bosh.ValidateProxy("")).To(Succeed())
+	})
+
+	It("allows a socks5 proxy", func() {
+		Expect(bosh.ValidateProxy("socks5://localhost:1080")).To(Succeed())
+	})
+
+	It("allows an ssh tunnel with a private key", func() {
+		keyPath := filepath.Join(tmpDir, "jumpbox.key")
+		Expect(ioutil.WriteFile(keyPath, []byte("some-key"), 0600)).To(Succeed())
+
+		Expect(bosh.ValidateProxy("ssh+socks5://jumpbox@10.0.0.5:22?private-key=" + keyPath)).To(Succeed())
+	})
+
+	It("rejects other schemes", func() {
+		Expect(bosh.ValidateProxy("http://localhost:8080")).To(MatchError(ContainSubstring(`unsupported proxy scheme "http"`)))
+	})
+
+	It("rejects a url without a host", func() {
+		Expect(bosh.ValidateProxy("socks5://")).To(MatchError(ContainSubstring("has no host")))
+	})
+
+	It("rejects an ssh tunnel without a private key", func() {
+		Expect(bosh.ValidateProxy("ssh+socks5://jumpbox@10.0.0.5:22")).To(MatchError(ContainSubstring("missing the private-key")))
+	})
+
+	It("rejects an ssh tunnel whose private key does not exist", func() {
+		err := bosh.ValidateProxy("ssh+socks5://jumpbox@10.0.0.5:22?private-key=" + filepath.Join(tmpDir, "missing.key"))
+		Expect(err).To(MatchError(ContainSubstring("reading proxy private key")))
+	})
+})
@@ -0,0 +1,36 @@
+package bosh
+
+import boshdir "github.com/cloudfoundry/bosh-cli/director"
+
+// Recover has the director resolve the problems it finds in each of its
+// deployments with their default resolutions, as bosh cloud-check --auto
+// does. Once the vm has rebooted its containers are gone, and the default
+// resolution recreates them, attaching their persistent disks again.
+func (b *Bosh) Recover() error {
+	deps, err := b.dir.Deployments()
+	if err != nil {
+		return err
+	}
+
+	for _, dep := range deps {
+		problems, err := dep.ScanForProblems()
+		if err != nil {
+			return err
+		}
+		if len(problems) == 0 {
+			continue
+		}
+
+		var answers []boshdir.ProblemAnswer
+		for _, problem := range problems {
+			answers = append(answers, boshdir.ProblemAnswer{
+				ProblemID:  problem.ID,
+				Resolution: boshdir.ProblemResolutionDefault,
+			})
+		}
+		if err := dep.ResolveProblems(answers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package bosh_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/bosh/mocks"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recover", func() {
+	var (
+		mockController *gomock.Controller
+		mockDir        *mocks.MockDirector
+		mockCF         *mocks.MockDeployment
+		mockMysql      *mocks.MockDeployment
+		subject        *bosh.Bosh
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockDir = mocks.NewMockDirector(mockController)
+		mockCF = mocks.NewMockDeployment(mockController)
+		mockMysql = mocks.NewMockDeployment(mockController)
+		subject = bosh.NewWithDirector(mockDir)
+
+		mockDir.EXPECT().Deployments().Return([]boshdir.Deployment{mockCF, mockMysql}, nil)
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("resolves the problems of each deployment with their defaults", func() {
+		mockCF.EXPECT().ScanForProblems().Return([]boshdir.Problem{{ID: 4, Type: "missing_vm"}, {ID: 5, Type: "missing_vm"}}, nil)
+		mockCF.EXPECT().ResolveProblems([]boshdir.ProblemAnswer{
+			{ProblemID: 4, Resolution: boshdir.ProblemResolutionDefault},
+			{ProblemID: 5, Resolution: boshdir.ProblemResolutionDefault},
+		})
+		mockMysql.EXPECT().ScanForProblems().Return(nil, nil)
+
+		Expect(subject.Recover()).To(Succeed())
+	})
+
+	It("returns an error when the director cannot scan a deployment", func() {
+		mockCF.EXPECT().ScanForProblems().Return(nil, errors.New("some-error"))
+
+		Expect(subject.Recover()).To(MatchError("some-error"))
+	})
+})
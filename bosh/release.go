@@ -0,0 +1,67 @@
+package bosh
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	"gopkg.in/yaml.v2"
+)
+
+// ReleaseManifest is the name and version a release tarball declares in
+// its release.MF.
+type ReleaseManifest struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+}
+
+// HasRelease reports whether the director already has the version of the
+// release, so that uploading it again can be skipped.
+func (b *Bosh) HasRelease(name, version string) (bool, error) {
+	return b.dir.HasRelease(name, version, boshdir.OSVersionSlug{})
+}
+
+// ReadReleaseManifest reads the release.MF of a local release tarball.
+func ReadReleaseManifest(tarball string) (ReleaseManifest, error) {
+	file, err := os.Open(tarball)
+	if err != nil {
+		return ReleaseManifest{}, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return ReleaseManifest{}, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return ReleaseManifest{}, fmt.Errorf("%s has no release.MF", tarball)
+		} else if err != nil {
+			return ReleaseManifest{}, err
+		}
+
+		if path.Clean(header.Name) != "release.MF" {
+			continue
+		}
+
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return ReleaseManifest{}, err
+		}
+
+		var manifest ReleaseManifest
+		if err := yaml.Unmarshal(content, &manifest); err != nil {
+			return ReleaseManifest{}, err
+		}
+		return manifest, nil
+	}
+}
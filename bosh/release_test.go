@@ -0,0 +1,94 @@
+package bosh_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/bosh/mocks"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Releases", func() {
+	var (
+		mockController *gomock.Controller
+		mockDir        *mocks.MockDirector
+		subject        *bosh.Bosh
+		tmpDir         string
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockDir = mocks.NewMockDirector(mockController)
+		subject = bosh.NewWithDirector(mockDir)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-release-")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+		mockController.Finish()
+	})
+
+	writeTarball := func(files map[string]string) string {
+		path := filepath.Join(tmpDir, "release.tgz")
+		file, err := os.Create(path)
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+
+		gz := gzip.NewWriter(file)
+		tw := tar.NewWriter(gz)
+		for name, content := range files {
+			Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))})).To(Succeed())
+			_, err := tw.Write([]byte(content))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(tw.Close()).To(Succeed())
+		Expect(gz.Close()).To(Succeed())
+		return path
+	}
+
+	Describe("HasRelease", func() {
+		It("asks the director for the release version", func() {
+			mockDir.EXPECT().HasRelease("some-release", "1.2.3", boshdir.OSVersionSlug{}).Return(true, nil)
+
+			Expect(subject.HasRelease("some-release", "1.2.3")).To(BeTrue())
+		})
+	})
+
+	Describe("ReadReleaseManifest", func() {
+		It("reads the name and version from release.MF", func() {
+			path := writeTarball(map[string]string{
+				"./release.MF": "name: some-release\nversion: 1.2.3\n",
+			})
+
+			Expect(bosh.ReadReleaseManifest(path)).To(Equal(bosh.ReleaseManifest{
+				Name:    "some-release",
+				Version: "1.2.3",
+			}))
+		})
+
+		It("returns an error when there is no release.MF", func() {
+			path := writeTarball(map[string]string{"./packages/some.tgz": "some-package"})
+
+			_, err := bosh.ReadReleaseManifest(path)
+			Expect(err).To(MatchError(ContainSubstring("has no release.MF")))
+		})
+
+		It("returns an error when the file is not a tarball", func() {
+			path := filepath.Join(tmpDir, "release.tgz")
+			Expect(ioutil.WriteFile(path, []byte("some-release"), 0600)).To(Succeed())
+
+			_, err := bosh.ReadReleaseManifest(path)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,153 @@
+package bosh
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+
+	cfdevssh "code.cloudfoundry.org/cfdev/ssh"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSH runs cmd on an instance, e.g. diego-cell/0, or opens an interactive
+// shell on it when cmd is empty. The director adds a temporary user with a
+// new key to the instance, which is removed again afterwards, and the
+// instance is reached through the director vm.
+func (b *Bosh) SSH(instance string, cmd []string) error {
+	slug, err := boshdir.NewAllOrInstanceGroupOrInstanceSlugFromString(instance)
+	if err != nil {
+		return err
+	}
+
+	dep, err := b.instanceDeployment(slug.Name())
+	if err != nil {
+		return err
+	}
+
+	opts, privateKey, err := boshdir.NewSSHOpts(boshuuid.NewGenerator())
+	if err != nil {
+		return err
+	}
+
+	result, err := dep.SetUpSSH(slug, opts)
+	if err != nil {
+		return err
+	}
+	defer dep.CleanUpSSH(slug, opts)
+
+	if len(result.Hosts) == 0 {
+		return fmt.Errorf("no instances match %s", instance)
+	}
+
+	client, err := b.dialInstance(result.Hosts[0], opts.Username, privateKey)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	session.Stdin = os.Stdin
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
+
+	if len(cmd) > 0 {
+		return session.Run(strings.Join(cmd, " "))
+	}
+	return cfdevssh.LoginShell(session)
+}
+
+// instanceDeployment finds the deployment with the instance group, so
+// that users do not have to know which deployment a vm belongs to.
+func (b *Bosh) instanceDeployment(group string) (boshdir.Deployment, error) {
+	deps, err := b.dir.Deployments()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dep := range deps {
+		vmInfos, err := dep.VMInfos()
+		if err != nil {
+			continue
+		}
+		for _, v := range vmInfos {
+			if v.JobName == group {
+				return dep, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no deployment has an instance group named %s", group)
+}
+
+func (b *Bosh) dialInstance(host boshdir.Host, username string, privateKey string) (*ssh.Client, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(host.HostPublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("parsing the host key of %s/%s: %s", host.Job, host.IndexOrID, err)
+	}
+
+	config := &ssh.ClientConfig{
+		User:            username,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+	}
+	address := net.JoinHostPort(host.Host, "22")
+
+	if b.config.GatewayHost == "" {
+		return ssh.Dial("tcp", address, config)
+	}
+
+	gateway, err := b.dialGateway()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := gateway.Dial("tcp", address)
+	if err != nil {
+		gateway.Close()
+		return nil, err
+	}
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, address, config)
+	if err != nil {
+		gateway.Close()
+		return nil, err
+	}
+
+	client := ssh.NewClient(c, chans, reqs)
+	go func() {
+		client.Wait()
+		gateway.Close()
+	}()
+	return client, nil
+}
+
+func (b *Bosh) dialGateway() (*ssh.Client, error) {
+	key, err := ioutil.ReadFile(b.config.GatewayPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse gateway private key: %s", err)
+	}
+
+	return ssh.Dial("tcp", net.JoinHostPort(b.config.GatewayHost, "22"), &ssh.ClientConfig{
+		User:            b.config.GatewayUsername,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+}
@@ -0,0 +1,67 @@
+package bosh_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/bosh/mocks"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SSH", func() {
+	var (
+		mockController *gomock.Controller
+		mockDir        *mocks.MockDirector
+		mockCF         *mocks.MockDeployment
+		mockMysql      *mocks.MockDeployment
+		subject        *bosh.Bosh
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockDir = mocks.NewMockDirector(mockController)
+		mockCF = mocks.NewMockDeployment(mockController)
+		mockMysql = mocks.NewMockDeployment(mockController)
+		subject = bosh.NewWithDirector(mockDir)
+
+		mockDir.EXPECT().Deployments().Return([]boshdir.Deployment{mockMysql, mockCF}, nil).AnyTimes()
+		mockMysql.EXPECT().VMInfos().Return([]boshdir.VMInfo{{JobName: "mysql"}}, nil).AnyTimes()
+		mockCF.EXPECT().VMInfos().Return([]boshdir.VMInfo{{JobName: "router"}, {JobName: "diego-cell"}}, nil).AnyTimes()
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("returns an error when no deployment has the instance group", func() {
+		err := subject.SSH("some-group/0", nil)
+		Expect(err).To(MatchError("no deployment has an instance group named some-group"))
+	})
+
+	It("returns an error when the director cannot set up ssh", func() {
+		slug := boshdir.NewAllOrInstanceGroupOrInstanceSlug("diego-cell", "0")
+		mockCF.EXPECT().SetUpSSH(slug, gomock.Any()).Return(boshdir.SSHResult{}, errors.New("some-error"))
+
+		Expect(subject.SSH("diego-cell/0", nil)).To(MatchError("some-error"))
+	})
+
+	It("cleans up the ssh user when no instances match", func() {
+		slug := boshdir.NewAllOrInstanceGroupOrInstanceSlug("diego-cell", "5")
+		var opts boshdir.SSHOpts
+		gomock.InOrder(
+			mockCF.EXPECT().SetUpSSH(slug, gomock.Any()).Do(func(_ boshdir.AllOrInstanceGroupOrInstanceSlug, o boshdir.SSHOpts) {
+				opts = o
+			}).Return(boshdir.SSHResult{}, nil),
+			mockCF.EXPECT().CleanUpSSH(slug, gomock.Any()).Do(func(_ boshdir.AllOrInstanceGroupOrInstanceSlug, o boshdir.SSHOpts) {
+				Expect(o).To(Equal(opts))
+			}),
+		)
+
+		Expect(subject.SSH("diego-cell/5", nil)).To(MatchError("no instances match diego-cell/5"))
+		Expect(opts.Username).To(HavePrefix("bosh_"))
+		Expect(opts.PublicKey).To(HavePrefix("ssh-rsa "))
+	})
+})
@@ -0,0 +1,175 @@
+package bosh
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+)
+
+// CurrentTask returns the id of the task the director is running for the
+// deployment.
+func (b *Bosh) CurrentTask(deploymentName string) (int, error) {
+	tasks, err := b.dir.CurrentTasks(boshdir.TasksFilter{Deployment: deploymentName})
+	if err != nil {
+		return 0, err
+	}
+	if len(tasks) == 0 {
+		return 0, fmt.Errorf("no task is running for deployment %s", deploymentName)
+	}
+	return tasks[0].ID(), nil
+}
+
+// TaskInfo is a task the director has run, e.g. a deploy. Result holds the
+// error message of a failed task.
+type TaskInfo struct {
+	ID             int
+	State          string
+	Description    string
+	Deployment     string
+	Result         string
+	StartedAt      time.Time
+	LastActivityAt time.Time
+}
+
+// Failed reports whether the task errored, rather than being cancelled or
+// still running.
+func (t TaskInfo) Failed() bool {
+	return t.State == "error"
+}
+
+// RecentTasks returns the last n tasks the director ran, newest first.
+func (b *Bosh) RecentTasks(n int) ([]TaskInfo, error) {
+	tasks, err := b.dir.RecentTasks(n, boshdir.TasksFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []TaskInfo
+	for _, task := range tasks {
+		infos = append(infos, TaskInfo{
+			ID:             task.ID(),
+			State:          task.State(),
+			Description:    task.Description(),
+			Deployment:     task.DeploymentName(),
+			Result:         task.Result(),
+			StartedAt:      task.StartedAt(),
+			LastActivityAt: task.LastActivityAt(),
+		})
+	}
+	return infos, nil
+}
+
+// CancelCurrentTasks cancels the tasks the director is running or has
+// queued for the deployment, so that an interrupted deploy does not hold
+// the deployment's lock and block the next one.
+func (b *Bosh) CancelCurrentTasks(deploymentName string) error {
+	tasks, err := b.dir.CurrentTasks(boshdir.TasksFilter{Deployment: deploymentName})
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, task := range tasks {
+		if err := task.Cancel(); err != nil {
+			failed = append(failed, fmt.Sprintf("task %d: %s", task.ID(), err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to cancel %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// StreamTaskLogs writes the task's events to w as they happen, one line per
+// event, until the task finishes.
+func (b *Bosh) StreamTaskLogs(taskID int, w io.Writer) error {
+	task, err := b.dir.FindTask(taskID)
+	if err != nil {
+		return err
+	}
+
+	events := &eventWriter{w: w}
+	err = task.EventOutput(events)
+	events.flush()
+	return err
+}
+
+// taskEvent is a line of a director task's event log.
+type taskEvent struct {
+	Stage    string   `json:"stage"`
+	Tags     []string `json:"tags"`
+	Total    int      `json:"total"`
+	Task     string   `json:"task"`
+	Index    int      `json:"index"`
+	State    string   `json:"state"`
+	Progress int      `json:"progress"`
+	Error    *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (e taskEvent) String() string {
+	if e.Stage == "" && e.Error != nil {
+		return "Error: " + e.Error.Message
+	}
+
+	stage := e.Stage
+	if len(e.Tags) > 0 {
+		stage = fmt.Sprintf("%s (%s)", stage, strings.Join(e.Tags, ", "))
+	}
+
+	line := fmt.Sprintf("%s: %s (%d/%d) %s", stage, e.Task, e.Index, e.Total, e.State)
+	if e.Error != nil {
+		line += ": " + e.Error.Message
+	}
+	return line
+}
+
+// eventWriter turns the chunks of event log the director sends into
+// readable lines. Chunks need not end on a line boundary.
+type eventWriter struct {
+	w       io.Writer
+	partial []byte
+}
+
+func (e *eventWriter) TaskStarted(id int)            {}
+func (e *eventWriter) TaskFinished(id int, _ string) {}
+
+func (e *eventWriter) TaskOutputChunk(id int, chunk []byte) {
+	e.partial = append(e.partial, chunk...)
+	for {
+		i := bytes.IndexByte(e.partial, '\n')
+		if i < 0 {
+			return
+		}
+		e.writeLine(e.partial[:i])
+		e.partial = e.partial[i+1:]
+	}
+}
+
+func (e *eventWriter) flush() {
+	if len(e.partial) > 0 {
+		e.writeLine(e.partial)
+		e.partial = nil
+	}
+}
+
+func (e *eventWriter) writeLine(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+
+	var event taskEvent
+	if err := json.Unmarshal(line, &event); err != nil {
+		fmt.Fprintf(e.w, "%s\n", line)
+		return
+	}
+	fmt.Fprintf(e.w, "%s\n", event)
+}
@@ -0,0 +1,133 @@
+package bosh_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/bosh/mocks"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+//go:generate mockgen -package mocks -destination mocks/task.go github.com/cloudfoundry/bosh-cli/director Task
+
+var _ = Describe("Tasks", func() {
+	var (
+		mockController *gomock.Controller
+		mockDir        *mocks.MockDirector
+		mockTask       *mocks.MockTask
+		subject        *bosh.Bosh
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockDir = mocks.NewMockDirector(mockController)
+		mockTask = mocks.NewMockTask(mockController)
+		subject = bosh.NewWithDirector(mockDir)
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	Describe("CurrentTask", func() {
+		It("returns the task running for the deployment", func() {
+			mockDir.EXPECT().CurrentTasks(boshdir.TasksFilter{Deployment: "cf"}).Return([]boshdir.Task{mockTask}, nil)
+			mockTask.EXPECT().ID().Return(42)
+
+			Expect(subject.CurrentTask("cf")).To(Equal(42))
+		})
+
+		It("returns an error when no task is running", func() {
+			mockDir.EXPECT().CurrentTasks(gomock.Any()).Return(nil, nil)
+
+			_, err := subject.CurrentTask("cf")
+			Expect(err).To(MatchError("no task is running for deployment cf"))
+		})
+	})
+
+	Describe("RecentTasks", func() {
+		It("returns the last tasks the director ran", func() {
+			started := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+			mockDir.EXPECT().RecentTasks(5, boshdir.TasksFilter{}).Return([]boshdir.Task{mockTask}, nil)
+			mockTask.EXPECT().ID().Return(42)
+			mockTask.EXPECT().State().Return("error")
+			mockTask.EXPECT().Description().Return("create deployment")
+			mockTask.EXPECT().DeploymentName().Return("cf")
+			mockTask.EXPECT().Result().Return("some-error")
+			mockTask.EXPECT().StartedAt().Return(started)
+			mockTask.EXPECT().LastActivityAt().Return(started.Add(time.Minute))
+
+			tasks, err := subject.RecentTasks(5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tasks).To(Equal([]bosh.TaskInfo{{
+				ID:             42,
+				State:          "error",
+				Description:    "create deployment",
+				Deployment:     "cf",
+				Result:         "some-error",
+				StartedAt:      started,
+				LastActivityAt: started.Add(time.Minute),
+			}}))
+			Expect(tasks[0].Failed()).To(BeTrue())
+		})
+
+		It("returns the director's error", func() {
+			mockDir.EXPECT().RecentTasks(5, gomock.Any()).Return(nil, errors.New("some-error"))
+
+			_, err := subject.RecentTasks(5)
+			Expect(err).To(MatchError("some-error"))
+		})
+	})
+
+	Describe("CancelCurrentTasks", func() {
+		It("cancels every task of the deployment", func() {
+			otherTask := mocks.NewMockTask(mockController)
+			mockDir.EXPECT().CurrentTasks(boshdir.TasksFilter{Deployment: "cf"}).Return([]boshdir.Task{mockTask, otherTask}, nil)
+			mockTask.EXPECT().Cancel()
+			otherTask.EXPECT().Cancel()
+
+			Expect(subject.CancelCurrentTasks("cf")).To(Succeed())
+		})
+
+		It("cancels the rest when one cannot be cancelled", func() {
+			otherTask := mocks.NewMockTask(mockController)
+			mockDir.EXPECT().CurrentTasks(gomock.Any()).Return([]boshdir.Task{mockTask, otherTask}, nil)
+			mockTask.EXPECT().Cancel().Return(errors.New("some-error"))
+			mockTask.EXPECT().ID().Return(42)
+			otherTask.EXPECT().Cancel()
+
+			Expect(subject.CancelCurrentTasks("cf")).To(MatchError("failed to cancel task 42: some-error"))
+		})
+	})
+
+	Describe("StreamTaskLogs", func() {
+		It("writes each event as a line, however the output is chunked", func() {
+			mockDir.EXPECT().FindTask(42).Return(mockTask, nil)
+			mockTask.EXPECT().EventOutput(gomock.Any()).DoAndReturn(func(reporter boshdir.TaskReporter) error {
+				reporter.TaskStarted(42)
+				reporter.TaskOutputChunk(42, []byte(`{"time":1,"stage":"Compiling packages","tags":[],"total":2,"task":"garden/8f3","index":1,"state":"started","progress":0}`+"\n"+`{"time":2,"stage":"Updating instance","tags":["diego-cell"],`))
+				reporter.TaskOutputChunk(42, []byte(`"total":1,"task":"diego-cell/0","index":1,"state":"failed","progress":100,"error":{"code":450002,"message":"timed out"}}`+"\n"))
+				reporter.TaskFinished(42, "done")
+				return nil
+			})
+
+			writer := &bytes.Buffer{}
+			Expect(subject.StreamTaskLogs(42, writer)).To(Succeed())
+			Expect(writer.String()).To(Equal(
+				"Compiling packages: garden/8f3 (1/2) started\n" +
+					"Updating instance (diego-cell): diego-cell/0 (1/1) failed: timed out\n",
+			))
+		})
+
+		It("returns an error when the task cannot be found", func() {
+			mockDir.EXPECT().FindTask(42).Return(nil, errors.New("some-error"))
+
+			Expect(subject.StreamTaskLogs(42, &bytes.Buffer{})).To(MatchError("some-error"))
+		})
+	})
+})
@@ -0,0 +1,67 @@
+package bosh
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// maxTimings bounds the timings log to the last few starts.
+const maxTimings = 100
+
+// Timing is how long a phase of a deployment took, e.g. compiling the
+// packages of cf.
+type Timing struct {
+	Deployment string        `json:"deployment"`
+	Phase      string        `json:"phase"`
+	Duration   time.Duration `json:"duration"`
+	FinishedAt time.Time     `json:"finished_at"`
+}
+
+// TimingsLog keeps the phase timings of recent deploys in a file, for the
+// report at the end of cf dev start and for analytics on where starts are
+// slow.
+type TimingsLog struct {
+	Path string
+}
+
+// Add appends the timing, dropping the oldest once there are more than
+// maxTimings.
+func (l *TimingsLog) Add(timing Timing) error {
+	timings, _ := l.Read()
+	timings = append(timings, timing)
+	if len(timings) > maxTimings {
+		timings = timings[len(timings)-maxTimings:]
+	}
+
+	contents, err := json.Marshal(timings)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(l.Path, contents, 0644)
+}
+
+// Read returns the timings, oldest first. A missing log has none.
+func (l *TimingsLog) Read() ([]Timing, error) {
+	contents, err := ioutil.ReadFile(l.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var timings []Timing
+	if err := json.Unmarshal(contents, &timings); err != nil {
+		return nil, err
+	}
+	return timings, nil
+}
+
+// Timings returns the phase timings of recent deploys, oldest first.
+func (b *Bosh) Timings() ([]Timing, error) {
+	if b.timings == nil {
+		return nil, nil
+	}
+	return b.timings.Read()
+}
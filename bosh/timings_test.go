@@ -0,0 +1,109 @@
+package bosh_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/bosh/mocks"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TimingsLog", func() {
+	var (
+		dir     string
+		timings *bosh.TimingsLog
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "timings")
+		Expect(err).NotTo(HaveOccurred())
+		timings = &bosh.TimingsLog{Path: filepath.Join(dir, "deploy-timings.json")}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("has no timings before anything is added", func() {
+		Expect(timings.Read()).To(BeEmpty())
+	})
+
+	It("keeps only the most recent timings", func() {
+		for i := 0; i < 105; i++ {
+			Expect(timings.Add(bosh.Timing{Deployment: "cf", Phase: bosh.Deploying, Duration: time.Duration(i) * time.Second})).To(Succeed())
+		}
+
+		all, err := timings.Read()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(all).To(HaveLen(100))
+		Expect(all[0].Duration).To(Equal(5 * time.Second))
+		Expect(all[99].Duration).To(Equal(104 * time.Second))
+	})
+
+	Context("when tracking progress", func() {
+		var (
+			mockController *gomock.Controller
+			mockDir        *mocks.MockDirector
+			mockDep        *mocks.MockDeployment
+			subject        *bosh.Bosh
+		)
+
+		BeforeEach(func() {
+			mockController = gomock.NewController(GinkgoT())
+			mockDir = mocks.NewMockDirector(mockController)
+			mockDep = mocks.NewMockDeployment(mockController)
+			history := &bosh.PhaseHistory{Path: filepath.Join(dir, "phase-durations.json")}
+			subject = bosh.NewWithDirector(mockDir).WithPhaseHistory(history).WithTimings(timings)
+			mockDir.EXPECT().FindDeployment("cf").AnyTimes().Return(mockDep, nil)
+			mockDir.EXPECT().Info().AnyTimes()
+		})
+
+		AfterEach(func() {
+			mockController.Finish()
+		})
+
+		It("records how long each phase took", func() {
+			start := time.Now()
+
+			mockDep.EXPECT().VMInfos().Return(nil, nil)
+			mockDir.EXPECT().Releases().Return([]boshdir.Release{nil}, nil)
+			p, err := subject.GetVMProgress(context.Background(), start, "cf", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.State).To(Equal(bosh.UploadingReleases))
+
+			mockDep.EXPECT().VMInfos().Return([]boshdir.VMInfo{{JobName: "compilation-abc", ProcessState: "running"}}, nil)
+			p, err = subject.GetVMProgress(context.Background(), start, "cf", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.State).To(Equal(bosh.Compiling))
+
+			mockDep.EXPECT().VMInfos().Return([]boshdir.VMInfo{{JobName: "router", ProcessState: "starting"}}, nil)
+			p, err = subject.GetVMProgress(context.Background(), start, "cf", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.State).To(Equal(bosh.Deploying))
+
+			subject.FinishPhase("cf")
+
+			all, err := subject.Timings()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(all).To(HaveLen(3))
+			Expect(all[0].Phase).To(Equal(bosh.UploadingReleases))
+			Expect(all[1].Phase).To(Equal(bosh.Compiling))
+			Expect(all[2].Phase).To(Equal(bosh.Deploying))
+			for _, timing := range all {
+				Expect(timing.Deployment).To(Equal("cf"))
+				Expect(timing.FinishedAt).To(BeTemporally(">=", start))
+			}
+
+			subject.FinishPhase("cf")
+			Expect(subject.Timings()).To(HaveLen(3))
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package bosh
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/config"
+	boshuaa "github.com/cloudfoundry/bosh-cli/uaa"
+	"gopkg.in/yaml.v2"
+)
+
+// UAAConfig is how to log in to a director that authenticates with UAA
+// rather than with the admin secret, e.g. after a custom director ops file
+// enables it. It is read from uaa.yml in the bosh state dir.
+type UAAConfig struct {
+	URL          string `yaml:"url"`
+	Client       string `yaml:"client"`
+	ClientSecret string `yaml:"client_secret"`
+	// CACert is the certificate UAA's is signed by. It defaults to the
+	// director's.
+	CACert string `yaml:"ca_cert"`
+}
+
+// ReadUAAConfig returns the director's UAA config, and false when there is
+// none, i.e. the director uses basic auth.
+func ReadUAAConfig(cfg config.Config) (UAAConfig, bool, error) {
+	path := filepath.Join(cfg.StateBosh, "uaa.yml")
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return UAAConfig{}, false, nil
+	} else if err != nil {
+		return UAAConfig{}, false, err
+	}
+
+	var uaaConfig UAAConfig
+	if err := yaml.Unmarshal(content, &uaaConfig); err != nil {
+		return UAAConfig{}, false, fmt.Errorf("parsing %s: %s", path, err)
+	}
+	if uaaConfig.URL == "" || uaaConfig.Client == "" {
+		return UAAConfig{}, false, fmt.Errorf("%s must set url and client", path)
+	}
+	return uaaConfig, true, nil
+}
+
+// TokenFunc logs in to UAA with the client credentials, for the director
+// client to authenticate its requests with. caCert is used when the config
+// does not name a CA of its own.
+func (c UAAConfig) TokenFunc(caCert string) (func(bool) (string, error), error) {
+	uaaConfig, err := boshuaa.NewConfigFromURL(c.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	uaaConfig.Client = c.Client
+	uaaConfig.ClientSecret = c.ClientSecret
+	uaaConfig.CACert = c.CACert
+	if uaaConfig.CACert == "" {
+		uaaConfig.CACert = caCert
+	}
+
+	uaa, err := boshuaa.NewFactory(&Logger{}).New(uaaConfig)
+	if err != nil {
+		return nil, err
+	}
+	return boshuaa.NewClientTokenSession(uaa).TokenFunc, nil
+}
@@ -0,0 +1,120 @@
+package bosh_test
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UAA", func() {
+	var (
+		tmpDir string
+		cfg    config.Config
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-uaa-")
+		Expect(err).NotTo(HaveOccurred())
+		cfg = config.Config{StateBosh: tmpDir, BoshDirectorIP: "10.144.0.4"}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	writeUAAConfig := func(content string) {
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "uaa.yml"), []byte(content), 0600)).To(Succeed())
+	}
+
+	Describe("ReadUAAConfig", func() {
+		It("reports that there is none when the director uses basic auth", func() {
+			_, ok, err := bosh.ReadUAAConfig(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("reads the uaa url and client", func() {
+			writeUAAConfig("url: https://10.144.0.4:8443\nclient: some-client\nclient_secret: some-secret\n")
+
+			uaaConfig, ok, err := bosh.ReadUAAConfig(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(uaaConfig).To(Equal(bosh.UAAConfig{
+				URL:          "https://10.144.0.4:8443",
+				Client:       "some-client",
+				ClientSecret: "some-secret",
+			}))
+		})
+
+		It("returns an error when the url or client is missing", func() {
+			writeUAAConfig("client_secret: some-secret\n")
+
+			_, _, err := bosh.ReadUAAConfig(cfg)
+			Expect(err).To(MatchError(ContainSubstring("must set url and client")))
+		})
+	})
+
+	Describe("FetchConfig", func() {
+		It("uses the uaa client in place of the admin user", func() {
+			writeUAAConfig("url: https://10.144.0.4:8443\nclient: some-client\nclient_secret: some-secret\n")
+
+			boshConfig, err := bosh.FetchConfig(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(boshConfig.AdminUsername).To(Equal("some-client"))
+			Expect(boshConfig.AdminPassword).To(Equal("some-secret"))
+		})
+
+		It("uses the admin secret otherwise", func() {
+			Expect(ioutil.WriteFile(filepath.Join(tmpDir, "secret"), []byte("some-admin-secret\n"), 0600)).To(Succeed())
+
+			boshConfig, err := bosh.FetchConfig(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(boshConfig.AdminUsername).To(Equal("admin"))
+			Expect(boshConfig.AdminPassword).To(Equal("some-admin-secret"))
+		})
+	})
+
+	Describe("TokenFunc", func() {
+		It("logs in with the client credentials", func() {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				Expect(r.URL.Path).To(Equal("/oauth/token"))
+				Expect(r.FormValue("grant_type")).To(Equal("client_credentials"))
+
+				username, password, _ := r.BasicAuth()
+				Expect(username).To(Equal("some-client"))
+				Expect(password).To(Equal("some-secret"))
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"access_token": "some-token", "token_type": "bearer"}`))
+			}))
+			defer server.Close()
+
+			caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			uaaConfig := bosh.UAAConfig{URL: server.URL, Client: "some-client", ClientSecret: "some-secret"}
+
+			tokenFunc, err := uaaConfig.TokenFunc(string(caCert))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tokenFunc(false)).To(Equal("bearer some-token"))
+		})
+	})
+
+	Describe("New", func() {
+		It("does not need the admin secret when the director uses uaa", func() {
+			writeUAAConfig("url: https://10.144.0.4:8443\nclient: some-client\nclient_secret: some-secret\n")
+			Expect(ioutil.WriteFile(filepath.Join(tmpDir, "ca.crt"), []byte(""), 0600)).To(Succeed())
+
+			_, err := bosh.New(cfg)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,88 @@
+package bosh
+
+import (
+	"os"
+	"strings"
+)
+
+// UploadProgress is called as a file is uploaded to the director, with the
+// bytes sent so far and the size of the file.
+type UploadProgress func(sent, total int64)
+
+// UploadRelease uploads a release tarball from a local path or a URL. The
+// director fetches URLs itself, so progress is only reported for paths.
+// progress may be nil.
+func (b *Bosh) UploadRelease(location string, progress UploadProgress) error {
+	if isURL(location) {
+		return b.dir.UploadReleaseURL(location, "", false, false)
+	}
+
+	file, err := openUpload(location, progress)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return b.dir.UploadReleaseFile(file, false, false)
+}
+
+// UploadStemcell uploads a stemcell tarball from a local path or a URL, in
+// the same way as UploadRelease.
+func (b *Bosh) UploadStemcell(location string, progress UploadProgress) error {
+	if isURL(location) {
+		return b.dir.UploadStemcellURL(location, "", false)
+	}
+
+	file, err := openUpload(location, progress)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return b.dir.UploadStemcellFile(file, false)
+}
+
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+func openUpload(path string, progress UploadProgress) (*uploadFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &uploadFile{File: file, total: info.Size(), progress: progress}, nil
+}
+
+// uploadFile counts the bytes the director client reads from the file.
+type uploadFile struct {
+	*os.File
+	sent     int64
+	total    int64
+	progress UploadProgress
+}
+
+func (f *uploadFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	f.sent += int64(n)
+	if f.progress != nil && n > 0 {
+		f.progress(f.sent, f.total)
+	}
+	return n, err
+}
+
+// Seek keeps the count right when the client rewinds to retry.
+func (f *uploadFile) Seek(offset int64, whence int) (int64, error) {
+	pos, err := f.File.Seek(offset, whence)
+	if err == nil {
+		f.sent = pos
+	}
+	return pos, err
+}
@@ -1,8 +1,10 @@
 package cfanalytics
 
 import (
+	adconfig "code.cloudfoundry.org/cfdev/analyticsd/config"
 	"code.cloudfoundry.org/cfdev/config"
 	"code.cloudfoundry.org/cfdev/daemon"
+	"os"
 )
 
 const AnalyticsDLabel = "org.cloudfoundry.cfdev.cfanalyticsd"
@@ -39,7 +41,11 @@ func (a *AnalyticsD) Stop() error {
 	return reterr
 }
 
+// Destroy also forgets the last event analyticsd processed, so that the
+// events from while it is gone, e.g. with telemetry turned off, are never
+// sent.
 func (a *AnalyticsD) Destroy() error {
+	os.Remove(adconfig.CursorPath(a.Config.CFDevHome))
 	return a.DaemonRunner.RemoveDaemon(AnalyticsDLabel)
 }
 
@@ -0,0 +1,20 @@
+package cfanalytics
+
+import (
+	"code.cloudfoundry.org/cfdev/daemon"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+func (a *AnalyticsD) DaemonSpec() daemon.DaemonSpec {
+	return daemon.DaemonSpec{
+		Label:            AnalyticsDLabel,
+		Program:          filepath.Join(a.Config.CacheDir, "analyticsd"),
+		SessionType:      "Background",
+		ProgramArguments: []string{filepath.Join(a.Config.CacheDir, "analyticsd"), os.Getenv("CFDEV_MODE"), "--cf-domain=" + a.Config.CFDomain},
+		RunAtLoad:        false,
+		StdoutPath:       path.Join(a.Config.LogDir, "analyticsd.stdout.log"),
+		StderrPath:       path.Join(a.Config.LogDir, "analyticsd.stderr.log"),
+	}
+}
@@ -11,7 +11,7 @@ func (a *AnalyticsD) DaemonSpec() daemon.DaemonSpec {
 		Label:            AnalyticsDLabel,
 		Program:          filepath.Join(a.Config.CacheDir, "analyticsd.exe"),
 		SessionType:      "Background",
-		ProgramArguments: []string{os.Getenv("CFDEV_MODE")},
+		ProgramArguments: []string{os.Getenv("CFDEV_MODE"), "--cf-domain=" + a.Config.CFDomain},
 		StdoutPath:       filepath.Join(a.Config.LogDir, "analyticsd.stdout.log"),
 	}
 }
@@ -20,6 +20,9 @@ const (
 	ERROR            = "error"
 	UNINSTALL        = "uninstall"
 	DEPLOY_SERVICE   = "deployed service"
+	REMOVE_SERVICE   = "removed service"
+	HEARTBEAT        = "guest heartbeat"
+	CRASH            = "crash"
 )
 
 //go:generate mockgen -package mocks -destination mocks/analytics_client.go gopkg.in/segmentio/analytics-go.v3 Client
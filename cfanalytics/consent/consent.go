@@ -0,0 +1,193 @@
+// Package consent keeps whether the user agreed to telemetry. Both the
+// plugin and analyticsd read it, and nothing is sent without it.
+package consent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DoNotTrackEnv turns telemetry off when set to anything but 0 or false,
+// following https://consoledonottrack.com.
+const DoNotTrackEnv = "DO_NOT_TRACK"
+
+// MachinePath is a file administrators can create to turn telemetry off
+// for every user of the machine, containing {"doNotTrack": true}.
+func MachinePath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "cfdev", "consent.json")
+	}
+	return filepath.Join("/etc", "cfdev", "consent.json")
+}
+
+type Store struct {
+	// Path is where the user's answer is kept.
+	Path string
+	// LegacyPath is the file earlier versions kept the answer in, which is
+	// read until the answer is next saved.
+	LegacyPath  string
+	MachinePath string
+
+	defined                bool
+	CfAnalyticsEnabled     bool                   `json:"cfAnalyticsEnabled"`
+	CustomAnalyticsEnabled bool                   `json:"customAnalyticsEnabled"`
+	DecidedAt              time.Time              `json:"decidedAt,omitempty"`
+	Props                  map[string]interface{} `json:"props"`
+}
+
+// New loads the consent kept in the cfdev home.
+func New(cfdevHome string) *Store {
+	s := &Store{
+		Path:        filepath.Join(cfdevHome, "analytics", "consent.json"),
+		LegacyPath:  filepath.Join(cfdevHome, "analytics", "analytics.txt"),
+		MachinePath: MachinePath(),
+	}
+	s.Load()
+	return s
+}
+
+// Open loads the consent kept at path alone.
+func Open(path string) *Store {
+	s := &Store{Path: path}
+	s.Load()
+	return s
+}
+
+// Load reads the user's answer, if they gave one.
+func (s *Store) Load() {
+	s.defined = false
+	s.CfAnalyticsEnabled = false
+	s.CustomAnalyticsEnabled = false
+	s.Props = map[string]interface{}{}
+
+	for _, path := range []string{s.Path, s.LegacyPath} {
+		if path == "" {
+			continue
+		}
+		if txt, err := ioutil.ReadFile(path); err == nil {
+			if err := json.Unmarshal(txt, s); err == nil {
+				s.defined = true
+			} else {
+				fmt.Printf("Error unmarshalling json: %v", err)
+			}
+			break
+		}
+	}
+
+	if s.Props == nil {
+		s.Props = map[string]interface{}{}
+	}
+}
+
+// Override returns what turned telemetry off regardless of the user's
+// answer, if anything did.
+func (s *Store) Override() string {
+	if value := strings.ToLower(strings.TrimSpace(os.Getenv(DoNotTrackEnv))); value != "" && value != "0" && value != "false" {
+		return DoNotTrackEnv
+	}
+
+	if s.MachinePath != "" {
+		var machine struct {
+			DoNotTrack bool `json:"doNotTrack"`
+		}
+		if txt, err := ioutil.ReadFile(s.MachinePath); err == nil && json.Unmarshal(txt, &machine) == nil && machine.DoNotTrack {
+			return s.MachinePath
+		}
+	}
+	return ""
+}
+
+// Defined reports whether the user need not be asked, either because they
+// answered already or because telemetry is overridden.
+func (s *Store) Defined() bool {
+	return s.defined || s.Override() != ""
+}
+
+func (s *Store) CustomAnalyticsDefined() bool {
+	if s.Override() != "" {
+		return true
+	}
+	if !s.defined {
+		return false
+	}
+	return !(s.CfAnalyticsEnabled && !s.CustomAnalyticsEnabled)
+}
+
+func (s *Store) Enabled() bool {
+	if s.Override() != "" {
+		return false
+	}
+	return s.CfAnalyticsEnabled || s.CustomAnalyticsEnabled
+}
+
+func (s *Store) IsCustom() bool {
+	return s.CustomAnalyticsEnabled
+}
+
+func (s *Store) SetCFAnalyticsEnabled(value bool) error {
+	if err := s.allow(value); err != nil {
+		return err
+	}
+
+	s.CfAnalyticsEnabled = value
+	if !value {
+		s.CustomAnalyticsEnabled = value
+	}
+	return s.decide()
+}
+
+func (s *Store) SetCustomAnalyticsEnabled(value bool) error {
+	if err := s.allow(value); err != nil {
+		return err
+	}
+
+	s.CfAnalyticsEnabled = value
+	s.CustomAnalyticsEnabled = value
+	return s.decide()
+}
+
+func (s *Store) GetProps() map[string]interface{} {
+	return s.Props
+}
+
+func (s *Store) SetProp(k, v string) error {
+	s.Props[k] = v
+	return s.save()
+}
+
+// allow refuses to turn telemetry on while it is overridden.
+func (s *Store) allow(enabled bool) error {
+	if override := s.Override(); enabled && override != "" {
+		return fmt.Errorf("telemetry is turned off by %s", override)
+	}
+	return nil
+}
+
+func (s *Store) decide() error {
+	s.defined = true
+	s.DecidedAt = time.Now().UTC()
+	return s.save()
+}
+
+func (s *Store) save() error {
+	os.MkdirAll(filepath.Dir(s.Path), 0755)
+	hash := map[string]interface{}{"props": s.Props}
+	if s.defined {
+		hash["cfAnalyticsEnabled"] = s.CfAnalyticsEnabled
+		hash["customAnalyticsEnabled"] = s.CustomAnalyticsEnabled
+		if !s.DecidedAt.IsZero() {
+			hash["decidedAt"] = s.DecidedAt
+		}
+	}
+	txt, err := json.Marshal(hash)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.Path, txt, 0644)
+}
@@ -0,0 +1,13 @@
+package consent_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestConsent(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Consent Suite")
+}
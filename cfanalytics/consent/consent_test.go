@@ -1,15 +1,16 @@
-package toggle_test
+package consent_test
 
 import (
-	"code.cloudfoundry.org/cfdev/cfanalytics/toggle"
+	"code.cloudfoundry.org/cfdev/cfanalytics/consent"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 )
 
-var _ = Describe("Toggle", func() {
+var _ = Describe("Store", func() {
 	var (
 		tmpDir, saveFile string
 	)
@@ -18,8 +19,10 @@ var _ = Describe("Toggle", func() {
 		tmpDir, err = ioutil.TempDir("", "analytics")
 		Expect(err).ToNot(HaveOccurred())
 		saveFile = filepath.Join(tmpDir, "somefile.txt")
+		os.Unsetenv("DO_NOT_TRACK")
 	})
 	AfterEach(func() {
+		os.Unsetenv("DO_NOT_TRACK")
 		os.RemoveAll(tmpDir)
 	})
 
@@ -30,7 +33,7 @@ var _ = Describe("Toggle", func() {
 			})
 
 			It("returns enabled true and custom is true", func() {
-				t := toggle.New(saveFile)
+				t := consent.Open(saveFile)
 
 				Expect(t.CustomAnalyticsDefined()).To(BeTrue())
 				Expect(t.Enabled()).To(BeTrue())
@@ -44,7 +47,7 @@ var _ = Describe("Toggle", func() {
 			})
 
 			It("returns enabled false and custom is false", func() {
-				t := toggle.New(saveFile)
+				t := consent.Open(saveFile)
 
 				Expect(t.CustomAnalyticsDefined()).To(BeTrue())
 				Expect(t.Enabled()).To(BeFalse())
@@ -58,7 +61,7 @@ var _ = Describe("Toggle", func() {
 			})
 
 			It("returns enabled true and custom is false", func() {
-				t := toggle.New(saveFile)
+				t := consent.Open(saveFile)
 
 				Expect(t.CustomAnalyticsDefined()).To(BeFalse())
 				Expect(t.Enabled()).To(BeTrue())
@@ -72,7 +75,7 @@ var _ = Describe("Toggle", func() {
 			})
 
 			It("returns enabled true and custom is true", func() {
-				t := toggle.New(saveFile)
+				t := consent.Open(saveFile)
 
 				Expect(t.CustomAnalyticsDefined()).To(BeTrue())
 				Expect(t.Enabled()).To(BeTrue())
@@ -86,20 +89,20 @@ var _ = Describe("Toggle", func() {
 			})
 
 			It("updates somefile.txt", func() {
-				t := toggle.New(saveFile)
+				t := consent.Open(saveFile)
 				Expect(t.SetCustomAnalyticsEnabled(true)).To(Succeed())
 				Expect(t.IsCustom()).To(BeTrue())
 
 				txt, err := ioutil.ReadFile(saveFile)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(string(txt)).To(Equal(`{"cfAnalyticsEnabled":true,"customAnalyticsEnabled":true,"props":{}}`))
+				Expect(string(txt)).To(ContainSubstring(`"cfAnalyticsEnabled":true,"customAnalyticsEnabled":true,"decidedAt":`))
 			})
 		})
 
 		Describe("Analytics file does NOT exist", func() {
 			Context("and custom analytics are set to true", func() {
 				It("returns enabled true and custom is true and defined is true", func() {
-					t := toggle.New(saveFile)
+					t := consent.Open(saveFile)
 
 					Expect(t.Defined()).To(BeFalse())
 					Expect(t.CustomAnalyticsDefined()).To(BeFalse())
@@ -113,11 +116,11 @@ var _ = Describe("Toggle", func() {
 		})
 		Describe("Set Prop", func() {
 			var (
-				t *toggle.Toggle
+				t *consent.Store
 			)
 			BeforeEach(func() {
 				saveFile = filepath.Join(tmpDir, "somedir", "somefile.txt")
-				t = toggle.New(saveFile)
+				t = consent.Open(saveFile)
 			})
 			It("sets props", func() {
 				Expect(t.GetProps()).To(BeEquivalentTo(map[string]interface{}{}))
@@ -135,7 +138,91 @@ var _ = Describe("Toggle", func() {
 				Expect(t.SetCFAnalyticsEnabled(false)).To(Succeed())
 				Expect(t.SetProp("key", "value")).To(Succeed())
 				Expect(t.SetProp("other", "thing")).To(Succeed())
-				Expect(ioutil.ReadFile(saveFile)).To(MatchJSON(`{"cfAnalyticsEnabled":false,"customAnalyticsEnabled":false,"props": {"key": "value","other": "thing"}}`))
+				Expect(ioutil.ReadFile(saveFile)).To(MatchJSON(`{"cfAnalyticsEnabled":false,"customAnalyticsEnabled":false,"decidedAt":"` + t.DecidedAt.Format(time.RFC3339Nano) + `","props": {"key": "value","other": "thing"}}`))
+			})
+		})
+	})
+
+	Describe("New", func() {
+		It("reads the answer kept by earlier versions until it is saved again", func() {
+			Expect(os.MkdirAll(filepath.Join(tmpDir, "analytics"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(tmpDir, "analytics", "analytics.txt"), []byte(`{"cfAnalyticsEnabled":true,"customAnalyticsEnabled":true,"props":{"type":"cf.dev"}}`), 0644)).To(Succeed())
+
+			s := consent.New(tmpDir)
+			s.MachinePath = ""
+			Expect(s.Defined()).To(BeTrue())
+			Expect(s.Enabled()).To(BeTrue())
+
+			Expect(s.SetProp("key", "value")).To(Succeed())
+			txt, err := ioutil.ReadFile(filepath.Join(tmpDir, "analytics", "consent.json"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(txt)).To(ContainSubstring(`"type":"cf.dev"`))
+		})
+	})
+
+	Describe("overrides", func() {
+		var s *consent.Store
+
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile(saveFile, []byte(`{"cfAnalyticsEnabled":true,"customAnalyticsEnabled":true,"props":{}}`), 0644)).To(Succeed())
+			s = consent.Open(saveFile)
+		})
+
+		for _, value := range []string{"1", "true", "yes"} {
+			value := value
+			Context("DO_NOT_TRACK is "+value, func() {
+				BeforeEach(func() {
+					os.Setenv("DO_NOT_TRACK", value)
+				})
+
+				It("turns telemetry off without asking", func() {
+					Expect(s.Override()).To(Equal("DO_NOT_TRACK"))
+					Expect(s.Enabled()).To(BeFalse())
+					Expect(s.Defined()).To(BeTrue())
+					Expect(s.CustomAnalyticsDefined()).To(BeTrue())
+				})
+
+				It("refuses to turn telemetry on", func() {
+					Expect(s.SetCFAnalyticsEnabled(true)).To(MatchError("telemetry is turned off by DO_NOT_TRACK"))
+					Expect(s.SetCFAnalyticsEnabled(false)).To(Succeed())
+				})
+			})
+		}
+
+		for _, value := range []string{"0", "false", ""} {
+			value := value
+			Context("DO_NOT_TRACK is '"+value+"'", func() {
+				BeforeEach(func() {
+					os.Setenv("DO_NOT_TRACK", value)
+				})
+
+				It("uses the user's answer", func() {
+					Expect(s.Override()).To(BeEmpty())
+					Expect(s.Enabled()).To(BeTrue())
+				})
+			})
+		}
+
+		Context("the machine file turns tracking off", func() {
+			BeforeEach(func() {
+				s.MachinePath = filepath.Join(tmpDir, "machine.json")
+				Expect(ioutil.WriteFile(s.MachinePath, []byte(`{"doNotTrack":true}`), 0644)).To(Succeed())
+			})
+
+			It("turns telemetry off", func() {
+				Expect(s.Override()).To(Equal(s.MachinePath))
+				Expect(s.Enabled()).To(BeFalse())
+			})
+		})
+
+		Context("the machine file does not turn tracking off", func() {
+			BeforeEach(func() {
+				s.MachinePath = filepath.Join(tmpDir, "machine.json")
+				Expect(ioutil.WriteFile(s.MachinePath, []byte(`{"doNotTrack":false}`), 0644)).To(Succeed())
+			})
+
+			It("uses the user's answer", func() {
+				Expect(s.Enabled()).To(BeTrue())
 			})
 		})
 	})
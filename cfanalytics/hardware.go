@@ -0,0 +1,45 @@
+package cfanalytics
+
+// HardwareClass buckets the host's memory and cpus coarsely enough that
+// no single machine can be told apart by them.
+func HardwareClass(totalMemoryMB uint64, cpus int) map[string]interface{} {
+	return map[string]interface{}{
+		"memory class": memoryClass(totalMemoryMB),
+		"cpu class":    cpuClass(cpus),
+	}
+}
+
+func memoryClass(totalMemoryMB uint64) string {
+	switch gb := totalMemoryMB / 1024; {
+	case gb < 8:
+		return "<8GB"
+	case gb < 16:
+		return "8-16GB"
+	case gb < 32:
+		return "16-32GB"
+	default:
+		return "32GB+"
+	}
+}
+
+func cpuClass(cpus int) string {
+	switch {
+	case cpus <= 2:
+		return "1-2"
+	case cpus <= 4:
+		return "3-4"
+	case cpus <= 8:
+		return "5-8"
+	default:
+		return "9+"
+	}
+}
+
+// HostClass is HardwareClass along with the virtualization cf dev runs its
+// vm with on this os.
+func HostClass(totalMemoryMB uint64, cpus int) map[string]interface{} {
+	host := HardwareClass(totalMemoryMB, cpus)
+	host["hypervisor"] = HypervisorBackend
+	host["virtualization"] = VirtualizationType
+	return host
+}
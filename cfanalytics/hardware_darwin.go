@@ -0,0 +1,6 @@
+package cfanalytics
+
+const (
+	HypervisorBackend  = "hyperkit"
+	VirtualizationType = "hypervisor.framework"
+)
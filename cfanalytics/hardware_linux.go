@@ -0,0 +1,6 @@
+package cfanalytics
+
+const (
+	HypervisorBackend  = "qemu"
+	VirtualizationType = "kvm"
+)
@@ -0,0 +1,31 @@
+package cfanalytics_test
+
+import (
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HardwareClass", func() {
+	It("buckets memory", func() {
+		Expect(cfanalytics.HardwareClass(4096, 1)["memory class"]).To(Equal("<8GB"))
+		Expect(cfanalytics.HardwareClass(8192, 1)["memory class"]).To(Equal("8-16GB"))
+		Expect(cfanalytics.HardwareClass(16384, 1)["memory class"]).To(Equal("16-32GB"))
+		Expect(cfanalytics.HardwareClass(65536, 1)["memory class"]).To(Equal("32GB+"))
+	})
+
+	It("buckets cpus", func() {
+		Expect(cfanalytics.HardwareClass(0, 2)["cpu class"]).To(Equal("1-2"))
+		Expect(cfanalytics.HardwareClass(0, 4)["cpu class"]).To(Equal("3-4"))
+		Expect(cfanalytics.HardwareClass(0, 8)["cpu class"]).To(Equal("5-8"))
+		Expect(cfanalytics.HardwareClass(0, 16)["cpu class"]).To(Equal("9+"))
+	})
+
+	It("adds the virtualization to the host class", func() {
+		host := cfanalytics.HostClass(16384, 4)
+		Expect(host).To(HaveKeyWithValue("memory class", "16-32GB"))
+		Expect(host).To(HaveKeyWithValue("cpu class", "3-4"))
+		Expect(host).To(HaveKeyWithValue("hypervisor", cfanalytics.HypervisorBackend))
+		Expect(host).To(HaveKeyWithValue("virtualization", cfanalytics.VirtualizationType))
+	})
+})
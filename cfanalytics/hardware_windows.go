@@ -0,0 +1,6 @@
+package cfanalytics
+
+const (
+	HypervisorBackend  = "hyperv"
+	VirtualizationType = "hyper-v"
+)
@@ -26,6 +26,10 @@ const connectCfdevdMsg = "connecting to cfdevd"
 
 // sends command and returns serverName (and error)
 func (c *Client) Send(command uint8) (string, error) {
+	return c.send(command, nil)
+}
+
+func (c *Client) send(command uint8, payload []byte) (string, error) {
 	handshake := append(c.name[:], make([]byte, 44, 44)...)
 	conn, err := net.Dial("unix", c.socket)
 	if err != nil {
@@ -42,7 +46,7 @@ func (c *Client) Send(command uint8) (string, error) {
 	}
 
 	serverName := string(handshake[:5])
-	if err := binary.Write(conn, binary.LittleEndian, []byte{command}); err != nil {
+	if err := binary.Write(conn, binary.LittleEndian, append([]byte{command}, payload...)); err != nil {
 		return serverName, errors.SafeWrap(err, "sending command to cfdevd")
 	}
 
@@ -63,18 +67,43 @@ func (c *Client) Uninstall() (string, error) {
 	return name, err
 }
 
-func (c *Client) RemoveIPAlias() (string, error) {
-	name, err := c.Send(2)
+// RemoveIPAlias removes the loopback aliases of addrs.
+func (c *Client) RemoveIPAlias(addrs ...string) (string, error) {
+	payload, err := addressPayload(addrs)
+	if err != nil {
+		return "", err
+	}
+	name, err := c.send(4, payload)
 	if err != nil && (strings.HasPrefix(err.Error(), eofReadingExitCodeMsg) || strings.HasPrefix(err.Error(), connectCfdevdMsg)) {
 		return name, nil
 	}
 	return name, err
 }
 
-func (c *Client) AddIPAlias() (string, error) {
-	name, err := c.Send(3)
+// AddIPAlias aliases addrs on the loopback, which cfdevd only does for
+// private addresses.
+func (c *Client) AddIPAlias(addrs ...string) (string, error) {
+	payload, err := addressPayload(addrs)
+	if err != nil {
+		return "", err
+	}
+	name, err := c.send(5, payload)
 	if err != nil && (strings.HasPrefix(err.Error(), eofReadingExitCodeMsg) || strings.HasPrefix(err.Error(), connectCfdevdMsg)) {
 		return name, nil
 	}
 	return name, err
 }
+
+// addressPayload is the count of addrs followed by each address in four
+// bytes, as cfdevd reads them after an alias command.
+func addressPayload(addrs []string) ([]byte, error) {
+	payload := []byte{byte(len(addrs))}
+	for _, addr := range addrs {
+		ip := net.ParseIP(addr).To4()
+		if ip == nil {
+			return nil, fmt.Errorf("%q is not an IPv4 address", addr)
+		}
+		payload = append(payload, ip...)
+	}
+	return payload, nil
+}
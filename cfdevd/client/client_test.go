@@ -76,6 +76,37 @@ var _ = Describe("CFDevD Client", func() {
 			})
 		})
 	})
+	Context("when aliasing addresses", func() {
+		var received chan []byte
+		BeforeEach(func() {
+			received = make(chan []byte, 1)
+			ln, err := net.Listen("unix", socketPath)
+			Expect(err).NotTo(HaveOccurred())
+			go func() {
+				conn, err := ln.Accept()
+				Expect(err).NotTo(HaveOccurred())
+				handshake := make([]byte, 49, 49)
+				binary.Read(conn, binary.LittleEndian, handshake)
+				binary.Write(conn, binary.LittleEndian, handshake)
+				message := make([]byte, 10, 10)
+				binary.Read(conn, binary.LittleEndian, message)
+				received <- message
+				binary.Write(conn, binary.LittleEndian, []byte{0})
+			}()
+		})
+
+		It("sends the addresses after the command", func() {
+			_, err := subject.AddIPAlias("10.245.0.4", "10.245.0.34")
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(received).Should(Receive(Equal([]byte{5, 2, 10, 245, 0, 4, 10, 245, 0, 34})))
+		})
+
+		It("refuses an address that is not IPv4", func() {
+			_, err := subject.AddIPAlias("fe80::1")
+			Expect(err).To(MatchError(`"fe80::1" is not an IPv4 address`))
+		})
+	})
 	Context("cfdevd socket is specified but does not exist", func() {
 		It("succeeds", func() {
 			_, err := subject.Uninstall()
@@ -6,13 +6,16 @@ import (
 	"code.cloudfoundry.org/cfdev/cfdevd/networkd"
 )
 
+// AddIPAliasCommand aliases Addresses on the loopback, or the default
+// addresses when the client sent none.
 type AddIPAliasCommand struct {
+	Addresses []string
 }
 
 func (u *AddIPAliasCommand) Execute(conn *net.UnixConn) error {
 	hostNet := &networkd.HostNetD{}
 
-	err := hostNet.AddLoopbackAliases(BOSH_IP, GOROUTER_IP)
+	err := hostNet.AddLoopbackAliases(addressesOrDefaults(u.Addresses)...)
 	if err == nil {
 		conn.Write([]byte{0})
 	} else {
@@ -0,0 +1,63 @@
+// +build darwin
+
+package cmd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+)
+
+var privateNetworks = []*net.IPNet{
+	mustParseCIDR("10.0.0.0/8"),
+	mustParseCIDR("172.16.0.0/12"),
+	mustParseCIDR("192.168.0.0/16"),
+}
+
+// UnmarshalAddresses reads the addresses an alias command carries: their
+// count, then each IPv4 address in four bytes. cfdevd runs as root, so it
+// only takes private addresses, which cannot stand in for a public host.
+func UnmarshalAddresses(conn io.Reader) ([]string, error) {
+	var count uint8
+	if err := binary.Read(conn, binary.LittleEndian, &count); err != nil {
+		return nil, fmt.Errorf("reading the number of addresses: %s", err)
+	}
+
+	addrs := make([]string, 0, count)
+	for i := 0; i < int(count); i++ {
+		ip := make(net.IP, 4)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return nil, fmt.Errorf("reading an address: %s", err)
+		}
+		if !isPrivate(ip) {
+			return nil, fmt.Errorf("refusing to alias %s, which is not a private address", ip)
+		}
+		addrs = append(addrs, ip.String())
+	}
+	return addrs, nil
+}
+
+func isPrivate(ip net.IP) bool {
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func addressesOrDefaults(addrs []string) []string {
+	if len(addrs) == 0 {
+		return []string{BOSH_IP, GOROUTER_IP}
+	}
+	return addrs
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
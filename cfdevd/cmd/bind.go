@@ -11,6 +11,8 @@ import (
 	"syscall"
 )
 
+// BOSH_IP and GOROUTER_IP are aliased for clients that send no addresses
+// with their alias commands.
 const BOSH_IP = "10.144.0.4"
 const GOROUTER_IP = "10.144.0.34"
 
@@ -25,6 +27,9 @@ type BindCommand struct {
 	Addr *net.TCPAddr
 }
 
+// isIPAllowed lets the vm's ports be bound on the default addresses, and on
+// any private address that has been aliased on the loopback, which is
+// where the addresses a client configured end up.
 func (b *BindCommand) isIPAllowed(ip net.IP) bool {
 	allowedIPs := []net.IP{
 		net.ParseIP(BOSH_IP),
@@ -35,6 +40,23 @@ func (b *BindCommand) isIPAllowed(ip net.IP) bool {
 			return true
 		}
 	}
+	return isPrivate(ip) && isAliased(ip)
+}
+
+func isAliased(ip net.IP) bool {
+	iface, err := net.InterfaceByName(loopback)
+	if err != nil {
+		return false
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return true
+		}
+	}
 	return false
 }
 
@@ -18,6 +18,8 @@ type Command interface {
 const UninstallType = uint8(1)
 const RemoveIPAliasType = uint8(2)
 const AddIPAliasType = uint8(3)
+const RemoveIPAliasesType = uint8(4)
+const AddIPAliasesType = uint8(5)
 const BindType = uint8(6)
 
 func UnmarshalCommand(conn io.Reader) (Command, error) {
@@ -35,6 +37,18 @@ func UnmarshalCommand(conn io.Reader) (Command, error) {
 		return &RemoveIPAliasCommand{}, nil
 	case AddIPAliasType:
 		return &AddIPAliasCommand{}, nil
+	case RemoveIPAliasesType:
+		addrs, err := UnmarshalAddresses(conn)
+		if err != nil {
+			return nil, err
+		}
+		return &RemoveIPAliasCommand{Addresses: addrs}, nil
+	case AddIPAliasesType:
+		addrs, err := UnmarshalAddresses(conn)
+		if err != nil {
+			return nil, err
+		}
+		return &AddIPAliasCommand{Addresses: addrs}, nil
 	default:
 		return &UnimplementedCommand{
 			Instruction: instr,
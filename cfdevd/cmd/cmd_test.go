@@ -77,4 +77,30 @@ var _ = Describe("cmd", func() {
 			Fail("wrong type!")
 		}
 	})
+
+	It("returns a RemoveIPAliasCommand with the addresses that follow a 4", func() {
+		message := bytes.NewReader([]byte{uint8(4), 2, 10, 245, 0, 4, 10, 245, 0, 34})
+
+		command, err := cmd.UnmarshalCommand(message)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(command).To(Equal(&cmd.RemoveIPAliasCommand{Addresses: []string{"10.245.0.4", "10.245.0.34"}}))
+	})
+
+	It("returns a AddIPAliasCommand with the addresses that follow a 5", func() {
+		message := bytes.NewReader([]byte{uint8(5), 1, 192, 168, 144, 4})
+
+		command, err := cmd.UnmarshalCommand(message)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(command).To(Equal(&cmd.AddIPAliasCommand{Addresses: []string{"192.168.144.4"}}))
+	})
+
+	It("refuses to alias an address that is not private", func() {
+		message := bytes.NewReader([]byte{uint8(5), 1, 8, 8, 8, 8})
+
+		_, err := cmd.UnmarshalCommand(message)
+
+		Expect(err).To(MatchError("refusing to alias 8.8.8.8, which is not a private address"))
+	})
 })
@@ -10,13 +10,16 @@ import (
 	"strings"
 )
 
+// RemoveIPAliasCommand removes the loopback aliases of Addresses, or of the
+// default addresses when the client sent none.
 type RemoveIPAliasCommand struct {
+	Addresses []string
 }
 
 const loopback = "lo0"
 
 func (u *RemoveIPAliasCommand) Execute(conn *net.UnixConn) error {
-	err := u.RemoveLoopbackAliases(BOSH_IP, GOROUTER_IP)
+	err := u.RemoveLoopbackAliases(addressesOrDefaults(u.Addresses)...)
 	if err == nil {
 		conn.Write([]byte{0})
 	} else {
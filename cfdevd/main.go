@@ -82,6 +82,8 @@ func listenAndServe() {
 			command, err := cmd.UnmarshalCommand(conn)
 			if err != nil {
 				log.Printf("Command Error: %s\n", err)
+				conn.Write([]byte{1})
+				conn.Close()
 				continue
 			}
 
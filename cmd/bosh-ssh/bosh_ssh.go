@@ -0,0 +1,50 @@
+package bosh_ssh
+
+import (
+	e "code.cloudfoundry.org/cfdev/errors"
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/bosh-ssh Provisioner
+type Provisioner interface {
+	Ping() error
+	SSH(instance string, cmd []string) error
+}
+
+type Args struct {
+	Instance string
+	Command  []string
+}
+
+// BoshSSH opens a shell on, or runs a command on, a vm of one of the
+// deployments, e.g. to debug a failing diego cell.
+type BoshSSH struct {
+	Provisioner Provisioner
+}
+
+func (b *BoshSSH) Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "bosh-ssh INSTANCE [-- COMMAND...]",
+		Short: "SSH into a BOSH instance, e.g. diego-cell/0",
+		Example: `cf dev bosh-ssh diego-cell/0
+cf dev bosh-ssh diego-cell/0 -- sudo monit summary`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := b.Execute(Args{Instance: args[0], Command: args[1:]}); err != nil {
+				return e.SafeWrap(err, "cf dev bosh-ssh")
+			}
+			return nil
+		},
+	}
+}
+
+func (b *BoshSSH) Execute(args Args) error {
+	if err := b.Provisioner.Ping(); err != nil {
+		return e.SafeWrap(err, "cf dev is not running")
+	}
+
+	if err := b.Provisioner.SSH(args.Instance, args.Command); err != nil {
+		return e.SafeWrap(err, "ssh to "+args.Instance)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package bosh_ssh_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestBoshSSH(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "BoshSSH Suite")
+}
@@ -0,0 +1,66 @@
+package bosh_ssh_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cfdev/cmd/bosh-ssh"
+	"code.cloudfoundry.org/cfdev/cmd/bosh-ssh/mocks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BoshSSH", func() {
+	var (
+		mockController  *gomock.Controller
+		mockProvisioner *mocks.MockProvisioner
+		subject         *bosh_ssh.BoshSSH
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+
+		subject = &bosh_ssh.BoshSSH{
+			Provisioner: mockProvisioner,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("opens a shell on the instance", func() {
+		gomock.InOrder(
+			mockProvisioner.EXPECT().Ping(),
+			mockProvisioner.EXPECT().SSH("diego-cell/0", nil),
+		)
+
+		Expect(subject.Execute(bosh_ssh.Args{Instance: "diego-cell/0"})).To(Succeed())
+	})
+
+	It("runs the command on the instance", func() {
+		gomock.InOrder(
+			mockProvisioner.EXPECT().Ping(),
+			mockProvisioner.EXPECT().SSH("diego-cell/0", []string{"sudo", "monit", "summary"}),
+		)
+
+		Expect(subject.Execute(bosh_ssh.Args{
+			Instance: "diego-cell/0",
+			Command:  []string{"sudo", "monit", "summary"},
+		})).To(Succeed())
+	})
+
+	It("returns the ssh error", func() {
+		mockProvisioner.EXPECT().Ping()
+		mockProvisioner.EXPECT().SSH("diego-cell/0", nil).Return(errors.New("some-error"))
+
+		Expect(subject.Execute(bosh_ssh.Args{Instance: "diego-cell/0"})).To(MatchError(ContainSubstring("some-error")))
+	})
+
+	It("returns an error when cf dev is not running", func() {
+		mockProvisioner.EXPECT().Ping().Return(errors.New("not running"))
+
+		Expect(subject.Execute(bosh_ssh.Args{Instance: "diego-cell/0"})).To(MatchError(ContainSubstring("cf dev is not running")))
+	})
+})
@@ -4,11 +4,14 @@ import (
 	"code.cloudfoundry.org/cfdev/bosh"
 	"code.cloudfoundry.org/cfdev/cfanalytics"
 	"code.cloudfoundry.org/cfdev/config"
+	"io/ioutil"
 	"os"
+	"strings"
 
 	"runtime"
 
 	"code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
 	"code.cloudfoundry.org/cfdev/shell"
 	"github.com/spf13/cobra"
 )
@@ -24,11 +27,18 @@ type AnalyticsClient interface {
 	PromptOptInIfNeeded(string) error
 }
 
+//go:generate mockgen -package mocks -destination mocks/director.go code.cloudfoundry.org/cfdev/cmd/bosh Director
+type Director interface {
+	CloudConfig() (string, error)
+	UpdateCloudConfig(manifest []byte) error
+}
+
 type Bosh struct {
 	Exit      chan struct{}
 	UI        UI
 	Config    config.Config
 	Analytics AnalyticsClient
+	Director  Director
 }
 
 func (b *Bosh) Cmd() *cobra.Command {
@@ -36,23 +46,44 @@ func (b *Bosh) Cmd() *cobra.Command {
 		Use: "bosh",
 		Run: func(cmd *cobra.Command, args []string) {
 			if runtime.GOOS != "windows" {
-				b.UI.Say(`Usage: eval $(cf dev bosh env)`)
+				b.UI.Say(messages.T("bosh.usage"))
 			} else {
-				b.UI.Say(`Usage: cf dev bosh env | Invoke-Expression`)
+				b.UI.Say(messages.T("bosh.usage-windows"))
 			}
 		},
 	}
+	var shellName string
 	envCmd := &cobra.Command{
-		Use: "env",
+		Use:     "env",
+		Short:   "Print exports that point the bosh cli at the BOSH Director",
+		Example: `eval "$(cf dev bosh env)"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return b.Env(shellName)
+		},
+	}
+	envCmd.Flags().StringVar(&shellName, "shell", "", "shell to print exports for: "+strings.Join(shell.Shells, ", ")+" (default: your shell)")
+	cloudConfigCmd := &cobra.Command{
+		Use:   "cloud-config",
+		Short: "Show the BOSH Director's cloud config",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return b.Env()
+			return b.CloudConfig()
 		},
 	}
-	cmd.AddCommand(envCmd)
+	updateCloudConfigCmd := &cobra.Command{
+		Use:   "update-cloud-config PATH",
+		Short: "Replace the BOSH Director's cloud config",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return b.UpdateCloudConfig(args[0])
+		},
+	}
+	cmd.AddCommand(envCmd, cloudConfigCmd, updateCloudConfigCmd)
 	return cmd
 }
 
-func (b *Bosh) Env() error {
+// Env prints the exports for shellName, or the user's shell when it is
+// empty.
+func (b *Bosh) Env(shellName string) error {
 	go func() {
 		<-b.Exit
 		os.Exit(128)
@@ -65,7 +96,7 @@ func (b *Bosh) Env() error {
 
 	b.Analytics.Event(cfanalytics.BOSH_ENV)
 
-	env := shell.Environment{}
+	env := shell.Environment{Shell: shellName}
 	shellScript, err := env.Prepare(config)
 	if err != nil {
 		return errors.SafeWrap(err, "failed to prepare bosh configuration")
@@ -74,3 +105,27 @@ func (b *Bosh) Env() error {
 	b.UI.Say(shellScript)
 	return nil
 }
+
+func (b *Bosh) CloudConfig() error {
+	cloudConfig, err := b.Director.CloudConfig()
+	if err != nil {
+		return errors.SafeWrap(err, "failed to fetch the cloud config")
+	}
+
+	b.UI.Say(cloudConfig)
+	return nil
+}
+
+func (b *Bosh) UpdateCloudConfig(path string) error {
+	manifest, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.SafeWrap(err, "failed to read the cloud config")
+	}
+
+	if err := b.Director.UpdateCloudConfig(manifest); err != nil {
+		return errors.SafeWrap(err, "failed to update the cloud config")
+	}
+
+	b.UI.Say(messages.T("bosh.cloud-config-updated"))
+	return nil
+}
@@ -82,7 +82,7 @@ export BOSH_GW_USER="jumpbox";`,
 					filepath.Join(tmpDir, "jumpbox.key"),
 				))
 
-				Expect(boshCmd.Env()).To(Succeed())
+				Expect(boshCmd.Env("bash")).To(Succeed())
 			})
 		})
 	})
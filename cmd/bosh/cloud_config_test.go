@@ -0,0 +1,70 @@
+package bosh_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	cmd "code.cloudfoundry.org/cfdev/cmd/bosh"
+	"code.cloudfoundry.org/cfdev/cmd/bosh/mocks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cloud config", func() {
+	var (
+		mockController *gomock.Controller
+		mockUI         *mocks.MockUI
+		mockDirector   *mocks.MockDirector
+		boshCmd        *cmd.Bosh
+		tmpDir         string
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockDirector = mocks.NewMockDirector(mockController)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cmd-bosh-cloud-config")
+		Expect(err).NotTo(HaveOccurred())
+
+		boshCmd = &cmd.Bosh{
+			UI:       mockUI,
+			Director: mockDirector,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("prints the cloud config", func() {
+		mockDirector.EXPECT().CloudConfig().Return("vm_types: []", nil)
+		mockUI.EXPECT().Say("vm_types: []")
+
+		Expect(boshCmd.CloudConfig()).To(Succeed())
+	})
+
+	It("updates the cloud config from a file", func() {
+		path := filepath.Join(tmpDir, "cloud-config.yml")
+		Expect(ioutil.WriteFile(path, []byte("networks: []"), 0600)).To(Succeed())
+
+		mockDirector.EXPECT().UpdateCloudConfig([]byte("networks: []"))
+		mockUI.EXPECT().Say("Updated the cloud config. Existing deployments pick it up when they are next deployed")
+
+		Expect(boshCmd.UpdateCloudConfig(path)).To(Succeed())
+	})
+
+	It("returns an error when the director rejects the cloud config", func() {
+		path := filepath.Join(tmpDir, "cloud-config.yml")
+		Expect(ioutil.WriteFile(path, []byte("networks: ["), 0600)).To(Succeed())
+
+		mockDirector.EXPECT().UpdateCloudConfig(gomock.Any()).Return(errors.New("some-error"))
+
+		Expect(boshCmd.UpdateCloudConfig(path)).To(MatchError("failed to update the cloud config: some-error"))
+	})
+})
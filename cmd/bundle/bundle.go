@@ -0,0 +1,128 @@
+package bundle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/bundle UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/hypervisor.go code.cloudfoundry.org/cfdev/cmd/bundle Hypervisor
+type Hypervisor interface {
+	IsRunning(vmName string) (bool, error)
+	Export(vmName string, path string) error
+	Import(path string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/env.go code.cloudfoundry.org/cfdev/cmd/bundle Env
+type Env interface {
+	CreateDirs() error
+	MarkImported() error
+}
+
+// Export writes the vm's disk and bosh state to a bundle that can be
+// imported on another machine with the same hypervisor.
+type Export struct {
+	UI         UI
+	Hypervisor Hypervisor
+	Config     config.Config
+}
+
+func (x *Export) Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export PATH",
+		Short: "Export the stopped VM to a bundle that can be imported on another machine",
+		Args:  cobra.ExactArgs(1),
+		RunE:  x.RunE,
+	}
+}
+
+func (x *Export) RunE(cmd *cobra.Command, args []string) error {
+	path, err := filepath.Abs(args[0])
+	if err != nil {
+		return e.SafeWrap(err, "determining absolute path to bundle")
+	}
+
+	if err := ensureStopped(x.Hypervisor, x.Config.VMName()); err != nil {
+		return err
+	}
+
+	x.UI.Say(messages.T("bundle.exporting", map[string]interface{}{"Path": path}))
+	if err := x.Hypervisor.Export(x.Config.VMName(), path); err != nil {
+		return e.SafeWrap(err, "cf dev export")
+	}
+
+	x.UI.Say(messages.T("bundle.exported"))
+	return nil
+}
+
+// Import replaces the vm's state with that of an exported bundle. The next
+// cf dev start boots from it.
+type Import struct {
+	UI         UI
+	Hypervisor Hypervisor
+	Env        Env
+	Config     config.Config
+}
+
+func (i *Import) Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import PATH",
+		Short: "Import a bundle made by cf dev export for the next start",
+		Args:  cobra.ExactArgs(1),
+		RunE:  i.RunE,
+	}
+}
+
+func (i *Import) RunE(cmd *cobra.Command, args []string) error {
+	path, err := filepath.Abs(args[0])
+	if err != nil {
+		return e.SafeWrap(err, "determining absolute path to bundle")
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("no file found at: %s", path)
+	}
+
+	if err := ensureStopped(i.Hypervisor, i.Config.VMName()); err != nil {
+		return err
+	}
+
+	if err := i.Env.CreateDirs(); err != nil {
+		return e.SafeWrap(err, "setting up cfdev home dir")
+	}
+
+	i.UI.Say(messages.T("bundle.importing", map[string]interface{}{"Path": path}))
+	if err := i.Hypervisor.Import(path); err != nil {
+		return e.SafeWrap(err, "cf dev import")
+	}
+
+	if err := i.Env.MarkImported(); err != nil {
+		return err
+	}
+
+	i.UI.Say(messages.T("bundle.imported"))
+	return nil
+}
+
+// ensureStopped refuses to touch the disk of a running vm, which would
+// not be consistent.
+func ensureStopped(hypervisor Hypervisor, vmName string) error {
+	running, err := hypervisor.IsRunning(vmName)
+	if err != nil {
+		return e.SafeWrap(err, "is running")
+	}
+	if running {
+		return fmt.Errorf("cf dev is running. Please execute 'cf dev stop' first")
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package bundle_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/cmd/bundle"
+	"code.cloudfoundry.org/cfdev/cmd/bundle/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bundle", func() {
+	var (
+		mockController *gomock.Controller
+		mockUI         *mocks.MockUI
+		mockHypervisor *mocks.MockHypervisor
+		mockEnv        *mocks.MockEnv
+		dir            string
+		path           string
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockHypervisor = mocks.NewMockHypervisor(mockController)
+		mockEnv = mocks.NewMockEnv(mockController)
+
+		var err error
+		dir, err = ioutil.TempDir("", "cfdev-bundle")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "env.tgz")
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(dir)
+	})
+
+	Describe("Export", func() {
+		var subject *bundle.Export
+
+		BeforeEach(func() {
+			subject = &bundle.Export{
+				UI:         mockUI,
+				Hypervisor: mockHypervisor,
+				Config:     config.Config{},
+			}
+		})
+
+		It("exports the vm", func() {
+			gomock.InOrder(
+				mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
+				mockUI.EXPECT().Say("Exporting the VM to "+path+"..."),
+				mockHypervisor.EXPECT().Export("cfdev", path),
+				mockUI.EXPECT().Say("Done"),
+			)
+
+			Expect(subject.RunE(nil, []string{path})).To(Succeed())
+		})
+
+		Context("when the vm is running", func() {
+			It("returns an error", func() {
+				mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil)
+
+				Expect(subject.RunE(nil, []string{path})).To(MatchError("cf dev is running. Please execute 'cf dev stop' first"))
+			})
+		})
+
+		Context("when exporting fails", func() {
+			It("returns an error", func() {
+				mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil)
+				mockUI.EXPECT().Say(gomock.Any())
+				mockHypervisor.EXPECT().Export("cfdev", path).Return(errors.New("some-error"))
+
+				Expect(subject.RunE(nil, []string{path})).To(MatchError("cf dev export: some-error"))
+			})
+		})
+	})
+
+	Describe("Import", func() {
+		var subject *bundle.Import
+
+		BeforeEach(func() {
+			subject = &bundle.Import{
+				UI:         mockUI,
+				Hypervisor: mockHypervisor,
+				Env:        mockEnv,
+				Config:     config.Config{},
+			}
+			Expect(ioutil.WriteFile(path, []byte("some-bundle"), 0600)).To(Succeed())
+		})
+
+		It("imports the bundle for the next start", func() {
+			gomock.InOrder(
+				mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
+				mockEnv.EXPECT().CreateDirs(),
+				mockUI.EXPECT().Say("Importing "+path+"..."),
+				mockHypervisor.EXPECT().Import(path),
+				mockEnv.EXPECT().MarkImported(),
+				mockUI.EXPECT().Say("Done. Run 'cf dev start' to boot the imported VM"),
+			)
+
+			Expect(subject.RunE(nil, []string{path})).To(Succeed())
+		})
+
+		Context("when the bundle does not exist", func() {
+			It("returns an error", func() {
+				missing := filepath.Join(dir, "missing.tgz")
+
+				Expect(subject.RunE(nil, []string{missing})).To(MatchError("no file found at: " + missing))
+			})
+		})
+
+		Context("when the vm is running", func() {
+			It("returns an error", func() {
+				mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil)
+
+				Expect(subject.RunE(nil, []string{path})).To(MatchError("cf dev is running. Please execute 'cf dev stop' first"))
+			})
+		})
+
+		Context("when importing fails", func() {
+			It("does not mark the state as imported", func() {
+				mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil)
+				mockEnv.EXPECT().CreateDirs()
+				mockUI.EXPECT().Say(gomock.Any())
+				mockHypervisor.EXPECT().Import(path).Return(errors.New("some-error"))
+
+				Expect(subject.RunE(nil, []string{path})).To(MatchError("cf dev import: some-error"))
+			})
+		})
+	})
+})
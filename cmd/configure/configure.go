@@ -0,0 +1,164 @@
+package configure
+
+import (
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+	"github.com/spf13/cobra"
+)
+
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/telemetry.go code.cloudfoundry.org/cfdev/cmd/configure Telemetry
+type Telemetry interface {
+	TurnOn() error
+	TurnOff() error
+}
+
+//go:generate mockgen -package mocks -destination mocks/stop.go code.cloudfoundry.org/cfdev/cmd/configure Stop
+type Stop interface {
+	RunE(cmd *cobra.Command, args []string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/env.go code.cloudfoundry.org/cfdev/cmd/configure Env
+type Env interface {
+	MoveHome(dir string) error
+}
+
+// Configure reads and writes the settings in config.yml, so that they need
+// not be edited by hand.
+type Configure struct {
+	UI        UI
+	Telemetry Telemetry
+	Stop      Stop
+	Env       Env
+	Config    config.Config
+}
+
+func (c *Configure) Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show and change the settings cf dev starts with",
+	}
+
+	getCmd := &cobra.Command{
+		Use:     "get [SETTING]",
+		Short:   "Show a setting, or every setting",
+		Example: "cf dev config get memory",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, cmdArgs []string) error {
+			key := ""
+			if len(cmdArgs) > 0 {
+				key = cmdArgs[0]
+			}
+			if err := c.Get(key); err != nil {
+				return e.SafeWrap(err, "cf dev config get")
+			}
+			return nil
+		},
+	}
+
+	setCmd := &cobra.Command{
+		Use:     "set SETTING VALUE",
+		Short:   "Change a setting, or remove it with an empty value",
+		Example: "cf dev config set memory 8192\ncf dev config set home D:\\cfdev\ncf dev config set registries registry.example.com:5000,localhost:5000\ncf dev config set proxy.http \"\"",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, cmdArgs []string) error {
+			if err := c.Set(cmdArgs[0], cmdArgs[1]); err != nil {
+				return e.SafeWrap(err, "cf dev config set")
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(getCmd, setCmd)
+	return cmd
+}
+
+// Get prints the value of key as is, for scripts, or every setting when
+// key is empty.
+func (c *Configure) Get(key string) error {
+	file, err := config.LoadFile(c.Config.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	if key != "" {
+		value, err := file.Get(key)
+		if err != nil {
+			return err
+		}
+		c.UI.Say("%s", value)
+		return nil
+	}
+
+	for _, key := range config.Settings {
+		value, _ := file.Get(key)
+		c.UI.Say(messages.T("config.setting", map[string]interface{}{"Key": key, "Value": value}))
+	}
+	return nil
+}
+
+// Set saves value as key in config.yml. Telemetry is also turned on or off
+// straight away, since the file only answers the prompt of those who were
+// not asked yet, and the home is moved before it is saved.
+func (c *Configure) Set(key, value string) error {
+	path := c.Config.ConfigFile
+	file, err := config.LoadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := file.Set(key, value); err != nil {
+		return err
+	}
+	if key == "home" {
+		if err := c.moveHome(file.Home); err != nil {
+			return err
+		}
+	}
+	if err := config.SaveFile(path, file); err != nil {
+		return err
+	}
+
+	if key == "telemetry" && file.Telemetry != nil {
+		if err := c.setTelemetry(*file.Telemetry); err != nil {
+			return err
+		}
+	}
+
+	saved, _ := file.Get(key)
+	c.UI.Say(messages.T("config.set", map[string]interface{}{"Key": key, "Value": saved, "Path": path}))
+	return nil
+}
+
+// moveHome stops cf dev, which removes the daemons that refer to the
+// home, and moves it to dir, or back beside the config file when dir is
+// empty. The next start sets the daemons up again from there.
+func (c *Configure) moveHome(dir string) error {
+	if dir == "" {
+		dir = filepath.Dir(c.Config.ConfigFile)
+	}
+	if filepath.Clean(dir) == filepath.Clean(c.Config.CFDevHome) {
+		return nil
+	}
+
+	c.UI.Say(messages.T("config.moving-home", map[string]interface{}{"From": c.Config.CFDevHome, "To": dir}))
+	if err := c.Stop.RunE(nil, nil); err != nil {
+		return e.SafeWrap(err, "stopping cf dev")
+	}
+	if err := c.Env.MoveHome(dir); err != nil {
+		return e.SafeWrap(err, "moving the home, run the command again to finish")
+	}
+	return nil
+}
+
+func (c *Configure) setTelemetry(enabled bool) error {
+	if enabled {
+		return c.Telemetry.TurnOn()
+	}
+	return c.Telemetry.TurnOff()
+}
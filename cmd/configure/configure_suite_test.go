@@ -0,0 +1,13 @@
+package configure_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestConfigure(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Configure Suite")
+}
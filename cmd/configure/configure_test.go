@@ -0,0 +1,184 @@
+package configure_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/cmd/configure"
+	"code.cloudfoundry.org/cfdev/cmd/configure/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type recordingUI struct {
+	said []string
+}
+
+func (r *recordingUI) Say(message string, args ...interface{}) {
+	r.said = append(r.said, fmt.Sprintf(message, args...))
+}
+
+var _ = Describe("Configure", func() {
+	var (
+		mockController *gomock.Controller
+		mockTelemetry  *mocks.MockTelemetry
+		mockStop       *mocks.MockStop
+		mockEnv        *mocks.MockEnv
+		ui             *recordingUI
+		cfdevHome      string
+		subject        *configure.Configure
+	)
+
+	BeforeEach(func() {
+		var err error
+		cfdevHome, err = ioutil.TempDir("", "cfdev-configure-")
+		Expect(err).NotTo(HaveOccurred())
+
+		mockController = gomock.NewController(GinkgoT())
+		mockTelemetry = mocks.NewMockTelemetry(mockController)
+		mockStop = mocks.NewMockStop(mockController)
+		mockEnv = mocks.NewMockEnv(mockController)
+		ui = &recordingUI{}
+		subject = &configure.Configure{
+			UI:        ui,
+			Telemetry: mockTelemetry,
+			Stop:      mockStop,
+			Env:       mockEnv,
+			Config: config.Config{
+				CFDevHome:  cfdevHome,
+				ConfigFile: filepath.Join(cfdevHome, "config.yml"),
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(cfdevHome)
+	})
+
+	writeFile := func(contents string) {
+		Expect(ioutil.WriteFile(filepath.Join(cfdevHome, "config.yml"), []byte(contents), 0644)).To(Succeed())
+	}
+
+	readFile := func() string {
+		contents, err := ioutil.ReadFile(filepath.Join(cfdevHome, "config.yml"))
+		Expect(err).NotTo(HaveOccurred())
+		return string(contents)
+	}
+
+	Describe("Get", func() {
+		BeforeEach(func() {
+			writeFile("memory: 8192\nservices: [mysql, redis]\nproxy:\n  http: http://proxy.example.com:3128\n")
+		})
+
+		It("prints the value of a setting", func() {
+			Expect(subject.Get("services")).To(Succeed())
+			Expect(ui.said).To(Equal([]string{"mysql,redis"}))
+		})
+
+		It("prints a value as it is", func() {
+			writeFile("proxy:\n  no_proxy: 100%.example.com\n")
+			Expect(subject.Get("proxy.no_proxy")).To(Succeed())
+			Expect(ui.said).To(Equal([]string{"100%.example.com"}))
+		})
+
+		It("prints every setting when none is given", func() {
+			Expect(subject.Get("")).To(Succeed())
+			Expect(ui.said).To(ContainElement("memory: 8192"))
+			Expect(ui.said).To(ContainElement("cpus: "))
+			Expect(ui.said).To(ContainElement("proxy.http: http://proxy.example.com:3128"))
+			Expect(ui.said).To(HaveLen(len(config.Settings)))
+		})
+
+		It("fails on an unknown setting", func() {
+			Expect(subject.Get("ram")).To(MatchError(ContainSubstring(`unknown setting "ram"`)))
+		})
+	})
+
+	Describe("Set", func() {
+		It("saves the setting, creating the file", func() {
+			Expect(subject.Set("memory", "8192")).To(Succeed())
+			Expect(readFile()).To(Equal("memory: 8192\n"))
+			Expect(ui.said).To(Equal([]string{"Set memory to '8192' in " + filepath.Join(cfdevHome, "config.yml")}))
+		})
+
+		It("keeps the other settings", func() {
+			writeFile("cpus: 6\n")
+			Expect(subject.Set("registries", "registry.example.com:5000, localhost:5000")).To(Succeed())
+
+			file, err := config.LoadFile(filepath.Join(cfdevHome, "config.yml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*file.Cpus).To(Equal(6))
+			Expect(file.Registries).To(Equal([]string{"registry.example.com:5000", "localhost:5000"}))
+		})
+
+		It("removes a setting set to nothing", func() {
+			writeFile("cpus: 6\nproxy:\n  http: http://proxy.example.com:3128\n")
+			Expect(subject.Set("proxy.http", "")).To(Succeed())
+			Expect(readFile()).To(Equal("cpus: 6\n"))
+		})
+
+		It("rejects a value of the wrong type without saving it", func() {
+			writeFile("memory: 8192\n")
+			Expect(subject.Set("memory", "lots")).To(MatchError(ContainSubstring(`memory must be a whole number above 0, not "lots"`)))
+			Expect(readFile()).To(Equal("memory: 8192\n"))
+		})
+
+		It("turns telemetry on or off straight away", func() {
+			mockTelemetry.EXPECT().TurnOff()
+			Expect(subject.Set("telemetry", "false")).To(Succeed())
+			Expect(readFile()).To(Equal("telemetry: false\n"))
+
+			mockTelemetry.EXPECT().TurnOn()
+			Expect(subject.Set("telemetry", "true")).To(Succeed())
+			Expect(readFile()).To(Equal("telemetry: true\n"))
+		})
+
+		Context("home", func() {
+			var newHome string
+
+			BeforeEach(func() {
+				newHome = filepath.Join(cfdevHome, "bigger-drive")
+			})
+
+			It("stops cf dev and moves the home before recording it", func() {
+				gomock.InOrder(
+					mockStop.EXPECT().RunE(nil, nil),
+					mockEnv.EXPECT().MoveHome(newHome),
+				)
+
+				Expect(subject.Set("home", newHome)).To(Succeed())
+				Expect(readFile()).To(Equal("home: " + newHome + "\n"))
+				Expect(ui.said[0]).To(Equal("Stopping CF Dev and moving " + cfdevHome + " to " + newHome + "..."))
+			})
+
+			It("moves a moved home back beside the config file", func() {
+				writeFile("home: " + newHome + "\n")
+				subject.Config.CFDevHome = newHome
+				gomock.InOrder(
+					mockStop.EXPECT().RunE(nil, nil),
+					mockEnv.EXPECT().MoveHome(cfdevHome),
+				)
+
+				Expect(subject.Set("home", "")).To(Succeed())
+				Expect(readFile()).To(Equal("{}\n"))
+			})
+
+			It("does not record a home that failed to move", func() {
+				writeFile("cpus: 6\n")
+				gomock.InOrder(
+					mockStop.EXPECT().RunE(nil, nil),
+					mockEnv.EXPECT().MoveHome(newHome).Return(errors.New("disk full")),
+				)
+
+				Expect(subject.Set("home", newHome)).To(MatchError(ContainSubstring("disk full")))
+				Expect(readFile()).To(Equal("cpus: 6\n"))
+			})
+		})
+	})
+})
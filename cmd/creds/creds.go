@@ -0,0 +1,182 @@
+package creds
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/credhub"
+	"code.cloudfoundry.org/cfdev/creds"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/creds UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/keychain.go code.cloudfoundry.org/cfdev/cmd/creds Keychain
+type Keychain interface {
+	Store(c creds.Credentials) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/credhub.go code.cloudfoundry.org/cfdev/cmd/creds CredHub
+type CredHub interface {
+	GetCredential(name string) (credhub.Credential, error)
+	SetCredential(name, credType string, value interface{}) error
+}
+
+type Args struct {
+	JSON bool
+}
+
+// Creds prints every credential of the environment and mirrors them into
+// the host's credential store. The state dir stays the source of truth.
+type Creds struct {
+	UI       UI
+	Config   config.Config
+	Keychain Keychain
+	CredHub  CredHub
+}
+
+func (c *Creds) Cmd() *cobra.Command {
+	args := Args{}
+	cmd := &cobra.Command{
+		Use:   "creds",
+		Short: "Show the credentials of CF, UAA, the BOSH director and CredHub",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := c.Execute(args); err != nil {
+				return e.SafeWrap(err, "cf dev creds")
+			}
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().BoolVar(&args.JSON, "json", false, "print the credentials as json")
+
+	getCmd := &cobra.Command{
+		Use:     "get NAME",
+		Short:   "Show a credential stored in CredHub",
+		Example: "cf dev creds get /bosh-lite/cf/cf_admin_password",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, cmdArgs []string) error {
+			if err := c.Get(cmdArgs[0]); err != nil {
+				return e.SafeWrap(err, "cf dev creds get")
+			}
+			return nil
+		},
+	}
+
+	var credType string
+	setCmd := &cobra.Command{
+		Use:     "set NAME VALUE",
+		Short:   "Store a credential in CredHub, for deployments to use",
+		Example: "cf dev creds set /bosh-lite/cf-redis/api_key some-api-key",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, cmdArgs []string) error {
+			if err := c.Set(cmdArgs[0], credType, cmdArgs[1]); err != nil {
+				return e.SafeWrap(err, "cf dev creds set")
+			}
+			return nil
+		},
+	}
+	setCmd.Flags().StringVarP(&credType, "type", "t", "value", "the CredHub type of the credential: value or password")
+
+	cmd.AddCommand(getCmd, setCmd)
+	return cmd
+}
+
+func (c *Creds) Execute(args Args) error {
+	credentials, err := creds.Load(c.Config)
+	if err != nil {
+		return e.SafeWrap(err, "cf dev is not provisioned. Please run 'cf dev start' first")
+	}
+
+	c.cfAdminFromCredHub(&credentials)
+
+	if err := c.Keychain.Store(credentials); err != nil && !args.JSON {
+		c.UI.Say(messages.T("creds.keychain-failed", map[string]interface{}{"Error": err}))
+	}
+
+	if args.JSON {
+		bytes, err := json.MarshalIndent(credentials, "", "  ")
+		if err != nil {
+			return e.SafeWrap(err, "unable to marshal credentials")
+		}
+		c.UI.Say(string(bytes))
+		return nil
+	}
+
+	c.say("CF admin", credentials.CF)
+	c.say("UAA admin client", credentials.UAAAdminClient)
+	c.say("BOSH director", credentials.Director.Account)
+	c.say("CredHub", credentials.CredHub)
+	return nil
+}
+
+// cfAdminPassword is where deploy-cf keeps CF's admin password when it
+// generates one in place of the config's.
+const cfAdminPassword = "/bosh-lite/cf/cf_admin_password"
+
+// cfAdminFromCredHub takes CF's admin password from the director's CredHub,
+// when it is deployed and has one.
+func (c *Creds) cfAdminFromCredHub(credentials *creds.Credentials) {
+	if credentials.CredHub.Password == "" {
+		return
+	}
+	credential, err := c.CredHub.GetCredential(cfAdminPassword)
+	if err != nil {
+		return
+	}
+	if password, ok := credential.Value.(string); ok && password != "" {
+		credentials.CF.Password = password
+	}
+}
+
+func (c *Creds) say(name string, account creds.Account) {
+	if account.Password == "" {
+		return
+	}
+
+	c.UI.Say(messages.T("creds.account", map[string]interface{}{
+		"Name":     name,
+		"Username": account.Username,
+		"Password": account.Password,
+	}))
+}
+
+// Get prints the credential's value: as is when it is a string, e.g. a
+// password, and as json otherwise, e.g. a certificate.
+func (c *Creds) Get(name string) error {
+	credential, err := c.CredHub.GetCredential(name)
+	if err != nil {
+		return err
+	}
+
+	if value, ok := credential.Value.(string); ok {
+		c.UI.Say(value)
+		return nil
+	}
+
+	bytes, err := json.MarshalIndent(credential.Value, "", "  ")
+	if err != nil {
+		return e.SafeWrap(err, "unable to marshal credential")
+	}
+	c.UI.Say(string(bytes))
+	return nil
+}
+
+func (c *Creds) Set(name, credType, value string) error {
+	if credType != "value" && credType != "password" {
+		return fmt.Errorf("unsupported credential type %s, use value or password", credType)
+	}
+
+	if err := c.CredHub.SetCredential(name, credType, value); err != nil {
+		return err
+	}
+
+	c.UI.Say(messages.T("creds.set", map[string]interface{}{"Name": name}))
+	return nil
+}
@@ -0,0 +1,160 @@
+package creds_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	cmd "code.cloudfoundry.org/cfdev/cmd/creds"
+	"code.cloudfoundry.org/cfdev/cmd/creds/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/credhub"
+	"code.cloudfoundry.org/cfdev/creds"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Creds", func() {
+	var (
+		mockController *gomock.Controller
+		mockUI         *mocks.MockUI
+		mockKeychain   *mocks.MockKeychain
+		mockCredHub    *mocks.MockCredHub
+		tmpDir         string
+		subject        *cmd.Creds
+
+		cfAdminCredential credhub.Credential
+		cfAdminErr        error
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockKeychain = mocks.NewMockKeychain(mockController)
+		mockCredHub = mocks.NewMockCredHub(mockController)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cmd-creds-test")
+		Expect(err).NotTo(HaveOccurred())
+		ioutil.WriteFile(filepath.Join(tmpDir, "secret"), []byte("some-director-secret"), 0600)
+		ioutil.WriteFile(filepath.Join(tmpDir, "creds.yml"), []byte("credhub_admin_client_secret: some-credhub-secret\n"), 0600)
+
+		subject = &cmd.Creds{
+			UI:       mockUI,
+			Keychain: mockKeychain,
+			CredHub:  mockCredHub,
+			Config: config.Config{
+				StateBosh:       tmpDir,
+				BoshDirectorIP:  "10.0.0.1",
+				CFAdminUsername: "admin",
+				CFAdminPassword: "admin",
+			},
+		}
+		cfAdminCredential, cfAdminErr = credhub.Credential{}, errors.New("credential not found")
+	})
+
+	JustBeforeEach(func() {
+		mockCredHub.EXPECT().GetCredential("/bosh-lite/cf/cf_admin_password").Return(cfAdminCredential, cfAdminErr).AnyTimes()
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("mirrors the credentials into the keychain and prints them", func() {
+		gomock.InOrder(
+			mockKeychain.EXPECT().Store(gomock.Any()),
+			mockUI.EXPECT().Say("CF admin: admin / admin"),
+			mockUI.EXPECT().Say("BOSH director: admin / some-director-secret"),
+			mockUI.EXPECT().Say("CredHub: credhub-admin / some-credhub-secret"),
+		)
+
+		Expect(subject.Execute(cmd.Args{})).To(Succeed())
+	})
+
+	Context("when CredHub has CF's admin password", func() {
+		BeforeEach(func() {
+			cfAdminCredential, cfAdminErr = credhub.Credential{Type: "password", Value: "some-cf-password"}, nil
+		})
+
+		It("prints that one", func() {
+			mockKeychain.EXPECT().Store(gomock.Any())
+			mockUI.EXPECT().Say("CF admin: admin / some-cf-password")
+			mockUI.EXPECT().Say(gomock.Any()).Times(2)
+
+			Expect(subject.Execute(cmd.Args{})).To(Succeed())
+		})
+	})
+
+	Context("when --json is given", func() {
+		It("prints the credentials as json", func() {
+			var output string
+			mockKeychain.EXPECT().Store(gomock.Any())
+			mockUI.EXPECT().Say(gomock.Any()).Do(func(message string, _ ...interface{}) {
+				output = message
+			})
+
+			Expect(subject.Execute(cmd.Args{JSON: true})).To(Succeed())
+
+			var credentials creds.Credentials
+			Expect(json.Unmarshal([]byte(output), &credentials)).To(Succeed())
+			Expect(credentials.Director.Password).To(Equal("some-director-secret"))
+			Expect(credentials.Director.Address).To(Equal("10.0.0.1"))
+			Expect(credentials.CredHub.Password).To(Equal("some-credhub-secret"))
+		})
+	})
+
+	Context("when the keychain cannot be written to", func() {
+		It("warns and still prints the credentials", func() {
+			mockKeychain.EXPECT().Store(gomock.Any()).Return(errors.New("some-error"))
+			mockUI.EXPECT().Say("[WARN] Unable to mirror the credentials into the keychain: some-error")
+			mockUI.EXPECT().Say(gomock.Any()).Times(3)
+
+			Expect(subject.Execute(cmd.Args{})).To(Succeed())
+		})
+	})
+
+	Context("when cf dev has not been provisioned", func() {
+		It("returns an error", func() {
+			os.Remove(filepath.Join(tmpDir, "secret"))
+
+			Expect(subject.Execute(cmd.Args{})).To(MatchError(ContainSubstring("Please run 'cf dev start' first")))
+		})
+	})
+
+	Describe("Get", func() {
+		It("prints string values as they are", func() {
+			mockCredHub.EXPECT().GetCredential("/cf/admin_password").Return(credhub.Credential{Type: "password", Value: "some-password"}, nil)
+			mockUI.EXPECT().Say("some-password")
+
+			Expect(subject.Get("/cf/admin_password")).To(Succeed())
+		})
+
+		It("prints other values as json", func() {
+			mockCredHub.EXPECT().GetCredential("/cf/admin").Return(credhub.Credential{
+				Type:  "user",
+				Value: map[string]interface{}{"username": "admin"},
+			}, nil)
+			mockUI.EXPECT().Say("{\n  \"username\": \"admin\"\n}")
+
+			Expect(subject.Get("/cf/admin")).To(Succeed())
+		})
+	})
+
+	Describe("Set", func() {
+		It("stores the value in credhub", func() {
+			mockCredHub.EXPECT().SetCredential("/my/secret", "password", "some-secret")
+			mockUI.EXPECT().Say("Stored /my/secret in CredHub")
+
+			Expect(subject.Set("/my/secret", "password", "some-secret")).To(Succeed())
+		})
+
+		It("rejects types that do not take a single value", func() {
+			Expect(subject.Set("/my/secret", "certificate", "some-cert")).To(MatchError(ContainSubstring("unsupported credential type certificate")))
+		})
+	})
+})
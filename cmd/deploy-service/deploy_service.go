@@ -4,6 +4,7 @@ import (
 	"code.cloudfoundry.org/cfdev/cfanalytics"
 	"code.cloudfoundry.org/cfdev/config"
 	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
 	"code.cloudfoundry.org/cfdev/metadata"
 	"code.cloudfoundry.org/cfdev/provision"
 	"fmt"
@@ -30,6 +31,8 @@ type Provisioner interface {
 	Ping() error
 	DeployServices(provision.UI, []provision.Service) error
 	GetWhiteListedService(string, []provision.Service) (*provision.Service, error)
+	CancelDeploy() error
+	DiffService(provision.Service) ([]string, error)
 }
 
 //go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/stop Analytics
@@ -50,6 +53,7 @@ type DeployService struct {
 
 type Args struct {
 	Service string
+	DryRun  bool
 }
 
 func (c *DeployService) Cmd() *cobra.Command {
@@ -60,12 +64,17 @@ func (c *DeployService) Cmd() *cobra.Command {
 		Long: "Command deploy a new service provided as a parameter",
 	}
 
+	cmd.Flags().Bool("dry-run", false, "print the changes to the service's manifest instead of deploying it")
 	return cmd
 }
 
 func (c *DeployService) RunE(cmd *cobra.Command, args []string) error {
 	go func() {
 		<-c.Exit
+		c.UI.Say(messages.T("provision.cancelling"))
+		if err := c.Provisioner.CancelDeploy(); err != nil {
+			c.UI.Say(messages.T("provision.cancel-failed", map[string]interface{}{"Error": err}))
+		}
 		os.Exit(128)
 	}()
 
@@ -73,8 +82,10 @@ func (c *DeployService) RunE(cmd *cobra.Command, args []string) error {
 		return errors.New("A service name need to be passed as a argument")
 	}
 
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	return c.Execute(Args{
 		Service: args[0],
+		DryRun:  dryRun,
 	})
 }
 
@@ -98,6 +109,10 @@ func (c *DeployService) Execute(args Args) error {
 		return e.SafeWrap(err, "Failed to whitelist service")
 	}
 
+	if args.DryRun {
+		return c.diff(*service)
+	}
+
 	if err := c.Provisioner.DeployServices(c.UI, []provision.Service{*service}); err != nil {
 		return e.SafeWrap(err, "Failed to deploy services")
 	}
@@ -107,3 +122,22 @@ func (c *DeployService) Execute(args Args) error {
 
 	return nil
 }
+
+// diff prints what deploying the service would change, without deploying
+// it.
+func (c *DeployService) diff(service provision.Service) error {
+	lines, err := c.Provisioner.DiffService(service)
+	if err != nil {
+		return e.SafeWrap(err, "Failed to compare the service's manifest")
+	}
+
+	if len(lines) == 0 {
+		c.UI.Say(messages.T("deploy-service.no-changes", map[string]interface{}{"Service": service.Name}))
+		return nil
+	}
+
+	for _, line := range lines {
+		c.UI.Say(line)
+	}
+	return nil
+}
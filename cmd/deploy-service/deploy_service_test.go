@@ -84,6 +84,35 @@ var _ = Describe("DeployService", func() {
 		})
 	})
 
+	Describe("when --dry-run is given", func() {
+		var service provision.Service
+
+		BeforeEach(func() {
+			service = provision.Service{Name: "some-service"}
+			mockMetadataReader.EXPECT().Read(filepath.Join("some-cache-dir", "metadata.yml")).Return(metadata.Metadata{
+				Version:  "v3",
+				Services: []provision.Service{service},
+			}, nil)
+			mockProvisioner.EXPECT().Ping().Return(nil)
+			mockProvisioner.EXPECT().GetWhiteListedService("some-service", []provision.Service{service}).Return(&service, nil)
+		})
+
+		It("prints the changes instead of deploying", func() {
+			mockProvisioner.EXPECT().DiffService(service).Return([]string{"  instance_groups:", "+ - name: redis"}, nil)
+			mockUI.EXPECT().Say("  instance_groups:")
+			mockUI.EXPECT().Say("+ - name: redis")
+
+			Expect(cmd.Execute(deploy_service.Args{Service: "some-service", DryRun: true})).To(Succeed())
+		})
+
+		It("says when nothing would change", func() {
+			mockProvisioner.EXPECT().DiffService(service).Return(nil, nil)
+			mockUI.EXPECT().Say("some-service: no changes to deploy")
+
+			Expect(cmd.Execute(deploy_service.Args{Service: "some-service", DryRun: true})).To(Succeed())
+		})
+	})
+
 	Describe("When service is not whitelisted", func() {
 		It("returns an error", func() {
 			service := provision.Service{
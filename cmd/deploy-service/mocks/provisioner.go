@@ -33,6 +33,18 @@ func (m *MockProvisioner) EXPECT() *MockProvisionerMockRecorder {
 	return m.recorder
 }
 
+// CancelDeploy mocks base method
+func (m *MockProvisioner) CancelDeploy() error {
+	ret := m.ctrl.Call(m, "CancelDeploy")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelDeploy indicates an expected call of CancelDeploy
+func (mr *MockProvisionerMockRecorder) CancelDeploy() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeploy", reflect.TypeOf((*MockProvisioner)(nil).CancelDeploy))
+}
+
 // DeployServices mocks base method
 func (m *MockProvisioner) DeployServices(arg0 provision.UI, arg1 []provision.Service) error {
 	ret := m.ctrl.Call(m, "DeployServices", arg0, arg1)
@@ -45,6 +57,19 @@ func (mr *MockProvisionerMockRecorder) DeployServices(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployServices", reflect.TypeOf((*MockProvisioner)(nil).DeployServices), arg0, arg1)
 }
 
+// DiffService mocks base method
+func (m *MockProvisioner) DiffService(arg0 provision.Service) ([]string, error) {
+	ret := m.ctrl.Call(m, "DiffService", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffService indicates an expected call of DiffService
+func (mr *MockProvisionerMockRecorder) DiffService(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffService", reflect.TypeOf((*MockProvisioner)(nil).DiffService), arg0)
+}
+
 // GetWhiteListedService mocks base method
 func (m *MockProvisioner) GetWhiteListedService(arg0 string, arg1 []provision.Service) (*provision.Service, error) {
 	ret := m.ctrl.Call(m, "GetWhiteListedService", arg0, arg1)
@@ -0,0 +1,296 @@
+package doctor
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/host"
+	"code.cloudfoundry.org/cfdev/hypervisor"
+	"code.cloudfoundry.org/cfdev/messages"
+	"code.cloudfoundry.org/cfdev/quirks"
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/doctor UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/host.go code.cloudfoundry.org/cfdev/cmd/doctor Host
+type Host interface {
+	CheckRequirements() error
+	MissingDefenderExclusions(paths []string) ([]string, error)
+	AddDefenderExclusions(paths []string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/quirks.go code.cloudfoundry.org/cfdev/cmd/doctor Quirks
+type Quirks interface {
+	Detect() []quirks.Quirk
+}
+
+//go:generate mockgen -package mocks -destination mocks/hypervisor.go code.cloudfoundry.org/cfdev/cmd/doctor Hypervisor
+type Hypervisor interface {
+	Preflight(vm hypervisor.VM) []hypervisor.Finding
+	IsRunning(vmName string) (bool, error)
+}
+
+//go:generate mockgen -package mocks -destination mocks/network.go code.cloudfoundry.org/cfdev/cmd/doctor Network
+type Network interface {
+	LookupHost(host string) ([]string, error)
+	Listening(address string) bool
+}
+
+type status int
+
+const (
+	passed status = iota
+	warned
+	failed
+)
+
+// A check looks at one thing that affects cf dev, says what it found and
+// how to fix it, and returns how it went.
+type check func() (status, error)
+
+type Doctor struct {
+	UI         UI
+	Host       Host
+	Quirks     Quirks
+	Hypervisor Hypervisor
+	Network    Network
+	Config     config.Config
+	Args       struct {
+		Fix bool
+	}
+}
+
+func (d *Doctor) Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check your workstation for problems that affect cf dev",
+		Long: "Check the host requirements, virtualization, free memory and disk, the ports forwarded to the vm, " +
+			"DNS for the CF domain, state left behind by a cf dev that was not stopped cleanly, antivirus " +
+			"exclusions and known host issues. Each check passes, warns or fails and says how to fix what " +
+			"it found. Exits non-zero when any check fails.",
+		RunE: d.RunE,
+	}
+
+	cmd.PersistentFlags().BoolVar(&d.Args.Fix, "fix", false, "Apply the suggested fixes")
+	return cmd
+}
+
+// checks are run in order. A new check only needs adding here.
+func (d *Doctor) checks() []check {
+	return []check{
+		d.checkRequirements,
+		d.checkPreflight,
+		d.checkPorts,
+		d.checkDNS,
+		d.checkStaleState,
+		d.checkDefenderExclusions,
+		d.checkQuirks,
+	}
+}
+
+func (d *Doctor) RunE(cmd *cobra.Command, args []string) error {
+	failures := 0
+	for _, check := range d.checks() {
+		result, err := check()
+		if err != nil {
+			// A check that could not finish fails, and the others still
+			// run.
+			d.UI.Say(messages.T("doctor.check-failed", map[string]interface{}{"Error": err.Error()}))
+			result = failed
+		}
+		if result == failed {
+			failures++
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d of the checks failed", failures)
+	}
+	return nil
+}
+
+func (d *Doctor) checkRequirements() (status, error) {
+	if err := d.Host.CheckRequirements(); err != nil {
+		d.UI.Say(messages.T("doctor.requirements-failed", map[string]interface{}{"Error": err.Error()}))
+		return failed, nil
+	}
+
+	d.UI.Say(messages.T("doctor.requirements-ok"))
+	return passed, nil
+}
+
+// checkPreflight runs the checks cf dev start runs before it creates the
+// vm, sized as the config asks for.
+func (d *Doctor) checkPreflight() (status, error) {
+	vm := hypervisor.NewVM(d.Config, d.Config.Cpus, d.Config.MemoryMB)
+
+	result := passed
+	for _, finding := range d.Hypervisor.Preflight(vm) {
+		switch {
+		case finding.Passed:
+		case finding.Fatal:
+			d.UI.Say(messages.T("doctor.preflight-failed", map[string]interface{}{"Message": finding.Message}))
+			result = failed
+		default:
+			d.UI.Say(messages.T("doctor.preflight-warning", map[string]interface{}{"Message": finding.Message}))
+			if result == passed {
+				result = warned
+			}
+		}
+	}
+
+	if result == passed {
+		d.UI.Say(messages.T("doctor.preflight-ok"))
+	}
+	return result, nil
+}
+
+// checkPorts looks for anything else listening on the ports forwarded to
+// the vm. While the vm runs, they are cf dev's own.
+func (d *Doctor) checkPorts() (status, error) {
+	running, err := d.Hypervisor.IsRunning(d.Config.VMName())
+	if err != nil {
+		return d.vmUnknown("ports", err), nil
+	}
+
+	var busy []string
+	if !running {
+		for _, forward := range hypervisor.Forwards(d.Config) {
+			address := net.JoinHostPort(forward.IP, strconv.Itoa(forward.Port))
+			if d.Network.Listening(address) {
+				busy = append(busy, address)
+			}
+		}
+	}
+
+	if len(busy) == 0 {
+		d.UI.Say(messages.T("doctor.ports-ok"))
+		return passed, nil
+	}
+
+	d.UI.Say(messages.T("doctor.ports-in-use", map[string]interface{}{"Addresses": strings.Join(busy, ", ")}))
+	return failed, nil
+}
+
+// checkDNS checks that the CF domain resolves to the router, as some
+// routers and DNS servers drop answers that point to private addresses.
+func (d *Doctor) checkDNS() (status, error) {
+	host := "api." + d.Config.CFDomain
+	addresses, err := d.Network.LookupHost(host)
+	if err != nil || len(addresses) == 0 {
+		d.UI.Say(messages.T("doctor.dns-failed", map[string]interface{}{"Host": host}))
+		return failed, nil
+	}
+
+	for _, address := range addresses {
+		if address == d.Config.CFRouterIP {
+			d.UI.Say(messages.T("doctor.dns-ok", map[string]interface{}{"Domain": d.Config.CFDomain}))
+			return passed, nil
+		}
+	}
+
+	d.UI.Say(messages.T("doctor.dns-mismatch", map[string]interface{}{
+		"Host":      host,
+		"Addresses": strings.Join(addresses, ", "),
+		"RouterIP":  d.Config.CFRouterIP,
+	}))
+	return warned, nil
+}
+
+// checkStaleState looks for the pid files of a vm that is no longer
+// running, which a crash or a reboot while cf dev ran leaves behind.
+func (d *Doctor) checkStaleState() (status, error) {
+	running, err := d.Hypervisor.IsRunning(d.Config.VMName())
+	if err != nil {
+		return d.vmUnknown("leftover state", err), nil
+	}
+
+	var stale []string
+	if !running {
+		for _, dir := range []string{d.Config.StateLinuxkit, d.Config.VpnKitStateDir} {
+			if dir == "" {
+				continue
+			}
+			matches, _ := filepath.Glob(filepath.Join(dir, "*.pid"))
+			stale = append(stale, matches...)
+		}
+	}
+
+	if len(stale) == 0 {
+		d.UI.Say(messages.T("doctor.stale-state-ok"))
+		return passed, nil
+	}
+
+	paths := strings.Join(stale, ", ")
+	if !d.Args.Fix {
+		d.UI.Say(messages.T("doctor.stale-state", map[string]interface{}{"Paths": paths}))
+		return warned, nil
+	}
+
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return failed, errors.SafeWrap(err, "removing leftover state")
+		}
+	}
+	d.UI.Say(messages.T("doctor.stale-state-fixed", map[string]interface{}{"Paths": paths}))
+	return passed, nil
+}
+
+func (d *Doctor) vmUnknown(check string, err error) status {
+	d.UI.Say(messages.T("doctor.vm-unknown", map[string]interface{}{"Check": check, "Error": err.Error()}))
+	return warned
+}
+
+func (d *Doctor) checkQuirks() (status, error) {
+	found := d.Quirks.Detect()
+	if len(found) == 0 {
+		d.UI.Say(messages.T("doctor.quirks-ok"))
+		return passed, nil
+	}
+
+	for _, quirk := range found {
+		d.UI.Say(messages.T("doctor.quirk", map[string]interface{}{"Guidance": quirk.Guidance}))
+	}
+	return warned, nil
+}
+
+func (d *Doctor) checkDefenderExclusions() (status, error) {
+	missing, err := d.Host.MissingDefenderExclusions([]string{
+		d.Config.CacheDir,
+		d.Config.StateDir,
+	})
+	if err == host.ErrDefenderExclusionsUnknown {
+		d.UI.Say(messages.T("doctor.defender-unknown", map[string]interface{}{"Error": err.Error()}))
+		return warned, nil
+	}
+	if err != nil {
+		return failed, errors.SafeWrap(err, "checking antivirus exclusions")
+	}
+
+	if len(missing) == 0 {
+		d.UI.Say(messages.T("doctor.defender-ok"))
+		return passed, nil
+	}
+
+	paths := strings.Join(missing, ", ")
+	if !d.Args.Fix {
+		d.UI.Say(messages.T("doctor.defender-missing", map[string]interface{}{"Paths": paths}))
+		return warned, nil
+	}
+
+	if err := d.Host.AddDefenderExclusions(missing); err != nil {
+		return failed, errors.SafeWrap(err, "adding antivirus exclusions")
+	}
+	d.UI.Say(messages.T("doctor.defender-fixed", map[string]interface{}{"Paths": paths}))
+	return passed, nil
+}
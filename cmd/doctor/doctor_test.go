@@ -0,0 +1,349 @@
+package doctor_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/cmd/doctor"
+	"code.cloudfoundry.org/cfdev/cmd/doctor/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/host"
+	"code.cloudfoundry.org/cfdev/hypervisor"
+	"code.cloudfoundry.org/cfdev/quirks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Doctor", func() {
+	var (
+		mockController *gomock.Controller
+		mockUI         *mocks.MockUI
+		mockHost       *mocks.MockHost
+		mockQuirks     *mocks.MockQuirks
+		mockHypervisor *mocks.MockHypervisor
+		mockNetwork    *mocks.MockNetwork
+		subject        *doctor.Doctor
+		stateDir       string
+		paths          = []string{"some-cache-dir", "some-state-dir"}
+		forwarded      = []string{
+			"127.0.0.1:9992", "127.0.0.1:9999",
+			"10.144.0.4:22", "10.144.0.4:6868", "10.144.0.4:8443", "10.144.0.4:8844", "10.144.0.4:25555",
+			"10.144.0.34:80", "10.144.0.34:443", "10.144.0.34:2222",
+		}
+	)
+
+	expectRequirements := func() {
+		mockHost.EXPECT().CheckRequirements()
+		mockUI.EXPECT().Say("[OK] Host requirements")
+	}
+	expectPreflight := func() {
+		mockHypervisor.EXPECT().Preflight(gomock.Any())
+		mockUI.EXPECT().Say("[OK] Virtualization, free memory and disk space")
+	}
+	expectStopped := func() {
+		mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil).Times(2)
+	}
+	expectPorts := func() {
+		for _, address := range forwarded {
+			mockNetwork.EXPECT().Listening(address)
+		}
+		mockUI.EXPECT().Say("[OK] Ports")
+	}
+	expectDNS := func() {
+		mockNetwork.EXPECT().LookupHost("api.dev.cfdev.sh").Return([]string{"10.144.0.34"}, nil)
+		mockUI.EXPECT().Say("[OK] DNS for dev.cfdev.sh")
+	}
+	expectNoStaleState := func() {
+		mockUI.EXPECT().Say("[OK] No state left behind")
+	}
+	expectDefender := func() {
+		mockHost.EXPECT().MissingDefenderExclusions(paths).Return(nil, nil)
+		mockUI.EXPECT().Say("[OK] Antivirus exclusions")
+	}
+	expectQuirks := func() {
+		mockQuirks.EXPECT().Detect()
+		mockUI.EXPECT().Say("[OK] Known host issues")
+	}
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockHost = mocks.NewMockHost(mockController)
+		mockQuirks = mocks.NewMockQuirks(mockController)
+		mockHypervisor = mocks.NewMockHypervisor(mockController)
+		mockNetwork = mocks.NewMockNetwork(mockController)
+
+		var err error
+		stateDir, err = ioutil.TempDir("", "cfdev-doctor-")
+		Expect(err).NotTo(HaveOccurred())
+
+		subject = &doctor.Doctor{
+			UI:         mockUI,
+			Host:       mockHost,
+			Quirks:     mockQuirks,
+			Hypervisor: mockHypervisor,
+			Network:    mockNetwork,
+			Config: config.Config{
+				CacheDir:       "some-cache-dir",
+				StateDir:       "some-state-dir",
+				StateLinuxkit:  stateDir,
+				CFDomain:       "dev.cfdev.sh",
+				BoshDirectorIP: "10.144.0.4",
+				CFRouterIP:     "10.144.0.34",
+				MemoryMB:       8192,
+				Cpus:           4,
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(stateDir)
+	})
+
+	Context("when every check passes", func() {
+		It("reports each of them as ok", func() {
+			expectRequirements()
+			expectPreflight()
+			expectStopped()
+			expectPorts()
+			expectDNS()
+			expectNoStaleState()
+			expectDefender()
+			expectQuirks()
+
+			Expect(subject.RunE(nil, nil)).To(Succeed())
+		})
+	})
+
+	Context("when the host does not meet the requirements", func() {
+		It("fails the check", func() {
+			mockHost.EXPECT().CheckRequirements().Return(errors.New("some-requirement"))
+			mockUI.EXPECT().Say("[FAIL] some-requirement")
+			expectPreflight()
+			expectStopped()
+			expectPorts()
+			expectDNS()
+			expectNoStaleState()
+			expectDefender()
+			expectQuirks()
+
+			Expect(subject.RunE(nil, nil)).To(MatchError("1 of the checks failed"))
+		})
+	})
+
+	Context("when preflight checks fail", func() {
+		It("warns about degrading findings and fails on fatal ones", func() {
+			expectRequirements()
+			mockHypervisor.EXPECT().Preflight(hypervisor.VM{Name: "cfdev", CPUs: 4, MemoryMB: 8192}).Return([]hypervisor.Finding{
+				{Check: "some-passed", Passed: true, Message: "some-passed-message"},
+				{Check: "some-degrading", Message: "some-degrading-message"},
+				{Check: "some-fatal", Fatal: true, Message: "some-fatal-message"},
+			})
+			mockUI.EXPECT().Say("[WARN] some-degrading-message")
+			mockUI.EXPECT().Say("[FAIL] some-fatal-message")
+			expectStopped()
+			expectPorts()
+			expectDNS()
+			expectNoStaleState()
+			expectDefender()
+			expectQuirks()
+
+			Expect(subject.RunE(nil, nil)).To(MatchError("1 of the checks failed"))
+		})
+	})
+
+	Context("when something else listens on the forwarded ports", func() {
+		It("fails the check while the vm is stopped", func() {
+			expectRequirements()
+			expectPreflight()
+			expectStopped()
+			for _, address := range forwarded {
+				mockNetwork.EXPECT().Listening(address).Return(address == "127.0.0.1:9992" || address == "10.144.0.34:443")
+			}
+			mockUI.EXPECT().Say("[FAIL] Something else listens on 127.0.0.1:9992, 10.144.0.34:443, which cf dev forwards to the vm. Stop whatever uses them before running 'cf dev start'")
+			expectDNS()
+			expectNoStaleState()
+			expectDefender()
+			expectQuirks()
+
+			Expect(subject.RunE(nil, nil)).To(MatchError("1 of the checks failed"))
+		})
+
+		It("does not look at the ports while the vm runs", func() {
+			expectRequirements()
+			expectPreflight()
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil).Times(2)
+			mockUI.EXPECT().Say("[OK] Ports")
+			expectDNS()
+			expectNoStaleState()
+			expectDefender()
+			expectQuirks()
+
+			Expect(subject.RunE(nil, nil)).To(Succeed())
+		})
+	})
+
+	Context("when the vm state cannot be read", func() {
+		It("warns that ports and leftover state were not checked", func() {
+			expectRequirements()
+			expectPreflight()
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, errors.New("some-error")).Times(2)
+			mockUI.EXPECT().Say("[WARN] Could not tell whether the vm is running, so ports went unchecked: some-error")
+			mockUI.EXPECT().Say("[WARN] Could not tell whether the vm is running, so leftover state went unchecked: some-error")
+			expectDNS()
+			expectDefender()
+			expectQuirks()
+
+			Expect(subject.RunE(nil, nil)).To(Succeed())
+		})
+	})
+
+	Context("when the CF domain does not resolve", func() {
+		It("fails the check", func() {
+			expectRequirements()
+			expectPreflight()
+			expectStopped()
+			expectPorts()
+			mockNetwork.EXPECT().LookupHost("api.dev.cfdev.sh").Return(nil, errors.New("no such host"))
+			mockUI.EXPECT().Say("[FAIL] api.dev.cfdev.sh does not resolve. Some routers and DNS servers drop answers that point to private addresses, so use another DNS server, e.g. 8.8.8.8")
+			expectNoStaleState()
+			expectDefender()
+			expectQuirks()
+
+			Expect(subject.RunE(nil, nil)).To(MatchError("1 of the checks failed"))
+		})
+	})
+
+	Context("when the CF domain resolves elsewhere", func() {
+		It("warns", func() {
+			expectRequirements()
+			expectPreflight()
+			expectStopped()
+			expectPorts()
+			mockNetwork.EXPECT().LookupHost("api.dev.cfdev.sh").Return([]string{"1.2.3.4"}, nil)
+			mockUI.EXPECT().Say("[WARN] api.dev.cfdev.sh resolves to 1.2.3.4 rather than the CF router at 10.144.0.34. Look for an entry for it in your hosts file, or a VPN whose DNS servers answer for it")
+			expectNoStaleState()
+			expectDefender()
+			expectQuirks()
+
+			Expect(subject.RunE(nil, nil)).To(Succeed())
+		})
+	})
+
+	Context("when a stopped vm left pid files behind", func() {
+		var pidFile string
+
+		BeforeEach(func() {
+			pidFile = filepath.Join(stateDir, "hyperkit.pid")
+			Expect(ioutil.WriteFile(pidFile, []byte("123"), 0600)).To(Succeed())
+
+			expectRequirements()
+			expectPreflight()
+			expectStopped()
+			expectPorts()
+			expectDNS()
+			expectDefender()
+			expectQuirks()
+		})
+
+		It("suggests the fix", func() {
+			mockUI.EXPECT().Say("[WARN] cf dev was not stopped cleanly and left " + pidFile + " behind. Run 'cf dev doctor --fix' to remove them")
+
+			Expect(subject.RunE(nil, nil)).To(Succeed())
+			Expect(pidFile).To(BeAnExistingFile())
+		})
+
+		It("removes them when --fix is passed", func() {
+			subject.Args.Fix = true
+			mockUI.EXPECT().Say("[FIXED] Removed " + pidFile)
+
+			Expect(subject.RunE(nil, nil)).To(Succeed())
+			Expect(pidFile).NotTo(BeAnExistingFile())
+		})
+	})
+
+	Context("when the cfdev directories are scanned", func() {
+		BeforeEach(func() {
+			expectRequirements()
+			expectPreflight()
+			expectStopped()
+			expectPorts()
+			expectDNS()
+			expectNoStaleState()
+			mockHost.EXPECT().MissingDefenderExclusions(paths).Return([]string{"some-state-dir"}, nil)
+		})
+
+		It("suggests the fix", func() {
+			mockUI.EXPECT().Say("[WARN] Windows Defender scans some-state-dir, which slows down starting and deploying. Run 'cf dev doctor --fix' to exclude them")
+			expectQuirks()
+
+			Expect(subject.RunE(nil, nil)).To(Succeed())
+		})
+
+		Context("when --fix is passed", func() {
+			BeforeEach(func() {
+				subject.Args.Fix = true
+			})
+
+			It("adds the exclusions", func() {
+				mockHost.EXPECT().AddDefenderExclusions([]string{"some-state-dir"})
+				mockUI.EXPECT().Say("[FIXED] Excluded some-state-dir from Windows Defender scanning")
+				expectQuirks()
+
+				Expect(subject.RunE(nil, nil)).To(Succeed())
+			})
+
+			It("fails the check and runs the others when the exclusions cannot be added", func() {
+				mockHost.EXPECT().AddDefenderExclusions(gomock.Any()).Return(errors.New("some-error"))
+				mockUI.EXPECT().Say("[FAIL] adding antivirus exclusions: some-error")
+				expectQuirks()
+
+				Expect(subject.RunE(nil, nil)).To(MatchError("1 of the checks failed"))
+			})
+		})
+	})
+
+	Context("when the antivirus exclusions cannot be seen", func() {
+		BeforeEach(func() {
+			expectRequirements()
+			expectPreflight()
+			expectStopped()
+			expectPorts()
+			expectDNS()
+			expectNoStaleState()
+			mockHost.EXPECT().MissingDefenderExclusions(paths).Return(nil, host.ErrDefenderExclusionsUnknown)
+			subject.Args.Fix = true
+		})
+
+		It("says they went unchecked rather than adding them", func() {
+			mockUI.EXPECT().Say("[WARN] Could not tell whether Windows Defender scans the cf dev directories, so antivirus exclusions went unchecked: Windows Defender only shows its exclusions to administrators. Run 'cf dev doctor' from an admin shell to check them")
+			expectQuirks()
+
+			Expect(subject.RunE(nil, nil)).To(Succeed())
+		})
+	})
+
+	Context("when the host has known quirks", func() {
+		It("prints guidance for each of them", func() {
+			expectRequirements()
+			expectPreflight()
+			expectStopped()
+			expectPorts()
+			expectDNS()
+			expectNoStaleState()
+			expectDefender()
+			mockQuirks.EXPECT().Detect().Return([]quirks.Quirk{
+				{Name: "some-quirk", Guidance: "some-guidance"},
+				{Name: "some-other-quirk", Guidance: "some-other-guidance"},
+			})
+			mockUI.EXPECT().Say("[WARN] some-guidance")
+			mockUI.EXPECT().Say("[WARN] some-other-guidance")
+
+			Expect(subject.RunE(nil, nil)).To(Succeed())
+		})
+	})
+})
@@ -10,6 +10,7 @@ import (
 
 	"code.cloudfoundry.org/cfdev/config"
 	"code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
 	"code.cloudfoundry.org/cfdev/resource"
 	"code.cloudfoundry.org/cfdev/resource/progress"
 	"github.com/spf13/cobra"
@@ -49,7 +50,7 @@ func (d *Download) RunE(cmd *cobra.Command, args []string) error {
 		return errors.SafeWrap(err, "setup for download")
 	}
 
-	d.UI.Say("Downloading Resources...")
+	d.UI.Say(messages.T("download.downloading-resources"))
 	return CacheSync(d.Config.Dependencies, d.Config.CacheDir, d.UI.Writer())
 }
 
@@ -0,0 +1,21 @@
+package cmd_test
+
+import (
+	"code.cloudfoundry.org/cfdev/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("flags read before the commands are built", func() {
+	It("finds the instance", func() {
+		Expect(cmd.InstanceFlag([]string{"dev", "start", "--instance", "other"})).To(Equal("other"))
+		Expect(cmd.InstanceFlag([]string{"dev", "start", "-p=other"})).To(Equal("other"))
+		Expect(cmd.InstanceFlag([]string{"dev", "start"})).To(BeEmpty())
+	})
+
+	It("finds the profile", func() {
+		Expect(cmd.ProfileFlag([]string{"dev", "start", "--profile", "minimal"})).To(Equal("minimal"))
+		Expect(cmd.ProfileFlag([]string{"dev", "start", "--profile=full", "-p", "other"})).To(Equal("full"))
+		Expect(cmd.ProfileFlag([]string{"dev", "start", "-p", "other"})).To(BeEmpty())
+	})
+})
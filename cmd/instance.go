@@ -0,0 +1,30 @@
+package cmd
+
+import "strings"
+
+const (
+	instanceFlag      = "instance"
+	instanceShorthand = "p"
+)
+
+// InstanceFlag finds the value of the --instance flag in args. It is needed
+// before the commands are built because the config each of them is given
+// depends on it.
+func InstanceFlag(args []string) string {
+	return flagValue(args, "--"+instanceFlag, "-"+instanceShorthand)
+}
+
+// flagValue finds the value of the flag called one of names in args.
+func flagValue(args []string, names ...string) string {
+	for i, arg := range args {
+		for _, name := range names {
+			if arg == name && i+1 < len(args) {
+				return args[i+1]
+			}
+			if strings.HasPrefix(arg, name+"=") {
+				return strings.TrimPrefix(arg, name+"=")
+			}
+		}
+	}
+	return ""
+}
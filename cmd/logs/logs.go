@@ -0,0 +1,236 @@
+package logs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+	"github.com/spf13/cobra"
+)
+
+// The components whose logs can be shown.
+const (
+	VM         = "vm"
+	VpnKit     = "vpnkit"
+	Bosh       = "bosh"
+	Analyticsd = "analyticsd"
+	Garden     = "garden"
+)
+
+// hostComponents keep their logs on the host, and are shown when no
+// component is asked for.
+var hostComponents = []string{VM, VpnKit, Bosh, Analyticsd}
+
+// gardenInstance runs garden, whose logs are only fetched when asked for
+// as they need cf to be deployed.
+const gardenInstance = "diego-cell"
+
+var gardenLogs = []string{
+	"/var/vcap/sys/log/garden/garden.stdout.log",
+	"/var/vcap/sys/log/garden/garden.stderr.log",
+}
+
+const defaultPollInterval = time.Second
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/logs UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/hypervisor.go code.cloudfoundry.org/cfdev/cmd/logs Hypervisor
+type Hypervisor interface {
+	ConsoleLogPath(vmName string) string
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/logs Provisioner
+type Provisioner interface {
+	Ping() error
+	SSH(instance string, cmd []string) error
+}
+
+// Logs shows the logs cf dev's components write, so that users need not
+// know where in the cfdev home each one is.
+type Logs struct {
+	UI          UI
+	Hypervisor  Hypervisor
+	Provisioner Provisioner
+	Config      config.Config
+	Exit        chan struct{}
+	// Out is where the logs are written. Without it, stdout.
+	Out io.Writer
+	// PollInterval is how often --follow looks for new lines. Without it,
+	// every second.
+	PollInterval time.Duration
+}
+
+type Args struct {
+	Components []string
+	Follow     bool
+	Lines      int
+}
+
+func (l *Logs) Cmd() *cobra.Command {
+	args := Args{}
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the logs of CF Dev's components",
+		Long: "Show the last lines each component logged, prefixed with its name. Components are " +
+			strings.Join(append(append([]string{}, hostComponents...), Garden), ", ") +
+			". All but garden, which is read from the diego cell, are shown by default.",
+		Example: `cf dev logs
+cf dev logs --component vpnkit --follow
+cf dev logs -c bosh -c garden -n 200`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := l.Execute(args); err != nil {
+				return e.SafeWrap(err, "cf dev logs")
+			}
+			return nil
+		},
+	}
+
+	pf := cmd.PersistentFlags()
+	pf.StringSliceVarP(&args.Components, "component", "c", nil, "components to show the logs of, all but garden by default")
+	pf.BoolVarP(&args.Follow, "follow", "f", false, "keep showing lines as they are logged")
+	pf.IntVarP(&args.Lines, "lines", "n", 50, "how many of the last lines of each log to show")
+	return cmd
+}
+
+func (l *Logs) Execute(args Args) error {
+	components := args.Components
+	if len(components) == 0 {
+		components = hostComponents
+	}
+
+	var (
+		sources []source
+		garden  bool
+	)
+	for _, component := range components {
+		if component == Garden {
+			garden = true
+			continue
+		}
+
+		paths, err := l.paths(component)
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			l.UI.Say(messages.T("logs.none", map[string]interface{}{"Component": component}))
+		}
+		for _, path := range paths {
+			sources = append(sources, source{component: component, path: path})
+		}
+	}
+
+	out := l.Out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	for i := range sources {
+		if err := sources[i].tail(out, args.Lines); err != nil {
+			return e.SafeWrap(err, "reading "+sources[i].path)
+		}
+	}
+
+	if garden {
+		if err := l.Provisioner.Ping(); err != nil {
+			return e.SafeWrap(err, "cf dev is not running")
+		}
+
+		// The garden logs stream over ssh, alongside the host's.
+		if args.Follow && len(sources) > 0 {
+			go l.gardenLogs(args)
+		} else if err := l.gardenLogs(args); err != nil {
+			return err
+		}
+	}
+
+	if args.Follow && len(sources) > 0 {
+		l.follow(out, sources)
+	}
+	return nil
+}
+
+// paths are the logs of component that exist.
+func (l *Logs) paths(component string) ([]string, error) {
+	var patterns []string
+	switch component {
+	case VM:
+		patterns = []string{
+			l.Hypervisor.ConsoleLogPath(l.Config.VMName()),
+			filepath.Join(l.Config.LogDir, "linuxkit.std*.log"),
+			filepath.Join(l.Config.LogDir, "qemu.std*.log"),
+		}
+	case VpnKit:
+		patterns = []string{filepath.Join(l.Config.LogDir, "vpnkit.std*.log")}
+	case Bosh:
+		patterns = []string{filepath.Join(l.Config.LogDir, "deploy-*.log")}
+	case Analyticsd:
+		// analyticsd logs to the home's log dir whatever the instance.
+		patterns = []string{
+			filepath.Join(l.Config.CFDevHome, "log", "analyticsd.log"),
+			filepath.Join(l.Config.LogDir, "analyticsd.std*.log"),
+		}
+	default:
+		return nil, fmt.Errorf("unknown component %q, use one of %s", component,
+			strings.Join(append(append([]string{}, hostComponents...), Garden), ", "))
+	}
+
+	var paths []string
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				paths = append(paths, match)
+			}
+		}
+	}
+	return paths, nil
+}
+
+func (l *Logs) gardenLogs(args Args) error {
+	cmd := []string{"sudo", "tail", "-n", strconv.Itoa(args.Lines)}
+	if args.Follow {
+		cmd = append(cmd, "-F")
+	}
+	cmd = append(cmd, gardenLogs...)
+
+	if err := l.Provisioner.SSH(gardenInstance, cmd); err != nil {
+		return e.SafeWrap(err, "reading the garden logs on "+gardenInstance)
+	}
+	return nil
+}
+
+// follow writes the lines logged from now on until cf dev logs is
+// interrupted.
+func (l *Logs) follow(out io.Writer, sources []source) {
+	interval := l.PollInterval
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+
+	for {
+		select {
+		case <-l.Exit:
+			return
+		case <-time.After(interval):
+			for i := range sources {
+				sources[i].poll(out)
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package logs_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/cmd/logs"
+	"code.cloudfoundry.org/cfdev/cmd/logs/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// syncBuffer is written by --follow while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+var _ = Describe("Logs", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockHypervisor  *mocks.MockHypervisor
+		mockProvisioner *mocks.MockProvisioner
+		cfdevHome       string
+		logDir          string
+		out             *syncBuffer
+		exit            chan struct{}
+		subject         *logs.Logs
+	)
+
+	writeLog := func(name, contents string) {
+		Expect(ioutil.WriteFile(filepath.Join(logDir, name), []byte(contents), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockHypervisor = mocks.NewMockHypervisor(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+
+		var err error
+		cfdevHome, err = ioutil.TempDir("", "cfdev-logs")
+		Expect(err).NotTo(HaveOccurred())
+		logDir = filepath.Join(cfdevHome, "log")
+		Expect(os.MkdirAll(logDir, 0755)).To(Succeed())
+
+		mockHypervisor.EXPECT().ConsoleLogPath("cfdev").Return(filepath.Join(logDir, "cfdev-console.log")).AnyTimes()
+
+		out = &syncBuffer{}
+		exit = make(chan struct{})
+		subject = &logs.Logs{
+			UI:           mockUI,
+			Hypervisor:   mockHypervisor,
+			Provisioner:  mockProvisioner,
+			Config:       config.Config{CFDevHome: cfdevHome, LogDir: logDir},
+			Exit:         exit,
+			Out:          out,
+			PollInterval: 10 * time.Millisecond,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(cfdevHome)
+	})
+
+	It("shows the last lines of every component on the host, prefixed with its name", func() {
+		writeLog("cfdev-console.log", "booting\nbooted\n")
+		writeLog("vpnkit.stdout.log", "one\ntwo\nthree\n")
+		writeLog("deploy-cf.log", "Task 42 done\n")
+		writeLog("analyticsd.log", "Starting\n")
+
+		Expect(subject.Execute(logs.Args{Lines: 2})).To(Succeed())
+		Expect(out.String()).To(Equal("[vm] booting\n[vm] booted\n[vpnkit] two\n[vpnkit] three\n[bosh] Task 42 done\n[analyticsd] Starting\n"))
+	})
+
+	It("shows only the components asked for", func() {
+		writeLog("cfdev-console.log", "booted\n")
+		writeLog("deploy-bosh.log", "bosh\n")
+		writeLog("deploy-mysql.log", "mysql\n")
+
+		Expect(subject.Execute(logs.Args{Components: []string{"bosh"}, Lines: 10})).To(Succeed())
+		Expect(out.String()).To(Equal("[bosh] bosh\n[bosh] mysql\n"))
+	})
+
+	It("says when a component has not logged anything yet", func() {
+		mockUI.EXPECT().Say("No vpnkit logs yet")
+
+		Expect(subject.Execute(logs.Args{Components: []string{"vpnkit"}, Lines: 10})).To(Succeed())
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("rejects components it does not know", func() {
+		err := subject.Execute(logs.Args{Components: []string{"uaa"}})
+		Expect(err).To(MatchError(`unknown component "uaa", use one of vm, vpnkit, bosh, analyticsd, garden`))
+	})
+
+	It("follows the logs until interrupted", func() {
+		writeLog("vpnkit.stdout.log", "before\n")
+
+		done := make(chan error)
+		go func() {
+			done <- subject.Execute(logs.Args{Components: []string{"vpnkit"}, Follow: true, Lines: 10})
+		}()
+		Eventually(out.String).Should(Equal("[vpnkit] before\n"))
+
+		file, err := os.OpenFile(filepath.Join(logDir, "vpnkit.stdout.log"), os.O_APPEND|os.O_WRONLY, 0644)
+		Expect(err).NotTo(HaveOccurred())
+		file.WriteString("after\npart")
+		Eventually(out.String).Should(Equal("[vpnkit] before\n[vpnkit] after\n"))
+		file.WriteString("ial\n")
+		file.Close()
+		Eventually(out.String).Should(Equal("[vpnkit] before\n[vpnkit] after\n[vpnkit] partial\n"))
+
+		writeLog("vpnkit.stdout.log", "rotated\n")
+		Eventually(out.String).Should(HaveSuffix("[vpnkit] rotated\n"))
+
+		close(exit)
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	Context("garden", func() {
+		It("tails its logs on the diego cell", func() {
+			mockProvisioner.EXPECT().Ping()
+			mockProvisioner.EXPECT().SSH("diego-cell", []string{"sudo", "tail", "-n", "20", "-F",
+				"/var/vcap/sys/log/garden/garden.stdout.log", "/var/vcap/sys/log/garden/garden.stderr.log"})
+
+			Expect(subject.Execute(logs.Args{Components: []string{"garden"}, Follow: true, Lines: 20})).To(Succeed())
+		})
+
+		It("needs cf dev to be running", func() {
+			mockProvisioner.EXPECT().Ping().Return(errors.New("some-error"))
+
+			err := subject.Execute(logs.Args{Components: []string{"garden"}})
+			Expect(err).To(MatchError(ContainSubstring("cf dev is not running")))
+		})
+	})
+})
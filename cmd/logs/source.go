@@ -0,0 +1,100 @@
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// maxTail bounds how much of the end of a log is read for its last lines,
+// as the vm's console log can be large.
+const maxTail = 256 * 1024
+
+// source is a log file of a component, read from where it was last left
+// off.
+type source struct {
+	component string
+	path      string
+	offset    int64
+	partial   []byte
+}
+
+// tail writes the last lines of the log and leaves off at its end.
+func (s *source) tail(out io.Writer, lines int) error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	start := info.Size() - maxTail
+	if start < 0 {
+		start = 0
+	}
+
+	contents := make([]byte, info.Size()-start)
+	if _, err := file.ReadAt(contents, start); err != nil && err != io.EOF {
+		return err
+	}
+	s.offset = info.Size()
+
+	all := bytes.Split(bytes.TrimRight(contents, "\n"), []byte("\n"))
+	if len(all) == 1 && len(all[0]) == 0 {
+		return nil
+	}
+	if lines >= 0 && len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+	for _, line := range all {
+		s.write(out, line)
+	}
+	return nil
+}
+
+// poll writes the lines logged since it left off. A log that shrank was
+// rotated or truncated, so is read again from its start.
+func (s *source) poll(out io.Writer) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return
+	}
+	if info.Size() < s.offset {
+		s.offset = 0
+		s.partial = nil
+	}
+	if info.Size() == s.offset {
+		return
+	}
+
+	contents := make([]byte, info.Size()-s.offset)
+	n, err := file.ReadAt(contents, s.offset)
+	if err != nil && err != io.EOF {
+		return
+	}
+	s.offset += int64(n)
+
+	s.partial = append(s.partial, contents[:n]...)
+	for {
+		i := bytes.IndexByte(s.partial, '\n')
+		if i < 0 {
+			return
+		}
+		s.write(out, s.partial[:i])
+		s.partial = s.partial[i+1:]
+	}
+}
+
+func (s *source) write(out io.Writer, line []byte) {
+	fmt.Fprintf(out, "[%s] %s\n", s.component, bytes.TrimRight(line, "\r"))
+}
@@ -0,0 +1,8 @@
+package cmd
+
+// ProfileFlag finds the value of cf dev start's --profile flag in args. As
+// with the instance, it is needed before the commands are built because
+// the profile sets the defaults of start's flags.
+func ProfileFlag(args []string) string {
+	return flagValue(args, "--profile")
+}
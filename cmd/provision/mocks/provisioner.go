@@ -5,9 +5,12 @@
 package mocks
 
 import (
+	bosh "code.cloudfoundry.org/cfdev/bosh"
 	provision "code.cloudfoundry.org/cfdev/provision"
 	gomock "github.com/golang/mock/gomock"
+	io "io"
 	reflect "reflect"
+	time "time"
 )
 
 // MockProvisioner is a mock of Provisioner interface
@@ -33,6 +36,31 @@ func (m *MockProvisioner) EXPECT() *MockProvisionerMockRecorder {
 	return m.recorder
 }
 
+// CFDigest mocks base method
+func (m *MockProvisioner) CFDigest(arg0 []string) (string, error) {
+	ret := m.ctrl.Call(m, "CFDigest", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CFDigest indicates an expected call of CFDigest
+func (mr *MockProvisionerMockRecorder) CFDigest(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CFDigest", reflect.TypeOf((*MockProvisioner)(nil).CFDigest), arg0)
+}
+
+// CancelDeploy mocks base method
+func (m *MockProvisioner) CancelDeploy() error {
+	ret := m.ctrl.Call(m, "CancelDeploy")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelDeploy indicates an expected call of CancelDeploy
+func (mr *MockProvisionerMockRecorder) CancelDeploy() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeploy", reflect.TypeOf((*MockProvisioner)(nil).CancelDeploy))
+}
+
 // DeployBosh mocks base method
 func (m *MockProvisioner) DeployBosh() error {
 	ret := m.ctrl.Call(m, "DeployBosh")
@@ -69,6 +97,52 @@ func (mr *MockProvisionerMockRecorder) DeployServices(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployServices", reflect.TypeOf((*MockProvisioner)(nil).DeployServices), arg0, arg1)
 }
 
+// DeployedDigests mocks base method
+func (m *MockProvisioner) DeployedDigests() (map[string]string, error) {
+	ret := m.ctrl.Call(m, "DeployedDigests")
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeployedDigests indicates an expected call of DeployedDigests
+func (mr *MockProvisionerMockRecorder) DeployedDigests() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployedDigests", reflect.TypeOf((*MockProvisioner)(nil).DeployedDigests))
+}
+
+// Deployments mocks base method
+func (m *MockProvisioner) Deployments() ([]string, error) {
+	ret := m.ctrl.Call(m, "Deployments")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deployments indicates an expected call of Deployments
+func (mr *MockProvisionerMockRecorder) Deployments() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deployments", reflect.TypeOf((*MockProvisioner)(nil).Deployments))
+}
+
+// EnableJSONProgress mocks base method
+func (m *MockProvisioner) EnableJSONProgress(arg0 io.Writer) {
+	m.ctrl.Call(m, "EnableJSONProgress", arg0)
+}
+
+// EnableJSONProgress indicates an expected call of EnableJSONProgress
+func (mr *MockProvisionerMockRecorder) EnableJSONProgress(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableJSONProgress", reflect.TypeOf((*MockProvisioner)(nil).EnableJSONProgress), arg0)
+}
+
+// EnableTaskLogs mocks base method
+func (m *MockProvisioner) EnableTaskLogs(arg0 io.Writer) {
+	m.ctrl.Call(m, "EnableTaskLogs", arg0)
+}
+
+// EnableTaskLogs indicates an expected call of EnableTaskLogs
+func (mr *MockProvisionerMockRecorder) EnableTaskLogs(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTaskLogs", reflect.TypeOf((*MockProvisioner)(nil).EnableTaskLogs), arg0)
+}
+
 // Ping mocks base method
 func (m *MockProvisioner) Ping() error {
 	ret := m.ctrl.Call(m, "Ping")
@@ -81,6 +155,68 @@ func (mr *MockProvisionerMockRecorder) Ping() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockProvisioner)(nil).Ping))
 }
 
+// RecordDeployed mocks base method
+func (m *MockProvisioner) RecordDeployed(arg0, arg1 string) error {
+	ret := m.ctrl.Call(m, "RecordDeployed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDeployed indicates an expected call of RecordDeployed
+func (mr *MockProvisionerMockRecorder) RecordDeployed(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDeployed", reflect.TypeOf((*MockProvisioner)(nil).RecordDeployed), arg0, arg1)
+}
+
+// SeedServiceInstances mocks base method
+func (m *MockProvisioner) SeedServiceInstances() error {
+	ret := m.ctrl.Call(m, "SeedServiceInstances")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SeedServiceInstances indicates an expected call of SeedServiceInstances
+func (mr *MockProvisionerMockRecorder) SeedServiceInstances() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SeedServiceInstances", reflect.TypeOf((*MockProvisioner)(nil).SeedServiceInstances))
+}
+
+// ServiceDigest mocks base method
+func (m *MockProvisioner) ServiceDigest(arg0 provision.Service) (string, error) {
+	ret := m.ctrl.Call(m, "ServiceDigest", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ServiceDigest indicates an expected call of ServiceDigest
+func (mr *MockProvisionerMockRecorder) ServiceDigest(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServiceDigest", reflect.TypeOf((*MockProvisioner)(nil).ServiceDigest), arg0)
+}
+
+// StartDiskMonitor mocks base method
+func (m *MockProvisioner) StartDiskMonitor() error {
+	ret := m.ctrl.Call(m, "StartDiskMonitor")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartDiskMonitor indicates an expected call of StartDiskMonitor
+func (mr *MockProvisionerMockRecorder) StartDiskMonitor() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartDiskMonitor", reflect.TypeOf((*MockProvisioner)(nil).StartDiskMonitor))
+}
+
+// Timings mocks base method
+func (m *MockProvisioner) Timings(arg0 time.Time) ([]bosh.Timing, error) {
+	ret := m.ctrl.Call(m, "Timings", arg0)
+	ret0, _ := ret[0].([]bosh.Timing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Timings indicates an expected call of Timings
+func (mr *MockProvisionerMockRecorder) Timings(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Timings", reflect.TypeOf((*MockProvisioner)(nil).Timings), arg0)
+}
+
 // WhiteListServices mocks base method
 func (m *MockProvisioner) WhiteListServices(arg0 string, arg1 []provision.Service) ([]provision.Service, error) {
 	ret := m.ctrl.Call(m, "WhiteListServices", arg0, arg1)
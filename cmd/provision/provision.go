@@ -1,9 +1,11 @@
 package provision
 
 import (
+	"code.cloudfoundry.org/cfdev/bosh"
 	"code.cloudfoundry.org/cfdev/cmd/start"
 	"code.cloudfoundry.org/cfdev/config"
 	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
 	"code.cloudfoundry.org/cfdev/metadata"
 	"code.cloudfoundry.org/cfdev/provision"
 	"fmt"
@@ -14,6 +16,7 @@ import (
 	"path/filepath"
 	"strings"
 	"text/template"
+	"time"
 )
 
 //go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/provision UI
@@ -31,9 +34,20 @@ type MetaDataReader interface {
 type Provisioner interface {
 	Ping() error
 	DeployBosh() error
+	StartDiskMonitor() error
 	DeployCloudFoundry(provision.UI, []string) error
 	WhiteListServices(string, []provision.Service) ([]provision.Service, error)
 	DeployServices(provision.UI, []provision.Service) error
+	SeedServiceInstances() error
+	DeployedDigests() (map[string]string, error)
+	Deployments() ([]string, error)
+	CFDigest([]string) (string, error)
+	ServiceDigest(provision.Service) (string, error)
+	RecordDeployed(deployment string, digest string) error
+	EnableTaskLogs(io.Writer)
+	EnableJSONProgress(io.Writer)
+	CancelDeploy() error
+	Timings(since time.Time) ([]bosh.Timing, error)
 }
 
 const compatibilityVersion = "v3"
@@ -58,12 +72,22 @@ func (c *Provision) Cmd() *cobra.Command {
 func (c *Provision) RunE(cmd *cobra.Command, args []string) error {
 	go func() {
 		<-c.Exit
+		c.Cancel()
 		os.Exit(128)
 	}()
 
 	return c.Execute(start.Args{})
 }
 
+// Cancel stops the deploy in progress on the director, if any, for when
+// the user interrupts provisioning.
+func (c *Provision) Cancel() {
+	c.UI.Say(messages.T("provision.cancelling"))
+	if err := c.Provisioner.CancelDeploy(); err != nil {
+		c.UI.Say(messages.T("provision.cancel-failed", map[string]interface{}{"Error": err}))
+	}
+}
+
 func (c *Provision) Execute(args start.Args) error {
 	metadataConfig, err := c.MetaDataReader.Read(filepath.Join(c.Config.CacheDir, "metadata.yml"))
 	if err != nil {
@@ -79,23 +103,59 @@ func (c *Provision) Execute(args start.Args) error {
 		return e.SafeWrap(err, "Unable to parse docker registries")
 	}
 
+	if args.Debug {
+		c.Provisioner.EnableTaskLogs(c.UI.Writer())
+	}
+	if args.JSON {
+		c.Provisioner.EnableJSONProgress(c.UI.Writer())
+	}
+
 	return c.provision(metadataConfig, registries, args.DeploySingleService)
 }
 
 func (c *Provision) provision(metadataConfig metadata.Metadata, registries []string, deploySingleService string) error {
+	started := time.Now()
+
 	err := c.Provisioner.Ping()
 	if err != nil {
 		return e.SafeWrap(err, "VM is not running. Please execute 'cf dev start'")
 	}
 
-	c.UI.Say("Deploying the BOSH Director...")
+	c.UI.Say(messages.T("provision.deploying-bosh"))
 	if err := c.Provisioner.DeployBosh(); err != nil {
 		return e.SafeWrap(err, "Failed to deploy the BOSH Director")
 	}
+	if err := c.Provisioner.StartDiskMonitor(); err != nil {
+		c.UI.Say(messages.T("provision.disk-monitor-failed", map[string]interface{}{"Error": err}))
+	}
 
-	c.UI.Say("Deploying CF...")
-	if err := c.Provisioner.DeployCloudFoundry(c.UI, registries); err != nil {
-		return e.SafeWrap(err, "Failed to deploy the Cloud Foundry")
+	// The vm only has deployments already when its state was kept, e.g.
+	// after an import. Only those whose assets changed are deployed again.
+	// The digests outlive the vm, so those of deployments the director does
+	// not have are left out.
+	deployed, err := c.Provisioner.DeployedDigests()
+	if err != nil {
+		return e.SafeWrap(err, "Failed to read what is deployed")
+	}
+	deployments, err := c.Provisioner.Deployments()
+	if err != nil {
+		return e.SafeWrap(err, "Failed to list the deployments")
+	}
+	deployed = onDirector(deployed, deployments)
+	upgrade := len(deployed) > 0
+
+	cfDigest, err := c.Provisioner.CFDigest(registries)
+	if err != nil {
+		return e.SafeWrap(err, "Failed to compare CF assets")
+	}
+	if c.changed(upgrade, deployed, "cf", cfDigest) {
+		c.UI.Say(messages.T("provision.deploying-cf"))
+		if err := c.Provisioner.DeployCloudFoundry(c.UI, registries); err != nil {
+			return e.SafeWrap(err, "Failed to deploy the Cloud Foundry")
+		}
+		if err := c.Provisioner.RecordDeployed("cf", cfDigest); err != nil {
+			return e.SafeWrap(err, "Failed to record the CF deployment")
+		}
 	}
 
 	services, err := c.Provisioner.WhiteListServices(deploySingleService, metadataConfig.Services)
@@ -103,9 +163,36 @@ func (c *Provision) provision(metadataConfig metadata.Metadata, registries []str
 		return e.SafeWrap(err, "Failed to whitelist services")
 	}
 
-	if err := c.Provisioner.DeployServices(c.UI, services); err != nil {
+	changedServices := []provision.Service{}
+	digests := map[string]string{}
+	for _, service := range services {
+		digest, err := c.Provisioner.ServiceDigest(service)
+		if err != nil {
+			return e.SafeWrap(err, "Failed to compare "+service.Name+" assets")
+		}
+		if c.changed(upgrade, deployed, service.Deployment, digest) {
+			changedServices = append(changedServices, service)
+			digests[service.Deployment] = digest
+		}
+	}
+
+	if err := c.Provisioner.DeployServices(c.UI, changedServices); err != nil {
 		return e.SafeWrap(err, "Failed to deploy services")
 	}
+	for _, service := range changedServices {
+		if err := c.Provisioner.RecordDeployed(service.Deployment, digests[service.Deployment]); err != nil {
+			return e.SafeWrap(err, "Failed to record the "+service.Name+" deployment")
+		}
+	}
+
+	if len(c.Config.Seed.Instances) > 0 {
+		c.UI.Say(messages.T("provision.seeding"))
+		if err := c.Provisioner.SeedServiceInstances(); err != nil {
+			c.UI.Say(messages.T("provision.seeding-failed", map[string]interface{}{"Error": err}))
+		}
+	}
+
+	c.reportTimings(started)
 
 	if metadataConfig.Message != "" {
 		t := template.Must(template.New("message").Parse(metadataConfig.Message))
@@ -118,6 +205,56 @@ func (c *Provision) provision(metadataConfig metadata.Metadata, registries []str
 	return nil
 }
 
+// reportTimings lists how long each phase of the deploys took, so that
+// slow starts can be told apart from slow compiles. It is only a report,
+// so timings that cannot be read are left out.
+func (c *Provision) reportTimings(since time.Time) {
+	timings, err := c.Provisioner.Timings(since)
+	if err != nil || len(timings) == 0 {
+		return
+	}
+
+	c.UI.Say(messages.T("provision.timings"))
+	for _, timing := range timings {
+		c.UI.Say(messages.T("provision.timing", map[string]interface{}{
+			"Deployment": timing.Deployment,
+			"Phase":      strings.Replace(timing.Phase, "-", " ", -1),
+			"Duration":   timing.Duration.Round(time.Second),
+		}))
+	}
+}
+
+// onDirector keeps the digests of the deployments the director has.
+func onDirector(digests map[string]string, deployments []string) map[string]string {
+	kept := map[string]string{}
+	for _, deployment := range deployments {
+		if digest, ok := digests[deployment]; ok {
+			kept[deployment] = digest
+		}
+	}
+	return kept
+}
+
+// changed reports whether a deployment needs deploying, summarizing why
+// when the vm already has deployments.
+func (c *Provision) changed(upgrade bool, deployed map[string]string, deployment string, digest string) bool {
+	if !upgrade {
+		return true
+	}
+
+	previous, ok := deployed[deployment]
+	switch {
+	case !ok:
+		c.UI.Say(messages.T("provision.deployment-new", map[string]interface{}{"Deployment": deployment}))
+	case previous != digest:
+		c.UI.Say(messages.T("provision.deployment-changed", map[string]interface{}{"Deployment": deployment}))
+	default:
+		c.UI.Say(messages.T("provision.deployment-unchanged", map[string]interface{}{"Deployment": deployment}))
+		return false
+	}
+	return true
+}
+
 func (c *Provision) parseDockerRegistriesFlag(flag string) ([]string, error) {
 	if flag == "" {
 		return nil, nil
@@ -1,6 +1,10 @@
 package provision_test
 
 import (
+	"bytes"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
 	"code.cloudfoundry.org/cfdev/cmd/provision"
 	"code.cloudfoundry.org/cfdev/cmd/provision/mocks"
 	"code.cloudfoundry.org/cfdev/cmd/start"
@@ -52,10 +56,16 @@ var _ = Describe("Provision", func() {
 				mockProvisioner.EXPECT().Ping(),
 				mockUI.EXPECT().Say("Deploying the BOSH Director..."),
 				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().StartDiskMonitor(),
+				mockProvisioner.EXPECT().DeployedDigests().Return(map[string]string{}, nil),
+				mockProvisioner.EXPECT().Deployments().Return([]string{}, nil),
+				mockProvisioner.EXPECT().CFDigest(nil).Return("cf-digest", nil),
 				mockUI.EXPECT().Say("Deploying CF..."),
 				mockProvisioner.EXPECT().DeployCloudFoundry(mockUI, nil),
+				mockProvisioner.EXPECT().RecordDeployed("cf", "cf-digest"),
 				mockProvisioner.EXPECT().WhiteListServices("", nil).Return([]prvsion.Service{}, nil),
 				mockProvisioner.EXPECT().DeployServices(mockUI, []prvsion.Service{}),
+				mockProvisioner.EXPECT().Timings(gomock.Any()),
 			)
 
 			err := cmd.Execute(start.Args{})
@@ -63,6 +73,158 @@ var _ = Describe("Provision", func() {
 		})
 	})
 
+	Describe("when the disk monitor cannot be started", func() {
+		It("warns and deploys anyway", func() {
+			gomock.InOrder(
+				mockMetadataReader.EXPECT().Read(gomock.Any()).Return(metadata.Metadata{Version: "v3"}, nil),
+				mockProvisioner.EXPECT().Ping(),
+				mockUI.EXPECT().Say("Deploying the BOSH Director..."),
+				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().StartDiskMonitor().Return(errors.New("some-error")),
+				mockUI.EXPECT().Say("[WARN] Unable to watch the disk, unused BOSH packages will not be cleaned up as it fills: some-error"),
+				mockProvisioner.EXPECT().DeployedDigests().Return(map[string]string{}, nil),
+				mockProvisioner.EXPECT().Deployments().Return([]string{}, nil),
+				mockProvisioner.EXPECT().CFDigest(nil).Return("cf-digest", nil),
+				mockUI.EXPECT().Say("Deploying CF..."),
+				mockProvisioner.EXPECT().DeployCloudFoundry(mockUI, nil),
+				mockProvisioner.EXPECT().RecordDeployed("cf", "cf-digest"),
+				mockProvisioner.EXPECT().WhiteListServices("", nil).Return([]prvsion.Service{}, nil),
+				mockProvisioner.EXPECT().DeployServices(mockUI, []prvsion.Service{}),
+				mockProvisioner.EXPECT().Timings(gomock.Any()),
+			)
+
+			Expect(cmd.Execute(start.Args{})).To(Succeed())
+		})
+	})
+
+	Describe("when the vm already has deployments", func() {
+		var mysql, redis prvsion.Service
+
+		BeforeEach(func() {
+			mysql = prvsion.Service{Name: "Mysql", Deployment: "cf-mysql"}
+			redis = prvsion.Service{Name: "Redis", Deployment: "cf-redis"}
+		})
+
+		It("only deploys those whose assets changed", func() {
+			gomock.InOrder(
+				mockMetadataReader.EXPECT().Read(gomock.Any()).Return(metadata.Metadata{Version: "v3"}, nil),
+				mockProvisioner.EXPECT().Ping(),
+				mockUI.EXPECT().Say("Deploying the BOSH Director..."),
+				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().StartDiskMonitor(),
+				mockProvisioner.EXPECT().DeployedDigests().Return(map[string]string{
+					"cf":       "cf-digest",
+					"cf-mysql": "old-mysql-digest",
+				}, nil),
+				mockProvisioner.EXPECT().Deployments().Return([]string{"cf", "cf-mysql"}, nil),
+				mockProvisioner.EXPECT().CFDigest(nil).Return("cf-digest", nil),
+				mockUI.EXPECT().Say("cf: unchanged, skipping"),
+				mockProvisioner.EXPECT().WhiteListServices("", nil).Return([]prvsion.Service{mysql, redis}, nil),
+				mockProvisioner.EXPECT().ServiceDigest(mysql).Return("new-mysql-digest", nil),
+				mockUI.EXPECT().Say("cf-mysql: assets changed, redeploying"),
+				mockProvisioner.EXPECT().ServiceDigest(redis).Return("redis-digest", nil),
+				mockUI.EXPECT().Say("cf-redis: new, deploying"),
+				mockProvisioner.EXPECT().DeployServices(mockUI, []prvsion.Service{mysql, redis}),
+				mockProvisioner.EXPECT().RecordDeployed("cf-mysql", "new-mysql-digest"),
+				mockProvisioner.EXPECT().RecordDeployed("cf-redis", "redis-digest"),
+				mockProvisioner.EXPECT().Timings(gomock.Any()),
+			)
+
+			Expect(cmd.Execute(start.Args{})).To(Succeed())
+		})
+	})
+
+	Describe("when the director has none of the deployments recorded", func() {
+		It("deploys everything, as the vm is fresh", func() {
+			gomock.InOrder(
+				mockMetadataReader.EXPECT().Read(gomock.Any()).Return(metadata.Metadata{Version: "v3"}, nil),
+				mockProvisioner.EXPECT().Ping(),
+				mockUI.EXPECT().Say("Deploying the BOSH Director..."),
+				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().StartDiskMonitor(),
+				mockProvisioner.EXPECT().DeployedDigests().Return(map[string]string{"cf": "cf-digest"}, nil),
+				mockProvisioner.EXPECT().Deployments().Return([]string{}, nil),
+				mockProvisioner.EXPECT().CFDigest(nil).Return("cf-digest", nil),
+				mockUI.EXPECT().Say("Deploying CF..."),
+				mockProvisioner.EXPECT().DeployCloudFoundry(mockUI, nil),
+				mockProvisioner.EXPECT().RecordDeployed("cf", "cf-digest"),
+				mockProvisioner.EXPECT().WhiteListServices("", nil).Return([]prvsion.Service{}, nil),
+				mockProvisioner.EXPECT().DeployServices(mockUI, []prvsion.Service{}),
+				mockProvisioner.EXPECT().Timings(gomock.Any()),
+			)
+
+			Expect(cmd.Execute(start.Args{})).To(Succeed())
+		})
+	})
+
+	Describe("when service instances are configured", func() {
+		BeforeEach(func() {
+			cmd.Config.Seed.Instances = []config.ServiceInstanceConfig{
+				{Service: "p-mysql", Plan: "10mb", Name: "some-db"},
+			}
+			mockMetadataReader.EXPECT().Read(gomock.Any()).Return(metadata.Metadata{Version: "v3"}, nil)
+			mockProvisioner.EXPECT().Ping()
+			mockUI.EXPECT().Say("Deploying the BOSH Director...")
+			mockProvisioner.EXPECT().DeployBosh()
+			mockProvisioner.EXPECT().StartDiskMonitor()
+			mockProvisioner.EXPECT().DeployedDigests().Return(map[string]string{}, nil)
+			mockProvisioner.EXPECT().Deployments().Return([]string{}, nil)
+			mockProvisioner.EXPECT().CFDigest(nil).Return("cf-digest", nil)
+			mockUI.EXPECT().Say("Deploying CF...")
+			mockProvisioner.EXPECT().DeployCloudFoundry(mockUI, nil)
+			mockProvisioner.EXPECT().RecordDeployed("cf", "cf-digest")
+			mockProvisioner.EXPECT().WhiteListServices("", nil).Return([]prvsion.Service{}, nil)
+			mockProvisioner.EXPECT().DeployServices(mockUI, []prvsion.Service{})
+			mockUI.EXPECT().Say("Creating service instances...")
+			mockProvisioner.EXPECT().Timings(gomock.Any())
+		})
+
+		It("creates them after deploying services", func() {
+			mockProvisioner.EXPECT().SeedServiceInstances()
+
+			Expect(cmd.Execute(start.Args{})).To(Succeed())
+		})
+
+		It("only warns when they cannot be created", func() {
+			mockProvisioner.EXPECT().SeedServiceInstances().Return(errors.New("some-error"))
+			mockUI.EXPECT().Say("[WARN] Unable to create every service instance: some-error")
+
+			Expect(cmd.Execute(start.Args{})).To(Succeed())
+		})
+	})
+
+	Describe("when the deploys recorded timings", func() {
+		It("reports how long each phase took", func() {
+			before := time.Now()
+			gomock.InOrder(
+				mockMetadataReader.EXPECT().Read(gomock.Any()).Return(metadata.Metadata{Version: "v3"}, nil),
+				mockProvisioner.EXPECT().Ping(),
+				mockUI.EXPECT().Say("Deploying the BOSH Director..."),
+				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().StartDiskMonitor(),
+				mockProvisioner.EXPECT().DeployedDigests().Return(map[string]string{}, nil),
+				mockProvisioner.EXPECT().Deployments().Return([]string{}, nil),
+				mockProvisioner.EXPECT().CFDigest(nil).Return("cf-digest", nil),
+				mockUI.EXPECT().Say("Deploying CF..."),
+				mockProvisioner.EXPECT().DeployCloudFoundry(mockUI, nil),
+				mockProvisioner.EXPECT().RecordDeployed("cf", "cf-digest"),
+				mockProvisioner.EXPECT().WhiteListServices("", nil).Return([]prvsion.Service{}, nil),
+				mockProvisioner.EXPECT().DeployServices(mockUI, []prvsion.Service{}),
+				mockProvisioner.EXPECT().Timings(gomock.Any()).Do(func(since time.Time) {
+					Expect(since).To(BeTemporally(">=", before))
+				}).Return([]bosh.Timing{
+					{Deployment: "cf", Phase: bosh.UploadingReleases, Duration: 90 * time.Second},
+					{Deployment: "cf", Phase: bosh.Deploying, Duration: 20*time.Minute + 400*time.Millisecond},
+				}, nil),
+				mockUI.EXPECT().Say("Deploy timings:"),
+				mockUI.EXPECT().Say("  cf uploading releases: 1m30s"),
+				mockUI.EXPECT().Say("  cf deploying: 20m0s"),
+			)
+
+			Expect(cmd.Execute(start.Args{})).To(Succeed())
+		})
+	})
+
 	Describe("when version is not compatible", func() {
 		It("return an error", func() {
 			gomock.InOrder(
@@ -85,10 +247,16 @@ var _ = Describe("Provision", func() {
 				mockProvisioner.EXPECT().Ping(),
 				mockUI.EXPECT().Say("Deploying the BOSH Director..."),
 				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().StartDiskMonitor(),
+				mockProvisioner.EXPECT().DeployedDigests().Return(map[string]string{}, nil),
+				mockProvisioner.EXPECT().Deployments().Return([]string{}, nil),
+				mockProvisioner.EXPECT().CFDigest([]string{"domain1.com", "domain2.com"}).Return("cf-digest", nil),
 				mockUI.EXPECT().Say("Deploying CF..."),
 				mockProvisioner.EXPECT().DeployCloudFoundry(mockUI, []string{"domain1.com", "domain2.com"}),
+				mockProvisioner.EXPECT().RecordDeployed("cf", "cf-digest"),
 				mockProvisioner.EXPECT().WhiteListServices("", nil).Return([]prvsion.Service{}, nil),
 				mockProvisioner.EXPECT().DeployServices(mockUI, []prvsion.Service{}),
+				mockProvisioner.EXPECT().Timings(gomock.Any()),
 			)
 
 			err := cmd.Execute(start.Args{
@@ -98,6 +266,53 @@ var _ = Describe("Provision", func() {
 		})
 	})
 
+	Describe("when debugging", func() {
+		It("streams the director's task logs to the ui", func() {
+			writer := &bytes.Buffer{}
+			gomock.InOrder(
+				mockMetadataReader.EXPECT().Read(gomock.Any()).Return(metadata.Metadata{Version: "v3"}, nil),
+				mockUI.EXPECT().Writer().Return(writer),
+				mockProvisioner.EXPECT().EnableTaskLogs(writer),
+				mockProvisioner.EXPECT().Ping().Return(errors.New("not running")),
+			)
+
+			Expect(cmd.Execute(start.Args{Debug: true})).NotTo(Succeed())
+		})
+	})
+
+	Describe("Cancel", func() {
+		It("cancels the deploy in progress", func() {
+			gomock.InOrder(
+				mockUI.EXPECT().Say("Cancelling the deploy..."),
+				mockProvisioner.EXPECT().CancelDeploy(),
+			)
+
+			cmd.Cancel()
+		})
+
+		It("warns when the deploy cannot be cancelled", func() {
+			mockUI.EXPECT().Say("Cancelling the deploy...")
+			mockProvisioner.EXPECT().CancelDeploy().Return(errors.New("some-error"))
+			mockUI.EXPECT().Say("[WARN] Unable to cancel the deploy, the next start may have to wait for it to finish: some-error")
+
+			cmd.Cancel()
+		})
+	})
+
+	Describe("when --json is given", func() {
+		It("reports progress as json", func() {
+			writer := &bytes.Buffer{}
+			gomock.InOrder(
+				mockMetadataReader.EXPECT().Read(gomock.Any()).Return(metadata.Metadata{Version: "v3"}, nil),
+				mockUI.EXPECT().Writer().Return(writer),
+				mockProvisioner.EXPECT().EnableJSONProgress(writer),
+				mockProvisioner.EXPECT().Ping().Return(errors.New("not running")),
+			)
+
+			Expect(cmd.Execute(start.Args{JSON: true})).NotTo(Succeed())
+		})
+	})
+
 	Describe("when the vm is not running", func() {
 		It("return an error", func() {
 			gomock.InOrder(
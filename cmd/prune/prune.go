@@ -0,0 +1,45 @@
+package prune
+
+import (
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/prune UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/prune Provisioner
+type Provisioner interface {
+	Ping() error
+	Prune() error
+}
+
+type Prune struct {
+	UI          UI
+	Provisioner Provisioner
+}
+
+func (p *Prune) Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune",
+		Short: "Remove the releases, stemcells and orphaned disks and VMs that no deployment uses",
+		RunE:  p.RunE,
+	}
+}
+
+func (p *Prune) RunE(cmd *cobra.Command, args []string) error {
+	if err := p.Provisioner.Ping(); err != nil {
+		return e.SafeWrap(err, "cf dev is not running. Please execute 'cf dev start'")
+	}
+
+	p.UI.Say(messages.T("prune.pruning"))
+	if err := p.Provisioner.Prune(); err != nil {
+		return e.SafeWrap(err, "cf dev prune")
+	}
+
+	p.UI.Say(messages.T("prune.done"))
+	return nil
+}
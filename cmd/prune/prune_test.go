@@ -0,0 +1,64 @@
+package prune_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cfdev/cmd/prune"
+	"code.cloudfoundry.org/cfdev/cmd/prune/mocks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Prune", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockProvisioner *mocks.MockProvisioner
+		subject         *prune.Prune
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+
+		subject = &prune.Prune{
+			UI:          mockUI,
+			Provisioner: mockProvisioner,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("prunes the vm", func() {
+		gomock.InOrder(
+			mockProvisioner.EXPECT().Ping(),
+			mockUI.EXPECT().Say("Removing the releases, stemcells and orphaned disks and VMs that no deployment uses..."),
+			mockProvisioner.EXPECT().Prune(),
+			mockUI.EXPECT().Say("Done"),
+		)
+
+		Expect(subject.RunE(nil, nil)).To(Succeed())
+	})
+
+	Context("when the vm is not running", func() {
+		It("returns an error", func() {
+			mockProvisioner.EXPECT().Ping().Return(errors.New("some-error"))
+
+			Expect(subject.RunE(nil, nil)).To(MatchError("cf dev is not running. Please execute 'cf dev start': some-error"))
+		})
+	})
+
+	Context("when pruning fails", func() {
+		It("returns an error", func() {
+			mockProvisioner.EXPECT().Ping()
+			mockUI.EXPECT().Say(gomock.Any())
+			mockProvisioner.EXPECT().Prune().Return(errors.New("some-error"))
+
+			Expect(subject.RunE(nil, nil)).To(MatchError("cf dev prune: some-error"))
+		})
+	})
+})
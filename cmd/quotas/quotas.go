@@ -0,0 +1,52 @@
+package quotas
+
+import (
+	"code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/quotas UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/quotas Provisioner
+type Provisioner interface {
+	Ping() error
+	RelaxQuotas() error
+}
+
+type Quotas struct {
+	UI          UI
+	Provisioner Provisioner
+}
+
+func (q *Quotas) Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "quotas",
+		Short: "Manage the quota applied to orgs in the local CF",
+	}
+	relaxCmd := &cobra.Command{
+		Use:   "relax",
+		Short: "Lift the memory and app instance limits of the default quota",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return q.Relax()
+		},
+	}
+	cmd.AddCommand(relaxCmd)
+	return cmd
+}
+
+func (q *Quotas) Relax() error {
+	if err := q.Provisioner.Ping(); err != nil {
+		return errors.SafeWrap(err, "cf dev is not running. Please execute 'cf dev start'")
+	}
+
+	if err := q.Provisioner.RelaxQuotas(); err != nil {
+		return errors.SafeWrap(err, "relaxing quotas")
+	}
+
+	q.UI.Say(messages.T("quotas.relaxed"))
+	return nil
+}
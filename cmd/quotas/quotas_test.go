@@ -0,0 +1,64 @@
+package quotas_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cfdev/cmd/quotas"
+	"code.cloudfoundry.org/cfdev/cmd/quotas/mocks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Quotas", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockProvisioner *mocks.MockProvisioner
+		subject         *quotas.Quotas
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+
+		subject = &quotas.Quotas{
+			UI:          mockUI,
+			Provisioner: mockProvisioner,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	Describe("Relax", func() {
+		It("relaxes the default quota", func() {
+			gomock.InOrder(
+				mockProvisioner.EXPECT().Ping(),
+				mockProvisioner.EXPECT().RelaxQuotas(),
+				mockUI.EXPECT().Say("The default quota no longer limits memory or app instances"),
+			)
+
+			Expect(subject.Relax()).To(Succeed())
+		})
+
+		Context("when the vm is not running", func() {
+			It("returns an error", func() {
+				mockProvisioner.EXPECT().Ping().Return(errors.New("some-error"))
+
+				Expect(subject.Relax()).To(MatchError("cf dev is not running. Please execute 'cf dev start': some-error"))
+			})
+		})
+
+		Context("when relaxing fails", func() {
+			It("returns an error", func() {
+				mockProvisioner.EXPECT().Ping()
+				mockProvisioner.EXPECT().RelaxQuotas().Return(errors.New("some-error"))
+
+				Expect(subject.Relax()).To(MatchError("relaxing quotas: some-error"))
+			})
+		})
+	})
+})
@@ -0,0 +1,124 @@
+package reconfigure
+
+import (
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+	"code.cloudfoundry.org/cfdev/provision"
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/reconfigure UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/hypervisor.go code.cloudfoundry.org/cfdev/cmd/reconfigure Hypervisor
+type Hypervisor interface {
+	IsRunning(vmName string) (bool, error)
+	Stop(vmName string) error
+	Reconfigure(vmName string, cpus int, memoryMB int) error
+	Start(vmName string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/reconfigure Provisioner
+type Provisioner interface {
+	Ping() error
+	DeployBosh() error
+	RecoverDeployments() error
+}
+
+type Args struct {
+	Cpus int
+	Mem  int
+}
+
+// Reconfigure restarts the running vm with new resources. Unlike stopping
+// and starting again, the vm keeps its disk so nothing is re-provisioned.
+type Reconfigure struct {
+	UI          UI
+	Hypervisor  Hypervisor
+	Provisioner Provisioner
+	Config      config.Config
+	// VMTimeout bounds how long to wait for the vm to come back up.
+	VMTimeout time.Duration
+}
+
+func (r *Reconfigure) Cmd() *cobra.Command {
+	args := Args{}
+	cmd := &cobra.Command{
+		Use:   "reconfigure",
+		Short: "Restart the VM with a new number of cpus or amount of memory",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := r.Execute(args); err != nil {
+				return e.SafeWrap(err, "cf dev reconfigure")
+			}
+			return nil
+		},
+	}
+
+	pf := cmd.PersistentFlags()
+	pf.IntVarP(&args.Cpus, "cpus", "c", 0, "cpus to allocate to vm, unchanged when 0")
+	pf.IntVarP(&args.Mem, "memory", "m", 0, "memory to allocate to vm in MB, unchanged when 0")
+	return cmd
+}
+
+// Execute changes the cpus and memory that are not zero, leaving the rest
+// as the vm has them.
+func (r *Reconfigure) Execute(args Args) error {
+	if args.Cpus < 0 || args.Mem < 0 {
+		return fmt.Errorf("cpus and memory must be greater than zero")
+	} else if args.Cpus == 0 && args.Mem == 0 {
+		return fmt.Errorf("nothing to change, pass --cpus, --memory or both")
+	}
+
+	vmName := r.Config.VMName()
+	if running, err := r.Hypervisor.IsRunning(vmName); err != nil {
+		return e.SafeWrap(err, "is running")
+	} else if !running {
+		return fmt.Errorf("cf dev is not running. Please pass --cpus and --memory to 'cf dev start' instead")
+	}
+
+	restarting := "reconfigure.restarting"
+	if args.Cpus == 0 {
+		restarting = "reconfigure.restarting-memory"
+	} else if args.Mem == 0 {
+		restarting = "reconfigure.restarting-cpus"
+	}
+	r.UI.Say(messages.T(restarting, map[string]interface{}{
+		"Cpus":   args.Cpus,
+		"Memory": args.Mem,
+	}))
+
+	if err := r.Hypervisor.Stop(vmName); err != nil {
+		return e.SafeWrap(err, "stopping the vm")
+	}
+
+	if err := r.Hypervisor.Reconfigure(vmName, args.Cpus, args.Mem); err != nil {
+		return e.SafeWrap(err, "reconfiguring the vm")
+	}
+
+	if err := r.Hypervisor.Start(vmName); err != nil {
+		return e.SafeWrap(err, "starting the vm")
+	}
+
+	if err := provision.WaitForVM(r.Provisioner.Ping, r.VMTimeout); err != nil {
+		return e.SafeWrap(err, "Timed out waiting for the VM")
+	}
+
+	// The containers of the director and the deployments do not survive the
+	// reboot.
+	r.UI.Say(messages.T("reconfigure.recovering"))
+	if err := r.Provisioner.DeployBosh(); err != nil {
+		return e.SafeWrap(err, "bringing the BOSH Director back up")
+	}
+	if err := r.Provisioner.RecoverDeployments(); err != nil {
+		return e.SafeWrap(err, "recreating the deployments' vms")
+	}
+
+	r.UI.Say(messages.T("reconfigure.done"))
+	return nil
+}
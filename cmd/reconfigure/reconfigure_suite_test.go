@@ -0,0 +1,13 @@
+package reconfigure_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestReconfigure(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Reconfigure Suite")
+}
@@ -0,0 +1,124 @@
+package reconfigure_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/cmd/reconfigure"
+	"code.cloudfoundry.org/cfdev/cmd/reconfigure/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reconfigure", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockHypervisor  *mocks.MockHypervisor
+		mockProvisioner *mocks.MockProvisioner
+		subject         *reconfigure.Reconfigure
+		args            reconfigure.Args
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockHypervisor = mocks.NewMockHypervisor(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+
+		subject = &reconfigure.Reconfigure{
+			UI:          mockUI,
+			Hypervisor:  mockHypervisor,
+			Provisioner: mockProvisioner,
+			Config:      config.Config{},
+			VMTimeout:   time.Millisecond,
+		}
+		args = reconfigure.Args{Cpus: 6, Mem: 8192}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("restarts the vm with the new resources", func() {
+		gomock.InOrder(
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil),
+			mockUI.EXPECT().Say("Restarting the VM with 6 cpus and 8192MB of memory..."),
+			mockHypervisor.EXPECT().Stop("cfdev"),
+			mockHypervisor.EXPECT().Reconfigure("cfdev", 6, 8192),
+			mockHypervisor.EXPECT().Start("cfdev"),
+			mockProvisioner.EXPECT().Ping(),
+			mockUI.EXPECT().Say("Bringing the BOSH Director and the deployments' vms back up..."),
+			mockProvisioner.EXPECT().DeployBosh(),
+			mockProvisioner.EXPECT().RecoverDeployments(),
+			mockUI.EXPECT().Say("The VM has been reconfigured. CF may take a few minutes to be available again, run 'cf dev wait --for cf-api' to wait for it"),
+		)
+
+		Expect(subject.Execute(args)).To(Succeed())
+	})
+
+	Context("when cf dev is not running", func() {
+		It("points at cf dev start", func() {
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil)
+
+			Expect(subject.Execute(args)).To(MatchError(ContainSubstring("'cf dev start'")))
+		})
+	})
+
+	Context("when the vm cannot be reconfigured", func() {
+		It("returns the error without starting the vm", func() {
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil)
+			mockUI.EXPECT().Say(gomock.Any())
+			mockHypervisor.EXPECT().Stop("cfdev")
+			mockHypervisor.EXPECT().Reconfigure("cfdev", 6, 8192).Return(errors.New("some-error"))
+
+			Expect(subject.Execute(args)).To(MatchError("reconfiguring the vm: some-error"))
+		})
+	})
+
+	Context("when the vm does not come back up", func() {
+		It("returns the error", func() {
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil)
+			mockUI.EXPECT().Say(gomock.Any())
+			mockHypervisor.EXPECT().Stop("cfdev")
+			mockHypervisor.EXPECT().Reconfigure("cfdev", 6, 8192)
+			mockHypervisor.EXPECT().Start("cfdev")
+			mockProvisioner.EXPECT().Ping().Return(errors.New("some-error")).AnyTimes()
+
+			Expect(subject.Execute(args)).To(MatchError("Timed out waiting for the VM: some-error"))
+		})
+	})
+
+	Context("when only memory is given", func() {
+		It("leaves the cpus unchanged", func() {
+			gomock.InOrder(
+				mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil),
+				mockUI.EXPECT().Say("Restarting the VM with 8192MB of memory..."),
+				mockHypervisor.EXPECT().Stop("cfdev"),
+				mockHypervisor.EXPECT().Reconfigure("cfdev", 0, 8192),
+				mockHypervisor.EXPECT().Start("cfdev"),
+				mockProvisioner.EXPECT().Ping(),
+				mockUI.EXPECT().Say("Bringing the BOSH Director and the deployments' vms back up..."),
+				mockProvisioner.EXPECT().DeployBosh(),
+				mockProvisioner.EXPECT().RecoverDeployments(),
+				mockUI.EXPECT().Say("The VM has been reconfigured. CF may take a few minutes to be available again, run 'cf dev wait --for cf-api' to wait for it"),
+			)
+
+			Expect(subject.Execute(reconfigure.Args{Mem: 8192})).To(Succeed())
+		})
+	})
+
+	Context("when nothing is given", func() {
+		It("errors", func() {
+			Expect(subject.Execute(reconfigure.Args{})).To(MatchError("nothing to change, pass --cpus, --memory or both"))
+		})
+	})
+
+	Context("when a negative amount is given", func() {
+		It("errors", func() {
+			Expect(subject.Execute(reconfigure.Args{Cpus: -1, Mem: 8192})).To(MatchError("cpus and memory must be greater than zero"))
+		})
+	})
+})
@@ -0,0 +1,93 @@
+package remove_service
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/metadata"
+	"code.cloudfoundry.org/cfdev/provision"
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/remove-service UI
+type UI interface {
+	Say(message string, args ...interface{})
+	Writer() io.Writer
+}
+
+//go:generate mockgen -package mocks -destination mocks/metadata_reader.go code.cloudfoundry.org/cfdev/cmd/remove-service MetaDataReader
+type MetaDataReader interface {
+	Read(tarballPath string) (metadata.Metadata, error)
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/remove-service Provisioner
+type Provisioner interface {
+	Ping() error
+	GetWhiteListedService(string, []provision.Service) (*provision.Service, error)
+	DeleteService(provision.UI, provision.Service) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/remove-service Analytics
+type Analytics interface {
+	Event(event string, data ...map[string]interface{}) error
+}
+
+// RemoveService deletes the deployment of a single service, the reverse of
+// deploy-service, without destroying the rest of the environment.
+type RemoveService struct {
+	UI             UI
+	Provisioner    Provisioner
+	MetaDataReader MetaDataReader
+	Config         config.Config
+	Analytics      Analytics
+}
+
+type Args struct {
+	Service string
+}
+
+func (c *RemoveService) Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove-service SERVICE",
+		Short:   "Remove a deployed service",
+		Example: "cf dev remove-service redis",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := c.Execute(Args{Service: args[0]}); err != nil {
+				return e.SafeWrap(err, "cf dev remove-service")
+			}
+			return nil
+		},
+	}
+}
+
+func (c *RemoveService) Execute(args Args) error {
+	metadataConfig, err := c.MetaDataReader.Read(filepath.Join(c.Config.CacheDir, "metadata.yml"))
+	if err != nil {
+		return e.SafeWrap(err, "something went wrong while reading the assets. Please execute 'cf dev start'")
+	}
+
+	if c.Provisioner.Ping() != nil {
+		return fmt.Errorf("cf dev is not running. Please execute 'cf dev start'")
+	}
+
+	service, err := c.Provisioner.GetWhiteListedService(args.Service, metadataConfig.Services)
+	if err != nil {
+		return e.SafeWrap(err, "Failed to find service")
+	}
+
+	if service.Flagname == "always-include" {
+		return fmt.Errorf("%s is part of every cf dev environment and cannot be removed", service.Name)
+	}
+
+	if err := c.Provisioner.DeleteService(c.UI, *service); err != nil {
+		return e.SafeWrap(err, "Failed to remove service")
+	}
+
+	c.Analytics.Event(cfanalytics.REMOVE_SERVICE, map[string]interface{}{"name": args.Service})
+	return nil
+}
@@ -0,0 +1,13 @@
+package remove_service
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestRemoveService(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cmd Remove Service Suite")
+}
@@ -0,0 +1,94 @@
+package remove_service_test
+
+import (
+	"errors"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/cmd/remove-service"
+	"code.cloudfoundry.org/cfdev/cmd/remove-service/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/metadata"
+	"code.cloudfoundry.org/cfdev/provision"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RemoveService", func() {
+	var (
+		mockController     *gomock.Controller
+		mockMetadataReader *mocks.MockMetaDataReader
+		mockProvisioner    *mocks.MockProvisioner
+		mockUI             *mocks.MockUI
+		mockAnalytics      *mocks.MockAnalytics
+		cmd                *remove_service.RemoveService
+		service            provision.Service
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockMetadataReader = mocks.NewMockMetaDataReader(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+		mockAnalytics = mocks.NewMockAnalytics(mockController)
+
+		cmd = &remove_service.RemoveService{
+			UI:             mockUI,
+			MetaDataReader: mockMetadataReader,
+			Provisioner:    mockProvisioner,
+			Config: config.Config{
+				CacheDir: "some-cache-dir",
+			},
+			Analytics: mockAnalytics,
+		}
+
+		service = provision.Service{Name: "Redis", Flagname: "redis", Deployment: "cf-redis"}
+		mockMetadataReader.EXPECT().Read(filepath.Join("some-cache-dir", "metadata.yml")).Return(metadata.Metadata{
+			Version:  "v3",
+			Services: []provision.Service{service},
+		}, nil)
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("deletes the service's deployment", func() {
+		gomock.InOrder(
+			mockProvisioner.EXPECT().Ping(),
+			mockProvisioner.EXPECT().GetWhiteListedService("redis", []provision.Service{service}).Return(&service, nil),
+			mockProvisioner.EXPECT().DeleteService(mockUI, service),
+			mockAnalytics.EXPECT().Event("removed service", map[string]interface{}{"name": "redis"}),
+		)
+
+		Expect(cmd.Execute(remove_service.Args{Service: "redis"})).To(Succeed())
+	})
+
+	Context("when the service is part of every environment", func() {
+		It("refuses to remove it", func() {
+			service.Flagname = "always-include"
+			mockProvisioner.EXPECT().Ping()
+			mockProvisioner.EXPECT().GetWhiteListedService("redis", gomock.Any()).Return(&service, nil)
+
+			Expect(cmd.Execute(remove_service.Args{Service: "redis"})).To(MatchError(ContainSubstring("cannot be removed")))
+		})
+	})
+
+	Context("when the deployment cannot be deleted", func() {
+		It("returns an error", func() {
+			mockProvisioner.EXPECT().Ping()
+			mockProvisioner.EXPECT().GetWhiteListedService("redis", gomock.Any()).Return(&service, nil)
+			mockProvisioner.EXPECT().DeleteService(mockUI, service).Return(errors.New("some-error"))
+
+			Expect(cmd.Execute(remove_service.Args{Service: "redis"})).To(MatchError("Failed to remove service: some-error"))
+		})
+	})
+
+	Context("when cf dev is not running", func() {
+		It("returns an error", func() {
+			mockProvisioner.EXPECT().Ping().Return(errors.New("some-error"))
+
+			Expect(cmd.Execute(remove_service.Args{Service: "redis"})).To(MatchError(ContainSubstring("cf dev is not running")))
+		})
+	})
+})
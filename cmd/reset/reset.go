@@ -0,0 +1,55 @@
+package reset
+
+import (
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/reset UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/stop.go code.cloudfoundry.org/cfdev/cmd/reset Stop
+type Stop interface {
+	RunE(cmd *cobra.Command, args []string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/start.go code.cloudfoundry.org/cfdev/cmd/reset Start
+type Start interface {
+	Cmd() *cobra.Command
+}
+
+// Reset throws away everything the vm has written since it was created and
+// starts it again. The vm's disk is a delta on top of the cached base disk,
+// so nothing large is copied.
+type Reset struct {
+	UI    UI
+	Stop  Stop
+	Start Start
+}
+
+func (r *Reset) Cmd() *cobra.Command {
+	start := r.Start.Cmd()
+	cmd := &cobra.Command{
+		Use:   "reset",
+		Short: "Discard all changes made to the VM and start it again",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return r.RunE(start, args)
+		},
+	}
+
+	cmd.Flags().AddFlagSet(start.PersistentFlags())
+	return cmd
+}
+
+func (r *Reset) RunE(start *cobra.Command, args []string) error {
+	r.UI.Say(messages.T("reset.resetting"))
+
+	if err := r.Stop.RunE(nil, nil); err != nil {
+		return e.SafeWrap(err, "cf dev reset")
+	}
+
+	return start.RunE(start, args)
+}
@@ -0,0 +1,13 @@
+package reset_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestReset(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Reset Suite")
+}
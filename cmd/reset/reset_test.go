@@ -0,0 +1,79 @@
+package reset_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cfdev/cmd/reset"
+	"code.cloudfoundry.org/cfdev/cmd/reset/mocks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+var _ = Describe("Reset", func() {
+	var (
+		mockController *gomock.Controller
+		mockUI         *mocks.MockUI
+		mockStop       *mocks.MockStop
+		mockStart      *mocks.MockStart
+		startCmd       *cobra.Command
+		started        bool
+		cpus           int
+		subject        *reset.Reset
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockStop = mocks.NewMockStop(mockController)
+		mockStart = mocks.NewMockStart(mockController)
+
+		started = false
+		startCmd = &cobra.Command{
+			Use: "start",
+			RunE: func(_ *cobra.Command, _ []string) error {
+				started = true
+				return nil
+			},
+		}
+		startCmd.PersistentFlags().IntVarP(&cpus, "cpus", "c", 4, "")
+		mockStart.EXPECT().Cmd().Return(startCmd).AnyTimes()
+
+		subject = &reset.Reset{
+			UI:    mockUI,
+			Stop:  mockStop,
+			Start: mockStart,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("stops and starts the vm again", func() {
+		gomock.InOrder(
+			mockUI.EXPECT().Say("Discarding changes made to the VM..."),
+			mockStop.EXPECT().RunE(nil, nil),
+		)
+
+		cmd := subject.Cmd()
+		cmd.SetArgs([]string{"--cpus", "2"})
+		Expect(cmd.Execute()).To(Succeed())
+
+		Expect(started).To(BeTrue())
+		Expect(cpus).To(Equal(2))
+	})
+
+	Context("when stopping fails", func() {
+		It("returns an error without starting", func() {
+			mockUI.EXPECT().Say(gomock.Any())
+			mockStop.EXPECT().RunE(nil, nil).Return(errors.New("some-error"))
+
+			cmd := subject.Cmd()
+			cmd.SetArgs([]string{})
+			Expect(cmd.Execute()).To(MatchError("cf dev reset: some-error"))
+			Expect(started).To(BeFalse())
+		})
+	})
+})
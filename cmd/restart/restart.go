@@ -0,0 +1,173 @@
+package restart
+
+import (
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+	"code.cloudfoundry.org/cfdev/provision"
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/restart UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/host.go code.cloudfoundry.org/cfdev/cmd/restart Host
+type Host interface {
+	CheckRequirements() error
+}
+
+//go:generate mockgen -package mocks -destination mocks/hypervisor.go code.cloudfoundry.org/cfdev/cmd/restart Hypervisor
+type Hypervisor interface {
+	IsRunning(vmName string) (bool, error)
+	Stop(vmName string) error
+	Start(vmName string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/vpnkit.go code.cloudfoundry.org/cfdev/cmd/restart VpnKit
+type VpnKit interface {
+	Start() error
+	Stop() error
+}
+
+//go:generate mockgen -package mocks -destination mocks/network.go code.cloudfoundry.org/cfdev/cmd/restart HostNet
+type HostNet interface {
+	AddLoopbackAliases(...string) error
+}
+
+//go:generate mockgen -package mocks -destination mocks/analyticsd.go code.cloudfoundry.org/cfdev/cmd/restart AnalyticsD
+type AnalyticsD interface {
+	Start() error
+	Stop() error
+}
+
+//go:generate mockgen -package mocks -destination mocks/toggle.go code.cloudfoundry.org/cfdev/cmd/restart Toggle
+type Toggle interface {
+	Enabled() bool
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/restart Provisioner
+type Provisioner interface {
+	Ping() error
+	DeployBosh() error
+	RecoverDeployments() error
+	StartDiskMonitor() error
+}
+
+// Restart stops the vm and boots it again from its disk, bringing vpnkit,
+// the loopback aliases and analyticsd back up around it. The containers of
+// the director and the deployments do not survive the reboot, so they are
+// recreated from their persistent disks. Unlike stopping and starting,
+// which destroys the vm, CF and everything pushed to it are kept and
+// nothing is re-provisioned.
+type Restart struct {
+	UI              UI
+	Host            Host
+	Hypervisor      Hypervisor
+	VpnKit          VpnKit
+	HostNet         HostNet
+	AnalyticsD      AnalyticsD
+	AnalyticsToggle Toggle
+	Provisioner     Provisioner
+	Config          config.Config
+	// VMTimeout bounds how long to wait for the vm to come back up.
+	VMTimeout time.Duration
+}
+
+func (r *Restart) Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restart",
+		Short: "Restart the VM, keeping CF and its apps",
+		Long: "Stop the VM and boot it again from its disk, restarting the networking and daemons around it. " +
+			"Unlike 'cf dev stop' and 'cf dev start', CF is not deployed again and nothing pushed to it is lost.",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := r.Execute(); err != nil {
+				return e.SafeWrap(err, "cf dev restart")
+			}
+			return nil
+		},
+	}
+}
+
+func (r *Restart) Execute() (err error) {
+	if err := r.Host.CheckRequirements(); err != nil {
+		return err
+	}
+
+	vmName := r.Config.VMName()
+	if running, err := r.Hypervisor.IsRunning(vmName); err != nil {
+		return e.SafeWrap(err, "is running")
+	} else if !running {
+		return fmt.Errorf("cf dev is not running. Please run 'cf dev start' instead")
+	}
+
+	r.UI.Say(messages.T("restart.stopping"))
+	if err := r.AnalyticsD.Stop(); err != nil {
+		return e.SafeWrap(err, "stopping analyticsd")
+	}
+	analyticsStarted := false
+	defer func() {
+		// analyticsd is brought back up even when the vm is not, so that a
+		// failed restart does not leave telemetry stopped.
+		if err != nil && !analyticsStarted {
+			r.startAnalytics()
+		}
+	}()
+
+	if err := r.Hypervisor.Stop(vmName); err != nil {
+		return e.SafeWrap(err, "stopping the vm")
+	}
+
+	if err := r.VpnKit.Stop(); err != nil {
+		return e.SafeWrap(err, "stopping vpnkit")
+	}
+
+	r.UI.Say(messages.T("restart.starting"))
+	if err := r.HostNet.AddLoopbackAliases(r.Config.BoshDirectorIP, r.Config.CFRouterIP); err != nil {
+		return e.SafeWrap(err, "adding aliases")
+	}
+
+	if err := r.VpnKit.Start(); err != nil {
+		return e.SafeWrap(err, "starting vpnkit")
+	}
+
+	if err := r.Hypervisor.Start(vmName); err != nil {
+		return e.SafeWrap(err, "starting the vm")
+	}
+
+	if err := provision.WaitForVM(r.Provisioner.Ping, r.VMTimeout); err != nil {
+		return e.SafeWrap(err, "Timed out waiting for the VM")
+	}
+
+	r.UI.Say(messages.T("restart.recovering"))
+	if err := r.Provisioner.DeployBosh(); err != nil {
+		return e.SafeWrap(err, "bringing the BOSH Director back up")
+	}
+	if err := r.Provisioner.RecoverDeployments(); err != nil {
+		return e.SafeWrap(err, "recreating the deployments' vms")
+	}
+
+	if err := r.Provisioner.StartDiskMonitor(); err != nil {
+		r.UI.Say(messages.T("provision.disk-monitor-failed", map[string]interface{}{"Error": err}))
+	}
+
+	analyticsStarted = true
+	if err := r.startAnalytics(); err != nil {
+		return e.SafeWrap(err, "starting analyticsd")
+	}
+
+	r.UI.Say(messages.T("restart.done"))
+	return nil
+}
+
+// startAnalytics starts analyticsd unless telemetry is off.
+func (r *Restart) startAnalytics() error {
+	if !r.AnalyticsToggle.Enabled() {
+		return nil
+	}
+	return r.AnalyticsD.Start()
+}
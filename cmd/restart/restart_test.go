@@ -0,0 +1,201 @@
+package restart_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/cmd/restart"
+	"code.cloudfoundry.org/cfdev/cmd/restart/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Restart", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockHost        *mocks.MockHost
+		mockHypervisor  *mocks.MockHypervisor
+		mockVpnKit      *mocks.MockVpnKit
+		mockHostNet     *mocks.MockHostNet
+		mockAnalyticsD  *mocks.MockAnalyticsD
+		mockToggle      *mocks.MockToggle
+		mockProvisioner *mocks.MockProvisioner
+		subject         *restart.Restart
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockHost = mocks.NewMockHost(mockController)
+		mockHypervisor = mocks.NewMockHypervisor(mockController)
+		mockVpnKit = mocks.NewMockVpnKit(mockController)
+		mockHostNet = mocks.NewMockHostNet(mockController)
+		mockAnalyticsD = mocks.NewMockAnalyticsD(mockController)
+		mockToggle = mocks.NewMockToggle(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+
+		subject = &restart.Restart{
+			UI:              mockUI,
+			Host:            mockHost,
+			Hypervisor:      mockHypervisor,
+			VpnKit:          mockVpnKit,
+			HostNet:         mockHostNet,
+			AnalyticsD:      mockAnalyticsD,
+			AnalyticsToggle: mockToggle,
+			Provisioner:     mockProvisioner,
+			Config: config.Config{
+				BoshDirectorIP: "10.144.0.2",
+				CFRouterIP:     "10.144.0.34",
+			},
+			VMTimeout: time.Millisecond,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("stops the vm and starts it again without destroying it", func() {
+		gomock.InOrder(
+			mockHost.EXPECT().CheckRequirements(),
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil),
+			mockUI.EXPECT().Say("Stopping the VM, keeping its disk..."),
+			mockAnalyticsD.EXPECT().Stop(),
+			mockHypervisor.EXPECT().Stop("cfdev"),
+			mockVpnKit.EXPECT().Stop(),
+			mockUI.EXPECT().Say("Starting the VM again..."),
+			mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.2", "10.144.0.34"),
+			mockVpnKit.EXPECT().Start(),
+			mockHypervisor.EXPECT().Start("cfdev"),
+			mockProvisioner.EXPECT().Ping(),
+			mockUI.EXPECT().Say("Bringing the BOSH Director and the deployments' vms back up..."),
+			mockProvisioner.EXPECT().DeployBosh(),
+			mockProvisioner.EXPECT().RecoverDeployments(),
+			mockProvisioner.EXPECT().StartDiskMonitor(),
+			mockToggle.EXPECT().Enabled().Return(true),
+			mockAnalyticsD.EXPECT().Start(),
+			mockUI.EXPECT().Say("The VM has been restarted. CF may take a few minutes to be available again, run 'cf dev wait --for cf-api' to wait for it"),
+		)
+
+		Expect(subject.Execute()).To(Succeed())
+	})
+
+	It("leaves analyticsd stopped when telemetry is off", func() {
+		mockHost.EXPECT().CheckRequirements()
+		mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil)
+		mockUI.EXPECT().Say(gomock.Any()).AnyTimes()
+		mockAnalyticsD.EXPECT().Stop()
+		mockHypervisor.EXPECT().Stop("cfdev")
+		mockVpnKit.EXPECT().Stop()
+		mockHostNet.EXPECT().AddLoopbackAliases(gomock.Any(), gomock.Any())
+		mockVpnKit.EXPECT().Start()
+		mockHypervisor.EXPECT().Start("cfdev")
+		mockProvisioner.EXPECT().Ping()
+		mockProvisioner.EXPECT().DeployBosh()
+		mockProvisioner.EXPECT().RecoverDeployments()
+		mockProvisioner.EXPECT().StartDiskMonitor()
+		mockToggle.EXPECT().Enabled().Return(false)
+
+		Expect(subject.Execute()).To(Succeed())
+	})
+
+	It("warns when the disk monitor cannot be started again", func() {
+		mockHost.EXPECT().CheckRequirements()
+		mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil)
+		mockUI.EXPECT().Say("Stopping the VM, keeping its disk...")
+		mockUI.EXPECT().Say("Starting the VM again...")
+		mockUI.EXPECT().Say("Bringing the BOSH Director and the deployments' vms back up...")
+		mockUI.EXPECT().Say("The VM has been restarted. CF may take a few minutes to be available again, run 'cf dev wait --for cf-api' to wait for it")
+		mockAnalyticsD.EXPECT().Stop()
+		mockHypervisor.EXPECT().Stop("cfdev")
+		mockVpnKit.EXPECT().Stop()
+		mockHostNet.EXPECT().AddLoopbackAliases(gomock.Any(), gomock.Any())
+		mockVpnKit.EXPECT().Start()
+		mockHypervisor.EXPECT().Start("cfdev")
+		mockProvisioner.EXPECT().Ping()
+		mockProvisioner.EXPECT().DeployBosh()
+		mockProvisioner.EXPECT().RecoverDeployments()
+		mockProvisioner.EXPECT().StartDiskMonitor().Return(errors.New("some-error"))
+		mockUI.EXPECT().Say("[WARN] Unable to watch the disk, unused BOSH packages will not be cleaned up as it fills: some-error")
+		mockToggle.EXPECT().Enabled().Return(false)
+
+		Expect(subject.Execute()).To(Succeed())
+	})
+
+	Context("when cf dev is not running", func() {
+		It("points at cf dev start", func() {
+			mockHost.EXPECT().CheckRequirements()
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil)
+
+			Expect(subject.Execute()).To(MatchError(ContainSubstring("'cf dev start'")))
+		})
+	})
+
+	Context("when the host does not meet the requirements", func() {
+		It("returns the error", func() {
+			mockHost.EXPECT().CheckRequirements().Return(errors.New("some-error"))
+
+			Expect(subject.Execute()).To(MatchError("some-error"))
+		})
+	})
+
+	Context("when vpnkit cannot be started", func() {
+		It("returns the error without starting the vm", func() {
+			mockHost.EXPECT().CheckRequirements()
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil)
+			mockUI.EXPECT().Say(gomock.Any()).AnyTimes()
+			mockAnalyticsD.EXPECT().Stop()
+			mockHypervisor.EXPECT().Stop("cfdev")
+			mockVpnKit.EXPECT().Stop()
+			mockHostNet.EXPECT().AddLoopbackAliases(gomock.Any(), gomock.Any())
+			mockVpnKit.EXPECT().Start().Return(errors.New("some-error"))
+			mockToggle.EXPECT().Enabled().Return(true)
+			mockAnalyticsD.EXPECT().Start()
+
+			Expect(subject.Execute()).To(MatchError("starting vpnkit: some-error"))
+		})
+	})
+
+	Context("when the deployments' vms cannot be recreated", func() {
+		It("returns the error and brings analyticsd back up", func() {
+			mockHost.EXPECT().CheckRequirements()
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil)
+			mockUI.EXPECT().Say(gomock.Any()).AnyTimes()
+			mockAnalyticsD.EXPECT().Stop()
+			mockHypervisor.EXPECT().Stop("cfdev")
+			mockVpnKit.EXPECT().Stop()
+			mockHostNet.EXPECT().AddLoopbackAliases(gomock.Any(), gomock.Any())
+			mockVpnKit.EXPECT().Start()
+			mockHypervisor.EXPECT().Start("cfdev")
+			mockProvisioner.EXPECT().Ping()
+			mockProvisioner.EXPECT().DeployBosh()
+			mockProvisioner.EXPECT().RecoverDeployments().Return(errors.New("some-error"))
+			mockToggle.EXPECT().Enabled().Return(true)
+			mockAnalyticsD.EXPECT().Start()
+
+			Expect(subject.Execute()).To(MatchError("recreating the deployments' vms: some-error"))
+		})
+	})
+
+	Context("when the vm does not come back up", func() {
+		It("returns the error", func() {
+			mockHost.EXPECT().CheckRequirements()
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil)
+			mockUI.EXPECT().Say(gomock.Any()).AnyTimes()
+			mockAnalyticsD.EXPECT().Stop()
+			mockHypervisor.EXPECT().Stop("cfdev")
+			mockVpnKit.EXPECT().Stop()
+			mockHostNet.EXPECT().AddLoopbackAliases(gomock.Any(), gomock.Any())
+			mockVpnKit.EXPECT().Start()
+			mockHypervisor.EXPECT().Start("cfdev")
+			mockProvisioner.EXPECT().Ping().Return(errors.New("some-error")).AnyTimes()
+			mockToggle.EXPECT().Enabled().Return(true)
+			mockAnalyticsD.EXPECT().Start()
+
+			Expect(subject.Execute()).To(MatchError("Timed out waiting for the VM: some-error"))
+		})
+	})
+})
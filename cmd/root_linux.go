@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"code.cloudfoundry.org/cfdev/env"
+	"code.cloudfoundry.org/cfdev/profiler"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	b2 "code.cloudfoundry.org/cfdev/cmd/bosh"
+	b21 "code.cloudfoundry.org/cfdev/cmd/bosh-ssh"
+	b16 "code.cloudfoundry.org/cfdev/cmd/bundle"
+	b3 "code.cloudfoundry.org/cfdev/cmd/catalog"
+	b22 "code.cloudfoundry.org/cfdev/cmd/configure"
+	b18 "code.cloudfoundry.org/cfdev/cmd/creds"
+	b9 "code.cloudfoundry.org/cfdev/cmd/deploy-service"
+	b11 "code.cloudfoundry.org/cfdev/cmd/doctor"
+	b4 "code.cloudfoundry.org/cfdev/cmd/download"
+	b23 "code.cloudfoundry.org/cfdev/cmd/logs"
+	b8 "code.cloudfoundry.org/cfdev/cmd/provision"
+	b10 "code.cloudfoundry.org/cfdev/cmd/prune"
+	b12 "code.cloudfoundry.org/cfdev/cmd/quotas"
+	b17 "code.cloudfoundry.org/cfdev/cmd/reconfigure"
+	b20 "code.cloudfoundry.org/cfdev/cmd/remove-service"
+	b13 "code.cloudfoundry.org/cfdev/cmd/reset"
+	b25 "code.cloudfoundry.org/cfdev/cmd/restart"
+	b19 "code.cloudfoundry.org/cfdev/cmd/run-errand"
+	b26 "code.cloudfoundry.org/cfdev/cmd/self-update"
+	b24 "code.cloudfoundry.org/cfdev/cmd/ssh"
+	b5 "code.cloudfoundry.org/cfdev/cmd/start"
+	b14 "code.cloudfoundry.org/cfdev/cmd/status"
+	b6 "code.cloudfoundry.org/cfdev/cmd/stop"
+	b7 "code.cloudfoundry.org/cfdev/cmd/telemetry"
+	b1 "code.cloudfoundry.org/cfdev/cmd/version"
+	b15 "code.cloudfoundry.org/cfdev/cmd/wait"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/credhub"
+	"code.cloudfoundry.org/cfdev/creds"
+	"code.cloudfoundry.org/cfdev/daemon"
+	"code.cloudfoundry.org/cfdev/host"
+	"code.cloudfoundry.org/cfdev/hypervisor"
+	"code.cloudfoundry.org/cfdev/metadata"
+	"code.cloudfoundry.org/cfdev/network"
+	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/quirks"
+	"code.cloudfoundry.org/cfdev/resource"
+	"code.cloudfoundry.org/cfdev/resource/progress"
+	"github.com/spf13/cobra"
+)
+
+type UI interface {
+	Say(message string, args ...interface{})
+	Writer() io.Writer
+}
+
+type cmdBuilder interface {
+	Cmd() *cobra.Command
+}
+
+type AnalyticsClient interface {
+	Event(event string, data ...map[string]interface{}) error
+	PromptOptInIfNeeded(customMessage string) error
+}
+
+// Hypervisor is what each vm backend provides.
+type Hypervisor interface {
+	Preflight(vm hypervisor.VM) []hypervisor.Finding
+	CreateVM(vm hypervisor.VM) error
+	Start(vmName string) error
+	Stop(vmName string) error
+	Destroy(vmName string) error
+	Reconfigure(vmName string, cpus int, memoryMB int) error
+	IsRunning(vmName string) (bool, error)
+	Stats(vmName string) (hypervisor.Stats, error)
+	ConsoleLogPath(vmName string) string
+	Export(vmName string, path string) error
+	Import(path string) error
+}
+
+// VpnKit networks the vm with the host.
+type VpnKit interface {
+	Start() error
+	Stop() error
+	Destroy() error
+	Watch(chan string)
+}
+
+type Toggle interface {
+	Defined() bool
+	Enabled() bool
+	CustomAnalyticsDefined() bool
+	IsCustom() bool
+	SetCFAnalyticsEnabled(value bool) error
+	SetCustomAnalyticsEnabled(value bool) error
+	GetProps() map[string]interface{}
+	SetProp(k, v string) error
+	Override() string
+}
+
+func NewRoot(exit chan struct{}, ui UI, config config.Config, analyticsClient AnalyticsClient, analyticsToggle Toggle) *cobra.Command {
+	root := &cobra.Command{Use: "cf", SilenceUsage: true, SilenceErrors: true}
+	root.PersistentFlags().Bool("help", false, "")
+	root.PersistentFlags().Lookup("help").Hidden = true
+	lctl := daemon.New(config.CFDevHome)
+
+	usageTemplate := strings.Replace(root.UsageTemplate(), "\n"+`Use "{{.CommandPath}} [command] --help" for more information about a command.`, "", -1)
+	root.SetUsageTemplate(usageTemplate)
+
+	skipVerify := strings.ToLower(os.Getenv("CFDEV_SKIP_ASSET_CHECK"))
+	writer := ui.Writer()
+	cache := &resource.Cache{
+		Dir:                   config.CacheDir,
+		HttpDo:                http.DefaultClient.Do,
+		SkipAssetVerification: skipVerify == "true",
+		Progress:              progress.New(writer),
+		RetryWait:             time.Second,
+		Writer:                writer,
+	}
+	// QEMU is the only backend on linux, and networks the vm itself.
+	var (
+		vmBackend Hypervisor = &hypervisor.QEMU{Config: config, DaemonRunner: lctl}
+		vpnkit    VpnKit     = network.UserMode{}
+	)
+	metaDataReader := metadata.New()
+	analyticsD := &cfanalytics.AnalyticsD{
+		Config:       config,
+		DaemonRunner: lctl,
+	}
+	provisionCmd := &b8.Provision{
+		Exit:           exit,
+		UI:             ui,
+		Provisioner:    provision.NewController(config),
+		MetaDataReader: metaDataReader,
+		Config:         config,
+	}
+
+	telemetryCmd := &b7.Telemetry{
+		UI:              ui,
+		Analytics:       analyticsClient,
+		AnalyticsToggle: analyticsToggle,
+		AnalyticsD:      analyticsD,
+		Config:          config,
+	}
+
+	stopCmd := &b6.Stop{
+		Config:     config,
+		Analytics:  analyticsClient,
+		Hypervisor: vmBackend,
+		HostNet:    &network.HostNet{},
+		Host:       &host.Host{},
+		AnalyticsD: analyticsD,
+		VpnKit:     vpnkit,
+	}
+
+	startCmd := &b5.Start{
+		Exit:            exit,
+		LocalExit:       make(chan string, 3),
+		UI:              ui,
+		Config:          config,
+		Cache:           cache,
+		Env:             &env.Env{Config: config},
+		Analytics:       analyticsClient,
+		AnalyticsToggle: analyticsToggle,
+		HostNet:         &network.HostNet{},
+		Host:            &host.Host{},
+		Quirks:          &quirks.Quirks{},
+		VpnKit:          vpnkit,
+		AnalyticsD:      analyticsD,
+		Hypervisor:      vmBackend,
+		Provisioner:     provision.NewController(config),
+		Provision:       provisionCmd,
+		MetaDataReader:  metaDataReader,
+		Stop:            stopCmd,
+		Profiler:        &profiler.SystemProfiler{},
+	}
+
+	dev := &cobra.Command{
+		Use:           "dev",
+		Short:         "Start and stop a single vm CF deployment running on your workstation",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	dev.PersistentFlags().StringP(instanceFlag, instanceShorthand, "", "name of the cf dev instance to act on")
+	root.AddCommand(dev)
+
+	for _, cmd := range []cmdBuilder{
+		&b1.Version{
+			UI:             ui,
+			Version:        config.CliVersion,
+			BuildVersion:   config.BuildVersion,
+			Config:         config,
+			MetaDataReader: metaDataReader,
+		},
+		&b2.Bosh{
+			Exit:      exit,
+			UI:        ui,
+			Config:    config,
+			Analytics: analyticsClient,
+			Director:  provision.NewController(config),
+		},
+		&b3.Catalog{
+			UI:     ui,
+			Config: config,
+		},
+		&b4.Download{
+			Exit:   exit,
+			UI:     ui,
+			Config: config,
+			Env:    &env.Env{Config: config},
+		},
+		startCmd,
+		stopCmd,
+		telemetryCmd,
+		provisionCmd,
+		&b9.DeployService{
+			UI:             ui,
+			Provisioner:    provision.NewController(config),
+			MetaDataReader: metaDataReader,
+			Analytics:      analyticsClient,
+			Config:         config,
+		},
+		&b10.Prune{
+			UI:          ui,
+			Provisioner: provision.NewController(config),
+		},
+		&b11.Doctor{
+			UI:         ui,
+			Host:       &host.Host{},
+			Quirks:     &quirks.Quirks{},
+			Hypervisor: vmBackend,
+			Network:    network.Probe{},
+			Config:     config,
+		},
+		&b12.Quotas{
+			UI:          ui,
+			Provisioner: provision.NewController(config),
+		},
+		&b13.Reset{
+			UI:    ui,
+			Stop:  stopCmd,
+			Start: startCmd,
+		},
+		&b14.Status{
+			UI:          ui,
+			Provisioner: provision.NewController(config),
+			Hypervisor:  vmBackend,
+			Analytics:   analyticsClient,
+			Config:      config,
+		},
+		&b15.Wait{
+			UI:             ui,
+			Provisioner:    provision.NewController(config),
+			MetaDataReader: metaDataReader,
+			Config:         config,
+		},
+		&b16.Export{
+			UI:         ui,
+			Hypervisor: vmBackend,
+			Config:     config,
+		},
+		&b16.Import{
+			UI:         ui,
+			Hypervisor: vmBackend,
+			Env:        &env.Env{Config: config},
+			Config:     config,
+		},
+		&b17.Reconfigure{
+			UI:          ui,
+			Hypervisor:  vmBackend,
+			Provisioner: provision.NewController(config),
+			Config:      config,
+		},
+		&b18.Creds{
+			UI:       ui,
+			Config:   config,
+			Keychain: &creds.Keychain{},
+			CredHub:  credhub.New(config),
+		},
+		&b19.RunErrand{
+			UI:          ui,
+			Provisioner: provision.NewController(config),
+		},
+		&b20.RemoveService{
+			UI:             ui,
+			Provisioner:    provision.NewController(config),
+			MetaDataReader: metaDataReader,
+			Config:         config,
+			Analytics:      analyticsClient,
+		},
+		&b21.BoshSSH{
+			Provisioner: provision.NewController(config),
+		},
+		&b22.Configure{
+			UI:        ui,
+			Telemetry: telemetryCmd,
+			Stop:      stopCmd,
+			Env:       &env.Env{Config: config},
+			Config:    config,
+		},
+		&b23.Logs{
+			UI:          ui,
+			Hypervisor:  vmBackend,
+			Provisioner: provision.NewController(config),
+			Config:      config,
+			Exit:        exit,
+		},
+		&b24.SSH{
+			Hypervisor:  vmBackend,
+			Provisioner: provision.NewController(config),
+			Config:      config,
+		},
+		&b25.Restart{
+			UI:              ui,
+			Host:            &host.Host{},
+			Hypervisor:      vmBackend,
+			VpnKit:          vpnkit,
+			HostNet:         &network.HostNet{},
+			AnalyticsD:      analyticsD,
+			AnalyticsToggle: analyticsToggle,
+			Provisioner:     provision.NewController(config),
+			Config:          config,
+		},
+		&b26.SelfUpdate{
+			UI:     ui,
+			Config: config,
+			HttpDo: http.DefaultClient.Do,
+			Plugin: b26.CFPlugin{},
+			GOOS:   runtime.GOOS,
+		},
+	} {
+		dev.AddCommand(cmd.Cmd())
+	}
+
+	dev.AddCommand(&cobra.Command{
+		Use:   "help [command]",
+		Short: "Help about any command",
+		Run: func(c *cobra.Command, args []string) {
+			cmd, _, _ := dev.Find(args)
+			cmd.Help()
+		},
+	})
+
+	return root
+}
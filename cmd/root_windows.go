@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"runtime"
 	"strings"
 	"time"
 
@@ -14,21 +15,41 @@ import (
 
 	"code.cloudfoundry.org/cfdev/cfanalytics"
 	b2 "code.cloudfoundry.org/cfdev/cmd/bosh"
+	b21 "code.cloudfoundry.org/cfdev/cmd/bosh-ssh"
+	b16 "code.cloudfoundry.org/cfdev/cmd/bundle"
 	b3 "code.cloudfoundry.org/cfdev/cmd/catalog"
+	b22 "code.cloudfoundry.org/cfdev/cmd/configure"
+	b18 "code.cloudfoundry.org/cfdev/cmd/creds"
+	b11 "code.cloudfoundry.org/cfdev/cmd/doctor"
 	b4 "code.cloudfoundry.org/cfdev/cmd/download"
+	b23 "code.cloudfoundry.org/cfdev/cmd/logs"
 	b8 "code.cloudfoundry.org/cfdev/cmd/provision"
+	b10 "code.cloudfoundry.org/cfdev/cmd/prune"
+	b12 "code.cloudfoundry.org/cfdev/cmd/quotas"
+	b17 "code.cloudfoundry.org/cfdev/cmd/reconfigure"
+	b20 "code.cloudfoundry.org/cfdev/cmd/remove-service"
+	b13 "code.cloudfoundry.org/cfdev/cmd/reset"
+	b25 "code.cloudfoundry.org/cfdev/cmd/restart"
+	b19 "code.cloudfoundry.org/cfdev/cmd/run-errand"
+	b26 "code.cloudfoundry.org/cfdev/cmd/self-update"
+	b24 "code.cloudfoundry.org/cfdev/cmd/ssh"
 	b5 "code.cloudfoundry.org/cfdev/cmd/start"
+	b14 "code.cloudfoundry.org/cfdev/cmd/status"
 	b6 "code.cloudfoundry.org/cfdev/cmd/stop"
 	b7 "code.cloudfoundry.org/cfdev/cmd/telemetry"
 	b1 "code.cloudfoundry.org/cfdev/cmd/version"
+	b15 "code.cloudfoundry.org/cfdev/cmd/wait"
 	b9 "code.cloudfoundry.org/cfdev/cmd/deploy-service"
 	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/credhub"
+	"code.cloudfoundry.org/cfdev/creds"
 	"code.cloudfoundry.org/cfdev/daemon"
 	"code.cloudfoundry.org/cfdev/host"
 	"code.cloudfoundry.org/cfdev/hypervisor"
 	"code.cloudfoundry.org/cfdev/metadata"
 	"code.cloudfoundry.org/cfdev/network"
 	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/quirks"
 	"code.cloudfoundry.org/cfdev/resource"
 	"code.cloudfoundry.org/cfdev/resource/progress"
 	"github.com/spf13/cobra"
@@ -48,6 +69,29 @@ type AnalyticsClient interface {
 	PromptOptInIfNeeded(message string) error
 }
 
+// Hypervisor is what each vm backend provides.
+type Hypervisor interface {
+	Preflight(vm hypervisor.VM) []hypervisor.Finding
+	CreateVM(vm hypervisor.VM) error
+	Start(vmName string) error
+	Stop(vmName string) error
+	Destroy(vmName string) error
+	Reconfigure(vmName string, cpus int, memoryMB int) error
+	IsRunning(vmName string) (bool, error)
+	Stats(vmName string) (hypervisor.Stats, error)
+	ConsoleLogPath(vmName string) string
+	Export(vmName string, path string) error
+	Import(path string) error
+}
+
+// VpnKit networks the vm with the host.
+type VpnKit interface {
+	Start() error
+	Stop() error
+	Destroy() error
+	Watch(chan string)
+}
+
 type Toggle interface {
 	Defined() bool
 	Enabled() bool
@@ -57,6 +101,7 @@ type Toggle interface {
 	SetCustomAnalyticsEnabled(value bool) error
 	GetProps() map[string]interface{}
 	SetProp(k, v string) error
+	Override() string
 }
 
 func NewRoot(exit chan struct{}, ui UI, config config.Config, analyticsClient AnalyticsClient, analyticsToggle Toggle) *cobra.Command {
@@ -64,15 +109,20 @@ func NewRoot(exit chan struct{}, ui UI, config config.Config, analyticsClient An
 	root.PersistentFlags().Bool("help", false, "")
 	root.PersistentFlags().Lookup("help").Hidden = true
 	lctl := daemon.NewWinSW(config.CFDevHome)
-	vpnkit := &network.VpnKit{
+	var vpnkit VpnKit = &network.VpnKit{
 		Config:        config,
 		DaemonRunner:  lctl,
 		Powershell:    runner.Powershell{},
-		Label:         network.VpnKitLabel,
+		Label:         config.DaemonLabel(network.VpnKitLabel),
 		EthernetGUID:  "7207f451-2ca3-4b88-8d01-820a21d78293",
 		PortGUID:      "cc2a519a-fb40-4e45-a9f1-c7f04c5ad7fa",
 		ForwarderGUID: "e3ae8f06-8c25-47fb-b6ed-c20702bcef5e",
 	}
+	var vmBackend Hypervisor = hypervisor.NewHyperV(config)
+	if config.Hypervisor == "qemu" {
+		vmBackend = &hypervisor.QEMU{Config: config, DaemonRunner: lctl}
+		vpnkit = network.UserMode{}
+	}
 	metaDataReader := metadata.New()
 	hostnet := &network.HostNet{
 		VMSwitchName: "cfdev",
@@ -105,12 +155,60 @@ func NewRoot(exit chan struct{}, ui UI, config config.Config, analyticsClient An
 		Config:         config,
 	}
 
+	telemetryCmd := &b7.Telemetry{
+		UI:              ui,
+		Analytics:       analyticsClient,
+		AnalyticsToggle: analyticsToggle,
+		AnalyticsD:      analyticsD,
+		Config:          config,
+	}
+
+	stopCmd := &b6.Stop{
+		Config:     config,
+		Analytics:  analyticsClient,
+		Hypervisor: vmBackend,
+		VpnKit:     vpnkit,
+		HostNet:    hostnet,
+		Host: &host.Host{
+			Powershell: &runner.Powershell{},
+		},
+		AnalyticsD: analyticsD,
+	}
+
+	startCmd := &b5.Start{
+		Exit:            exit,
+		LocalExit:       make(chan string, 3),
+		UI:              ui,
+		Config:          config,
+		Cache:           cache,
+		Env:             &env.Env{Config: config},
+		Analytics:       analyticsClient,
+		AnalyticsToggle: analyticsToggle,
+		HostNet:         hostnet,
+		Host: &host.Host{
+			Powershell: &runner.Powershell{},
+		},
+		Quirks: &quirks.Quirks{
+			Powershell: &runner.Powershell{},
+		},
+		AnalyticsD:     analyticsD,
+		CFDevD:         &network.CFDevD{ExecutablePath: filepath.Join(config.CacheDir, "cfdevd")},
+		Hypervisor:     vmBackend,
+		VpnKit:         vpnkit,
+		Provisioner:    provision.NewController(config),
+		Provision:      provisionCmd,
+		MetaDataReader: metaDataReader,
+		Stop:           stopCmd,
+		Profiler:       &profiler.SystemProfiler{},
+	}
+
 	dev := &cobra.Command{
 		Use:           "dev",
 		Short:         "Start and stop a single vm CF deployment running on your workstation",
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
+	dev.PersistentFlags().StringP(instanceFlag, instanceShorthand, "", "name of the cf dev instance to act on")
 	root.AddCommand(dev)
 
 	for _, cmd := range []cmdBuilder{
@@ -126,6 +224,7 @@ func NewRoot(exit chan struct{}, ui UI, config config.Config, analyticsClient An
 			UI:          ui,
 			Config:      config,
 			Analytics:   analyticsClient,
+			Director:    provision.NewController(config),
 		},
 		&b3.Catalog{
 			UI:     ui,
@@ -137,64 +236,131 @@ func NewRoot(exit chan struct{}, ui UI, config config.Config, analyticsClient An
 			Config: config,
 			Env:    &env.Env{Config: config},
 		},
-		&b5.Start{
-			Exit:            exit,
-			LocalExit:       make(chan string, 3),
-			UI:              ui,
-			Config:          config,
-			Cache:           cache,
-			Env:             &env.Env{Config: config},
-			Analytics:       analyticsClient,
-			AnalyticsToggle: analyticsToggle,
-			HostNet:         hostnet,
+		startCmd,
+		stopCmd,
+		telemetryCmd,
+		provisionCmd,
+		&b9.DeployService{
+			UI:             ui,
+			Provisioner:    provision.NewController(config),
+			MetaDataReader: metaDataReader,
+			Config:         config,
+			Analytics:      analyticsClient,
+		},
+		&b10.Prune{
+			UI:          ui,
+			Provisioner: provision.NewController(config),
+		},
+		&b11.Doctor{
+			UI: ui,
 			Host: &host.Host{
 				Powershell: &runner.Powershell{},
 			},
-			AnalyticsD:     analyticsD,
-			CFDevD:         &network.CFDevD{ExecutablePath: filepath.Join(config.CacheDir, "cfdevd")},
-			Hypervisor:     &hypervisor.HyperV{Config: config},
-			VpnKit:         vpnkit,
+			Quirks: &quirks.Quirks{
+				Powershell: &runner.Powershell{},
+			},
+			Hypervisor: vmBackend,
+			Network:    network.Probe{},
+			Config:     config,
+		},
+		&b12.Quotas{
+			UI:          ui,
+			Provisioner: provision.NewController(config),
+		},
+		&b13.Reset{
+			UI:    ui,
+			Stop:  stopCmd,
+			Start: startCmd,
+		},
+		&b14.Status{
+			UI:          ui,
+			Provisioner: provision.NewController(config),
+			Hypervisor:  vmBackend,
+			Analytics:   analyticsClient,
+			Config:      config,
+		},
+		&b15.Wait{
+			UI:             ui,
 			Provisioner:    provision.NewController(config),
-			Provision:      provisionCmd,
 			MetaDataReader: metaDataReader,
-			Stop: &b6.Stop{
-				Config:     config,
-				Analytics:  analyticsClient,
-				Hypervisor: &hypervisor.HyperV{Config: config},
-				VpnKit:     vpnkit,
-				HostNet:    hostnet,
-				Host: &host.Host{
-					Powershell: &runner.Powershell{},
-				},
-				AnalyticsD: analyticsD,
-			},
-			Profiler: &profiler.SystemProfiler{},
+			Config:         config,
 		},
-		&b6.Stop{
+		&b16.Export{
+			UI:         ui,
+			Hypervisor: vmBackend,
 			Config:     config,
-			Analytics:  analyticsClient,
-			Hypervisor: &hypervisor.HyperV{Config: config},
-			VpnKit:     vpnkit,
-			HostNet:    hostnet,
-			Host: &host.Host{
-				Powershell: &runner.Powershell{},
-			},
-			AnalyticsD: analyticsD,
 		},
-		&b7.Telemetry{
-			UI:              ui,
-			Analytics:       analyticsClient,
-			AnalyticsToggle: analyticsToggle,
-			AnalyticsD:      analyticsD,
+		&b16.Import{
+			UI:         ui,
+			Hypervisor: vmBackend,
+			Env:        &env.Env{Config: config},
+			Config:     config,
 		},
-		provisionCmd,
-		&b9.DeployService{
+		&b17.Reconfigure{
+			UI:          ui,
+			Hypervisor:  vmBackend,
+			Provisioner: provision.NewController(config),
+			Config:      config,
+		},
+		&b18.Creds{
+			UI:       ui,
+			Config:   config,
+			Keychain: &creds.Keychain{},
+			CredHub:  credhub.New(config),
+		},
+		&b19.RunErrand{
+			UI:          ui,
+			Provisioner: provision.NewController(config),
+		},
+		&b20.RemoveService{
 			UI:             ui,
 			Provisioner:    provision.NewController(config),
 			MetaDataReader: metaDataReader,
 			Config:         config,
 			Analytics:      analyticsClient,
 		},
+		&b21.BoshSSH{
+			Provisioner: provision.NewController(config),
+		},
+		&b22.Configure{
+			UI:        ui,
+			Telemetry: telemetryCmd,
+			Stop:      stopCmd,
+			Env:       &env.Env{Config: config},
+			Config:    config,
+		},
+		&b23.Logs{
+			UI:          ui,
+			Hypervisor:  vmBackend,
+			Provisioner: provision.NewController(config),
+			Config:      config,
+			Exit:        exit,
+		},
+		&b24.SSH{
+			Hypervisor:  vmBackend,
+			Provisioner: provision.NewController(config),
+			Config:      config,
+		},
+		&b25.Restart{
+			UI: ui,
+			Host: &host.Host{
+				Powershell: &runner.Powershell{},
+			},
+			Hypervisor:      vmBackend,
+			VpnKit:          vpnkit,
+			HostNet:         hostnet,
+			AnalyticsD:      analyticsD,
+			AnalyticsToggle: analyticsToggle,
+			Provisioner:     provision.NewController(config),
+			Config:          config,
+		},
+		&b26.SelfUpdate{
+			UI:     ui,
+			Config: config,
+			HttpDo: http.DefaultClient.Do,
+			Plugin: b26.CFPlugin{},
+			GOOS:   runtime.GOOS,
+		},
 	} {
 		dev.AddCommand(cmd.Cmd())
 	}
@@ -0,0 +1,87 @@
+package run_errand
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/run-errand UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/run-errand Provisioner
+type Provisioner interface {
+	Ping() error
+	RunErrand(deployment, errand string) ([]bosh.ErrandResult, error)
+}
+
+type Args struct {
+	Deployment string
+	Errand     string
+}
+
+// RunErrand runs an errand of a deployment again, e.g. CF's smoke tests.
+type RunErrand struct {
+	UI          UI
+	Provisioner Provisioner
+}
+
+func (r *RunErrand) Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "run-errand DEPLOYMENT ERRAND",
+		Short:   "Run a BOSH errand, e.g. smoke tests",
+		Example: "cf dev run-errand cf smoke-tests",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := r.Execute(Args{Deployment: args[0], Errand: args[1]}); err != nil {
+				return e.SafeWrap(err, "cf dev run-errand")
+			}
+			return nil
+		},
+	}
+}
+
+func (r *RunErrand) Execute(args Args) error {
+	if err := r.Provisioner.Ping(); err != nil {
+		return e.SafeWrap(err, "cf dev is not running")
+	}
+
+	r.UI.Say(messages.T("run-errand.running", map[string]interface{}{
+		"Errand":     args.Errand,
+		"Deployment": args.Deployment,
+	}))
+
+	results, err := r.Provisioner.RunErrand(args.Deployment, args.Errand)
+	if err != nil {
+		return e.SafeWrap(err, "running errand")
+	}
+
+	failed := false
+	for _, result := range results {
+		if stdout := strings.TrimSpace(result.Stdout); stdout != "" {
+			r.UI.Say(messages.T("run-errand.stdout", map[string]interface{}{"Instance": result.Instance}))
+			r.UI.Say(stdout)
+		}
+		if stderr := strings.TrimSpace(result.Stderr); stderr != "" {
+			r.UI.Say(messages.T("run-errand.stderr", map[string]interface{}{"Instance": result.Instance}))
+			r.UI.Say(stderr)
+		}
+
+		r.UI.Say(messages.T("run-errand.exited", map[string]interface{}{
+			"Instance": result.Instance,
+			"ExitCode": result.ExitCode,
+		}))
+		failed = failed || result.ExitCode != 0
+	}
+
+	if failed {
+		return fmt.Errorf("errand %s failed", args.Errand)
+	}
+	return nil
+}
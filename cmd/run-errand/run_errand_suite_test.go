@@ -0,0 +1,13 @@
+package run_errand_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestRunErrand(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RunErrand Suite")
+}
@@ -0,0 +1,88 @@
+package run_errand_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/cmd/run-errand"
+	"code.cloudfoundry.org/cfdev/cmd/run-errand/mocks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RunErrand", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockProvisioner *mocks.MockProvisioner
+		subject         *run_errand.RunErrand
+		args            run_errand.Args
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+
+		subject = &run_errand.RunErrand{
+			UI:          mockUI,
+			Provisioner: mockProvisioner,
+		}
+		args = run_errand.Args{Deployment: "cf", Errand: "smoke-tests"}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("runs the errand and prints its output", func() {
+		gomock.InOrder(
+			mockProvisioner.EXPECT().Ping(),
+			mockUI.EXPECT().Say("Running errand smoke-tests of cf..."),
+			mockProvisioner.EXPECT().RunErrand("cf", "smoke-tests").Return([]bosh.ErrandResult{
+				{Instance: "smoke-tests/abc", ExitCode: 0, Stdout: "all passed\n"},
+			}, nil),
+			mockUI.EXPECT().Say("Stdout from smoke-tests/abc:"),
+			mockUI.EXPECT().Say("all passed"),
+			mockUI.EXPECT().Say("smoke-tests/abc exited with 0"),
+		)
+
+		Expect(subject.Execute(args)).To(Succeed())
+	})
+
+	Context("when the errand exits non-zero", func() {
+		It("prints stderr and returns an error", func() {
+			gomock.InOrder(
+				mockProvisioner.EXPECT().Ping(),
+				mockUI.EXPECT().Say("Running errand smoke-tests of cf..."),
+				mockProvisioner.EXPECT().RunErrand("cf", "smoke-tests").Return([]bosh.ErrandResult{
+					{Instance: "smoke-tests/abc", ExitCode: 1, Stderr: "push failed"},
+				}, nil),
+				mockUI.EXPECT().Say("Stderr from smoke-tests/abc:"),
+				mockUI.EXPECT().Say("push failed"),
+				mockUI.EXPECT().Say("smoke-tests/abc exited with 1"),
+			)
+
+			Expect(subject.Execute(args)).To(MatchError("errand smoke-tests failed"))
+		})
+	})
+
+	Context("when the errand cannot be run", func() {
+		It("returns the error", func() {
+			mockProvisioner.EXPECT().Ping()
+			mockUI.EXPECT().Say(gomock.Any())
+			mockProvisioner.EXPECT().RunErrand("cf", "smoke-tests").Return(nil, errors.New("some-error"))
+
+			Expect(subject.Execute(args)).To(MatchError("running errand: some-error"))
+		})
+	})
+
+	Context("when cf dev is not running", func() {
+		It("returns an error", func() {
+			mockProvisioner.EXPECT().Ping().Return(errors.New("some-error"))
+
+			Expect(subject.Execute(args)).To(MatchError("cf dev is not running: some-error"))
+		})
+	})
+})
@@ -0,0 +1,200 @@
+package self_update
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+	"code.cloudfoundry.org/cfdev/semver"
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/self-update UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/plugin.go code.cloudfoundry.org/cfdev/cmd/self-update Plugin
+type Plugin interface {
+	Install(path string) error
+}
+
+// Manifest is the manifest.json generate-release.sh writes beside the
+// binaries of a release.
+type Manifest struct {
+	Version  string `json:"version"`
+	Binaries []struct {
+		Platform string `json:"platform"`
+		Name     string `json:"name"`
+		SHA256   string `json:"sha256"`
+	} `json:"binaries"`
+}
+
+// SelfUpdate replaces the plugin with the latest release's binary for this
+// platform.
+type SelfUpdate struct {
+	UI     UI
+	Config config.Config
+	HttpDo func(req *http.Request) (*http.Response, error)
+	Plugin Plugin
+	// GOOS is the platform the plugin runs on, which decides whether it
+	// can replace itself.
+	GOOS string
+}
+
+func (s *SelfUpdate) Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "self-update",
+		Short: "Update cf dev to the latest release",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := s.Execute(); err != nil {
+				return e.SafeWrap(err, "cf dev self-update")
+			}
+			return nil
+		},
+	}
+}
+
+func (s *SelfUpdate) Execute() error {
+	if s.Config.ReleaseManifestURL == "" {
+		return errors.New("this build does not know where releases are published, set CFDEV_RELEASE_MANIFEST to the url of a release's manifest.json")
+	}
+
+	manifest, err := s.manifest()
+	if err != nil {
+		return e.SafeWrap(err, "reading the release manifest")
+	}
+
+	latest, err := semver.New(manifest.Version)
+	if err != nil {
+		return fmt.Errorf("the release manifest has an invalid version %q", manifest.Version)
+	}
+	if !s.Config.CliVersion.LessThan(latest) {
+		s.UI.Say(messages.T("self-update.up-to-date", map[string]interface{}{"Version": s.Config.CliVersion.Original}))
+		return nil
+	}
+
+	platform := runtime.GOOS + "-" + runtime.GOARCH
+	for _, binary := range manifest.Binaries {
+		if binary.Platform != platform {
+			continue
+		}
+
+		s.UI.Say(messages.T("self-update.downloading", map[string]interface{}{"Version": manifest.Version}))
+		path, err := s.download(binary.Name, binary.SHA256)
+		if err != nil {
+			return e.SafeWrap(err, "downloading "+binary.Name)
+		}
+
+		if s.GOOS == "windows" {
+			s.UI.Say(messages.T("self-update.install", map[string]interface{}{"Version": manifest.Version, "Path": path}))
+			return nil
+		}
+
+		defer os.RemoveAll(filepath.Dir(path))
+		s.UI.Say(messages.T("self-update.installing", map[string]interface{}{"Version": manifest.Version}))
+		if err := s.Plugin.Install(path); err != nil {
+			return e.SafeWrap(err, "installing the plugin")
+		}
+		s.UI.Say(messages.T("self-update.done", map[string]interface{}{"Version": manifest.Version}))
+		return nil
+	}
+
+	return fmt.Errorf("release %s has no binary for %s", manifest.Version, platform)
+}
+
+func (s *SelfUpdate) get(url string) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.HttpDo(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (s *SelfUpdate) manifest() (Manifest, error) {
+	body, err := s.get(s.Config.ReleaseManifestURL)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer body.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(body).Decode(&manifest); err != nil {
+		return Manifest{}, err
+	}
+	return manifest, nil
+}
+
+// download fetches the binary name, which is relative to the manifest, and
+// checks it against the manifest's checksum before it is installed.
+func (s *SelfUpdate) download(name, sha string) (string, error) {
+	base, err := url.Parse(s.Config.ReleaseManifestURL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(name)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := s.get(base.ResolveReference(ref).String())
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	dir, err := ioutil.TempDir("", "cfdev-self-update")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, filepath.Base(ref.Path))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0755)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hash), body); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != sha {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("sha256 of %s is %s, expected %s", name, actual, sha)
+	}
+	return path, nil
+}
+
+// CFPlugin installs the plugin with the cf CLI that runs it.
+type CFPlugin struct{}
+
+func (CFPlugin) Install(path string) error {
+	output, err := exec.Command("cf", "install-plugin", "-f", path).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %s", err, output)
+	}
+	return nil
+}
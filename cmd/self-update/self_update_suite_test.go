@@ -0,0 +1,13 @@
+package self_update_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSelfUpdate(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cmd SelfUpdate Suite")
+}
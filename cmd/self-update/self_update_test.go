@@ -0,0 +1,124 @@
+package self_update_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+
+	"code.cloudfoundry.org/cfdev/cmd/self-update"
+	"code.cloudfoundry.org/cfdev/cmd/self-update/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/semver"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SelfUpdate", func() {
+	var (
+		mockController *gomock.Controller
+		mockUI         *mocks.MockUI
+		mockPlugin     *mocks.MockPlugin
+		server         *httptest.Server
+		binary         string
+		binarySHA      string
+		subject        *self_update.SelfUpdate
+	)
+
+	platform := runtime.GOOS + "-" + runtime.GOARCH
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockPlugin = mocks.NewMockPlugin(mockController)
+
+		binary = "some-binary"
+		sum := sha256.Sum256([]byte(binary))
+		binarySHA = hex.EncodeToString(sum[:])
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/releases/latest/manifest.json":
+				fmt.Fprintf(w, `{"version": "0.2.0", "binaries": [
+					{"platform": "plan9-amd64", "name": "cf-dev-plugin-0.2.0-plan9-amd64", "sha256": "other"},
+					{"platform": %q, "name": "cf-dev-plugin-0.2.0-%s", "sha256": %q}
+				]}`, platform, platform, binarySHA)
+			case "/releases/latest/cf-dev-plugin-0.2.0-" + platform:
+				fmt.Fprint(w, binary)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+
+		subject = &self_update.SelfUpdate{
+			UI: mockUI,
+			Config: config.Config{
+				CliVersion:         semver.Must(semver.New("0.1.0")),
+				ReleaseManifestURL: server.URL + "/releases/latest/manifest.json",
+			},
+			HttpDo: http.DefaultClient.Do,
+			Plugin: mockPlugin,
+			GOOS:   "linux",
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		mockController.Finish()
+	})
+
+	It("installs this platform's binary of the latest release", func() {
+		gomock.InOrder(
+			mockUI.EXPECT().Say("Downloading cf dev 0.2.0..."),
+			mockUI.EXPECT().Say("Installing cf dev 0.2.0..."),
+			mockPlugin.EXPECT().Install(gomock.Any()).Do(func(path string) {
+				Expect(ioutil.ReadFile(path)).To(Equal([]byte(binary)))
+			}),
+			mockUI.EXPECT().Say("Updated cf dev to 0.2.0"),
+		)
+
+		Expect(subject.Execute()).To(Succeed())
+	})
+
+	Context("when the plugin is already the latest release", func() {
+		It("leaves it", func() {
+			subject.Config.CliVersion = semver.Must(semver.New("0.2.0"))
+			mockUI.EXPECT().Say("cf dev 0.2.0 is the latest release")
+
+			Expect(subject.Execute()).To(Succeed())
+		})
+	})
+
+	Context("when the binary does not match its checksum", func() {
+		It("does not install it", func() {
+			binarySHA = "0000"
+			mockUI.EXPECT().Say("Downloading cf dev 0.2.0...")
+
+			Expect(subject.Execute()).To(MatchError(ContainSubstring("expected 0000")))
+		})
+	})
+
+	Context("when the plugin runs on windows", func() {
+		It("tells the user to install the download", func() {
+			subject.GOOS = "windows"
+			mockUI.EXPECT().Say("Downloading cf dev 0.2.0...")
+			mockUI.EXPECT().Say(gomock.Any()).Do(func(message string) {
+				Expect(message).To(ContainSubstring("cf install-plugin -f "))
+			})
+
+			Expect(subject.Execute()).To(Succeed())
+		})
+	})
+
+	Context("when the build has no release manifest", func() {
+		It("says how to set one", func() {
+			subject.Config.ReleaseManifestURL = ""
+
+			Expect(subject.Execute()).To(MatchError(ContainSubstring("CFDEV_RELEASE_MANIFEST")))
+		})
+	})
+})
@@ -0,0 +1,63 @@
+package ssh
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"github.com/spf13/cobra"
+)
+
+//go:generate mockgen -package mocks -destination mocks/hypervisor.go code.cloudfoundry.org/cfdev/cmd/ssh Hypervisor
+type Hypervisor interface {
+	IsRunning(vmName string) (bool, error)
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/ssh Provisioner
+type Provisioner interface {
+	VMShell(cmd []string) error
+}
+
+type Args struct {
+	Command []string
+}
+
+// SSH opens a shell in, or runs a command in, the cf dev vm itself, e.g. to
+// debug garden or the bosh agent without the hypervisor's console.
+type SSH struct {
+	Hypervisor  Hypervisor
+	Provisioner Provisioner
+	Config      config.Config
+}
+
+func (s *SSH) Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ssh [-- COMMAND...]",
+		Short: "SSH into the CF Dev vm",
+		Long: "Open a root shell in the vm CF Dev runs everything in, or run a command in it. " +
+			"Use bosh-ssh for the vms of the deployments.",
+		Example: `cf dev ssh
+cf dev ssh -- df -h`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := s.Execute(Args{Command: args}); err != nil {
+				return e.SafeWrap(err, "cf dev ssh")
+			}
+			return nil
+		},
+	}
+}
+
+func (s *SSH) Execute(args Args) error {
+	running, err := s.Hypervisor.IsRunning(s.Config.VMName())
+	if err != nil {
+		return e.SafeWrap(err, "checking the vm")
+	}
+	if !running {
+		return errors.New("cf dev is not running")
+	}
+
+	if err := s.Provisioner.VMShell(args.Command); err != nil {
+		return e.SafeWrap(err, "ssh to the vm")
+	}
+	return nil
+}
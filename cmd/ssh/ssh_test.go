@@ -0,0 +1,74 @@
+package ssh_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cfdev/cmd/ssh"
+	"code.cloudfoundry.org/cfdev/cmd/ssh/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SSH", func() {
+	var (
+		mockController  *gomock.Controller
+		mockHypervisor  *mocks.MockHypervisor
+		mockProvisioner *mocks.MockProvisioner
+		subject         *ssh.SSH
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockHypervisor = mocks.NewMockHypervisor(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+
+		subject = &ssh.SSH{
+			Hypervisor:  mockHypervisor,
+			Provisioner: mockProvisioner,
+			Config:      config.Config{},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("opens a shell in the vm", func() {
+		gomock.InOrder(
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil),
+			mockProvisioner.EXPECT().VMShell(nil),
+		)
+
+		Expect(subject.Execute(ssh.Args{})).To(Succeed())
+	})
+
+	It("runs the command in the vm", func() {
+		gomock.InOrder(
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil),
+			mockProvisioner.EXPECT().VMShell([]string{"df", "-h"}),
+		)
+
+		Expect(subject.Execute(ssh.Args{Command: []string{"df", "-h"}})).To(Succeed())
+	})
+
+	It("returns the ssh error", func() {
+		mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil)
+		mockProvisioner.EXPECT().VMShell(nil).Return(errors.New("some-error"))
+
+		Expect(subject.Execute(ssh.Args{})).To(MatchError(ContainSubstring("some-error")))
+	})
+
+	It("returns an error when the vm is not running", func() {
+		mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil)
+
+		Expect(subject.Execute(ssh.Args{})).To(MatchError(ContainSubstring("cf dev is not running")))
+	})
+
+	It("returns an error when the vm cannot be checked", func() {
+		mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, errors.New("some-error"))
+
+		Expect(subject.Execute(ssh.Args{})).To(MatchError(ContainSubstring("some-error")))
+	})
+})
@@ -58,6 +58,18 @@ func (mr *MockHypervisorMockRecorder) IsRunning(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRunning", reflect.TypeOf((*MockHypervisor)(nil).IsRunning), arg0)
 }
 
+// Preflight mocks base method
+func (m *MockHypervisor) Preflight(arg0 hypervisor.VM) []hypervisor.Finding {
+	ret := m.ctrl.Call(m, "Preflight", arg0)
+	ret0, _ := ret[0].([]hypervisor.Finding)
+	return ret0
+}
+
+// Preflight indicates an expected call of Preflight
+func (mr *MockHypervisorMockRecorder) Preflight(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preflight", reflect.TypeOf((*MockHypervisor)(nil).Preflight), arg0)
+}
+
 // Start mocks base method
 func (m *MockHypervisor) Start(arg0 string) error {
 	ret := m.ctrl.Call(m, "Start", arg0)
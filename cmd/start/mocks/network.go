@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	config "code.cloudfoundry.org/cfdev/config"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 )
@@ -47,3 +48,16 @@ func (m *MockHostNet) AddLoopbackAliases(arg0 ...string) error {
 func (mr *MockHostNetMockRecorder) AddLoopbackAliases(arg0 ...interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLoopbackAliases", reflect.TypeOf((*MockHostNet)(nil).AddLoopbackAliases), arg0...)
 }
+
+// Routes mocks base method
+func (m *MockHostNet) Routes() ([]config.Route, error) {
+	ret := m.ctrl.Call(m, "Routes")
+	ret0, _ := ret[0].([]config.Route)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Routes indicates an expected call of Routes
+func (mr *MockHostNetMockRecorder) Routes() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Routes", reflect.TypeOf((*MockHostNet)(nil).Routes))
+}
@@ -33,6 +33,16 @@ func (m *MockProvision) EXPECT() *MockProvisionMockRecorder {
 	return m.recorder
 }
 
+// Cancel mocks base method
+func (m *MockProvision) Cancel() {
+	m.ctrl.Call(m, "Cancel")
+}
+
+// Cancel indicates an expected call of Cancel
+func (mr *MockProvisionMockRecorder) Cancel() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockProvision)(nil).Cancel))
+}
+
 // Execute mocks base method
 func (m *MockProvision) Execute(arg0 start.Args) error {
 	ret := m.ctrl.Call(m, "Execute", arg0)
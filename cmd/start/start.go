@@ -1,18 +1,21 @@
 package start
 
 import (
+	"errors"
 	"io"
-	"time"
 
+	"code.cloudfoundry.org/cfdev/messages"
 	"code.cloudfoundry.org/cfdev/metadata"
 
 	"code.cloudfoundry.org/cfdev/config"
 	e "code.cloudfoundry.org/cfdev/errors"
 	"code.cloudfoundry.org/cfdev/provision"
+	"code.cloudfoundry.org/cfdev/quirks"
 	"code.cloudfoundry.org/cfdev/resource"
 	"fmt"
 	"github.com/spf13/cobra"
 	"os"
+	"runtime"
 	"strings"
 
 	"code.cloudfoundry.org/cfdev/cfanalytics"
@@ -49,6 +52,7 @@ type SystemProfiler interface {
 //go:generate mockgen -package mocks -destination mocks/network.go code.cloudfoundry.org/cfdev/cmd/start HostNet
 type HostNet interface {
 	AddLoopbackAliases(...string) error
+	Routes() ([]config.Route, error)
 }
 
 //go:generate mockgen -package mocks -destination mocks/host.go code.cloudfoundry.org/cfdev/cmd/start Host
@@ -56,6 +60,11 @@ type Host interface {
 	CheckRequirements() error
 }
 
+//go:generate mockgen -package mocks -destination mocks/quirks.go code.cloudfoundry.org/cfdev/cmd/start Quirks
+type Quirks interface {
+	Detect() []quirks.Quirk
+}
+
 //go:generate mockgen -package mocks -destination mocks/cache.go code.cloudfoundry.org/cfdev/cmd/start Cache
 type Cache interface {
 	Sync(resource.Catalog) error
@@ -82,12 +91,19 @@ type AnalyticsD interface {
 
 //go:generate mockgen -package mocks -destination mocks/hypervisor.go code.cloudfoundry.org/cfdev/cmd/start Hypervisor
 type Hypervisor interface {
+	Preflight(vm hypervisor.VM) []hypervisor.Finding
 	CreateVM(vm hypervisor.VM) error
 	Start(vmName string) error
 	Stop(vmName string) error
 	IsRunning(vmName string) (bool, error)
 }
 
+// DryRunner is a Hypervisor that can print the commands it would run,
+// e.g. Hyper-V's powershell, instead of running them.
+type DryRunner interface {
+	DryRunTo(out io.Writer)
+}
+
 //go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/start Provisioner
 type Provisioner interface {
 	Ping() error
@@ -96,6 +112,7 @@ type Provisioner interface {
 //go:generate mockgen -package mocks -destination mocks/provision.go code.cloudfoundry.org/cfdev/cmd/start Provision
 type Provision interface {
 	Execute(args Args) error
+	Cancel()
 }
 
 //go:generate mockgen -package mocks -destination mocks/isoreader.go code.cloudfoundry.org/cfdev/cmd/start MetaDataReader
@@ -121,6 +138,10 @@ type Args struct {
 	NoProvision         bool
 	Cpus                int
 	Mem                 int
+	Debug               bool
+	JSON                bool
+	DryRun              bool
+	VM                  config.VMConfig
 }
 
 type Start struct {
@@ -133,6 +154,7 @@ type Start struct {
 	AnalyticsToggle Toggle
 	HostNet         HostNet
 	Host            Host
+	Quirks          Quirks
 	Cache           Cache
 	CFDevD          CFDevD
 	VpnKit          VpnKit
@@ -143,6 +165,8 @@ type Start struct {
 	Provision       Provision
 	Env             Env
 	Profiler        SystemProfiler
+	// NumCPU counts the host's cpus. Without it, runtime.NumCPU is used.
+	NumCPU func() int
 }
 
 const compatibilityVersion = "v3"
@@ -162,11 +186,24 @@ func (s *Start) Cmd() *cobra.Command {
 
 	pf := cmd.PersistentFlags()
 	pf.StringVarP(&args.DepsPath, "file", "f", "", "path to .dev file containing bosh & cf bits")
-	pf.StringVarP(&args.Registries, "registries", "r", "", "docker registries that skip ssl validation - ie. host:port,host2:port2")
-	pf.IntVarP(&args.Cpus, "cpus", "c", 4, "cpus to allocate to vm")
-	pf.IntVarP(&args.Mem, "memory", "m", 0, "memory to allocate to vm in MB")
+	// The defaults come from the config file and environment, which the
+	// flags override.
+	pf.StringVarP(&args.Registries, "registries", "r", strings.Join(s.Config.Registries, ","), "docker registries that skip ssl validation - ie. host:port,host2:port2")
+	pf.IntVarP(&args.Cpus, "cpus", "c", s.Config.Cpus, "cpus to allocate to vm, half of the host's up to 4 when 0")
+	pf.IntVarP(&args.Mem, "memory", "m", s.Config.MemoryMB, "memory to allocate to vm in MB, half of the host's up to 8GB when 0")
 	pf.BoolVarP(&args.NoProvision, "no-provision", "n", false, "start vm but do not provision")
-	pf.StringVarP(&args.DeploySingleService, "white-listed-services", "s", "", "list of supported services to deploy")
+	pf.StringVarP(&args.DeploySingleService, "white-listed-services", "s", strings.Join(s.Config.Services, ","), "list of supported services to deploy")
+	pf.BoolVar(&args.Debug, "debug", false, "show the BOSH director's task logs while deploying")
+	pf.BoolVar(&args.JSON, "json", false, "print deploy progress as lines of json")
+	pf.IntVar(&args.VM.DataDiskMB, "data-disk", s.Config.VM.DataDiskMB, "size in MB of a disk kept in the cfdev home's data directory and attached to the vm, none when 0")
+	pf.IntVar(&args.VM.MinMemoryMB, "min-memory", s.Config.VM.MinMemoryMB, "least memory in MB Hyper-V may shrink the vm to, with --max-memory")
+	pf.IntVar(&args.VM.MaxMemoryMB, "max-memory", s.Config.VM.MaxMemoryMB, "most memory in MB Hyper-V may grow the vm to, with --min-memory")
+	pf.BoolVar(&args.VM.NestedVirtualization, "nested-virtualization", s.Config.VM.NestedVirtualization, "let the vm run hypervisors of its own (Hyper-V only)")
+	pf.BoolVar(&args.VM.SecureBoot, "secure-boot", s.Config.VM.SecureBoot, "have the firmware verify the vm's boot loader (Hyper-V only)")
+	pf.BoolVar(&args.VM.TPM, "tpm", s.Config.VM.TPM, "give the vm a virtual TPM (Hyper-V only)")
+	pf.BoolVar(&args.DryRun, "dry-run", false, "print the commands that would create and start the vm instead of running them (Hyper-V only)")
+	// The profile is applied to the config before the commands are built.
+	pf.String("profile", "", "name of a profile in the cfdev home's profiles directory to start with")
 
 	pf.MarkHidden("no-provision")
 	return cmd
@@ -176,11 +213,11 @@ func (s *Start) Execute(args Args) error {
 	go func() {
 		select {
 		case <-s.Exit:
-			// no-op
+			s.Provision.Cancel()
 		case name := <-s.LocalExit:
-			s.UI.Say("ERROR: %s has stopped", name)
+			s.UI.Say(messages.T("start.stopped-unexpectedly", map[string]interface{}{"Name": name}))
 		}
-		s.Hypervisor.Stop("cfdev")
+		s.Hypervisor.Stop(s.Config.VMName())
 		s.VpnKit.Stop()
 		os.Exit(128)
 	}()
@@ -217,13 +254,50 @@ func (s *Start) Execute(args Args) error {
 		return err
 	}
 
-	if running, err := s.Hypervisor.IsRunning("cfdev"); err != nil {
+	for _, quirk := range s.Quirks.Detect() {
+		s.UI.Say(messages.T("start.quirk", map[string]interface{}{"Guidance": quirk.Guidance}))
+	}
+
+	if running, err := s.Hypervisor.IsRunning(s.Config.VMName()); err != nil {
 		return e.SafeWrap(err, "is running")
 	} else if running {
-		s.UI.Say("CF Dev is already running...")
+		s.UI.Say(messages.T("start.already-running"))
 		s.Analytics.Event(cfanalytics.START_END, map[string]interface{}{"alreadyrunning": true})
 		return nil
 	}
+	if err := s.Config.CheckNoOtherInstance(); err != nil {
+		return err
+	}
+
+	numCPU := runtime.NumCPU
+	if s.NumCPU != nil {
+		numCPU = s.NumCPU
+	}
+	// Routes that cannot be read are left out, rather than keeping cf dev
+	// from starting on a host that has none in its way.
+	routes, _ := s.HostNet.Routes()
+	host := config.HostCapacity{TotalMemoryMB: tMem, CPUs: numCPU(), Routes: routes}
+	if host.BelowMinimum() {
+		s.UI.Say(messages.T("start.below-minimum-host", map[string]interface{}{"Memory": config.MinHostMemoryMB, "Cpus": config.MinHostCPUs}))
+	}
+	if args.Cpus <= 0 {
+		args.Cpus = config.DefaultCPUs(host)
+	}
+
+	// The flags win over the config, so it is what they ask for that has to
+	// fit the host.
+	requested := s.Config
+	requested.MemoryMB = args.Mem
+	requested.Cpus = args.Cpus
+	requested.VM = args.VM
+	if err := requested.Validate(host); err != nil {
+		return err
+	}
+	s.Config.VM = args.VM
+
+	if args.DryRun {
+		return s.dryRun(args, host)
+	}
 
 	if err := s.Stop.RunE(nil, nil); err != nil {
 		return e.SafeWrap(err, "stopping cfdev")
@@ -232,9 +306,12 @@ func (s *Start) Execute(args Args) error {
 	if err := s.Env.CreateDirs(); err != nil {
 		return e.SafeWrap(err, "setting up cfdev home dir")
 	}
+	if err := s.Config.MarkActive(); err != nil {
+		return err
+	}
 
 	if cfdevd := s.Config.Dependencies.Lookup("cfdevd"); cfdevd != nil {
-		s.UI.Say("Downloading Network Helper...")
+		s.UI.Say(messages.T("start.downloading-helper"))
 		if err := s.Cache.Sync(resource.Catalog{
 			Items: []resource.Item{*cfdevd},
 		}); err != nil {
@@ -251,12 +328,12 @@ func (s *Start) Execute(args Args) error {
 		return e.SafeWrap(err, "adding aliases")
 	}
 
-	s.UI.Say("Downloading Resources...")
+	s.UI.Say(messages.T("start.downloading-resources"))
 	if err := s.Cache.Sync(s.Config.Dependencies); err != nil {
 		return e.SafeWrap(err, "Unable to sync assets")
 	}
 
-	s.UI.Say("Setting State...")
+	s.UI.Say(messages.T("start.setting-state"))
 	if err := s.Env.SetupState(); err != nil {
 		return e.SafeWrap(err, "Unable to setup directories")
 	}
@@ -271,10 +348,12 @@ func (s *Start) Execute(args Args) error {
 
 	s.Analytics.PromptOptInIfNeeded(metaData.AnalyticsMessage)
 
+	hardware := cfanalytics.HostClass(tMem, args.Cpus)
+
 	s.Analytics.Event(cfanalytics.START_BEGIN, map[string]interface{}{
 		"total memory":     tMem,
 		"available memory": aMem,
-	})
+	}, hardware)
 
 	if args.DeploySingleService != "" {
 		if !s.isServiceSupported(args.DeploySingleService, metaData.Services) {
@@ -283,38 +362,39 @@ func (s *Start) Execute(args Args) error {
 		s.Analytics.Event(cfanalytics.SELECTED_SERVICE, map[string]interface{}{"services_requested": args.DeploySingleService})
 	}
 
-	memoryToAllocate, err := s.allocateMemory(metaData, args.Mem)
+	memoryToAllocate, err := s.allocateMemory(metaData, args.Mem, host)
 	if err != nil {
 		return err
 	}
 
-	s.UI.Say("Creating the VM...")
-	if err := s.Hypervisor.CreateVM(hypervisor.VM{
-		Name:     "cfdev",
-		CPUs:     args.Cpus,
-		MemoryMB: memoryToAllocate,
-	}); err != nil {
+	vm := s.vm(args.Cpus, memoryToAllocate)
+	if err := s.preflight(vm); err != nil {
+		return err
+	}
+
+	s.UI.Say(messages.T("start.creating-vm"))
+	if err := s.Hypervisor.CreateVM(vm); err != nil {
 		return e.SafeWrap(err, "creating the vm")
 	}
-	s.UI.Say("Starting VPNKit...")
+	s.UI.Say(messages.T("start.starting-vpnkit"))
 	if err := s.VpnKit.Start(); err != nil {
 		return e.SafeWrap(err, "starting vpnkit")
 	}
 	s.VpnKit.Watch(s.LocalExit)
 
-	s.UI.Say("Starting the VM...")
-	if err := s.Hypervisor.Start("cfdev"); err != nil {
+	s.UI.Say(messages.T("start.starting-vm"))
+	if err := s.Hypervisor.Start(s.Config.VMName()); err != nil {
 		return e.SafeWrap(err, "starting the vm")
 	}
 
-	s.UI.Say("Waiting for the VM...")
-	err = s.waitForVM()
+	s.UI.Say(messages.T("start.waiting-for-vm"))
+	err = provision.WaitForVM(s.Provisioner.Ping, 0)
 	if err != nil {
 		return e.SafeWrap(err, "Timed out waiting for the VM")
 	}
 
 	if args.NoProvision {
-		s.UI.Say("VM will not be provisioned because '-n' (no-provision) flag was specified.")
+		s.UI.Say(messages.T("start.no-provision"))
 		return nil
 	}
 
@@ -331,19 +411,56 @@ func (s *Start) Execute(args Args) error {
 	return nil
 }
 
-func (s *Start) waitForVM() error {
-	timeout := 120
-	var err error
-	for i := 0; i < timeout; i++ {
-		err = s.Provisioner.Ping()
-		if err == nil {
-			return nil
-		}
+// dryRun prints the commands that would create and start the vm, changing
+// nothing on the host. The deps' metadata is read from the cache, where an
+// earlier start left it.
+func (s *Start) dryRun(args Args, host config.HostCapacity) error {
+	dryRunner, ok := s.Hypervisor.(DryRunner)
+	if !ok {
+		return errors.New("--dry-run is only supported with Hyper-V")
+	}
+
+	metaData, err := s.MetaDataReader.Read(filepath.Join(s.Config.CacheDir, "metadata.yml"))
+	if err != nil {
+		return e.SafeWrap(err, "reading the deps' metadata, which 'cf dev start' leaves in the cache")
+	}
+	memoryToAllocate, err := s.allocateMemory(metaData, args.Mem, host)
+	if err != nil {
+		return err
+	}
+
+	s.UI.Say(messages.T("start.dry-run"))
+	dryRunner.DryRunTo(s.UI.Writer())
+	if err := s.Hypervisor.CreateVM(s.vm(args.Cpus, memoryToAllocate)); err != nil {
+		return e.SafeWrap(err, "creating the vm")
+	}
+	return s.Hypervisor.Start(s.Config.VMName())
+}
 
-		time.Sleep(1 * time.Second)
+// vm is the vm to create with the given resources.
+func (s *Start) vm(cpus, memoryMB int) hypervisor.VM {
+	return hypervisor.NewVM(s.Config, cpus, memoryMB)
+}
+
+// preflight reports failed checks as warnings, unless any of them would
+// stop the vm from starting at all.
+func (s *Start) preflight(vm hypervisor.VM) error {
+	var fatal []string
+	for _, finding := range s.Hypervisor.Preflight(vm) {
+		if finding.Passed {
+			continue
+		}
+		if finding.Fatal {
+			fatal = append(fatal, finding.Message)
+			continue
+		}
+		s.UI.Say(messages.T("start.preflight", map[string]interface{}{"Message": finding.Message}))
 	}
 
-	return err
+	if len(fatal) > 0 {
+		return e.SafeWrap(errors.New(strings.Join(fatal, "\n")), "preflight checks failed")
+	}
+	return nil
 }
 
 func (s *Start) isServiceSupported(service string, services []provision.Service) bool {
@@ -370,7 +487,7 @@ func contains(services []provision.Service, service string) bool {
 	return false
 }
 
-func (s *Start) allocateMemory(metaData metadata.Metadata, requestedMem int) (int, error) {
+func (s *Start) allocateMemory(metaData metadata.Metadata, requestedMem int, host config.HostCapacity) (int, error) {
 	baseMem := defaultMemory
 	if metaData.DefaultMemory > 0 {
 		baseMem = metaData.DefaultMemory
@@ -389,29 +506,33 @@ func (s *Start) allocateMemory(metaData metadata.Metadata, requestedMem int) (in
 			}
 
 			if availableMem < uint64(requestedMem) {
-				s.UI.Say("WARNING: This machine may not have enough available RAM to run with what is specified.")
+				s.UI.Say(messages.T("start.low-available-memory"))
 				return requestedMem, nil
 			}
 		}
 
 		if requestedMem < baseMem {
-			s.UI.Say(fmt.Sprintf("WARNING: It is recommended that you run %s Dev with at least %v MB of RAM.", strings.ToUpper(metaData.DeploymentName), baseMem))
+			s.UI.Say(messages.T("start.below-recommended-memory", map[string]interface{}{"Deployment": strings.ToUpper(metaData.DeploymentName), "Memory": baseMem}))
 			if availableMem >= uint64(requestedMem) {
 				return requestedMem, nil
 			}
 
 			if availableMem < uint64(requestedMem) {
-				s.UI.Say("WARNING: This machine may not have enough available RAM to run with what is specified.")
+				s.UI.Say(messages.T("start.low-available-memory"))
 				return requestedMem, nil
 			}
 		}
 	} else {
-		if availableMem >= uint64(baseMem) {
-			return baseMem, nil
-		} else {
-			s.UI.Say(fmt.Sprintf("WARNING: %s Dev requires %v MB of RAM to run. This machine may not have enough free RAM.", strings.ToUpper(metaData.DeploymentName), baseMem))
-			return baseMem, nil
+		defaultMem := config.DefaultMemoryMB(host, baseMem)
+		if defaultMem < config.MinVMMemoryMB {
+			s.UI.Say(messages.T("start.below-required-memory", map[string]interface{}{"Deployment": strings.ToUpper(metaData.DeploymentName), "Memory": config.MinVMMemoryMB, "HostMemory": host.TotalMemoryMB}))
+			return defaultMem, nil
+		}
+
+		if availableMem < uint64(defaultMem) {
+			s.UI.Say(messages.T("start.low-available-memory"))
 		}
+		return defaultMem, nil
 	}
 
 	return 0, nil
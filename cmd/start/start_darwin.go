@@ -1,9 +1,12 @@
 package start
 
-import "code.cloudfoundry.org/cfdev/errors"
+import (
+	"code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+)
 
 func (s *Start) osSpecificSetup() error {
-	s.UI.Say("Installing cfdevd network helper...")
+	s.UI.Say(messages.T("start.installing-helper"))
 	if err := s.CFDevD.Install(); err != nil {
 		return errors.SafeWrap(err, "installing cfdevd")
 	}
@@ -0,0 +1,7 @@
+package start
+
+// osSpecificSetup has nothing to install on linux, where QEMU networks the
+// vm without a helper.
+func (s *Start) osSpecificSetup() error {
+	return nil
+}
@@ -1,6 +1,8 @@
 package start_test
 
 import (
+	"bytes"
+	"io"
 	"runtime"
 
 	mdata "code.cloudfoundry.org/cfdev/metadata"
@@ -30,6 +32,7 @@ var _ = Describe("Start", func() {
 		mockToggle          *mocks.MockToggle
 		mockHostNet         *mocks.MockHostNet
 		mockHost            *mocks.MockHost
+		mockQuirks          *mocks.MockQuirks
 		mockCache           *mocks.MockCache
 		mockCFDevD          *mocks.MockCFDevD
 		mockVpnKit          *mocks.MockVpnKit
@@ -52,16 +55,16 @@ var _ = Describe("Start", func() {
 
 	services := []provision.Service{
 		{
-			Name:          "some-service",
-			Flagname:      "some-service-flagname",
-			Script:        "/path/to/some-script",
-			Deployment:    "some-deployment",
+			Name:       "some-service",
+			Flagname:   "some-service-flagname",
+			Script:     "/path/to/some-script",
+			Deployment: "some-deployment",
 		},
 		{
-			Name:          "some-other-service",
-			Flagname:      "some-other-service-flagname",
-			Script:        "/path/to/some-other-script",
-			Deployment:    "some-other-deployment",
+			Name:       "some-other-service",
+			Flagname:   "some-other-service-flagname",
+			Script:     "/path/to/some-other-script",
+			Deployment: "some-other-deployment",
 		},
 	}
 	BeforeEach(func() {
@@ -71,12 +74,16 @@ var _ = Describe("Start", func() {
 		mockAnalyticsClient = mocks.NewMockAnalyticsClient(mockController)
 		mockToggle = mocks.NewMockToggle(mockController)
 		mockHostNet = mocks.NewMockHostNet(mockController)
+		mockHostNet.EXPECT().Routes().AnyTimes()
 		mockHost = mocks.NewMockHost(mockController)
+		mockQuirks = mocks.NewMockQuirks(mockController)
+		mockQuirks.EXPECT().Detect().AnyTimes()
 		mockCache = mocks.NewMockCache(mockController)
 		mockCFDevD = mocks.NewMockCFDevD(mockController)
 		mockVpnKit = mocks.NewMockVpnKit(mockController)
 		mockAnalyticsD = mocks.NewMockAnalyticsD(mockController)
 		mockHypervisor = mocks.NewMockHypervisor(mockController)
+		mockHypervisor.EXPECT().Preflight(gomock.Any()).AnyTimes()
 		mockProvisioner = mocks.NewMockProvisioner(mockController)
 		mockProvision = mocks.NewMockProvision(mockController)
 		mockSystemProfiler = mocks.NewMockSystemProfiler(mockController)
@@ -99,8 +106,10 @@ var _ = Describe("Start", func() {
 				VpnKitStateDir: filepath.Join(tmpDir, "some-vpnkit-state-dir"),
 				CacheDir:       cacheDir,
 				DepsFile:       &depsFile,
-				CFRouterIP:     "some-cf-router-ip",
-				BoshDirectorIP: "some-bosh-director-ip",
+				CFRouterIP:     "10.144.0.34",
+				BoshDirectorIP: "10.144.0.4",
+				HostIP:         "192.168.65.2",
+				CFSubnet:       "10.144.0.0/16",
 				Dependencies: resource.Catalog{
 					Items: []resource.Item{
 						{Name: "some-item"},
@@ -115,6 +124,7 @@ var _ = Describe("Start", func() {
 			AnalyticsToggle: mockToggle,
 			HostNet:         mockHostNet,
 			Host:            mockHost,
+			Quirks:          mockQuirks,
 			Cache:           mockCache,
 			CFDevD:          mockCFDevD,
 			VpnKit:          mockVpnKit,
@@ -126,6 +136,7 @@ var _ = Describe("Start", func() {
 			Env:             mockEnv,
 			Stop:            mockStop,
 			Profiler:        mockSystemProfiler,
+			NumCPU:          func() int { return 8 },
 		}
 
 		metadata = mdata.Metadata{
@@ -153,14 +164,14 @@ var _ = Describe("Start", func() {
 				gomock.InOrder(
 					mockToggle.EXPECT().SetProp("type", "cf"),
 					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
-					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(222), nil),
+					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
 
 					mockHost.EXPECT().CheckRequirements(),
 					mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
 					mockStop.EXPECT().RunE(nil, nil),
 					mockEnv.EXPECT().CreateDirs(),
 
-					mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+					mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 					mockUI.EXPECT().Say("Downloading Resources..."),
 					mockCache.EXPECT().Sync(resource.Catalog{
 						Items: []resource.Item{
@@ -174,9 +185,9 @@ var _ = Describe("Start", func() {
 
 					mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
 					mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
-						"total memory":     uint64(222),
+						"total memory":     uint64(22222),
 						"available memory": uint64(111),
-					}),
+					}, cfanalytics.HostClass(22222, 7)),
 					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(10000), nil),
 					mockUI.EXPECT().Say("Creating the VM..."),
 					mockHypervisor.EXPECT().CreateVM(hypervisor.VM{
@@ -204,6 +215,58 @@ var _ = Describe("Start", func() {
 				})).To(Succeed())
 			})
 
+			It("attaches the data disk the flags ask for", func() {
+				if runtime.GOOS == "darwin" {
+					mockUI.EXPECT().Say("Installing cfdevd network helper...")
+					mockCFDevD.EXPECT().Install()
+				}
+				startCmd.Config.DataDir = filepath.Join(tmpDir, "some-data-dir")
+				vm := config.VMConfig{DataDiskMB: 10240}
+
+				gomock.InOrder(
+					mockToggle.EXPECT().SetProp("type", "cf"),
+					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
+					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
+
+					mockHost.EXPECT().CheckRequirements(),
+					mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
+					mockStop.EXPECT().RunE(nil, nil),
+					mockEnv.EXPECT().CreateDirs(),
+
+					mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
+					mockUI.EXPECT().Say("Downloading Resources..."),
+					mockCache.EXPECT().Sync(gomock.Any()),
+					mockUI.EXPECT().Say("Setting State..."),
+					mockEnv.EXPECT().SetupState(),
+					mockMetadataReader.EXPECT().Read(filepath.Join(cacheDir, "metadata.yml")).Return(metadata, nil),
+
+					mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
+					mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, gomock.Any(), gomock.Any()),
+					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(10000), nil),
+					mockUI.EXPECT().Say("Creating the VM..."),
+					mockHypervisor.EXPECT().CreateVM(hypervisor.VM{
+						Name:      "cfdev",
+						CPUs:      7,
+						MemoryMB:  8765,
+						DataDisks: []hypervisor.Disk{{Path: startCmd.Config.DataDiskPath(), SizeMB: 10240}},
+					}),
+					mockUI.EXPECT().Say("Starting VPNKit..."),
+					mockVpnKit.EXPECT().Start(),
+					mockVpnKit.EXPECT().Watch(localExitChan),
+					mockUI.EXPECT().Say("Starting the VM..."),
+					mockHypervisor.EXPECT().Start("cfdev"),
+					mockUI.EXPECT().Say("Waiting for the VM..."),
+					mockProvisioner.EXPECT().Ping(),
+					mockProvision.EXPECT().Execute(start.Args{Cpus: 7, VM: vm}),
+
+					mockToggle.EXPECT().Enabled().Return(true),
+					mockAnalyticsD.EXPECT().Start(),
+					mockAnalyticsClient.EXPECT().Event(cfanalytics.START_END),
+				)
+
+				Expect(startCmd.Execute(start.Args{Cpus: 7, VM: vm})).To(Succeed())
+			})
+
 			It("starts the vm with analytics toggled off", func() {
 				if runtime.GOOS == "darwin" {
 					mockUI.EXPECT().Say("Installing cfdevd network helper...")
@@ -213,13 +276,13 @@ var _ = Describe("Start", func() {
 				gomock.InOrder(
 					mockToggle.EXPECT().SetProp("type", "cf"),
 					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
-					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(222), nil),
+					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
 					mockHost.EXPECT().CheckRequirements(),
 					mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
 					mockStop.EXPECT().RunE(nil, nil),
 					mockEnv.EXPECT().CreateDirs(),
 
-					mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+					mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 					mockUI.EXPECT().Say("Downloading Resources..."),
 					mockCache.EXPECT().Sync(resource.Catalog{
 						Items: []resource.Item{
@@ -233,9 +296,9 @@ var _ = Describe("Start", func() {
 
 					mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
 					mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
-						"total memory":     uint64(222),
+						"total memory":     uint64(22222),
 						"available memory": uint64(111),
-					}),
+					}, gomock.Any()),
 					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(10000), nil),
 					mockUI.EXPECT().Say("Creating the VM..."),
 					mockHypervisor.EXPECT().CreateVM(hypervisor.VM{
@@ -281,7 +344,7 @@ var _ = Describe("Start", func() {
 					gomock.InOrder(
 						mockToggle.EXPECT().SetProp("type", "cf"),
 						mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
-						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(222), nil),
+						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
 						mockHost.EXPECT().CheckRequirements(),
 						mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
 						mockStop.EXPECT().RunE(nil, nil),
@@ -293,7 +356,7 @@ var _ = Describe("Start", func() {
 								{Name: "cfdevd"},
 							},
 						}),
-						mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+						mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 						mockUI.EXPECT().Say("Downloading Resources..."),
 						mockCache.EXPECT().Sync(resource.Catalog{
 							Items: []resource.Item{
@@ -307,9 +370,9 @@ var _ = Describe("Start", func() {
 
 						mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
 						mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
-							"total memory":     uint64(222),
+							"total memory":     uint64(22222),
 							"available memory": uint64(111),
-						}),
+						}, gomock.Any()),
 						mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(110000), nil),
 						mockUI.EXPECT().Say("Creating the VM..."),
 						mockHypervisor.EXPECT().CreateVM(hypervisor.VM{
@@ -339,7 +402,7 @@ var _ = Describe("Start", func() {
 			})
 
 			Context("when no args are provided AND deps.iso does not have a default memory field", func() {
-				It("starts the vm with a default memory setting", func() {
+				It("starts the vm with half the host's memory, up to 8GB", func() {
 					if runtime.GOOS == "darwin" {
 						mockUI.EXPECT().Say("Installing cfdevd network helper...")
 						mockCFDevD.EXPECT().Install()
@@ -348,13 +411,13 @@ var _ = Describe("Start", func() {
 					gomock.InOrder(
 						mockToggle.EXPECT().SetProp("type", "cf"),
 						mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
-						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(222), nil),
+						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
 						mockHost.EXPECT().CheckRequirements(),
 						mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
 						mockStop.EXPECT().RunE(nil, nil),
 						mockEnv.EXPECT().CreateDirs(),
 
-						mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+						mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 						mockUI.EXPECT().Say("Downloading Resources..."),
 						mockCache.EXPECT().Sync(resource.Catalog{
 							Items: []resource.Item{
@@ -373,15 +436,15 @@ var _ = Describe("Start", func() {
 
 						mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
 						mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
-							"total memory":     uint64(222),
+							"total memory":     uint64(22222),
 							"available memory": uint64(111),
-						}),
+						}, gomock.Any()),
 						mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(10000), nil),
 						mockUI.EXPECT().Say("Creating the VM..."),
 						mockHypervisor.EXPECT().CreateVM(hypervisor.VM{
 							Name:     "cfdev",
 							CPUs:     7,
-							MemoryMB: 4192,
+							MemoryMB: 8192,
 						}),
 						mockUI.EXPECT().Say("Starting VPNKit..."),
 						mockVpnKit.EXPECT().Start(),
@@ -414,13 +477,13 @@ var _ = Describe("Start", func() {
 					gomock.InOrder(
 						mockToggle.EXPECT().SetProp("type", "cf"),
 						mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
-						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(222), nil),
+						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
 						mockHost.EXPECT().CheckRequirements(),
 						mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
 						mockStop.EXPECT().RunE(nil, nil),
 						mockEnv.EXPECT().CreateDirs(),
 
-						mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+						mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 						mockUI.EXPECT().Say("Downloading Resources..."),
 						mockCache.EXPECT().Sync(resource.Catalog{
 							Items: []resource.Item{
@@ -434,11 +497,11 @@ var _ = Describe("Start", func() {
 
 						mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
 						mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
-							"total memory":     uint64(222),
+							"total memory":     uint64(22222),
 							"available memory": uint64(111),
-						}),
+						}, gomock.Any()),
 						mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(1000), nil),
-						mockUI.EXPECT().Say("WARNING: CF Dev requires 8765 MB of RAM to run. This machine may not have enough free RAM."),
+						mockUI.EXPECT().Say("WARNING: This machine may not have enough available RAM to run with what is specified."),
 						mockUI.EXPECT().Say("Creating the VM..."),
 						mockHypervisor.EXPECT().CreateVM(hypervisor.VM{
 							Name:     "cfdev",
@@ -479,13 +542,13 @@ var _ = Describe("Start", func() {
 					gomock.InOrder(
 						mockToggle.EXPECT().SetProp("type", "cf"),
 						mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
-						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(222), nil),
+						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
 						mockHost.EXPECT().CheckRequirements(),
 						mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
 						mockStop.EXPECT().RunE(nil, nil),
 						mockEnv.EXPECT().CreateDirs(),
 
-						mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+						mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 						mockUI.EXPECT().Say("Downloading Resources..."),
 						mockCache.EXPECT().Sync(resource.Catalog{
 							Items: []resource.Item{
@@ -499,9 +562,9 @@ var _ = Describe("Start", func() {
 
 						mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
 						mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
-							"total memory":     uint64(222),
+							"total memory":     uint64(22222),
 							"available memory": uint64(111),
-						}),
+						}, gomock.Any()),
 						mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(10000), nil),
 						mockUI.EXPECT().Say("WARNING: It is recommended that you run CF Dev with at least 8765 MB of RAM."),
 						mockUI.EXPECT().Say("Creating the VM..."),
@@ -548,7 +611,7 @@ var _ = Describe("Start", func() {
 								mockStop.EXPECT().RunE(nil, nil),
 								mockEnv.EXPECT().CreateDirs(),
 
-								mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+								mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 								mockUI.EXPECT().Say("Downloading Resources..."),
 								mockCache.EXPECT().Sync(resource.Catalog{
 									Items: []resource.Item{
@@ -564,7 +627,7 @@ var _ = Describe("Start", func() {
 								mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
 									"total memory":     uint64(16000),
 									"available memory": uint64(15000),
-								}),
+								}, gomock.Any()),
 								mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(15000), nil),
 								mockUI.EXPECT().Say("Creating the VM..."),
 								mockHypervisor.EXPECT().CreateVM(hypervisor.VM{
@@ -604,13 +667,13 @@ var _ = Describe("Start", func() {
 						gomock.InOrder(
 							mockToggle.EXPECT().SetProp("type", "cf"),
 							mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(9000), nil),
-							mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(9500), nil),
+							mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(12000), nil),
 							mockHost.EXPECT().CheckRequirements(),
 							mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
 							mockStop.EXPECT().RunE(nil, nil),
 							mockEnv.EXPECT().CreateDirs(),
 
-							mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+							mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 							mockUI.EXPECT().Say("Downloading Resources..."),
 							mockCache.EXPECT().Sync(resource.Catalog{
 								Items: []resource.Item{
@@ -624,9 +687,9 @@ var _ = Describe("Start", func() {
 
 							mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
 							mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
-								"total memory":     uint64(9500),
+								"total memory":     uint64(12000),
 								"available memory": uint64(9000),
-							}),
+							}, gomock.Any()),
 							mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(1200), nil),
 							mockUI.EXPECT().Say("WARNING: This machine may not have enough available RAM to run with what is specified."),
 							mockUI.EXPECT().Say("Creating the VM..."),
@@ -675,7 +738,7 @@ var _ = Describe("Start", func() {
 							mockStop.EXPECT().RunE(nil, nil),
 							mockEnv.EXPECT().CreateDirs(),
 
-							mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+							mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 							mockUI.EXPECT().Say("Downloading Resources..."),
 							mockCache.EXPECT().Sync(resource.Catalog{
 								Items: []resource.Item{
@@ -697,7 +760,7 @@ var _ = Describe("Start", func() {
 							mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
 								"total memory":     uint64(16000),
 								"available memory": uint64(15000),
-							}),
+							}, gomock.Any()),
 							mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(15000), nil),
 							mockUI.EXPECT().Say("WARNING: It is recommended that you run SOME-DEPLOYMENT-NAME Dev with at least 8765 MB of RAM."),
 							mockUI.EXPECT().Say("Creating the VM..."),
@@ -736,13 +799,13 @@ var _ = Describe("Start", func() {
 						gomock.InOrder(
 							mockToggle.EXPECT().SetProp("type", "cf"),
 							mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(5000), nil),
-							mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(5500), nil),
+							mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(8192), nil),
 							mockHost.EXPECT().CheckRequirements(),
 							mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
 							mockStop.EXPECT().RunE(nil, nil),
 							mockEnv.EXPECT().CreateDirs(),
 
-							mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+							mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 							mockUI.EXPECT().Say("Downloading Resources..."),
 							mockCache.EXPECT().Sync(resource.Catalog{
 								Items: []resource.Item{
@@ -756,9 +819,9 @@ var _ = Describe("Start", func() {
 
 							mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
 							mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
-								"total memory":     uint64(5500),
+								"total memory":     uint64(8192),
 								"available memory": uint64(5000),
-							}),
+							}, gomock.Any()),
 							mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(1200), nil),
 							mockUI.EXPECT().Say("WARNING: It is recommended that you run CF Dev with at least 8765 MB of RAM."),
 							mockUI.EXPECT().Say("WARNING: This machine may not have enough available RAM to run with what is specified."),
@@ -802,13 +865,13 @@ var _ = Describe("Start", func() {
 					gomock.InOrder(
 						mockToggle.EXPECT().SetProp("type", "cf"),
 						mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
-						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(222), nil),
+						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
 						mockHost.EXPECT().CheckRequirements(),
 						mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
 						mockStop.EXPECT().RunE(nil, nil),
 						mockEnv.EXPECT().CreateDirs(),
 
-						mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+						mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 						mockUI.EXPECT().Say("Downloading Resources..."),
 						mockCache.EXPECT().Sync(resource.Catalog{
 							Items: []resource.Item{
@@ -822,9 +885,9 @@ var _ = Describe("Start", func() {
 
 						mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
 						mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
-							"total memory":     uint64(222),
+							"total memory":     uint64(22222),
 							"available memory": uint64(111),
-						}),
+						}, gomock.Any()),
 						mockAnalyticsClient.EXPECT().Event(cfanalytics.SELECTED_SERVICE, map[string]interface{}{"services_requested": "all"}),
 						mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(10000), nil),
 						mockUI.EXPECT().Say("Creating the VM..."),
@@ -865,13 +928,13 @@ var _ = Describe("Start", func() {
 					gomock.InOrder(
 						mockToggle.EXPECT().SetProp("type", "cf"),
 						mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
-						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(222), nil),
+						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
 						mockHost.EXPECT().CheckRequirements(),
 						mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
 						mockStop.EXPECT().RunE(nil, nil),
 						mockEnv.EXPECT().CreateDirs(),
 
-						mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+						mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 						mockUI.EXPECT().Say("Downloading Resources..."),
 						mockCache.EXPECT().Sync(resource.Catalog{
 							Items: []resource.Item{
@@ -885,9 +948,9 @@ var _ = Describe("Start", func() {
 
 						mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
 						mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
-							"total memory":     uint64(222),
+							"total memory":     uint64(22222),
 							"available memory": uint64(111),
-						}),
+						}, gomock.Any()),
 						mockAnalyticsClient.EXPECT().Event(cfanalytics.SELECTED_SERVICE, map[string]interface{}{"services_requested": "some-service-flagname,some-other-service-flagname"}),
 						mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(10000), nil),
 						mockUI.EXPECT().Say("Creating the VM..."),
@@ -928,13 +991,13 @@ var _ = Describe("Start", func() {
 					gomock.InOrder(
 						mockToggle.EXPECT().SetProp("type", "cf"),
 						mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
-						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(222), nil),
+						mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
 						mockHost.EXPECT().CheckRequirements(),
 						mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
 						mockStop.EXPECT().RunE(nil, nil),
 						mockEnv.EXPECT().CreateDirs(),
 
-						mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+						mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 						mockUI.EXPECT().Say("Downloading Resources..."),
 						mockCache.EXPECT().Sync(resource.Catalog{
 							Items: []resource.Item{
@@ -948,9 +1011,9 @@ var _ = Describe("Start", func() {
 
 						mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
 						mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
-							"total memory":     uint64(222),
+							"total memory":     uint64(22222),
 							"available memory": uint64(111),
-						}),
+						}, gomock.Any()),
 					)
 
 					Expect(startCmd.Execute(start.Args{
@@ -976,13 +1039,13 @@ var _ = Describe("Start", func() {
 				gomock.InOrder(
 					mockToggle.EXPECT().SetProp("type", "custom.tgz"),
 					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
-					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(222), nil),
+					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
 					mockHost.EXPECT().CheckRequirements(),
 					mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
 					mockStop.EXPECT().RunE(nil, nil),
 					mockEnv.EXPECT().CreateDirs(),
 
-					mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+					mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 					mockUI.EXPECT().Say("Downloading Resources..."),
 					// don't download cfdev-deps that we won't use
 					mockCache.EXPECT().Sync(resource.Catalog{
@@ -1016,13 +1079,13 @@ var _ = Describe("Start", func() {
 				gomock.InOrder(
 					mockToggle.EXPECT().SetProp("type", "custom.tgz"),
 					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
-					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(222), nil),
+					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
 					mockHost.EXPECT().CheckRequirements(),
 					mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
 					mockStop.EXPECT().RunE(nil, nil),
 					mockEnv.EXPECT().CreateDirs(),
 
-					mockHostNet.EXPECT().AddLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip"),
+					mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
 					mockUI.EXPECT().Say("Downloading Resources..."),
 					// don't download cfdev-deps that we won't use
 					mockCache.EXPECT().Sync(resource.Catalog{
@@ -1036,9 +1099,9 @@ var _ = Describe("Start", func() {
 
 					mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
 					mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, map[string]interface{}{
-						"total memory":     uint64(222),
+						"total memory":     uint64(22222),
 						"available memory": uint64(111),
-					}),
+					}, gomock.Any()),
 					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(10000), nil),
 					mockUI.EXPECT().Say("WARNING: It is recommended that you run CF Dev with at least 8765 MB of RAM."),
 					mockUI.EXPECT().Say("Creating the VM..."),
@@ -1074,7 +1137,7 @@ var _ = Describe("Start", func() {
 				gomock.InOrder(
 					mockToggle.EXPECT().SetProp("type", "cf"),
 					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
-					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(222), nil),
+					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
 					mockHost.EXPECT().CheckRequirements(),
 					mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil),
 					mockUI.EXPECT().Say("CF Dev is already running..."),
@@ -1084,5 +1147,200 @@ var _ = Describe("Start", func() {
 				Expect(startCmd.Execute(start.Args{})).To(Succeed())
 			})
 		})
+
+		Context("when preflight checks fail", func() {
+			It("warns about the checks that would not stop the vm and starts it", func() {
+				startCmd.Hypervisor = &preflightHypervisor{MockHypervisor: mockHypervisor, findings: []hypervisor.Finding{
+					{Check: "memory", Passed: true},
+					{Check: "disk", Message: "only 10GB of disk is free"},
+				}}
+				if runtime.GOOS == "darwin" {
+					mockUI.EXPECT().Say("Installing cfdevd network helper...")
+					mockCFDevD.EXPECT().Install()
+				}
+
+				gomock.InOrder(
+					mockToggle.EXPECT().SetProp("type", "cf"),
+					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
+					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
+
+					mockHost.EXPECT().CheckRequirements(),
+					mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
+					mockStop.EXPECT().RunE(nil, nil),
+					mockEnv.EXPECT().CreateDirs(),
+
+					mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
+					mockUI.EXPECT().Say("Downloading Resources..."),
+					mockCache.EXPECT().Sync(gomock.Any()),
+					mockUI.EXPECT().Say("Setting State..."),
+					mockEnv.EXPECT().SetupState(),
+					mockMetadataReader.EXPECT().Read(filepath.Join(cacheDir, "metadata.yml")).Return(metadata, nil),
+
+					mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
+					mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, gomock.Any(), gomock.Any()),
+					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(10000), nil),
+					mockUI.EXPECT().Say("WARNING: only 10GB of disk is free"),
+					mockUI.EXPECT().Say("Creating the VM..."),
+					mockHypervisor.EXPECT().CreateVM(gomock.Any()),
+					mockUI.EXPECT().Say("Starting VPNKit..."),
+					mockVpnKit.EXPECT().Start(),
+					mockVpnKit.EXPECT().Watch(localExitChan),
+					mockUI.EXPECT().Say("Starting the VM..."),
+					mockHypervisor.EXPECT().Start("cfdev"),
+					mockUI.EXPECT().Say("Waiting for the VM..."),
+					mockProvisioner.EXPECT().Ping(),
+					mockProvision.EXPECT().Execute(start.Args{Cpus: 7}),
+
+					mockToggle.EXPECT().Enabled().Return(true),
+					mockAnalyticsD.EXPECT().Start(),
+					mockAnalyticsClient.EXPECT().Event(cfanalytics.START_END),
+				)
+
+				Expect(startCmd.Execute(start.Args{Cpus: 7})).To(Succeed())
+			})
+
+			It("fails with every check that would stop the vm, without creating it", func() {
+				startCmd.Hypervisor = &preflightHypervisor{MockHypervisor: mockHypervisor, findings: []hypervisor.Finding{
+					{Check: "disk", Message: "only 10GB of disk is free"},
+					{Check: "memory", Fatal: true, Message: "8765MB of memory is not free"},
+					{Check: "virtualization", Fatal: true, Message: "virtualization is turned off"},
+				}}
+				if runtime.GOOS == "darwin" {
+					mockUI.EXPECT().Say("Installing cfdevd network helper...")
+					mockCFDevD.EXPECT().Install()
+				}
+
+				gomock.InOrder(
+					mockToggle.EXPECT().SetProp("type", "cf"),
+					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
+					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
+
+					mockHost.EXPECT().CheckRequirements(),
+					mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
+					mockStop.EXPECT().RunE(nil, nil),
+					mockEnv.EXPECT().CreateDirs(),
+
+					mockHostNet.EXPECT().AddLoopbackAliases("10.144.0.4", "10.144.0.34"),
+					mockUI.EXPECT().Say("Downloading Resources..."),
+					mockCache.EXPECT().Sync(gomock.Any()),
+					mockUI.EXPECT().Say("Setting State..."),
+					mockEnv.EXPECT().SetupState(),
+					mockMetadataReader.EXPECT().Read(filepath.Join(cacheDir, "metadata.yml")).Return(metadata, nil),
+
+					mockAnalyticsClient.EXPECT().PromptOptInIfNeeded(""),
+					mockAnalyticsClient.EXPECT().Event(cfanalytics.START_BEGIN, gomock.Any(), gomock.Any()),
+					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(10000), nil),
+					mockUI.EXPECT().Say("WARNING: only 10GB of disk is free"),
+				)
+
+				Expect(startCmd.Execute(start.Args{Cpus: 7})).To(MatchError("preflight checks failed: 8765MB of memory is not free\nvirtualization is turned off"))
+			})
+		})
+
+		Context("when another instance is running", func() {
+			It("refuses to start, as the instances share ports and addresses", func() {
+				Expect(startCmd.Config.WithInstance("other").MarkActive()).To(Succeed())
+
+				gomock.InOrder(
+					mockToggle.EXPECT().SetProp("type", "cf"),
+					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
+					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(22222), nil),
+					mockHost.EXPECT().CheckRequirements(),
+					mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
+				)
+
+				Expect(startCmd.Execute(start.Args{})).To(MatchError(ContainSubstring(`instance "other" is running`)))
+			})
+		})
+
+		Context("when --dry-run is given", func() {
+			It("prints the commands that would create and start the vm, changing nothing", func() {
+				output := &bytes.Buffer{}
+				startCmd.Hypervisor = &dryRunHypervisor{MockHypervisor: mockHypervisor}
+
+				gomock.InOrder(
+					mockToggle.EXPECT().SetProp("type", "cf"),
+					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(10000), nil),
+					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(16000), nil),
+					mockHost.EXPECT().CheckRequirements(),
+					mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
+					mockMetadataReader.EXPECT().Read(filepath.Join(cacheDir, "metadata.yml")).Return(metadata, nil),
+					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(10000), nil),
+					mockUI.EXPECT().Say("Dry run: the VM would be created and started with these commands, none of which were run:"),
+					mockUI.EXPECT().Writer().Return(output),
+					mockHypervisor.EXPECT().CreateVM(hypervisor.VM{Name: "cfdev", CPUs: 4, MemoryMB: 8765}),
+					mockHypervisor.EXPECT().Start("cfdev"),
+				)
+
+				Expect(startCmd.Execute(start.Args{Cpus: 4, Mem: 8765, DryRun: true})).To(Succeed())
+				Expect(startCmd.Hypervisor.(*dryRunHypervisor).out).To(BeIdenticalTo(output))
+			})
+
+			It("fails for a hypervisor that cannot print its commands", func() {
+				gomock.InOrder(
+					mockToggle.EXPECT().SetProp("type", "cf"),
+					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(10000), nil),
+					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(16000), nil),
+					mockHost.EXPECT().CheckRequirements(),
+					mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
+				)
+
+				Expect(startCmd.Execute(start.Args{Cpus: 4, Mem: 8765, DryRun: true})).To(MatchError("--dry-run is only supported with Hyper-V"))
+			})
+		})
+
+		Context("when the configuration is invalid", func() {
+			It("fails before creating the vm", func() {
+				gomock.InOrder(
+					mockToggle.EXPECT().SetProp("type", "cf"),
+					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
+					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(4000), nil),
+					mockHost.EXPECT().CheckRequirements(),
+					mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
+					mockUI.EXPECT().Say("WARNING: CF Dev is recommended on a machine with at least 8192 MB of RAM and 2 cpus. This one has less, so it may be slow or fail to start."),
+				)
+
+				err := startCmd.Execute(start.Args{Cpus: 16, Mem: 6666})
+				Expect(err).To(MatchError(ContainSubstring("invalid configuration")))
+				Expect(err.Error()).To(ContainSubstring("6666MB of memory is more than the 4000MB"))
+				Expect(err.Error()).To(ContainSubstring("16 cpus are more than the 8"))
+			})
+
+			It("sizes the cpus left unset to the host", func() {
+				gomock.InOrder(
+					mockToggle.EXPECT().SetProp("type", "cf"),
+					mockSystemProfiler.EXPECT().GetAvailableMemory().Return(uint64(111), nil),
+					mockSystemProfiler.EXPECT().GetTotalMemory().Return(uint64(12000), nil),
+					mockHost.EXPECT().CheckRequirements(),
+					mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil),
+				)
+
+				err := startCmd.Execute(start.Args{Mem: 16000})
+				Expect(err).To(MatchError(ContainSubstring("16000MB of memory is more than the 12000MB")))
+				Expect(err.Error()).NotTo(ContainSubstring("cpu"))
+			})
+		})
 	})
 })
+
+// preflightHypervisor is a hypervisor whose preflight checks find
+// findings.
+type preflightHypervisor struct {
+	*mocks.MockHypervisor
+	findings []hypervisor.Finding
+}
+
+func (h *preflightHypervisor) Preflight(vm hypervisor.VM) []hypervisor.Finding {
+	return h.findings
+}
+
+// dryRunHypervisor is a hypervisor that can print its commands, like
+// Hyper-V.
+type dryRunHypervisor struct {
+	*mocks.MockHypervisor
+	out io.Writer
+}
+
+func (h *dryRunHypervisor) DryRunTo(out io.Writer) {
+	h.out = out
+}
@@ -0,0 +1,409 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/hypervisor"
+	"code.cloudfoundry.org/cfdev/messages"
+	"code.cloudfoundry.org/cfdev/provision"
+	"github.com/spf13/cobra"
+)
+
+// slowLatency is far beyond what a local deployment needs to answer a
+// request. Latencies above it tend to come from the host network layer,
+// e.g. vpnkit, rather than from CF.
+const slowLatency = 500 * time.Millisecond
+
+// maxClockSkew is enough drift to break certificate validation and token
+// expiry inside the vm.
+const maxClockSkew = 5 * time.Second
+
+// recentTasks is how many of the director's tasks --tasks lists.
+const recentTasks = 10
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/status UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/status Provisioner
+type Provisioner interface {
+	Ping() error
+	ProbeLatency() []provision.Latency
+	GuestDiskUsage() (provision.DiskUsage, error)
+	GuestHeartbeat() (provision.Heartbeat, error)
+	DirectorReady() error
+	CFAPIReady() error
+	Deployments() ([]string, error)
+	RecentTasks(n int) ([]bosh.TaskInfo, error)
+}
+
+//go:generate mockgen -package mocks -destination mocks/hypervisor.go code.cloudfoundry.org/cfdev/cmd/status Hypervisor
+type Hypervisor interface {
+	IsRunning(vmName string) (bool, error)
+	Stats(vmName string) (hypervisor.Stats, error)
+}
+
+//go:generate mockgen -package mocks -destination mocks/analytics.go code.cloudfoundry.org/cfdev/cmd/status Analytics
+type Analytics interface {
+	Event(event string, data ...map[string]interface{}) error
+}
+
+type Status struct {
+	UI          UI
+	Provisioner Provisioner
+	Hypervisor  Hypervisor
+	Analytics   Analytics
+	Config      config.Config
+}
+
+type Args struct {
+	Verbose bool
+	Tasks   bool
+	JSON    bool
+}
+
+// Report is what --json prints. The parts that could not be checked, e.g.
+// everything but the vm when cf dev is not running, are left out.
+type Report struct {
+	Running   bool             `json:"running"`
+	VM        VMReport         `json:"vm"`
+	Director  *Check           `json:"director,omitempty"`
+	CFAPI     *Check           `json:"cf_api,omitempty"`
+	Latencies []LatencyReport  `json:"latencies,omitempty"`
+	Disk      *DiskReport      `json:"disk,omitempty"`
+	Services  []string         `json:"services,omitempty"`
+	Heartbeat *HeartbeatReport `json:"heartbeat,omitempty"`
+	Tasks     []TaskReport     `json:"tasks,omitempty"`
+	Errors    []string         `json:"errors,omitempty"`
+}
+
+type Check struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+type VMReport struct {
+	Running          bool    `json:"running"`
+	CPUPercent       float64 `json:"cpu_percent,omitempty"`
+	MemoryDemandMB   uint64  `json:"memory_demand_mb,omitempty"`
+	MemoryAssignedMB uint64  `json:"memory_assigned_mb,omitempty"`
+}
+
+type LatencyReport struct {
+	Name         string `json:"name"`
+	Milliseconds int64  `json:"ms,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+type DiskReport struct {
+	Mount       string `json:"mount"`
+	UsedPercent int    `json:"used_percent"`
+	AvailableMB uint64 `json:"available_mb"`
+}
+
+type HeartbeatReport struct {
+	CPUStealPercent       float64 `json:"cpu_steal_percent"`
+	MemoryPressurePercent float64 `json:"memory_pressure_percent"`
+	ClockSkewSeconds      float64 `json:"clock_skew_seconds"`
+}
+
+type TaskReport struct {
+	ID          int       `json:"id"`
+	State       string    `json:"state"`
+	Deployment  string    `json:"deployment"`
+	Description string    `json:"description"`
+	Result      string    `json:"result,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+func (s *Status) Cmd() *cobra.Command {
+	args := Args{}
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether CF Dev is running and how responsive it is",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return s.Execute(args)
+		},
+	}
+
+	pf := cmd.PersistentFlags()
+	pf.BoolVarP(&args.Verbose, "verbose", "v", false, "also show the guest's cpu steal, memory pressure and clock skew")
+	pf.BoolVar(&args.Tasks, "tasks", false, "also list the BOSH Director's recent tasks")
+	pf.BoolVar(&args.JSON, "json", false, "print the status as json, for scripts")
+	return cmd
+}
+
+func (s *Status) Execute(args Args) error {
+	r := &run{Status: s, quiet: args.JSON}
+
+	if err := s.Provisioner.Ping(); err != nil {
+		r.reportVM()
+		if r.report.VM.Running {
+			r.say(messages.T("status.not-responding"))
+		} else {
+			r.say(messages.T("status.not-running"))
+		}
+		return r.printJSON()
+	}
+
+	r.report.Running = true
+	r.say(messages.T("status.running"))
+	r.reportVM()
+
+	r.report.Director = r.check(s.Provisioner.DirectorReady, "status.director", "status.director-failed")
+	r.report.CFAPI = r.check(s.Provisioner.CFAPIReady, "status.cf-api", "status.cf-api-failed")
+
+	for _, latency := range s.Provisioner.ProbeLatency() {
+		if latency.Err != nil {
+			r.report.Latencies = append(r.report.Latencies, LatencyReport{Name: latency.Name, Error: latency.Err.Error()})
+			r.say(messages.T("status.latency-failed", map[string]interface{}{
+				"Name":  latency.Name,
+				"Error": latency.Err,
+			}))
+			continue
+		}
+
+		duration := latency.Duration.Round(time.Millisecond)
+		r.report.Latencies = append(r.report.Latencies, LatencyReport{Name: latency.Name, Milliseconds: int64(duration / time.Millisecond)})
+		if latency.Duration > slowLatency {
+			r.say(messages.T("status.latency-slow", map[string]interface{}{
+				"Name":    latency.Name,
+				"Latency": duration,
+			}))
+			continue
+		}
+
+		r.say(messages.T("status.latency", map[string]interface{}{
+			"Name":    latency.Name,
+			"Latency": duration,
+		}))
+	}
+
+	r.reportDiskUsage()
+	r.reportServices()
+
+	if args.Verbose {
+		r.reportHeartbeat()
+	}
+
+	if args.Tasks {
+		r.reportTasks()
+	}
+	return r.printJSON()
+}
+
+// run is a single status, which --json gathers into a report rather than
+// saying as it goes.
+type run struct {
+	*Status
+	quiet  bool
+	report Report
+}
+
+func (r *run) say(message string) {
+	if !r.quiet {
+		r.UI.Say(message)
+	}
+}
+
+// warn says a part could not be checked, and records why in the report.
+func (r *run) warn(message string) {
+	r.report.Errors = append(r.report.Errors, message)
+	r.say(message)
+}
+
+func (r *run) printJSON() error {
+	if !r.quiet {
+		return nil
+	}
+
+	bytes, err := json.Marshal(r.report)
+	if err != nil {
+		return e.SafeWrap(err, "unable to marshal status")
+	}
+	r.UI.Say(string(bytes))
+	return nil
+}
+
+func (r *run) check(ready func() error, healthy, failed string) *Check {
+	if err := ready(); err != nil {
+		r.say(messages.T(failed, map[string]interface{}{"Error": err}))
+		return &Check{Error: err.Error()}
+	}
+	r.say(messages.T(healthy))
+	return &Check{Healthy: true}
+}
+
+// reportVM asks the hypervisor whether the vm is running and what it uses,
+// which it can tell even when nothing inside the vm answers.
+func (r *run) reportVM() {
+	vmName := r.Config.VMName()
+	running, err := r.Hypervisor.IsRunning(vmName)
+	if err != nil {
+		r.warn(messages.T("status.vm-failed", map[string]interface{}{"Error": err}))
+		return
+	}
+	r.report.VM.Running = running
+	if !running {
+		return
+	}
+
+	stats, err := r.Hypervisor.Stats(vmName)
+	if err != nil {
+		r.warn(messages.T("status.vm-failed", map[string]interface{}{"Error": err}))
+		return
+	}
+	r.report.VM.CPUPercent = stats.CPUPercent
+	r.report.VM.MemoryDemandMB = stats.MemoryDemandMB
+	r.report.VM.MemoryAssignedMB = stats.MemoryAssignedMB
+
+	r.say(messages.T("status.vm", map[string]interface{}{
+		"CPU":      fmt.Sprintf("%.1f", stats.CPUPercent),
+		"Demand":   stats.MemoryDemandMB,
+		"Assigned": stats.MemoryAssignedMB,
+	}))
+}
+
+// reportServices lists the services the director has deployed alongside
+// cf, including those deployed with the bosh cli rather than cf dev.
+func (r *run) reportServices() {
+	deployments, err := r.Provisioner.Deployments()
+	if err != nil {
+		r.warn(messages.T("status.services-failed", map[string]interface{}{"Error": err}))
+		return
+	}
+
+	services := []string{}
+	for _, deployment := range deployments {
+		if deployment != "cf" {
+			services = append(services, deployment)
+		}
+	}
+	r.report.Services = services
+
+	if len(services) == 0 {
+		r.say(messages.T("status.no-services"))
+		return
+	}
+	r.say(messages.T("status.services", map[string]interface{}{"Services": strings.Join(services, ", ")}))
+}
+
+// reportTasks lists the director's recent tasks, so that users can tell
+// whether an earlier deploy failed, and why, without the bosh cli.
+func (r *run) reportTasks() {
+	tasks, err := r.Provisioner.RecentTasks(recentTasks)
+	if err != nil {
+		r.warn(messages.T("status.tasks-failed", map[string]interface{}{"Error": err}))
+		return
+	}
+
+	r.say(messages.T("status.tasks"))
+	for _, task := range tasks {
+		r.report.Tasks = append(r.report.Tasks, TaskReport{
+			ID:          task.ID,
+			State:       task.State,
+			Deployment:  task.Deployment,
+			Description: task.Description,
+			Result:      task.Result,
+			StartedAt:   task.StartedAt,
+		})
+		r.say(messages.T("status.task", map[string]interface{}{
+			"ID":          task.ID,
+			"State":       task.State,
+			"Deployment":  task.Deployment,
+			"Description": task.Description,
+			"StartedAt":   task.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			"Duration":    task.LastActivityAt.Sub(task.StartedAt).Round(time.Second),
+		}))
+		if task.Failed() {
+			r.say(messages.T("status.task-error", map[string]interface{}{"Result": task.Result}))
+		}
+	}
+}
+
+// reportHeartbeat shows what the guest is short of, and records it in
+// buckets so failures can be correlated with resource exhaustion without
+// sending exact figures.
+func (r *run) reportHeartbeat() {
+	heartbeat, err := r.Provisioner.GuestHeartbeat()
+	if err != nil {
+		r.warn(messages.T("status.heartbeat-failed", map[string]interface{}{"Error": err}))
+		return
+	}
+
+	r.report.Heartbeat = &HeartbeatReport{
+		CPUStealPercent:       heartbeat.CPUStealPercent,
+		MemoryPressurePercent: heartbeat.MemoryPressurePercent,
+		ClockSkewSeconds:      heartbeat.ClockSkew.Seconds(),
+	}
+	r.say(messages.T("status.heartbeat", map[string]interface{}{
+		"Steal":    fmt.Sprintf("%.1f", heartbeat.CPUStealPercent),
+		"Pressure": fmt.Sprintf("%.1f", heartbeat.MemoryPressurePercent),
+		"Skew":     heartbeat.ClockSkew.Round(time.Millisecond),
+	}))
+
+	skew := heartbeat.ClockSkew
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxClockSkew {
+		r.say(messages.T("status.clock-skew", map[string]interface{}{
+			"Skew": heartbeat.ClockSkew.Round(time.Second),
+		}))
+	}
+
+	r.Analytics.Event(cfanalytics.HEARTBEAT, map[string]interface{}{
+		"cpu_steal":       bucket(heartbeat.CPUStealPercent, 5, 20, 50),
+		"memory_pressure": bucket(heartbeat.MemoryPressurePercent, 5, 20, 50),
+		"disk_used":       bucket(float64(heartbeat.Disk.UsedPercent), 50, 75, provision.DiskPressurePercent),
+		"clock_skew":      bucket(skew.Seconds(), 1, maxClockSkew.Seconds(), 60),
+	})
+}
+
+// bucket names the range among bounds, given in ascending order, that
+// value falls in.
+func bucket(value float64, bounds ...float64) string {
+	lower := 0.0
+	for _, bound := range bounds {
+		if value < bound {
+			return fmt.Sprintf("%g-%g", lower, bound)
+		}
+		lower = bound
+	}
+	return fmt.Sprintf("%g+", lower)
+}
+
+// reportDiskUsage warns when the guest disk is filling up. The monitor
+// started with the director cleans up unused packages as it does, so that
+// status only reads.
+func (r *run) reportDiskUsage() {
+	usage, err := r.Provisioner.GuestDiskUsage()
+	if err != nil {
+		r.warn(messages.T("status.disk-failed", map[string]interface{}{"Error": err}))
+		return
+	}
+
+	r.report.Disk = &DiskReport{Mount: usage.Mount, UsedPercent: usage.UsedPercent, AvailableMB: usage.AvailableMB}
+
+	if usage.UsedPercent >= provision.DiskPressurePercent {
+		r.say(messages.T("status.disk-pressure", map[string]interface{}{
+			"Mount":   usage.Mount,
+			"Percent": usage.UsedPercent,
+		}))
+		return
+	}
+
+	r.say(messages.T("status.disk", map[string]interface{}{
+		"Mount":       usage.Mount,
+		"Percent":     usage.UsedPercent,
+		"AvailableMB": usage.AvailableMB,
+	}))
+}
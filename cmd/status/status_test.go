@@ -0,0 +1,372 @@
+package status_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/cmd/status"
+	"code.cloudfoundry.org/cfdev/cmd/status/mocks"
+	"code.cloudfoundry.org/cfdev/hypervisor"
+	"code.cloudfoundry.org/cfdev/provision"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Status", func() {
+	var (
+		mockController  *gomock.Controller
+		mockUI          *mocks.MockUI
+		mockProvisioner *mocks.MockProvisioner
+		mockHypervisor  *mocks.MockHypervisor
+		mockAnalytics   *mocks.MockAnalytics
+		subject         *status.Status
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+		mockHypervisor = mocks.NewMockHypervisor(mockController)
+		mockAnalytics = mocks.NewMockAnalytics(mockController)
+
+		subject = &status.Status{
+			UI:          mockUI,
+			Provisioner: mockProvisioner,
+			Hypervisor:  mockHypervisor,
+			Analytics:   mockAnalytics,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	expectVM := func() {
+		mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil)
+		mockHypervisor.EXPECT().Stats("cfdev").Return(hypervisor.Stats{CPUPercent: 25, MemoryDemandMB: 3000, MemoryAssignedMB: 8192}, nil)
+		mockUI.EXPECT().Say("VM: 25.0% cpu, 3000MB of 8192MB memory in use")
+	}
+
+	expectCFAPI := func() {
+		mockProvisioner.EXPECT().CFAPIReady()
+		mockUI.EXPECT().Say("CF API: healthy")
+	}
+
+	expectServices := func() {
+		mockProvisioner.EXPECT().Deployments().Return([]string{"cf"}, nil)
+		mockUI.EXPECT().Say("Deployed services: none")
+	}
+
+	It("reports the latency of each layer", func() {
+		gomock.InOrder(
+			mockProvisioner.EXPECT().Ping(),
+			mockUI.EXPECT().Say("CF Dev is running"),
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil),
+			mockHypervisor.EXPECT().Stats("cfdev").Return(hypervisor.Stats{CPUPercent: 25, MemoryDemandMB: 3000, MemoryAssignedMB: 8192}, nil),
+			mockUI.EXPECT().Say("VM: 25.0% cpu, 3000MB of 8192MB memory in use"),
+			mockProvisioner.EXPECT().DirectorReady(),
+			mockUI.EXPECT().Say("BOSH Director: healthy"),
+			mockProvisioner.EXPECT().CFAPIReady(),
+			mockUI.EXPECT().Say("CF API: healthy"),
+			mockProvisioner.EXPECT().ProbeLatency().Return([]provision.Latency{
+				{Name: "CC API", Duration: 40 * time.Millisecond},
+				{Name: "Router", Duration: 12 * time.Millisecond},
+			}),
+			mockUI.EXPECT().Say("CC API latency: 40ms"),
+			mockUI.EXPECT().Say("Router latency: 12ms"),
+			mockProvisioner.EXPECT().GuestDiskUsage().Return(provision.DiskUsage{Mount: "/var/vcap/data", UsedPercent: 40, AvailableMB: 6000}, nil),
+			mockUI.EXPECT().Say("Disk usage: 40% of /var/vcap/data (6000MB free)"),
+			mockProvisioner.EXPECT().Deployments().Return([]string{"cf", "mysql", "redis"}, nil),
+			mockUI.EXPECT().Say("Deployed services: mysql, redis"),
+		)
+
+		Expect(subject.Execute(status.Args{})).To(Succeed())
+	})
+
+	Context("when the director is unhealthy", func() {
+		It("warns", func() {
+			mockProvisioner.EXPECT().Ping()
+			mockUI.EXPECT().Say("CF Dev is running")
+			expectVM()
+			mockProvisioner.EXPECT().DirectorReady().Return(errors.New("some-error"))
+			mockUI.EXPECT().Say("[WARN] BOSH Director: some-error")
+			expectCFAPI()
+			mockProvisioner.EXPECT().ProbeLatency()
+			mockProvisioner.EXPECT().GuestDiskUsage().Return(provision.DiskUsage{Mount: "/", UsedPercent: 10}, nil)
+			mockUI.EXPECT().Say(gomock.Any())
+			expectServices()
+
+			Expect(subject.Execute(status.Args{})).To(Succeed())
+		})
+	})
+
+	Context("when a layer is slow", func() {
+		It("points at the network layer", func() {
+			mockProvisioner.EXPECT().Ping()
+			mockUI.EXPECT().Say("CF Dev is running")
+			expectVM()
+			mockProvisioner.EXPECT().DirectorReady()
+			mockUI.EXPECT().Say("BOSH Director: healthy")
+			expectCFAPI()
+			mockProvisioner.EXPECT().ProbeLatency().Return([]provision.Latency{
+				{Name: "Router", Duration: 2 * time.Second},
+			})
+			mockUI.EXPECT().Say("[WARN] Router latency: 2s. This is unusually slow and usually points at the host network layer (vpnkit) rather than CF itself")
+			mockProvisioner.EXPECT().GuestDiskUsage().Return(provision.DiskUsage{Mount: "/", UsedPercent: 10}, nil)
+			mockUI.EXPECT().Say(gomock.Any())
+			expectServices()
+
+			Expect(subject.Execute(status.Args{})).To(Succeed())
+		})
+	})
+
+	Context("when a layer is unreachable", func() {
+		It("reports the error", func() {
+			mockProvisioner.EXPECT().Ping()
+			mockUI.EXPECT().Say("CF Dev is running")
+			expectVM()
+			mockProvisioner.EXPECT().DirectorReady()
+			mockUI.EXPECT().Say("BOSH Director: healthy")
+			expectCFAPI()
+			mockProvisioner.EXPECT().ProbeLatency().Return([]provision.Latency{
+				{Name: "CC API", Err: errors.New("some-error")},
+			})
+			mockUI.EXPECT().Say("[WARN] CC API is unreachable: some-error")
+			mockProvisioner.EXPECT().GuestDiskUsage().Return(provision.DiskUsage{Mount: "/", UsedPercent: 10}, nil)
+			mockUI.EXPECT().Say(gomock.Any())
+			expectServices()
+
+			Expect(subject.Execute(status.Args{})).To(Succeed())
+		})
+	})
+
+	Context("when the guest disk is nearly full", func() {
+		BeforeEach(func() {
+			mockProvisioner.EXPECT().Ping()
+			mockUI.EXPECT().Say("CF Dev is running")
+			expectVM()
+			mockProvisioner.EXPECT().DirectorReady()
+			mockUI.EXPECT().Say("BOSH Director: healthy")
+			expectCFAPI()
+			mockProvisioner.EXPECT().ProbeLatency()
+			expectServices()
+		})
+
+		It("warns without cleaning up, which is left to the disk monitor and cf dev prune", func() {
+			gomock.InOrder(
+				mockProvisioner.EXPECT().GuestDiskUsage().Return(provision.DiskUsage{Mount: "/var/vcap/data", UsedPercent: 92, AvailableMB: 800}, nil),
+				mockUI.EXPECT().Say("[WARN] /var/vcap/data is 92% full. Deploys and pushes will start failing once it runs out of space, try 'cf dev prune', removing unused apps and services or 'cf dev reset'"),
+			)
+
+			Expect(subject.Execute(status.Args{})).To(Succeed())
+		})
+	})
+
+	Context("when the director cannot list its deployments", func() {
+		It("warns", func() {
+			mockProvisioner.EXPECT().Ping()
+			mockUI.EXPECT().Say("CF Dev is running")
+			expectVM()
+			mockProvisioner.EXPECT().DirectorReady()
+			mockUI.EXPECT().Say("BOSH Director: healthy")
+			expectCFAPI()
+			mockProvisioner.EXPECT().ProbeLatency()
+			mockProvisioner.EXPECT().GuestDiskUsage().Return(provision.DiskUsage{Mount: "/", UsedPercent: 10}, nil)
+			mockUI.EXPECT().Say("Disk usage: 10% of / (0MB free)")
+			mockProvisioner.EXPECT().Deployments().Return(nil, errors.New("some-error"))
+			mockUI.EXPECT().Say("[WARN] Unable to list deployed services: some-error")
+
+			Expect(subject.Execute(status.Args{})).To(Succeed())
+		})
+	})
+
+	Context("when the disk usage cannot be read", func() {
+		It("warns", func() {
+			mockProvisioner.EXPECT().Ping()
+			mockUI.EXPECT().Say("CF Dev is running")
+			expectVM()
+			mockProvisioner.EXPECT().DirectorReady()
+			mockUI.EXPECT().Say("BOSH Director: healthy")
+			expectCFAPI()
+			mockProvisioner.EXPECT().ProbeLatency()
+			mockProvisioner.EXPECT().GuestDiskUsage().Return(provision.DiskUsage{}, errors.New("some-error"))
+			mockUI.EXPECT().Say("[WARN] Unable to read disk usage: some-error")
+			expectServices()
+
+			Expect(subject.Execute(status.Args{})).To(Succeed())
+		})
+	})
+
+	Context("when verbose", func() {
+		BeforeEach(func() {
+			mockProvisioner.EXPECT().Ping()
+			mockUI.EXPECT().Say("CF Dev is running")
+			expectVM()
+			mockProvisioner.EXPECT().DirectorReady()
+			mockUI.EXPECT().Say("BOSH Director: healthy")
+			expectCFAPI()
+			mockProvisioner.EXPECT().ProbeLatency()
+			mockProvisioner.EXPECT().GuestDiskUsage().Return(provision.DiskUsage{Mount: "/", UsedPercent: 10}, nil)
+			mockUI.EXPECT().Say(gomock.Any())
+			expectServices()
+		})
+
+		It("reports the guest heartbeat and records it in buckets", func() {
+			gomock.InOrder(
+				mockProvisioner.EXPECT().GuestHeartbeat().Return(provision.Heartbeat{
+					CPUStealPercent:       12.34,
+					MemoryPressurePercent: 0.5,
+					Disk:                  provision.DiskUsage{UsedPercent: 90},
+					ClockSkew:             -1500 * time.Millisecond,
+				}, nil),
+				mockUI.EXPECT().Say("Guest: 12.3% cpu steal, 0.5% memory pressure, clock skew -1.5s"),
+				mockAnalytics.EXPECT().Event("guest heartbeat", map[string]interface{}{
+					"cpu_steal":       "5-20",
+					"memory_pressure": "0-5",
+					"disk_used":       "85+",
+					"clock_skew":      "1-5",
+				}),
+			)
+
+			Expect(subject.Execute(status.Args{Verbose: true})).To(Succeed())
+		})
+
+		Context("when the guest clock has drifted", func() {
+			It("warns", func() {
+				gomock.InOrder(
+					mockProvisioner.EXPECT().GuestHeartbeat().Return(provision.Heartbeat{ClockSkew: 90 * time.Second}, nil),
+					mockUI.EXPECT().Say("Guest: 0.0% cpu steal, 0.0% memory pressure, clock skew 1m30s"),
+					mockUI.EXPECT().Say("[WARN] The guest clock is 1m30s off the host's, which breaks certificate and token validation. Try 'cf dev stop' and 'cf dev start'"),
+					mockAnalytics.EXPECT().Event("guest heartbeat", gomock.Any()),
+				)
+
+				Expect(subject.Execute(status.Args{Verbose: true})).To(Succeed())
+			})
+		})
+
+		Context("when the heartbeat cannot be read", func() {
+			It("warns", func() {
+				mockProvisioner.EXPECT().GuestHeartbeat().Return(provision.Heartbeat{}, errors.New("some-error"))
+				mockUI.EXPECT().Say("[WARN] Unable to read the guest heartbeat: some-error")
+
+				Expect(subject.Execute(status.Args{Verbose: true})).To(Succeed())
+			})
+		})
+	})
+
+	Context("when listing tasks", func() {
+		BeforeEach(func() {
+			mockProvisioner.EXPECT().Ping()
+			mockUI.EXPECT().Say("CF Dev is running")
+			expectVM()
+			mockProvisioner.EXPECT().DirectorReady()
+			mockUI.EXPECT().Say("BOSH Director: healthy")
+			expectCFAPI()
+			mockProvisioner.EXPECT().ProbeLatency()
+			mockProvisioner.EXPECT().GuestDiskUsage().Return(provision.DiskUsage{Mount: "/", UsedPercent: 10}, nil)
+			mockUI.EXPECT().Say(gomock.Any())
+			expectServices()
+		})
+
+		It("lists the director's recent tasks with the errors of failed ones", func() {
+			started := time.Date(2018, 10, 1, 12, 0, 0, 0, time.Local)
+			gomock.InOrder(
+				mockProvisioner.EXPECT().RecentTasks(10).Return([]bosh.TaskInfo{
+					{ID: 43, State: "done", Deployment: "cf", Description: "create deployment", StartedAt: started.Add(time.Hour), LastActivityAt: started.Add(time.Hour + 90*time.Second)},
+					{ID: 42, State: "error", Deployment: "cf", Description: "create deployment", Result: "some-error", StartedAt: started, LastActivityAt: started.Add(time.Minute)},
+				}, nil),
+				mockUI.EXPECT().Say("Recent BOSH tasks:"),
+				mockUI.EXPECT().Say("  43 done 2018-10-01 13:00:00 (1m30s) cf: create deployment"),
+				mockUI.EXPECT().Say("  42 error 2018-10-01 12:00:00 (1m0s) cf: create deployment"),
+				mockUI.EXPECT().Say("    Error: some-error"),
+			)
+
+			Expect(subject.Execute(status.Args{Tasks: true})).To(Succeed())
+		})
+
+		It("warns when they cannot be listed", func() {
+			mockProvisioner.EXPECT().RecentTasks(10).Return(nil, errors.New("some-error"))
+			mockUI.EXPECT().Say("[WARN] Unable to list BOSH tasks: some-error")
+
+			Expect(subject.Execute(status.Args{Tasks: true})).To(Succeed())
+		})
+	})
+
+	Context("when cf dev is not running", func() {
+		It("says so without probing", func() {
+			mockProvisioner.EXPECT().Ping().Return(errors.New("some-error"))
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil)
+			mockUI.EXPECT().Say("CF Dev is not running")
+
+			Expect(subject.Execute(status.Args{})).To(Succeed())
+		})
+
+		It("tells a vm that does not answer yet from one that is not running", func() {
+			mockProvisioner.EXPECT().Ping().Return(errors.New("some-error"))
+			expectVM()
+			mockUI.EXPECT().Say("[WARN] The CF Dev VM is running but not answering. It may still be booting, try 'cf dev wait --for vm-running'")
+
+			Expect(subject.Execute(status.Args{})).To(Succeed())
+		})
+	})
+
+	Context("when the CF API is unhealthy", func() {
+		It("warns", func() {
+			mockProvisioner.EXPECT().Ping()
+			mockUI.EXPECT().Say("CF Dev is running")
+			expectVM()
+			mockProvisioner.EXPECT().DirectorReady()
+			mockUI.EXPECT().Say("BOSH Director: healthy")
+			mockProvisioner.EXPECT().CFAPIReady().Return(errors.New("cloud controller responded with 502 Bad Gateway"))
+			mockUI.EXPECT().Say("[WARN] CF API: cloud controller responded with 502 Bad Gateway")
+			mockProvisioner.EXPECT().ProbeLatency()
+			mockProvisioner.EXPECT().GuestDiskUsage().Return(provision.DiskUsage{Mount: "/", UsedPercent: 10}, nil)
+			mockUI.EXPECT().Say(gomock.Any())
+			expectServices()
+
+			Expect(subject.Execute(status.Args{})).To(Succeed())
+		})
+	})
+
+	Context("with --json", func() {
+		var printed string
+
+		BeforeEach(func() {
+			mockUI.EXPECT().Say(gomock.Any()).Do(func(message string, _ ...interface{}) {
+				printed = message
+			})
+		})
+
+		It("prints everything it checked as a single json object", func() {
+			mockProvisioner.EXPECT().Ping()
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(true, nil)
+			mockHypervisor.EXPECT().Stats("cfdev").Return(hypervisor.Stats{CPUPercent: 25, MemoryDemandMB: 3000, MemoryAssignedMB: 8192}, nil)
+			mockProvisioner.EXPECT().DirectorReady()
+			mockProvisioner.EXPECT().CFAPIReady().Return(errors.New("some-error"))
+			mockProvisioner.EXPECT().ProbeLatency().Return([]provision.Latency{{Name: "Router", Duration: 12 * time.Millisecond}})
+			mockProvisioner.EXPECT().GuestDiskUsage().Return(provision.DiskUsage{Mount: "/", UsedPercent: 10, AvailableMB: 9000}, nil)
+			mockProvisioner.EXPECT().Deployments().Return([]string{"cf", "redis"}, nil)
+
+			Expect(subject.Execute(status.Args{JSON: true})).To(Succeed())
+			Expect(printed).To(MatchJSON(`{
+				"running": true,
+				"vm": {"running": true, "cpu_percent": 25, "memory_demand_mb": 3000, "memory_assigned_mb": 8192},
+				"director": {"healthy": true},
+				"cf_api": {"healthy": false, "error": "some-error"},
+				"latencies": [{"name": "Router", "ms": 12}],
+				"disk": {"mount": "/", "used_percent": 10, "available_mb": 9000},
+				"services": ["redis"]
+			}`))
+		})
+
+		It("reports a stopped vm", func() {
+			mockProvisioner.EXPECT().Ping().Return(errors.New("some-error"))
+			mockHypervisor.EXPECT().IsRunning("cfdev").Return(false, nil)
+
+			Expect(subject.Execute(status.Args{JSON: true})).To(Succeed())
+			Expect(printed).To(MatchJSON(`{"running": false, "vm": {"running": false}}`))
+		})
+	})
+})
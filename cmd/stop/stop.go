@@ -12,7 +12,7 @@ import (
 //go:generate mockgen -package mocks -destination mocks/cfdevd_client.go code.cloudfoundry.org/cfdev/cmd/stop CfdevdClient
 type CfdevdClient interface {
 	Uninstall() (string, error)
-	RemoveIPAlias() (string, error)
+	RemoveIPAlias(addrs ...string) (string, error)
 }
 
 type UI interface {
@@ -70,8 +70,6 @@ func (s *Stop) Cmd() *cobra.Command {
 	}
 }
 
-const vmName = "cfdev"
-
 func (s *Stop) RunE(cmd *cobra.Command, args []string) error {
 
 
@@ -91,11 +89,11 @@ func (s *Stop) RunE(cmd *cobra.Command, args []string) error {
 		reterr = errors.SafeWrap(err, "failed to destroy analyticsd")
 	}
 
-	if err := s.Hypervisor.Stop(vmName); err != nil {
+	if err := s.Hypervisor.Stop(s.Config.VMName()); err != nil {
 		reterr = errors.SafeWrap(err, "failed to stop the VM")
 	}
 
-	if err := s.Hypervisor.Destroy(vmName); err != nil {
+	if err := s.Hypervisor.Destroy(s.Config.VMName()); err != nil {
 		reterr = errors.SafeWrap(err, "failed to destroy the VM")
 	}
 
@@ -107,14 +105,21 @@ func (s *Stop) RunE(cmd *cobra.Command, args []string) error {
 		reterr = errors.SafeWrap(err, "failed to destroy vpnkit")
 	}
 
+	// The aliases and the network helper are shared, so they are left to
+	// another instance that is running.
+	if s.Config.CheckNoOtherInstance() == nil {
+		if err := s.HostNet.RemoveLoopbackAliases(s.Config.BoshDirectorIP, s.Config.CFRouterIP); err != nil {
+			reterr = errors.SafeWrap(err, "failed to remove IP aliases")
+		}
 
-	if err := s.HostNet.RemoveLoopbackAliases(s.Config.BoshDirectorIP, s.Config.CFRouterIP); err != nil {
-		reterr = errors.SafeWrap(err, "failed to remove IP aliases")
-	}
+		if runtime.GOOS == "darwin" {
+			if _, err := s.CfdevdClient.Uninstall(); err != nil {
+				reterr = errors.SafeWrap(err, "failed to uninstall cfdevd")
+			}
+		}
 
-	if runtime.GOOS == "darwin" {
-		if _, err := s.CfdevdClient.Uninstall(); err != nil {
-			reterr = errors.SafeWrap(err, "failed to uninstall cfdevd")
+		if err := s.Config.ClearActive(); err != nil {
+			reterr = err
 		}
 	}
 
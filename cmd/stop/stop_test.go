@@ -38,6 +38,7 @@ var _ = Describe("Stop", func() {
 		Expect(err).NotTo(HaveOccurred())
 
 		cfg = config.Config{
+			CFDevHome:      stateDir,
 			StateDir:       stateDir,
 			CFRouterIP:     "some-cf-router-ip",
 			BoshDirectorIP: "some-bosh-director-ip",
@@ -90,6 +91,44 @@ var _ = Describe("Stop", func() {
 		Expect(stopCmd.Execute()).To(Succeed())
 	})
 
+	It("clears the mark of the running instance", func() {
+		Expect(cfg.MarkActive()).To(Succeed())
+
+		mockAnalytics.EXPECT().Event(cfanalytics.STOP)
+		mockHost.EXPECT().CheckRequirements()
+		mockAnalyticsD.EXPECT().Stop()
+		mockAnalyticsD.EXPECT().Destroy()
+		mockHypervisor.EXPECT().Stop("cfdev")
+		mockHypervisor.EXPECT().Destroy("cfdev")
+		mockVpnkit.EXPECT().Stop()
+		mockVpnkit.EXPECT().Destroy()
+		mockHostNet.EXPECT().RemoveLoopbackAliases("some-bosh-director-ip", "some-cf-router-ip")
+		if runtime.GOOS == "darwin" {
+			mockCfdevdClient.EXPECT().Uninstall()
+		}
+
+		Expect(stopCmd.Execute()).To(Succeed())
+		_, active := cfg.ActiveInstance()
+		Expect(active).To(BeFalse())
+	})
+
+	It("leaves the aliases and the network helper to another instance that is running", func() {
+		Expect(cfg.WithInstance("other").MarkActive()).To(Succeed())
+
+		mockAnalytics.EXPECT().Event(cfanalytics.STOP)
+		mockHost.EXPECT().CheckRequirements()
+		mockAnalyticsD.EXPECT().Stop()
+		mockAnalyticsD.EXPECT().Destroy()
+		mockHypervisor.EXPECT().Stop("cfdev")
+		mockHypervisor.EXPECT().Destroy("cfdev")
+		mockVpnkit.EXPECT().Stop()
+		mockVpnkit.EXPECT().Destroy()
+
+		Expect(stopCmd.Execute()).To(Succeed())
+		name, _ := cfg.ActiveInstance()
+		Expect(name).To(Equal("other"))
+	})
+
 	Context("stopping the VM fails", func() {
 		It("stops the others and returns VM error", func() {
 			mockAnalytics.EXPECT().Event(cfanalytics.STOP)
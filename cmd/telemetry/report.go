@@ -0,0 +1,123 @@
+package telemetry
+
+import (
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	"code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+	"github.com/spf13/cobra"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func (t *Telemetry) reportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "report",
+		Short: "Summarize the telemetry kept on this machine",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return t.Report()
+		},
+	}
+}
+
+// Report summarizes the events logged by the file sink and those queued
+// to be sent, so that users can see what telemetry leaves their machine.
+func (t *Telemetry) Report() error {
+	sinkConfig, err := sink.LoadConfig(t.Config.CFDevHome)
+	if err != nil {
+		return errors.SafeWrap(err, "reading the analytics sink configuration")
+	}
+
+	switch sinkConfig.Sink {
+	case sink.File:
+		logged, err := sink.ReadEvents(sinkConfig.Path)
+		if err != nil {
+			return errors.SafeWrap(err, "reading the logged events")
+		}
+		t.UI.Say(messages.T("telemetry.report-logged", map[string]interface{}{"Path": sinkConfig.Path, "Count": len(logged)}))
+		t.summarize(logged)
+	case sink.None:
+		t.UI.Say(messages.T("telemetry.report-none"))
+	default:
+		t.UI.Say(messages.T("telemetry.report-not-logged", map[string]interface{}{"Sink": sinkConfig.Sink}))
+	}
+
+	queued, err := sink.ReadEvents(sink.QueuePath(t.Config.CFDevHome))
+	if err != nil {
+		return errors.SafeWrap(err, "reading the queued events")
+	}
+	t.UI.Say(messages.T("telemetry.report-queued", map[string]interface{}{"Count": len(queued)}))
+	t.summarize(queued)
+	return nil
+}
+
+// summarize says how many of each event there are, when they were sent
+// and which properties they have.
+func (t *Telemetry) summarize(msgs []analytics.Message) {
+	type summary struct {
+		count      int
+		first      string
+		last       string
+		properties map[string]bool
+	}
+	summaries := map[string]*summary{}
+
+	for _, msg := range msgs {
+		name, timestamp, properties := describe(msg)
+		s, ok := summaries[name]
+		if !ok {
+			s = &summary{first: timestamp, properties: map[string]bool{}}
+			summaries[name] = s
+		}
+		s.count++
+		s.last = timestamp
+		for _, property := range properties {
+			s.properties[property] = true
+		}
+	}
+
+	var names []string
+	for name := range summaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := summaries[name]
+		var properties []string
+		for property := range s.properties {
+			properties = append(properties, property)
+		}
+		sort.Strings(properties)
+
+		t.UI.Say(messages.T("telemetry.report-event", map[string]interface{}{
+			"Event":      name,
+			"Count":      s.count,
+			"First":      s.first,
+			"Last":       s.last,
+			"Properties": strings.Join(properties, ", "),
+		}))
+	}
+}
+
+func describe(msg analytics.Message) (string, string, []string) {
+	const format = "2006-01-02 15:04"
+
+	switch m := msg.(type) {
+	case analytics.Track:
+		var properties []string
+		for property := range m.Properties {
+			properties = append(properties, property)
+		}
+		return m.Event, m.Timestamp.Local().Format(format), properties
+	case analytics.Identify:
+		var traits []string
+		for trait := range m.Traits {
+			traits = append(traits, trait)
+		}
+		return "identify", m.Timestamp.Local().Format(format), traits
+	default:
+		return "unknown", "", nil
+	}
+}
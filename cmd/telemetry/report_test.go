@@ -0,0 +1,80 @@
+package telemetry_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	"code.cloudfoundry.org/cfdev/cmd/telemetry"
+	"code.cloudfoundry.org/cfdev/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+type recordingUI struct {
+	said []string
+}
+
+func (r *recordingUI) Say(message string, args ...interface{}) {
+	r.said = append(r.said, fmt.Sprintf(message, args...))
+}
+
+var _ = Describe("Report", func() {
+	var (
+		ui        *recordingUI
+		cfdevHome string
+		subject   *telemetry.Telemetry
+		timestamp time.Time
+	)
+
+	BeforeEach(func() {
+		var err error
+		cfdevHome, err = ioutil.TempDir("", "cfdev-telemetry-report-")
+		Expect(err).NotTo(HaveOccurred())
+
+		ui = &recordingUI{}
+		subject = &telemetry.Telemetry{
+			UI:     ui,
+			Config: config.Config{CFDevHome: cfdevHome},
+		}
+		timestamp = time.Date(2018, 8, 8, 8, 8, 0, 0, time.Local)
+		os.Unsetenv("CFDEV_ANALYTICS_SINK")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(cfdevHome)
+	})
+
+	It("summarizes the events the file sink logged and those queued", func() {
+		Expect(ioutil.WriteFile(filepath.Join(cfdevHome, "analytics-sink.json"), []byte(`{"sink": "file"}`), 0644)).To(Succeed())
+		logged := &sink.FileSink{Path: filepath.Join(cfdevHome, "analytics", "events.jsonl")}
+		Expect(logged.Enqueue(analytics.Track{Event: "app push", Timestamp: timestamp, Properties: analytics.Properties{"os": "darwin"}})).To(Succeed())
+		Expect(logged.Enqueue(analytics.Track{Event: "app push", Timestamp: timestamp.Add(time.Hour), Properties: analytics.Properties{"buildpack": "go"}})).To(Succeed())
+		Expect(logged.Enqueue(analytics.Track{Event: "app created", Timestamp: timestamp})).To(Succeed())
+		queued := &sink.FileSink{Path: sink.QueuePath(cfdevHome)}
+		Expect(queued.Enqueue(analytics.Track{Event: "app created", Timestamp: timestamp})).To(Succeed())
+
+		Expect(subject.Report()).To(Succeed())
+
+		Expect(ui.said).To(Equal([]string{
+			"Events logged to " + logged.Path + ": 3",
+			"  app created x1 (2018-08-08 08:08 to 2018-08-08 08:08): ",
+			"  app push x2 (2018-08-08 08:08 to 2018-08-08 09:08): buildpack, os",
+			"Events waiting to be sent: 1",
+			"  app created x1 (2018-08-08 08:08 to 2018-08-08 08:08): ",
+		}))
+	})
+
+	It("says when the sink does not keep the events", func() {
+		Expect(subject.Report()).To(Succeed())
+
+		Expect(ui.said).To(Equal([]string{
+			`Events sent to segment are not kept on this machine. To keep a log of them, set {"sink": "file"} in analytics-sink.json`,
+			"Events waiting to be sent: 0",
+		}))
+	})
+})
@@ -2,7 +2,9 @@ package telemetry
 
 import (
 	"code.cloudfoundry.org/cfdev/cfanalytics"
+	"code.cloudfoundry.org/cfdev/config"
 	"code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +16,7 @@ type Toggle interface {
 	Enabled() bool
 	SetCustomAnalyticsEnabled(value bool) error
 	SetCFAnalyticsEnabled(value bool) error
+	Override() string
 }
 
 type Analytics interface {
@@ -33,6 +36,7 @@ type Telemetry struct {
 	Analytics       Analytics
 	AnalyticsToggle Toggle
 	AnalyticsD      AnalyticsD
+	Config          config.Config
 	Args            struct {
 		FlagOff bool
 		FlagOn  bool
@@ -48,31 +52,35 @@ func (t *Telemetry) Cmd() *cobra.Command {
 
 	cmd.PersistentFlags().BoolVar(&t.Args.FlagOff, "off", false, "Disable the collection of anonymous usage telemetry")
 	cmd.PersistentFlags().BoolVar(&t.Args.FlagOn, "on", false, "Enable the collection of anonymous usage telemetry")
+	cmd.AddCommand(t.reportCmd())
 	return cmd
 }
 
 func (t *Telemetry) RunE(cmd *cobra.Command, args []string) error {
 	if t.Args.FlagOff {
-		err := t.turnTelemetryOff()
+		err := t.TurnOff()
 		if err != nil {
 			return err
 		}
 	} else if t.Args.FlagOn {
-		err := t.turnTelemetryOn()
+		err := t.TurnOn()
 		if err != nil {
 			return err
 		}
 	}
 
-	if t.AnalyticsToggle.Enabled() {
-		t.UI.Say("Telemetry is turned ON")
+	if override := t.AnalyticsToggle.Override(); override != "" {
+		t.UI.Say(messages.T("telemetry.overridden", map[string]interface{}{"Override": override}))
+	} else if t.AnalyticsToggle.Enabled() {
+		t.UI.Say(messages.T("telemetry.on"))
 	} else {
-		t.UI.Say("Telemetry is turned OFF")
+		t.UI.Say(messages.T("telemetry.off"))
 	}
 	return nil
 }
 
-func (t *Telemetry)turnTelemetryOff() error {
+// TurnOff stops collecting telemetry, stopping analyticsd if it runs.
+func (t *Telemetry) TurnOff() error {
 	t.Analytics.Event(cfanalytics.STOP_TELEMETRY)
 
 	if err := t.AnalyticsToggle.SetCustomAnalyticsEnabled(false); err != nil {
@@ -93,7 +101,8 @@ func (t *Telemetry)turnTelemetryOff() error {
 	return nil
 }
 
-func (t *Telemetry) turnTelemetryOn() error {
+// TurnOn collects telemetry, starting analyticsd if it does not run.
+func (t *Telemetry) TurnOn() error {
 	if err := t.AnalyticsToggle.SetCFAnalyticsEnabled(true); err != nil {
 		return errors.SafeWrap(err, "turning on telemetry")
 	}
@@ -1,7 +1,7 @@
 package telemetry_test
 
 import (
-	"code.cloudfoundry.org/cfdev/cfanalytics/toggle"
+	"code.cloudfoundry.org/cfdev/cfanalytics/consent"
 	"code.cloudfoundry.org/cfdev/cmd/telemetry"
 	"fmt"
 	"io/ioutil"
@@ -37,7 +37,7 @@ var _ = Describe("Telemetry", func() {
 		mockController *gomock.Controller
 		mockAnalyticsD *mocks.MockAnalyticsD
 		mockAnalytics  MockAnalitics
-		t0ggle         *toggle.Toggle
+		t0ggle         *consent.Store
 		telCmd         *cobra.Command
 		tempFilePath   string
 	)
@@ -51,10 +51,11 @@ var _ = Describe("Telemetry", func() {
 		tempFile, err := ioutil.TempFile("", "cfdev-telemetry-")
 		Expect(err).NotTo(HaveOccurred())
 		tempFilePath = tempFile.Name()
+		os.Unsetenv("DO_NOT_TRACK")
 	})
 
 	JustBeforeEach(func() {
-		t0ggle = toggle.New(tempFilePath)
+		t0ggle = consent.Open(tempFilePath)
 
 		subject := &telemetry.Telemetry{
 			UI:              &mockUI,
@@ -68,6 +69,7 @@ var _ = Describe("Telemetry", func() {
 	})
 
 	AfterEach(func() {
+		os.Unsetenv("DO_NOT_TRACK")
 		os.RemoveAll(tempFilePath)
 		mockController.Finish()
 	})
@@ -147,5 +149,27 @@ var _ = Describe("Telemetry", func() {
 				Expect(mockUI.WasCalledWith).To(Equal("Telemetry is turned OFF"))
 			})
 		})
+
+		Context("when DO_NOT_TRACK is set", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(
+					tempFilePath,
+					[]byte(`{"cfAnalyticsEnabled": true, "customAnalyticsEnabled": false}`),
+					0600)
+				Expect(err).NotTo(HaveOccurred())
+				os.Setenv("DO_NOT_TRACK", "1")
+			})
+
+			It("should display what turned it OFF", func() {
+				Expect(telCmd.Execute()).To(Succeed())
+
+				Expect(mockUI.WasCalledWith).To(Equal("Telemetry is turned OFF by DO_NOT_TRACK"))
+			})
+
+			It("cannot be turned ON", func() {
+				telCmd.SetArgs([]string{"--on"})
+				Expect(telCmd.Execute()).To(MatchError(ContainSubstring("telemetry is turned off by DO_NOT_TRACK")))
+			})
+		})
 	})
 })
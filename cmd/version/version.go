@@ -2,6 +2,7 @@ package version
 
 import (
 	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/messages"
 	"code.cloudfoundry.org/cfdev/metadata"
 	"code.cloudfoundry.org/cfdev/resource"
 	"code.cloudfoundry.org/cfdev/semver"
@@ -39,7 +40,7 @@ func (v *Version) Execute(pathTarball string) {
 
 	if pathTarball != "" {
 		if !exists(pathTarball) {
-			v.UI.Say(fmt.Sprintf("%s: file not found", pathTarball))
+			v.UI.Say(messages.T("version.file-not-found", map[string]interface{}{"Path": pathTarball}))
 			return
 		}
 
@@ -54,7 +55,7 @@ func (v *Version) Execute(pathTarball string) {
 		})
 
 		if !exists(filepath.Join(tmpDir, "metadata.yml")) {
-			v.UI.Say("Metadata not found version unknown")
+			v.UI.Say(messages.T("version.metadata-not-found"))
 			return
 		}
 
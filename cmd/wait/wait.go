@@ -0,0 +1,176 @@
+package wait
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/config"
+	e "code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+	"code.cloudfoundry.org/cfdev/metadata"
+	"code.cloudfoundry.org/cfdev/provision"
+	"github.com/spf13/cobra"
+)
+
+const (
+	VMRunning     = "vm-running"
+	DirectorReady = "director-ready"
+	CFAPI         = "cf-api"
+	servicePrefix = "service="
+
+	defaultPollInterval = 5 * time.Second
+)
+
+//go:generate mockgen -package mocks -destination mocks/ui.go code.cloudfoundry.org/cfdev/cmd/wait UI
+type UI interface {
+	Say(message string, args ...interface{})
+}
+
+//go:generate mockgen -package mocks -destination mocks/provisioner.go code.cloudfoundry.org/cfdev/cmd/wait Provisioner
+type Provisioner interface {
+	Ping() error
+	DirectorReady() error
+	CFAPIReady() error
+	ServiceReady(provision.Service) error
+	GetWhiteListedService(string, []provision.Service) (*provision.Service, error)
+}
+
+//go:generate mockgen -package mocks -destination mocks/metadata_reader.go code.cloudfoundry.org/cfdev/cmd/wait MetaDataReader
+type MetaDataReader interface {
+	Read(tarballPath string) (metadata.Metadata, error)
+}
+
+type Wait struct {
+	UI             UI
+	Provisioner    Provisioner
+	MetaDataReader MetaDataReader
+	Config         config.Config
+	PollInterval   time.Duration
+}
+
+type Args struct {
+	For     string
+	Timeout time.Duration
+	JSON    bool
+}
+
+// Result is what --json prints once waiting is over.
+type Result struct {
+	Condition      string  `json:"condition"`
+	Ready          bool    `json:"ready"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+	Error          string  `json:"error,omitempty"`
+}
+
+func (w *Wait) Cmd() *cobra.Command {
+	args := Args{}
+	cmd := &cobra.Command{
+		Use:   "wait",
+		Short: "Block until part of the environment is ready",
+		Long: "Block until a condition holds or the timeout passes. Conditions are " +
+			strings.Join([]string{VMRunning, DirectorReady, CFAPI, servicePrefix + "<service>"}, ", "),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := w.Execute(args); err != nil {
+				return e.SafeWrap(err, "cf dev wait")
+			}
+			return nil
+		},
+	}
+
+	pf := cmd.PersistentFlags()
+	pf.StringVar(&args.For, "for", "", "condition to wait for")
+	pf.DurationVar(&args.Timeout, "timeout", 10*time.Minute, "how long to wait before giving up")
+	pf.BoolVar(&args.JSON, "json", false, "print the result as json")
+	return cmd
+}
+
+func (w *Wait) Execute(args Args) error {
+	check, err := w.condition(args.For)
+	if err != nil {
+		return err
+	}
+
+	interval := w.PollInterval
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+
+	if !args.JSON {
+		w.UI.Say(messages.T("wait.waiting", map[string]interface{}{"Condition": args.For}))
+	}
+
+	start := time.Now()
+	deadline := start.Add(args.Timeout)
+	for {
+		err = check()
+		if err == nil || !time.Now().Add(interval).Before(deadline) {
+			break
+		}
+		time.Sleep(interval)
+	}
+	elapsed := time.Since(start)
+
+	if args.JSON {
+		result := Result{
+			Condition:      args.For,
+			Ready:          err == nil,
+			ElapsedSeconds: elapsed.Seconds(),
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		bytes, merr := json.Marshal(result)
+		if merr != nil {
+			return e.SafeWrap(merr, "unable to marshal result")
+		}
+		w.UI.Say(string(bytes))
+	}
+
+	if err != nil {
+		return fmt.Errorf("timed out after %s waiting for %s: %s", args.Timeout, args.For, err)
+	}
+
+	if !args.JSON {
+		w.UI.Say(messages.T("wait.ready", map[string]interface{}{
+			"Condition": args.For,
+			"Elapsed":   elapsed.Round(time.Second),
+		}))
+	}
+	return nil
+}
+
+func (w *Wait) condition(name string) (func() error, error) {
+	switch {
+	case name == VMRunning:
+		return w.Provisioner.Ping, nil
+	case name == DirectorReady:
+		return w.Provisioner.DirectorReady, nil
+	case name == CFAPI:
+		return w.Provisioner.CFAPIReady, nil
+	case strings.HasPrefix(name, servicePrefix):
+		service, err := w.service(strings.TrimPrefix(name, servicePrefix))
+		if err != nil {
+			return nil, err
+		}
+		return func() error {
+			return w.Provisioner.ServiceReady(*service)
+		}, nil
+	case name == "":
+		return nil, fmt.Errorf("a condition must be given with --for")
+	default:
+		return nil, fmt.Errorf("unknown condition '%s'", name)
+	}
+}
+
+func (w *Wait) service(name string) (*provision.Service, error) {
+	metadataConfig, err := w.MetaDataReader.Read(filepath.Join(w.Config.CacheDir, "metadata.yml"))
+	if err != nil {
+		return nil, e.SafeWrap(err, "something went wrong while reading the assets. Please execute 'cf dev start'")
+	}
+
+	return w.Provisioner.GetWhiteListedService(name, metadataConfig.Services)
+}
@@ -0,0 +1,137 @@
+package wait_test
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/cmd/wait"
+	"code.cloudfoundry.org/cfdev/cmd/wait/mocks"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/metadata"
+	"code.cloudfoundry.org/cfdev/provision"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Wait", func() {
+	var (
+		mockController     *gomock.Controller
+		mockUI             *mocks.MockUI
+		mockProvisioner    *mocks.MockProvisioner
+		mockMetaDataReader *mocks.MockMetaDataReader
+		subject            *wait.Wait
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockUI = mocks.NewMockUI(mockController)
+		mockProvisioner = mocks.NewMockProvisioner(mockController)
+		mockMetaDataReader = mocks.NewMockMetaDataReader(mockController)
+
+		subject = &wait.Wait{
+			UI:             mockUI,
+			Provisioner:    mockProvisioner,
+			MetaDataReader: mockMetaDataReader,
+			Config:         config.Config{CacheDir: "some-cache-dir"},
+			PollInterval:   time.Millisecond,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("polls until the vm is running", func() {
+		gomock.InOrder(
+			mockUI.EXPECT().Say("Waiting for vm-running..."),
+			mockProvisioner.EXPECT().Ping().Return(errors.New("not yet")),
+			mockProvisioner.EXPECT().Ping().Return(errors.New("not yet")),
+			mockProvisioner.EXPECT().Ping(),
+			mockUI.EXPECT().Say("vm-running is ready after 0s"),
+		)
+
+		Expect(subject.Execute(wait.Args{For: "vm-running", Timeout: time.Minute})).To(Succeed())
+	})
+
+	It("waits for the director", func() {
+		mockUI.EXPECT().Say(gomock.Any()).Times(2)
+		mockProvisioner.EXPECT().DirectorReady()
+
+		Expect(subject.Execute(wait.Args{For: "director-ready", Timeout: time.Minute})).To(Succeed())
+	})
+
+	It("waits for the cf api", func() {
+		mockUI.EXPECT().Say(gomock.Any()).Times(2)
+		mockProvisioner.EXPECT().CFAPIReady()
+
+		Expect(subject.Execute(wait.Args{For: "cf-api", Timeout: time.Minute})).To(Succeed())
+	})
+
+	It("waits for a service's deployment", func() {
+		services := []provision.Service{{Name: "Mysql", Flagname: "mysql", Deployment: "cf-mysql"}}
+		mockMetaDataReader.EXPECT().Read(filepath.Join("some-cache-dir", "metadata.yml")).Return(metadata.Metadata{Services: services}, nil)
+		mockProvisioner.EXPECT().GetWhiteListedService("mysql", services).Return(&services[0], nil)
+		mockUI.EXPECT().Say(gomock.Any()).Times(2)
+		mockProvisioner.EXPECT().ServiceReady(services[0])
+
+		Expect(subject.Execute(wait.Args{For: "service=mysql", Timeout: time.Minute})).To(Succeed())
+	})
+
+	Context("when the condition does not hold before the timeout", func() {
+		It("returns an error", func() {
+			mockUI.EXPECT().Say("Waiting for cf-api...")
+			mockProvisioner.EXPECT().CFAPIReady().Return(errors.New("some-error")).MinTimes(1)
+
+			err := subject.Execute(wait.Args{For: "cf-api", Timeout: 10 * time.Millisecond})
+			Expect(err).To(MatchError("timed out after 10ms waiting for cf-api: some-error"))
+		})
+	})
+
+	Context("when --json is given", func() {
+		It("prints the result as json", func() {
+			var output string
+			mockUI.EXPECT().Say(gomock.Any()).Do(func(message string, _ ...interface{}) {
+				output = message
+			})
+			mockProvisioner.EXPECT().Ping()
+
+			Expect(subject.Execute(wait.Args{For: "vm-running", Timeout: time.Minute, JSON: true})).To(Succeed())
+
+			var result wait.Result
+			Expect(json.Unmarshal([]byte(output), &result)).To(Succeed())
+			Expect(result.Condition).To(Equal("vm-running"))
+			Expect(result.Ready).To(BeTrue())
+			Expect(result.Error).To(BeEmpty())
+		})
+
+		It("includes the error when timing out", func() {
+			var output string
+			mockUI.EXPECT().Say(gomock.Any()).Do(func(message string, _ ...interface{}) {
+				output = message
+			})
+			mockProvisioner.EXPECT().Ping().Return(errors.New("some-error")).MinTimes(1)
+
+			Expect(subject.Execute(wait.Args{For: "vm-running", Timeout: 0, JSON: true})).NotTo(Succeed())
+
+			var result wait.Result
+			Expect(json.Unmarshal([]byte(output), &result)).To(Succeed())
+			Expect(result.Ready).To(BeFalse())
+			Expect(result.Error).To(Equal("some-error"))
+		})
+	})
+
+	Context("when the condition is unknown", func() {
+		It("returns an error", func() {
+			Expect(subject.Execute(wait.Args{For: "something"})).To(MatchError("unknown condition 'something'"))
+		})
+	})
+
+	Context("when no condition is given", func() {
+		It("returns an error", func() {
+			Expect(subject.Execute(wait.Args{})).To(MatchError("a condition must be given with --for"))
+		})
+	})
+})
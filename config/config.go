@@ -2,10 +2,12 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 
 	"code.cloudfoundry.org/cfdev/errors"
 
@@ -32,11 +34,25 @@ var (
 
 	cliVersion string
 	buildVersion string
+
+	releaseManifestUrl string
+)
+
+// DefaultBoshDirectorIP and DefaultCFRouterIP are the addresses
+// dev.cfdev.sh and the deps are set up for. DefaultCFSubnet is the subnet
+// the cf deployment's cloud config ships with.
+const (
+	DefaultBoshDirectorIP = "10.144.0.4"
+	DefaultCFRouterIP     = "10.144.0.34"
+	DefaultCFSubnet       = "10.144.0.0/16"
 )
 
 type Config struct {
+	Instance               string
 	BoshDirectorIP         string
 	CFRouterIP             string
+	CFSubnet               string
+	ServicesSubnet         string
 	HostIP                 string
 	CFDevHome              string
 	StateDir               string
@@ -45,6 +61,7 @@ type Config struct {
 	CacheDir               string
 	VpnKitStateDir         string
 	LogDir                 string
+	DataDir                string
 	DepsFile               *string
 	Dependencies           resource.Catalog
 	CFDevDSocketPath       string
@@ -54,16 +71,148 @@ type Config struct {
 	AnalyticsKey           string
 	ServicesDir            string
 	CFDomain               string
+	// ReleaseManifestURL is where self-update reads the latest release's
+	// manifest.json, which names each platform's binary relative to it.
+	ReleaseManifestURL string
+	// CFAdminUsername and CFAdminPassword are the account deploy-cf gives
+	// CF's admin user.
+	CFAdminUsername string
+	CFAdminPassword string
+	Garden          GardenConfig
+	// ComponentProperties overrides bosh job properties of CF components when
+	// CF is provisioned. It is keyed by component (cc, uaa or diego) and then
+	// by dotted property path, e.g. cc.default_app_memory.
+	ComponentProperties map[string]map[string]string
+	Quota               QuotaConfig
+	// MACAddress pins the vm's network adapter so the host's DHCP server
+	// leases it the same address on every start. Empty lets the hypervisor
+	// pick one.
+	MACAddress string
+	// GPU asks for the vm to be given a partition of the host's GPU.
+	GPU bool
+	// VM has the vm's optional hardware, which the hypervisor is asked for
+	// when cf dev start creates it.
+	VM VMConfig
+	// Hypervisor picks the vm backend. Empty uses the platform's own,
+	// Hyper-V or hyperkit; "qemu" uses QEMU for hosts that have neither,
+	// and is the default on linux and on arm64 macs.
+	Hypervisor string
+	QEMU       QEMUConfig
+	// Seed lists service instances to create once CF and its services are
+	// deployed, so a team's usual dependencies exist as soon as start ends.
+	Seed SeedConfig
+	// ParallelDeploys is how many services are deployed at once. Zero and
+	// one deploy them in turn.
+	ParallelDeploys int
+	// MemoryMB, Cpus, Registries and Services are what cf dev start uses
+	// when its flags are not given. Zero memory and cpus are worked out
+	// from the deps' defaults and the host.
+	MemoryMB   int
+	Cpus       int
+	Registries []string
+	Services   []string
+	// DiskSizeMB is the size the vm's disk can grow to. Zero keeps the
+	// backend's default.
+	DiskSizeMB int
+	// Profile is the named profile the resources were taken from, if any.
+	Profile string
+	// Telemetry answers the telemetry prompt for users who have not been
+	// asked yet. Nil prompts them.
+	Telemetry *bool
+	Proxy     ProxyConfig
+	// ConfigFile is the config.yml the settings are read from, which stays
+	// in the original home when the home is moved.
+	ConfigFile string
+}
+
+// VMConfig is the optional hardware of the vm. DataDiskMB attaches a disk
+// of that size kept in DataDir, which outlives the vm. The rest only Hyper-V
+// supports.
+type VMConfig struct {
+	DataDiskMB           int
+	MinMemoryMB          int
+	MaxMemoryMB          int
+	NestedVirtualization bool
+	SecureBoot           bool
+	TPM                  bool
+}
+
+// SeedConfig holds the service instances created after provisioning and
+// the space they are created in.
+type SeedConfig struct {
+	Org       string
+	Space     string
+	Instances []ServiceInstanceConfig
+}
+
+// ServiceInstanceConfig is a service instance to create. It is bound to
+// BindApp when that is set and the app exists.
+type ServiceInstanceConfig struct {
+	Service string
+	Plan    string
+	Name    string
+	BindApp string
+}
+
+// QEMUConfig holds the settings of the QEMU backend.
+type QEMUConfig struct {
+	// Binary is the qemu-system-x86_64 executable, looked up on the PATH
+	// unless it is a path.
+	Binary string
+	// Accel is the QEMU accelerator: tcg emulates the cpu and works
+	// anywhere, while kvm, hvf and whpx are much faster but need hardware
+	// virtualization on the host.
+	Accel string
+	// Firmware is the UEFI firmware the vm boots with.
+	Firmware string
+}
+
+// GardenConfig holds garden defaults applied when CF is provisioned.
+// Zero values leave the deployment's own defaults in place.
+type GardenConfig struct {
+	MaxContainers int
+	DiskQuotaMB   int
+	GraceTime     string
+}
+
+// QuotaConfig limits what orgs without a quota of their own can use, so a
+// runaway script cannot exhaust the vm. Zero values leave CF's default quota
+// as it is.
+type QuotaConfig struct {
+	MemoryMB     int
+	AppInstances int
 }
 
 func NewConfig() (Config, error) {
-	cfdevHome := getCfdevHome()
+	configFile := FilePath(getCfdevHome())
 
 	catalog, err := catalog()
 	if err != nil {
 		return Config{}, err
 	}
 
+	file, err := LoadFile(configFile)
+	if err != nil {
+		return Config{}, err
+	}
+
+	instances, err := serviceInstances()
+	if err != nil {
+		return Config{}, err
+	}
+
+	// A home moved with cf dev config set home is recorded in the config
+	// file, which stays behind.
+	cfdevHome := filepath.Dir(configFile)
+	if file.Home != "" {
+		cfdevHome = file.Home
+	}
+
+	// Every setting can be overridden with a CFDEV_ variable, for CI
+	// pipelines that cannot answer prompts or pass flags. The state and
+	// logs follow CFDEV_HOME and CFDEV_INSTANCE.
+	cacheDir := envOr("CFDEV_CACHE_DIR", filepath.Join(cfdevHome, "cache"))
+
 	var analytixKey string
 	if os.Getenv("CFDEV_MODE") == "debug" || analyticsKey == "" {
 		analytixKey = testAnalyticsKey
@@ -73,27 +222,200 @@ func NewConfig() (Config, error) {
 
 	depsFile := ""
 
-	return Config{
-		BoshDirectorIP:         "10.144.0.4",
-		CFRouterIP:             "10.144.0.34",
-		HostIP:                 "192.168.65.2",
+	conf := Config{
+		BoshDirectorIP:         stringSetting("CFDEV_BOSH_DIRECTOR_IP", file.Network.DirectorIP, DefaultBoshDirectorIP),
+		CFRouterIP:             stringSetting("CFDEV_ROUTER_IP", file.Network.RouterIP, DefaultCFRouterIP),
+		CFSubnet:               stringSetting("CFDEV_CF_SUBNET", file.Network.CFSubnet, DefaultCFSubnet),
+		ServicesSubnet:         stringSetting("CFDEV_SERVICES_SUBNET", file.Network.ServicesSubnet, ""),
+		HostIP:                 envOr("CFDEV_HOST_IP", "192.168.65.2"),
 		CFDevHome:              cfdevHome,
+		ConfigFile:             configFile,
 		StateDir:               filepath.Join(cfdevHome, "state"),
 		StateBosh:              filepath.Join(cfdevHome, "state", "bosh"),
 		StateLinuxkit:          filepath.Join(cfdevHome, "state", "linuxkit"),
-		CacheDir:               filepath.Join(cfdevHome, "cache"),
+		CacheDir:               cacheDir,
 		VpnKitStateDir:         filepath.Join(cfdevHome, "state", "vpnkit"),
 		LogDir:                 filepath.Join(cfdevHome, "log"),
+		DataDir:                filepath.Join(cfdevHome, "data"),
 		DepsFile:               &depsFile,
 		Dependencies:           catalog,
-		CFDevDSocketPath:       filepath.Join("/var", "tmp", "cfdevd.socket"),
-		CFDevDInstallationPath: filepath.Join("/Library", "PrivilegedHelperTools", "org.cloudfoundry.cfdevd"),
+		CFDevDSocketPath:       envOr("CFDEV_CFDEVD_SOCKET", filepath.Join("/var", "tmp", "cfdevd.socket")),
+		CFDevDInstallationPath: envOr("CFDEV_CFDEVD_PATH", filepath.Join("/Library", "PrivilegedHelperTools", "org.cloudfoundry.cfdevd")),
 		CliVersion:             semver.Must(semver.New(cliVersion)),
 		BuildVersion:           buildVersion,
+		ReleaseManifestURL:     envOr("CFDEV_RELEASE_MANIFEST", releaseManifestUrl),
 		AnalyticsKey:           analytixKey,
 		ServicesDir:            filepath.Join(cfdevHome, "services"),
-		CFDomain:               "dev.cfdev.sh",
-	}, nil
+		CFDomain:               envOr("CFDEV_DOMAIN", "dev.cfdev.sh"),
+		CFAdminUsername:        envOr("CFDEV_CF_ADMIN_USERNAME", "admin"),
+		CFAdminPassword:        envOr("CFDEV_CF_ADMIN_PASSWORD", "admin"),
+		Garden: GardenConfig{
+			MaxContainers: int(aToUint64(os.Getenv("CFDEV_GARDEN_MAX_CONTAINERS"))),
+			DiskQuotaMB:   int(aToUint64(os.Getenv("CFDEV_GARDEN_DISK_QUOTA_MB"))),
+			GraceTime:     os.Getenv("CFDEV_GARDEN_GRACE_TIME"),
+		},
+		ComponentProperties: componentProperties(),
+		Quota: QuotaConfig{
+			MemoryMB:     int(aToUint64(os.Getenv("CFDEV_QUOTA_MEMORY_MB"))),
+			AppInstances: int(aToUint64(os.Getenv("CFDEV_QUOTA_APP_INSTANCES"))),
+		},
+		MACAddress: os.Getenv("CFDEV_MAC_ADDRESS"),
+		GPU:        os.Getenv("CFDEV_GPU") == "true",
+		Hypervisor: envOr("CFDEV_HYPERVISOR", defaultHypervisor()),
+		QEMU: QEMUConfig{
+			Binary:   envOr("CFDEV_QEMU_BINARY", "qemu-system-x86_64"),
+			Accel:    envOr("CFDEV_QEMU_ACCEL", defaultAccel()),
+			Firmware: envOr("CFDEV_QEMU_FIRMWARE", filepath.Join(cacheDir, "UEFI.fd")),
+		},
+		VM: VMConfig{
+			DataDiskMB:           intSetting("CFDEV_DATA_DISK_MB", file.VM.DataDisk, 0),
+			MinMemoryMB:          intSetting("CFDEV_MIN_MEMORY_MB", file.VM.MinMemory, 0),
+			MaxMemoryMB:          intSetting("CFDEV_MAX_MEMORY_MB", file.VM.MaxMemory, 0),
+			NestedVirtualization: boolSetting("CFDEV_NESTED_VIRTUALIZATION", file.VM.NestedVirtualization),
+			SecureBoot:           boolSetting("CFDEV_SECURE_BOOT", file.VM.SecureBoot),
+			TPM:                  boolSetting("CFDEV_TPM", file.VM.TPM),
+		},
+		Seed: SeedConfig{
+			Org:       envOr("CFDEV_SEED_ORG", "cfdev-org"),
+			Space:     envOr("CFDEV_SEED_SPACE", "cfdev-space"),
+			Instances: instances,
+		},
+		ParallelDeploys: intSetting("CFDEV_PARALLEL_DEPLOYS", file.ParallelDeploys, 0),
+		Telemetry:       file.Telemetry,
+		Proxy: ProxyConfig{
+			HTTP:     file.Proxy.HTTP,
+			HTTPS:    file.Proxy.HTTPS,
+			NoProxy:  file.Proxy.NoProxy,
+			Username: os.Getenv("CFDEV_PROXY_USERNAME"),
+			Password: os.Getenv("CFDEV_PROXY_PASSWORD"),
+		},
+	}
+
+	return conf.withResources(file.Resources).WithInstance(os.Getenv("CFDEV_INSTANCE")), nil
+}
+
+// WithInstance namespaces the vm and everything kept about it on the host
+// so that more than one cfdev vm can exist side by side. Downloads in the
+// cache are shared. The guest still listens on fixed ports and CF on a fixed
+// domain, so only one instance can be running at a time, which start checks
+// with CheckNoOtherInstance.
+func (c Config) WithInstance(name string) Config {
+	if name == "" {
+		return c
+	}
+
+	home := filepath.Join(c.CFDevHome, "instances", name)
+	c.Instance = name
+	c.StateDir = filepath.Join(home, "state")
+	c.StateBosh = filepath.Join(home, "state", "bosh")
+	c.StateLinuxkit = filepath.Join(home, "state", "linuxkit")
+	c.VpnKitStateDir = filepath.Join(home, "state", "vpnkit")
+	c.LogDir = filepath.Join(home, "log")
+	c.ServicesDir = filepath.Join(home, "services")
+	c.DataDir = filepath.Join(home, "data")
+	return c
+}
+
+// DataDiskPath is where the vm's data disk is kept, in the format its
+// hypervisor attaches.
+func (c Config) DataDiskPath() string {
+	if runtime.GOOS == "windows" && c.Hypervisor == "" {
+		return filepath.Join(c.DataDir, "data.vhdx")
+	}
+	return filepath.Join(c.DataDir, "data.qcow2")
+}
+
+// VMName is the name the instance's vm is registered under with the
+// hypervisor.
+func (c Config) VMName() string {
+	if c.Instance == "" {
+		return "cfdev"
+	}
+	return "cfdev-" + c.Instance
+}
+
+// DaemonLabel suffixes label with the instance, if any, so each instance
+// gets its own daemons.
+func (c Config) DaemonLabel(label string) string {
+	if c.Instance == "" {
+		return label
+	}
+	return label + "." + c.Instance
+}
+
+// componentProperties reads CFDEV_<COMPONENT>_PROPERTIES, a comma separated
+// list of property=value pairs, for each overridable CF component.
+func componentProperties() map[string]map[string]string {
+	properties := map[string]map[string]string{}
+	for _, component := range []string{"cc", "uaa", "diego"} {
+		value := os.Getenv("CFDEV_" + strings.ToUpper(component) + "_PROPERTIES")
+		if value == "" {
+			continue
+		}
+
+		properties[component] = map[string]string{}
+		for _, pair := range strings.Split(value, ",") {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			properties[component][strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return properties
+}
+
+// serviceInstances reads CFDEV_SEED_SERVICE_INSTANCES, a comma separated
+// list of service:plan:name[:app] entries. Every malformed entry is named
+// in the error, rather than seeding less than was asked for.
+func serviceInstances() ([]ServiceInstanceConfig, error) {
+	var (
+		instances []ServiceInstanceConfig
+		malformed []string
+	)
+	for _, entry := range strings.Split(os.Getenv("CFDEV_SEED_SERVICE_INSTANCES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) < 3 || len(parts) > 4 || hasEmpty(parts) {
+			malformed = append(malformed, fmt.Sprintf("%q", entry))
+			continue
+		}
+
+		instance := ServiceInstanceConfig{
+			Service: parts[0],
+			Plan:    parts[1],
+			Name:    parts[2],
+		}
+		if len(parts) == 4 {
+			instance.BindApp = parts[3]
+		}
+		instances = append(instances, instance)
+	}
+
+	if len(malformed) > 0 {
+		return nil, fmt.Errorf("CFDEV_SEED_SERVICE_INSTANCES has malformed entries %s, each must be service:plan:name or service:plan:name:app", strings.Join(malformed, ", "))
+	}
+	return instances, nil
+}
+
+func hasEmpty(parts []string) bool {
+	for _, part := range parts {
+		if part == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
 }
 
 func aToUint64(a string) uint64 {
@@ -127,30 +449,41 @@ func catalog() (resource.Catalog, error) {
 		},
 	}
 
-	if runtime.GOOS != "windows" {
+	switch runtime.GOOS {
+	case "windows":
 		catalog.Items = append(catalog.Items,
 			resource.Item{
 				URL:   analyticsdUrl,
-				Name:  "analyticsd",
+				Name:  "analyticsd.exe",
 				MD5:   analyticsdMd5,
 				Size:  aToUint64(analyticsdSize),
 				InUse: true,
-			},
+			})
+	case "linux":
+		// QEMU networks the vm on linux, so there is no cfdevd to fetch.
+		catalog.Items = append(catalog.Items,
 			resource.Item{
-				URL:   cfdevdUrl,
-				Name:  "cfdevd",
-				MD5:   cfdevdMd5,
-				Size:  aToUint64(cfdevdSize),
+				URL:   analyticsdUrl,
+				Name:  "analyticsd",
+				MD5:   analyticsdMd5,
+				Size:  aToUint64(analyticsdSize),
 				InUse: true,
 			})
-	} else {
+	default:
 		catalog.Items = append(catalog.Items,
 			resource.Item{
 				URL:   analyticsdUrl,
-				Name:  "analyticsd.exe",
+				Name:  "analyticsd",
 				MD5:   analyticsdMd5,
 				Size:  aToUint64(analyticsdSize),
 				InUse: true,
+			},
+			resource.Item{
+				URL:   cfdevdUrl,
+				Name:  "cfdevd",
+				MD5:   cfdevdMd5,
+				Size:  aToUint64(cfdevdSize),
+				InUse: true,
 			})
 	}
 
@@ -160,6 +493,25 @@ func catalog() (resource.Catalog, error) {
 	return catalog, nil
 }
 
+// defaultHypervisor is QEMU where there is neither Hyper-V nor hyperkit:
+// on linux, and on arm64 macs, which hyperkit does not run on.
+func defaultHypervisor() string {
+	if runtime.GOOS == "linux" || (runtime.GOOS == "darwin" && runtime.GOARCH == "arm64") {
+		return "qemu"
+	}
+	return ""
+}
+
+// defaultAccel is kvm on linux. Elsewhere QEMU emulates the x86_64 vm,
+// as hvf and whpx cannot run it on an arm64 mac and Hyper-V is used on
+// windows anyway.
+func defaultAccel() string {
+	if runtime.GOOS == "linux" {
+		return "kvm"
+	}
+	return "tcg"
+}
+
 func getCfdevHome() string {
 	cfdevHome := os.Getenv("CFDEV_HOME")
 	if cfdevHome != "" {
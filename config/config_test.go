@@ -0,0 +1,170 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithInstance", func() {
+	var conf config.Config
+
+	BeforeEach(func() {
+		conf = config.Config{
+			CFDevHome:     "some-home",
+			CacheDir:      filepath.Join("some-home", "cache"),
+			StateDir:      filepath.Join("some-home", "state"),
+			StateLinuxkit: filepath.Join("some-home", "state", "linuxkit"),
+		}
+	})
+
+	It("leaves the default instance as it is", func() {
+		Expect(conf.WithInstance("")).To(Equal(conf))
+		Expect(conf.VMName()).To(Equal("cfdev"))
+		Expect(conf.DaemonLabel("some-label")).To(Equal("some-label"))
+	})
+
+	It("namespaces the vm and its state but shares the cache", func() {
+		conf = conf.WithInstance("other")
+
+		Expect(conf.Instance).To(Equal("other"))
+		Expect(conf.VMName()).To(Equal("cfdev-other"))
+		Expect(conf.DaemonLabel("some-label")).To(Equal("some-label.other"))
+		Expect(conf.CacheDir).To(Equal(filepath.Join("some-home", "cache")))
+		Expect(conf.StateDir).To(Equal(filepath.Join("some-home", "instances", "other", "state")))
+		Expect(conf.StateBosh).To(Equal(filepath.Join("some-home", "instances", "other", "state", "bosh")))
+		Expect(conf.StateLinuxkit).To(Equal(filepath.Join("some-home", "instances", "other", "state", "linuxkit")))
+		Expect(conf.VpnKitStateDir).To(Equal(filepath.Join("some-home", "instances", "other", "state", "vpnkit")))
+		Expect(conf.LogDir).To(Equal(filepath.Join("some-home", "instances", "other", "log")))
+		Expect(conf.ServicesDir).To(Equal(filepath.Join("some-home", "instances", "other", "services")))
+		Expect(conf.DataDir).To(Equal(filepath.Join("some-home", "instances", "other", "data")))
+	})
+})
+
+var _ = Describe("NewConfig", func() {
+	var (
+		cfdevHome string
+		variables = map[string]string{
+			"CFDEV_BOSH_DIRECTOR_IP": "10.0.0.4",
+			"CFDEV_ROUTER_IP":        "10.0.0.34",
+			"CFDEV_HOST_IP":          "10.0.0.2",
+			"CFDEV_DOMAIN":           "ci.example.com",
+			"CFDEV_CACHE_DIR":        "some-cache",
+			"CFDEV_CFDEVD_SOCKET":    "some-socket",
+			"CFDEV_CFDEVD_PATH":      "some-cfdevd",
+			"CFDEV_MEMORY":           "6144",
+			"CFDEV_CPUS":             "2",
+			"CFDEV_DISK_SIZE_MB":     "40960",
+			"CFDEV_REGISTRIES":       "registry.example.com:5000, other.example.com",
+			"CFDEV_SERVICES":         "mysql",
+		}
+	)
+
+	BeforeEach(func() {
+		var err error
+		cfdevHome, err = ioutil.TempDir("", "cfdev-home")
+		Expect(err).NotTo(HaveOccurred())
+		os.Setenv("CFDEV_HOME", cfdevHome)
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("CFDEV_HOME")
+		for name := range variables {
+			os.Unsetenv(name)
+		}
+		os.RemoveAll(cfdevHome)
+	})
+
+	It("lets the environment override the config file", func() {
+		Expect(ioutil.WriteFile(config.FilePath(cfdevHome), []byte("memory: 8192\ncpus: 6\nservices: [redis]\n"), 0644)).To(Succeed())
+
+		conf, err := config.NewConfig()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conf.MemoryMB).To(Equal(8192))
+		Expect(conf.Cpus).To(Equal(6))
+		Expect(conf.Services).To(Equal([]string{"redis"}))
+		Expect(conf.BoshDirectorIP).To(Equal("10.144.0.4"))
+
+		for name, value := range variables {
+			os.Setenv(name, value)
+		}
+
+		conf, err = config.NewConfig()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conf.BoshDirectorIP).To(Equal("10.0.0.4"))
+		Expect(conf.CFRouterIP).To(Equal("10.0.0.34"))
+		Expect(conf.HostIP).To(Equal("10.0.0.2"))
+		Expect(conf.CFDomain).To(Equal("ci.example.com"))
+		Expect(conf.CacheDir).To(Equal("some-cache"))
+		Expect(conf.QEMU.Firmware).To(Equal(filepath.Join("some-cache", "UEFI.fd")))
+		Expect(conf.CFDevDSocketPath).To(Equal("some-socket"))
+		Expect(conf.CFDevDInstallationPath).To(Equal("some-cfdevd"))
+		Expect(conf.MemoryMB).To(Equal(6144))
+		Expect(conf.Cpus).To(Equal(2))
+		Expect(conf.DiskSizeMB).To(Equal(40960))
+		Expect(conf.Registries).To(Equal([]string{"registry.example.com:5000", "other.example.com"}))
+		Expect(conf.Services).To(Equal([]string{"mysql"}))
+	})
+
+	It("reads the vm's hardware from the config file and the environment", func() {
+		Expect(ioutil.WriteFile(config.FilePath(cfdevHome), []byte("vm:\n  data_disk: 10240\n  min_memory: 4096\n  max_memory: 12288\n  tpm: true\n"), 0644)).To(Succeed())
+		os.Setenv("CFDEV_DATA_DISK_MB", "20480")
+		os.Setenv("CFDEV_TPM", "false")
+		os.Setenv("CFDEV_SECURE_BOOT", "true")
+		defer os.Unsetenv("CFDEV_DATA_DISK_MB")
+		defer os.Unsetenv("CFDEV_TPM")
+		defer os.Unsetenv("CFDEV_SECURE_BOOT")
+
+		conf, err := config.NewConfig()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conf.VM).To(Equal(config.VMConfig{
+			DataDiskMB:  20480,
+			MinMemoryMB: 4096,
+			MaxMemoryMB: 12288,
+			SecureBoot:  true,
+		}))
+		Expect(conf.DataDir).To(Equal(filepath.Join(cfdevHome, "data")))
+		Expect(filepath.Dir(conf.DataDiskPath())).To(Equal(conf.DataDir))
+	})
+
+	It("follows a home that was moved, reading the config file left behind", func() {
+		movedHome := filepath.Join(cfdevHome, "bigger-drive")
+		Expect(ioutil.WriteFile(config.FilePath(cfdevHome), []byte("home: "+movedHome+"\nmemory: 8192\n"), 0644)).To(Succeed())
+
+		conf, err := config.NewConfig()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conf.CFDevHome).To(Equal(movedHome))
+		Expect(conf.ConfigFile).To(Equal(config.FilePath(cfdevHome)))
+		Expect(conf.StateDir).To(Equal(filepath.Join(movedHome, "state")))
+		Expect(conf.CacheDir).To(Equal(filepath.Join(movedHome, "cache")))
+		Expect(conf.MemoryMB).To(Equal(8192))
+	})
+
+	Describe("CFDEV_SEED_SERVICE_INSTANCES", func() {
+		AfterEach(func() {
+			os.Unsetenv("CFDEV_SEED_SERVICE_INSTANCES")
+		})
+
+		It("reads the instances, with or without an app to bind", func() {
+			os.Setenv("CFDEV_SEED_SERVICE_INSTANCES", "p.mysql:db-small:orders-db, p.redis:cache:sessions:web,")
+
+			conf, err := config.NewConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conf.Seed.Instances).To(Equal([]config.ServiceInstanceConfig{
+				{Service: "p.mysql", Plan: "db-small", Name: "orders-db"},
+				{Service: "p.redis", Plan: "cache", Name: "sessions", BindApp: "web"},
+			}))
+		})
+
+		It("names every malformed entry", func() {
+			os.Setenv("CFDEV_SEED_SERVICE_INSTANCES", "p.mysql:db-small,p.redis:cache:sessions,p.rabbitmq::queue,a:b:c:d:e")
+
+			_, err := config.NewConfig()
+			Expect(err).To(MatchError(`CFDEV_SEED_SERVICE_INSTANCES has malformed entries "p.mysql:db-small", "p.rabbitmq::queue", "a:b:c:d:e", each must be service:plan:name or service:plan:name:app`))
+		})
+	})
+})
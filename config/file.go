@@ -0,0 +1,291 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/cfdev/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// File is the config.yml in the cfdev home, which a team can check into
+// its repos to share the settings its members start cf dev with, e.g.
+//
+//	memory: 8192
+//	cpus: 6
+//	registries: [registry.example.com:5000]
+//	services: [mysql, redis]
+//	telemetry: false
+//	proxy:
+//	  http: http://proxy.example.com:3128
+//
+// The environment overrides the file, and flags the environment.
+type File struct {
+	// Version is the format of the file, for a newer cf dev to upgrade it.
+	Version   int `yaml:"version,omitempty"`
+	Resources `yaml:",inline"`
+	// Home is where the rest of the cfdev home was moved to, e.g. onto a
+	// bigger drive.
+	Home      string `yaml:"home,omitempty"`
+	Telemetry *bool  `yaml:"telemetry,omitempty"`
+	// Proxy has no credentials, as the file is shared and readable by
+	// all. They come from the environment or the keychain instead.
+	Proxy struct {
+		HTTP    string `yaml:"http,omitempty"`
+		HTTPS   string `yaml:"https,omitempty"`
+		NoProxy string `yaml:"no_proxy,omitempty"`
+	} `yaml:"proxy,omitempty"`
+	// Network moves cf dev's networks out of the way of those the host
+	// routes elsewhere, e.g. through a VPN.
+	Network struct {
+		DirectorIP     string `yaml:"director_ip,omitempty"`
+		RouterIP       string `yaml:"router_ip,omitempty"`
+		CFSubnet       string `yaml:"cf_subnet,omitempty"`
+		ServicesSubnet string `yaml:"services_subnet,omitempty"`
+	} `yaml:"network,omitempty"`
+	// ParallelDeploys is how many services are deployed at once.
+	ParallelDeploys int `yaml:"parallel_deploys,omitempty"`
+	// VM is the vm's optional hardware. Sizes are in MB.
+	VM struct {
+		DataDisk             int   `yaml:"data_disk,omitempty"`
+		MinMemory            int   `yaml:"min_memory,omitempty"`
+		MaxMemory            int   `yaml:"max_memory,omitempty"`
+		NestedVirtualization *bool `yaml:"nested_virtualization,omitempty"`
+		SecureBoot           *bool `yaml:"secure_boot,omitempty"`
+		TPM                  *bool `yaml:"tpm,omitempty"`
+	} `yaml:"vm,omitempty"`
+}
+
+// Resources are what the vm is started with, which profiles can set as
+// well as the file. Nil is unset, so that a profile can set a resource to
+// zero or empty in place of the file's, e.g. services: [] to deploy none.
+type Resources struct {
+	// Memory and Disk are in MB.
+	Memory     *int     `yaml:"memory,omitempty"`
+	Cpus       *int     `yaml:"cpus,omitempty"`
+	Disk       *int     `yaml:"disk,omitempty"`
+	Registries []string `yaml:"registries,omitempty"`
+	Services   []string `yaml:"services,omitempty"`
+}
+
+// FilePath is where the config file is read from.
+func FilePath(cfdevHome string) string {
+	return filepath.Join(cfdevHome, "config.yml")
+}
+
+// LoadFile reads the config file at path. There are no settings when it
+// does not exist.
+func LoadFile(path string) (File, error) {
+	var file File
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return file, nil
+	}
+	// The version is checked first, as a newer format's settings would
+	// otherwise fail as unknown.
+	if err := checkFileVersion(path); err != nil {
+		return File{}, err
+	}
+	if err := checkProxyCredentials(path); err != nil {
+		return File{}, err
+	}
+	if err := readYAML(path, &file); err != nil {
+		return File{}, err
+	}
+	return file, nil
+}
+
+func checkFileVersion(path string) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.SafeWrap(err, "reading "+filepath.Base(path))
+	}
+
+	var versioned struct {
+		Version int `yaml:"version"`
+	}
+	if yaml.Unmarshal(contents, &versioned) == nil && versioned.Version > FileVersion {
+		return fmt.Errorf("%s was written by a newer cf dev (version %d, this one reads %d), upgrade the cf dev plugin", path, versioned.Version, FileVersion)
+	}
+	return nil
+}
+
+// checkProxyCredentials fails for a file that still has the proxy
+// credentials older versions kept in it, saying where they go now.
+func checkProxyCredentials(path string) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.SafeWrap(err, "reading "+filepath.Base(path))
+	}
+
+	var legacy struct {
+		Proxy struct {
+			Username string `yaml:"username"`
+			Password string `yaml:"password"`
+		} `yaml:"proxy"`
+	}
+	if yaml.Unmarshal(contents, &legacy) == nil && (legacy.Proxy.Username != "" || legacy.Proxy.Password != "") {
+		return fmt.Errorf("%s has the proxy's credentials, which cf dev no longer reads from it: remove proxy.username and proxy.password and set CFDEV_PROXY_USERNAME and CFDEV_PROXY_PASSWORD, or store them in the keychain, instead", path)
+	}
+	return nil
+}
+
+// SaveFile writes file to path, leaving out the settings it does not have.
+func SaveFile(path string, file File) error {
+	contents, err := yaml.Marshal(file)
+	if err != nil {
+		return errors.SafeWrap(err, "encoding "+filepath.Base(path))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.SafeWrap(err, "creating "+filepath.Dir(path))
+	}
+	if err := ioutil.WriteFile(path, contents, 0644); err != nil {
+		return errors.SafeWrap(err, "writing "+filepath.Base(path))
+	}
+	return nil
+}
+
+// withResources sets the resources r has, unless the environment does.
+func (c Config) withResources(r Resources) Config {
+	c.MemoryMB = resourceSetting("CFDEV_MEMORY", r.Memory, c.MemoryMB)
+	c.Cpus = resourceSetting("CFDEV_CPUS", r.Cpus, c.Cpus)
+	c.DiskSizeMB = resourceSetting("CFDEV_DISK_SIZE_MB", r.Disk, c.DiskSizeMB)
+	c.Registries = listSetting("CFDEV_REGISTRIES", r.Registries, c.Registries)
+	c.Services = listSetting("CFDEV_SERVICES", r.Services, c.Services)
+	return c
+}
+
+// readYAML strictly, so that a misspelt setting is not silently ignored.
+func readYAML(path string, dest interface{}) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.SafeWrap(err, "reading "+filepath.Base(path))
+	}
+
+	if err := yaml.UnmarshalStrict(contents, dest); err != nil {
+		return errors.SafeWrap(err, "parsing "+filepath.Base(path))
+	}
+	return nil
+}
+
+// ProxyConfig is the proxy the vm's traffic goes through, unless the
+// usual proxy environment variables are set.
+type ProxyConfig struct {
+	HTTP    string
+	HTTPS   string
+	NoProxy string
+	// Username and Password authenticate with the proxy, unless its URL
+	// has credentials of its own. They come from CFDEV_PROXY_USERNAME and
+	// CFDEV_PROXY_PASSWORD, or the keychain, never the file.
+	Username string
+	Password string
+}
+
+// Setenv sets the proxy environment variables that are not set, so that
+// everything that reads them, e.g. vpnkit's configuration, the BOSH
+// director and the apps' containers, uses the proxy. Only vpnkit, which
+// proxies the vm's traffic, is given the credentials.
+func (p ProxyConfig) Setenv() {
+	for _, variable := range []struct {
+		names []string
+		value string
+	}{
+		{[]string{"HTTP_PROXY", "http_proxy"}, p.withAuth(p.HTTP)},
+		{[]string{"HTTPS_PROXY", "https_proxy"}, p.withAuth(p.HTTPS)},
+		{[]string{"NO_PROXY", "no_proxy"}, p.NoProxy},
+	} {
+		if variable.value == "" || os.Getenv(variable.names[0]) != "" || os.Getenv(variable.names[1]) != "" {
+			continue
+		}
+		os.Setenv(variable.names[0], variable.value)
+	}
+}
+
+// withAuth adds the credentials to the proxy URL raw.
+func (p ProxyConfig) withAuth(raw string) string {
+	if raw == "" || p.Username == "" {
+		return raw
+	}
+
+	proxyURL, err := url.Parse(raw)
+	if err != nil || proxyURL.User != nil {
+		return raw
+	}
+	proxyURL.User = url.UserPassword(p.Username, p.Password)
+	return proxyURL.String()
+}
+
+// stringSetting is the environment variable key when it is set, and
+// otherwise the file's value or fallback.
+func stringSetting(key string, file, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	if file != "" {
+		return file
+	}
+	return fallback
+}
+
+// intSetting is the environment variable key when it is set, and
+// otherwise the file's value or fallback.
+func intSetting(key string, file, fallback int) int {
+	if value := aToUint64(os.Getenv(key)); value > 0 {
+		return int(value)
+	}
+	if file > 0 {
+		return file
+	}
+	return fallback
+}
+
+// boolSetting is the environment variable key when it is true or false,
+// and otherwise the file's value.
+func boolSetting(key string, file *bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return file != nil && *file
+}
+
+// resourceSetting is the environment variable key when it is set, and
+// otherwise the file's value, even zero, or fallback when it has none.
+func resourceSetting(key string, file *int, fallback int) int {
+	if value := aToUint64(os.Getenv(key)); value > 0 {
+		return int(value)
+	}
+	if file != nil {
+		return *file
+	}
+	return fallback
+}
+
+// listSetting is the comma separated environment variable key when it is
+// set, and otherwise the file's list, even an empty one, or fallback when
+// it has none.
+func listSetting(key string, file, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		if file != nil {
+			return file
+		}
+		return fallback
+	}
+	return splitList(value)
+}
+
+// splitList splits a comma separated list, dropping empty items.
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.cloudfoundry.org/cfdev/errors"
+)
+
+// activeInstanceFile is kept in the shared home, not the instance's, and
+// names the instance whose vm holds the guest's ports on the host and the
+// loopback aliases, which every instance uses.
+const activeInstanceFile = "active-instance"
+
+// ActiveInstance is the instance marked as running, if any.
+func (c Config) ActiveInstance() (string, bool) {
+	contents, err := ioutil.ReadFile(filepath.Join(c.CFDevHome, activeInstanceFile))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(contents)), true
+}
+
+// CheckNoOtherInstance fails when another instance is marked as running,
+// saying how to stop it, as two would fight over the same ports and
+// addresses.
+func (c Config) CheckNoOtherInstance() error {
+	other, ok := c.ActiveInstance()
+	if !ok || other == c.Instance {
+		return nil
+	}
+
+	if other == "" {
+		return fmt.Errorf("the default instance is running and only one instance can run at a time, stop it with cf dev stop first")
+	}
+	return fmt.Errorf("instance %q is running and only one instance can run at a time, stop it with cf dev stop --instance %s first", other, other)
+}
+
+// MarkActive records the instance as the one running.
+func (c Config) MarkActive() error {
+	if err := ioutil.WriteFile(filepath.Join(c.CFDevHome, activeInstanceFile), []byte(c.Instance), 0644); err != nil {
+		return errors.SafeWrap(err, "marking the instance as running")
+	}
+	return nil
+}
+
+// ClearActive removes the mark, unless it is another instance's.
+func (c Config) ClearActive() error {
+	if other, ok := c.ActiveInstance(); !ok || other != c.Instance {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(c.CFDevHome, activeInstanceFile)); err != nil && !os.IsNotExist(err) {
+		return errors.SafeWrap(err, "clearing the running instance")
+	}
+	return nil
+}
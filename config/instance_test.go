@@ -0,0 +1,59 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	"code.cloudfoundry.org/cfdev/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("the running instance", func() {
+	var (
+		conf  config.Config
+		other config.Config
+	)
+
+	BeforeEach(func() {
+		home, err := ioutil.TempDir("", "cfdev-instance")
+		Expect(err).NotTo(HaveOccurred())
+		conf = config.Config{CFDevHome: home}
+		other = conf.WithInstance("other")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(conf.CFDevHome)
+	})
+
+	It("lets any instance start when none is running", func() {
+		_, active := conf.ActiveInstance()
+		Expect(active).To(BeFalse())
+		Expect(conf.CheckNoOtherInstance()).To(Succeed())
+		Expect(other.CheckNoOtherInstance()).To(Succeed())
+	})
+
+	It("lets only the running instance start again", func() {
+		Expect(other.MarkActive()).To(Succeed())
+
+		name, _ := conf.ActiveInstance()
+		Expect(name).To(Equal("other"))
+		Expect(other.CheckNoOtherInstance()).To(Succeed())
+		Expect(conf.CheckNoOtherInstance()).To(MatchError(`instance "other" is running and only one instance can run at a time, stop it with cf dev stop --instance other first`))
+
+		Expect(conf.MarkActive()).To(Succeed())
+		Expect(other.CheckNoOtherInstance()).To(MatchError("the default instance is running and only one instance can run at a time, stop it with cf dev stop first"))
+	})
+
+	It("clears only its own mark", func() {
+		Expect(other.MarkActive()).To(Succeed())
+
+		Expect(conf.ClearActive()).To(Succeed())
+		name, _ := conf.ActiveInstance()
+		Expect(name).To(Equal("other"))
+
+		Expect(other.ClearActive()).To(Succeed())
+		_, active := conf.ActiveInstance()
+		Expect(active).To(BeFalse())
+	})
+})
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/cfdev/cfanalytics/consent"
+	e "code.cloudfoundry.org/cfdev/errors"
+)
+
+// FileVersion is the newest format of config.yml this cf dev reads. A file
+// without a version has the first.
+const FileVersion = 1
+
+// homeMigration upgrades a cfdev home from the layout before it to the
+// next.
+type homeMigration struct {
+	description string
+	migrate     func(cfdevHome string) error
+}
+
+// homeMigrations upgrade the cfdev home one layout at a time, so that a
+// newer cf dev takes over the state an older one left rather than failing
+// on files it expects elsewhere. The first upgrades homes from before the
+// layout was recorded. New layouts are added to the end, never in between.
+var homeMigrations = []homeMigration{
+	{"keep the telemetry answer in consent.json", moveLegacyConsent},
+}
+
+// HomeVersion is the layout of the cfdev home this cf dev keeps.
+func HomeVersion() int {
+	return len(homeMigrations)
+}
+
+// HomeVersionPath is where the layout of the cfdev home is recorded.
+func HomeVersionPath(cfdevHome string) string {
+	return filepath.Join(cfdevHome, "version")
+}
+
+// MigrateHome upgrades the cfdev home to the layout this cf dev keeps. The
+// layout is recorded after each step, so that a failed upgrade resumes
+// where it stopped. A home laid out by a newer cf dev is left alone.
+func MigrateHome(cfdevHome string) error {
+	version, err := homeVersion(cfdevHome)
+	if err != nil {
+		return err
+	}
+	if version > HomeVersion() {
+		return fmt.Errorf("%s was laid out by a newer cf dev (version %d, this one keeps %d), upgrade the cf dev plugin or set CFDEV_HOME elsewhere", cfdevHome, version, HomeVersion())
+	}
+	if version == HomeVersion() {
+		return nil
+	}
+
+	if err := os.MkdirAll(cfdevHome, 0755); err != nil {
+		return e.SafeWrap(err, "creating "+cfdevHome)
+	}
+	for ; version < HomeVersion(); version++ {
+		step := homeMigrations[version]
+		if err := step.migrate(cfdevHome); err != nil {
+			return e.SafeWrap(err, fmt.Sprintf("upgrading %s to version %d, to %s", cfdevHome, version+1, step.description))
+		}
+		if err := ioutil.WriteFile(HomeVersionPath(cfdevHome), []byte(strconv.Itoa(version+1)+"\n"), 0644); err != nil {
+			return e.SafeWrap(err, "recording the version of "+cfdevHome)
+		}
+	}
+	return nil
+}
+
+func homeVersion(cfdevHome string) (int, error) {
+	contents, err := ioutil.ReadFile(HomeVersionPath(cfdevHome))
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, e.SafeWrap(err, "reading the version of "+cfdevHome)
+	}
+
+	version, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("%s does not hold a version: %q", HomeVersionPath(cfdevHome), strings.TrimSpace(string(contents)))
+	}
+	return version, nil
+}
+
+// moveLegacyConsent moves the telemetry answer earlier versions kept in
+// analytics.txt to where it is kept now. An answer already there wins.
+func moveLegacyConsent(cfdevHome string) error {
+	store := consent.New(cfdevHome)
+	if _, err := os.Stat(store.LegacyPath); os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Stat(store.Path); err == nil {
+		return os.Remove(store.LegacyPath)
+	}
+	return os.Rename(store.LegacyPath, store.Path)
+}
@@ -0,0 +1,76 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"code.cloudfoundry.org/cfdev/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MigrateHome", func() {
+	var cfdevHome string
+
+	BeforeEach(func() {
+		var err error
+		cfdevHome, err = ioutil.TempDir("", "cfdev-home")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(cfdevHome)
+	})
+
+	readVersion := func() string {
+		contents, err := ioutil.ReadFile(config.HomeVersionPath(cfdevHome))
+		Expect(err).NotTo(HaveOccurred())
+		return string(contents)
+	}
+
+	It("records the layout of a new home", func() {
+		home := filepath.Join(cfdevHome, "new")
+		Expect(config.MigrateHome(home)).To(Succeed())
+		cfdevHome = home
+		Expect(readVersion()).To(Equal(strconv.Itoa(config.HomeVersion()) + "\n"))
+	})
+
+	It("moves the telemetry answer of a home from before versioning", func() {
+		Expect(os.MkdirAll(filepath.Join(cfdevHome, "analytics"), 0755)).To(Succeed())
+		legacy := filepath.Join(cfdevHome, "analytics", "analytics.txt")
+		Expect(ioutil.WriteFile(legacy, []byte(`{"cfAnalyticsEnabled":true}`), 0644)).To(Succeed())
+
+		Expect(config.MigrateHome(cfdevHome)).To(Succeed())
+
+		Expect(legacy).NotTo(BeAnExistingFile())
+		contents, err := ioutil.ReadFile(filepath.Join(cfdevHome, "analytics", "consent.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal(`{"cfAnalyticsEnabled":true}`))
+		Expect(readVersion()).To(Equal(strconv.Itoa(config.HomeVersion()) + "\n"))
+	})
+
+	It("leaves a current home alone", func() {
+		Expect(ioutil.WriteFile(config.HomeVersionPath(cfdevHome), []byte(strconv.Itoa(config.HomeVersion())), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(cfdevHome, "analytics"), 0755)).To(Succeed())
+		legacy := filepath.Join(cfdevHome, "analytics", "analytics.txt")
+		Expect(ioutil.WriteFile(legacy, []byte(`{}`), 0644)).To(Succeed())
+
+		Expect(config.MigrateHome(cfdevHome)).To(Succeed())
+		Expect(legacy).To(BeAnExistingFile())
+	})
+
+	It("refuses a home laid out by a newer cf dev", func() {
+		Expect(ioutil.WriteFile(config.HomeVersionPath(cfdevHome), []byte("99\n"), 0644)).To(Succeed())
+
+		err := config.MigrateHome(cfdevHome)
+		Expect(err).To(MatchError(ContainSubstring("laid out by a newer cf dev (version 99")))
+		Expect(readVersion()).To(Equal("99\n"))
+	})
+
+	It("fails for a version it cannot read", func() {
+		Expect(ioutil.WriteFile(config.HomeVersionPath(cfdevHome), []byte("two\n"), 0644)).To(Succeed())
+		Expect(config.MigrateHome(cfdevHome)).To(MatchError(ContainSubstring(`does not hold a version: "two"`)))
+	})
+})
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ProfilesDir is where named profiles are kept, one <name>.yml each with
+// the resources of config.yml, e.g. a minimal one for a laptop and a full
+// one with every service.
+func ProfilesDir(cfdevHome string) string {
+	return filepath.Join(cfdevHome, "profiles")
+}
+
+// Profiles lists the names of the profiles there are.
+func Profiles(cfdevHome string) ([]string, error) {
+	files, err := ioutil.ReadDir(ProfilesDir(cfdevHome))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, file := range files {
+		if !file.IsDir() && filepath.Ext(file.Name()) == ".yml" {
+			names = append(names, strings.TrimSuffix(file.Name(), ".yml"))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadProfile reads the profile called name.
+func LoadProfile(cfdevHome, name string) (Resources, error) {
+	path := filepath.Join(ProfilesDir(cfdevHome), name+".yml")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		names, _ := Profiles(cfdevHome)
+		if len(names) == 0 {
+			return Resources{}, fmt.Errorf("there is no profile named %s, profiles are kept in %s", name, ProfilesDir(cfdevHome))
+		}
+		return Resources{}, fmt.Errorf("there is no profile named %s, choose one of %s", name, strings.Join(names, ", "))
+	}
+
+	var resources Resources
+	if err := readYAML(path, &resources); err != nil {
+		return Resources{}, err
+	}
+	return resources, nil
+}
+
+// WithProfile takes the resources the profile called name sets in place of
+// those of config.yml. The environment still overrides them.
+func (c Config) WithProfile(name string) (Config, error) {
+	if name == "" {
+		return c, nil
+	}
+
+	resources, err := LoadProfile(c.CFDevHome, name)
+	if err != nil {
+		return Config{}, err
+	}
+
+	c.Profile = name
+	return c.withResources(resources), nil
+}
@@ -0,0 +1,89 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithProfile", func() {
+	var (
+		cfdevHome string
+		conf      config.Config
+	)
+
+	BeforeEach(func() {
+		var err error
+		cfdevHome, err = ioutil.TempDir("", "cfdev-profiles")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(config.ProfilesDir(cfdevHome), 0755)).To(Succeed())
+
+		conf = config.Config{
+			CFDevHome: cfdevHome,
+			MemoryMB:  8192,
+			Cpus:      4,
+			Services:  []string{"redis"},
+		}
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("CFDEV_CPUS")
+		os.RemoveAll(cfdevHome)
+	})
+
+	writeProfile := func(name, contents string) {
+		path := filepath.Join(config.ProfilesDir(cfdevHome), name+".yml")
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+	}
+
+	It("leaves the config as it is without a profile", func() {
+		Expect(conf.WithProfile("")).To(Equal(conf))
+	})
+
+	It("takes the resources the profile sets", func() {
+		writeProfile("mysql-dev", "cpus: 2\nservices: [mysql]\n")
+
+		profiled, err := conf.WithProfile("mysql-dev")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(profiled.Profile).To(Equal("mysql-dev"))
+		Expect(profiled.MemoryMB).To(Equal(8192))
+		Expect(profiled.Cpus).To(Equal(2))
+		Expect(profiled.Services).To(Equal([]string{"mysql"}))
+	})
+
+	It("takes empty and zero resources in place of the config's", func() {
+		writeProfile("bare", "memory: 0\nservices: []\n")
+
+		profiled, err := conf.WithProfile("bare")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(profiled.MemoryMB).To(Equal(0))
+		Expect(profiled.Cpus).To(Equal(4))
+		Expect(profiled.Services).To(BeEmpty())
+	})
+
+	It("lets the environment override the profile", func() {
+		writeProfile("minimal", "cpus: 2\n")
+		os.Setenv("CFDEV_CPUS", "3")
+
+		profiled, err := conf.WithProfile("minimal")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(profiled.Cpus).To(Equal(3))
+	})
+
+	It("fails for profiles there are not and settings they cannot have", func() {
+		writeProfile("minimal", "cpus: 2\n")
+		writeProfile("full", "telemetry: true\n")
+
+		Expect(config.Profiles(cfdevHome)).To(Equal([]string{"full", "minimal"}))
+
+		_, err := conf.WithProfile("other")
+		Expect(err).To(MatchError("there is no profile named other, choose one of full, minimal"))
+
+		_, err = conf.WithProfile("full")
+		Expect(err).To(MatchError(ContainSubstring("parsing full.yml")))
+	})
+})
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Settings are the keys of the config file that cf dev config reads and
+// writes, with the nested proxy settings named proxy.<setting>.
+var Settings = []string{
+	"home",
+	"memory",
+	"cpus",
+	"disk",
+	"registries",
+	"services",
+	"telemetry",
+	"parallel_deploys",
+	"proxy.http",
+	"proxy.https",
+	"proxy.no_proxy",
+	"network.director_ip",
+	"network.router_ip",
+	"network.cf_subnet",
+	"network.services_subnet",
+	"vm.data_disk",
+	"vm.min_memory",
+	"vm.max_memory",
+	"vm.nested_virtualization",
+	"vm.secure_boot",
+	"vm.tpm",
+}
+
+// Get returns the setting key, or "" when the file does not have it. Lists
+// are comma separated.
+func (f File) Get(key string) (string, error) {
+	switch key {
+	case "home":
+		return f.Home, nil
+	case "memory":
+		return resourceString(f.Memory), nil
+	case "cpus":
+		return resourceString(f.Cpus), nil
+	case "disk":
+		return resourceString(f.Disk), nil
+	case "registries":
+		return strings.Join(f.Registries, ","), nil
+	case "services":
+		return strings.Join(f.Services, ","), nil
+	case "telemetry":
+		return boolString(f.Telemetry), nil
+	case "parallel_deploys":
+		return intString(f.ParallelDeploys), nil
+	case "proxy.http":
+		return redactURL(f.Proxy.HTTP), nil
+	case "proxy.https":
+		return redactURL(f.Proxy.HTTPS), nil
+	case "proxy.no_proxy":
+		return f.Proxy.NoProxy, nil
+	case "network.director_ip":
+		return f.Network.DirectorIP, nil
+	case "network.router_ip":
+		return f.Network.RouterIP, nil
+	case "network.cf_subnet":
+		return f.Network.CFSubnet, nil
+	case "network.services_subnet":
+		return f.Network.ServicesSubnet, nil
+	case "vm.data_disk":
+		return intString(f.VM.DataDisk), nil
+	case "vm.min_memory":
+		return intString(f.VM.MinMemory), nil
+	case "vm.max_memory":
+		return intString(f.VM.MaxMemory), nil
+	case "vm.nested_virtualization":
+		return boolString(f.VM.NestedVirtualization), nil
+	case "vm.secure_boot":
+		return boolString(f.VM.SecureBoot), nil
+	case "vm.tpm":
+		return boolString(f.VM.TPM), nil
+	}
+	return "", unknownSetting(key)
+}
+
+// Set parses value as the setting key, rejecting values of the wrong type
+// so that a mistake is caught before it is saved. An empty value removes
+// the setting.
+func (f *File) Set(key, value string) error {
+	value = strings.TrimSpace(value)
+
+	var err error
+	switch key {
+	case "home":
+		f.Home, err = parseHome(value)
+	case "memory":
+		f.Memory, err = parseResource(key, value)
+	case "cpus":
+		f.Cpus, err = parseResource(key, value)
+	case "disk":
+		f.Disk, err = parseResource(key, value)
+	case "registries":
+		f.Registries = splitList(value)
+	case "services":
+		f.Services = splitList(value)
+	case "telemetry":
+		f.Telemetry, err = parseBool(key, value)
+	case "parallel_deploys":
+		f.ParallelDeploys, err = parseSize(key, value)
+	case "proxy.http":
+		f.Proxy.HTTP, err = parseProxy(key, value)
+	case "proxy.https":
+		f.Proxy.HTTPS, err = parseProxy(key, value)
+	case "proxy.no_proxy":
+		f.Proxy.NoProxy = strings.Join(splitList(value), ",")
+	case "network.director_ip":
+		f.Network.DirectorIP, err = parseIP(key, value)
+	case "network.router_ip":
+		f.Network.RouterIP, err = parseIP(key, value)
+	case "network.cf_subnet":
+		f.Network.CFSubnet, err = parseSubnet(key, value)
+	case "network.services_subnet":
+		f.Network.ServicesSubnet, err = parseSubnet(key, value)
+	case "vm.data_disk":
+		f.VM.DataDisk, err = parseSize(key, value)
+	case "vm.min_memory":
+		f.VM.MinMemory, err = parseSize(key, value)
+	case "vm.max_memory":
+		f.VM.MaxMemory, err = parseSize(key, value)
+	case "vm.nested_virtualization":
+		f.VM.NestedVirtualization, err = parseBool(key, value)
+	case "vm.secure_boot":
+		f.VM.SecureBoot, err = parseBool(key, value)
+	case "vm.tpm":
+		f.VM.TPM, err = parseBool(key, value)
+	default:
+		err = unknownSetting(key)
+	}
+	return err
+}
+
+func unknownSetting(key string) error {
+	return fmt.Errorf("unknown setting %q, use one of %s", key, strings.Join(Settings, ", "))
+}
+
+func intString(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+func boolString(value *bool) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatBool(*value)
+}
+
+func resourceString(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return intString(*value)
+}
+
+// parseResource is parseSize for a resource, which is unset when value is
+// empty.
+func parseResource(key, value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	size, err := parseSize(key, value)
+	if err != nil {
+		return nil, err
+	}
+	return &size, nil
+}
+
+func parseSize(key, value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || size < 1 {
+		return 0, fmt.Errorf("%s must be a whole number above 0, not %q", key, value)
+	}
+	return size, nil
+}
+
+func parseHome(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if !filepath.IsAbs(value) {
+		return "", fmt.Errorf("home must be an absolute path, not %q", value)
+	}
+	return filepath.Clean(value), nil
+}
+
+func parseBool(key, value string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be true or false, not %q", key, value)
+	}
+	return &enabled, nil
+}
+
+func parseIP(key, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if ip := net.ParseIP(value); ip == nil || ip.To4() == nil {
+		return "", fmt.Errorf("%s must be an IPv4 address, e.g. 10.245.0.4, not %q", key, value)
+	}
+	return value, nil
+}
+
+func parseSubnet(key, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	_, subnet, err := net.ParseCIDR(value)
+	if err != nil || subnet.IP.To4() == nil {
+		return "", fmt.Errorf("%s must be an IPv4 CIDR, e.g. 10.245.0.0/16, not %q", key, value)
+	}
+	return subnet.String(), nil
+}
+
+func parseProxy(key, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	proxy, err := url.Parse(value)
+	if err != nil || (proxy.Scheme != "http" && proxy.Scheme != "https") || proxy.Host == "" {
+		return "", fmt.Errorf("%s must be an http or https URL, e.g. http://proxy.example.com:3128, not %q", key, redactURL(value))
+	}
+	if proxy.User != nil {
+		return "", fmt.Errorf("%s must not have credentials, as config.yml is readable by all: set CFDEV_PROXY_USERNAME and CFDEV_PROXY_PASSWORD, or store them in the keychain, instead", key)
+	}
+	return value, nil
+}
+
+// redactURL hides the credentials of a URL written into the file by hand,
+// so that showing the settings does not show them.
+func redactURL(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.User == nil {
+		return value
+	}
+	parsed.User = url.User("REDACTED")
+	return parsed.String()
+}
@@ -0,0 +1,69 @@
+package config
+
+const (
+	// MinHostMemoryMB and MinHostCPUs are the smallest host cf dev is
+	// recommended on.
+	MinHostMemoryMB = 8192
+	MinHostCPUs     = 2
+
+	// MinVMMemoryMB is the least memory a vm can run cf dev with.
+	MinVMMemoryMB = 4096
+
+	maxDefaultMemoryMB = 8192
+	maxDefaultCPUs     = 4
+)
+
+// DefaultMemoryMB is the memory a vm is given when none is asked for: half
+// the host's, up to 8GB or the deployment's recommendedMB if that is more.
+// It is never less than MinVMMemoryMB, unless the host has less than that,
+// and never more than the host has. A host whose memory is unknown gets
+// recommendedMB.
+func DefaultMemoryMB(host HostCapacity, recommendedMB int) int {
+	if host.TotalMemoryMB == 0 {
+		return recommendedMB
+	}
+
+	limit := maxDefaultMemoryMB
+	if recommendedMB > limit {
+		limit = recommendedMB
+	}
+
+	memory := int(host.TotalMemoryMB / 2)
+	if memory > limit {
+		memory = limit
+	}
+	if memory < MinVMMemoryMB {
+		memory = MinVMMemoryMB
+	}
+	if memory > int(host.TotalMemoryMB) {
+		memory = int(host.TotalMemoryMB)
+	}
+	return memory
+}
+
+// DefaultCPUs is the cpus a vm is given when none are asked for: half the
+// host's, between 2 and 4, but never more than it has. A host whose cpus are
+// unknown gets 4.
+func DefaultCPUs(host HostCapacity) int {
+	if host.CPUs <= 0 {
+		return maxDefaultCPUs
+	}
+	cpus := host.CPUs / 2
+	if cpus > maxDefaultCPUs {
+		cpus = maxDefaultCPUs
+	}
+	if cpus < MinHostCPUs {
+		cpus = MinHostCPUs
+	}
+	if cpus > host.CPUs {
+		cpus = host.CPUs
+	}
+	return cpus
+}
+
+// BelowMinimum reports whether the host has less memory or fewer cpus than
+// cf dev is recommended on. What is unknown about it is not counted.
+func (h HostCapacity) BelowMinimum() bool {
+	return (h.TotalMemoryMB > 0 && h.TotalMemoryMB < MinHostMemoryMB) ||
+		(h.CPUs > 0 && h.CPUs < MinHostCPUs)
+}
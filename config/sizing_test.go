@@ -0,0 +1,60 @@
+package config_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sizing", func() {
+	Describe("DefaultMemoryMB", func() {
+		It("gives the vm half the host's memory", func() {
+			Expect(config.DefaultMemoryMB(config.HostCapacity{TotalMemoryMB: 12000}, 4192)).To(Equal(6000))
+		})
+
+		It("caps it at 8GB, or what the deployment recommends if that is more", func() {
+			Expect(config.DefaultMemoryMB(config.HostCapacity{TotalMemoryMB: 65536}, 4192)).To(Equal(8192))
+			Expect(config.DefaultMemoryMB(config.HostCapacity{TotalMemoryMB: 65536}, 10000)).To(Equal(10000))
+		})
+
+		It("does not go below what a vm needs", func() {
+			Expect(config.DefaultMemoryMB(config.HostCapacity{TotalMemoryMB: 6000}, 8192)).To(Equal(4096))
+		})
+
+		It("never gives more than the host has", func() {
+			Expect(config.DefaultMemoryMB(config.HostCapacity{TotalMemoryMB: 3000}, 8192)).To(Equal(3000))
+		})
+
+		It("gives what the deployment recommends when the host's memory is unknown", func() {
+			Expect(config.DefaultMemoryMB(config.HostCapacity{}, 8192)).To(Equal(8192))
+		})
+	})
+
+	Describe("DefaultCPUs", func() {
+		It("gives the vm half the host's cpus, between 2 and 4", func() {
+			Expect(config.DefaultCPUs(config.HostCapacity{CPUs: 6})).To(Equal(3))
+			Expect(config.DefaultCPUs(config.HostCapacity{CPUs: 16})).To(Equal(4))
+			Expect(config.DefaultCPUs(config.HostCapacity{CPUs: 2})).To(Equal(2))
+		})
+
+		It("never gives more than the host has", func() {
+			Expect(config.DefaultCPUs(config.HostCapacity{CPUs: 1})).To(Equal(1))
+		})
+
+		It("gives 4 when the host's cpus are unknown", func() {
+			Expect(config.DefaultCPUs(config.HostCapacity{})).To(Equal(4))
+		})
+	})
+
+	Describe("BelowMinimum", func() {
+		It("is true for a host with too little memory or too few cpus", func() {
+			Expect(config.HostCapacity{TotalMemoryMB: 4096, CPUs: 8}.BelowMinimum()).To(BeTrue())
+			Expect(config.HostCapacity{TotalMemoryMB: 16384, CPUs: 1}.BelowMinimum()).To(BeTrue())
+		})
+
+		It("is false otherwise, or when nothing is known", func() {
+			Expect(config.HostCapacity{TotalMemoryMB: 16384, CPUs: 8}.BelowMinimum()).To(BeFalse())
+			Expect(config.HostCapacity{}.BelowMinimum()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	e "code.cloudfoundry.org/cfdev/errors"
+)
+
+// HostCapacity is what the machine cf dev runs on has, which the config is
+// validated against. Zero values are not checked.
+type HostCapacity struct {
+	TotalMemoryMB uint64
+	CPUs          int
+	// Routes are the host's own, which cf dev's networks must stay clear of.
+	Routes []Route
+}
+
+// Route is where the host sends the traffic for Destination.
+type Route struct {
+	Destination *net.IPNet
+	Interface   string
+}
+
+var macAddressPattern = regexp.MustCompile(`^[0-9A-Fa-f]{12}$`)
+
+// Validate checks the config before any vm is created, so that a mistake
+// fails start straight away rather than part way through. Every problem
+// found is returned together, each saying what to change.
+func (c Config) Validate(host HostCapacity) error {
+	var problems []string
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if host.TotalMemoryMB > 0 && uint64(c.MemoryMB) > host.TotalMemoryMB {
+		problem("%dMB of memory is more than the %dMB this machine has, lower --memory, CFDEV_MEMORY or memory in config.yml", c.MemoryMB, host.TotalMemoryMB)
+	}
+	if c.Cpus < 1 {
+		problem("the vm needs at least 1 cpu, not %d, raise --cpus, CFDEV_CPUS or cpus in config.yml", c.Cpus)
+	} else if host.CPUs > 0 && c.Cpus > host.CPUs {
+		problem("%d cpus are more than the %d this machine has, lower --cpus, CFDEV_CPUS or cpus in config.yml", c.Cpus, host.CPUs)
+	}
+
+	for _, ip := range []struct{ name, value string }{
+		{"CFDEV_BOSH_DIRECTOR_IP", c.BoshDirectorIP},
+		{"CFDEV_ROUTER_IP", c.CFRouterIP},
+		{"CFDEV_HOST_IP", c.HostIP},
+	} {
+		if net.ParseIP(ip.value) == nil {
+			problem("%s is not an IP address: %q", ip.name, ip.value)
+		}
+	}
+	if c.BoshDirectorIP == c.CFRouterIP {
+		problem("the BOSH director and the CF router cannot share %s, change CFDEV_BOSH_DIRECTOR_IP or CFDEV_ROUTER_IP", c.BoshDirectorIP)
+	}
+	if runtime.GOOS == "darwin" {
+		for _, ip := range []struct{ name, value string }{
+			{"CFDEV_BOSH_DIRECTOR_IP", c.BoshDirectorIP},
+			{"CFDEV_ROUTER_IP", c.CFRouterIP},
+		} {
+			if parsed := net.ParseIP(ip.value); parsed != nil && !privateIPv4(parsed) {
+				problem("the macOS network helper only aliases private IPv4 addresses, in 10.0.0.0/8, 172.16.0.0/12 or 192.168.0.0/16, not %s %s", ip.name, ip.value)
+			}
+		}
+	}
+
+	var subnets []namedSubnet
+	if _, cfSubnet, err := net.ParseCIDR(c.CFSubnet); err != nil {
+		problem("CFDEV_CF_SUBNET is not a CIDR: %q", c.CFSubnet)
+	} else {
+		subnets = append(subnets, namedSubnet{"CFDEV_CF_SUBNET", cfSubnet})
+		for _, ip := range []struct{ name, value string }{
+			{"CFDEV_BOSH_DIRECTOR_IP", c.BoshDirectorIP},
+			{"CFDEV_ROUTER_IP", c.CFRouterIP},
+		} {
+			if parsed := net.ParseIP(ip.value); parsed != nil && !cfSubnet.Contains(parsed) {
+				problem("%s %s is outside CFDEV_CF_SUBNET %s, move it into the subnet", ip.name, ip.value, cfSubnet)
+			}
+		}
+	}
+	if c.ServicesSubnet != "" {
+		if _, servicesSubnet, err := net.ParseCIDR(c.ServicesSubnet); err != nil {
+			problem("CFDEV_SERVICES_SUBNET is not a CIDR: %q", c.ServicesSubnet)
+		} else if len(subnets) > 0 && overlaps(subnets[0].subnet, servicesSubnet) {
+			problem("CFDEV_SERVICES_SUBNET %s overlaps CFDEV_CF_SUBNET %s, pick a subnet apart from it", servicesSubnet, subnets[0].subnet)
+		} else {
+			subnets = append(subnets, namedSubnet{"CFDEV_SERVICES_SUBNET", servicesSubnet})
+		}
+	}
+	for _, subnet := range subnets {
+		for _, route := range host.Routes {
+			if ones, _ := route.Destination.Mask.Size(); ones == 0 || !overlaps(subnet.subnet, route.Destination) {
+				continue
+			}
+			problem("%s %s overlaps %s, which the host routes through %s, e.g. for a VPN, move it and the addresses in it with CFDEV_ variables or network in config.yml", subnet.name, subnet.subnet, route.Destination, route.Interface)
+		}
+	}
+
+	for _, dir := range []struct{ name, path string }{
+		{"CFDEV_HOME", c.CFDevHome},
+		{"CFDEV_CACHE_DIR", c.CacheDir},
+		{"the state directory", c.StateDir},
+		{"the log directory", c.LogDir},
+		{"the data directory", c.DataDir},
+	} {
+		if dir.path == "" {
+			continue
+		}
+		if err := writable(dir.path); err != nil {
+			problem("%s %s is not writable: %s", dir.name, dir.path, err)
+		}
+	}
+
+	switch c.Hypervisor {
+	case "", "qemu":
+	default:
+		problem("CFDEV_HYPERVISOR must be empty or qemu, not %q", c.Hypervisor)
+	}
+	hyperV := runtime.GOOS == "windows" && c.Hypervisor == ""
+	if c.Hypervisor == "qemu" {
+		switch c.QEMU.Accel {
+		case "tcg", "kvm", "hvf", "whpx":
+		default:
+			problem("CFDEV_QEMU_ACCEL must be one of tcg, kvm, hvf or whpx, not %q", c.QEMU.Accel)
+		}
+	}
+	if c.GPU && !hyperV {
+		problem("CFDEV_GPU needs Hyper-V, unset it or CFDEV_HYPERVISOR")
+	}
+	if c.MACAddress != "" {
+		if !macAddressPattern.MatchString(strings.NewReplacer(":", "", "-", "").Replace(c.MACAddress)) {
+			problem("CFDEV_MAC_ADDRESS is not a MAC address: %q", c.MACAddress)
+		} else if !hyperV {
+			problem("CFDEV_MAC_ADDRESS needs Hyper-V, unset it or CFDEV_HYPERVISOR")
+		}
+	}
+	if (c.VM.MinMemoryMB > 0) != (c.VM.MaxMemoryMB > 0) {
+		problem("a memory range needs both --min-memory and --max-memory, set both or neither of CFDEV_MIN_MEMORY_MB and CFDEV_MAX_MEMORY_MB")
+	} else if c.VM.MinMemoryMB > 0 {
+		if c.VM.MinMemoryMB > c.VM.MaxMemoryMB {
+			problem("the minimum memory %dMB is above the maximum %dMB, swap them", c.VM.MinMemoryMB, c.VM.MaxMemoryMB)
+		} else if c.MemoryMB > 0 && (c.MemoryMB < c.VM.MinMemoryMB || c.MemoryMB > c.VM.MaxMemoryMB) {
+			problem("%dMB of memory is outside the range %dMB to %dMB, change --memory or the range", c.MemoryMB, c.VM.MinMemoryMB, c.VM.MaxMemoryMB)
+		}
+	}
+	for _, feature := range []struct {
+		name string
+		on   bool
+	}{
+		{"a memory range", c.VM.MinMemoryMB > 0 || c.VM.MaxMemoryMB > 0},
+		{"nested virtualization", c.VM.NestedVirtualization},
+		{"secure boot", c.VM.SecureBoot},
+		{"a TPM", c.VM.TPM},
+	} {
+		if feature.on && !hyperV {
+			problem("%s needs Hyper-V, turn it off in the flags, CFDEV_ variables or vm in config.yml", feature.name)
+		}
+	}
+
+	if len(problems) > 0 {
+		return e.SafeWrap(errors.New(strings.Join(problems, "\n")), "invalid configuration")
+	}
+	return nil
+}
+
+type namedSubnet struct {
+	name   string
+	subnet *net.IPNet
+}
+
+func overlaps(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// writable checks that files can be made in dir, or in the closest of its
+// parents that exists when it does not yet.
+func writable(dir string) error {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+
+	file, err := ioutil.TempFile(dir, ".cfdev-writable-")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// privateIPv4 is whether ip is in one of the private IPv4 ranges, the only
+// addresses the macOS network helper, which runs as root, aliases.
+func privateIPv4(ip net.IP) bool {
+	for _, cidr := range []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"} {
+		if _, network, _ := net.ParseCIDR(cidr); network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"code.cloudfoundry.org/cfdev/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	var (
+		cfdevHome string
+		conf      config.Config
+		host      config.HostCapacity
+	)
+
+	BeforeEach(func() {
+		var err error
+		cfdevHome, err = ioutil.TempDir("", "cfdev-validate")
+		Expect(err).NotTo(HaveOccurred())
+
+		conf = config.Config{
+			CFDevHome:      cfdevHome,
+			CacheDir:       filepath.Join(cfdevHome, "cache"),
+			StateDir:       filepath.Join(cfdevHome, "state"),
+			LogDir:         filepath.Join(cfdevHome, "log"),
+			BoshDirectorIP: "10.144.0.4",
+			CFRouterIP:     "10.144.0.34",
+			CFSubnet:       "10.144.0.0/16",
+			HostIP:         "192.168.65.2",
+			MemoryMB:       8192,
+			Cpus:           4,
+			QEMU:           config.QEMUConfig{Accel: "tcg"},
+		}
+		host = config.HostCapacity{TotalMemoryMB: 16384, CPUs: 8}
+	})
+
+	AfterEach(func() {
+		os.Chmod(cfdevHome, 0755)
+		os.RemoveAll(cfdevHome)
+	})
+
+	It("accepts a config that fits the host", func() {
+		Expect(conf.Validate(host)).To(Succeed())
+	})
+
+	It("does not check what the host did not report", func() {
+		conf.MemoryMB = 65536
+		conf.Cpus = 64
+		Expect(conf.Validate(config.HostCapacity{})).To(Succeed())
+	})
+
+	It("rejects more memory than the host has", func() {
+		conf.MemoryMB = 32768
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring("32768MB of memory is more than the 16384MB this machine has")))
+	})
+
+	It("rejects fewer than 1 cpu and more cpus than the host has", func() {
+		conf.Cpus = 0
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring("at least 1 cpu")))
+		conf.Cpus = 12
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring("12 cpus are more than the 8")))
+	})
+
+	It("rejects IP addresses that do not parse and a director sharing the router's", func() {
+		conf.HostIP = "192.168.65"
+		err := conf.Validate(host)
+		Expect(err).To(MatchError(ContainSubstring(`CFDEV_HOST_IP is not an IP address: "192.168.65"`)))
+
+		conf.HostIP = "192.168.65.2"
+		conf.CFRouterIP = conf.BoshDirectorIP
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring("cannot share 10.144.0.4")))
+	})
+
+	It("rejects a CF subnet without the director and router", func() {
+		conf.CFSubnet = "10.245.0.0/16"
+		err := conf.Validate(host)
+		Expect(err).To(MatchError(ContainSubstring("CFDEV_BOSH_DIRECTOR_IP 10.144.0.4 is outside CFDEV_CF_SUBNET 10.245.0.0/16")))
+		Expect(err).To(MatchError(ContainSubstring("CFDEV_ROUTER_IP 10.144.0.34 is outside CFDEV_CF_SUBNET 10.245.0.0/16")))
+
+		conf.CFSubnet = "10.245.0.0"
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring(`CFDEV_CF_SUBNET is not a CIDR: "10.245.0.0"`)))
+	})
+
+	It("rejects a services subnet overlapping the CF subnet", func() {
+		conf.ServicesSubnet = "10.144.128.0/17"
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring("CFDEV_SERVICES_SUBNET 10.144.128.0/17 overlaps CFDEV_CF_SUBNET 10.144.0.0/16")))
+
+		conf.ServicesSubnet = "10.145.0.0/16"
+		Expect(conf.Validate(host)).To(Succeed())
+	})
+
+	Context("when the host routes some of the subnets elsewhere", func() {
+		route := func(cidr, iface string) config.Route {
+			_, destination, err := net.ParseCIDR(cidr)
+			Expect(err).NotTo(HaveOccurred())
+			return config.Route{Destination: destination, Interface: iface}
+		}
+
+		BeforeEach(func() {
+			conf.ServicesSubnet = "10.145.0.0/16"
+			host.Routes = []config.Route{
+				route("0.0.0.0/0", "en0"),
+				route("192.168.1.0/24", "en0"),
+				route("10.0.0.0/8", "utun3"),
+			}
+		})
+
+		It("rejects the subnets that overlap the routes", func() {
+			err := conf.Validate(host)
+			Expect(err).To(MatchError(ContainSubstring("CFDEV_CF_SUBNET 10.144.0.0/16 overlaps 10.0.0.0/8, which the host routes through utun3")))
+			Expect(err).To(MatchError(ContainSubstring("CFDEV_SERVICES_SUBNET 10.145.0.0/16 overlaps 10.0.0.0/8, which the host routes through utun3")))
+			Expect(err).NotTo(MatchError(ContainSubstring("en0")))
+		})
+
+		It("accepts subnets moved out of the way", func() {
+			conf.CFSubnet = "172.30.0.0/16"
+			conf.BoshDirectorIP = "172.30.0.4"
+			conf.CFRouterIP = "172.30.0.34"
+			conf.ServicesSubnet = "172.31.0.0/16"
+
+			Expect(conf.Validate(host)).To(Succeed())
+		})
+
+		It("rejects public addresses, which the macOS network helper does not alias", func() {
+			if runtime.GOOS != "darwin" {
+				Skip("only the macOS network helper restricts the addresses")
+			}
+			conf.CFSubnet = "100.64.0.0/16"
+			conf.BoshDirectorIP = "100.64.0.4"
+			conf.CFRouterIP = "100.64.0.34"
+
+			Expect(conf.Validate(host)).To(MatchError(ContainSubstring("only aliases private IPv4 addresses")))
+		})
+	})
+
+	It("rejects directories that cannot be written", func() {
+		if runtime.GOOS == "windows" || os.Getuid() == 0 {
+			Skip("permissions are not enforced")
+		}
+		Expect(os.Chmod(cfdevHome, 0555)).To(Succeed())
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring("CFDEV_CACHE_DIR " + conf.CacheDir + " is not writable")))
+	})
+
+	It("rejects an unknown hypervisor and QEMU accelerator", func() {
+		conf.Hypervisor = "virtualbox"
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring(`CFDEV_HYPERVISOR must be empty or qemu, not "virtualbox"`)))
+
+		conf.Hypervisor = "qemu"
+		conf.QEMU.Accel = "kqemu"
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring(`CFDEV_QEMU_ACCEL must be one of tcg, kvm, hvf or whpx, not "kqemu"`)))
+	})
+
+	It("rejects Hyper-V options with QEMU", func() {
+		conf.Hypervisor = "qemu"
+		conf.GPU = true
+		conf.MACAddress = "00:15:5d:00:00:01"
+		err := conf.Validate(host)
+		Expect(err).To(MatchError(ContainSubstring("CFDEV_GPU needs Hyper-V")))
+		Expect(err).To(MatchError(ContainSubstring("CFDEV_MAC_ADDRESS needs Hyper-V")))
+
+		conf.GPU = false
+		conf.MACAddress = ""
+		conf.VM = config.VMConfig{MinMemoryMB: 4096, MaxMemoryMB: 12288, NestedVirtualization: true, SecureBoot: true, TPM: true}
+		err = conf.Validate(host)
+		Expect(err).To(MatchError(ContainSubstring("a memory range needs Hyper-V")))
+		Expect(err).To(MatchError(ContainSubstring("nested virtualization needs Hyper-V")))
+		Expect(err).To(MatchError(ContainSubstring("secure boot needs Hyper-V")))
+		Expect(err).To(MatchError(ContainSubstring("a TPM needs Hyper-V")))
+	})
+
+	It("rejects a MAC address that does not parse", func() {
+		conf.MACAddress = "00:15:5d:00:00"
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring(`CFDEV_MAC_ADDRESS is not a MAC address: "00:15:5d:00:00"`)))
+
+		conf.MACAddress = "00-15-5d-00-00-zz"
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring(`CFDEV_MAC_ADDRESS is not a MAC address: "00-15-5d-00-00-zz"`)))
+	})
+
+	It("accepts a data disk with any hypervisor", func() {
+		conf.Hypervisor = "qemu"
+		conf.VM.DataDiskMB = 10240
+		Expect(conf.Validate(host)).To(Succeed())
+	})
+
+	It("rejects a memory range that is half set or leaves out the memory", func() {
+		conf.VM.MinMemoryMB = 4096
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring("a memory range needs both --min-memory and --max-memory")))
+
+		conf.VM.MaxMemoryMB = 2048
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring("the minimum memory 4096MB is above the maximum 2048MB")))
+
+		conf.VM.MaxMemoryMB = 6144
+		Expect(conf.Validate(host)).To(MatchError(ContainSubstring("8192MB of memory is outside the range 4096MB to 6144MB")))
+	})
+
+	It("returns every problem together", func() {
+		conf.MemoryMB = 32768
+		conf.Cpus = 12
+		conf.BoshDirectorIP = "bosh"
+		err := conf.Validate(host)
+		Expect(err).To(MatchError(ContainSubstring("invalid configuration")))
+		Expect(err).To(MatchError(ContainSubstring("32768MB of memory")))
+		Expect(err).To(MatchError(ContainSubstring("12 cpus")))
+		Expect(err).To(MatchError(ContainSubstring("CFDEV_BOSH_DIRECTOR_IP is not an IP address")))
+	})
+})
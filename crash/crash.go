@@ -0,0 +1,116 @@
+// Package crash reports panics, so that maintainers learn about failures
+// users run into. A report is always written locally, and only sent when
+// the user opted in to telemetry.
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+)
+
+type Report struct {
+	Program string    `json:"program"`
+	Version string    `json:"version"`
+	OS      string    `json:"os"`
+	Arch    string    `json:"arch"`
+	Time    time.Time `json:"time"`
+	Panic   string    `json:"panic"`
+	Stack   string    `json:"stack"`
+}
+
+type Reporter struct {
+	Program string
+	Version string
+	// Dir is where reports are written.
+	Dir string
+	// HashKey keys the hashes of what could identify the user in the
+	// panic, as sink.DefaultScrubber does for events.
+	HashKey []byte
+	// Send is given each report, e.g. to send it as a crash event. It
+	// must check that the user opted in to telemetry.
+	Send func(Report) error
+}
+
+// Recover reports a panic and then panics again, so that the program still
+// fails as it would have. It must be deferred directly.
+func (r *Reporter) Recover() {
+	if value := recover(); value != nil {
+		path, err := r.Report(value, debug.Stack())
+		if path != "" {
+			fmt.Fprintf(os.Stderr, "%s crashed. A report was written to %s\n", r.Program, path)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s crashed and could not report it: %s\n", r.Program, err)
+		}
+		panic(value)
+	}
+}
+
+// Report writes a report of the panic to Dir and sends it, returning the
+// path written to. What could identify the user is removed from both the
+// panic and the stack first. The path is returned even when sending fails,
+// as the report was still written.
+func (r *Reporter) Report(value interface{}, stack []byte) (string, error) {
+	report := Report{
+		Program: r.Program,
+		Version: r.Version,
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+		Time:    time.Now().UTC(),
+		Panic:   sink.DefaultScrubber.WithKey(r.HashKey).String(fmt.Sprint(value)),
+		Stack:   anonymizeStack(string(stack)),
+	}
+
+	var sendErr error
+	if r.Send != nil {
+		sendErr = r.Send(report)
+	}
+
+	contents, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(r.Dir, fmt.Sprintf("%s-%s.json", r.Program, report.Time.Format("20060102T150405Z")))
+	if err := ioutil.WriteFile(path, contents, 0644); err != nil {
+		return "", err
+	}
+	if sendErr != nil {
+		return path, fmt.Errorf("sending the report: %s", sendErr)
+	}
+	return path, nil
+}
+
+var stackFile = regexp.MustCompile(`(?m)^\t(\S+?)(:\d+.*)$`)
+
+// anonymizeStack trims the source paths in a stack to where they are in
+// their module or GOROOT, dropping the directories of the machine that
+// built them.
+func anonymizeStack(stack string) string {
+	return stackFile.ReplaceAllStringFunc(stack, func(line string) string {
+		parts := stackFile.FindStringSubmatch(line)
+		return "\t" + trimSourcePath(parts[1]) + parts[2]
+	})
+}
+
+func trimSourcePath(path string) string {
+	path = filepath.ToSlash(path)
+	for _, marker := range []string{"/pkg/mod/", "/src/"} {
+		if i := strings.LastIndex(path, marker); i >= 0 {
+			return path[i+len(marker):]
+		}
+	}
+	return filepath.Base(path)
+}
@@ -0,0 +1,129 @@
+package crash_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"runtime"
+
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
+	"code.cloudfoundry.org/cfdev/crash"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const fakeStack = `goroutine 1 [running]:
+runtime/debug.Stack(0xc0000a2000, 0x1, 0x1)
+	/usr/local/go/src/runtime/debug/stack.go:24 +0x9d
+code.cloudfoundry.org/cfdev/cmd/start.(*Start).Execute(0xc0000a2000)
+	/Users/jane/go/src/code.cloudfoundry.org/cfdev/cmd/start/start.go:123 +0x5c
+github.com/spf13/cobra.(*Command).execute(0xc0000a2000)
+	/Users/jane/go/pkg/mod/github.com/spf13/cobra@v0.0.3/command.go:762 +0x473
+main.main()
+	/home/jane/workspace/cfdev/main.go:42 +0x20
+`
+
+var _ = Describe("Reporter", func() {
+	var (
+		dir      string
+		sent     []crash.Report
+		reporter *crash.Reporter
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "crash")
+		Expect(err).NotTo(HaveOccurred())
+
+		sent = nil
+		reporter = &crash.Reporter{
+			Program: "cf-dev",
+			Version: "some-version",
+			Dir:     dir,
+			Send: func(report crash.Report) error {
+				sent = append(sent, report)
+				return nil
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("writes and sends an anonymized report", func() {
+		path, err := reporter.Report("open /Users/jane/.cfdev/state: permission denied", []byte(fakeStack))
+		Expect(err).NotTo(HaveOccurred())
+
+		contents, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		var report crash.Report
+		Expect(json.Unmarshal(contents, &report)).To(Succeed())
+
+		Expect(report.Program).To(Equal("cf-dev"))
+		Expect(report.Version).To(Equal("some-version"))
+		Expect(report.OS).To(Equal(runtime.GOOS))
+		Expect(report.Panic).To(Equal("open <path>: permission denied"))
+		Expect(report.Stack).To(ContainSubstring("\truntime/debug/stack.go:24 +0x9d\n"))
+		Expect(report.Stack).To(ContainSubstring("\tcode.cloudfoundry.org/cfdev/cmd/start/start.go:123 +0x5c\n"))
+		Expect(report.Stack).To(ContainSubstring("\tgithub.com/spf13/cobra@v0.0.3/command.go:762 +0x473\n"))
+		Expect(report.Stack).To(ContainSubstring("\tmain.go:42 +0x20\n"))
+		Expect(report.Stack).NotTo(ContainSubstring("jane"))
+
+		Expect(sent).To(Equal([]crash.Report{report}))
+	})
+
+	It("hashes what could identify the user in the panic with the key", func() {
+		reporter.HashKey = []byte("some-key")
+		_, err := reporter.Report("sending to jane@example.com failed", []byte(fakeStack))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sent[0].Panic).To(MatchRegexp(`^sending to hmac:[0-9a-f]{16} failed$`))
+
+		reporter.HashKey = nil
+		_, err = reporter.Report("sending to jane@example.com failed", []byte(fakeStack))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sent[1].Panic).To(Equal("sending to <redacted> failed"))
+	})
+
+	It("keeps cfdev's package names when the report is scrubbed again to be sent", func() {
+		path, err := reporter.Report("boom", []byte(fakeStack))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(path).To(BeAnExistingFile())
+
+		Expect(sink.StackScrubber.String(sent[0].Stack)).To(Equal(sent[0].Stack))
+	})
+
+	It("still writes the report when it cannot be sent", func() {
+		reporter.Send = func(crash.Report) error {
+			return errors.New("some-error")
+		}
+
+		path, err := reporter.Report("boom", []byte(fakeStack))
+		Expect(err).To(MatchError("sending the report: some-error"))
+		Expect(path).To(BeAnExistingFile())
+	})
+
+	It("reports a panic and panics again", func() {
+		Expect(func() {
+			defer reporter.Recover()
+			panic("boom")
+		}).To(Panic())
+
+		Expect(sent).To(HaveLen(1))
+		Expect(sent[0].Panic).To(Equal("boom"))
+		Expect(sent[0].Stack).To(ContainSubstring("crash_test.go"))
+
+		files, err := ioutil.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+	})
+
+	It("does nothing without a panic", func() {
+		func() {
+			defer reporter.Recover()
+		}()
+
+		Expect(sent).To(BeEmpty())
+	})
+})
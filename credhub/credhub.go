@@ -0,0 +1,172 @@
+package credhub
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/creds"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"gopkg.in/yaml.v2"
+)
+
+// Credential is a credential stored in CredHub. Value is a string for the
+// value and password types, and a map for the others, e.g. user or
+// certificate.
+type Credential struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// Client talks to the CredHub that runs alongside the director.
+type Client struct {
+	Config config.Config
+	URL    string
+	// HTTPClient is used as is when set. Otherwise one is logged in as the
+	// credhub admin client on first use.
+	HTTPClient *http.Client
+}
+
+func New(cfg config.Config) *Client {
+	return &Client{
+		Config: cfg,
+		URL:    "https://" + cfg.BoshDirectorIP + ":8844",
+	}
+}
+
+// GetCredential returns the current value of the named credential.
+func (c *Client) GetCredential(name string) (Credential, error) {
+	var resp struct {
+		Data []Credential `json:"data"`
+	}
+	query := url.Values{"name": {name}, "current": {"true"}}
+	if err := c.do("GET", "/api/v1/data?"+query.Encode(), nil, &resp); err != nil {
+		return Credential{}, err
+	}
+
+	if len(resp.Data) == 0 {
+		return Credential{}, fmt.Errorf("credential %s not found", name)
+	}
+	return resp.Data[0], nil
+}
+
+// SetCredential stores a new value of the named credential, replacing any
+// it had.
+func (c *Client) SetCredential(name, credType string, value interface{}) error {
+	return c.do("PUT", "/api/v1/data", Credential{Name: name, Type: credType, Value: value}, nil)
+}
+
+func (c *Client) do(method, path string, body interface{}, result interface{}) error {
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, c.URL+path, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("requesting %s: %s", path, err)
+	}
+	defer resp.Body.Close()
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		var credhubErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(contents, &credhubErr) == nil && credhubErr.Error != "" {
+			return fmt.Errorf("credhub responded with %s: %s", resp.Status, credhubErr.Error)
+		}
+		return fmt.Errorf("credhub responded with %s", resp.Status)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(contents, result)
+}
+
+// client logs in to the director's UAA as the credhub admin client, whose
+// secret was generated when the director was deployed.
+func (c *Client) client() (*http.Client, error) {
+	if c.HTTPClient != nil {
+		return c.HTTPClient, nil
+	}
+
+	credentials, err := creds.Load(c.Config)
+	if err != nil {
+		return nil, err
+	}
+	if credentials.CredHub.Password == "" {
+		return nil, fmt.Errorf("credhub is not deployed")
+	}
+
+	pool, err := c.caPool()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: bosh.Transport(pool),
+	})
+	cfg := &clientcredentials.Config{
+		ClientID:     credentials.CredHub.Username,
+		ClientSecret: credentials.CredHub.Password,
+		TokenURL:     "https://" + c.Config.BoshDirectorIP + ":8443/oauth/token",
+	}
+
+	c.HTTPClient = cfg.Client(ctx)
+	return c.HTTPClient, nil
+}
+
+// caPool trusts the director's CA, which signed UAA's certificate, and
+// CredHub's own CA.
+func (c *Client) caPool() (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+
+	ca, err := ioutil.ReadFile(filepath.Join(c.Config.StateBosh, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+	pool.AppendCertsFromPEM(ca)
+
+	vars := struct {
+		CredHubCA struct {
+			Certificate string `yaml:"certificate"`
+		} `yaml:"credhub_ca"`
+	}{}
+	if contents, err := ioutil.ReadFile(filepath.Join(c.Config.StateBosh, "creds.yml")); err == nil {
+		if err := yaml.Unmarshal(contents, &vars); err == nil {
+			pool.AppendCertsFromPEM([]byte(vars.CredHubCA.Certificate))
+		}
+	}
+	return pool, nil
+}
@@ -0,0 +1,91 @@
+package credhub_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/credhub"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		server   *httptest.Server
+		handler  http.HandlerFunc
+		requests []*http.Request
+		bodies   []string
+		client   *credhub.Client
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		bodies = nil
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			requests = append(requests, r)
+			bodies = append(bodies, string(body))
+			handler(w, r)
+		}))
+
+		client = credhub.New(config.Config{BoshDirectorIP: "10.144.0.4"})
+		Expect(client.URL).To(Equal("https://10.144.0.4:8844"))
+		client.URL = server.URL
+		client.HTTPClient = server.Client()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("GetCredential", func() {
+		It("returns the current value", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"data":[{"name":"/cf/admin_password","type":"password","value":"some-password"}]}`))
+			}
+
+			Expect(client.GetCredential("/cf/admin_password")).To(Equal(credhub.Credential{
+				Name:  "/cf/admin_password",
+				Type:  "password",
+				Value: "some-password",
+			}))
+			Expect(requests[0].Method).To(Equal("GET"))
+			Expect(requests[0].URL.Path).To(Equal("/api/v1/data"))
+			Expect(requests[0].URL.Query().Get("name")).To(Equal("/cf/admin_password"))
+			Expect(requests[0].URL.Query().Get("current")).To(Equal("true"))
+		})
+
+		It("returns credhub's error", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":"The request could not be completed because the credential does not exist or you do not have sufficient authorization."}`))
+			}
+
+			_, err := client.GetCredential("/missing")
+			Expect(err).To(MatchError(ContainSubstring("credhub responded with 404 Not Found: The request could not be completed")))
+		})
+	})
+
+	Describe("SetCredential", func() {
+		It("puts the new value", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{}`))
+			}
+
+			Expect(client.SetCredential("/my/secret", "value", "some-value")).To(Succeed())
+			Expect(requests[0].Method).To(Equal("PUT"))
+			Expect(requests[0].URL.Path).To(Equal("/api/v1/data"))
+
+			var body map[string]interface{}
+			Expect(json.Unmarshal([]byte(bodies[0]), &body)).To(Succeed())
+			Expect(body).To(Equal(map[string]interface{}{
+				"name":  "/my/secret",
+				"type":  "value",
+				"value": "some-value",
+			}))
+		})
+	})
+})
@@ -0,0 +1,93 @@
+package creds
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// KeychainService is the service credentials are mirrored under in the
+// host's credential store.
+const KeychainService = "org.cloudfoundry.cfdev"
+
+// ProxyService is the item of the host's credential store the proxy's
+// credentials are read from, which the user stores them in.
+const ProxyService = KeychainService + ".proxy"
+
+type Account struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type Director struct {
+	Account
+	Address       string `json:"address"`
+	CACertificate string `json:"ca_certificate"`
+}
+
+// Credentials are the credentials generated for, or hardcoded into, an
+// environment. Clients that were not deployed are left empty.
+type Credentials struct {
+	CF             Account  `json:"cf_admin"`
+	UAAAdminClient Account  `json:"uaa_admin_client"`
+	Director       Director `json:"director"`
+	CredHub        Account  `json:"credhub"`
+}
+
+// Accounts names each non-empty account, e.g. for storing them one by one.
+func (c Credentials) Accounts() map[string]Account {
+	accounts := map[string]Account{}
+	for name, account := range map[string]Account{
+		"cf-admin":         c.CF,
+		"uaa-admin-client": c.UAAAdminClient,
+		"director":         c.Director.Account,
+		"credhub":          c.CredHub,
+	} {
+		if account.Password != "" {
+			accounts[name] = account
+		}
+	}
+	return accounts
+}
+
+// Load gathers the credentials from the bosh state dir, which is written
+// readable by its owner only, and the config.
+func Load(cfg config.Config) (Credentials, error) {
+	secret, err := ioutil.ReadFile(filepath.Join(cfg.StateBosh, "secret"))
+	if err != nil {
+		return Credentials{}, errors.SafeWrap(err, "reading director secret")
+	}
+
+	vars := struct {
+		UAAAdminClientSecret     string `yaml:"uaa_admin_client_secret"`
+		CredHubAdminClientSecret string `yaml:"credhub_admin_client_secret"`
+	}{}
+	content, err := ioutil.ReadFile(filepath.Join(cfg.StateBosh, "creds.yml"))
+	if err != nil && !os.IsNotExist(err) {
+		return Credentials{}, errors.SafeWrap(err, "reading director vars")
+	}
+	if err := yaml.Unmarshal(content, &vars); err != nil {
+		return Credentials{}, errors.SafeWrap(err, "parsing director vars")
+	}
+
+	creds := Credentials{
+		CF: Account{Username: cfg.CFAdminUsername, Password: cfg.CFAdminPassword},
+		Director: Director{
+			Account:       Account{Username: "admin", Password: strings.TrimSpace(string(secret))},
+			Address:       cfg.BoshDirectorIP,
+			CACertificate: filepath.Join(cfg.StateBosh, "ca.crt"),
+		},
+	}
+	if vars.UAAAdminClientSecret != "" {
+		creds.UAAAdminClient = Account{Username: "uaa_admin", Password: vars.UAAAdminClientSecret}
+	}
+	if vars.CredHubAdminClientSecret != "" {
+		creds.CredHub = Account{Username: "credhub-admin", Password: vars.CredHubAdminClientSecret}
+	}
+	return creds, nil
+}
@@ -0,0 +1,74 @@
+package creds_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/creds"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load", func() {
+	var (
+		dir string
+		cfg config.Config
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "cfdev-creds")
+		Expect(err).NotTo(HaveOccurred())
+
+		cfg = config.Config{StateBosh: dir, BoshDirectorIP: "10.0.0.1", CFAdminUsername: "admin", CFAdminPassword: "some-cf-password"}
+		Expect(ioutil.WriteFile(filepath.Join(dir, "secret"), []byte("some-director-secret\n"), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("gathers the credentials of every component", func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "creds.yml"), []byte(`
+admin_password: some-director-secret
+uaa_admin_client_secret: some-uaa-secret
+credhub_admin_client_secret: some-credhub-secret
+`), 0644)).To(Succeed())
+
+		c, err := creds.Load(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c).To(Equal(creds.Credentials{
+			CF:             creds.Account{Username: "admin", Password: "some-cf-password"},
+			UAAAdminClient: creds.Account{Username: "uaa_admin", Password: "some-uaa-secret"},
+			Director: creds.Director{
+				Account:       creds.Account{Username: "admin", Password: "some-director-secret"},
+				Address:       "10.0.0.1",
+				CACertificate: filepath.Join(dir, "ca.crt"),
+			},
+			CredHub: creds.Account{Username: "credhub-admin", Password: "some-credhub-secret"},
+		}))
+		Expect(c.Accounts()).To(HaveLen(4))
+	})
+
+	Context("when the director was deployed without uaa or credhub", func() {
+		It("leaves their clients empty", func() {
+			c, err := creds.Load(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.UAAAdminClient).To(BeZero())
+			Expect(c.CredHub).To(BeZero())
+			Expect(c.Accounts()).To(HaveKey("director"))
+			Expect(c.Accounts()).NotTo(HaveKey("credhub"))
+		})
+	})
+
+	Context("when the director has not been deployed", func() {
+		It("returns an error", func() {
+			os.Remove(filepath.Join(dir, "secret"))
+
+			_, err := creds.Load(cfg)
+			Expect(err).To(MatchError(ContainSubstring("reading director secret")))
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package creds
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Keychain mirrors credentials into the user's login keychain, so they can
+// be read with e.g. `security find-generic-password -s org.cloudfoundry.cfdev -a director -w`.
+type Keychain struct{}
+
+// Store runs security interactively, writing the commands to its stdin, so
+// that the passwords are not on a command line that any user can list.
+func (k *Keychain) Store(c Credentials) error {
+	for name, account := range c.Accounts() {
+		cmd := exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(strings.Join([]string{
+			"add-generic-password", "-U",
+			"-s", quote(KeychainService),
+			"-a", quote(name),
+			"-l", quote(fmt.Sprintf("%s (%s)", KeychainService, account.Username)),
+			"-w", quote(account.Password),
+		}, " ") + "\n")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("storing %s in keychain: %s: %s", name, err, output)
+		}
+	}
+	return nil
+}
+
+// quote makes value one argument of security's interactive mode.
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// keychainNotFound is the exit status of security when there is no such
+// item.
+const keychainNotFound = 44
+
+var accountAttribute = regexp.MustCompile(`"acct"<blob>="([^"]*)"`)
+
+// Proxy reads the proxy's credentials, stored with e.g.
+// `security add-generic-password -s org.cloudfoundry.cfdev.proxy -a alice -w`,
+// which prompts for the password. It is empty when they are not stored.
+func (k *Keychain) Proxy() (Account, error) {
+	attributes, err := exec.Command("security", "find-generic-password", "-s", ProxyService).Output()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == keychainNotFound {
+		return Account{}, nil
+	} else if err != nil {
+		return Account{}, fmt.Errorf("reading the proxy's credentials from keychain: %s", err)
+	}
+	match := accountAttribute.FindSubmatch(attributes)
+	if match == nil {
+		return Account{}, fmt.Errorf("reading the proxy's credentials from keychain: %s has no account", ProxyService)
+	}
+
+	password, err := exec.Command("security", "find-generic-password", "-s", ProxyService, "-w").Output()
+	if err != nil {
+		return Account{}, fmt.Errorf("reading the proxy's credentials from keychain: %s", err)
+	}
+	return Account{
+		Username: string(match[1]),
+		Password: strings.TrimSuffix(string(password), "\n"),
+	}, nil
+}
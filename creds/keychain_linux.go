@@ -0,0 +1,57 @@
+package creds
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Keychain mirrors credentials into the desktop's secret service, e.g.
+// GNOME Keyring or KWallet, so they can be read with e.g.
+// `secret-tool lookup service org.cloudfoundry.cfdev account director`.
+type Keychain struct{}
+
+// Store gives secret-tool the passwords on its stdin, so that they are not
+// on a command line that any user can list.
+func (k *Keychain) Store(c Credentials) error {
+	for name, account := range c.Accounts() {
+		cmd := exec.Command("secret-tool", "store",
+			"--label", fmt.Sprintf("%s (%s)", KeychainService, account.Username),
+			"service", KeychainService,
+			"account", name)
+		cmd.Stdin = strings.NewReader(account.Password)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("storing %s in the secret service: %s: %s", name, err, output)
+		}
+	}
+	return nil
+}
+
+// Proxy reads the proxy's credentials, stored with e.g.
+// `secret-tool store --label proxy service org.cloudfoundry.cfdev.proxy account alice`,
+// which prompts for the password. It is empty when they are not stored.
+func (k *Keychain) Proxy() (Account, error) {
+	output, err := exec.Command("secret-tool", "search", "service", ProxyService).Output()
+	if exitErr, ok := err.(*exec.ExitError); ok && len(output) == 0 && len(exitErr.Stderr) == 0 {
+		return Account{}, nil
+	} else if err != nil {
+		return Account{}, fmt.Errorf("reading the proxy's credentials from the secret service: %s", err)
+	}
+
+	var (
+		account      Account
+		haveUsername bool
+	)
+	for _, line := range strings.Split(string(output), "\n") {
+		if value := strings.TrimPrefix(line, "attribute.account = "); value != line {
+			account.Username, haveUsername = value, true
+		} else if value := strings.TrimPrefix(line, "secret = "); value != line {
+			account.Password = value
+		}
+	}
+	if !haveUsername {
+		return Account{}, fmt.Errorf("reading the proxy's credentials from the secret service: %s has no account", ProxyService)
+	}
+	return account, nil
+}
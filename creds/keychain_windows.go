@@ -0,0 +1,123 @@
+package creds
+
+import (
+	"fmt"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// Keychain mirrors credentials into the Windows Credential Manager as
+// generic credentials named org.cloudfoundry.cfdev/<account>.
+type Keychain struct{}
+
+// Store writes the credentials with the Credential Manager's API, rather
+// than cmdkey, so that the passwords are not on a command line that any
+// user can list.
+func (k *Keychain) Store(c Credentials) error {
+	for name, account := range c.Accounts() {
+		if err := writeCredential(fmt.Sprintf("%s/%s", KeychainService, name), account); err != nil {
+			return fmt.Errorf("storing %s in credential manager: %s", name, err)
+		}
+	}
+	return nil
+}
+
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	procCredReadW  = advapi32.NewProc("CredReadW")
+	procCredWriteW = advapi32.NewProc("CredWriteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric = 1
+	// credPersistLocalMachine keeps the credential across logons, as
+	// cmdkey does.
+	credPersistLocalMachine = 2
+	errorNotFound           = syscall.Errno(1168)
+)
+
+// credential is the CREDENTIALW the Credential Manager reads into.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// Proxy reads the proxy's credentials, stored with e.g.
+// `cmdkey /generic:org.cloudfoundry.cfdev.proxy /user:alice /pass`, which
+// prompts for the password. It is empty when they are not stored.
+func (k *Keychain) Proxy() (Account, error) {
+	target, err := syscall.UTF16PtrFromString(ProxyService)
+	if err != nil {
+		return Account{}, err
+	}
+
+	var cred *credential
+	ret, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ret == 0 {
+		if err == errorNotFound {
+			return Account{}, nil
+		}
+		return Account{}, fmt.Errorf("reading the proxy's credentials from credential manager: %s", err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	// cmdkey stores the password as UTF-16.
+	blob := (*[1 << 20]uint16)(unsafe.Pointer(cred.CredentialBlob))[: cred.CredentialBlobSize/2 : cred.CredentialBlobSize/2]
+	return Account{
+		Username: utf16PtrToString(cred.UserName),
+		Password: string(utf16.Decode(blob)),
+	}, nil
+}
+
+// writeCredential stores account as the generic credential target, with
+// the password in UTF-16 like cmdkey stores it.
+func writeCredential(target string, account Account) error {
+	targetName, err := syscall.UTF16PtrFromString(target)
+	if err != nil {
+		return err
+	}
+	userName, err := syscall.UTF16PtrFromString(account.Username)
+	if err != nil {
+		return err
+	}
+
+	cred := credential{
+		Type:       credTypeGeneric,
+		TargetName: targetName,
+		UserName:   userName,
+		Persist:    credPersistLocalMachine,
+	}
+	if password := utf16.Encode([]rune(account.Password)); len(password) > 0 {
+		cred.CredentialBlobSize = uint32(len(password) * 2)
+		cred.CredentialBlob = (*byte)(unsafe.Pointer(&password[0]))
+	}
+
+	ret, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
+
+func utf16PtrToString(p *uint16) string {
+	if p == nil {
+		return ""
+	}
+	var chars []uint16
+	for ptr := unsafe.Pointer(p); *(*uint16)(ptr) != 0; ptr = unsafe.Pointer(uintptr(ptr) + 2) {
+		chars = append(chars, *(*uint16)(ptr))
+	}
+	return string(utf16.Decode(chars))
+}
@@ -145,6 +145,13 @@ var plistTemplate = `<?xml version="1.0" encoding="UTF-8"?>
   </array>
   <key>RunAtLoad</key>
   <{{.RunAtLoad}}/>
+  {{if .KeepAlive}}
+  <key>KeepAlive</key>
+  <dict>
+    <key>Crashed</key>
+    <true/>
+  </dict>
+  {{end}}
   {{if .Sockets}}
   <key>Sockets</key>
   <dict>
@@ -136,6 +136,42 @@ var _ = Describe("Launchd", func() {
   </dict>
 </dict>
 </plist>
+`, label, executableToInstall, executableToInstall)))
+		})
+
+		It("restarts crashed daemons when asked to keep them alive", func() {
+			executableToInstall := filepath.Join(binDir, "some-executable")
+			spec := daemon.DaemonSpec{
+				Label:            label,
+				Program:          executableToInstall,
+				ProgramArguments: []string{executableToInstall},
+				KeepAlive:        true,
+			}
+
+			Expect(lnchd.AddDaemon(spec)).To(Succeed())
+
+			Expect(ioutil.ReadFile(plistPath)).To(MatchXML(fmt.Sprintf(
+				`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>Label</key>
+  <string>%s</string>
+  <key>Program</key>
+  <string>%s</string>
+  <key>ProgramArguments</key>
+  <array>
+    <string>%s</string>
+  </array>
+  <key>RunAtLoad</key>
+  <false/>
+  <key>KeepAlive</key>
+  <dict>
+    <key>Crashed</key>
+    <true/>
+  </dict>
+</dict>
+</plist>
 `, label, executableToInstall, executableToInstall)))
 		})
 	})
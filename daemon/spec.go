@@ -9,4 +9,7 @@ type DaemonSpec struct {
 	Sockets          map[string]string
 	StdoutPath       string
 	StderrPath       string
+	// KeepAlive has the service manager restart the program when it
+	// crashes. Stopping the daemon does not count as a crash.
+	KeepAlive bool
 }
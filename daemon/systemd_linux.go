@@ -0,0 +1,132 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Systemd runs daemons as units of the user's own systemd instance, so
+// that, as with launchd, cf dev needs no root to keep them running.
+type Systemd struct {
+	UnitDir string
+}
+
+func New(unitDir string) *Systemd {
+	return &Systemd{
+		UnitDir: unitDir,
+	}
+}
+
+func (s *Systemd) AddDaemon(spec DaemonSpec) error {
+	unitPath := filepath.Join(s.UnitDir, unit(spec.Label))
+	s.RemoveDaemon(spec.Label)
+	if err := s.writeUnit(spec, unitPath); err != nil {
+		return err
+	}
+	if err := systemctl("link", unitPath); err != nil {
+		return err
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	if spec.RunAtLoad {
+		return s.Start(spec.Label)
+	}
+	return nil
+}
+
+func (s *Systemd) RemoveDaemon(label string) error {
+	unitPath := filepath.Join(s.UnitDir, unit(label))
+	if _, err := os.Stat(unitPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := s.Stop(label); err != nil {
+		return err
+	}
+	if err := systemctl("disable", unit(label)); err != nil {
+		return err
+	}
+	if err := os.Remove(unitPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return systemctl("daemon-reload")
+}
+
+func (s *Systemd) Start(label string) error {
+	return systemctl("start", unit(label))
+}
+
+func (s *Systemd) Stop(label string) error {
+	if running, _ := s.IsRunning(label); !running {
+		return nil
+	}
+	return systemctl("stop", unit(label))
+}
+
+// IsRunning reads is-active, which exits non-zero for every state but
+// active, so its exit status is not an error here.
+func (s *Systemd) IsRunning(label string) (bool, error) {
+	out, err := exec.Command("systemctl", "--user", "is-active", unit(label)).Output()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		return false, err
+	}
+	return strings.TrimSpace(string(out)) == "active", nil
+}
+
+func unit(label string) string {
+	return label + ".service"
+}
+
+func systemctl(args ...string) error {
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (s *Systemd) writeUnit(spec DaemonSpec, dest string) error {
+	tmplt := template.Must(template.New("unit").Funcs(template.FuncMap{
+		"quote": quote,
+		"seconds": func(d time.Duration) int {
+			return int(d / time.Second)
+		},
+	}).Parse(unitTemplate))
+	unit, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer unit.Close()
+	return tmplt.Execute(unit, struct {
+		DaemonSpec
+		RestartDelay time.Duration
+	}{spec, RestartDelay})
+}
+
+// quote makes value one word of ExecStart, whose quoting is systemd's own
+// rather than a shell's.
+func quote(value string) string {
+	return fmt.Sprintf(`"%s"`, strings.NewReplacer(`\`, `\\`, `"`, `\"`, `%`, `%%`, `$`, `$$`).Replace(value))
+}
+
+var unitTemplate = `[Unit]
+Description={{.Label}}
+
+[Service]
+ExecStart={{quote .Program}}{{range $i, $arg := .ProgramArguments}}{{if $i}} {{quote $arg}}{{end}}{{end}}
+{{- if .StdoutPath}}
+StandardOutput=append:{{.StdoutPath}}
+{{- end}}
+{{- if .StderrPath}}
+StandardError=append:{{.StderrPath}}
+{{- end}}
+{{- if .KeepAlive}}
+Restart=on-failure
+RestartSec={{seconds .RestartDelay}}
+{{- end}}
+`
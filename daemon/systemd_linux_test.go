@@ -0,0 +1,136 @@
+package daemon_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/daemon"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Systemd", func() {
+	var (
+		unitDir  string
+		binDir   string
+		calls    string
+		oldPath  string
+		systemd  *daemon.Systemd
+		unitPath string
+	)
+
+	// fakeSystemctl records its arguments and prints state for is-active.
+	fakeSystemctl := func(state string) {
+		Expect(ioutil.WriteFile(filepath.Join(binDir, "systemctl"), []byte(`#!/bin/sh
+echo "$@" >> `+calls+`
+if [ "$2" = is-active ]; then
+  echo `+state+`
+  [ `+state+` = active ]
+fi
+`), 0755)).To(Succeed())
+	}
+
+	recorded := func() string {
+		out, _ := ioutil.ReadFile(calls)
+		return string(out)
+	}
+
+	BeforeEach(func() {
+		unitDir, _ = ioutil.TempDir("", "units")
+		binDir, _ = ioutil.TempDir("", "bin")
+		calls = filepath.Join(binDir, "calls")
+		oldPath = os.Getenv("PATH")
+		os.Setenv("PATH", binDir+":"+oldPath)
+		fakeSystemctl("inactive")
+		systemd = daemon.New(unitDir)
+		unitPath = filepath.Join(unitDir, "some-label.service")
+	})
+
+	AfterEach(func() {
+		os.Setenv("PATH", oldPath)
+		os.RemoveAll(unitDir)
+		os.RemoveAll(binDir)
+	})
+
+	Describe("AddDaemon", func() {
+		It("writes the unit and links it", func() {
+			Expect(systemd.AddDaemon(daemon.DaemonSpec{
+				Label:            "some-label",
+				Program:          "/some/program",
+				ProgramArguments: []string{"/some/program", "some arg", "50%"},
+				StdoutPath:       "/some/stdout.log",
+				StderrPath:       "/some/stderr.log",
+				KeepAlive:        true,
+			})).To(Succeed())
+
+			unit, err := ioutil.ReadFile(unitPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(unit)).To(ContainSubstring(`ExecStart="/some/program" "some arg" "50%%"` + "\n"))
+			Expect(string(unit)).To(ContainSubstring("StandardOutput=append:/some/stdout.log\n"))
+			Expect(string(unit)).To(ContainSubstring("StandardError=append:/some/stderr.log\n"))
+			Expect(string(unit)).To(ContainSubstring("Restart=on-failure\nRestartSec=10\n"))
+			Expect(recorded()).To(Equal("--user link " + unitPath + "\n--user daemon-reload\n"))
+		})
+
+		It("leaves a daemon that is not kept alive to stay down", func() {
+			Expect(systemd.AddDaemon(daemon.DaemonSpec{Label: "some-label", Program: "/some/program"})).To(Succeed())
+
+			unit, _ := ioutil.ReadFile(unitPath)
+			Expect(string(unit)).NotTo(ContainSubstring("Restart="))
+		})
+
+		It("starts the daemon when it runs at load", func() {
+			Expect(systemd.AddDaemon(daemon.DaemonSpec{Label: "some-label", Program: "/some/program", RunAtLoad: true})).To(Succeed())
+
+			Expect(recorded()).To(HaveSuffix("--user start some-label.service\n"))
+		})
+
+		It("takes the restart delay from the watch", func() {
+			daemon.RestartDelay = 30 * time.Second
+			defer func() { daemon.RestartDelay = 10 * time.Second }()
+
+			Expect(systemd.AddDaemon(daemon.DaemonSpec{Label: "some-label", Program: "/some/program", KeepAlive: true})).To(Succeed())
+
+			unit, _ := ioutil.ReadFile(unitPath)
+			Expect(string(unit)).To(ContainSubstring("RestartSec=30\n"))
+		})
+	})
+
+	Describe("RemoveDaemon", func() {
+		It("stops, disables and deletes the unit", func() {
+			fakeSystemctl("active")
+			Expect(ioutil.WriteFile(unitPath, []byte{}, 0644)).To(Succeed())
+
+			Expect(systemd.RemoveDaemon("some-label")).To(Succeed())
+
+			Expect(unitPath).NotTo(BeAnExistingFile())
+			Expect(recorded()).To(Equal(
+				"--user is-active some-label.service\n" +
+					"--user stop some-label.service\n" +
+					"--user disable some-label.service\n" +
+					"--user daemon-reload\n"))
+		})
+
+		It("does nothing when there is no unit", func() {
+			Expect(systemd.RemoveDaemon("some-label")).To(Succeed())
+
+			Expect(recorded()).To(BeEmpty())
+		})
+	})
+
+	Describe("IsRunning", func() {
+		It("is true when the unit is active", func() {
+			fakeSystemctl("active")
+
+			Expect(systemd.IsRunning("some-label")).To(BeTrue())
+		})
+
+		It("is false otherwise", func() {
+			fakeSystemctl("failed")
+
+			Expect(systemd.IsRunning("some-label")).To(BeFalse())
+		})
+	})
+})
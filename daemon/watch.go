@@ -0,0 +1,43 @@
+package daemon
+
+import "time"
+
+// WatchInterval is how often Watch checks on a daemon.
+var WatchInterval = 5 * time.Second
+
+// RestartDelay is how long the service managers wait before restarting a
+// keep alive daemon that crashed. It is launchd's throttle interval and
+// the delay winsw is configured with.
+var RestartDelay = 10 * time.Second
+
+// restartChecks is how many checks in a row a daemon has to be down for
+// before Watch gives up on it. It outlasts RestartDelay by a check, so a
+// daemon that is being restarted is not reported.
+func restartChecks() int {
+	return int(RestartDelay/WatchInterval) + 2
+}
+
+// Watch sends name on exit once the daemon with the given label has stopped
+// and has not been restarted.
+func Watch(isRunning func(label string) (bool, error), label string, name string, exit chan string) {
+	go func() {
+		down := 0
+		checks := restartChecks()
+		for {
+			running, err := isRunning(label)
+			switch {
+			case err != nil:
+			case running:
+				down = 0
+			default:
+				down++
+			}
+
+			if down >= checks {
+				exit <- name
+				return
+			}
+			time.Sleep(WatchInterval)
+		}
+	}()
+}
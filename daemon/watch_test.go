@@ -0,0 +1,65 @@
+package daemon_test
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/daemon"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Watch", func() {
+	var (
+		mutex   sync.Mutex
+		running []bool
+		exit    chan string
+	)
+
+	isRunning := func(label string) (bool, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		Expect(label).To(Equal("some-label"))
+		if len(running) == 0 {
+			return false, nil
+		}
+		r := running[0]
+		running = running[1:]
+		return r, nil
+	}
+
+	BeforeEach(func() {
+		daemon.WatchInterval = time.Millisecond
+		daemon.RestartDelay = 2 * time.Millisecond
+		exit = make(chan string, 1)
+	})
+
+	AfterEach(func() {
+		daemon.WatchInterval = 5 * time.Second
+		daemon.RestartDelay = 10 * time.Second
+	})
+
+	It("reports a daemon that stays down", func() {
+		mutex.Lock()
+		running = []bool{true, true}
+		mutex.Unlock()
+
+		daemon.Watch(isRunning, "some-label", "some-daemon", exit)
+
+		Eventually(exit).Should(Receive(Equal("some-daemon")))
+	})
+
+	It("tolerates a daemon being restarted", func() {
+		mutex.Lock()
+		running = []bool{true, false, false, true}
+		for i := 0; i < 1000; i++ {
+			running = append(running, true)
+		}
+		mutex.Unlock()
+
+		daemon.Watch(isRunning, "some-label", "some-daemon", exit)
+
+		Consistently(exit, 100*time.Millisecond).ShouldNot(Receive())
+	})
+})
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type WinSW struct {
@@ -24,15 +25,21 @@ func NewWinSW(cfDevHome string) *WinSW {
 }
 
 type Config struct {
-	XMLName     xml.Name `xml:"configuration"`
-	Id          string   `xml:"id"`
-	Name        string   `xml:"name"`
-	Description string   `xml:"description"`
-	Executable  string   `xml:"executable"`
-	Arguments   string   `xml:"arguments"`
-	StartMode   string   `xml:"startmode"`
-	LogPath     string   `xml:"logpath"`
-	LogMode     string   `xml:"logmode"`
+	XMLName     xml.Name    `xml:"configuration"`
+	Id          string      `xml:"id"`
+	Name        string      `xml:"name"`
+	Description string      `xml:"description"`
+	Executable  string      `xml:"executable"`
+	Arguments   string      `xml:"arguments"`
+	StartMode   string      `xml:"startmode"`
+	LogPath     string      `xml:"logpath"`
+	LogMode     string      `xml:"logmode"`
+	OnFailure   []OnFailure `xml:"onfailure,omitempty"`
+}
+
+type OnFailure struct {
+	Action string `xml:"action,attr"`
+	Delay  string `xml:"delay,attr,omitempty"`
 }
 
 func (w *WinSW) AddDaemon(spec DaemonSpec) error {
@@ -147,6 +154,9 @@ func createXml(serviceDst string, spec DaemonSpec) error {
 		LogPath:     filepath.Dir(spec.StdoutPath),
 		LogMode:     "rotate",
 	}
+	if spec.KeepAlive {
+		config.OnFailure = []OnFailure{{Action: "restart", Delay: fmt.Sprintf("%d sec", int(RestartDelay/time.Second))}}
+	}
 	configWriter := io.Writer(file)
 
 	enc := xml.NewEncoder(configWriter)
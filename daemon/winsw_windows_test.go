@@ -63,6 +63,21 @@ var _ = Describe("Winsw", func() {
 
 			Expect(output).NotTo(BeEmpty())
 		})
+
+		It("restarts crashed daemons when asked to keep them alive", func() {
+			spec := daemon.DaemonSpec{
+				Label:            label,
+				Program:          "powershell.exe",
+				ProgramArguments: []string{"echo 'hello'"},
+				KeepAlive:        true,
+			}
+
+			Expect(winsw.AddDaemon(spec)).To(Succeed())
+
+			contents, err := ioutil.ReadFile(filepath.Join(tmpDir, "winservice", label, label+".xml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring(`<onfailure action="restart" delay="10 sec"></onfailure>`))
+		})
 	})
 
 	Describe("RemoveDaemon", func() {
@@ -3,7 +3,9 @@ package env
 import (
 	"code.cloudfoundry.org/cfdev/resource"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 
@@ -54,15 +56,21 @@ func BuildProxyConfig(boshDirectorIP string, cfRouterIP string, hostIP string) P
 	return proxyConfig
 }
 
+// importedMarker flags state restored by cf dev import, which the next
+// start boots from in place of fresh state from the deps tarball.
+const importedMarker = "imported"
+
 type Env struct {
 	Config config.Config
 }
 
 func (e *Env) CreateDirs() error {
-	err := e.RemoveDirAlls(
-		e.Config.LogDir,
-		e.Config.ServicesDir,
-		e.Config.StateDir)
+	dirs := []string{e.Config.LogDir, e.Config.ServicesDir}
+	if !e.imported() {
+		dirs = append(dirs, e.Config.StateDir)
+	}
+
+	err := e.RemoveDirAlls(dirs...)
 	if err != nil {
 		return err
 	}
@@ -74,7 +82,8 @@ func (e *Env) CreateDirs() error {
 		e.Config.StateLinuxkit,
 		e.Config.StateBosh,
 		e.Config.ServicesDir,
-		e.Config.LogDir)
+		e.Config.LogDir,
+		e.Config.DataDir)
 }
 
 func (e *Env) MkdirAlls(dirs ...string) error {
@@ -97,39 +106,37 @@ func (e *Env) RemoveDirAlls(dirs ...string) error {
 	return nil
 }
 
+// MarkImported makes the next start keep the vm's disk and bosh state
+// rather than replacing them.
+func (e *Env) MarkImported() error {
+	err := ioutil.WriteFile(filepath.Join(e.Config.StateDir, importedMarker), []byte{}, 0600)
+	if err != nil {
+		return errors.SafeWrap(err, "failed to mark the state as imported")
+	}
+	return nil
+}
+
+func (e *Env) imported() bool {
+	_, err := os.Stat(filepath.Join(e.Config.StateDir, importedMarker))
+	return err == nil
+}
+
 func (e *Env) SetupState() error {
-	thingsToUntar := []resource.TarOpts{
-		{
-			Include: "state.json",
-			Dst:     e.Config.StateBosh,
-		},
-		{
-			Include: "creds.yml",
-			Dst:     e.Config.StateBosh,
-		},
-		{
-			Include: "secret",
-			Dst:     e.Config.StateBosh,
-		},
-		{
-			Include: "jumpbox.key",
-			Dst:     e.Config.StateBosh,
-		},
-		{
-			Include: "ca.crt",
-			Dst:     e.Config.StateBosh,
-		},
-		{
-			Include: "ca.yml",
-			Dst:     e.Config.StateBosh,
-		},
+	imported := e.imported()
+
+	var thingsToUntar []resource.TarOpts
+	if !imported {
+		thingsToUntar = append(thingsToUntar, e.boshState()...)
+	}
+
+	thingsToUntar = append(thingsToUntar, []resource.TarOpts{
 		{
 			Include: "id_rsa",
 			Dst:     e.Config.CacheDir,
 		},
 		{
 			IncludeFolder: "services",
-			Dst:           e.Config.CFDevHome,
+			Dst:           filepath.Dir(e.Config.ServicesDir),
 		},
 		{
 			IncludeFolder: "binaries",
@@ -141,14 +148,27 @@ func (e *Env) SetupState() error {
 			FlattenFolder: true,
 			Dst:           e.Config.CacheDir,
 		},
-	}
+	}...)
 
+	var baseDiskStamp string
 	if runtime.GOOS == "windows" {
-		thingsToUntar = append(thingsToUntar, resource.TarOpts{
-			Include: "disk.vhdx",
-			Dst:     e.Config.StateLinuxkit,
-		})
-	} else {
+		// The vm boots from a differencing disk on top of the base disk kept
+		// in the cache, so the multi-GB base only needs extracting again when
+		// the deps tarball changes.
+		stamp, err := e.depsStamp()
+		if err != nil {
+			return err
+		}
+		if !e.baseDiskIsCurrent(stamp) {
+			baseDiskStamp = stamp
+			thingsToUntar = append(thingsToUntar, resource.TarOpts{
+				Include: "disk.vhdx",
+				Dst:     e.Config.CacheDir,
+			})
+		}
+	} else if !imported {
+		// hyperkit's qcow2 support has no backing files, so the vm gets a
+		// full copy of the disk.
 		thingsToUntar = append(thingsToUntar, resource.TarOpts{
 			Include: "disk.qcow2",
 			Dst:     e.Config.StateLinuxkit,
@@ -160,5 +180,55 @@ func (e *Env) SetupState() error {
 		return errors.SafeWrap(err, "failed to untar the desired parts of the tarball")
 	}
 
+	if imported {
+		// Only the first start after an import keeps its state.
+		if err := os.Remove(filepath.Join(e.Config.StateDir, importedMarker)); err != nil {
+			return errors.SafeWrap(err, "failed to clear the imported marker")
+		}
+	}
+
+	if baseDiskStamp != "" {
+		err = ioutil.WriteFile(e.baseDiskStampPath(), []byte(baseDiskStamp), 0600)
+		if err != nil {
+			return errors.SafeWrap(err, "failed to record the base disk version")
+		}
+	}
+
 	return nil
 }
+
+// boshState is written readable by its owner only, as most of it is
+// secret.
+func (e *Env) boshState() []resource.TarOpts {
+	var opts []resource.TarOpts
+	for _, file := range []string{"state.json", "creds.yml", "secret", "jumpbox.key", "ca.crt", "ca.yml"} {
+		opts = append(opts, resource.TarOpts{
+			Include: file,
+			Dst:     e.Config.StateBosh,
+			Mode:    0600,
+		})
+	}
+	return opts
+}
+
+func (e *Env) depsStamp() (string, error) {
+	info, err := os.Stat(*e.Config.DepsFile)
+	if err != nil {
+		return "", errors.SafeWrap(err, "failed to stat the deps tarball")
+	}
+
+	return fmt.Sprintf("%s %d %d", *e.Config.DepsFile, info.Size(), info.ModTime().UnixNano()), nil
+}
+
+func (e *Env) baseDiskIsCurrent(stamp string) bool {
+	if _, err := os.Stat(filepath.Join(e.Config.CacheDir, "disk.vhdx")); err != nil {
+		return false
+	}
+
+	contents, err := ioutil.ReadFile(e.baseDiskStampPath())
+	return err == nil && string(contents) == stamp
+}
+
+func (e *Env) baseDiskStampPath() string {
+	return filepath.Join(e.Config.CacheDir, "disk.vhdx.source")
+}
@@ -5,6 +5,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"runtime"
+	"time"
 
 	"os"
 
@@ -61,7 +62,7 @@ var _ = Describe("env", func() {
 	})
 
 	Describe("CreateDirs", func() {
-		var dir, homeDir, cacheDir, stateDir, boshDir, linuxkitDir, vpnkitStateDir, servicesDir, logDir string
+		var dir, homeDir, cacheDir, stateDir, boshDir, linuxkitDir, vpnkitStateDir, servicesDir, logDir, dataDir string
 		var err error
 		var conf config.Config
 		var subject env.Env
@@ -78,6 +79,7 @@ var _ = Describe("env", func() {
 			vpnkitStateDir = filepath.Join(stateDir, "some-vpnkit-state-dir")
 			servicesDir = filepath.Join(homeDir, "services")
 			logDir = filepath.Join(homeDir, "log")
+			dataDir = filepath.Join(homeDir, "data")
 
 			depsFile := filepath.Join(dir, "tmp-tar.tgz")
 			conf = config.Config{
@@ -90,6 +92,7 @@ var _ = Describe("env", func() {
 				VpnKitStateDir: vpnkitStateDir,
 				ServicesDir:    servicesDir,
 				LogDir:         logDir,
+				DataDir:        dataDir,
 			}
 
 			subject = env.Env{
@@ -123,6 +126,17 @@ var _ = Describe("env", func() {
 
 			_, err = os.Stat(logDir)
 			Expect(err).NotTo(HaveOccurred())
+
+			_, err = os.Stat(dataDir)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("keeps the data disk", func() {
+			Expect(os.MkdirAll(dataDir, 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dataDir, "data.qcow2"), []byte("data"), 0600)).To(Succeed())
+
+			Expect(subject.CreateDirs()).To(Succeed())
+			Expect(filepath.Join(dataDir, "data.qcow2")).To(BeAnExistingFile())
 		})
 
 		Context("when there is already state in the home dir", func() {
@@ -176,16 +190,16 @@ var _ = Describe("env", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
-			It("overwrites the qcow disk with a new one", func() {
+			It("overwrites the vm disk with a new one", func() {
 				var fPath string
 
 				if runtime.GOOS == "windows" {
-					fPath = filepath.Join(stateDir, "some-linuxkit-state-dir", "disk.vhdx")
+					fPath = filepath.Join(cacheDir, "disk.vhdx")
 				} else {
 					fPath = filepath.Join(stateDir, "some-linuxkit-state-dir", "disk.qcow2")
 				}
 
-				Expect(os.MkdirAll(filepath.Join(stateDir, "some-linuxkit-state-dir"), 0755)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Dir(fPath), 0755)).To(Succeed())
 				Expect(ioutil.WriteFile(fPath, []byte("old-qcow"), 0600)).To(Succeed())
 
 				Expect(subject.CreateDirs()).To(Succeed())
@@ -196,6 +210,31 @@ var _ = Describe("env", func() {
 				Expect(string(b)).To(Equal("tmp-disk"))
 			})
 
+			if runtime.GOOS == "windows" {
+				It("only extracts the base disk again when the deps tarball changes", func() {
+					fPath := filepath.Join(cacheDir, "disk.vhdx")
+
+					Expect(subject.CreateDirs()).To(Succeed())
+					Expect(subject.SetupState()).To(Succeed())
+					Expect(ioutil.WriteFile(fPath, []byte("base-disk"), 0600)).To(Succeed())
+
+					Expect(subject.CreateDirs()).To(Succeed())
+					Expect(subject.SetupState()).To(Succeed())
+
+					b, err := ioutil.ReadFile(fPath)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(b)).To(Equal("base-disk"))
+
+					later := time.Now().Add(time.Minute)
+					Expect(os.Chtimes(*conf.DepsFile, later, later)).To(Succeed())
+					Expect(subject.SetupState()).To(Succeed())
+
+					b, err = ioutil.ReadFile(fPath)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(b)).To(Equal("tmp-disk"))
+				})
+			}
+
 			It("copies bosh state", func() {
 				Expect(subject.CreateDirs()).To(Succeed())
 				Expect(subject.SetupState()).To(Succeed())
@@ -265,6 +304,37 @@ var _ = Describe("env", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(string(b)).To(Equal("some-bosh-secret"))
 			})
+
+			Context("when the state was imported", func() {
+				BeforeEach(func() {
+					Expect(subject.CreateDirs()).To(Succeed())
+					Expect(ioutil.WriteFile(filepath.Join(boshDir, "state.json"), []byte("imported-state"), 0600)).To(Succeed())
+					Expect(ioutil.WriteFile(filepath.Join(linuxkitDir, "disk.qcow2"), []byte("imported-disk"), 0600)).To(Succeed())
+					Expect(subject.MarkImported()).To(Succeed())
+				})
+
+				It("keeps the imported state for the next start only", func() {
+					Expect(subject.CreateDirs()).To(Succeed())
+					Expect(subject.SetupState()).To(Succeed())
+
+					b, err := ioutil.ReadFile(filepath.Join(boshDir, "state.json"))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(b)).To(Equal("imported-state"))
+
+					if runtime.GOOS != "windows" {
+						b, err = ioutil.ReadFile(filepath.Join(linuxkitDir, "disk.qcow2"))
+						Expect(err).ToNot(HaveOccurred())
+						Expect(string(b)).To(Equal("imported-disk"))
+					}
+
+					Expect(subject.CreateDirs()).To(Succeed())
+					Expect(subject.SetupState()).To(Succeed())
+
+					b, err = ioutil.ReadFile(filepath.Join(boshDir, "state.json"))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(b)).To(Equal("state"))
+				})
+			})
 		})
 
 		Context("when home dir cannot be created", func() {
@@ -0,0 +1,111 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.cloudfoundry.org/cfdev/errors"
+)
+
+// MoveHome moves everything in the cfdev home to dir, e.g. onto a bigger
+// drive, except the config file, which records where the home went. Each
+// entry is renamed when it can be, and copied and then removed when dir is
+// on another drive, so that an interrupted move leaves the rest in place
+// to be moved again.
+func (e *Env) MoveHome(dir string) error {
+	from, err := filepath.Abs(e.Config.CFDevHome)
+	if err != nil {
+		return errors.SafeWrap(err, "failed to move the cfdev home")
+	}
+	to, err := filepath.Abs(dir)
+	if err != nil {
+		return errors.SafeWrap(err, "failed to move the cfdev home")
+	}
+	if to == from || strings.HasPrefix(to, from+string(filepath.Separator)) {
+		return fmt.Errorf("cannot move the cfdev home %s into itself", from)
+	}
+
+	entries, err := ioutil.ReadDir(from)
+	if os.IsNotExist(err) {
+		entries = nil
+	} else if err != nil {
+		return errors.SafeWrap(err, "failed to move the cfdev home")
+	}
+	if err := os.MkdirAll(to, 0755); err != nil {
+		return errors.SafeWrap(fmt.Errorf("path %s: %s", to, err), "failed to create dir")
+	}
+
+	for _, entry := range entries {
+		src := filepath.Join(from, entry.Name())
+		dst := filepath.Join(to, entry.Name())
+		if src == e.Config.ConfigFile {
+			continue
+		}
+		if _, err := os.Lstat(dst); err == nil {
+			return fmt.Errorf("cannot move %s, %s already exists", src, dst)
+		}
+
+		if err := os.Rename(src, dst); err == nil {
+			continue
+		}
+		if err := copyTree(src, dst); err != nil {
+			os.RemoveAll(dst)
+			return errors.SafeWrap(fmt.Errorf("path %s: %s", src, err), "failed to move the cfdev home")
+		}
+		if err := os.RemoveAll(src); err != nil {
+			return errors.SafeWrap(fmt.Errorf("path %s: %s", src, err), "failed to remove dir")
+		}
+	}
+	return nil
+}
+
+// copyTree copies the files, directories and symlinks under src to dst.
+// Sockets and the like belong to daemons that are stopped, so are skipped.
+func copyTree(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch mode := info.Mode(); {
+		case mode.IsDir():
+			return os.MkdirAll(target, mode.Perm()|0700)
+		case mode&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case mode.IsRegular():
+			return copyFile(path, target, mode.Perm())
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,69 @@
+package env_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/env"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MoveHome", func() {
+	var (
+		tmpDir  string
+		home    string
+		newHome string
+		subject *env.Env
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cfdev-move-home-")
+		Expect(err).NotTo(HaveOccurred())
+		home = filepath.Join(tmpDir, "home")
+		newHome = filepath.Join(tmpDir, "bigger-drive", "cfdev")
+
+		Expect(os.MkdirAll(filepath.Join(home, "cache"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(home, "state", "bosh"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(home, "cache", "cfdev-deps.tgz"), []byte("deps"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(home, "state", "bosh", "state.json"), []byte("{}"), 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(home, "config.yml"), []byte("memory: 8192\n"), 0644)).To(Succeed())
+
+		subject = &env.Env{Config: config.Config{
+			CFDevHome:  home,
+			ConfigFile: filepath.Join(home, "config.yml"),
+		}}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("moves everything but the config file", func() {
+		Expect(subject.MoveHome(newHome)).To(Succeed())
+
+		Expect(ioutil.ReadFile(filepath.Join(newHome, "cache", "cfdev-deps.tgz"))).To(Equal([]byte("deps")))
+		Expect(ioutil.ReadFile(filepath.Join(newHome, "state", "bosh", "state.json"))).To(Equal([]byte("{}")))
+		Expect(filepath.Join(newHome, "config.yml")).NotTo(BeAnExistingFile())
+
+		entries, err := ioutil.ReadDir(home)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Name()).To(Equal("config.yml"))
+	})
+
+	It("refuses to overwrite what is already there", func() {
+		Expect(os.MkdirAll(filepath.Join(newHome, "cache"), 0755)).To(Succeed())
+
+		Expect(subject.MoveHome(newHome)).To(MatchError(ContainSubstring("already exists")))
+		Expect(filepath.Join(home, "cache", "cfdev-deps.tgz")).To(BeAnExistingFile())
+	})
+
+	It("refuses to move the home into itself", func() {
+		Expect(subject.MoveHome(filepath.Join(home, "sub"))).To(MatchError(ContainSubstring("into itself")))
+		Expect(subject.MoveHome(home)).To(MatchError(ContainSubstring("into itself")))
+	})
+})
@@ -0,0 +1,147 @@
+#!/usr/bin/env bash
+set -eo pipefail
+
+# Builds the release binaries of the plugin for every platform that has an
+# asset manifest in <assets-dir>, named <goos>-<goarch>.env, e.g.
+#
+#   cfdepsUrl=https://.../cfdev-deps-darwin.tgz
+#   cfdepsMd5=...
+#   cfdepsSize=...
+#   analyticsdUrl=...   (and Md5, Size)
+#   cfdevdUrl=...       (and Md5, Size; darwin only)
+#
+# The manifest is compiled into that platform's binary as its asset catalog.
+# CFDEV_ANALYTICS_KEY must be the production analytics key, so that release
+# telemetry does not land with the development builds'.
+# CFDEV_RELEASE_MANIFEST is the url the manifest.json below is published at,
+# e.g. https://.../releases/latest/manifest.json, with the binaries beside
+# it; `cf dev self-update` reads it to find newer releases.
+# Binaries are signed when CFDEV_CODESIGN_IDENTITY (darwin) or
+# CFDEV_AUTHENTICODE_CERT and CFDEV_AUTHENTICODE_PASSWORD (windows) are set.
+# A manifest.json with the checksums of every binary is written alongside
+# them for the plugin repository index.
+
+if [[ -z "$2" ]]; then
+  echo "USAGE: $0 <version> <assets-dir> [output-dir]"
+  exit 1
+fi
+
+version=$1
+assets_dir=$(cd "$2" && pwd)
+output_dir=${3:-"$PWD/release"}
+pkg="code.cloudfoundry.org/cfdev/config"
+
+if [[ -z "$CFDEV_ANALYTICS_KEY" ]]; then
+  echo "CFDEV_ANALYTICS_KEY must be set to the production analytics key" >&2
+  exit 1
+fi
+analyticskey=$CFDEV_ANALYTICS_KEY
+
+if [[ -z "$CFDEV_RELEASE_MANIFEST" ]]; then
+  echo "CFDEV_RELEASE_MANIFEST is not set, so the binaries cannot update themselves" >&2
+fi
+
+# Hyperkit only runs on amd64 macs; arm64 macs and linux run the vm with
+# QEMU instead.
+supported="darwin-amd64 darwin-arm64 windows-amd64 linux-amd64"
+
+function sign() {
+  local goos=$1 binary=$2
+
+  case $goos in
+    darwin)
+      if [[ -n "$CFDEV_CODESIGN_IDENTITY" ]]; then
+        codesign --force --sign "$CFDEV_CODESIGN_IDENTITY" "$binary"
+      fi
+      ;;
+    windows)
+      if [[ -n "$CFDEV_AUTHENTICODE_CERT" ]]; then
+        osslsigncode sign \
+          -pkcs12 "$CFDEV_AUTHENTICODE_CERT" \
+          -pass "$CFDEV_AUTHENTICODE_PASSWORD" \
+          -n "CF Dev" \
+          -in "$binary" \
+          -out "$binary.signed"
+        mv "$binary.signed" "$binary"
+      fi
+      ;;
+  esac
+}
+
+function build() {
+  local platform=$1 manifest=$2
+  local goos=${platform%-*} goarch=${platform#*-}
+  local binary="$output_dir/cf-dev-plugin-$version-$platform"
+  [[ $goos == windows ]] && binary="$binary.exe"
+
+  (
+    set -a
+    source "$manifest"
+    set +a
+
+    # The darwin binaries read the host's memory through cgo, which go
+    # turns off when it cross compiles, e.g. arm64 on an amd64 mac.
+    cgo=0
+    [[ $goos == darwin ]] && cgo=1
+
+    CGO_ENABLED=$cgo GOOS=$goos GOARCH=$goarch go build \
+      -o "$binary" \
+      -ldflags \
+        "-X $pkg.cfdepsUrl=$cfdepsUrl
+         -X $pkg.cfdepsMd5=$cfdepsMd5
+         -X $pkg.cfdepsSize=$cfdepsSize
+
+         -X $pkg.cfdevdUrl=$cfdevdUrl
+         -X $pkg.cfdevdMd5=$cfdevdMd5
+         -X $pkg.cfdevdSize=$cfdevdSize
+
+         -X $pkg.analyticsdUrl=$analyticsdUrl
+         -X $pkg.analyticsdMd5=$analyticsdMd5
+         -X $pkg.analyticsdSize=$analyticsdSize
+
+         -X $pkg.cliVersion=$version
+         -X $pkg.buildVersion=$platform
+         -X $pkg.releaseManifestUrl=$CFDEV_RELEASE_MANIFEST
+         -X $pkg.analyticsKey=$analyticskey" \
+      code.cloudfoundry.org/cfdev
+  ) || return 1
+
+  sign "$goos" "$binary" >&2 || return 1
+  echo "$binary"
+}
+
+mkdir -p "$output_dir"
+binaries=()
+
+for manifest in "$assets_dir"/*.env; do
+  platform=$(basename "$manifest" .env)
+  if [[ " $supported " != *" $platform "* ]]; then
+    echo "Skipping $platform: cf dev has no hypervisor backend for it" >&2
+    continue
+  fi
+
+  binary=$(build "$platform" "$manifest")
+  binaries+=("$binary")
+done
+
+if [[ ${#binaries[@]} -eq 0 ]]; then
+  echo "No asset manifests for supported platforms ($supported) found in $assets_dir" >&2
+  exit 1
+fi
+
+{
+  echo "{"
+  echo "  \"version\": \"$version\","
+  echo "  \"binaries\": ["
+  for i in "${!binaries[@]}"; do
+    binary=${binaries[$i]}
+    name=$(basename "$binary")
+    platform=${name#cf-dev-plugin-$version-}
+    platform=${platform%.exe}
+    separator=","
+    [[ $i -eq $((${#binaries[@]} - 1)) ]] && separator=""
+    echo "    {\"platform\": \"$platform\", \"name\": \"$name\", \"sha1\": \"$(shasum -a 1 "$binary" | awk '{ print $1 }')\", \"sha256\": \"$(shasum -a 256 "$binary" | awk '{ print $1 }')\"}$separator"
+  done
+  echo "  ]"
+  echo "}"
+} > "$output_dir/manifest.json"
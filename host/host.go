@@ -1,5 +1,11 @@
 package host
 
+import "errors"
+
+// ErrDefenderExclusionsUnknown is returned when Windows Defender does not
+// show which paths it excludes, as it only shows them to administrators.
+var ErrDefenderExclusionsUnknown = errors.New("Windows Defender only shows its exclusions to administrators")
+
 //go:generate mockgen -package mocks -destination mocks/powershell.go code.cloudfoundry.org/cfdev/host Powershell
 type Powershell interface {
 	Output(command string) (string, error)
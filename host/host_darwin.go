@@ -22,3 +22,11 @@ func (h *Host) Version() (string, error) {
 
 	return fmt.Sprintf("%s %s", strings.TrimSpace(string(name)), strings.TrimSpace(string(version))), nil
 }
+
+func (*Host) MissingDefenderExclusions(paths []string) ([]string, error) {
+	return nil, nil
+}
+
+func (*Host) AddDefenderExclusions(paths []string) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package host
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+func (*Host) CheckRequirements() error {
+	return nil
+}
+
+// Version is the distribution's name and version from os-release.
+func (h *Host) Version() (string, error) {
+	file, err := os.Open("/etc/os-release")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value := strings.TrimPrefix(scanner.Text(), "PRETTY_NAME="); value != scanner.Text() {
+			return strings.Trim(value, `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "Linux", nil
+}
+
+func (*Host) MissingDefenderExclusions(paths []string) ([]string, error) {
+	return nil, nil
+}
+
+func (*Host) AddDefenderExclusions(paths []string) error {
+	return nil
+}
@@ -83,4 +83,31 @@ var _ = Describe("Host", func() {
 			})
 		})
 	})
+
+	Describe("defender exclusions", func() {
+		It("returns the paths that are not excluded", func() {
+			mockPowershell.EXPECT().Output(`(Get-MpPreference).ExclusionPath`).Return("C:\\Users\\me\\.cfdev\\cache\\\r\nC:\\Other\r\n", nil)
+
+			Expect(h.MissingDefenderExclusions([]string{
+				`c:\users\me\.cfdev\cache`,
+				`C:\Users\me\.cfdev\state`,
+			})).To(Equal([]string{`C:\Users\me\.cfdev\state`}))
+		})
+
+		It("does not know which paths are excluded outside an admin shell", func() {
+			mockPowershell.EXPECT().Output(`(Get-MpPreference).ExclusionPath`).Return("N/A: Must be an administrator to view exclusions\r\n", nil)
+
+			_, err := h.MissingDefenderExclusions([]string{`C:\Users\me\.cfdev\state`})
+			Expect(err).To(Equal(host.ErrDefenderExclusionsUnknown))
+		})
+
+		It("adds exclusions from an admin shell", func() {
+			gomock.InOrder(
+				mockPowershell.EXPECT().Output(adminQueryStr).Return("True", nil),
+				mockPowershell.EXPECT().Output(`Add-MpPreference -ExclusionPath "C:\a","C:\b"`),
+			)
+
+			Expect(h.AddDefenderExclusions([]string{`C:\a`, `C:\b`})).To(Succeed())
+		})
+	})
 })
@@ -0,0 +1,157 @@
+package hypervisor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/resource"
+)
+
+const (
+	bundleMetadataFile = "bundle.json"
+	bundleBoshDir      = "bosh"
+)
+
+// BundleMetadata describes the vm a bundle was exported from.
+type BundleMetadata struct {
+	Backend   string    `json:"backend"`
+	Disk      string    `json:"disk"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// writeBundle tars the vm's disk, which stageDisk puts into the staging dir
+// it is given, together with the bosh state needed to reach the director
+// deployed on it. The staging dir lives in CFDevHome so that the disk can
+// be hard linked rather than copied.
+func writeBundle(cfg config.Config, path string, metadata BundleMetadata, stageDisk func(dir string) error) error {
+	dir, err := ioutil.TempDir(cfg.CFDevHome, "export")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := stageDisk(dir); err != nil {
+		return fmt.Errorf("staging disk: %s", err)
+	}
+
+	if err := copyDir(cfg.StateBosh, filepath.Join(dir, bundleBoshDir)); err != nil {
+		return fmt.Errorf("staging bosh state: %s", err)
+	}
+
+	contents, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, bundleMetadataFile), contents, 0644); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return resource.Tar(dir, f)
+}
+
+// readBundle restores the disk and bosh state of a bundle exported by the
+// given backend.
+func readBundle(cfg config.Config, path string, backend string) error {
+	dir, err := ioutil.TempDir(cfg.CFDevHome, "import")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	err = resource.Untar(path, []resource.TarOpts{{Include: bundleMetadataFile, Dst: dir}})
+	if err != nil {
+		return fmt.Errorf("reading bundle: %s", err)
+	}
+
+	contents, err := ioutil.ReadFile(filepath.Join(dir, bundleMetadataFile))
+	if err != nil {
+		return fmt.Errorf("%s is not a cf dev bundle", path)
+	}
+
+	var metadata BundleMetadata
+	if err := json.Unmarshal(contents, &metadata); err != nil {
+		return fmt.Errorf("reading bundle metadata: %s", err)
+	}
+
+	if metadata.Backend != backend {
+		return fmt.Errorf("the bundle was exported from %s and cannot be imported into %s", metadata.Backend, backend)
+	}
+
+	return resource.Untar(path, []resource.TarOpts{
+		{
+			Include: metadata.Disk,
+			Dst:     cfg.StateLinuxkit,
+		},
+		{
+			IncludeFolder: bundleBoshDir,
+			FlattenFolder: true,
+			Dst:           cfg.StateBosh,
+			Mode:          0600,
+		},
+	})
+}
+
+// linkOrCopy hard links src to dst, copying it when the two are on
+// different file systems.
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	return copyFile(src, dst)
+}
+
+func copyDir(src, dst string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	files, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, file.Name()), filepath.Join(dst, file.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,95 @@
+package hypervisor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("bundles", func() {
+	var (
+		dir       string
+		bundle    string
+		exported  config.Config
+		imported  config.Config
+		stageDisk func(string) error
+	)
+
+	newConfig := func(home string) config.Config {
+		cfg := config.Config{
+			CFDevHome:     home,
+			StateBosh:     filepath.Join(home, "state", "bosh"),
+			StateLinuxkit: filepath.Join(home, "state", "linuxkit"),
+		}
+		Expect(os.MkdirAll(cfg.StateBosh, 0755)).To(Succeed())
+		Expect(os.MkdirAll(cfg.StateLinuxkit, 0755)).To(Succeed())
+		return cfg
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "cfdev-bundle")
+		Expect(err).NotTo(HaveOccurred())
+		bundle = filepath.Join(dir, "env.tgz")
+
+		exported = newConfig(filepath.Join(dir, "exported"))
+		imported = newConfig(filepath.Join(dir, "imported"))
+
+		Expect(ioutil.WriteFile(filepath.Join(exported.StateLinuxkit, "disk.qcow2"), []byte("some-disk"), 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(exported.StateBosh, "state.json"), []byte("some-state"), 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(exported.StateBosh, "creds.yml"), []byte("some-creds"), 0600)).To(Succeed())
+
+		stageDisk = func(stagingDir string) error {
+			return linkOrCopy(
+				filepath.Join(exported.StateLinuxkit, "disk.qcow2"),
+				filepath.Join(stagingDir, "disk.qcow2"),
+			)
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("restores the disk and bosh state", func() {
+		metadata := BundleMetadata{Backend: "hyperkit", Disk: "disk.qcow2"}
+		Expect(writeBundle(exported, bundle, metadata, stageDisk)).To(Succeed())
+
+		Expect(readBundle(imported, bundle, "hyperkit")).To(Succeed())
+
+		contents, err := ioutil.ReadFile(filepath.Join(imported.StateLinuxkit, "disk.qcow2"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal("some-disk"))
+
+		contents, err = ioutil.ReadFile(filepath.Join(imported.StateBosh, "state.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal("some-state"))
+
+		contents, err = ioutil.ReadFile(filepath.Join(imported.StateBosh, "creds.yml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal("some-creds"))
+	})
+
+	It("cleans up the staging dir", func() {
+		metadata := BundleMetadata{Backend: "hyperkit", Disk: "disk.qcow2"}
+		Expect(writeBundle(exported, bundle, metadata, stageDisk)).To(Succeed())
+
+		entries, err := ioutil.ReadDir(exported.CFDevHome)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Name()).To(Equal("state"))
+	})
+
+	Context("when the bundle was exported by another backend", func() {
+		It("returns an error", func() {
+			metadata := BundleMetadata{Backend: "hyperv", Disk: "disk.vhdx"}
+			Expect(writeBundle(exported, bundle, metadata, func(string) error { return nil })).To(Succeed())
+
+			Expect(readBundle(imported, bundle, "hyperkit")).To(MatchError("the bundle was exported from hyperv and cannot be imported into hyperkit"))
+		})
+	})
+})
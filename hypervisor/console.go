@@ -0,0 +1,48 @@
+package hypervisor
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/logfile"
+)
+
+const (
+	consoleLogMaxBytes = 10 * bytesInMegabyte
+	consoleLogBackups  = 3
+)
+
+// CaptureConsoleCommand is the argument the cf dev binary is run with to
+// capture a console in a process of its own, which outlives the command
+// that started the vm.
+const CaptureConsoleCommand = "capture-console"
+
+// CaptureConsole copies the console at source into a rotated log until the
+// console closes, i.e. the vm stops. Hyper-V only serves its COM port pipe
+// once the vm is running so opening it is retried briefly.
+func CaptureConsole(source string, logPath string) error {
+	var (
+		console *os.File
+		err     error
+	)
+	for i := 0; i < 30; i++ {
+		if console, err = os.OpenFile(source, os.O_RDONLY, 0); err == nil {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	if err != nil {
+		return err
+	}
+	defer console.Close()
+
+	w, err := logfile.Open(logPath, consoleLogMaxBytes, consoleLogBackups)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	_, err = io.Copy(w, console)
+	return err
+}
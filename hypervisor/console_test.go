@@ -0,0 +1,34 @@
+package hypervisor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CaptureConsole", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "cfdev-console")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("copies the console into the log until it closes", func() {
+		source := filepath.Join(dir, "com1")
+		Expect(ioutil.WriteFile(source, []byte("[    0.000000] Linux version 4.14\n"), 0644)).To(Succeed())
+
+		logPath := filepath.Join(dir, "console.log")
+		Expect(CaptureConsole(source, logPath)).To(Succeed())
+
+		Expect(ioutil.ReadFile(logPath)).To(Equal([]byte("[    0.000000] Linux version 4.14\n")))
+	})
+})
@@ -0,0 +1,11 @@
+package hypervisor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach keeps cmd running once its parent exits.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
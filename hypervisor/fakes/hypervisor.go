@@ -0,0 +1,235 @@
+// Package fakes provides an in-memory hypervisor for testing commands and
+// tooling that drive cf dev vms without Hyper-V or hyperkit.
+package fakes
+
+import (
+	"fmt"
+	"sync"
+
+	"code.cloudfoundry.org/cfdev/hypervisor"
+)
+
+// State is where a fake vm is in its lifecycle.
+type State string
+
+const (
+	Created State = "created"
+	Running State = "running"
+	Stopped State = "stopped"
+)
+
+type vm struct {
+	spec  hypervisor.VM
+	state State
+}
+
+// Hypervisor implements the methods of hypervisor.HyperV and
+// hypervisor.LinuxKit by tracking vms in memory. It follows the same rules
+// as the real hypervisors: vms must exist to be started, must be stopped to
+// be reconfigured or exported, and stopping or destroying a vm that does not
+// exist succeeds. The zero value is ready to use.
+type Hypervisor struct {
+	// Findings is what Preflight returns.
+	Findings []hypervisor.Finding
+
+	mu       sync.Mutex
+	vms      map[string]*vm
+	bundles  map[string]hypervisor.VM
+	failures map[string]error
+}
+
+// FailOn makes every later call to method, e.g. "Start", return err.
+// A nil err clears the failure.
+func (h *Hypervisor) FailOn(method string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.failures == nil {
+		h.failures = map[string]error{}
+	}
+	h.failures[method] = err
+}
+
+// VM returns the spec and state of the vm called vmName, and whether it
+// exists.
+func (h *Hypervisor) VM(vmName string) (hypervisor.VM, State, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	v, ok := h.vms[vmName]
+	if !ok {
+		return hypervisor.VM{}, "", false
+	}
+	return v.spec, v.state, true
+}
+
+func (h *Hypervisor) Preflight(vm hypervisor.VM) []hypervisor.Finding {
+	return h.Findings
+}
+
+func (h *Hypervisor) CreateVM(spec hypervisor.VM) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.failures["CreateVM"]; err != nil {
+		return err
+	}
+	if _, ok := h.vms[spec.Name]; ok {
+		return fmt.Errorf("vm with name %s already exists", spec.Name)
+	}
+
+	if h.vms == nil {
+		h.vms = map[string]*vm{}
+	}
+	h.vms[spec.Name] = &vm{spec: spec, state: Created}
+	return nil
+}
+
+func (h *Hypervisor) Start(vmName string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	v, err := h.find("Start", vmName)
+	if err != nil {
+		return err
+	}
+	v.state = Running
+	return nil
+}
+
+func (h *Hypervisor) Stop(vmName string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.failures["Stop"]; err != nil {
+		return err
+	}
+	if v, ok := h.vms[vmName]; ok && v.state == Running {
+		v.state = Stopped
+	}
+	return nil
+}
+
+func (h *Hypervisor) Destroy(vmName string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.failures["Destroy"]; err != nil {
+		return err
+	}
+	delete(h.vms, vmName)
+	return nil
+}
+
+func (h *Hypervisor) IsRunning(vmName string) (bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.failures["IsRunning"]; err != nil {
+		return false, err
+	}
+	v, ok := h.vms[vmName]
+	return ok && v.state == Running, nil
+}
+
+func (h *Hypervisor) Reconfigure(vmName string, cpus int, memoryMB int) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	v, err := h.findStopped("Reconfigure", vmName)
+	if err != nil {
+		return err
+	}
+	if cpus > 0 {
+		v.spec.CPUs = cpus
+	}
+	if memoryMB > 0 {
+		v.spec.MemoryMB = memoryMB
+	}
+	return nil
+}
+
+// Export records the vm's spec under path, from where Import restores it.
+// Nothing is written to disk.
+func (h *Hypervisor) Export(vmName string, path string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	v, err := h.findStopped("Export", vmName)
+	if err != nil {
+		return err
+	}
+
+	if h.bundles == nil {
+		h.bundles = map[string]hypervisor.VM{}
+	}
+	h.bundles[path] = v.spec
+	return nil
+}
+
+// Import recreates a vm exported to path, replacing any vm of the same name.
+func (h *Hypervisor) Import(path string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.failures["Import"]; err != nil {
+		return err
+	}
+	spec, ok := h.bundles[path]
+	if !ok {
+		return fmt.Errorf("no vm was exported to %s", path)
+	}
+
+	if h.vms == nil {
+		h.vms = map[string]*vm{}
+	}
+	h.vms[spec.Name] = &vm{spec: spec, state: Stopped}
+	return nil
+}
+
+// Stats reports an idle vm using all of its memory.
+func (h *Hypervisor) Stats(vmName string) (hypervisor.Stats, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	v, err := h.find("Stats", vmName)
+	if err != nil {
+		return hypervisor.Stats{}, err
+	}
+	if v.state != Running {
+		return hypervisor.Stats{}, fmt.Errorf("vm with name %s is not running", vmName)
+	}
+
+	return hypervisor.Stats{
+		MemoryDemandMB:   uint64(v.spec.MemoryMB),
+		MemoryAssignedMB: uint64(v.spec.MemoryMB),
+	}, nil
+}
+
+// ConsoleLogPath is empty, as fake vms have no console.
+func (h *Hypervisor) ConsoleLogPath(vmName string) string {
+	return ""
+}
+
+func (h *Hypervisor) find(method string, vmName string) (*vm, error) {
+	if err := h.failures[method]; err != nil {
+		return nil, err
+	}
+
+	v, ok := h.vms[vmName]
+	if !ok {
+		return nil, fmt.Errorf("vm with name %s does not exist", vmName)
+	}
+	return v, nil
+}
+
+func (h *Hypervisor) findStopped(method string, vmName string) (*vm, error) {
+	v, err := h.find(method, vmName)
+	if err != nil {
+		return nil, err
+	}
+	if v.state == Running {
+		return nil, fmt.Errorf("vm %s must be stopped", vmName)
+	}
+	return v, nil
+}
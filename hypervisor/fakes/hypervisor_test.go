@@ -0,0 +1,116 @@
+package fakes_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cfdev/cmd/bundle"
+	"code.cloudfoundry.org/cfdev/cmd/reconfigure"
+	"code.cloudfoundry.org/cfdev/cmd/start"
+	"code.cloudfoundry.org/cfdev/cmd/stop"
+	"code.cloudfoundry.org/cfdev/hypervisor"
+	"code.cloudfoundry.org/cfdev/hypervisor/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	_ start.Hypervisor       = &fakes.Hypervisor{}
+	_ stop.Hypervisor        = &fakes.Hypervisor{}
+	_ reconfigure.Hypervisor = &fakes.Hypervisor{}
+	_ bundle.Hypervisor      = &fakes.Hypervisor{}
+)
+
+var _ = Describe("Hypervisor", func() {
+	var subject *fakes.Hypervisor
+
+	BeforeEach(func() {
+		subject = &fakes.Hypervisor{}
+		Expect(subject.CreateVM(hypervisor.VM{Name: "cfdev", CPUs: 4, MemoryMB: 8192})).To(Succeed())
+	})
+
+	It("takes a vm from created to running to stopped", func() {
+		_, state, exists := subject.VM("cfdev")
+		Expect(exists).To(BeTrue())
+		Expect(state).To(Equal(fakes.Created))
+
+		Expect(subject.Start("cfdev")).To(Succeed())
+		Expect(subject.IsRunning("cfdev")).To(BeTrue())
+
+		Expect(subject.Stop("cfdev")).To(Succeed())
+		Expect(subject.IsRunning("cfdev")).To(BeFalse())
+		_, state, _ = subject.VM("cfdev")
+		Expect(state).To(Equal(fakes.Stopped))
+
+		Expect(subject.Destroy("cfdev")).To(Succeed())
+		_, _, exists = subject.VM("cfdev")
+		Expect(exists).To(BeFalse())
+	})
+
+	It("refuses to create a vm twice", func() {
+		Expect(subject.CreateVM(hypervisor.VM{Name: "cfdev"})).To(MatchError("vm with name cfdev already exists"))
+	})
+
+	It("refuses to start a vm that does not exist", func() {
+		Expect(subject.Start("other")).To(MatchError("vm with name other does not exist"))
+	})
+
+	It("stops and destroys vms that do not exist without error", func() {
+		Expect(subject.Stop("other")).To(Succeed())
+		Expect(subject.Destroy("other")).To(Succeed())
+	})
+
+	It("only reconfigures stopped vms", func() {
+		Expect(subject.Start("cfdev")).To(Succeed())
+		Expect(subject.Reconfigure("cfdev", 2, 4096)).To(MatchError("vm cfdev must be stopped"))
+
+		Expect(subject.Stop("cfdev")).To(Succeed())
+		Expect(subject.Reconfigure("cfdev", 2, 4096)).To(Succeed())
+
+		vm, _, _ := subject.VM("cfdev")
+		Expect(vm.CPUs).To(Equal(2))
+		Expect(vm.MemoryMB).To(Equal(4096))
+
+		Expect(subject.Reconfigure("cfdev", 0, 8192)).To(Succeed())
+		vm, _, _ = subject.VM("cfdev")
+		Expect(vm.CPUs).To(Equal(2))
+		Expect(vm.MemoryMB).To(Equal(8192))
+	})
+
+	It("imports what was exported", func() {
+		Expect(subject.Export("cfdev", "some-bundle")).To(Succeed())
+		Expect(subject.Destroy("cfdev")).To(Succeed())
+
+		Expect(subject.Import("some-bundle")).To(Succeed())
+		vm, state, exists := subject.VM("cfdev")
+		Expect(exists).To(BeTrue())
+		Expect(state).To(Equal(fakes.Stopped))
+		Expect(vm.MemoryMB).To(Equal(8192))
+
+		Expect(subject.Import("other-bundle")).To(MatchError("no vm was exported to other-bundle"))
+	})
+
+	It("only reports stats for running vms", func() {
+		_, err := subject.Stats("cfdev")
+		Expect(err).To(MatchError("vm with name cfdev is not running"))
+
+		Expect(subject.Start("cfdev")).To(Succeed())
+		stats, err := subject.Stats("cfdev")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.MemoryAssignedMB).To(BeEquivalentTo(8192))
+	})
+
+	It("returns the preflight findings it is given", func() {
+		subject.Findings = []hypervisor.Finding{{Check: "memory", Passed: false}}
+		Expect(subject.Preflight(hypervisor.VM{})).To(Equal(subject.Findings))
+	})
+
+	Context("when a method is set to fail", func() {
+		It("returns the error until cleared", func() {
+			subject.FailOn("Start", errors.New("some-error"))
+			Expect(subject.Start("cfdev")).To(MatchError("some-error"))
+
+			subject.FailOn("Start", nil)
+			Expect(subject.Start("cfdev")).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,31 @@
+package hypervisor
+
+import "code.cloudfoundry.org/cfdev/config"
+
+// Forward is a host address whose port is forwarded to the same port in
+// the vm.
+type Forward struct {
+	IP   string
+	Port int
+}
+
+// Forwards are the guest ports exposed on the host: ssh and the cpi on
+// localhost, and the director and router on their loopback aliases.
+func Forwards(cfg config.Config) []Forward {
+	hosts := []struct {
+		ip    string
+		ports []int
+	}{
+		{"127.0.0.1", []int{9992, 9999}},
+		{cfg.BoshDirectorIP, []int{22, 6868, 8443, 8844, 25555}},
+		{cfg.CFRouterIP, []int{80, 443, 2222}},
+	}
+
+	var forwards []Forward
+	for _, host := range hosts {
+		for _, port := range host.ports {
+			forwards = append(forwards, Forward{IP: host.ip, Port: port})
+		}
+	}
+	return forwards
+}
@@ -1,18 +1,91 @@
 package hypervisor
 
 import (
+	"code.cloudfoundry.org/cfdev/messages"
 	"code.cloudfoundry.org/cfdev/runner"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
 
 	"strings"
+	"time"
 
 	"code.cloudfoundry.org/cfdev/config"
 )
 
+func consolePipe(vmName string) string {
+	return `\\.\pipe\` + vmName + "-com"
+}
+
+//go:generate mockgen -package mocks -destination mocks/powershell.go code.cloudfoundry.org/cfdev/hypervisor Powershell
+type Powershell interface {
+	Output(command string) (string, error)
+}
+
 type HyperV struct {
 	Config     config.Config
-	Powershell runner.Powershell
+	Powershell Powershell
+	// DryRun writes each powershell command to DryRunOutput, or stdout,
+	// instead of running it, so what cf dev would do to the machine can be
+	// reviewed first. Queries are not run either; the vm is taken to exist
+	// and to be stopped.
+	DryRun       bool
+	DryRunOutput io.Writer
+	// Retries is how many more times a command that is safe to run again,
+	// e.g. a query or a Set-VM, is run after failing with a transient WMI
+	// error, waiting RetryDelay and doubling the wait each time. Commands
+	// that create or attach something are run once, as running them again
+	// after a partial failure would fail or duplicate it.
+	Retries    int
+	RetryDelay time.Duration
+}
+
+// DryRunTo has h write its commands to out instead of running them, for
+// cf dev start --dry-run.
+func (h *HyperV) DryRunTo(out io.Writer) {
+	h.DryRun = true
+	h.DryRunOutput = out
+}
+
+func (h *HyperV) run(command string) (string, error) {
+	if h.DryRun {
+		out := h.DryRunOutput
+		if out == nil {
+			out = os.Stdout
+		}
+		fmt.Fprintln(out, command)
+		return "", nil
+	}
+	return h.Powershell.Output(command)
+}
+
+// retry runs command, which must be idempotent, again while it fails with
+// a transient error.
+func (h *HyperV) retry(command string) (string, error) {
+	delay := h.RetryDelay
+	for attempt := 0; ; attempt++ {
+		output, err := h.run(command)
+		if err == nil || attempt >= h.Retries || !runner.IsTransient(err) {
+			return output, err
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// NewHyperV retries idempotent commands on transient WMI errors, and runs
+// powershell with a timeout generous enough for converting a full disk.
+func NewHyperV(cfg config.Config) *HyperV {
+	return &HyperV{
+		Config:     cfg,
+		Powershell: &runner.Powershell{Timeout: 30 * time.Minute},
+		Retries:    3,
+		RetryDelay: time.Second,
+	}
 }
 
 func (h *HyperV) CreateVM(vm VM) error {
@@ -20,7 +93,7 @@ func (h *HyperV) CreateVM(vm VM) error {
 	var cfDevVHD = filepath.Join(h.Config.StateLinuxkit, "disk.vhdx")
 
 	command := fmt.Sprintf("New-VM -Name %s -Generation 2 -NoVHD", vm.Name)
-	_, err := h.Powershell.Output(command)
+	_, err := h.run(command)
 	if err != nil {
 		return fmt.Errorf("creating new vm: %s", err)
 	}
@@ -30,60 +103,82 @@ func (h *HyperV) CreateVM(vm VM) error {
 		"-AutomaticStopAction ShutDown "+
 		"-CheckpointType Disabled "+
 		fmt.Sprintf("-MemoryStartupBytes %dMB ", vm.MemoryMB)+
-		"-StaticMemory "+
+		memoryArgs(vm)+
 		fmt.Sprintf("-ProcessorCount %d", vm.CPUs),
 		vm.Name)
-	_, err = h.Powershell.Output(command)
+	_, err = h.retry(command)
 	if err != nil {
 		return fmt.Errorf("setting vm properites (memoryMB:%d, cpus:%d): %s", vm.MemoryMB, vm.CPUs, err)
 	}
 
+	if vm.NestedVirtualization {
+		command = fmt.Sprintf("Set-VMProcessor -VMName %s -ExposeVirtualizationExtensions $true", vm.Name)
+		if _, err := h.retry(command); err != nil {
+			return fmt.Errorf("enabling nested virtualization: %s", err)
+		}
+	}
+
 	err = h.addVhdDrive(cfdevEfiIso, vm.Name)
 	if err != nil {
 		return fmt.Errorf("adding dvd drive %s: %s", cfdevEfiIso, err)
 	}
 
-	command = fmt.Sprintf("(Get-VMNetworkAdapter -VMName * | Where-Object -FilterScript {$_.VMName -eq '%s'}).Name", vm.Name)
-	output, err := h.Powershell.Output(command)
-	if err == nil {
-		if output != "" {
-			adapterNames := strings.Split(output, "\n")
-			for _, name := range adapterNames {
-				command = fmt.Sprintf("Remove-VMNetworkAdapter "+
-					"-VMName %s "+
-					"-Name '%s'",
-					vm.Name, strings.TrimSpace(name))
-				_, err = h.Powershell.Output(command)
-				if err != nil {
-					fmt.Printf("failed to remove netowork adapter: %s", err)
-				}
-			}
+	// The vm is networked over vpnkit, so the adapter New-VM gives it is
+	// only kept when it is to be leased a fixed address.
+	if vm.MACAddress != "" {
+		if err := h.setMACAddress(vm.Name, vm.MACAddress); err != nil {
+			return fmt.Errorf("setting mac address: %s", err)
 		}
+	} else {
+		h.removeNetworkAdapters(vm.Name)
+	}
+
+	err = h.createDifferencingDisk(cfDevVHD)
+	if err != nil {
+		return fmt.Errorf("creating differencing disk %s: %s", cfDevVHD, err)
 	}
 
 	command = fmt.Sprintf("Add-VMHardDiskDrive -VMName %s "+
 		`-Path "%s"`, vm.Name, cfDevVHD)
-	_, err = h.Powershell.Output(command)
+	_, err = h.run(command)
 	if err != nil {
 		return fmt.Errorf("adding vhd %s : %s", cfDevVHD, err)
 	}
 
+	for _, disk := range vm.DataDisks {
+		if err := h.addDataDisk(disk, vm.Name); err != nil {
+			return fmt.Errorf("adding data disk %s: %s", disk.Path, err)
+		}
+	}
+
 	command = fmt.Sprintf("Set-VMFirmware "+
 		"-VMName %s "+
-		"-EnableSecureBoot Off "+
+		secureBootArgs(vm)+
 		"-FirstBootDevice $cdrom",
 		vm.Name)
-	_, err = h.Powershell.Output(command)
+	_, err = h.retry(command)
 	if err != nil {
 		return fmt.Errorf("setting firmware : %s", err)
 	}
 
+	if vm.TPM {
+		if err := h.enableTPM(vm.Name); err != nil {
+			return fmt.Errorf("enabling tpm: %s", err)
+		}
+	}
+
+	if vm.GPU {
+		if err := h.addGPUPartition(vm.Name); err != nil {
+			return fmt.Errorf("adding gpu partition: %s", err)
+		}
+	}
+
 	command = fmt.Sprintf("Set-VMComPort "+
 		"-VMName %s "+
 		"-number 1 "+
-		"-Path \\\\.\\pipe\\cfdev-com",
-		vm.Name)
-	_, err = h.Powershell.Output(command)
+		"-Path %s",
+		vm.Name, consolePipe(vm.Name))
+	_, err = h.retry(command)
 	if err != nil {
 		return fmt.Errorf("setting com port : %s", err)
 	}
@@ -91,9 +186,127 @@ func (h *HyperV) CreateVM(vm VM) error {
 	return nil
 }
 
+// memoryArgs pins the vm to MemoryMB unless a range is given, in which case
+// Hyper-V balloons it between MinMemoryMB and MaxMemoryMB with demand.
+// Hyper-V will not start a vm with nested virtualization and dynamic memory.
+func memoryArgs(vm VM) string {
+	if vm.NestedVirtualization || vm.MinMemoryMB <= 0 || vm.MaxMemoryMB <= 0 {
+		return "-StaticMemory "
+	}
+
+	return "-DynamicMemory " +
+		fmt.Sprintf("-MemoryMinimumBytes %dMB ", vm.MinMemoryMB) +
+		fmt.Sprintf("-MemoryMaximumBytes %dMB ", vm.MaxMemoryMB)
+}
+
+// linuxSecureBootTemplate trusts boot loaders signed by the Microsoft UEFI
+// CA, as Linux distributions' shims are.
+const linuxSecureBootTemplate = "MicrosoftUEFICertificateAuthority"
+
+// secureBootArgs are set explicitly either way, as the default differs
+// between Windows builds.
+func secureBootArgs(vm VM) string {
+	if !vm.SecureBoot {
+		return "-EnableSecureBoot Off "
+	}
+
+	template := vm.SecureBootTemplate
+	if template == "" {
+		template = linuxSecureBootTemplate
+	}
+	return "-EnableSecureBoot On " +
+		fmt.Sprintf("-SecureBootTemplate %s ", template)
+}
+
+// enableTPM needs a key protector on the vm before the TPM can be turned on.
+func (h *HyperV) enableTPM(vmName string) error {
+	command := fmt.Sprintf("Set-VMKeyProtector -VMName %s -NewLocalKeyProtector", vmName)
+	if _, err := h.run(command); err != nil {
+		return err
+	}
+
+	command = fmt.Sprintf("Enable-VMTPM -VMName %s", vmName)
+	_, err := h.retry(command)
+	return err
+}
+
+// addGPUPartition maps a partition of the host's GPU into the vm. The
+// driver needs the guest to control caching and room to map the GPU's
+// memory, and Hyper-V refuses to save the state of such a vm, so it must be
+// turned off rather than saved when the host shuts down.
+func (h *HyperV) addGPUPartition(vmName string) error {
+	command := fmt.Sprintf("Add-VMGpuPartitionAdapter -VMName %s", vmName)
+	if _, err := h.run(command); err != nil {
+		return err
+	}
+
+	command = fmt.Sprintf("Set-VM -Name %s "+
+		"-AutomaticStopAction TurnOff "+
+		"-GuestControlledCacheTypes $true "+
+		"-LowMemoryMappedIoSpace 1GB "+
+		"-HighMemoryMappedIoSpace 32GB",
+		vmName)
+	_, err := h.retry(command)
+	return err
+}
+
+// createDifferencingDisk makes the vm's disk a child of the base disk in the
+// cache so that the vm only ever writes a delta. Throwing the delta away
+// resets the vm without copying the multi-GB base disk again.
+func (h *HyperV) createDifferencingDisk(path string) error {
+	var baseVHD = filepath.Join(h.Config.CacheDir, "disk.vhdx")
+
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if _, err := os.Stat(baseVHD); os.IsNotExist(err) {
+		return fmt.Errorf("base disk %s is missing", baseVHD)
+	}
+
+	command := fmt.Sprintf(`New-VHD -Path "%s" -ParentPath "%s" -Differencing`, path, baseVHD)
+	if _, err := h.run(command); err != nil {
+		return err
+	}
+
+	if h.Config.DiskSizeMB > 0 {
+		command = fmt.Sprintf(`Resize-VHD -Path "%s" -SizeBytes %dMB`, path, h.Config.DiskSizeMB)
+		if _, err := h.retry(command); err != nil {
+			return fmt.Errorf("resizing disk to %dMB: %s", h.Config.DiskSizeMB, err)
+		}
+	}
+	return nil
+}
+
+// Export bundles the vm's disk and bosh state. The differencing disk is
+// merged with its base so the bundle does not depend on the cache. The vm
+// must be stopped so that the disk is consistent.
+func (h *HyperV) Export(vmName string, path string) error {
+	metadata := BundleMetadata{
+		Backend:   "hyperv",
+		Disk:      "disk.vhdx",
+		CreatedAt: time.Now(),
+	}
+
+	return writeBundle(h.Config, path, metadata, func(dir string) error {
+		command := fmt.Sprintf(`Convert-VHD -Path "%s" -DestinationPath "%s" -VHDType Dynamic`,
+			filepath.Join(h.Config.StateLinuxkit, metadata.Disk),
+			filepath.Join(dir, metadata.Disk))
+		_, err := h.run(command)
+		return err
+	})
+}
+
+// Import restores a bundle made by Export in place of the vm's state. The
+// restored disk stands alone, so the next CreateVM uses it as is rather than
+// creating a differencing disk.
+func (h *HyperV) Import(path string) error {
+	return readBundle(h.Config, path, "hyperv")
+}
+
 func (h *HyperV) addVhdDrive(isoPath string, vmName string) error {
 	command := fmt.Sprintf(`Add-VMDvdDrive -VMName %s -Path "%s"`, vmName, isoPath)
-	_, err := h.Powershell.Output(command)
+	_, err := h.run(command)
 	if err != nil {
 		return err
 	}
@@ -101,9 +314,60 @@ func (h *HyperV) addVhdDrive(isoPath string, vmName string) error {
 	return nil
 }
 
+func (h *HyperV) removeNetworkAdapters(vmName string) {
+	command := fmt.Sprintf("(Get-VMNetworkAdapter -VMName * | Where-Object -FilterScript {$_.VMName -eq '%s'}).Name", vmName)
+	output, err := h.retry(command)
+	if err != nil || output == "" {
+		return
+	}
+
+	for _, name := range strings.Split(output, "\n") {
+		command = fmt.Sprintf("Remove-VMNetworkAdapter "+
+			"-VMName %s "+
+			"-Name '%s'",
+			vmName, strings.TrimSpace(name))
+		if _, err := h.run(command); err != nil {
+			fmt.Printf("failed to remove netowork adapter: %s", err)
+		}
+	}
+}
+
+// setMACAddress gives the adapter New-VM created a fixed MAC address, so
+// that a DHCP reservation for it keeps handing the vm the same address.
+func (h *HyperV) setMACAddress(vmName string, macAddress string) error {
+	mac, err := normalizeMACAddress(macAddress)
+	if err != nil {
+		return err
+	}
+
+	command := fmt.Sprintf("Set-VMNetworkAdapter "+
+		"-VMName %s "+
+		"-StaticMacAddress %s",
+		vmName, mac)
+	_, err = h.retry(command)
+	return err
+}
+
+func (h *HyperV) addDataDisk(disk Disk, vmName string) error {
+	if _, err := os.Stat(disk.Path); os.IsNotExist(err) {
+		command := fmt.Sprintf(`New-VHD -Path "%s" -SizeBytes %dMB -Dynamic`, disk.Path, disk.SizeMB)
+		if _, err := h.run(command); err != nil {
+			return err
+		}
+	}
+
+	command := fmt.Sprintf(`Add-VMHardDiskDrive -VMName %s -Path "%s"`, vmName, disk.Path)
+	_, err := h.run(command)
+	return err
+}
+
 func (h *HyperV) exists(vmName string) (bool, error) {
-	command := fmt.Sprintf("Get-VM -Name %s*", vmName)
-	output, err := h.Powershell.Output(command)
+	if h.DryRun {
+		return true, nil
+	}
+
+	command := fmt.Sprintf("Get-VM -Name '%s' -ErrorAction SilentlyContinue", vmName)
+	output, err := h.retry(command)
 	if err != nil {
 		return false, fmt.Errorf("getting vms: %s", err)
 	}
@@ -118,13 +382,42 @@ func (h *HyperV) Start(vmName string) error {
 	}
 
 	command := fmt.Sprintf("Start-VM -Name %s", vmName)
-	if _, err := h.Powershell.Output(command); err != nil {
+	if _, err := h.run(command); err != nil {
 		return fmt.Errorf("start-vm: %s", err)
 	}
 
+	if !h.DryRun {
+		h.captureConsole(vmName)
+	}
+
 	return nil
 }
 
+// ConsoleLogPath is where output from the vm's serial console is written,
+// from the moment the vm boots until it stops.
+func (h *HyperV) ConsoleLogPath(vmName string) string {
+	return filepath.Join(h.Config.LogDir, vmName+"-console.log")
+}
+
+// captureConsole copies the COM port pipe into the console log from a
+// detached cf dev process, so that capture carries on once start returns.
+// If that process cannot be started, capture falls back to this one.
+// Capture is best effort and must never fail the start.
+func (h *HyperV) captureConsole(vmName string) {
+	args := []string{CaptureConsoleCommand, consolePipe(vmName), h.ConsoleLogPath(vmName)}
+
+	if executable, err := os.Executable(); err == nil {
+		cmd := exec.Command(executable, args...)
+		detach(cmd)
+		if err := cmd.Start(); err == nil {
+			cmd.Process.Release()
+			return
+		}
+	}
+
+	go CaptureConsole(args[1], args[2])
+}
+
 func (h *HyperV) Stop(vmName string) error {
 	if exists, err := h.exists(vmName); err != nil {
 		return err
@@ -133,13 +426,40 @@ func (h *HyperV) Stop(vmName string) error {
 	}
 
 	command := fmt.Sprintf("Stop-VM -Name %s -Turnoff", vmName)
-	if _, err := h.Powershell.Output(command); err != nil {
+	if _, err := h.retry(command); err != nil {
 		return fmt.Errorf("stopping vm: %s", err)
 	}
 
 	return nil
 }
 
+// Reconfigure changes the resources of an existing vm, leaving those that
+// are zero. Hyper-V only lets the processor count change while the vm is
+// off, so it must be stopped.
+func (h *HyperV) Reconfigure(vmName string, cpus int, memoryMB int) error {
+	if running, err := h.IsRunning(vmName); err != nil {
+		return err
+	} else if running {
+		return fmt.Errorf("vm %s must be stopped to be reconfigured", vmName)
+	}
+	if cpus <= 0 && memoryMB <= 0 {
+		return nil
+	}
+
+	command := fmt.Sprintf("Set-VM -Name %s", vmName)
+	if memoryMB > 0 {
+		command += fmt.Sprintf(" -MemoryStartupBytes %dMB", memoryMB)
+	}
+	if cpus > 0 {
+		command += fmt.Sprintf(" -ProcessorCount %d", cpus)
+	}
+	if _, err := h.retry(command); err != nil {
+		return fmt.Errorf("setting vm properites (memoryMB:%d, cpus:%d): %s", memoryMB, cpus, err)
+	}
+
+	return nil
+}
+
 func (h *HyperV) Destroy(vmName string) error {
 	if exists, err := h.exists(vmName); err != nil {
 		return err
@@ -148,7 +468,7 @@ func (h *HyperV) Destroy(vmName string) error {
 	}
 
 	command := fmt.Sprintf("Remove-VM -Name %s -Force", vmName)
-	if _, err := h.Powershell.Output(command); err != nil {
+	if _, err := h.run(command); err != nil {
 		return fmt.Errorf("removing vm: %s", err)
 	}
 
@@ -161,7 +481,7 @@ func (h *HyperV) IsRunning(vmName string) (bool, error) {
 	}
 
 	command := fmt.Sprintf("Get-VM -Name %s | format-list -Property State", vmName)
-	output, err := h.Powershell.Output(command)
+	output, err := h.retry(command)
 	if err != nil {
 		return false, err
 	}
@@ -172,3 +492,122 @@ func (h *HyperV) IsRunning(vmName string) (bool, error) {
 
 	return false, nil
 }
+
+func (h *HyperV) Stats(vmName string) (Stats, error) {
+	if running, err := h.IsRunning(vmName); err != nil {
+		return Stats{}, err
+	} else if !running {
+		return Stats{}, fmt.Errorf("hyperv vm with name %s is not running", vmName)
+	}
+
+	command := fmt.Sprintf("Get-VM -Name %s | format-list -Property CPUUsage,MemoryDemand,MemoryAssigned", vmName)
+	output, err := h.retry(command)
+	if err != nil {
+		return Stats{}, fmt.Errorf("getting vm usage: %s", err)
+	}
+	usage := parseFormatList(output)
+
+	// Metering is off by default and enabling it again is a no-op
+	command = fmt.Sprintf("Enable-VMResourceMetering -VMName %s", vmName)
+	if _, err := h.retry(command); err != nil {
+		return Stats{}, fmt.Errorf("enabling resource metering: %s", err)
+	}
+
+	command = fmt.Sprintf("Measure-VM -VMName %s | format-list -Property AggregatedDiskDataRead,AggregatedDiskDataWritten", vmName)
+	output, err = h.retry(command)
+	if err != nil {
+		return Stats{}, fmt.Errorf("measuring vm: %s", err)
+	}
+	metering := parseFormatList(output)
+
+	cpu, _ := strconv.ParseFloat(usage["CPUUsage"], 64)
+	demand, _ := strconv.ParseUint(usage["MemoryDemand"], 10, 64)
+	assigned, _ := strconv.ParseUint(usage["MemoryAssigned"], 10, 64)
+	// Measure-VM reports aggregated disk data in megabytes
+	read, _ := strconv.ParseUint(metering["AggregatedDiskDataRead"], 10, 64)
+	written, _ := strconv.ParseUint(metering["AggregatedDiskDataWritten"], 10, 64)
+
+	return Stats{
+		CPUPercent:       cpu,
+		MemoryDemandMB:   demand / bytesInMegabyte,
+		MemoryAssignedMB: assigned / bytesInMegabyte,
+		DiskReadBytes:    read * bytesInMegabyte,
+		DiskWriteBytes:   written * bytesInMegabyte,
+	}, nil
+}
+
+func (h *HyperV) Preflight(vm VM) []Finding {
+	findings := []Finding{
+		h.hypervFeatureFinding(),
+		h.hypervisorLaunchTypeFinding(),
+	}
+	findings = append(findings, resourceFindings(vm, h.Config.CFDevHome)...)
+	if vm.GPU {
+		findings = append(findings, h.partitionableGPUFinding())
+	}
+	return append(findings, h.conflictingDriversFinding())
+}
+
+func (h *HyperV) hypervFeatureFinding() Finding {
+	output, err := h.retry("(Get-WindowsOptionalFeature -FeatureName Microsoft-Hyper-V -Online).State")
+	return Finding{
+		Check:   "hyperv-feature",
+		Passed:  err == nil && strings.Contains(strings.ToLower(output), "enabled"),
+		Fatal:   true,
+		Message: messages.T("preflight.hyperv-feature"),
+	}
+}
+
+// The Hyper-V feature can be enabled while the hypervisor itself is kept
+// from loading at boot, which is a common way of making room for VirtualBox.
+func (h *HyperV) hypervisorLaunchTypeFinding() Finding {
+	output, err := h.retry(`bcdedit /enum "{current}"`)
+	passed := true
+	if err == nil {
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && strings.ToLower(fields[0]) == "hypervisorlaunchtype" {
+				passed = strings.ToLower(fields[1]) != "off"
+			}
+		}
+	}
+
+	return Finding{
+		Check:   "hypervisor-launch-type",
+		Passed:  passed,
+		Fatal:   true,
+		Message: messages.T("preflight.hypervisor-launch-type"),
+	}
+}
+
+func (h *HyperV) partitionableGPUFinding() Finding {
+	output, err := h.retry("(Get-VMHostPartitionableGpu).Name")
+	return Finding{
+		Check:   "partitionable-gpu",
+		Passed:  err == nil && strings.TrimSpace(output) != "",
+		Fatal:   true,
+		Message: messages.T("preflight.partitionable-gpu"),
+	}
+}
+
+func (h *HyperV) conflictingDriversFinding() Finding {
+	command := "Get-Service -Name VBoxDrv,vmx86 -ErrorAction SilentlyContinue | " +
+		"Where-Object {$_.Status -eq 'Running'} | " +
+		"ForEach-Object {$_.Name}"
+	output, err := h.retry(command)
+
+	var drivers []string
+	if err == nil {
+		for _, name := range strings.Split(output, "\n") {
+			if name = strings.TrimSpace(name); name != "" {
+				drivers = append(drivers, name)
+			}
+		}
+	}
+
+	return Finding{
+		Check:   "conflicting-drivers",
+		Passed:  len(drivers) == 0,
+		Message: messages.T("preflight.conflicting-drivers", map[string]interface{}{"Drivers": strings.Join(drivers, ", ")}),
+	}
+}
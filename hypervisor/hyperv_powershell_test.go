@@ -0,0 +1,226 @@
+package hypervisor_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/hypervisor"
+	"code.cloudfoundry.org/cfdev/hypervisor/mocks"
+	"code.cloudfoundry.org/cfdev/runner"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("HyperV with a fake powershell", func() {
+	var (
+		mockController *gomock.Controller
+		mockPowershell *mocks.MockPowershell
+		hyperV         *hypervisor.HyperV
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockPowershell = mocks.NewMockPowershell(mockController)
+		hyperV = &hypervisor.HyperV{
+			Config:     config.Config{},
+			Powershell: mockPowershell,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	Describe("IsRunning", func() {
+		It("is false when the vm does not exist", func() {
+			mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue").Return("", nil)
+
+			Expect(hyperV.IsRunning("cfdev")).To(BeFalse())
+		})
+
+		It("is true when the vm is running", func() {
+			gomock.InOrder(
+				mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue").Return("cfdev", nil),
+				mockPowershell.EXPECT().Output("Get-VM -Name cfdev | format-list -Property State").Return("State : Running", nil),
+			)
+
+			Expect(hyperV.IsRunning("cfdev")).To(BeTrue())
+		})
+
+		It("does not take a named instance's vm for the default one", func() {
+			gomock.InOrder(
+				mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue").Return("", nil),
+				mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev-foo' -ErrorAction SilentlyContinue").Return("cfdev-foo", nil),
+				mockPowershell.EXPECT().Output("Get-VM -Name cfdev-foo | format-list -Property State").Return("State : Running", nil),
+				mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue").Return("", nil).Times(2),
+			)
+
+			Expect(hyperV.IsRunning("cfdev")).To(BeFalse())
+			Expect(hyperV.IsRunning("cfdev-foo")).To(BeTrue())
+			Expect(hyperV.Stop("cfdev")).To(Succeed())
+			Expect(hyperV.Destroy("cfdev")).To(Succeed())
+		})
+	})
+
+	Describe("Reconfigure", func() {
+		It("sets the new resources on the stopped vm", func() {
+			gomock.InOrder(
+				mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue").Return("cfdev", nil),
+				mockPowershell.EXPECT().Output("Get-VM -Name cfdev | format-list -Property State").Return("State : Off", nil),
+				mockPowershell.EXPECT().Output("Set-VM -Name cfdev -MemoryStartupBytes 8192MB -ProcessorCount 6"),
+			)
+
+			Expect(hyperV.Reconfigure("cfdev", 6, 8192)).To(Succeed())
+		})
+
+		It("leaves the resources that are zero", func() {
+			gomock.InOrder(
+				mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue").Return("cfdev", nil),
+				mockPowershell.EXPECT().Output("Get-VM -Name cfdev | format-list -Property State").Return("State : Off", nil),
+				mockPowershell.EXPECT().Output("Set-VM -Name cfdev -ProcessorCount 6"),
+			)
+
+			Expect(hyperV.Reconfigure("cfdev", 6, 0)).To(Succeed())
+		})
+	})
+
+	Describe("Stop", func() {
+		It("returns the powershell error", func() {
+			gomock.InOrder(
+				mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue").Return("cfdev", nil),
+				mockPowershell.EXPECT().Output("Stop-VM -Name cfdev -Turnoff").Return("", errors.New("some-error")),
+			)
+
+			Expect(hyperV.Stop("cfdev")).To(MatchError("stopping vm: some-error"))
+		})
+	})
+
+	Describe("retries", func() {
+		transient := &runner.Error{Command: "Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue", Stderr: "Get-VM : The RPC server is unavailable.", ExitCode: 1}
+
+		BeforeEach(func() {
+			hyperV.Retries = 2
+			hyperV.RetryDelay = time.Millisecond
+		})
+
+		It("runs a query again when it fails with a transient error", func() {
+			gomock.InOrder(
+				mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue").Return("", transient),
+				mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue").Return("", nil),
+			)
+
+			Expect(hyperV.IsRunning("cfdev")).To(BeFalse())
+		})
+
+		It("gives up after the retries", func() {
+			mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue").Return("", transient).Times(3)
+
+			_, err := hyperV.IsRunning("cfdev")
+			Expect(err).To(MatchError(ContainSubstring("The RPC server is unavailable")))
+		})
+
+		It("runs a command that is not idempotent only once", func() {
+			gomock.InOrder(
+				mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue").Return("cfdev", nil),
+				mockPowershell.EXPECT().Output("Start-VM -Name cfdev").Return("", &runner.Error{Command: "Start-VM -Name cfdev", Stderr: "Generic failure", ExitCode: 1}),
+			)
+
+			Expect(hyperV.Start("cfdev")).To(MatchError(ContainSubstring("Generic failure")))
+		})
+
+		It("looks at what the command wrote to stderr, not at the command", func() {
+			mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue").Return("", &runner.Error{Command: "Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue # Generic failure", Stderr: "Access denied", ExitCode: 1})
+
+			_, err := hyperV.IsRunning("cfdev")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("does not retry errors that are not from powershell", func() {
+			mockPowershell.EXPECT().Output("Get-VM -Name 'cfdev' -ErrorAction SilentlyContinue").Return("", errors.New("Generic failure"))
+
+			_, err := hyperV.IsRunning("cfdev")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("HyperV in dry run mode", func() {
+	var (
+		mockController *gomock.Controller
+		mockPowershell *mocks.MockPowershell
+		output         *gbytes.Buffer
+		hyperV         *hypervisor.HyperV
+		cacheDir       string
+		stateDir       string
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockPowershell = mocks.NewMockPowershell(mockController)
+		output = gbytes.NewBuffer()
+
+		var err error
+		cacheDir, err = ioutil.TempDir("", "hyperv-dry-run")
+		Expect(err).NotTo(HaveOccurred())
+		stateDir = filepath.Join(cacheDir, "linuxkit")
+		Expect(ioutil.WriteFile(filepath.Join(cacheDir, "disk.vhdx"), nil, 0644)).To(Succeed())
+
+		hyperV = &hypervisor.HyperV{
+			Config: config.Config{
+				CacheDir:      cacheDir,
+				StateLinuxkit: stateDir,
+			},
+			Powershell:   mockPowershell,
+			DryRun:       true,
+			DryRunOutput: output,
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+		os.RemoveAll(cacheDir)
+	})
+
+	It("prints the commands that would create the vm without running them", func() {
+		Expect(hyperV.CreateVM(hypervisor.VM{Name: "cfdev", CPUs: 4, MemoryMB: 4096})).To(Succeed())
+
+		Expect(output).To(gbytes.Say("New-VM -Name cfdev -Generation 2 -NoVHD"))
+		Expect(output).To(gbytes.Say("Set-VM -Name cfdev .* -MemoryStartupBytes 4096MB -StaticMemory -ProcessorCount 4"))
+		Expect(output).To(gbytes.Say(`New-VHD -Path ".*disk.vhdx" -ParentPath ".*disk.vhdx" -Differencing`))
+		Expect(output).To(gbytes.Say("Set-VMFirmware -VMName cfdev -EnableSecureBoot Off"))
+		Expect(filepath.Join(stateDir, "disk.vhdx")).NotTo(BeAnExistingFile())
+	})
+
+	It("prints the commands that would give the vm a gpu partition", func() {
+		Expect(hyperV.CreateVM(hypervisor.VM{Name: "cfdev", CPUs: 4, MemoryMB: 4096, GPU: true})).To(Succeed())
+
+		Expect(output).To(gbytes.Say("Add-VMGpuPartitionAdapter -VMName cfdev"))
+		Expect(output).To(gbytes.Say("Set-VM -Name cfdev -AutomaticStopAction TurnOff -GuestControlledCacheTypes \\$true"))
+	})
+
+	It("prints the commands that would pin the vm's MAC address", func() {
+		Expect(hyperV.CreateVM(hypervisor.VM{Name: "cfdev", CPUs: 4, MemoryMB: 4096, MACAddress: "00:15:5d:01:02:03"})).To(Succeed())
+
+		Expect(output).To(gbytes.Say("Set-VMNetworkAdapter -VMName cfdev -StaticMacAddress 00155D010203"))
+		Expect(output).NotTo(gbytes.Say("Remove-VMNetworkAdapter"))
+	})
+
+	It("fails on a MAC address that does not parse", func() {
+		err := hyperV.CreateVM(hypervisor.VM{Name: "cfdev", CPUs: 4, MemoryMB: 4096, MACAddress: "00:15:5d:01:02"})
+		Expect(err).To(MatchError("setting mac address: invalid MAC address: 00:15:5d:01:02"))
+	})
+
+	It("prints the commands that would start and destroy the vm", func() {
+		Expect(hyperV.Start("cfdev")).To(Succeed())
+		Expect(hyperV.Destroy("cfdev")).To(Succeed())
+
+		Expect(output).To(gbytes.Say("Start-VM -Name cfdev"))
+		Expect(output).To(gbytes.Say("Remove-VM -Name cfdev -Force"))
+	})
+})
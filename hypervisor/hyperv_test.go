@@ -11,6 +11,7 @@ import (
 
 	"code.cloudfoundry.org/cfdev/config"
 	"code.cloudfoundry.org/cfdev/hypervisor"
+	"code.cloudfoundry.org/cfdev/runner"
 	"fmt"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -36,6 +37,7 @@ var _ = Describe("HyperV", func() {
 		Expect(err).NotTo(HaveOccurred())
 
 		hyperV = hypervisor.HyperV{
+			Powershell: &runner.Powershell{},
 			Config: config.Config{
 				CFDevHome:     cfdevHome,
 				CacheDir:      filepath.Join(cfdevHome, "cache"),
@@ -55,7 +57,7 @@ var _ = Describe("HyperV", func() {
 
 		copyFile(
 			filepath.Join(assetDir, "disk.vhdx"),
-			filepath.Join(hyperV.Config.StateLinuxkit, "disk.vhdx"),
+			filepath.Join(hyperV.Config.CacheDir, "disk.vhdx"),
 		)
 	})
 
@@ -91,6 +93,122 @@ var _ = Describe("HyperV", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(string(output)).ToNot(BeEmpty())
 		})
+
+		It("boots from a differencing disk on top of the cached base disk", func() {
+			vm := hypervisor.VM{
+				Name:     vmName,
+				MemoryMB: 2000,
+				CPUs:     1,
+			}
+			Expect(hyperV.CreateVM(vm)).To(Succeed())
+
+			childVHD := filepath.Join(hyperV.Config.StateLinuxkit, "disk.vhdx")
+			cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf(`Get-VHD -Path "%s" | format-list -Property VhdType`, childVHD))
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session, 10, 1).Should(gexec.Exit(0))
+			Expect(session).To(gbytes.Say("VhdType : Differencing"))
+		})
+
+		It("enables dynamic memory when a range is given", func() {
+			vm := hypervisor.VM{
+				Name:        vmName,
+				MemoryMB:    2000,
+				MinMemoryMB: 1000,
+				MaxMemoryMB: 3000,
+				CPUs:        1,
+			}
+			Expect(hyperV.CreateVM(vm)).To(Succeed())
+
+			cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf("Get-VM -Name %s | format-list -Property DynamicMemoryEnabled,MemoryMinimum,MemoryMaximum", vmName))
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session, 10, 1).Should(gexec.Exit(0))
+			Expect(session).To(gbytes.Say("DynamicMemoryEnabled : True"))
+			Expect(session).To(gbytes.Say("MemoryMinimum        : 1048576000"))
+			Expect(session).To(gbytes.Say("MemoryMaximum        : 3145728000"))
+		})
+
+		It("exposes virtualization extensions when nested virtualization is requested", func() {
+			vm := hypervisor.VM{
+				Name:                 vmName,
+				MemoryMB:             2000,
+				CPUs:                 1,
+				NestedVirtualization: true,
+			}
+			Expect(hyperV.CreateVM(vm)).To(Succeed())
+
+			cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf("Get-VMProcessor -VMName %s | format-list -Property ExposeVirtualizationExtensions", vmName))
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session, 10, 1).Should(gexec.Exit(0))
+			Expect(session).To(gbytes.Say("ExposeVirtualizationExtensions : True"))
+		})
+
+		It("gives the network adapter the given MAC address", func() {
+			vm := hypervisor.VM{
+				Name:       vmName,
+				MemoryMB:   2000,
+				CPUs:       1,
+				MACAddress: "00:15:5d:01:02:03",
+			}
+			Expect(hyperV.CreateVM(vm)).To(Succeed())
+
+			cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf("Get-VMNetworkAdapter -VMName %s | format-list -Property MacAddress,DynamicMacAddressEnabled", vmName))
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session, 10, 1).Should(gexec.Exit(0))
+			Expect(session).To(gbytes.Say("MacAddress               : 00155D010203"))
+			Expect(session).To(gbytes.Say("DynamicMacAddressEnabled : False"))
+		})
+
+		It("disables secure boot by default", func() {
+			vm := hypervisor.VM{
+				Name:     vmName,
+				MemoryMB: 2000,
+				CPUs:     1,
+			}
+			Expect(hyperV.CreateVM(vm)).To(Succeed())
+
+			cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf("Get-VMFirmware -VMName %s | format-list -Property SecureBoot", vmName))
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session, 10, 1).Should(gexec.Exit(0))
+			Expect(session).To(gbytes.Say("SecureBoot : Off"))
+		})
+
+		It("enables secure boot with the linux template when asked", func() {
+			vm := hypervisor.VM{
+				Name:       vmName,
+				MemoryMB:   2000,
+				CPUs:       1,
+				SecureBoot: true,
+			}
+			Expect(hyperV.CreateVM(vm)).To(Succeed())
+
+			cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf("Get-VMFirmware -VMName %s | format-list -Property SecureBoot,SecureBootTemplate", vmName))
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session, 10, 1).Should(gexec.Exit(0))
+			Expect(session).To(gbytes.Say("SecureBoot         : On"))
+			Expect(session).To(gbytes.Say("SecureBootTemplate : MicrosoftUEFICertificateAuthority"))
+		})
+
+		It("adds a virtual tpm when asked", func() {
+			vm := hypervisor.VM{
+				Name:     vmName,
+				MemoryMB: 2000,
+				CPUs:     1,
+				TPM:      true,
+			}
+			Expect(hyperV.CreateVM(vm)).To(Succeed())
+
+			cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf("Get-VMSecurity -VMName %s | format-list -Property TpmEnabled", vmName))
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session, 10, 1).Should(gexec.Exit(0))
+			Expect(session).To(gbytes.Say("TpmEnabled : True"))
+		})
 	})
 
 	Describe("Start", func() {
@@ -144,6 +262,27 @@ var _ = Describe("HyperV", func() {
 	})
 
 	Describe("Stop", func() {
+		Context("when only a vm whose name it starts is there", func() {
+			BeforeEach(func() {
+				cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf("New-VM -Name %s-foo -Generation 2 -NoVHD", vmName))
+				session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session, 10, 1).Should(gexec.Exit())
+			})
+			AfterEach(func() {
+				cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf("Remove-VM -Name %s-foo -Force", vmName))
+				session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session, 10, 1).Should(gexec.Exit())
+			})
+
+			It("returns false, and stopping and destroying the vm succeed", func() {
+				Expect(hyperV.IsRunning(vmName)).To(BeFalse())
+				Expect(hyperV.Stop(vmName)).To(Succeed())
+				Expect(hyperV.Destroy(vmName)).To(Succeed())
+				Expect(hyperV.IsRunning(vmName + "-foo")).To(BeFalse())
+			})
+		})
 		Context("when the vm exists", func() {
 			BeforeEach(func() {
 				cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf("New-VM -Name %s -Generation 2 -NoVHD", vmName))
@@ -200,6 +339,46 @@ var _ = Describe("HyperV", func() {
 		})
 	})
 
+	Describe("Reconfigure", func() {
+		BeforeEach(func() {
+			cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf("New-VM -Name %s -Generation 2 -NoVHD", vmName))
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session, 10, 1).Should(gexec.Exit())
+		})
+
+		AfterEach(func() {
+			cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf("Remove-VM -Name %s -Force", vmName))
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session, 10, 1).Should(gexec.Exit())
+		})
+
+		It("applies the new resources", func() {
+			Expect(hyperV.Reconfigure(vmName, 2, 3000)).To(Succeed())
+
+			cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf("Get-VM -Name %s | format-list -Property MemoryStartup,ProcessorCount", vmName))
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session, 10, 1).Should(gexec.Exit(0))
+			Expect(session).To(gbytes.Say("MemoryStartup  : 3145728000"))
+			Expect(session).To(gbytes.Say("ProcessorCount : 2"))
+		})
+
+		Context("when the vm is running", func() {
+			BeforeEach(func() {
+				cmd := exec.Command("powershell.exe", "-Command", fmt.Sprintf("Start-VM -Name %s", vmName))
+				session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session, 10, 1).Should(gexec.Exit())
+			})
+
+			It("errors", func() {
+				Expect(hyperV.Reconfigure(vmName, 2, 3000)).To(MatchError(ContainSubstring("must be stopped")))
+			})
+		})
+	})
+
 	Describe("Destroy", func() {
 		Context("when the vm exists and is stopped ", func() {
 			BeforeEach(func() {
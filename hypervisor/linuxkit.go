@@ -10,6 +10,7 @@ import (
 
 	"code.cloudfoundry.org/cfdev/config"
 	"code.cloudfoundry.org/cfdev/daemon"
+	"code.cloudfoundry.org/cfdev/messages"
 )
 
 type UI interface {
@@ -32,21 +33,28 @@ type DaemonRunner interface {
 
 const LinuxKitLabel = "org.cloudfoundry.cfdev.linuxkit"
 
+func (l *LinuxKit) label() string {
+	return l.Config.DaemonLabel(LinuxKitLabel)
+}
+
 func (l *LinuxKit) CreateVM(vm VM) error {
-	daemonSpec, err := l.DaemonSpec(vm.CPUs, vm.MemoryMB)
+	daemonSpec, err := l.DaemonSpec(vm.CPUs, vm.MemoryMB, vm.DataDisks...)
 	if err != nil {
 		return err
 	}
-	return l.DaemonRunner.AddDaemon(daemonSpec)
+	if err := l.DaemonRunner.AddDaemon(daemonSpec); err != nil {
+		return err
+	}
+	return saveVM(l.Config.StateLinuxkit, vm)
 }
 
 func (l *LinuxKit) Start(vmName string) error {
-	return l.DaemonRunner.Start(LinuxKitLabel)
+	return l.DaemonRunner.Start(l.label())
 }
 
 func (l *LinuxKit) Stop(vmName string) error {
 	var reterr error
-	if err := l.DaemonRunner.Stop(LinuxKitLabel); err != nil {
+	if err := l.DaemonRunner.Stop(l.label()); err != nil {
 		reterr = err
 	}
 	if err := SafeKill(
@@ -59,14 +67,69 @@ func (l *LinuxKit) Stop(vmName string) error {
 }
 
 func (l *LinuxKit) Destroy(vmName string) error {
-	return l.DaemonRunner.RemoveDaemon(LinuxKitLabel)
+	return l.DaemonRunner.RemoveDaemon(l.label())
+}
+
+// Reconfigure replaces the linuxkit daemon with one using the new
+// resources, keeping the data disks and the resources that are zero.
+// hyperkit reads them at boot, so the vm must be stopped.
+func (l *LinuxKit) Reconfigure(vmName string, cpus int, memoryMB int) error {
+	if running, err := l.IsRunning(vmName); err != nil {
+		return err
+	} else if running {
+		return fmt.Errorf("vm %s must be stopped to be reconfigured", vmName)
+	}
+
+	vm, err := resizedVM(l.Config.StateLinuxkit, vmName, cpus, memoryMB)
+	if err != nil {
+		return err
+	}
+	daemonSpec, err := l.DaemonSpec(vm.CPUs, vm.MemoryMB, vm.DataDisks...)
+	if err != nil {
+		return err
+	}
+	if err := l.DaemonRunner.RemoveDaemon(l.label()); err != nil {
+		return err
+	}
+	if err := l.DaemonRunner.AddDaemon(daemonSpec); err != nil {
+		return err
+	}
+	return saveVM(l.Config.StateLinuxkit, vm)
 }
 
 func (l *LinuxKit) IsRunning(vmName string) (bool, error) {
-	return l.DaemonRunner.IsRunning(LinuxKitLabel)
+	return l.DaemonRunner.IsRunning(l.label())
+}
+
+// ConsoleLogPath is the ring buffer hyperkit keeps of the vm's serial
+// console. It is bounded by hyperkit itself so needs no rotation.
+func (l *LinuxKit) ConsoleLogPath(vmName string) string {
+	return filepath.Join(l.Config.StateLinuxkit, "console-ring")
 }
 
-func (l *LinuxKit) DaemonSpec(cpus, mem int) (daemon.DaemonSpec, error) {
+// Export bundles the vm's disk and bosh state. The vm must be stopped so
+// that the disk is consistent.
+func (l *LinuxKit) Export(vmName string, path string) error {
+	metadata := BundleMetadata{
+		Backend:   "hyperkit",
+		Disk:      "disk.qcow2",
+		CreatedAt: time.Now(),
+	}
+
+	return writeBundle(l.Config, path, metadata, func(dir string) error {
+		return linkOrCopy(
+			filepath.Join(l.Config.StateLinuxkit, metadata.Disk),
+			filepath.Join(dir, metadata.Disk),
+		)
+	})
+}
+
+// Import restores a bundle made by Export in place of the vm's state.
+func (l *LinuxKit) Import(path string) error {
+	return readBundle(l.Config, path, "hyperkit")
+}
+
+func (l *LinuxKit) DaemonSpec(cpus, mem int, dataDisks ...Disk) (daemon.DaemonSpec, error) {
 	linuxkit := filepath.Join(l.Config.CacheDir, "linuxkit")
 	hyperkit := filepath.Join(l.Config.CacheDir, "hyperkit")
 	uefi := filepath.Join(l.Config.CacheDir, "UEFI.fd")
@@ -78,7 +141,7 @@ func (l *LinuxKit) DaemonSpec(cpus, mem int) (daemon.DaemonSpec, error) {
 
 	diskArgs := []string{
 		"type=qcow",
-		"size=80G",
+		"size=" + diskSize(l.Config.DiskSizeMB, "80G"),
 		"trim=true",
 		fmt.Sprintf("qcow-tool=%s", qcowtool),
 		"qcow-onflush=os",
@@ -86,38 +149,69 @@ func (l *LinuxKit) DaemonSpec(cpus, mem int) (daemon.DaemonSpec, error) {
 		"qcow-keeperased=262144",
 	}
 
+	programArgs := []string{
+		linuxkit, "run", "hyperkit",
+		"-console-file",
+		"-cpus", fmt.Sprintf("%d", cpus),
+		"-mem", fmt.Sprintf("%d", mem),
+		"-hyperkit", hyperkit,
+		"-networking", fmt.Sprintf("vpnkit,%v,%v", vpnkitEthSock, vpnkitPortSock),
+		"-fw", uefi,
+		"-disk", strings.Join(diskArgs, ","),
+	}
+
+	for _, disk := range dataDisks {
+		dataDiskArgs := []string{
+			fmt.Sprintf("file=%s", disk.Path),
+			"type=qcow",
+			fmt.Sprintf("size=%dM", disk.SizeMB),
+			"trim=true",
+			fmt.Sprintf("qcow-tool=%s", qcowtool),
+			"qcow-onflush=os",
+		}
+		programArgs = append(programArgs, "-disk", strings.Join(dataDiskArgs, ","))
+	}
+
+	programArgs = append(programArgs,
+		"-state", l.Config.StateLinuxkit,
+		"-uefi",
+		osImagePath,
+	)
+
 	return daemon.DaemonSpec{
-		Label:       LinuxKitLabel,
-		Program:     linuxkit,
-		SessionType: "Background",
-		ProgramArguments: []string{
-			linuxkit, "run", "hyperkit",
-			"-console-file",
-			"-cpus", fmt.Sprintf("%d", cpus),
-			"-mem", fmt.Sprintf("%d", mem),
-			"-hyperkit", hyperkit,
-			"-networking", fmt.Sprintf("vpnkit,%v,%v", vpnkitEthSock, vpnkitPortSock),
-			"-fw", uefi,
-			"-disk", strings.Join(diskArgs, ","),
-			"-state", l.Config.StateLinuxkit,
-			"-uefi",
-			osImagePath,
-		},
-		RunAtLoad:  false,
-		StdoutPath: path.Join(l.Config.LogDir, "linuxkit.stdout.log"),
-		StderrPath: path.Join(l.Config.LogDir, "linuxkit.stderr.log"),
+		Label:            l.label(),
+		Program:          linuxkit,
+		SessionType:      "Background",
+		ProgramArguments: programArgs,
+		RunAtLoad:        false,
+		StdoutPath:       path.Join(l.Config.LogDir, "linuxkit.stdout.log"),
+		StderrPath:       path.Join(l.Config.LogDir, "linuxkit.stderr.log"),
+		KeepAlive:        true,
 	}, nil
 }
 
 func (l *LinuxKit) Watch(exit chan string) {
-	go func() {
-		for {
-			running, err := l.DaemonRunner.IsRunning(LinuxKitLabel)
-			if !running && err == nil {
-				exit <- "linuxkit"
-				return
-			}
-			time.Sleep(5 * time.Second)
-		}
-	}()
+	daemon.Watch(l.DaemonRunner.IsRunning, l.label(), "linuxkit", exit)
+}
+
+func (l *LinuxKit) Stats(vmName string) (Stats, error) {
+	if running, err := l.IsRunning(vmName); err != nil {
+		return Stats{}, err
+	} else if !running {
+		return Stats{}, fmt.Errorf("linuxkit vm is not running")
+	}
+
+	return guestStats(l.Config)
+}
+
+func (l *LinuxKit) Preflight(vm VM) []Finding {
+	findings := resourceFindings(vm, l.Config.CFDevHome)
+	if vm.GPU {
+		findings = append(findings, Finding{
+			Check:   "gpu",
+			Passed:  false,
+			Message: messages.T("preflight.gpu-unsupported"),
+		})
+	}
+	return findings
 }
@@ -3,7 +3,11 @@
 package hypervisor_test
 
 import (
+	"io/ioutil"
+	"os"
+
 	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/daemon"
 	"code.cloudfoundry.org/cfdev/hypervisor"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
@@ -55,4 +59,83 @@ var _ = Describe("LinuxKit process", func() {
 			"/home-dir/.cfdev/cache/cfdev-efi-v2.iso",
 		))
 	})
+
+	It("sizes the disk as configured", func() {
+		linuxkit.Config.DiskSizeMB = 122880
+
+		start, err := linuxkit.DaemonSpec(4, 4096)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(start.ProgramArguments).To(ContainElement(
+			"type=qcow,size=122880M,trim=true,qcow-tool=/home-dir/.cfdev/cache/qcow-tool,qcow-onflush=os,qcow-compactafter=262144,qcow-keeperased=262144",
+		))
+	})
+
+	It("attaches data disks after the base disk", func() {
+		start, err := linuxkit.DaemonSpec(4, 4096, hypervisor.Disk{
+			Path:   "/home-dir/.cfdev/state/linuxkit/blobstore.qcow2",
+			SizeMB: 20480,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(start.ProgramArguments).To(ContainElement(
+			"file=/home-dir/.cfdev/state/linuxkit/blobstore.qcow2,type=qcow,size=20480M,trim=true,qcow-tool=/home-dir/.cfdev/cache/qcow-tool,qcow-onflush=os",
+		))
+		Expect(start.ProgramArguments[len(start.ProgramArguments)-1]).To(Equal("/home-dir/.cfdev/cache/cfdev-efi-v2.iso"))
+	})
+
+	Describe("Reconfigure", func() {
+		var (
+			runner   *recordingRunner
+			stateDir string
+		)
+
+		BeforeEach(func() {
+			var err error
+			stateDir, err = ioutil.TempDir("", "linuxkit")
+			Expect(err).NotTo(HaveOccurred())
+
+			runner = &recordingRunner{}
+			linuxkit.DaemonRunner = runner
+			linuxkit.Config.StateLinuxkit = stateDir
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(stateDir)
+		})
+
+		It("keeps the data disks and the resources that are not given", func() {
+			Expect(linuxkit.CreateVM(hypervisor.VM{
+				Name:      "cfdev",
+				CPUs:      4,
+				MemoryMB:  4096,
+				DataDisks: []hypervisor.Disk{{Path: "/some/data.qcow2", SizeMB: 1024}},
+			})).To(Succeed())
+
+			Expect(linuxkit.Reconfigure("cfdev", 0, 8192)).To(Succeed())
+
+			spec := runner.added[len(runner.added)-1]
+			Expect(spec.ProgramArguments).To(ContainElement("4"))
+			Expect(spec.ProgramArguments).To(ContainElement("8192"))
+			Expect(spec.ProgramArguments).To(ContainElement(ContainSubstring("file=/some/data.qcow2")))
+		})
+
+		It("needs both resources for a vm it does not know", func() {
+			Expect(linuxkit.Reconfigure("cfdev", 0, 8192)).To(MatchError("the current resources of vm cfdev are not known, give both cpus and memory"))
+		})
+	})
 })
+
+type recordingRunner struct {
+	added []daemon.DaemonSpec
+}
+
+func (r *recordingRunner) AddDaemon(spec daemon.DaemonSpec) error {
+	r.added = append(r.added, spec)
+	return nil
+}
+
+func (r *recordingRunner) RemoveDaemon(string) error      { return nil }
+func (r *recordingRunner) Start(string) error             { return nil }
+func (r *recordingRunner) Stop(string) error              { return nil }
+func (r *recordingRunner) IsRunning(string) (bool, error) { return false, nil }
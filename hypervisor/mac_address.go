@@ -0,0 +1,19 @@
+package hypervisor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var macAddressPattern = regexp.MustCompile(`^[0-9A-F]{12}$`)
+
+// normalizeMACAddress accepts a MAC address with or without ':' or '-'
+// separators and returns it in the bare form Hyper-V expects.
+func normalizeMACAddress(mac string) (string, error) {
+	normalized := strings.ToUpper(strings.NewReplacer(":", "", "-", "").Replace(mac))
+	if !macAddressPattern.MatchString(normalized) {
+		return "", fmt.Errorf("invalid MAC address: %s", mac)
+	}
+	return normalized, nil
+}
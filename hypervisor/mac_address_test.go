@@ -0,0 +1,22 @@
+package hypervisor
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("normalizeMACAddress", func() {
+	It("strips separators", func() {
+		Expect(normalizeMACAddress("00:15:5d:01:02:03")).To(Equal("00155D010203"))
+		Expect(normalizeMACAddress("00-15-5D-01-02-03")).To(Equal("00155D010203"))
+		Expect(normalizeMACAddress("00155D010203")).To(Equal("00155D010203"))
+	})
+
+	It("rejects anything that is not a MAC address", func() {
+		_, err := normalizeMACAddress("00:15:5d:01:02")
+		Expect(err).To(MatchError("invalid MAC address: 00:15:5d:01:02"))
+
+		_, err = normalizeMACAddress("00:15:5d:01:02:zz")
+		Expect(err).To(HaveOccurred())
+	})
+})
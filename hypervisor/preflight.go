@@ -0,0 +1,55 @@
+package hypervisor
+
+import (
+	"code.cloudfoundry.org/cfdev/messages"
+	"github.com/cloudfoundry/gosigar"
+)
+
+// minFreeDiskMB leaves room for the vm disk to grow while cf and any
+// services are deployed.
+const minFreeDiskMB = 20 * 1024
+
+// Finding is the outcome of a single preflight check. A failed check marked
+// Fatal would stop the vm from starting at all; other failures only degrade it.
+type Finding struct {
+	Check   string
+	Passed  bool
+	Fatal   bool
+	Message string
+}
+
+// resourceFindings checks that the host can spare the memory and disk the
+// vm needs, with the vm state kept under dir.
+func resourceFindings(vm VM, dir string) []Finding {
+	var findings []Finding
+
+	mem := sigar.Mem{}
+	if err := mem.Get(); err == nil {
+		availableMB := mem.ActualFree / bytesInMegabyte
+		findings = append(findings, Finding{
+			Check:  "free-memory",
+			Passed: availableMB >= uint64(vm.MemoryMB),
+			Message: messages.T("preflight.free-memory", map[string]interface{}{
+				"Available": availableMB,
+				"Required":  vm.MemoryMB,
+			}),
+		})
+	}
+
+	usage := sigar.FileSystemUsage{}
+	if err := usage.Get(dir); err == nil {
+		// Avail is reported in kilobytes
+		availableMB := usage.Avail / 1024
+		findings = append(findings, Finding{
+			Check:  "free-disk",
+			Passed: availableMB >= minFreeDiskMB,
+			Message: messages.T("preflight.free-disk", map[string]interface{}{
+				"Available": availableMB,
+				"Required":  minFreeDiskMB,
+				"Dir":       dir,
+			}),
+		})
+	}
+
+	return findings
+}
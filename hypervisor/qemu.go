@@ -0,0 +1,253 @@
+package hypervisor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/daemon"
+	"code.cloudfoundry.org/cfdev/messages"
+)
+
+const QEMULabel = "org.cloudfoundry.cfdev.qemu"
+
+// QEMU runs the vm under QEMU, for hosts such as CI runners that have
+// neither Hyper-V nor hyperkit. With the tcg accelerator every instruction
+// is emulated, so it is many times slower than the native hypervisors.
+// QEMU is networked on its own, so vpnkit is not needed.
+type QEMU struct {
+	Config       config.Config
+	DaemonRunner DaemonRunner
+}
+
+func (q *QEMU) label() string {
+	return q.Config.DaemonLabel(QEMULabel)
+}
+
+func (q *QEMU) diskPath() string {
+	return filepath.Join(q.Config.StateLinuxkit, "disk.qcow2")
+}
+
+func (q *QEMU) CreateVM(vm VM) error {
+	if err := q.createDisks(vm); err != nil {
+		return err
+	}
+
+	daemonSpec, err := q.DaemonSpec(vm)
+	if err != nil {
+		return err
+	}
+	if err := q.DaemonRunner.AddDaemon(daemonSpec); err != nil {
+		return err
+	}
+	return saveVM(q.Config.StateLinuxkit, vm)
+}
+
+// createDisks makes the disks the vm boots from and keeps its data on,
+// leaving any already there in place. On macOS the deps tarball provides
+// the base disk as qcow2. On Windows it is a vhdx in the cache, which the
+// vm writes to through a qcow2 overlay.
+func (q *QEMU) createDisks(vm VM) error {
+	if _, err := os.Stat(q.diskPath()); os.IsNotExist(err) {
+		base := filepath.Join(q.Config.CacheDir, "disk.vhdx")
+		args := []string{"create", "-f", "qcow2", "-F", "vhdx", "-b", base, q.diskPath()}
+		if q.Config.DiskSizeMB > 0 {
+			args = append(args, diskSize(q.Config.DiskSizeMB, ""))
+		}
+		if err := q.qemuImg(args...); err != nil {
+			return fmt.Errorf("creating overlay disk: %s", err)
+		}
+	}
+
+	for _, disk := range vm.DataDisks {
+		if _, err := os.Stat(disk.Path); err == nil {
+			continue
+		}
+		if err := q.qemuImg("create", "-f", "qcow2", disk.Path, fmt.Sprintf("%dM", disk.SizeMB)); err != nil {
+			return fmt.Errorf("creating data disk %s: %s", disk.Path, err)
+		}
+	}
+	return nil
+}
+
+func (q *QEMU) Start(vmName string) error {
+	return q.DaemonRunner.Start(q.label())
+}
+
+func (q *QEMU) Stop(vmName string) error {
+	return q.DaemonRunner.Stop(q.label())
+}
+
+func (q *QEMU) Destroy(vmName string) error {
+	return q.DaemonRunner.RemoveDaemon(q.label())
+}
+
+// Reconfigure replaces the qemu daemon with one using the new resources,
+// keeping the data disks and the resources that are zero. The vm must be
+// stopped.
+func (q *QEMU) Reconfigure(vmName string, cpus int, memoryMB int) error {
+	if running, err := q.IsRunning(vmName); err != nil {
+		return err
+	} else if running {
+		return fmt.Errorf("vm %s must be stopped to be reconfigured", vmName)
+	}
+
+	vm, err := resizedVM(q.Config.StateLinuxkit, vmName, cpus, memoryMB)
+	if err != nil {
+		return err
+	}
+	daemonSpec, err := q.DaemonSpec(vm)
+	if err != nil {
+		return err
+	}
+	if err := q.DaemonRunner.RemoveDaemon(q.label()); err != nil {
+		return err
+	}
+	if err := q.DaemonRunner.AddDaemon(daemonSpec); err != nil {
+		return err
+	}
+	return saveVM(q.Config.StateLinuxkit, vm)
+}
+
+func (q *QEMU) IsRunning(vmName string) (bool, error) {
+	return q.DaemonRunner.IsRunning(q.label())
+}
+
+func (q *QEMU) ConsoleLogPath(vmName string) string {
+	return filepath.Join(q.Config.LogDir, vmName+"-console.log")
+}
+
+// Export bundles the vm's disk and bosh state. The disk is flattened, so
+// that the bundle does not depend on the base disk in the cache. The vm
+// must be stopped so that the disk is consistent.
+func (q *QEMU) Export(vmName string, path string) error {
+	metadata := BundleMetadata{
+		Backend:   "qemu",
+		Disk:      "disk.qcow2",
+		CreatedAt: time.Now(),
+	}
+
+	return writeBundle(q.Config, path, metadata, func(dir string) error {
+		return q.qemuImg("convert", "-O", "qcow2", q.diskPath(), filepath.Join(dir, metadata.Disk))
+	})
+}
+
+// Import restores a bundle made by Export in place of the vm's state.
+func (q *QEMU) Import(path string) error {
+	return readBundle(q.Config, path, "qemu")
+}
+
+func (q *QEMU) Watch(exit chan string) {
+	daemon.Watch(q.DaemonRunner.IsRunning, q.label(), "qemu", exit)
+}
+
+func (q *QEMU) Stats(vmName string) (Stats, error) {
+	if running, err := q.IsRunning(vmName); err != nil {
+		return Stats{}, err
+	} else if !running {
+		return Stats{}, fmt.Errorf("qemu vm is not running")
+	}
+
+	return guestStats(q.Config)
+}
+
+func (q *QEMU) Preflight(vm VM) []Finding {
+	findings := resourceFindings(vm, q.Config.CFDevHome)
+
+	_, err := exec.LookPath(q.Config.QEMU.Binary)
+	findings = append(findings, Finding{
+		Check:   "qemu",
+		Passed:  err == nil,
+		Fatal:   true,
+		Message: messages.T("preflight.qemu", map[string]interface{}{"Binary": q.Config.QEMU.Binary}),
+	})
+
+	if vm.GPU {
+		findings = append(findings, Finding{
+			Check:   "gpu",
+			Passed:  false,
+			Message: messages.T("preflight.gpu-unsupported-qemu"),
+		})
+	}
+	return findings
+}
+
+func (q *QEMU) DaemonSpec(vm VM) (daemon.DaemonSpec, error) {
+	binary, err := exec.LookPath(q.Config.QEMU.Binary)
+	if err != nil {
+		return daemon.DaemonSpec{}, fmt.Errorf("finding qemu: %s", err)
+	}
+
+	programArgs := []string{
+		binary,
+		"-name", vm.Name,
+		"-machine", "q35,accel=" + q.Config.QEMU.Accel,
+		"-cpu", qemuCPU(q.Config.QEMU.Accel),
+		"-smp", fmt.Sprintf("%d", vm.CPUs),
+		"-m", fmt.Sprintf("%d", vm.MemoryMB),
+		"-bios", q.Config.QEMU.Firmware,
+		"-display", "none",
+		"-monitor", "none",
+		"-serial", "file:" + q.ConsoleLogPath(vm.Name),
+		"-drive", fmt.Sprintf("if=virtio,file=%s,format=qcow2,discard=unmap", q.diskPath()),
+	}
+
+	for _, disk := range vm.DataDisks {
+		programArgs = append(programArgs,
+			"-drive", fmt.Sprintf("if=virtio,file=%s,format=qcow2,discard=unmap", disk.Path))
+	}
+
+	programArgs = append(programArgs,
+		"-netdev", "user,id=net0,"+strings.Join(q.forwards(), ","),
+		"-device", "virtio-net-pci,netdev=net0",
+		"-cdrom", filepath.Join(q.Config.CacheDir, "cfdev-efi-v2.iso"),
+	)
+
+	return daemon.DaemonSpec{
+		Label:            q.label(),
+		Program:          binary,
+		SessionType:      "Background",
+		ProgramArguments: programArgs,
+		RunAtLoad:        false,
+		StdoutPath:       path.Join(q.Config.LogDir, "qemu.stdout.log"),
+		StderrPath:       path.Join(q.Config.LogDir, "qemu.stderr.log"),
+		KeepAlive:        true,
+	}, nil
+}
+
+// forwards has qemu expose the guest ports that vpnkit would otherwise
+// expose on the host.
+func (q *QEMU) forwards() []string {
+	var forwards []string
+	for _, forward := range Forwards(q.Config) {
+		forwards = append(forwards, fmt.Sprintf("hostfwd=tcp:%s:%d-:%d", forward.IP, forward.Port, forward.Port))
+	}
+	return forwards
+}
+
+func (q *QEMU) qemuImg(args ...string) error {
+	qemuImg := filepath.Join(filepath.Dir(q.Config.QEMU.Binary), "qemu-img")
+	if !strings.ContainsAny(q.Config.QEMU.Binary, `/\`) {
+		qemuImg = "qemu-img"
+	}
+
+	output, err := exec.Command(qemuImg, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// qemuCPU passes the host cpu through when it is virtualized, and
+// otherwise emulates one with every feature tcg supports.
+func qemuCPU(accel string) string {
+	if accel == "tcg" {
+		return "max"
+	}
+	return "host"
+}
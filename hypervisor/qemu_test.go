@@ -0,0 +1,112 @@
+// +build darwin
+
+package hypervisor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/hypervisor"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QEMU", func() {
+	var (
+		qemu   hypervisor.QEMU
+		tmpDir string
+		binary string
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "qemu")
+		Expect(err).NotTo(HaveOccurred())
+		binary = filepath.Join(tmpDir, "qemu-system-x86_64")
+		Expect(ioutil.WriteFile(binary, []byte("#!/bin/sh\n"), 0755)).To(Succeed())
+
+		qemu = hypervisor.QEMU{
+			Config: config.Config{
+				CFDevHome:      "/home-dir/.cfdev",
+				CacheDir:       "/home-dir/.cfdev/cache",
+				StateLinuxkit:  "/home-dir/.cfdev/state/linuxkit",
+				LogDir:         "/home-dir/.cfdev/log",
+				BoshDirectorIP: "10.144.0.4",
+				CFRouterIP:     "10.144.0.34",
+				QEMU: config.QEMUConfig{
+					Binary:   binary,
+					Accel:    "tcg",
+					Firmware: "/home-dir/.cfdev/cache/UEFI.fd",
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("boots the iso with an emulated cpu and user mode networking", func() {
+		spec, err := qemu.DaemonSpec(hypervisor.VM{Name: "cfdev", CPUs: 4, MemoryMB: 4096})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(spec.Program).To(Equal(binary))
+		Expect(spec.ProgramArguments).To(Equal([]string{
+			binary,
+			"-name", "cfdev",
+			"-machine", "q35,accel=tcg",
+			"-cpu", "max",
+			"-smp", "4",
+			"-m", "4096",
+			"-bios", "/home-dir/.cfdev/cache/UEFI.fd",
+			"-display", "none",
+			"-monitor", "none",
+			"-serial", "file:/home-dir/.cfdev/log/cfdev-console.log",
+			"-drive", "if=virtio,file=/home-dir/.cfdev/state/linuxkit/disk.qcow2,format=qcow2,discard=unmap",
+			"-netdev", "user,id=net0," +
+				"hostfwd=tcp:127.0.0.1:9992-:9992,hostfwd=tcp:127.0.0.1:9999-:9999," +
+				"hostfwd=tcp:10.144.0.4:22-:22,hostfwd=tcp:10.144.0.4:6868-:6868,hostfwd=tcp:10.144.0.4:8443-:8443," +
+				"hostfwd=tcp:10.144.0.4:8844-:8844,hostfwd=tcp:10.144.0.4:25555-:25555," +
+				"hostfwd=tcp:10.144.0.34:80-:80,hostfwd=tcp:10.144.0.34:443-:443,hostfwd=tcp:10.144.0.34:2222-:2222",
+			"-device", "virtio-net-pci,netdev=net0",
+			"-cdrom", "/home-dir/.cfdev/cache/cfdev-efi-v2.iso",
+		}))
+		Expect(spec.KeepAlive).To(BeTrue())
+	})
+
+	It("passes the host cpu through when accelerated", func() {
+		qemu.Config.QEMU.Accel = "kvm"
+
+		spec, err := qemu.DaemonSpec(hypervisor.VM{Name: "cfdev", CPUs: 4, MemoryMB: 4096})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.ProgramArguments).To(ContainElement("q35,accel=kvm"))
+		Expect(spec.ProgramArguments).To(ContainElement("host"))
+	})
+
+	It("attaches data disks after the base disk", func() {
+		spec, err := qemu.DaemonSpec(hypervisor.VM{
+			Name:      "cfdev",
+			CPUs:      4,
+			MemoryMB:  4096,
+			DataDisks: []hypervisor.Disk{{Path: "/some/data.qcow2", SizeMB: 1024}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.ProgramArguments).To(ContainElement("if=virtio,file=/some/data.qcow2,format=qcow2,discard=unmap"))
+	})
+
+	Context("when qemu is not installed", func() {
+		It("fails preflight", func() {
+			qemu.Config.QEMU.Binary = filepath.Join(tmpDir, "missing")
+
+			findings := qemu.Preflight(hypervisor.VM{Name: "cfdev"})
+			Expect(findings).To(ContainElement(hypervisor.Finding{
+				Check:   "qemu",
+				Passed:  false,
+				Fatal:   true,
+				Message: filepath.Join(tmpDir, "missing") + " was not found. Install QEMU or set CFDEV_QEMU_BINARY to its path",
+			}))
+		})
+	})
+})
@@ -0,0 +1,32 @@
+package hypervisor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// SafeKill kills the process in pidfile, but only if it is still the
+// program name, as the pid may have been reused since it was written.
+func SafeKill(pidfile, name string) error {
+	data, err := ioutil.ReadFile(pidfile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return err
+	}
+
+	path, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	if err == nil && strings.Contains(path, name) {
+		syscall.Kill(pid, syscall.SIGKILL)
+	}
+	return os.Remove(pidfile)
+}
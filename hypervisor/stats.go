@@ -0,0 +1,146 @@
+package hypervisor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/ssh"
+)
+
+const (
+	bytesInMegabyte = 1024 * 1024
+	sectorSize      = 512
+)
+
+// procStatsCommand is run in the guest. /proc/stat, /proc/meminfo and
+// /proc/diskstats are not namespaced so they describe the whole VM.
+const procStatsCommand = "head -n 1 /proc/stat; cat /proc/meminfo /proc/diskstats; sleep 1; head -n 1 /proc/stat"
+
+// guestStats reads the vm's usage from inside it, for hypervisors that
+// cannot report it themselves.
+func guestStats(cfg config.Config) (Stats, error) {
+	key, err := ioutil.ReadFile(filepath.Join(cfg.CacheDir, "id_rsa"))
+	if err != nil {
+		return Stats{}, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	s := ssh.SSH{}
+	err = s.RunSSHCommand(
+		procStatsCommand,
+		ssh.SSHAddress{IP: "127.0.0.1", Port: "9992"},
+		key,
+		20*time.Second,
+		&stdout,
+		&stderr,
+	)
+	if err != nil {
+		return Stats{}, fmt.Errorf("reading vm stats: %s: %s", err, stderr.String())
+	}
+
+	return parseProcStats(stdout.String())
+}
+
+// parseProcStats reads the output of procStatsCommand, which samples the
+// aggregate cpu line of /proc/stat on either side of a one second sleep.
+func parseProcStats(output string) (Stats, error) {
+	var (
+		stats    Stats
+		cpuLines []string
+		mem      = map[string]uint64{}
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "cpu":
+			cpuLines = append(cpuLines, line)
+		case strings.HasSuffix(fields[0], ":") && len(fields) >= 2:
+			value, err := strconv.ParseUint(fields[1], 10, 64)
+			if err == nil {
+				mem[strings.TrimSuffix(fields[0], ":")] = value
+			}
+		case len(fields) >= 10 && isWholeDisk(fields[2]):
+			read, _ := strconv.ParseUint(fields[5], 10, 64)
+			written, _ := strconv.ParseUint(fields[9], 10, 64)
+			stats.DiskReadBytes += read * sectorSize
+			stats.DiskWriteBytes += written * sectorSize
+		}
+	}
+
+	if len(cpuLines) != 2 {
+		return Stats{}, fmt.Errorf("expected 2 cpu samples, got %d", len(cpuLines))
+	}
+
+	idle0, total0, err := cpuTimes(cpuLines[0])
+	if err != nil {
+		return Stats{}, err
+	}
+	idle1, total1, err := cpuTimes(cpuLines[1])
+	if err != nil {
+		return Stats{}, err
+	}
+	if total1 > total0 {
+		busy := float64((total1 - total0) - (idle1 - idle0))
+		stats.CPUPercent = 100 * busy / float64(total1-total0)
+	}
+
+	// meminfo reports kB
+	stats.MemoryAssignedMB = mem["MemTotal"] / 1024
+	if available, ok := mem["MemAvailable"]; ok && available <= mem["MemTotal"] {
+		stats.MemoryDemandMB = (mem["MemTotal"] - available) / 1024
+	}
+
+	return stats, nil
+}
+
+func cpuTimes(line string) (idle, total uint64, err error) {
+	fields := strings.Fields(line)[1:]
+	for i, field := range fields {
+		value, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("parsing cpu line %q: %s", line, err)
+		}
+		total += value
+		// idle and iowait
+		if i == 3 || i == 4 {
+			idle += value
+		}
+	}
+	return idle, total, nil
+}
+
+func isWholeDisk(name string) bool {
+	for _, prefix := range []string{"sd", "vd", "hd", "xvd"} {
+		if strings.HasPrefix(name, prefix) {
+			suffix := strings.TrimPrefix(name, prefix)
+			return strings.Trim(suffix, "abcdefghijklmnopqrstuvwxyz") == ""
+		}
+	}
+	return false
+}
+
+// parseFormatList reads the "Name : Value" pairs written by
+// powershell's Format-List.
+func parseFormatList(output string) map[string]string {
+	values := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		values[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return values
+}
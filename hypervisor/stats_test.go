@@ -0,0 +1,43 @@
+package hypervisor
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("parseProcStats", func() {
+	It("reads cpu, memory and disk usage from the guest", func() {
+		output := `cpu  100 0 100 700 100 0 0 0 0 0
+MemTotal:        4194304 kB
+MemFree:         1048576 kB
+MemAvailable:    3145728 kB
+   8       0 sda 10 0 4 0 20 0 8 0 0 0 0
+   8       1 sda1 10 0 4 0 20 0 8 0 0 0 0
+ 254       0 vda 30 0 6 0 10 0 2 0 0 0 0
+cpu  150 0 150 750 150 0 0 0 0 0
+`
+		stats, err := parseProcStats(output)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats).To(Equal(Stats{
+			CPUPercent:       50,
+			MemoryDemandMB:   1024,
+			MemoryAssignedMB: 4096,
+			DiskReadBytes:    10 * 512,
+			DiskWriteBytes:   10 * 512,
+		}))
+	})
+
+	It("fails without two cpu samples", func() {
+		_, err := parseProcStats("cpu  100 0 100 700 100 0 0 0 0 0\n")
+		Expect(err).To(MatchError("expected 2 cpu samples, got 1"))
+	})
+})
+
+var _ = Describe("parseFormatList", func() {
+	It("reads property values", func() {
+		Expect(parseFormatList("\r\nCPUUsage       : 12\r\nMemoryAssigned : 2147483648\r\n")).To(Equal(map[string]string{
+			"CPUUsage":       "12",
+			"MemoryAssigned": "2147483648",
+		}))
+	})
+})
@@ -1,7 +1,131 @@
 package hypervisor
 
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/config"
+)
+
 type VM struct {
-	Name     string
-	MemoryMB int
-	CPUs     int
+	Name      string
+	MemoryMB  int
+	CPUs      int
+	DataDisks []Disk
+	// MinMemoryMB and MaxMemoryMB let the hypervisor grow and shrink the
+	// vm's memory with demand, starting from MemoryMB. Both must be set;
+	// otherwise MemoryMB is assigned for the life of the vm. Only Hyper-V
+	// supports this.
+	MinMemoryMB int
+	MaxMemoryMB int
+	// NestedVirtualization exposes the host's virtualization extensions to
+	// the vm so it can run hypervisors of its own. It takes precedence over a
+	// memory range. Only Hyper-V supports this.
+	NestedVirtualization bool
+	// SecureBoot has the firmware verify the boot loader against
+	// SecureBootTemplate, which defaults to the template for Linux guests.
+	// The cfdev iso is not signed, so it is off unless asked for. Only
+	// Hyper-V supports this.
+	SecureBoot         bool
+	SecureBootTemplate string
+	// TPM adds a virtual TPM to the vm, protected by a key local to the
+	// host. Only Hyper-V supports this.
+	TPM bool
+	// MACAddress is given to the vm's network adapter instead of one from the
+	// hypervisor's dynamic range, so that DHCP reservations keep handing the
+	// vm the same address across restarts. Only Hyper-V supports this.
+	MACAddress string
+	// GPU gives the vm a partition of the host's GPU, for workloads that
+	// need more than software rendering. Only Hyper-V supports this, and only
+	// with a GPU driver that supports partitioning.
+	GPU bool
+}
+
+// NewVM is the vm conf asks for with the given resources, with its data
+// disk, if it has one, kept in conf's data directory.
+func NewVM(conf config.Config, cpus, memoryMB int) VM {
+	vm := VM{
+		Name:                 conf.VMName(),
+		CPUs:                 cpus,
+		MemoryMB:             memoryMB,
+		MinMemoryMB:          conf.VM.MinMemoryMB,
+		MaxMemoryMB:          conf.VM.MaxMemoryMB,
+		NestedVirtualization: conf.VM.NestedVirtualization,
+		SecureBoot:           conf.VM.SecureBoot,
+		TPM:                  conf.VM.TPM,
+		MACAddress:           conf.MACAddress,
+		GPU:                  conf.GPU,
+	}
+	if conf.VM.DataDiskMB > 0 {
+		vm.DataDisks = []Disk{{Path: conf.DataDiskPath(), SizeMB: conf.VM.DataDiskMB}}
+	}
+	return vm
+}
+
+// Disk is a secondary volume attached to the VM alongside the base image.
+// It is created with SizeMB if it does not already exist at Path, so its
+// contents survive the base disk being replaced on upgrade.
+type Disk struct {
+	Path   string
+	SizeMB int
+}
+
+// vmFile keeps the vm a daemon backed hypervisor was created with, which
+// its daemon spec alone does not, so that Reconfigure can carry it over.
+const vmFile = "vm.json"
+
+func saveVM(dir string, vm VM) error {
+	contents, err := json.Marshal(vm)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, vmFile), contents, 0644)
+}
+
+// resizedVM is the vm saved in dir with the cpus and memoryMB that are
+// not zero, which leaves those that are unchanged. A vm created before it
+// was saved has to be given both.
+func resizedVM(dir string, vmName string, cpus int, memoryMB int) (VM, error) {
+	vm := VM{Name: vmName}
+	contents, err := ioutil.ReadFile(filepath.Join(dir, vmFile))
+	if err != nil && !os.IsNotExist(err) {
+		return VM{}, err
+	} else if err == nil {
+		if err := json.Unmarshal(contents, &vm); err != nil {
+			return VM{}, fmt.Errorf("reading %s: %s", vmFile, err)
+		}
+	}
+
+	if cpus > 0 {
+		vm.CPUs = cpus
+	}
+	if memoryMB > 0 {
+		vm.MemoryMB = memoryMB
+	}
+	if vm.CPUs <= 0 || vm.MemoryMB <= 0 {
+		return VM{}, fmt.Errorf("the current resources of vm %s are not known, give both cpus and memory", vmName)
+	}
+	return vm, nil
+}
+
+// Stats is a point-in-time view of the resources the VM is consuming.
+// Disk counters are cumulative rather than per-interval.
+type Stats struct {
+	CPUPercent       float64
+	MemoryDemandMB   uint64
+	MemoryAssignedMB uint64
+	DiskReadBytes    uint64
+	DiskWriteBytes   uint64
+}
+
+// diskSize is the size of the vm's disk in a form the hypervisor tools
+// take, sizeMB if it is set and otherwise fallback.
+func diskSize(sizeMB int, fallback string) string {
+	if sizeMB <= 0 {
+		return fallback
+	}
+	return fmt.Sprintf("%dM", sizeMB)
 }
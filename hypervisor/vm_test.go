@@ -0,0 +1,47 @@
+package hypervisor_test
+
+import (
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/hypervisor"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewVM", func() {
+	It("asks for the hardware the config has", func() {
+		conf := config.Config{
+			DataDir:    "some-data",
+			Hypervisor: "qemu",
+			MACAddress: "00:15:5d:01:02:03",
+			GPU:        true,
+			VM: config.VMConfig{
+				DataDiskMB:           10240,
+				MinMemoryMB:          4096,
+				MaxMemoryMB:          12288,
+				NestedVirtualization: true,
+				SecureBoot:           true,
+				TPM:                  true,
+			},
+		}
+
+		Expect(hypervisor.NewVM(conf, 4, 8192)).To(Equal(hypervisor.VM{
+			Name:                 "cfdev",
+			CPUs:                 4,
+			MemoryMB:             8192,
+			DataDisks:            []hypervisor.Disk{{Path: filepath.Join("some-data", "data.qcow2"), SizeMB: 10240}},
+			MinMemoryMB:          4096,
+			MaxMemoryMB:          12288,
+			NestedVirtualization: true,
+			SecureBoot:           true,
+			TPM:                  true,
+			MACAddress:           "00:15:5d:01:02:03",
+			GPU:                  true,
+		}))
+	})
+
+	It("attaches no data disk without a size", func() {
+		Expect(hypervisor.NewVM(config.Config{}, 4, 8192).DataDisks).To(BeEmpty())
+	})
+})
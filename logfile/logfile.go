@@ -0,0 +1,99 @@
+// Package logfile writes logs to files that are moved aside as they grow,
+// so that a long running process cannot fill the disk.
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// File appends to the file at Path, moving it aside once it reaches
+// MaxBytes and keeping Backups old files as Path.1 (the newest) to
+// Path.Backups.
+type File struct {
+	Path     string
+	MaxBytes int64
+	Backups  int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Open opens the file at path to append to, making its directory if
+// needed.
+func Open(path string, maxBytes int64, backups int) (*File, error) {
+	f := &File{Path: path, MaxBytes: maxBytes, Backups: backups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.size > 0 && f.size+int64(len(p)) > f.MaxBytes {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+func (f *File) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate moves each file one along, dropping the oldest. f.mu must be
+// held.
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	os.Remove(f.backup(f.Backups))
+	for i := f.Backups - 1; i >= 1; i-- {
+		os.Rename(f.backup(i), f.backup(i+1))
+	}
+	if f.Backups > 0 {
+		if err := os.Rename(f.Path, f.backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(f.Path); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+func (f *File) backup(i int) string {
+	return fmt.Sprintf("%s.%d", f.Path, i)
+}
@@ -0,0 +1,13 @@
+package logfile_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestLogfile(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Logfile Suite")
+}
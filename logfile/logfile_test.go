@@ -0,0 +1,75 @@
+package logfile_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/logfile"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File", func() {
+	var (
+		dir  string
+		path string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "logfile")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "log", "some.log")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("moves the file aside once it is full, keeping Backups old files", func() {
+		file, err := logfile.Open(path, 10, 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+			_, err := file.Write([]byte(line))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(file.Close()).To(Succeed())
+
+		Expect(ioutil.ReadFile(path)).To(Equal([]byte("fourth\n")))
+		Expect(ioutil.ReadFile(path + ".1")).To(Equal([]byte("third\n")))
+		Expect(ioutil.ReadFile(path + ".2")).To(Equal([]byte("second\n")))
+		Expect(path + ".3").NotTo(BeAnExistingFile())
+	})
+
+	It("keeps no old files without backups", func() {
+		file, err := logfile.Open(path, 10, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, line := range []string{"first\n", "second\n"} {
+			_, err := file.Write([]byte(line))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(file.Close()).To(Succeed())
+
+		Expect(ioutil.ReadFile(path)).To(Equal([]byte("second\n")))
+		Expect(path + ".1").NotTo(BeAnExistingFile())
+	})
+
+	It("appends to the file left by a previous run", func() {
+		file, err := logfile.Open(path, 100, 1)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.Write([]byte("first\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file.Close()).To(Succeed())
+
+		file, err = logfile.Open(path, 100, 1)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.Write([]byte("two\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file.Close()).To(Succeed())
+
+		Expect(ioutil.ReadFile(path)).To(Equal([]byte("first\ntwo\n")))
+	})
+})
@@ -13,11 +13,15 @@ import (
 	"strings"
 	"syscall"
 
+	"code.cloudfoundry.org/cfdev/analyticsd/sink"
 	"code.cloudfoundry.org/cfdev/cfanalytics"
-	"code.cloudfoundry.org/cfdev/cfanalytics/toggle"
+	"code.cloudfoundry.org/cfdev/cfanalytics/consent"
 	"code.cloudfoundry.org/cfdev/cmd"
 	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/crash"
+	"code.cloudfoundry.org/cfdev/creds"
 	"code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/hypervisor"
 	"code.cloudfoundry.org/cli/cf/terminal"
 	"code.cloudfoundry.org/cli/cf/trace"
 	"code.cloudfoundry.org/cli/plugin"
@@ -34,17 +38,19 @@ type Plugin struct {
 	UI        terminal.UI
 	Config    config.Config
 	Analytics *cfanalytics.Analytics
+	Toggle    *consent.Store
 	Root      *cobra.Command
 	Version   plugin.VersionType
 }
 
-const (
-	boshIP   = "10.144.0.4"
-	routerIP = "10.144.0.34"
-	domain   = "dev.cfdev.sh"
-)
-
 func main() {
+	if len(os.Args) == 4 && os.Args[1] == hypervisor.CaptureConsoleCommand {
+		if err := hypervisor.CaptureConsole(os.Args[2], os.Args[3]); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
 	exitChan := make(chan struct{})
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(make(chan os.Signal), syscall.SIGHUP)
@@ -69,10 +75,34 @@ func main() {
 		os.Exit(1)
 	}
 
-	analyticsToggle := toggle.New(filepath.Join(conf.CFDevHome, "analytics", "analytics.txt"))
-	baseAnalyticsClient, _ := analytics.NewWithConfig(conf.AnalyticsKey, analytics.Config{
+	// A home left by an older cf dev is upgraded before anything reads it.
+	if err := config.MigrateHome(conf.CFDevHome); err != nil {
+		ui.Failed(err.Error())
+		os.Exit(1)
+	}
+
+	analyticsToggle := consent.New(conf.CFDevHome)
+	// The config file answers the telemetry prompt for users who were not
+	// asked yet, but never changes an answer they gave.
+	if conf.Telemetry != nil && !analyticsToggle.Defined() {
+		analyticsToggle.SetCustomAnalyticsEnabled(*conf.Telemetry)
+	}
+	// Analytics must never get in the way of cf dev, so a sink that is
+	// misconfigured sends nothing rather than failing.
+	sinkConfig, err := sink.LoadConfig(conf.CFDevHome)
+	if err != nil {
+		sinkConfig = sink.Config{Sink: sink.None}
+	}
+	if sinkConfig.SegmentKey == "" {
+		sinkConfig.SegmentKey = conf.AnalyticsKey
+	}
+	sinkConfig.SegmentConfig = analytics.Config{
 		Logger: analytics.StdLogger(log.New(ioutil.Discard, "", 0)),
-	})
+	}
+	baseAnalyticsClient, err := sink.New(sinkConfig)
+	if err != nil {
+		baseAnalyticsClient = sink.NoopSink{}
+	}
 
 	h := host.Host{}
 	osVersion, err := h.Version()
@@ -82,13 +112,42 @@ func main() {
 	analyticsClient := cfanalytics.New(analyticsToggle, baseAnalyticsClient, conf.CliVersion.Original, osVersion, exitChan, ui)
 	defer analyticsClient.Close()
 
-	setWhiteListedProxyVariables()
+	// A panic is reported as a crash event when the user opted in to
+	// telemetry, and always to a local report.
+	reporter := &crash.Reporter{
+		Program: "cf-dev",
+		Version: conf.CliVersion.Original,
+		Dir:     filepath.Join(conf.CFDevHome, "crash"),
+		HashKey: sinkConfig.HashKey,
+		Send: func(report crash.Report) error {
+			return analyticsClient.Event(cfanalytics.CRASH, map[string]interface{}{
+				"program": report.Program,
+				"panic":   report.Panic,
+				"stack":   report.Stack,
+			})
+		},
+	}
+	defer reporter.Recover()
+
+	// The proxy's credentials are kept in the keychain, unless the
+	// environment has them.
+	if conf.Proxy.Username == "" {
+		account, err := (&creds.Keychain{}).Proxy()
+		if err != nil {
+			ui.Warn(err.Error())
+		}
+		conf.Proxy.Username, conf.Proxy.Password = account.Username, account.Password
+	}
+	conf.Proxy.Setenv()
+	setWhiteListedProxyVariables(conf)
 
 	v := conf.CliVersion
 	cfdev := &Plugin{
+		Exit:      exitChan,
 		UI:        ui,
 		Config:    conf,
 		Analytics: analyticsClient,
+		Toggle:    analyticsToggle,
 		Root:      cmd.NewRoot(exitChan, ui, conf, analyticsClient, analyticsToggle),
 		Version:   plugin.VersionType{Major: v.Major, Minor: v.Minor, Build: v.Build},
 	}
@@ -96,14 +155,14 @@ func main() {
 	plugin.Start(cfdev)
 }
 
-func setWhiteListedProxyVariables() {
+func setWhiteListedProxyVariables(conf config.Config) {
 	noProxyVars := os.Getenv("NO_PROXY")
 	if noProxyVars != "" {
 		noProxyVars = os.Getenv("no_proxy")
 	}
 
 	arr := strings.Split(noProxyVars, ",")
-	arr = append(arr, boshIP, routerIP, "."+domain)
+	arr = append(arr, conf.BoshDirectorIP, conf.CFRouterIP, "."+conf.CFDomain)
 
 	os.Setenv("NO_PROXY", strings.Join(arr, ","))
 }
@@ -138,6 +197,18 @@ func (p *Plugin) Run(connection plugin.CliConnection, args []string) {
 		}
 	}
 
+	instance, profile := cmd.InstanceFlag(args), cmd.ProfileFlag(args)
+	if instance != "" || profile != "" {
+		conf, err := p.Config.WithInstance(instance).WithProfile(profile)
+		if err != nil {
+			p.UI.Failed(err.Error())
+			p.Analytics.Close()
+			os.Exit(1)
+		}
+		p.Config = conf
+		p.Root = cmd.NewRoot(p.Exit, p.UI, p.Config, p.Analytics, p.Toggle)
+	}
+
 	p.Root.SetArgs(args)
 	if err := p.Root.Execute(); err != nil {
 		p.UI.Failed(err.Error())
@@ -0,0 +1,175 @@
+package messages
+
+var English = Catalog{
+	"start.stopped-unexpectedly":     "ERROR: {{.Name}} has stopped",
+	"start.already-running":          "CF Dev is already running...",
+	"start.downloading-helper":       "Downloading Network Helper...",
+	"start.installing-helper":        "Installing cfdevd network helper...",
+	"start.downloading-resources":    "Downloading Resources...",
+	"start.setting-state":            "Setting State...",
+	"start.creating-vm":              "Creating the VM...",
+	"start.dry-run":                  "Dry run: the VM would be created and started with these commands, none of which were run:",
+	"start.starting-vpnkit":          "Starting VPNKit...",
+	"start.starting-vm":              "Starting the VM...",
+	"start.waiting-for-vm":           "Waiting for the VM...",
+	"start.no-provision":             "VM will not be provisioned because '-n' (no-provision) flag was specified.",
+	"start.quirk":                    "WARNING: {{.Guidance}}",
+	"start.preflight":                "WARNING: {{.Message}}",
+	"start.low-available-memory":     "WARNING: This machine may not have enough available RAM to run with what is specified.",
+	"start.below-recommended-memory": "WARNING: It is recommended that you run {{.Deployment}} Dev with at least {{.Memory}} MB of RAM.",
+	"start.below-required-memory":    "WARNING: {{.Deployment}} Dev needs at least {{.Memory}} MB of RAM to run, but this machine only has {{.HostMemory}} MB.",
+	"start.below-minimum-host":       "WARNING: CF Dev is recommended on a machine with at least {{.Memory}} MB of RAM and {{.Cpus}} cpus. This one has less, so it may be slow or fail to start.",
+
+	"provision.deploying-bosh":       "Deploying the BOSH Director...",
+	"provision.deploying-cf":         "Deploying CF...",
+	"provision.deploying-service":    "Deploying {{.Service}}...",
+	"provision.disk-monitor-failed":  "[WARN] Unable to watch the disk, unused BOSH packages will not be cleaned up as it fills: {{.Error}}",
+	"provision.seeding":              "Creating service instances...",
+	"provision.seeding-failed":       "[WARN] Unable to create every service instance: {{.Error}}",
+	"provision.deployment-new":       "{{.Deployment}}: new, deploying",
+	"provision.deployment-changed":   "{{.Deployment}}: assets changed, redeploying",
+	"provision.deployment-unchanged": "{{.Deployment}}: unchanged, skipping",
+	"provision.cancelling":           "Cancelling the deploy...",
+	"provision.timings":              "Deploy timings:",
+	"provision.timing":               "  {{.Deployment}} {{.Phase}}: {{.Duration}}",
+	"provision.cancel-failed":        "[WARN] Unable to cancel the deploy, the next start may have to wait for it to finish: {{.Error}}",
+	"provision.deleting-service":     "Deleting {{.Service}}...",
+	"provision.vms-left":             "  VMs left: {{.Count}} ({{.Duration}})",
+	"provision.release-uploaded":     "Release {{.Name}}/{{.Version}} is already uploaded, skipping",
+	"provision.uploading-release":    "Uploading release {{.Location}}...",
+	"provision.uploading-stemcell":   "Uploading stemcell {{.Location}}...",
+	"provision.uploaded":             "  Uploaded: {{.Sent}} of {{.Total}} MB",
+	"provision.progress":             "  {{.Summary}} ({{.Duration}})",
+	"provision.done":                 "  Done",
+	"provision.done-after":           "  Done ({{.Duration}})",
+
+	"deploy-service.no-changes": "{{.Service}}: no changes to deploy",
+
+	"download.downloading-resources": "Downloading Resources...",
+
+	"telemetry.on":                "Telemetry is turned ON",
+	"telemetry.off":               "Telemetry is turned OFF",
+	"telemetry.overridden":        "Telemetry is turned OFF by {{.Override}}",
+	"telemetry.report-logged":     "Events logged to {{.Path}}: {{.Count}}",
+	"telemetry.report-not-logged": "Events sent to {{.Sink}} are not kept on this machine. To keep a log of them, set {\"sink\": \"file\"} in analytics-sink.json",
+	"telemetry.report-none":       "No events are sent, the analytics sink is none",
+	"telemetry.report-queued":     "Events waiting to be sent: {{.Count}}",
+	"telemetry.report-event":      "  {{.Event}} x{{.Count}} ({{.First}} to {{.Last}}): {{.Properties}}",
+
+	"bosh.usage":                "Usage: eval $(cf dev bosh env)",
+	"bosh.usage-windows":        "Usage: cf dev bosh env | Invoke-Expression",
+	"bosh.cloud-config-updated": "Updated the cloud config. Existing deployments pick it up when they are next deployed",
+
+	"self-update.up-to-date":  "cf dev {{.Version}} is the latest release",
+	"self-update.downloading": "Downloading cf dev {{.Version}}...",
+	"self-update.installing":  "Installing cf dev {{.Version}}...",
+	"self-update.done":        "Updated cf dev to {{.Version}}",
+	"self-update.install":     "Downloaded cf dev {{.Version}} to {{.Path}}. Windows will not replace the plugin while it runs, so install it with:\n\n  cf install-plugin -f {{.Path}}",
+
+	"prune.pruning": "Removing the releases, stemcells and orphaned disks and VMs that no deployment uses...",
+	"prune.done":    "Done",
+
+	"reset.resetting": "Discarding changes made to the VM...",
+
+	"reconfigure.restarting":        "Restarting the VM with {{.Cpus}} cpus and {{.Memory}}MB of memory...",
+	"reconfigure.restarting-cpus":   "Restarting the VM with {{.Cpus}} cpus...",
+	"reconfigure.restarting-memory": "Restarting the VM with {{.Memory}}MB of memory...",
+	"reconfigure.recovering":        "Bringing the BOSH Director and the deployments' vms back up...",
+	"reconfigure.done":              "The VM has been reconfigured. CF may take a few minutes to be available again, run 'cf dev wait --for cf-api' to wait for it",
+
+	"restart.stopping":   "Stopping the VM, keeping its disk...",
+	"restart.starting":   "Starting the VM again...",
+	"restart.recovering": "Bringing the BOSH Director and the deployments' vms back up...",
+	"restart.done":       "The VM has been restarted. CF may take a few minutes to be available again, run 'cf dev wait --for cf-api' to wait for it",
+
+	"run-errand.running": "Running errand {{.Errand}} of {{.Deployment}}...",
+	"run-errand.stdout":  "Stdout from {{.Instance}}:",
+	"run-errand.stderr":  "Stderr from {{.Instance}}:",
+	"run-errand.exited":  "{{.Instance}} exited with {{.ExitCode}}",
+
+	"status.running":          "CF Dev is running",
+	"status.not-running":      "CF Dev is not running",
+	"status.not-responding":   "[WARN] The CF Dev VM is running but not answering. It may still be booting, try 'cf dev wait --for vm-running'",
+	"status.vm":               "VM: {{.CPU}}% cpu, {{.Demand}}MB of {{.Assigned}}MB memory in use",
+	"status.vm-failed":        "[WARN] Unable to read the VM's state: {{.Error}}",
+	"status.director":         "BOSH Director: healthy",
+	"status.director-failed":  "[WARN] BOSH Director: {{.Error}}",
+	"status.cf-api":           "CF API: healthy",
+	"status.cf-api-failed":    "[WARN] CF API: {{.Error}}",
+	"status.services":         "Deployed services: {{.Services}}",
+	"status.no-services":      "Deployed services: none",
+	"status.services-failed":  "[WARN] Unable to list deployed services: {{.Error}}",
+	"status.latency":          "{{.Name}} latency: {{.Latency}}",
+	"status.latency-slow":     "[WARN] {{.Name}} latency: {{.Latency}}. This is unusually slow and usually points at the host network layer (vpnkit) rather than CF itself",
+	"status.latency-failed":   "[WARN] {{.Name}} is unreachable: {{.Error}}",
+	"status.disk":             "Disk usage: {{.Percent}}% of {{.Mount}} ({{.AvailableMB}}MB free)",
+	"status.disk-pressure":    "[WARN] {{.Mount}} is {{.Percent}}% full. Deploys and pushes will start failing once it runs out of space, try 'cf dev prune', removing unused apps and services or 'cf dev reset'",
+	"status.disk-failed":      "[WARN] Unable to read disk usage: {{.Error}}",
+	"status.heartbeat":        "Guest: {{.Steal}}% cpu steal, {{.Pressure}}% memory pressure, clock skew {{.Skew}}",
+	"status.heartbeat-failed": "[WARN] Unable to read the guest heartbeat: {{.Error}}",
+	"status.tasks":            "Recent BOSH tasks:",
+	"status.task":             "  {{.ID}} {{.State}} {{.StartedAt}} ({{.Duration}}) {{.Deployment}}: {{.Description}}",
+	"status.task-error":       "    Error: {{.Result}}",
+	"status.tasks-failed":     "[WARN] Unable to list BOSH tasks: {{.Error}}",
+	"status.clock-skew":       "[WARN] The guest clock is {{.Skew}} off the host's, which breaks certificate and token validation. Try 'cf dev stop' and 'cf dev start'",
+
+	"wait.waiting": "Waiting for {{.Condition}}...",
+	"wait.ready":   "{{.Condition}} is ready after {{.Elapsed}}",
+
+	"logs.none": "No {{.Component}} logs yet",
+
+	"bundle.exporting": "Exporting the VM to {{.Path}}...",
+	"bundle.exported":  "Done",
+	"bundle.importing": "Importing {{.Path}}...",
+	"bundle.imported":  "Done. Run 'cf dev start' to boot the imported VM",
+
+	"config.setting":     "{{.Key}}: {{.Value}}",
+	"config.set":         "Set {{.Key}} to '{{.Value}}' in {{.Path}}",
+	"config.moving-home": "Stopping CF Dev and moving {{.From}} to {{.To}}...",
+
+	"creds.account":         "{{.Name}}: {{.Username}} / {{.Password}}",
+	"creds.keychain-failed": "[WARN] Unable to mirror the credentials into the keychain: {{.Error}}",
+	"creds.set":             "Stored {{.Name}} in CredHub",
+
+	"doctor.requirements-ok":     "[OK] Host requirements",
+	"doctor.requirements-failed": "[FAIL] {{.Error}}",
+	"doctor.preflight-ok":        "[OK] Virtualization, free memory and disk space",
+	"doctor.preflight-warning":   "[WARN] {{.Message}}",
+	"doctor.preflight-failed":    "[FAIL] {{.Message}}",
+	"doctor.ports-ok":            "[OK] Ports",
+	"doctor.ports-in-use":        "[FAIL] Something else listens on {{.Addresses}}, which cf dev forwards to the vm. Stop whatever uses them before running 'cf dev start'",
+	"doctor.dns-ok":              "[OK] DNS for {{.Domain}}",
+	"doctor.dns-failed":          "[FAIL] {{.Host}} does not resolve. Some routers and DNS servers drop answers that point to private addresses, so use another DNS server, e.g. 8.8.8.8",
+	"doctor.dns-mismatch":        "[WARN] {{.Host}} resolves to {{.Addresses}} rather than the CF router at {{.RouterIP}}. Look for an entry for it in your hosts file, or a VPN whose DNS servers answer for it",
+	"doctor.stale-state-ok":      "[OK] No state left behind",
+	"doctor.stale-state":         "[WARN] cf dev was not stopped cleanly and left {{.Paths}} behind. Run 'cf dev doctor --fix' to remove them",
+	"doctor.stale-state-fixed":   "[FIXED] Removed {{.Paths}}",
+	"doctor.vm-unknown":          "[WARN] Could not tell whether the vm is running, so {{.Check}} went unchecked: {{.Error}}",
+	"doctor.defender-ok":         "[OK] Antivirus exclusions",
+	"doctor.defender-missing":    "[WARN] Windows Defender scans {{.Paths}}, which slows down starting and deploying. Run 'cf dev doctor --fix' to exclude them",
+	"doctor.defender-fixed":      "[FIXED] Excluded {{.Paths}} from Windows Defender scanning",
+	"doctor.defender-unknown":    "[WARN] Could not tell whether Windows Defender scans the cf dev directories, so antivirus exclusions went unchecked: {{.Error}}. Run 'cf dev doctor' from an admin shell to check them",
+	"doctor.quirks-ok":           "[OK] Known host issues",
+	"doctor.quirk":               "[WARN] {{.Guidance}}",
+	"doctor.check-failed":        "[FAIL] {{.Error}}",
+
+	"preflight.free-memory":            "Only {{.Available}} MB of RAM is free but the vm is allocated {{.Required}} MB",
+	"preflight.free-disk":              "Only {{.Available}} MB of disk is free under {{.Dir}} but at least {{.Required}} MB is needed",
+	"preflight.hyperv-feature":         "The Microsoft-Hyper-V feature is not enabled",
+	"preflight.hypervisor-launch-type": "Hyper-V is enabled but the hypervisor is not loaded at boot. Run 'bcdedit /set hypervisorlaunchtype auto' and restart",
+	"preflight.conflicting-drivers":    "The {{.Drivers}} driver is running and may conflict with Hyper-V",
+	"preflight.partitionable-gpu":      "A GPU was requested but this machine has no GPU that supports partitioning",
+	"preflight.qemu":                   "{{.Binary}} was not found. Install QEMU or set CFDEV_QEMU_BINARY to its path",
+	"preflight.gpu-unsupported-qemu":   "A GPU was requested but the QEMU backend cannot pass one through, so the vm will use software rendering",
+	"preflight.gpu-unsupported":        "A GPU was requested but hyperkit cannot pass one through, so the vm will use software rendering",
+
+	"quotas.relaxed": "The default quota no longer limits memory or app instances",
+
+	"quirks.hypervisor-framework":   "macOS does not allow this machine to use the Hypervisor framework, which cf dev needs to run its vm. This is usually because the hardware is too old or macOS is itself running in a vm without nested virtualization",
+	"quirks.virtualbox-coexistence": "VirtualBox {{.Version}} cannot run vms while Hyper-V is enabled. Upgrade to VirtualBox 6.0 or later to use both",
+	"quirks.amd-v-windows-build":    "Hyper-V is unreliable on AMD processors before Windows 10 build 17763, and this is build {{.Build}}. Please update Windows",
+	"quirks.kvm":                    "/dev/kvm cannot be opened, so QEMU would emulate the vm many times slower than it runs with KVM. Enable virtualization in the firmware, load the kvm module and add yourself to the group that owns /dev/kvm, or set CFDEV_QEMU_ACCEL=tcg to run without it",
+
+	"version.file-not-found":     "{{.Path}}: file not found",
+	"version.metadata-not-found": "Metadata not found version unknown",
+}
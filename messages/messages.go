@@ -0,0 +1,121 @@
+package messages
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Catalog maps message ids to their text. Text may contain text/template
+// actions (e.g. {{.Name}}) which are rendered with the data passed to T.
+type Catalog map[string]string
+
+const DefaultLocale = "en"
+
+var (
+	mutex    sync.RWMutex
+	catalogs = map[string]Catalog{DefaultLocale: English}
+	locale   = detectLocale()
+)
+
+// Register merges a catalog into the messages known for locale. Entries
+// replace any existing ones with the same id, so wrappers may also use it to
+// remap the English defaults.
+func Register(locale string, catalog Catalog) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	locale = normalize(locale)
+	existing, ok := catalogs[locale]
+	if !ok {
+		existing = Catalog{}
+		catalogs[locale] = existing
+	}
+	for id, text := range catalog {
+		existing[id] = text
+	}
+}
+
+func SetLocale(l string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	locale = normalize(l)
+}
+
+func Locale() string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return locale
+}
+
+// T returns the message for id in the current locale, falling back to the
+// language without region, then to English, then to the id itself.
+func T(id string, data ...map[string]interface{}) string {
+	mutex.RLock()
+	text, ok := lookup(id)
+	mutex.RUnlock()
+	if !ok {
+		return id
+	}
+
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+
+	values := map[string]interface{}{}
+	for _, d := range data {
+		for k, v := range d {
+			values[k] = v
+		}
+	}
+
+	tmpl, err := template.New(id).Parse(text)
+	if err != nil {
+		return text
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return text
+	}
+	return buf.String()
+}
+
+func lookup(id string) (string, bool) {
+	candidates := []string{locale}
+	if i := strings.Index(locale, "_"); i > 0 {
+		candidates = append(candidates, locale[:i])
+	}
+	candidates = append(candidates, DefaultLocale)
+
+	for _, l := range candidates {
+		if text, ok := catalogs[l][id]; ok {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+func detectLocale() string {
+	for _, name := range []string{"CFDEV_LOCALE", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return normalize(value)
+		}
+	}
+	return DefaultLocale
+}
+
+// normalize turns values such as "de_DE.UTF-8" or "pt-BR" into "de_DE" and "pt_BR"
+func normalize(l string) string {
+	if i := strings.IndexAny(l, ".@"); i >= 0 {
+		l = l[:i]
+	}
+	l = strings.Replace(l, "-", "_", -1)
+
+	switch l {
+	case "", "C", "POSIX":
+		return DefaultLocale
+	}
+	return l
+}
@@ -0,0 +1,57 @@
+package messages_test
+
+import (
+	"code.cloudfoundry.org/cfdev/messages"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Messages", func() {
+	var previousLocale string
+
+	BeforeEach(func() {
+		previousLocale = messages.Locale()
+		messages.SetLocale("en")
+	})
+
+	AfterEach(func() {
+		messages.SetLocale(previousLocale)
+	})
+
+	It("returns the english text by default", func() {
+		Expect(messages.T("start.creating-vm")).To(Equal("Creating the VM..."))
+	})
+
+	It("renders template data", func() {
+		Expect(messages.T("start.stopped-unexpectedly", map[string]interface{}{"Name": "vpnkit"})).To(Equal("ERROR: vpnkit has stopped"))
+	})
+
+	It("returns the id for unknown messages", func() {
+		Expect(messages.T("some.unknown-id")).To(Equal("some.unknown-id"))
+	})
+
+	Context("when a translation is registered", func() {
+		BeforeEach(func() {
+			messages.Register("de", messages.Catalog{
+				"start.creating-vm": "VM wird erstellt...",
+			})
+		})
+
+		It("uses the translation for the matching language", func() {
+			messages.SetLocale("de_DE.UTF-8")
+			Expect(messages.T("start.creating-vm")).To(Equal("VM wird erstellt..."))
+		})
+
+		It("falls back to english for missing entries", func() {
+			messages.SetLocale("de")
+			Expect(messages.T("start.starting-vm")).To(Equal("Starting the VM..."))
+		})
+	})
+
+	It("allows wrappers to remap the english defaults", func() {
+		messages.Register("en", messages.Catalog{"telemetry.on": "Usage data is being shared"})
+		defer messages.Register("en", messages.Catalog{"telemetry.on": "Telemetry is turned ON"})
+
+		Expect(messages.T("telemetry.on")).To(Equal("Usage data is being shared"))
+	})
+})
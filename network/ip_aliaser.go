@@ -5,8 +5,8 @@ import "code.cloudfoundry.org/cfdev/runner"
 //go:generate mockgen -package mocks -destination mocks/cfdevd_client.go code.cloudfoundry.org/cfdev/network CfdevdClient
 type CfdevdClient interface {
 	Uninstall() (string, error)
-	AddIPAlias() (string, error)
-	RemoveIPAlias() (string, error)
+	AddIPAlias(addrs ...string) (string, error)
+	RemoveIPAlias(addrs ...string) (string, error)
 }
 
 type HostNet struct {
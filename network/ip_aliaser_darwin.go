@@ -5,7 +5,7 @@ import "fmt"
 const loopback = "lo0"
 
 func (h *HostNet) RemoveLoopbackAliases(addrs ...string) error {
-	_, err := h.CfdevdClient.RemoveIPAlias()
+	_, err := h.CfdevdClient.RemoveIPAlias(addrs...)
 	if err != nil {
 		return err
 	}
@@ -15,7 +15,7 @@ func (h *HostNet) RemoveLoopbackAliases(addrs ...string) error {
 
 func (h *HostNet) AddLoopbackAliases(addrs ...string) error {
 	fmt.Println("Setting up IP aliases for the BOSH Director & CF Router (requires administrator privileges)")
-	_, err := h.CfdevdClient.AddIPAlias()
+	_, err := h.CfdevdClient.AddIPAlias(addrs...)
 	if err != nil {
 		return err
 	}
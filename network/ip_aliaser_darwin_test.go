@@ -32,15 +32,15 @@ var _ = Describe("IP Aliaser - Darwin", func() {
 
 	Describe("AddLoopbackAliases", func() {
 		It("calls cfdevd.AddLoopbackAliases", func() {
-			mockCfdevdClient.EXPECT().AddIPAlias()
-			Expect(hostnet.AddLoopbackAliases()).To(Succeed())
+			mockCfdevdClient.EXPECT().AddIPAlias("10.245.0.4", "10.245.0.34")
+			Expect(hostnet.AddLoopbackAliases("10.245.0.4", "10.245.0.34")).To(Succeed())
 		})
 	})
 
 	Describe("RemoveLoopbackAliases", func() {
 		It("calls cfdevd.RemoveLoopbackAliases", func() {
-			mockCfdevdClient.EXPECT().RemoveIPAlias()
-			Expect(hostnet.RemoveLoopbackAliases()).To(Succeed())
+			mockCfdevdClient.EXPECT().RemoveIPAlias("10.245.0.4", "10.245.0.34")
+			Expect(hostnet.RemoveLoopbackAliases("10.245.0.4", "10.245.0.34")).To(Succeed())
 		})
 	})
 })
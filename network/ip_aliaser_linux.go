@@ -0,0 +1,31 @@
+package network
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const loopback = "lo"
+
+func (h *HostNet) RemoveLoopbackAliases(addrs ...string) error {
+	for _, addr := range addrs {
+		output, err := exec.Command("sudo", "ip", "addr", "del", addr+"/32", "dev", loopback).CombinedOutput()
+		if err != nil && !strings.Contains(string(output), "Cannot assign requested address") {
+			return fmt.Errorf("failed to remove network alias: %s, %s, %s", addr, err, output)
+		}
+	}
+	return nil
+}
+
+func (h *HostNet) AddLoopbackAliases(addrs ...string) error {
+	fmt.Println("Setting up IP aliases for the BOSH Director & CF Router (requires administrator privileges)")
+
+	for _, addr := range addrs {
+		output, err := exec.Command("sudo", "ip", "addr", "add", addr+"/32", "dev", loopback).CombinedOutput()
+		if err != nil && !strings.Contains(string(output), "File exists") {
+			return fmt.Errorf("failed to add network alias: %s, %s, %s", addr, err, output)
+		}
+	}
+	return nil
+}
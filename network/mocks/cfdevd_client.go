@@ -33,29 +33,37 @@ func (m *MockCfdevdClient) EXPECT() *MockCfdevdClientMockRecorder {
 }
 
 // AddIPAlias mocks base method
-func (m *MockCfdevdClient) AddIPAlias() (string, error) {
-	ret := m.ctrl.Call(m, "AddIPAlias")
+func (m *MockCfdevdClient) AddIPAlias(arg0 ...string) (string, error) {
+	varargs := []interface{}{}
+	for _, a := range arg0 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AddIPAlias", varargs...)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddIPAlias indicates an expected call of AddIPAlias
-func (mr *MockCfdevdClientMockRecorder) AddIPAlias() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddIPAlias", reflect.TypeOf((*MockCfdevdClient)(nil).AddIPAlias))
+func (mr *MockCfdevdClientMockRecorder) AddIPAlias(arg0 ...interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddIPAlias", reflect.TypeOf((*MockCfdevdClient)(nil).AddIPAlias), arg0...)
 }
 
 // RemoveIPAlias mocks base method
-func (m *MockCfdevdClient) RemoveIPAlias() (string, error) {
-	ret := m.ctrl.Call(m, "RemoveIPAlias")
+func (m *MockCfdevdClient) RemoveIPAlias(arg0 ...string) (string, error) {
+	varargs := []interface{}{}
+	for _, a := range arg0 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoveIPAlias", varargs...)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RemoveIPAlias indicates an expected call of RemoveIPAlias
-func (mr *MockCfdevdClientMockRecorder) RemoveIPAlias() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveIPAlias", reflect.TypeOf((*MockCfdevdClient)(nil).RemoveIPAlias))
+func (mr *MockCfdevdClientMockRecorder) RemoveIPAlias(arg0 ...interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveIPAlias", reflect.TypeOf((*MockCfdevdClient)(nil).RemoveIPAlias), arg0...)
 }
 
 // Uninstall mocks base method
@@ -0,0 +1,25 @@
+package network
+
+import (
+	"net"
+	"time"
+)
+
+// Probe looks at the host's network from the outside, the way the cf cli
+// and the vm will see it.
+type Probe struct{}
+
+// LookupHost resolves host with the host's resolver.
+func (Probe) LookupHost(host string) ([]string, error) {
+	return net.LookupHost(host)
+}
+
+// Listening reports whether something accepts connections on address.
+func (Probe) Listening(address string) bool {
+	conn, err := net.DialTimeout("tcp", address, 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,112 @@
+package network
+
+import (
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/cfdev/config"
+)
+
+// ParseNetstat reads the routes printed by netstat -rn -f inet on macOS,
+// leaving out those through the interface skip. netstat drops the trailing
+// zeros of a destination, and its mask when it covers just the octets
+// left, e.g. 10.144/16 or 192.168.1.
+func ParseNetstat(output, skip string) []config.Route {
+	var routes []config.Route
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[3] == skip {
+			continue
+		}
+
+		destination := parseNetstatDestination(fields[0])
+		if destination == nil {
+			continue
+		}
+		routes = append(routes, config.Route{Destination: destination, Interface: fields[3]})
+	}
+	return routes
+}
+
+func parseNetstatDestination(destination string) *net.IPNet {
+	parts := strings.SplitN(destination, "/", 2)
+	octets := strings.Split(parts[0], ".")
+	if len(octets) > 4 {
+		return nil
+	}
+
+	ip := make(net.IP, 4)
+	for i, octet := range octets {
+		value, err := strconv.Atoi(octet)
+		if err != nil || value < 0 || value > 255 {
+			return nil
+		}
+		ip[i] = byte(value)
+	}
+
+	ones := 8 * len(octets)
+	if len(parts) == 2 {
+		var err error
+		if ones, err = strconv.Atoi(parts[1]); err != nil || ones < 0 || ones > 32 {
+			return nil
+		}
+	}
+
+	mask := net.CIDRMask(ones, 32)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+}
+
+// ParseNetRoutes reads lines of a destination prefix and the interface it
+// is routed through, as printed for Get-NetRoute on Windows, leaving out
+// those through the interface skip.
+func ParseNetRoutes(output, skip string) []config.Route {
+	var routes []config.Route
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if len(fields) < 2 || fields[1] == skip {
+			continue
+		}
+
+		_, destination, err := net.ParseCIDR(fields[0])
+		if err != nil || destination.IP.To4() == nil {
+			continue
+		}
+		routes = append(routes, config.Route{Destination: destination, Interface: fields[1]})
+	}
+	return routes
+}
+
+// ParseProcRoutes reads the routes in /proc/net/route on linux, leaving out
+// those through the interface skip. The kernel prints each destination and
+// mask as a hex word in its own byte order, which is little endian on the
+// hosts cf dev runs on.
+func ParseProcRoutes(output, skip string) []config.Route {
+	var routes []config.Route
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 8 || fields[0] == skip {
+			continue
+		}
+
+		destination, err := strconv.ParseUint(fields[1], 16, 32)
+		if err != nil {
+			continue
+		}
+		mask, err := strconv.ParseUint(fields[7], 16, 32)
+		if err != nil {
+			continue
+		}
+
+		ip := make(net.IP, 4)
+		binary.LittleEndian.PutUint32(ip, uint32(destination))
+		ipMask := make(net.IPMask, 4)
+		binary.LittleEndian.PutUint32(ipMask, uint32(mask))
+		routes = append(routes, config.Route{
+			Destination: &net.IPNet{IP: ip.Mask(ipMask), Mask: ipMask},
+			Interface:   fields[0],
+		})
+	}
+	return routes
+}
@@ -0,0 +1,17 @@
+package network
+
+import (
+	"os/exec"
+
+	"code.cloudfoundry.org/cfdev/config"
+)
+
+// Routes are the host's routes, except those to the loopback, where cf
+// dev's own addresses are aliased.
+func (h *HostNet) Routes() ([]config.Route, error) {
+	output, err := exec.Command("netstat", "-rn", "-f", "inet").Output()
+	if err != nil {
+		return nil, err
+	}
+	return ParseNetstat(string(output), loopback), nil
+}
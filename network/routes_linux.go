@@ -0,0 +1,17 @@
+package network
+
+import (
+	"io/ioutil"
+
+	"code.cloudfoundry.org/cfdev/config"
+)
+
+// Routes are the host's routes, except those to the loopback, where cf
+// dev's own addresses are aliased.
+func (h *HostNet) Routes() ([]config.Route, error) {
+	output, err := ioutil.ReadFile("/proc/net/route")
+	if err != nil {
+		return nil, err
+	}
+	return ParseProcRoutes(string(output), loopback), nil
+}
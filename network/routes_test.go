@@ -0,0 +1,66 @@
+package network_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/network"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	destinations := func(routes []config.Route) []string {
+		var result []string
+		for _, route := range routes {
+			result = append(result, route.Destination.String()+" "+route.Interface)
+		}
+		return result
+	}
+
+	Describe("ParseNetstat", func() {
+		It("reads the routes, filling in what netstat leaves out", func() {
+			output := `Routing tables
+
+Internet:
+Destination        Gateway            Flags        Netif Expire
+default            192.168.1.1        UGScg          en0
+10.144/16          10.8.0.1           UGSc         utun3
+10.144.0.4         10.144.0.4         UH             lo0
+127                127.0.0.1          UCS            lo0
+192.168.1          link#6             UCS            en0      !
+192.168.1.1/32     link#6             UCS            en0      !
+`
+			Expect(destinations(network.ParseNetstat(output, "lo0"))).To(Equal([]string{
+				"10.144.0.0/16 utun3",
+				"192.168.1.0/24 en0",
+				"192.168.1.1/32 en0",
+			}))
+		})
+	})
+
+	Describe("ParseNetRoutes", func() {
+		It("reads the routes, keeping interfaces with spaces in their names", func() {
+			output := "0.0.0.0/0 Wi-Fi\r\n10.144.0.0/16 Ethernet 2\r\n10.144.0.4/32 vEthernet (cfdev)\r\nff00::/8 Wi-Fi\r\n"
+
+			Expect(destinations(network.ParseNetRoutes(output, "vEthernet (cfdev)"))).To(Equal([]string{
+				"0.0.0.0/0 Wi-Fi",
+				"10.144.0.0/16 Ethernet 2",
+			}))
+		})
+	})
+
+	Describe("ParseProcRoutes", func() {
+		It("reads the routes, turning the kernel's hex words into addresses", func() {
+			output := "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n" +
+				"wlan0\t00000000\t0101A8C0\t0003\t0\t0\t600\t00000000\t0\t0\t0\n" +
+				"tun0\t0000900A\t0100080A\t0003\t0\t0\t50\t0000FFFF\t0\t0\t0\n" +
+				"lo\t0400900A\t00000000\t0005\t0\t0\t0\tFFFFFFFF\t0\t0\t0\n" +
+				"wlan0\t0001A8C0\t00000000\t0001\t0\t0\t600\t00FFFFFF\t0\t0\t0\n"
+
+			Expect(destinations(network.ParseProcRoutes(output, "lo"))).To(Equal([]string{
+				"0.0.0.0/0 wlan0",
+				"10.144.0.0/16 tun0",
+				"192.168.1.0/24 wlan0",
+			}))
+		})
+	})
+})
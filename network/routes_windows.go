@@ -0,0 +1,14 @@
+package network
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+)
+
+// Routes are the host's routes, except those to cf dev's own switch.
+func (h *HostNet) Routes() ([]config.Route, error) {
+	output, err := h.Powershell.Output("Get-NetRoute -AddressFamily IPv4 | ForEach-Object { $_.DestinationPrefix + ' ' + $_.InterfaceAlias }")
+	if err != nil {
+		return nil, err
+	}
+	return ParseNetRoutes(output, h.loopback()), nil
+}
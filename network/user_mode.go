@@ -0,0 +1,19 @@
+package network
+
+// UserMode stands in for vpnkit when the hypervisor networks the vm itself,
+// as QEMU does with its user mode network, so there is nothing to run.
+type UserMode struct{}
+
+func (UserMode) Start() error {
+	return nil
+}
+
+func (UserMode) Stop() error {
+	return nil
+}
+
+func (UserMode) Destroy() error {
+	return nil
+}
+
+func (UserMode) Watch(exit chan string) {}
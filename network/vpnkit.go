@@ -10,7 +10,6 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"time"
 )
 
 const VpnKitLabel = "org.cloudfoundry.cfdev.vpnkit"
@@ -38,16 +37,7 @@ func (v *VpnKit) Stop() error {
 }
 
 func (v *VpnKit) Watch(exit chan string) {
-	go func() {
-		for {
-			running, err := v.DaemonRunner.IsRunning(v.Label)
-			if !running && err == nil {
-				exit <- "vpnkit"
-				return
-			}
-			time.Sleep(5 * time.Second)
-		}
-	}()
+	daemon.Watch(v.DaemonRunner.IsRunning, v.Label, "vpnkit", exit)
 }
 
 func (v *VpnKit) writeHttpConfig() error {
@@ -66,8 +56,9 @@ func (v *VpnKit) writeHttpConfig() error {
 		}
 	}
 
+	// Only the owner may read it, as it has the proxy's credentials.
 	httpProxyConfig := []byte(proxyContents)
-	err = ioutil.WriteFile(httpProxyPath, httpProxyConfig, 0777)
+	err = ioutil.WriteFile(httpProxyPath, httpProxyConfig, 0600)
 	if err != nil {
 		return err
 	}
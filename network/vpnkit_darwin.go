@@ -59,6 +59,7 @@ func (v *VpnKit) daemonSpec() daemon.DaemonSpec {
 		RunAtLoad:  false,
 		StdoutPath: path.Join(v.Config.LogDir, "vpnkit.stdout.log"),
 		StderrPath: path.Join(v.Config.LogDir, "vpnkit.stderr.log"),
+		KeepAlive:  true,
 	}
 }
 
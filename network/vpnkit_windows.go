@@ -19,7 +19,7 @@ func (v *VpnKit) Start() error {
 		return errors.SafeWrap(err, "Failed to Setup VPNKit")
 	}
 
-	output, err := v.Powershell.Output("((Get-VM -Name cfdev).Id).Guid")
+	output, err := v.Powershell.Output(fmt.Sprintf("((Get-VM -Name '%s').Id).Guid", v.Config.VMName()))
 	if err != nil {
 		return fmt.Errorf("get vm name: %s", err)
 	}
@@ -71,6 +71,7 @@ func (v *VpnKit) daemonSpec(vmGuid string) daemon.DaemonSpec {
 		RunAtLoad:  false,
 		StdoutPath: path.Join(v.Config.LogDir, "vpnkit.stdout.log"),
 		StderrPath: path.Join(v.Config.LogDir, "vpnkit.stderr.log"),
+		KeepAlive:  true,
 	}
 }
 
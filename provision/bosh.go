@@ -44,6 +44,34 @@ func (c *Controller) DeployBosh() error {
 		"state.json",
 		"creds.yml")
 
+	networkVars, err := DirectorNetworkVars(c.Config.BoshDirectorIP, c.Config.CFSubnet)
+	if err != nil {
+		return err
+	}
+	command += " " + networkVars
+
+	proxyOpsFile, err := ProxyOpsFile(c.proxy())
+	if err != nil {
+		return err
+	}
+	if proxyOpsFile != nil {
+		proxyOpsFilePath := filepath.Join(c.Config.StateBosh, "proxy.yml")
+		if err := ioutil.WriteFile(proxyOpsFilePath, proxyOpsFile, 0600); err != nil {
+			return err
+		}
+		if err := s.CopyFile(proxyOpsFilePath, "proxy.yml", ssh.SSHAddress{
+			IP:   "127.0.0.1",
+			Port: "9992",
+		},
+			key,
+			20*time.Second,
+			logFile,
+			logFile); err != nil {
+			return err
+		}
+		command += " --ops-file proxy.yml"
+	}
+
 	// TODO: Added the time because we were seeing some delay between the time the container
 	// was started and the time it could access the internet
 	// Find a better solution
@@ -65,10 +93,15 @@ func (c *Controller) DeployBosh() error {
 		return err
 	}
 
-	return s.RetrieveFile(
+	err = s.RetrieveFile(
 		filepath.Join(c.Config.StateBosh, "state.json"),
 		"/root/state.json",
 		ssh.SSHAddress{IP: "127.0.0.1", Port: "9992"},
 		key,
 		20*time.Second)
+	if err != nil {
+		return err
+	}
+
+	return c.waitForDirector()
 }
@@ -0,0 +1,53 @@
+package provision
+
+import (
+	"sort"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+)
+
+// setDeploying records whether the deployment is being deployed, so that
+// CancelDeploy can cancel it. Services deployed side by side each record
+// their own.
+func (c *Controller) setDeploying(deployment string, deploying bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.deploying == nil {
+		c.deploying = map[string]bool{}
+	}
+	if deploying {
+		c.deploying[deployment] = true
+	} else {
+		delete(c.deploying, deployment)
+	}
+}
+
+// CancelDeploy cancels the director's tasks for the deployments being
+// deployed, if there are any. Without this an interrupted deploy keeps
+// running in the vm and holds the deployment's lock.
+func (c *Controller) CancelDeploy() error {
+	c.mu.Lock()
+	var deployments []string
+	for deployment := range c.deploying {
+		deployments = append(deployments, deployment)
+	}
+	c.mu.Unlock()
+
+	if len(deployments) == 0 {
+		return nil
+	}
+	sort.Strings(deployments)
+
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return err
+	}
+
+	var reterr error
+	for _, deployment := range deployments {
+		if err := b.CancelCurrentTasks(deployment); err != nil {
+			reterr = err
+		}
+	}
+	return reterr
+}
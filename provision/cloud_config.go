@@ -0,0 +1,23 @@
+package provision
+
+import "code.cloudfoundry.org/cfdev/bosh"
+
+// CloudConfig returns the director's cloud config.
+func (c *Controller) CloudConfig() (string, error) {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return "", err
+	}
+
+	return b.CloudConfig()
+}
+
+// UpdateCloudConfig replaces the director's cloud config.
+func (c *Controller) UpdateCloudConfig(manifest []byte) error {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return err
+	}
+
+	return b.UpdateCloudConfig(manifest)
+}
@@ -2,7 +2,9 @@ package provision
 
 import (
 	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/config"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -29,6 +31,31 @@ func (c *Controller) DeployCloudFoundry(ui UI, dockerRegistries []string) error
 	}
 
 	cmd.Env = append(cmd.Env, `DOCKER_REGISTRIES=[`+strings.Join(arr, ",")+"]")
+	cmd.Env = append(cmd.Env, gardenEnvs(c.Config.Garden)...)
+
+	opsFile, err := CFOpsFile(c.Config)
+	if err != nil {
+		return err
+	}
+	if opsFile != nil {
+		opsFilePath := filepath.Join(c.Config.StateDir, "cf-component-properties.yml")
+		if err := ioutil.WriteFile(opsFilePath, opsFile, 0600); err != nil {
+			return err
+		}
+		cmd.Env = append(cmd.Env, "CF_OPS_FILE="+opsFilePath)
+	}
+
+	cloudConfigOpsFile, err := CloudConfigOpsFile(c.Config)
+	if err != nil {
+		return err
+	}
+	if cloudConfigOpsFile != nil {
+		opsFilePath := filepath.Join(c.Config.StateDir, "cloud-config-network.yml")
+		if err := ioutil.WriteFile(opsFilePath, cloudConfigOpsFile, 0600); err != nil {
+			return err
+		}
+		cmd.Env = append(cmd.Env, "CLOUD_CONFIG_OPS_FILE="+opsFilePath)
+	}
 
 	logFile, err := os.Create(filepath.Join(c.Config.LogDir, "deploy-cf.log"))
 	if err != nil {
@@ -49,9 +76,30 @@ func (c *Controller) DeployCloudFoundry(ui UI, dockerRegistries []string) error
 		errChan <- cmd.Run()
 	}()
 
-	return c.report(time.Now(), ui, b, Service{
+	if err := c.report(time.Now(), ui, b, Service{
 		Name:       "cf",
 		Deployment: "cf",
 		IsErrand:   false,
-	}, errChan)
+	}, errChan); err != nil {
+		return err
+	}
+
+	if err := c.ApplyProxy(); err != nil {
+		return err
+	}
+	return c.ApplyQuotas()
+}
+
+func gardenEnvs(garden config.GardenConfig) []string {
+	var envs []string
+	if garden.MaxContainers > 0 {
+		envs = append(envs, fmt.Sprintf("GARDEN_MAX_CONTAINERS=%d", garden.MaxContainers))
+	}
+	if garden.DiskQuotaMB > 0 {
+		envs = append(envs, fmt.Sprintf("GARDEN_DISK_QUOTA_MB=%d", garden.DiskQuotaMB))
+	}
+	if garden.GraceTime != "" {
+		envs = append(envs, "GARDEN_GRACE_TIME="+garden.GraceTime)
+	}
+	return envs
 }
@@ -3,8 +3,12 @@ package provision
 import (
 	"code.cloudfoundry.org/cfdev/config"
 	"context"
+	"crypto/tls"
 	"github.com/aemengo/bosh-runc-cpi/client"
 	"io"
+	"net/http"
+	"sync"
+	"time"
 )
 
 type UI interface {
@@ -14,6 +18,15 @@ type UI interface {
 
 type Controller struct {
 	Config config.Config
+	// TaskLogs receives the event logs of the director tasks run while
+	// deploying, when set.
+	TaskLogs io.Writer
+	// Events receives each progress event as a line of json, in place of
+	// the progress line, when set.
+	Events io.Writer
+
+	mu        sync.Mutex
+	deploying map[string]bool
 }
 
 func NewController(config config.Config) *Controller {
@@ -22,7 +35,31 @@ func NewController(config config.Config) *Controller {
 	}
 }
 
+// EnableTaskLogs has deploys write the director's task events to w, so
+// compiling and other long steps are visible as they happen.
+func (c *Controller) EnableTaskLogs(w io.Writer) {
+	c.TaskLogs = w
+}
+
+// EnableJSONProgress has deploys write their progress to w as json, for
+// tools that drive cf dev rather than people.
+func (c *Controller) EnableJSONProgress(w io.Writer) {
+	c.Events = w
+}
+
 func (c *Controller) Ping() error {
 	ctx := context.Background()
 	return client.Ping(ctx, "127.0.0.1:9999")
 }
+
+// httpClient talks to the deployment, whose certificates are self signed.
+func httpClient() *http.Client {
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package provision
+
+import (
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+)
+
+// DeleteService deletes the deployment of a service, leaving cf and any
+// other services in place.
+func (c *Controller) DeleteService(ui UI, service Service) error {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	ui.Say(messages.T("provision.deleting-service", map[string]interface{}{"Service": service.Name}))
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- b.DeleteDeployment(service.Deployment)
+	}()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-errChan:
+			if err != nil {
+				return errors.SafeWrap(err, fmt.Sprintf("Failed to delete %s", service.Name))
+			}
+
+			ui.Writer().Write([]byte("\r\033[K" + messages.T("provision.done-after", map[string]interface{}{"Duration": time.Now().Sub(start).Round(time.Second)}) + "\n"))
+			return c.ForgetDeployed(service.Deployment)
+		case <-ticker.C:
+			if p, err := b.DeleteProgress(start, service.Deployment); err == nil {
+				ui.Writer().Write([]byte("\r\033[K" + messages.T("provision.vms-left", map[string]interface{}{"Count": p.Total, "Duration": p.Duration.Round(time.Second)})))
+			}
+		}
+	}
+}
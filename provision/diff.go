@@ -0,0 +1,34 @@
+package provision
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+)
+
+// manifestPath is where a service keeps its manifest, in the directory of
+// assets named after its deployment.
+func (c *Controller) manifestPath(service Service) string {
+	return filepath.Join(c.Config.ServicesDir, service.Deployment, "manifest.yml")
+}
+
+// DiffService previews the changes deploying the service's manifest would
+// make to its deployment.
+func (c *Controller) DiffService(service Service) ([]string, error) {
+	manifest, err := ioutil.ReadFile(c.manifestPath(service))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s has no manifest at %s to compare", service.Name, c.manifestPath(service))
+	} else if err != nil {
+		return nil, err
+	}
+
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Diff(service.Deployment, manifest)
+}
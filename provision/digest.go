@@ -0,0 +1,154 @@
+package provision
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// deploymentsFile records the digest of the assets each deployment was last
+// deployed from. It lives beside the state dir rather than in it, so that it
+// outlives the state a start replaces. A fresh vm's director has none of the
+// deployments, so provisioning only compares those the director has.
+const deploymentsFile = "deployments.json"
+
+// DeployedDigests returns the digests recorded for the deployments on the
+// vm, keyed by deployment name.
+func (c *Controller) DeployedDigests() (map[string]string, error) {
+	digests := map[string]string{}
+
+	contents, err := ioutil.ReadFile(c.digestsPath())
+	if os.IsNotExist(err) {
+		return digests, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(contents, &digests); err != nil {
+		return nil, err
+	}
+	return digests, nil
+}
+
+// RecordDeployed records that deployment is now deployed from the assets
+// with the given digest.
+func (c *Controller) RecordDeployed(deployment string, digest string) error {
+	digests, err := c.DeployedDigests()
+	if err != nil {
+		return err
+	}
+	digests[deployment] = digest
+
+	return c.writeDigests(digests)
+}
+
+// ForgetDeployed drops the digest recorded for a deployment that has been
+// deleted, so that it is deployed again when next asked for.
+func (c *Controller) ForgetDeployed(deployment string) error {
+	digests, err := c.DeployedDigests()
+	if err != nil {
+		return err
+	}
+	delete(digests, deployment)
+
+	return c.writeDigests(digests)
+}
+
+func (c *Controller) writeDigests(digests map[string]string) error {
+	contents, err := json.Marshal(digests)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.digestsPath(), contents, 0600)
+}
+
+func (c *Controller) digestsPath() string {
+	return filepath.Join(filepath.Dir(c.Config.StateDir), deploymentsFile)
+}
+
+// CFDigest covers the cf deploy script and its assets, along with the
+// configuration it is deployed with.
+func (c *Controller) CFDigest(dockerRegistries []string) (string, error) {
+	opsFile, err := CFOpsFile(c.Config)
+	if err != nil {
+		return "", err
+	}
+	cloudConfigOpsFile, err := CloudConfigOpsFile(c.Config)
+	if err != nil {
+		return "", err
+	}
+
+	extra := []string{
+		"registries=" + strings.Join(dockerRegistries, ","),
+		"garden=" + strings.Join(gardenEnvs(c.Config.Garden), ","),
+		"ops=" + string(opsFile),
+	}
+	if cloudConfigOpsFile != nil {
+		extra = append(extra, "cloud-config-ops="+string(cloudConfigOpsFile))
+	}
+	return c.digest("cf", "deploy-cf", extra...)
+}
+
+// ServiceDigest covers a service's deploy script and its assets, along
+// with the network it is deployed on.
+func (c *Controller) ServiceDigest(service Service) (string, error) {
+	if c.Config.ServicesSubnet == "" {
+		return c.digest(service.Deployment, service.Script)
+	}
+	return c.digest(service.Deployment, service.Script, "services-subnet="+c.Config.ServicesSubnet)
+}
+
+// digest hashes the deploy script, on either platform, every file under
+// the services dir directory named after the deployment, if there is one,
+// and any extra inputs.
+func (c *Controller) digest(deployment string, script string, extra ...string) (string, error) {
+	paths := []string{
+		filepath.Join(c.Config.ServicesDir, script),
+		filepath.Join(c.Config.ServicesDir, script+".ps1"),
+	}
+
+	err := filepath.Walk(filepath.Join(c.Config.ServicesDir, deployment), func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		rel, _ := filepath.Rel(c.Config.ServicesDir, path)
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, e := range extra {
+		fmt.Fprintf(h, "%s\x00", e)
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,100 @@
+package provision_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/provision"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Deployment digests", func() {
+	var (
+		dir        string
+		controller *provision.Controller
+		service    provision.Service
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "cfdev-digest")
+		Expect(err).NotTo(HaveOccurred())
+
+		controller = provision.NewController(config.Config{
+			StateDir:       filepath.Join(dir, "state"),
+			ServicesDir:    filepath.Join(dir, "services"),
+			BoshDirectorIP: config.DefaultBoshDirectorIP,
+			CFRouterIP:     config.DefaultCFRouterIP,
+			CFSubnet:       config.DefaultCFSubnet,
+		})
+		Expect(os.MkdirAll(filepath.Join(dir, "state"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(dir, "services", "bin"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(dir, "services", "cf-mysql"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "services", "bin", "deploy-mysql"), []byte("some-script"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "services", "cf-mysql", "manifest.yml"), []byte("some-manifest"), 0644)).To(Succeed())
+
+		service = provision.Service{Name: "Mysql", Script: "bin/deploy-mysql", Deployment: "cf-mysql"}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("changes when a deployment's assets change", func() {
+		before, err := controller.ServiceDigest(service)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(controller.ServiceDigest(service)).To(Equal(before))
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "services", "cf-mysql", "manifest.yml"), []byte("some-other-manifest"), 0644)).To(Succeed())
+		Expect(controller.ServiceDigest(service)).NotTo(Equal(before))
+	})
+
+	It("changes when cf is deployed with other registries", func() {
+		before, err := controller.CFDigest(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(controller.CFDigest([]string{"some-registry"})).NotTo(Equal(before))
+	})
+
+	It("changes when the network cf and the services are deployed on moves", func() {
+		cfBefore, err := controller.CFDigest(nil)
+		Expect(err).NotTo(HaveOccurred())
+		serviceBefore, err := controller.ServiceDigest(service)
+		Expect(err).NotTo(HaveOccurred())
+
+		controller.Config.ServicesSubnet = "10.145.0.0/16"
+		Expect(controller.CFDigest(nil)).NotTo(Equal(cfBefore))
+		Expect(controller.ServiceDigest(service)).NotTo(Equal(serviceBefore))
+	})
+
+	It("records what has been deployed", func() {
+		Expect(controller.DeployedDigests()).To(BeEmpty())
+
+		Expect(controller.RecordDeployed("cf", "some-digest")).To(Succeed())
+		Expect(controller.RecordDeployed("cf-mysql", "some-other-digest")).To(Succeed())
+
+		Expect(controller.DeployedDigests()).To(Equal(map[string]string{
+			"cf":       "some-digest",
+			"cf-mysql": "some-other-digest",
+		}))
+	})
+
+	It("keeps the record when the state dir is replaced", func() {
+		Expect(controller.RecordDeployed("cf", "some-digest")).To(Succeed())
+
+		Expect(os.RemoveAll(filepath.Join(dir, "state"))).To(Succeed())
+		Expect(controller.DeployedDigests()).To(Equal(map[string]string{"cf": "some-digest"}))
+	})
+
+	It("forgets deployments that have been deleted", func() {
+		Expect(controller.RecordDeployed("cf", "some-digest")).To(Succeed())
+		Expect(controller.RecordDeployed("cf-mysql", "some-other-digest")).To(Succeed())
+		Expect(controller.ForgetDeployed("cf-mysql")).To(Succeed())
+
+		Expect(controller.DeployedDigests()).To(Equal(map[string]string{
+			"cf": "some-digest",
+		}))
+	})
+})
@@ -0,0 +1,175 @@
+package provision
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/ssh"
+)
+
+// DiskPressurePercent is the guest disk usage at which unused bosh packages
+// should be cleaned up, well before the disk fills up and deploys or apps
+// start failing.
+const DiskPressurePercent = 85
+
+// DiskUsage is the usage of the fullest disk backed file system in the vm.
+type DiskUsage struct {
+	Mount       string
+	UsedPercent int
+	AvailableMB uint64
+}
+
+// GuestDiskUsage reads the vm's disk usage over ssh.
+func (c *Controller) GuestDiskUsage() (DiskUsage, error) {
+	output, err := c.guestCommand("df -Pk")
+	if err != nil {
+		return DiskUsage{}, fmt.Errorf("reading guest disk usage: %s", err)
+	}
+
+	return ParseDiskUsage(output)
+}
+
+// DiskMonitorInterval is how often the monitor started by StartDiskMonitor
+// checks the guest disk.
+const DiskMonitorInterval = 5 * time.Minute
+
+// diskMonitorScript checks the fullest disk backed file system every
+// interval and has the director clean up unused packages and releases once
+// it crosses the pressure threshold. It is run in the vm, which reaches the
+// director directly, so that it keeps watching while nothing runs on the
+// host.
+const diskMonitorScript = `echo $$ > disk-monitor.pid
+while true; do
+  used=$(df -Pk | awk '$1 ~ /^\/dev\// { sub("%%", "", $5); if ($5 + 0 > max) max = $5 + 0 } END { print max + 0 }')
+  if [ "$used" -ge %d ]; then
+    echo "$(date) disk at ${used}%%, cleaning up"
+    /bosh/bosh -n clean-up --all
+  fi
+  sleep %d
+done
+`
+
+// StartDiskMonitor starts watching the guest disk from the vm, replacing
+// the monitor a previous start left running. It needs the director to be
+// deployed.
+func (c *Controller) StartDiskMonitor() error {
+	boshConfig, err := bosh.FetchConfig(c.Config)
+	if err != nil {
+		return err
+	}
+
+	var script bytes.Buffer
+	for _, envVar := range boshConfig.EnvVars() {
+		if strings.HasPrefix(envVar.Name, "BOSH_GW_") {
+			continue
+		}
+		fmt.Fprintf(&script, "export %s=%s\n", envVar.Name, shellQuote(envVar.Value))
+	}
+	fmt.Fprintf(&script, diskMonitorScript, DiskPressurePercent, int(DiskMonitorInterval/time.Second))
+
+	scriptPath := filepath.Join(c.Config.StateBosh, "disk-monitor")
+	if err := ioutil.WriteFile(scriptPath, script.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	key, err := ioutil.ReadFile(filepath.Join(c.Config.CacheDir, "id_rsa"))
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	s := ssh.SSH{}
+	err = s.CopyFile(scriptPath, "disk-monitor",
+		ssh.SSHAddress{IP: "127.0.0.1", Port: "9992"},
+		key,
+		20*time.Second,
+		ioutil.Discard,
+		&stderr,
+	)
+	if err != nil {
+		return fmt.Errorf("copying the disk monitor: %s: %s", err, stderr.String())
+	}
+
+	_, err = c.guestCommand("chmod 600 disk-monitor; " +
+		"kill $(cat disk-monitor.pid 2>/dev/null) 2>/dev/null; " +
+		"nohup sh disk-monitor >>disk-monitor.log 2>&1 </dev/null &")
+	if err != nil {
+		return fmt.Errorf("starting the disk monitor: %s", err)
+	}
+	return nil
+}
+
+// shellQuote quotes value for sh.
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+// guestCommand runs command in the vm and returns its stdout.
+func (c *Controller) guestCommand(command string) (string, error) {
+	key, err := ioutil.ReadFile(filepath.Join(c.Config.CacheDir, "id_rsa"))
+	if err != nil {
+		return "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+	s := ssh.SSH{}
+	err = s.RunSSHCommand(
+		command,
+		ssh.SSHAddress{IP: "127.0.0.1", Port: "9992"},
+		key,
+		20*time.Second,
+		&stdout,
+		&stderr,
+	)
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
+
+// ParseDiskUsage picks the fullest file system backed by a block device out
+// of the output of df -Pk.
+func ParseDiskUsage(output string) (DiskUsage, error) {
+	var (
+		fullest DiskUsage
+		found   bool
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 || !strings.HasPrefix(fields[0], "/dev/") {
+			continue
+		}
+
+		used, err := strconv.Atoi(strings.TrimSuffix(fields[4], "%"))
+		if err != nil {
+			continue
+		}
+		availableKB, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if !found || used > fullest.UsedPercent {
+			fullest = DiskUsage{
+				Mount:       fields[5],
+				UsedPercent: used,
+				AvailableMB: availableKB / 1024,
+			}
+			found = true
+		}
+	}
+
+	if !found {
+		return DiskUsage{}, fmt.Errorf("no disks found in guest")
+	}
+	return fullest, nil
+}
@@ -0,0 +1,33 @@
+package provision_test
+
+import (
+	"code.cloudfoundry.org/cfdev/provision"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseDiskUsage", func() {
+	It("returns the fullest disk backed file system", func() {
+		usage, err := provision.ParseDiskUsage(`Filesystem     1024-blocks     Used Available Capacity Mounted on
+tmpfs              4068288   403232   3665056      10% /
+/dev/sda1         61796348 43257444  15365516      74% /var/lib
+/dev/sda2         10255636  9229096   1026540      90% /var/vcap/data
+shm                4068288        0   4068288       0% /dev/shm
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(usage).To(Equal(provision.DiskUsage{
+			Mount:       "/var/vcap/data",
+			UsedPercent: 90,
+			AvailableMB: 1002,
+		}))
+	})
+
+	Context("when there are no disk backed file systems", func() {
+		It("returns an error", func() {
+			_, err := provision.ParseDiskUsage(`Filesystem     1024-blocks     Used Available Capacity Mounted on
+tmpfs              4068288   403232   3665056      10% /
+`)
+			Expect(err).To(MatchError("no disks found in guest"))
+		})
+	})
+})
@@ -0,0 +1,14 @@
+package provision
+
+import "code.cloudfoundry.org/cfdev/bosh"
+
+// RunErrand runs an errand of a deployment, e.g. smoke tests, and returns
+// what it printed on each instance.
+func (c *Controller) RunErrand(deployment, errand string) ([]bosh.ErrandResult, error) {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.RunErrand(deployment, errand)
+}
@@ -0,0 +1,110 @@
+package provision
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// heartbeatCommand samples the aggregate cpu line of /proc/stat on either
+// side of a one second sleep, then prints the memory pressure stall
+// information, the disk usage and the guest clock in nanoseconds.
+const heartbeatCommand = "head -1 /proc/stat; sleep 1; head -1 /proc/stat; " +
+	"cat /proc/pressure/memory 2>/dev/null; df -Pk; date +%s%N"
+
+// Heartbeat is a snapshot of the resources the guest is short of.
+type Heartbeat struct {
+	// CPUStealPercent is the share of cpu time the host did not give
+	// the vm although it was ready to run.
+	CPUStealPercent float64
+	// MemoryPressurePercent is the share of the last ten seconds that
+	// some task spent stalled waiting for memory.
+	MemoryPressurePercent float64
+	Disk                  DiskUsage
+	// ClockSkew is how far the guest clock is ahead of the host's.
+	ClockSkew time.Duration
+}
+
+// GuestHeartbeat samples the vm's resource usage over ssh.
+func (c *Controller) GuestHeartbeat() (Heartbeat, error) {
+	output, err := c.guestCommand(heartbeatCommand)
+	if err != nil {
+		return Heartbeat{}, fmt.Errorf("reading guest heartbeat: %s", err)
+	}
+
+	return ParseHeartbeat(output, time.Now())
+}
+
+// ParseHeartbeat reads the output of the heartbeat command, which finished
+// running at hostTime.
+func ParseHeartbeat(output string, hostTime time.Time) (Heartbeat, error) {
+	var (
+		heartbeat Heartbeat
+		cpuLines  []string
+		guestTime string
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "cpu":
+			cpuLines = append(cpuLines, scanner.Text())
+		case fields[0] == "some" && len(fields) >= 2:
+			value := strings.TrimPrefix(fields[1], "avg10=")
+			heartbeat.MemoryPressurePercent, _ = strconv.ParseFloat(value, 64)
+		case len(fields) == 1:
+			guestTime = fields[0]
+		}
+	}
+
+	if len(cpuLines) != 2 {
+		return Heartbeat{}, fmt.Errorf("expected 2 cpu samples, got %d", len(cpuLines))
+	}
+	steal0, total0, err := stealTimes(cpuLines[0])
+	if err != nil {
+		return Heartbeat{}, err
+	}
+	steal1, total1, err := stealTimes(cpuLines[1])
+	if err != nil {
+		return Heartbeat{}, err
+	}
+	if total1 > total0 {
+		heartbeat.CPUStealPercent = 100 * float64(steal1-steal0) / float64(total1-total0)
+	}
+
+	heartbeat.Disk, err = ParseDiskUsage(output)
+	if err != nil {
+		return Heartbeat{}, err
+	}
+
+	nanos, err := strconv.ParseInt(guestTime, 10, 64)
+	if err != nil {
+		return Heartbeat{}, fmt.Errorf("parsing guest time %q: %s", guestTime, err)
+	}
+	heartbeat.ClockSkew = time.Unix(0, nanos).Sub(hostTime)
+
+	return heartbeat, nil
+}
+
+func stealTimes(line string) (steal, total uint64, err error) {
+	fields := strings.Fields(line)[1:]
+	for i, field := range fields {
+		value, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("parsing cpu line %q: %s", line, err)
+		}
+		// guest and guest_nice are already counted in user and nice
+		if i >= 8 {
+			break
+		}
+		total += value
+		if i == 7 {
+			steal = value
+		}
+	}
+	return steal, total, nil
+}
@@ -0,0 +1,55 @@
+package provision_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/cfdev/provision"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseHeartbeat", func() {
+	var hostTime time.Time
+
+	BeforeEach(func() {
+		hostTime = time.Unix(1500000000, 0)
+	})
+
+	It("reads the cpu steal, memory pressure, disk usage and clock skew", func() {
+		heartbeat, err := provision.ParseHeartbeat(`cpu  1000 0 1000 7000 0 0 0 1000 0 0
+cpu  1100 0 1100 7600 0 0 0 1200 50 0
+some avg10=12.50 avg60=3.10 avg300=0.80 total=123456
+full avg10=2.00 avg60=0.50 avg300=0.10 total=23456
+Filesystem     1024-blocks     Used Available Capacity Mounted on
+/dev/sda1         61796348 43257444  15365516      74% /var/vcap/data
+1500000003000000000
+`, hostTime)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(heartbeat.CPUStealPercent).To(BeNumerically("~", 20))
+		Expect(heartbeat.MemoryPressurePercent).To(BeNumerically("~", 12.5))
+		Expect(heartbeat.Disk.UsedPercent).To(Equal(74))
+		Expect(heartbeat.ClockSkew).To(Equal(3 * time.Second))
+	})
+
+	Context("when the kernel does not report pressure stall information", func() {
+		It("reports no memory pressure", func() {
+			heartbeat, err := provision.ParseHeartbeat(`cpu  1000 0 1000 7000 0 0 0 0
+cpu  1100 0 1100 7800 0 0 0 0
+Filesystem     1024-blocks     Used Available Capacity Mounted on
+/dev/sda1         61796348 43257444  15365516      74% /
+1499999999000000000
+`, hostTime)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(heartbeat.CPUStealPercent).To(BeZero())
+			Expect(heartbeat.MemoryPressurePercent).To(BeZero())
+			Expect(heartbeat.ClockSkew).To(Equal(-time.Second))
+		})
+	})
+
+	Context("when a cpu sample is missing", func() {
+		It("returns an error", func() {
+			_, err := provision.ParseHeartbeat("cpu  1000 0 1000 7000 0 0 0 0\n", hostTime)
+			Expect(err).To(MatchError("expected 2 cpu samples, got 1"))
+		})
+	})
+})
@@ -0,0 +1,73 @@
+package provision
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const latencySamples = 3
+
+// Latency is the round trip time of a request to one layer of the
+// deployment, or the error that prevented measuring it.
+type Latency struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// LatencyProbe times requests against the deployment.
+type LatencyProbe struct {
+	HTTPClient *http.Client
+	Samples    int
+}
+
+// Measure returns the median round trip time of requests to url. Any
+// response counts, whatever its status, as only the network path is of
+// interest.
+func (l *LatencyProbe) Measure(url string) (time.Duration, error) {
+	samples := l.Samples
+	if samples <= 0 {
+		samples = 1
+	}
+
+	durations := make([]time.Duration, 0, samples)
+	for i := 0; i < samples; i++ {
+		start := time.Now()
+		resp, err := l.HTTPClient.Get(url)
+		if err != nil {
+			return 0, fmt.Errorf("requesting %s: %s", url, err)
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		durations = append(durations, time.Since(start))
+	}
+
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+	return durations[len(durations)/2], nil
+}
+
+// ProbeLatency measures the CC API and the router. The router is probed
+// with a host that has no route, which gorouter answers itself, so no app
+// has to be pushed to take the measurement.
+func (c *Controller) ProbeLatency() []Latency {
+	probe := &LatencyProbe{
+		HTTPClient: httpClient(),
+		Samples:    latencySamples,
+	}
+
+	var latencies []Latency
+	for _, target := range []struct{ name, url string }{
+		{"CC API", "https://api." + c.Config.CFDomain + "/v2/info"},
+		{"Router", "http://cfdev-latency-probe." + c.Config.CFDomain + "/"},
+	} {
+		duration, err := probe.Measure(target.url)
+		latencies = append(latencies, Latency{Name: target.name, Duration: duration, Err: err})
+	}
+	return latencies
+}
@@ -0,0 +1,70 @@
+package provision_test
+
+import (
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/provision"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("LatencyProbe", func() {
+	var (
+		server *ghttp.Server
+		probe  *provision.LatencyProbe
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		probe = &provision.LatencyProbe{
+			HTTPClient: &http.Client{},
+			Samples:    3,
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns the median round trip time", func() {
+		delayed := func(d time.Duration) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(d)
+			}
+		}
+		server.AppendHandlers(
+			delayed(0),
+			delayed(300*time.Millisecond),
+			delayed(100*time.Millisecond),
+		)
+
+		latency, err := probe.Measure(server.URL())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(latency).To(BeNumerically(">=", 100*time.Millisecond))
+		Expect(latency).To(BeNumerically("<", 300*time.Millisecond))
+	})
+
+	It("counts error responses", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusNotFound, "unknown route"),
+			ghttp.RespondWith(http.StatusNotFound, "unknown route"),
+			ghttp.RespondWith(http.StatusNotFound, "unknown route"),
+		)
+
+		_, err := probe.Measure(server.URL())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	Context("when the endpoint is unreachable", func() {
+		It("returns an error", func() {
+			url := server.URL()
+			server.Close()
+
+			_, err := probe.Measure(url)
+			Expect(err).To(MatchError(ContainSubstring("requesting " + url)))
+		})
+	})
+})
@@ -0,0 +1,120 @@
+package provision
+
+import (
+	"fmt"
+	"net"
+
+	"code.cloudfoundry.org/cfdev/config"
+)
+
+// servicesNetworkName is the network the cloud config gives the services
+// when they have a subnet of their own.
+const servicesNetworkName = "services"
+
+// DirectorNetworkVars are the create-env variables that place the director
+// on the cf subnet at directorIP, with the subnet's first address as its
+// gateway.
+func DirectorNetworkVars(directorIP, cfSubnet string) (string, error) {
+	subnet, gateway, err := subnetGateway(cfSubnet)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("-v internal_ip=%s -v internal_cidr=%s -v internal_gw=%s", directorIP, subnet, gateway), nil
+}
+
+// CloudConfigOpsFile moves the cloud config's default network onto the cf
+// subnet, with the router's address kept for it and the director's out of
+// the way, and adds a network for the services when they have a subnet of
+// their own. It is nil when the network is left as it ships.
+func CloudConfigOpsFile(cfg config.Config) ([]byte, error) {
+	if defaultNetwork(cfg) {
+		return nil, nil
+	}
+
+	subnet, gateway, err := subnetGateway(cfg.CFSubnet)
+	if err != nil {
+		return nil, err
+	}
+	path := "/networks/name=default/subnets/0"
+	ops := []opsFileOperation{
+		{Type: "replace", Path: path + "/range", Value: subnet.String()},
+		{Type: "replace", Path: path + "/gateway", Value: gateway.String()},
+		{Type: "replace", Path: path + "/static?", Value: []string{cfg.CFRouterIP}},
+		{Type: "replace", Path: path + "/reserved?", Value: []string{cfg.BoshDirectorIP}},
+	}
+
+	if cfg.ServicesSubnet != "" {
+		subnet, gateway, err := subnetGateway(cfg.ServicesSubnet)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, opsFileOperation{
+			Type: "replace",
+			Path: "/networks/name=" + servicesNetworkName + "?",
+			Value: map[string]interface{}{
+				"name": servicesNetworkName,
+				"type": "manual",
+				"subnets": []map[string]interface{}{{
+					"range":            subnet.String(),
+					"gateway":          gateway.String(),
+					"azs":              []string{"z1"},
+					"cloud_properties": map[string]string{"name": "random"},
+				}},
+			},
+		})
+	}
+
+	return marshalOps(ops)
+}
+
+// CFOpsFile is the ops file the cf deployment is deployed with: the
+// component property overrides, and the router's address when it has
+// moved.
+func CFOpsFile(cfg config.Config) ([]byte, error) {
+	ops, err := componentOps(cfg.ComponentProperties)
+	if err != nil {
+		return nil, err
+	}
+
+	if !defaultNetwork(cfg) {
+		ops = append(ops, opsFileOperation{
+			Type:  "replace",
+			Path:  "/instance_groups/name=router/networks/name=default/static_ips",
+			Value: []string{cfg.CFRouterIP},
+		})
+	}
+
+	return marshalOps(ops)
+}
+
+// servicesNetwork is the network the services are deployed on.
+func servicesNetwork(cfg config.Config) string {
+	if cfg.ServicesSubnet != "" {
+		return servicesNetworkName
+	}
+	return "default"
+}
+
+func defaultNetwork(cfg config.Config) bool {
+	return cfg.CFSubnet == config.DefaultCFSubnet &&
+		cfg.BoshDirectorIP == config.DefaultBoshDirectorIP &&
+		cfg.CFRouterIP == config.DefaultCFRouterIP &&
+		cfg.ServicesSubnet == ""
+}
+
+// subnetGateway parses cidr and picks its first address as the gateway.
+func subnetGateway(cidr string) (*net.IPNet, net.IP, error) {
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, nil, err
+	}
+	gateway := subnet.IP.To4()
+	if gateway == nil {
+		return nil, nil, fmt.Errorf("the subnet %s is not IPv4", cidr)
+	}
+	gateway = append(net.IP{}, gateway...)
+	gateway[3]++
+
+	return subnet, gateway, nil
+}
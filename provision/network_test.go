@@ -0,0 +1,112 @@
+package provision_test
+
+import (
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/provision"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DirectorNetworkVars", func() {
+	It("places the director on the cf subnet, behind its first address", func() {
+		vars, err := provision.DirectorNetworkVars("10.245.0.4", "10.245.0.0/16")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vars).To(Equal("-v internal_ip=10.245.0.4 -v internal_cidr=10.245.0.0/16 -v internal_gw=10.245.0.1"))
+	})
+
+	It("fails for a subnet that does not parse", func() {
+		_, err := provision.DirectorNetworkVars("10.245.0.4", "10.245.0.0")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("network ops files", func() {
+	var cfg config.Config
+
+	BeforeEach(func() {
+		cfg = config.Config{
+			BoshDirectorIP: config.DefaultBoshDirectorIP,
+			CFRouterIP:     config.DefaultCFRouterIP,
+			CFSubnet:       config.DefaultCFSubnet,
+		}
+	})
+
+	It("leaves the network as it ships by default", func() {
+		Expect(provision.CloudConfigOpsFile(cfg)).To(BeNil())
+		Expect(provision.CFOpsFile(cfg)).To(BeNil())
+	})
+
+	Context("when the network moves", func() {
+		BeforeEach(func() {
+			cfg.BoshDirectorIP = "10.245.0.4"
+			cfg.CFRouterIP = "10.245.0.34"
+			cfg.CFSubnet = "10.245.0.0/16"
+		})
+
+		It("moves the cloud config's default network onto the cf subnet", func() {
+			opsFile, err := provision.CloudConfigOpsFile(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opsFile).To(MatchYAML(`
+- type: replace
+  path: /networks/name=default/subnets/0/range
+  value: 10.245.0.0/16
+- type: replace
+  path: /networks/name=default/subnets/0/gateway
+  value: 10.245.0.1
+- type: replace
+  path: /networks/name=default/subnets/0/static?
+  value: [10.245.0.34]
+- type: replace
+  path: /networks/name=default/subnets/0/reserved?
+  value: [10.245.0.4]
+`))
+		})
+
+		It("gives the router its address", func() {
+			opsFile, err := provision.CFOpsFile(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opsFile).To(MatchYAML(`
+- type: replace
+  path: /instance_groups/name=router/networks/name=default/static_ips
+  value: [10.245.0.34]
+`))
+		})
+
+		It("keeps the component property overrides", func() {
+			cfg.ComponentProperties = map[string]map[string]string{"cc": {"some.property": "some-value"}}
+
+			opsFile, err := provision.CFOpsFile(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(opsFile)).To(ContainSubstring("/instance_groups/name=api/jobs/name=cloud_controller_ng/properties/some/property?"))
+			Expect(string(opsFile)).To(ContainSubstring("/instance_groups/name=router/networks/name=default/static_ips"))
+		})
+	})
+
+	It("adds a network for the services when they have a subnet of their own", func() {
+		cfg.ServicesSubnet = "10.145.0.0/16"
+
+		opsFile, err := provision.CloudConfigOpsFile(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opsFile).To(ContainSubstring(`
+- type: replace
+  path: /networks/name=services?
+  value:
+    name: services
+    subnets:
+    - azs:
+      - z1
+      cloud_properties:
+        name: random
+      gateway: 10.145.0.1
+      range: 10.145.0.0/16
+    type: manual
+`))
+	})
+
+	It("fails for a subnet that does not parse", func() {
+		cfg.CFSubnet = "10.245.0.0"
+
+		_, err := provision.CloudConfigOpsFile(cfg)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,84 @@
+package provision
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type componentJob struct {
+	InstanceGroup string
+	Job           string
+}
+
+// componentJobs maps the components whose properties can be overridden to
+// the job that reads them in the cf deployment.
+var componentJobs = map[string]componentJob{
+	"cc":    {InstanceGroup: "api", Job: "cloud_controller_ng"},
+	"uaa":   {InstanceGroup: "uaa", Job: "uaa"},
+	"diego": {InstanceGroup: "diego-cell", Job: "rep"},
+}
+
+type opsFileOperation struct {
+	Type  string      `yaml:"type"`
+	Path  string      `yaml:"path"`
+	Value interface{} `yaml:"value"`
+}
+
+// ComponentOpsFile translates component property overrides into a bosh ops
+// file. Values are read as yaml so that "true" and "512" keep their types.
+func ComponentOpsFile(properties map[string]map[string]string) ([]byte, error) {
+	ops, err := componentOps(properties)
+	if err != nil {
+		return nil, err
+	}
+	return marshalOps(ops)
+}
+
+func componentOps(properties map[string]map[string]string) ([]opsFileOperation, error) {
+	var components []string
+	for component := range properties {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	var ops []opsFileOperation
+	for _, component := range components {
+		job, ok := componentJobs[component]
+		if !ok {
+			return nil, fmt.Errorf("unknown component '%s'", component)
+		}
+
+		var names []string
+		for name := range properties[component] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			var value interface{}
+			if err := yaml.Unmarshal([]byte(properties[component][name]), &value); err != nil {
+				return nil, fmt.Errorf("parsing value of %s.%s: %s", component, name, err)
+			}
+
+			ops = append(ops, opsFileOperation{
+				Type: "replace",
+				Path: fmt.Sprintf("/instance_groups/name=%s/jobs/name=%s/properties/%s?",
+					job.InstanceGroup, job.Job, strings.Replace(name, ".", "/", -1)),
+				Value: value,
+			})
+		}
+	}
+
+	return ops, nil
+}
+
+// marshalOps is the ops file of ops, or nil when there are none.
+func marshalOps(ops []opsFileOperation) ([]byte, error) {
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	return yaml.Marshal(ops)
+}
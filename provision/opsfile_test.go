@@ -0,0 +1,40 @@
+package provision_test
+
+import (
+	"code.cloudfoundry.org/cfdev/provision"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ComponentOpsFile", func() {
+	It("translates properties into replace operations on the component's job", func() {
+		opsFile, err := provision.ComponentOpsFile(map[string]map[string]string{
+			"uaa": {"uaa.logging_level": "DEBUG"},
+			"cc": {
+				"cc.default_app_memory":        "512",
+				"cc.experimental.some_feature": "true",
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(opsFile)).To(MatchYAML(`
+- type: replace
+  path: /instance_groups/name=api/jobs/name=cloud_controller_ng/properties/cc/default_app_memory?
+  value: 512
+- type: replace
+  path: /instance_groups/name=api/jobs/name=cloud_controller_ng/properties/cc/experimental/some_feature?
+  value: true
+- type: replace
+  path: /instance_groups/name=uaa/jobs/name=uaa/properties/uaa/logging_level?
+  value: DEBUG
+`))
+	})
+
+	It("returns nothing when there are no properties", func() {
+		Expect(provision.ComponentOpsFile(nil)).To(BeNil())
+	})
+
+	It("rejects unknown components", func() {
+		_, err := provision.ComponentOpsFile(map[string]map[string]string{"some-component": {"a": "b"}})
+		Expect(err).To(MatchError("unknown component 'some-component'"))
+	})
+})
@@ -0,0 +1,77 @@
+package provision
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/errors"
+	"code.cloudfoundry.org/cfdev/messages"
+)
+
+// deployParallel deploys the services side by side, no more than
+// ParallelDeploys at a time, showing the progress of each on a single line.
+func (c *Controller) deployParallel(ui UI, b *bosh.Bosh, services []Service) error {
+	start := time.Now()
+
+	var (
+		names []string
+		jobs  []bosh.Job
+	)
+	for _, service := range services {
+		service := service
+		names = append(names, service.Name)
+		jobs = append(jobs, bosh.Job{
+			Deployment: service.Deployment,
+			IsErrand:   service.IsErrand,
+			Run: func() error {
+				c.setDeploying(service.Deployment, true)
+				defer c.setDeploying(service.Deployment, false)
+
+				return b.RunLocked(context.Background(), service.Deployment, func() error {
+					return c.DeployService(service)
+				})
+			},
+		})
+	}
+	ui.Say(messages.T("provision.deploying-service", map[string]interface{}{"Service": strings.Join(names, ", ")}))
+
+	orchestrator := &bosh.Orchestrator{Bosh: b, Workers: c.Config.ParallelDeploys}
+	events, result := orchestrator.Run(context.Background(), jobs)
+
+	latest := map[string]bosh.ProgressEvent{}
+	for event := range events {
+		if c.Events != nil {
+			c.writeEvent(event)
+			continue
+		}
+
+		latest[event.Deployment] = event
+		ui.Writer().Write([]byte("\r\033[K" + messages.T("provision.progress", map[string]interface{}{"Summary": summary(jobs, latest), "Duration": time.Now().Sub(start).Round(time.Second)})))
+	}
+
+	if err := <-result; err != nil {
+		return errors.SafeWrap(err, "Failed to deploy services")
+	}
+
+	if c.Events == nil {
+		ui.Writer().Write([]byte("\r\033[K" + messages.T("provision.done-after", map[string]interface{}{"Duration": time.Now().Sub(start).Round(time.Second)}) + "\n"))
+	}
+	return nil
+}
+
+// summary is the latest progress of each job, in the order the jobs were
+// given.
+func summary(jobs []bosh.Job, latest map[string]bosh.ProgressEvent) string {
+	var parts []string
+	for _, job := range jobs {
+		event, ok := latest[job.Deployment]
+		if !ok || event.Message == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", job.Deployment, event.Message))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -1,7 +1,10 @@
 package provision
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"code.cloudfoundry.org/cfdev/bosh"
@@ -10,27 +13,139 @@ import (
 
 func (c *Controller) report(start time.Time, ui UI, b *bosh.Bosh, service Service, errChan chan error) error {
 	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	c.setDeploying(service.Deployment, true)
+	defer c.setDeploying(service.Deployment, false)
+
+	// Finishing the deploy stops any wait for the deployment to show up.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		err := <-errChan
+		cancel()
+		done <- err
+	}()
+
+	if c.TaskLogs != nil && !service.IsErrand {
+		go c.streamTaskLogs(ctx, b, service.Deployment)
+	}
 
 	for {
 		select {
-		case err := <-errChan:
+		case err := <-done:
 			if err != nil {
 				return errors.SafeWrap(err, fmt.Sprintf("Failed to deploy %s", service.Name))
 			}
+			b.FinishPhase(service.Deployment)
+
+			if c.Events != nil {
+				c.writeEvent(bosh.NewProgressEvent(service.Deployment, start, time.Now(), bosh.VMProgress{State: bosh.Done}))
+				return nil
+			}
 
 			ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  Done (%s)\n", time.Now().Sub(start).Round(time.Second))))
 			return nil
 		case <-ticker.C:
-			p := b.GetVMProgress(start, service.Deployment, service.IsErrand)
+			p, err := b.GetVMProgress(ctx, start, service.Deployment, service.IsErrand)
+			if err != nil {
+				if ctx.Err() != nil {
+					continue
+				}
+				if p.State == bosh.Failed && c.Events != nil {
+					event := bosh.NewProgressEvent(service.Deployment, start, time.Now(), p)
+					event.Message = err.Error()
+					c.writeEvent(event)
+				}
+				return errors.SafeWrap(err, fmt.Sprintf("Failed to deploy %s", service.Name))
+			}
+
+			if c.Events != nil {
+				c.writeEvent(bosh.NewProgressEvent(service.Deployment, start, time.Now(), p))
+				continue
+			}
 
 			switch p.State {
 			case bosh.UploadingReleases:
-				ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  Uploaded Releases: %d (%s)", p.Releases, p.Duration.Round(time.Second))))
+				ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  Uploaded Releases: %d (%s%s)", p.Releases, p.Duration.Round(time.Second), remaining(p.Remaining))))
+			case bosh.Compiling:
+				ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  Compiling packages (%s%s)", p.Duration.Round(time.Second), remaining(p.Remaining))))
 			case bosh.Deploying:
-				ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  Progress: %d of %d (%s)", p.Done, p.Total, p.Duration.Round(time.Second))))
+				ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  Progress: %d of %d (%s%s)%s", p.Done, p.Total, p.Duration.Round(time.Second), remaining(p.Remaining), waitingOn(p.Instances))))
+			case bosh.WaitingForLock:
+				ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  Waiting for another deploy of %s to finish (%s)", service.Deployment, p.Duration.Round(time.Second))))
 			case bosh.RunningErrand:
 				ui.Writer().Write([]byte(fmt.Sprintf("\r\033[K  Running errand (%s)", p.Duration.Round(time.Second))))
 			}
 		}
 	}
 }
+
+// writeEvent writes the event to Events as a line of json.
+func (c *Controller) writeEvent(event bosh.ProgressEvent) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	c.Events.Write(append(line, '\n'))
+}
+
+// streamTaskLogs follows each task the director runs for the deployment
+// until ctx is done.
+func (c *Controller) streamTaskLogs(ctx context.Context, b *bosh.Bosh, deployment string) {
+	streamed := map[int]bool{}
+	for {
+		if id, err := b.CurrentTask(deployment); err == nil && !streamed[id] {
+			streamed[id] = true
+			b.StreamTaskLogs(id, c.TaskLogs)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// maxWaitingOn keeps the progress line to a single line of the terminal.
+const maxWaitingOn = 3
+
+// waitingOn names the instances that have not started yet, with any
+// processes that are failing on them.
+func waitingOn(instances []bosh.InstanceStatus) string {
+	var waiting []string
+	for _, instance := range instances {
+		if instance.ProcessState == "running" && len(instance.FailingProcesses) == 0 {
+			continue
+		}
+
+		name := instance.Name()
+		if len(instance.FailingProcesses) > 0 {
+			name = fmt.Sprintf("%s [%s]", name, strings.Join(instance.FailingProcesses, ", "))
+		}
+		waiting = append(waiting, name)
+	}
+
+	if len(waiting) == 0 {
+		return ""
+	}
+	if len(waiting) > maxWaitingOn {
+		waiting = append(waiting[:maxWaitingOn], fmt.Sprintf("%d more", len(waiting)-maxWaitingOn))
+	}
+	return " waiting on " + strings.Join(waiting, ", ")
+}
+
+// remaining formats the estimated time left, when there is one.
+func remaining(estimate time.Duration) string {
+	switch {
+	case estimate <= 0:
+		return ""
+	case estimate < time.Minute:
+		return ", <1m remaining"
+	default:
+		return fmt.Sprintf(", ~%dm remaining", int(estimate.Round(time.Minute).Minutes()))
+	}
+}
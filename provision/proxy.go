@@ -0,0 +1,157 @@
+package provision
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"code.cloudfoundry.org/cfdev/env"
+	"gopkg.in/yaml.v2"
+)
+
+// proxyGroups are the environment variable groups CF gives the containers
+// that stage apps and that run them.
+var proxyGroups = []string{"staging", "running"}
+
+// EnvironmentVariableGroups changes the variables CF sets in every app's
+// containers.
+type EnvironmentVariableGroups struct {
+	APIURL     string
+	HTTPClient *http.Client
+}
+
+// Merge sets vars in the group, keeping the variables it has already.
+func (g *EnvironmentVariableGroups) Merge(group string, vars map[string]string) error {
+	url := g.APIURL + "/v2/config/environment_variable_groups/" + group
+
+	resp, err := g.HTTPClient.Get(url)
+	if err != nil {
+		return fmt.Errorf("reading the %s environment variable group: %s", group, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		contents, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("reading the %s environment variable group: [%s] %s", group, resp.Status, contents)
+	}
+
+	existing := map[string]interface{}{}
+	if err := json.NewDecoder(resp.Body).Decode(&existing); err != nil {
+		return fmt.Errorf("reading the %s environment variable group: %s", group, err)
+	}
+	for name, value := range vars {
+		existing[name] = value
+	}
+
+	body, err := json.Marshal(existing)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err = g.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("updating the %s environment variable group: %s", group, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		contents, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("updating the %s environment variable group: [%s] %s", group, resp.Status, contents)
+	}
+	return nil
+}
+
+// ProxyVars are the variables that send an app's traffic through the
+// proxy, in both cases since buildpacks and apps read either. It is empty
+// when there is no proxy. Any app can read the groups, so the proxy's
+// credentials are left out; vpnkit, which has them, authenticates the
+// vm's traffic with the proxy.
+func ProxyVars(proxy env.ProxyConfig) map[string]string {
+	if proxy.Http == "" && proxy.Https == "" {
+		return nil
+	}
+	proxy = withoutCredentials(proxy)
+
+	vars := map[string]string{}
+	for name, value := range map[string]string{
+		"http_proxy":  proxy.Http,
+		"https_proxy": proxy.Https,
+		"no_proxy":    proxy.NoProxy,
+	} {
+		if value != "" {
+			vars[name] = value
+			vars[strings.ToUpper(name)] = value
+		}
+	}
+	return vars
+}
+
+// ProxyOpsFile has the BOSH director go through the proxy, e.g. to
+// download releases and stemcells. It is nil when there is no proxy. Like
+// ProxyVars, it leaves out the proxy's credentials.
+func ProxyOpsFile(proxy env.ProxyConfig) ([]byte, error) {
+	if proxy.Http == "" && proxy.Https == "" {
+		return nil, nil
+	}
+	proxy = withoutCredentials(proxy)
+
+	return yaml.Marshal([]opsFileOperation{{
+		Type: "replace",
+		Path: "/instance_groups/name=bosh/properties/env?",
+		Value: map[string]string{
+			"http_proxy":  proxy.Http,
+			"https_proxy": proxy.Https,
+			"no_proxy":    proxy.NoProxy,
+		},
+	}})
+}
+
+// withoutCredentials removes the credentials from the proxy's URLs.
+func withoutCredentials(proxy env.ProxyConfig) env.ProxyConfig {
+	for _, raw := range []*string{&proxy.Http, &proxy.Https} {
+		proxyURL, err := url.Parse(*raw)
+		if err != nil || proxyURL.User == nil {
+			continue
+		}
+		proxyURL.User = nil
+		*raw = proxyURL.String()
+	}
+	return proxy
+}
+
+// ApplyProxy gives the containers that stage and run apps the proxy the
+// host uses, so that apps can fetch their dependencies.
+func (c *Controller) ApplyProxy() error {
+	vars := ProxyVars(c.proxy())
+	if len(vars) == 0 {
+		return nil
+	}
+
+	client, err := c.cfClient()
+	if err != nil {
+		return err
+	}
+	groups := &EnvironmentVariableGroups{
+		APIURL:     "https://api." + c.Config.CFDomain,
+		HTTPClient: client,
+	}
+	for _, group := range proxyGroups {
+		if err := groups.Merge(group, vars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// proxy is the proxy of the host, which the proxy settings of the config
+// were applied to, bypassed for cf dev's own addresses.
+func (c *Controller) proxy() env.ProxyConfig {
+	return env.BuildProxyConfig(c.Config.BoshDirectorIP, c.Config.CFRouterIP, c.Config.HostIP)
+}
@@ -0,0 +1,12 @@
+package provision
+
+import "code.cloudfoundry.org/cfdev/bosh"
+
+func (c *Controller) Prune() error {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return err
+	}
+
+	return b.CleanUp()
+}
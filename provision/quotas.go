@@ -0,0 +1,164 @@
+package provision
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	defaultQuotaName = "default"
+
+	// CF has no unlimited value for an org's total memory so relaxing the
+	// quota sets one far beyond what the vm could provide.
+	relaxedMemoryLimitMB = 1024 * 1024
+	unlimited            = -1
+)
+
+// Quotas changes the quota that CF applies to orgs without one of their own.
+type Quotas struct {
+	APIURL     string
+	HTTPClient *http.Client
+}
+
+// Apply limits the default quota. Zero values leave that limit unchanged.
+func (q *Quotas) Apply(memoryMB, appInstances int) error {
+	limits := map[string]int{}
+	if memoryMB > 0 {
+		limits["memory_limit"] = memoryMB
+	}
+	if appInstances > 0 {
+		limits["app_instance_limit"] = appInstances
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+
+	return q.update(limits)
+}
+
+// Relax lifts the memory and app instance limits of the default quota.
+func (q *Quotas) Relax() error {
+	return q.update(map[string]int{
+		"memory_limit":       relaxedMemoryLimitMB,
+		"app_instance_limit": unlimited,
+	})
+}
+
+func (q *Quotas) update(limits map[string]int) error {
+	guid, err := q.defaultQuotaGUID()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(limits)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, q.APIURL+"/v2/quota_definitions/"+guid, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := q.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("updating quota: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		contents, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("updating quota: [%s] %s", resp.Status, contents)
+	}
+	return nil
+}
+
+func (q *Quotas) defaultQuotaGUID() (string, error) {
+	params := url.Values{}
+	params.Add("q", "name:"+defaultQuotaName)
+
+	resp, err := q.HTTPClient.Get(q.APIURL + "/v2/quota_definitions?" + params.Encode())
+	if err != nil {
+		return "", fmt.Errorf("finding quota: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		contents, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("finding quota: [%s] %s", resp.Status, contents)
+	}
+
+	var result struct {
+		Resources []struct {
+			Metadata struct {
+				GUID string
+			}
+		}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("finding quota: %s", err)
+	}
+	if len(result.Resources) == 0 {
+		return "", fmt.Errorf("quota '%s' not found", defaultQuotaName)
+	}
+	return result.Resources[0].Metadata.GUID, nil
+}
+
+// ApplyQuotas applies the configured quota, if any, to the deployed CF.
+func (c *Controller) ApplyQuotas() error {
+	if c.Config.Quota.MemoryMB == 0 && c.Config.Quota.AppInstances == 0 {
+		return nil
+	}
+
+	q, err := c.quotas()
+	if err != nil {
+		return err
+	}
+	return q.Apply(c.Config.Quota.MemoryMB, c.Config.Quota.AppInstances)
+}
+
+func (c *Controller) RelaxQuotas() error {
+	q, err := c.quotas()
+	if err != nil {
+		return err
+	}
+	return q.Relax()
+}
+
+func (c *Controller) quotas() (*Quotas, error) {
+	client, err := c.cfClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Quotas{
+		APIURL:     "https://api." + c.Config.CFDomain,
+		HTTPClient: client,
+	}, nil
+}
+
+// cfClient is logged in to CF as the admin user the config names.
+func (c *Controller) cfClient() (*http.Client, error) {
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient())
+
+	cfg := &oauth2.Config{
+		ClientID: "cf",
+		Endpoint: oauth2.Endpoint{
+			TokenURL: "https://uaa." + c.Config.CFDomain + "/oauth/token",
+		},
+	}
+	token, err := cfg.PasswordCredentialsToken(ctx, c.Config.CFAdminUsername, c.Config.CFAdminPassword)
+	if err != nil {
+		return nil, fmt.Errorf("logging in to uaa: %s", err)
+	}
+
+	return cfg.Client(ctx, token), nil
+}
@@ -0,0 +1,97 @@
+package provision_test
+
+import (
+	"net/http"
+
+	"code.cloudfoundry.org/cfdev/provision"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Quotas", func() {
+	var (
+		server *ghttp.Server
+		quotas *provision.Quotas
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		quotas = &provision.Quotas{
+			APIURL:     server.URL(),
+			HTTPClient: &http.Client{},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	findDefaultQuota := ghttp.CombineHandlers(
+		ghttp.VerifyRequest(http.MethodGet, "/v2/quota_definitions", "q=name%3Adefault"),
+		ghttp.RespondWith(http.StatusOK, `{"resources": [{"metadata": {"guid": "some-guid"}}]}`),
+	)
+
+	Describe("Apply", func() {
+		It("limits the default quota", func() {
+			server.AppendHandlers(
+				findDefaultQuota,
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodPut, "/v2/quota_definitions/some-guid"),
+					ghttp.VerifyJSON(`{"memory_limit": 4096, "app_instance_limit": 20}`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+
+			Expect(quotas.Apply(4096, 20)).To(Succeed())
+		})
+
+		It("only sends the limits that are set", func() {
+			server.AppendHandlers(
+				findDefaultQuota,
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodPut, "/v2/quota_definitions/some-guid"),
+					ghttp.VerifyJSON(`{"app_instance_limit": 20}`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+
+			Expect(quotas.Apply(0, 20)).To(Succeed())
+		})
+
+		It("does nothing when no limits are set", func() {
+			Expect(quotas.Apply(0, 0)).To(Succeed())
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("returns an error when the default quota does not exist", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, `{"resources": []}`))
+
+			Expect(quotas.Apply(4096, 0)).To(MatchError("quota 'default' not found"))
+		})
+	})
+
+	Describe("Relax", func() {
+		It("lifts the limits of the default quota", func() {
+			server.AppendHandlers(
+				findDefaultQuota,
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodPut, "/v2/quota_definitions/some-guid"),
+					ghttp.VerifyJSON(`{"memory_limit": 1048576, "app_instance_limit": -1}`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+
+			Expect(quotas.Relax()).To(Succeed())
+		})
+
+		It("returns an error when the update is rejected", func() {
+			server.AppendHandlers(
+				findDefaultQuota,
+				ghttp.RespondWith(http.StatusForbidden, `some-error`),
+			)
+
+			Expect(quotas.Relax()).To(MatchError("updating quota: [403 Forbidden] some-error"))
+		})
+	})
+})
@@ -0,0 +1,207 @@
+package provision
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/config"
+)
+
+// Marketplace creates service instances through the CC API.
+type Marketplace struct {
+	APIURL     string
+	HTTPClient *http.Client
+	// PollInterval is how often an instance still being provisioned
+	// asynchronously by its broker is checked on before binding it.
+	PollInterval time.Duration
+	Timeout      time.Duration
+}
+
+type ccResource struct {
+	Metadata struct {
+		GUID string `json:"guid"`
+	} `json:"metadata"`
+	Entity struct {
+		Name          string `json:"name"`
+		LastOperation struct {
+			State       string `json:"state"`
+			Description string `json:"description"`
+		} `json:"last_operation"`
+	} `json:"entity"`
+}
+
+// Seed creates the service instance in the space unless one with its name
+// already exists, and binds it to its app if that has been pushed.
+func (m *Marketplace) Seed(org, space string, instance config.ServiceInstanceConfig) error {
+	orgGUID, err := m.find("/v2/organizations", "name:"+org)
+	if err != nil {
+		return fmt.Errorf("finding org %s: %s", org, err)
+	}
+	spaceGUID, err := m.find("/v2/organizations/"+orgGUID+"/spaces", "name:"+space)
+	if err != nil {
+		return fmt.Errorf("finding space %s: %s", space, err)
+	}
+
+	instanceGUID, err := m.find("/v2/spaces/"+spaceGUID+"/service_instances", "name:"+instance.Name)
+	if err == errNotFound {
+		instanceGUID, err = m.create(spaceGUID, instance)
+	}
+	if err != nil {
+		return err
+	}
+
+	if instance.BindApp == "" {
+		return nil
+	}
+
+	appGUID, err := m.find("/v2/spaces/"+spaceGUID+"/apps", "name:"+instance.BindApp)
+	if err == errNotFound {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("finding app %s: %s", instance.BindApp, err)
+	}
+
+	if err := m.awaitProvisioned(instanceGUID); err != nil {
+		return err
+	}
+
+	var binding ccResource
+	err = m.do(http.MethodPost, "/v2/service_bindings", map[string]string{
+		"service_instance_guid": instanceGUID,
+		"app_guid":              appGUID,
+	}, &binding)
+	if err != nil {
+		return fmt.Errorf("binding %s to %s: %s", instance.Name, instance.BindApp, err)
+	}
+	return nil
+}
+
+func (m *Marketplace) create(spaceGUID string, instance config.ServiceInstanceConfig) (string, error) {
+	serviceGUID, err := m.find("/v2/spaces/"+spaceGUID+"/services", "label:"+instance.Service)
+	if err != nil {
+		return "", fmt.Errorf("finding service %s: %s", instance.Service, err)
+	}
+	planGUID, err := m.find("/v2/services/"+serviceGUID+"/service_plans", "name:"+instance.Plan)
+	if err != nil {
+		return "", fmt.Errorf("finding plan %s of %s: %s", instance.Plan, instance.Service, err)
+	}
+
+	var created ccResource
+	err = m.do(http.MethodPost, "/v2/service_instances?accepts_incomplete=true", map[string]string{
+		"name":              instance.Name,
+		"space_guid":        spaceGUID,
+		"service_plan_guid": planGUID,
+	}, &created)
+	if err != nil {
+		return "", fmt.Errorf("creating service instance %s: %s", instance.Name, err)
+	}
+	return created.Metadata.GUID, nil
+}
+
+func (m *Marketplace) awaitProvisioned(instanceGUID string) error {
+	deadline := time.Now().Add(m.Timeout)
+	for {
+		var instance ccResource
+		if err := m.do(http.MethodGet, "/v2/service_instances/"+instanceGUID, nil, &instance); err != nil {
+			return err
+		}
+
+		switch instance.Entity.LastOperation.State {
+		case "in progress":
+		case "failed":
+			return fmt.Errorf("provisioning %s failed: %s", instance.Entity.Name, instance.Entity.LastOperation.Description)
+		default:
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for %s to be provisioned", instance.Entity.Name)
+		}
+		time.Sleep(m.PollInterval)
+	}
+}
+
+var errNotFound = fmt.Errorf("not found")
+
+// find returns the guid of the first ccResource at path matching the query.
+func (m *Marketplace) find(path string, query string) (string, error) {
+	params := url.Values{}
+	params.Add("q", query)
+
+	var result struct {
+		Resources []ccResource `json:"resources"`
+	}
+	if err := m.do(http.MethodGet, path+"?"+params.Encode(), nil, &result); err != nil {
+		return "", err
+	}
+	if len(result.Resources) == 0 {
+		return "", errNotFound
+	}
+	return result.Resources[0].Metadata.GUID, nil
+}
+
+func (m *Marketplace) do(method string, path string, body interface{}, result interface{}) error {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		if reqBody, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, m.APIURL+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		contents, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("[%s] %s", resp.Status, contents)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// SeedServiceInstances creates the configured service instances. It carries
+// on past failures so that one bad entry does not stop the others, and
+// returns every failure together.
+func (c *Controller) SeedServiceInstances() error {
+	if len(c.Config.Seed.Instances) == 0 {
+		return nil
+	}
+
+	client, err := c.cfClient()
+	if err != nil {
+		return err
+	}
+	m := &Marketplace{
+		APIURL:       "https://api." + c.Config.CFDomain,
+		HTTPClient:   client,
+		PollInterval: 5 * time.Second,
+		Timeout:      10 * time.Minute,
+	}
+
+	var failures []string
+	for _, instance := range c.Config.Seed.Instances {
+		if err := m.Seed(c.Config.Seed.Org, c.Config.Seed.Space, instance); err != nil {
+			failures = append(failures, fmt.Sprintf("seeding %s: %s", instance.Name, err))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package provision_test
+
+import (
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/config"
+	"code.cloudfoundry.org/cfdev/provision"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Marketplace", func() {
+	var (
+		server      *ghttp.Server
+		marketplace *provision.Marketplace
+		instance    config.ServiceInstanceConfig
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		marketplace = &provision.Marketplace{
+			APIURL:     server.URL(),
+			HTTPClient: &http.Client{},
+		}
+		instance = config.ServiceInstanceConfig{
+			Service: "p-mysql",
+			Plan:    "10mb",
+			Name:    "some-db",
+		}
+
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/v2/organizations", "q=name%3Acfdev-org"),
+				ghttp.RespondWith(http.StatusOK, `{"resources": [{"metadata": {"guid": "org-guid"}}]}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/v2/organizations/org-guid/spaces", "q=name%3Acfdev-space"),
+				ghttp.RespondWith(http.StatusOK, `{"resources": [{"metadata": {"guid": "space-guid"}}]}`),
+			),
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	createInstance := []http.HandlerFunc{
+		ghttp.CombineHandlers(
+			ghttp.VerifyRequest(http.MethodGet, "/v2/spaces/space-guid/service_instances", "q=name%3Asome-db"),
+			ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+		),
+		ghttp.CombineHandlers(
+			ghttp.VerifyRequest(http.MethodGet, "/v2/spaces/space-guid/services", "q=label%3Ap-mysql"),
+			ghttp.RespondWith(http.StatusOK, `{"resources": [{"metadata": {"guid": "service-guid"}}]}`),
+		),
+		ghttp.CombineHandlers(
+			ghttp.VerifyRequest(http.MethodGet, "/v2/services/service-guid/service_plans", "q=name%3A10mb"),
+			ghttp.RespondWith(http.StatusOK, `{"resources": [{"metadata": {"guid": "plan-guid"}}]}`),
+		),
+		ghttp.CombineHandlers(
+			ghttp.VerifyRequest(http.MethodPost, "/v2/service_instances", "accepts_incomplete=true"),
+			ghttp.VerifyJSON(`{"name": "some-db", "space_guid": "space-guid", "service_plan_guid": "plan-guid"}`),
+			ghttp.RespondWith(http.StatusAccepted, `{"metadata": {"guid": "instance-guid"}}`),
+		),
+	}
+
+	It("creates the service instance", func() {
+		server.AppendHandlers(createInstance...)
+
+		Expect(marketplace.Seed("cfdev-org", "cfdev-space", instance)).To(Succeed())
+		Expect(server.ReceivedRequests()).To(HaveLen(6))
+	})
+
+	Context("when the service instance already exists", func() {
+		It("leaves it alone", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/spaces/space-guid/service_instances", "q=name%3Asome-db"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [{"metadata": {"guid": "instance-guid"}}]}`),
+				),
+			)
+
+			Expect(marketplace.Seed("cfdev-org", "cfdev-space", instance)).To(Succeed())
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+	})
+
+	Context("when a bind target is given", func() {
+		BeforeEach(func() {
+			instance.BindApp = "some-app"
+			server.AppendHandlers(createInstance...)
+		})
+
+		It("binds the instance once it is provisioned", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/spaces/space-guid/apps", "q=name%3Asome-app"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [{"metadata": {"guid": "app-guid"}}]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/service_instances/instance-guid"),
+					ghttp.RespondWith(http.StatusOK, `{"entity": {"name": "some-db", "last_operation": {"state": "in progress"}}}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/service_instances/instance-guid"),
+					ghttp.RespondWith(http.StatusOK, `{"entity": {"name": "some-db", "last_operation": {"state": "succeeded"}}}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodPost, "/v2/service_bindings"),
+					ghttp.VerifyJSON(`{"service_instance_guid": "instance-guid", "app_guid": "app-guid"}`),
+					ghttp.RespondWith(http.StatusCreated, `{}`),
+				),
+			)
+			marketplace.Timeout = time.Second
+
+			Expect(marketplace.Seed("cfdev-org", "cfdev-space", instance)).To(Succeed())
+			Expect(server.ReceivedRequests()).To(HaveLen(10))
+		})
+
+		It("skips binding when the app has not been pushed", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/spaces/space-guid/apps", "q=name%3Asome-app"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+				),
+			)
+
+			Expect(marketplace.Seed("cfdev-org", "cfdev-space", instance)).To(Succeed())
+		})
+
+		It("returns an error when provisioning fails", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/v2/spaces/space-guid/apps", "q=name%3Asome-app"),
+					ghttp.RespondWith(http.StatusOK, `{"resources": [{"metadata": {"guid": "app-guid"}}]}`),
+				),
+				ghttp.RespondWith(http.StatusOK, `{"entity": {"name": "some-db", "last_operation": {"state": "failed", "description": "some-error"}}}`),
+			)
+
+			Expect(marketplace.Seed("cfdev-org", "cfdev-space", instance)).To(MatchError("provisioning some-db failed: some-error"))
+		})
+	})
+
+	Context("when the service is not in the marketplace", func() {
+		It("returns an error", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+				ghttp.RespondWith(http.StatusOK, `{"resources": []}`),
+			)
+
+			Expect(marketplace.Seed("cfdev-org", "cfdev-space", instance)).To(MatchError("finding service p-mysql: not found"))
+		})
+	})
+})
@@ -2,6 +2,8 @@ package provision
 
 import (
 	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/messages"
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -64,21 +66,45 @@ func contains(services []Service, name string) bool {
 	return false
 }
 
+// DeployServices deploys the services one by one, in order. When
+// ParallelDeploys is more than one, those that are not errands are
+// deployed side by side first, and then the errands in order, as they may
+// depend on the others.
 func (c *Controller) DeployServices(ui UI, services []Service) error {
 	b, err := bosh.New(c.Config)
 	if err != nil {
 		return err
 	}
 
+	if c.Config.ParallelDeploys > 1 {
+		var parallel, errands []Service
+		for _, service := range services {
+			if service.IsErrand {
+				errands = append(errands, service)
+			} else {
+				parallel = append(parallel, service)
+			}
+		}
+
+		if len(parallel) > 1 {
+			if err := c.deployParallel(ui, b, parallel); err != nil {
+				return err
+			}
+			services = errands
+		}
+	}
+
 	errChan := make(chan error, 1)
 
 	for _, service := range services {
 		start := time.Now()
 
-		ui.Say("Deploying %s...", service.Name)
+		ui.Say(messages.T("provision.deploying-service", map[string]interface{}{"Service": service.Name}))
 
 		go func(s Service) {
-			errChan <- c.DeployService(s)
+			errChan <- b.RunLocked(context.Background(), s.Deployment, func() error {
+				return c.DeployService(s)
+			})
 		}(service)
 
 		err = c.report(start, ui, b, service, errChan)
@@ -101,6 +127,7 @@ func (c *Controller) DeployService(service Service) error {
 
 	cmd.Env = os.Environ()
 	cmd.Env = append(cmd.Env, bosh.Envs(c.Config)...)
+	cmd.Env = append(cmd.Env, "SERVICES_NETWORK="+servicesNetwork(c.Config))
 
 	logFile, err := os.Create(filepath.Join(c.Config.LogDir, "deploy-"+strings.ToLower(service.Name)+".log"))
 	if err != nil {
@@ -0,0 +1,33 @@
+package provision
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/ssh"
+)
+
+// SSH runs cmd on an instance of a deployment, or opens a shell on it when
+// cmd is empty.
+func (c *Controller) SSH(instance string, cmd []string) error {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return err
+	}
+
+	return b.SSH(instance, cmd)
+}
+
+// VMShell runs cmd in the vm itself, or opens a shell in it when cmd is
+// empty. It logs in as root with the key cf dev put in the vm on start.
+func (c *Controller) VMShell(cmd []string) error {
+	key, err := ioutil.ReadFile(filepath.Join(c.Config.CacheDir, "id_rsa"))
+	if err != nil {
+		return err
+	}
+
+	s := ssh.SSH{}
+	return s.Shell(cmd, ssh.SSHAddress{IP: "127.0.0.1", Port: "9992"}, key, 20*time.Second)
+}
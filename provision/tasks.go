@@ -0,0 +1,34 @@
+package provision
+
+import "code.cloudfoundry.org/cfdev/bosh"
+
+// RecentTasks returns the last n tasks the director ran, newest first.
+func (c *Controller) RecentTasks(n int) ([]bosh.TaskInfo, error) {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.RecentTasks(n)
+}
+
+// Deployments lists the deployments the director has.
+func (c *Controller) Deployments() ([]string, error) {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.DeploymentNames()
+}
+
+// RecoverDeployments recreates the deployments' vms that are gone, e.g.
+// after the vm rebooted.
+func (c *Controller) RecoverDeployments() error {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return err
+	}
+
+	return b.Recover()
+}
@@ -0,0 +1,29 @@
+package provision
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+)
+
+// Timings returns the phase timings of the deploys that finished since
+// the given time, oldest first.
+func (c *Controller) Timings(since time.Time) ([]bosh.Timing, error) {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := b.Timings()
+	if err != nil {
+		return nil, err
+	}
+
+	var timings []bosh.Timing
+	for _, timing := range all {
+		if !timing.FinishedAt.Before(since) {
+			timings = append(timings, timing)
+		}
+	}
+	return timings, nil
+}
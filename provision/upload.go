@@ -0,0 +1,51 @@
+package provision
+
+import (
+	"code.cloudfoundry.org/cfdev/bosh"
+	"code.cloudfoundry.org/cfdev/messages"
+)
+
+// UploadRelease uploads a custom release, from a path or URL, to the
+// director. A local tarball is skipped when the director already has its
+// version, as uploading it again would only repeat the same work.
+func (c *Controller) UploadRelease(ui UI, location string) error {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return err
+	}
+
+	if manifest, err := bosh.ReadReleaseManifest(location); err == nil {
+		if found, err := b.HasRelease(manifest.Name, manifest.Version); err == nil && found {
+			ui.Say(messages.T("provision.release-uploaded", map[string]interface{}{"Name": manifest.Name, "Version": manifest.Version}))
+			return nil
+		}
+	}
+
+	ui.Say(messages.T("provision.uploading-release", map[string]interface{}{"Location": location}))
+	if err := b.UploadRelease(location, uploadProgress(ui)); err != nil {
+		return err
+	}
+	ui.Writer().Write([]byte("\r\033[K" + messages.T("provision.done") + "\n"))
+	return nil
+}
+
+// UploadStemcell uploads a stemcell, from a path or URL, to the director.
+func (c *Controller) UploadStemcell(ui UI, location string) error {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return err
+	}
+
+	ui.Say(messages.T("provision.uploading-stemcell", map[string]interface{}{"Location": location}))
+	if err := b.UploadStemcell(location, uploadProgress(ui)); err != nil {
+		return err
+	}
+	ui.Writer().Write([]byte("\r\033[K" + messages.T("provision.done") + "\n"))
+	return nil
+}
+
+func uploadProgress(ui UI) bosh.UploadProgress {
+	return func(sent, total int64) {
+		ui.Writer().Write([]byte("\r\033[K" + messages.T("provision.uploaded", map[string]interface{}{"Sent": sent >> 20, "Total": total >> 20})))
+	}
+}
@@ -0,0 +1,97 @@
+package provision
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/cfdev/bosh"
+)
+
+// DirectorReadyTimeout bounds the wait for the director to become healthy
+// after it is deployed.
+var DirectorReadyTimeout = 5 * time.Minute
+
+// DirectorReady returns an error unless the bosh director answers requests,
+// accepts our credentials and is working through its task queue.
+func (c *Controller) DirectorReady() error {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return err
+	}
+
+	return b.Health()
+}
+
+// waitForDirector waits for a freshly deployed director to become healthy.
+func (c *Controller) waitForDirector() error {
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DirectorReadyTimeout)
+	defer cancel()
+	return b.WaitReady(ctx)
+}
+
+// CFAPIReady returns an error unless the cloud controller answers requests
+// through the router.
+func (c *Controller) CFAPIReady() error {
+	resp, err := httpClient().Get("https://api." + c.Config.CFDomain + "/v2/info")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cloud controller responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// ServiceReady returns an error unless every vm of the service's deployment
+// is running.
+func (c *Controller) ServiceReady(service Service) error {
+	if service.IsErrand {
+		return fmt.Errorf("%s is deployed by an errand and has no vms to wait for", service.Name)
+	}
+
+	b, err := bosh.New(c.Config)
+	if err != nil {
+		return err
+	}
+
+	running, err := b.DeploymentRunning(service.Deployment)
+	if err != nil {
+		return err
+	}
+	if !running {
+		return fmt.Errorf("deployment %s is not running yet", service.Deployment)
+	}
+	return nil
+}
+
+// DefaultVMTimeout bounds the wait for a started vm to answer.
+const DefaultVMTimeout = 2 * time.Minute
+
+// WaitForVM pings the vm once a second until it answers or timeout, or
+// DefaultVMTimeout when it is zero, passes, and returns the last error.
+func WaitForVM(ping func() error, timeout time.Duration) error {
+	if timeout == 0 {
+		timeout = DefaultVMTimeout
+	}
+
+	var err error
+	deadline := time.Now().Add(timeout)
+	for {
+		if err = ping(); err == nil || time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(time.Second)
+	}
+}
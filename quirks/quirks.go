@@ -0,0 +1,36 @@
+package quirks
+
+// Quirk is a host setup that is known to break or slow down cf dev,
+// along with what the user can do about it.
+type Quirk struct {
+	Name     string
+	Guidance string
+}
+
+//go:generate mockgen -package mocks -destination mocks/powershell.go code.cloudfoundry.org/cfdev/quirks Powershell
+type Powershell interface {
+	Output(command string) (string, error)
+}
+
+type Quirks struct {
+	Powershell Powershell
+}
+
+type check struct {
+	name   string
+	detect func() (guidance string, found bool, err error)
+}
+
+// Detect runs every check for this host and returns the quirks that were
+// found. A check that cannot run is skipped rather than failing the others.
+func (q *Quirks) Detect() []Quirk {
+	var found []Quirk
+	for _, c := range q.checks() {
+		guidance, ok, err := c.detect()
+		if err != nil || !ok {
+			continue
+		}
+		found = append(found, Quirk{Name: c.name, Guidance: guidance})
+	}
+	return found
+}
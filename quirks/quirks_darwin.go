@@ -0,0 +1,27 @@
+package quirks
+
+import (
+	"os/exec"
+	"strings"
+
+	"code.cloudfoundry.org/cfdev/messages"
+)
+
+func (q *Quirks) checks() []check {
+	return []check{
+		{name: "hypervisor-framework", detect: hypervisorFrameworkUnavailable},
+	}
+}
+
+// hyperkit needs Hypervisor.framework, which macOS withholds on older
+// hardware and inside some virtual machines.
+func hypervisorFrameworkUnavailable() (string, bool, error) {
+	output, err := exec.Command("sysctl", "-n", "kern.hv_support").Output()
+	if err != nil {
+		return "", false, err
+	}
+	if strings.TrimSpace(string(output)) == "1" {
+		return "", false, nil
+	}
+	return messages.T("quirks.hypervisor-framework"), true, nil
+}
@@ -0,0 +1,24 @@
+package quirks
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/cfdev/messages"
+)
+
+func (q *Quirks) checks() []check {
+	return []check{
+		{name: "kvm", detect: kvmUnavailable},
+	}
+}
+
+// QEMU needs /dev/kvm to run the vm at native speed. Without it, or the
+// permission to open it, every instruction is emulated.
+func kvmUnavailable() (string, bool, error) {
+	kvm, err := os.OpenFile("/dev/kvm", os.O_RDWR, 0)
+	if err != nil {
+		return messages.T("quirks.kvm"), true, nil
+	}
+	kvm.Close()
+	return "", false, nil
+}
@@ -0,0 +1,13 @@
+package quirks_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestQuirks(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Quirks Suite")
+}
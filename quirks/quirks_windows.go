@@ -0,0 +1,65 @@
+package quirks
+
+import (
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/cfdev/messages"
+)
+
+const (
+	virtualBoxKey = `HKLM:\SOFTWARE\Oracle\VirtualBox`
+
+	// VirtualBox releases before 6.0 cannot run vms while Hyper-V is enabled
+	virtualBoxHyperVMajorVersion = 6
+
+	// Hyper-V on AMD processors was unreliable before the 1809 update
+	amdMinimumBuild = 17763
+)
+
+func (q *Quirks) checks() []check {
+	return []check{
+		{name: "virtualbox-coexistence", detect: q.virtualBoxCoexistence},
+		{name: "amd-v-windows-build", detect: q.amdOnOldBuild},
+	}
+}
+
+func (q *Quirks) virtualBoxCoexistence() (string, bool, error) {
+	output, err := q.Powershell.Output(`(Get-ItemProperty -Path '` + virtualBoxKey + `' -ErrorAction SilentlyContinue).Version`)
+	if err != nil {
+		return "", false, err
+	}
+
+	version := strings.TrimSpace(output)
+	if version == "" {
+		return "", false, nil
+	}
+
+	major, err := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	if err != nil || major >= virtualBoxHyperVMajorVersion {
+		return "", false, nil
+	}
+
+	return messages.T("quirks.virtualbox-coexistence", map[string]interface{}{"Version": version}), true, nil
+}
+
+func (q *Quirks) amdOnOldBuild() (string, bool, error) {
+	output, err := q.Powershell.Output("(Get-CimInstance -ClassName Win32_Processor | Select-Object -First 1).Manufacturer")
+	if err != nil {
+		return "", false, err
+	}
+	if !strings.Contains(output, "AuthenticAMD") {
+		return "", false, nil
+	}
+
+	output, err = q.Powershell.Output("[System.Environment]::OSVersion.Version.Build")
+	if err != nil {
+		return "", false, err
+	}
+	build, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil || build >= amdMinimumBuild {
+		return "", false, err
+	}
+
+	return messages.T("quirks.amd-v-windows-build", map[string]interface{}{"Build": build}), true, nil
+}
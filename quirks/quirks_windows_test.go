@@ -0,0 +1,59 @@
+package quirks_test
+
+import (
+	"code.cloudfoundry.org/cfdev/quirks"
+	"code.cloudfoundry.org/cfdev/quirks/mocks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Quirks", func() {
+	var (
+		mockController *gomock.Controller
+		mockPowershell *mocks.MockPowershell
+		q              *quirks.Quirks
+
+		virtualBoxQuery = `(Get-ItemProperty -Path 'HKLM:\SOFTWARE\Oracle\VirtualBox' -ErrorAction SilentlyContinue).Version`
+		processorQuery  = `(Get-CimInstance -ClassName Win32_Processor | Select-Object -First 1).Manufacturer`
+		buildQuery      = `[System.Environment]::OSVersion.Version.Build`
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		mockPowershell = mocks.NewMockPowershell(mockController)
+		q = &quirks.Quirks{Powershell: mockPowershell}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("finds nothing on a healthy host", func() {
+		mockPowershell.EXPECT().Output(virtualBoxQuery).Return("", nil)
+		mockPowershell.EXPECT().Output(processorQuery).Return("GenuineIntel\r\n", nil)
+
+		Expect(q.Detect()).To(BeEmpty())
+	})
+
+	It("finds virtualbox releases that cannot coexist with hyper-v", func() {
+		mockPowershell.EXPECT().Output(virtualBoxQuery).Return("5.2.22\r\n", nil)
+		mockPowershell.EXPECT().Output(processorQuery).Return("GenuineIntel\r\n", nil)
+
+		Expect(q.Detect()).To(Equal([]quirks.Quirk{{
+			Name:     "virtualbox-coexistence",
+			Guidance: "VirtualBox 5.2.22 cannot run vms while Hyper-V is enabled. Upgrade to VirtualBox 6.0 or later to use both",
+		}}))
+	})
+
+	It("finds amd processors on windows builds without reliable hyper-v support", func() {
+		mockPowershell.EXPECT().Output(virtualBoxQuery).Return("6.0.4\r\n", nil)
+		mockPowershell.EXPECT().Output(processorQuery).Return("AuthenticAMD\r\n", nil)
+		mockPowershell.EXPECT().Output(buildQuery).Return("17134\r\n", nil)
+
+		Expect(q.Detect()).To(Equal([]quirks.Quirk{{
+			Name:     "amd-v-windows-build",
+			Guidance: "Hyper-V is unreliable on AMD processors before Windows 10 build 17763, and this is build 17134. Please update Windows",
+		}}))
+	})
+})
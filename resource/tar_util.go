@@ -16,6 +16,9 @@ type TarOpts struct {
 	Exclude       string
 	FlattenFolder bool
 	Dst           string
+	// Mode, when set, is what the files are written with in place of the
+	// tarball's, e.g. to keep secrets readable by their owner only.
+	Mode os.FileMode
 }
 
 func Untar(src string, dstOpts []TarOpts) error {
@@ -86,10 +89,19 @@ func copyCurrentFileIfMatch(tr *tar.Reader, header *tar.Header, opts []TarOpts)
 		}
 
 		if target != "" {
-			f, err := os.OpenFile(target, os.O_TRUNC|os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
+			mode := os.FileMode(header.Mode)
+			if opt.Mode != 0 {
+				mode = opt.Mode
+			}
+			f, err := os.OpenFile(target, os.O_TRUNC|os.O_CREATE|os.O_RDWR, mode)
 			if err != nil {
 				return false, err
 			}
+			// The mode only applies to files that did not exist.
+			if err := f.Chmod(mode); err != nil {
+				f.Close()
+				return false, err
+			}
 			if _, err := io.Copy(f, tr); err != nil {
 				return false, err
 			}
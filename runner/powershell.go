@@ -1,17 +1,92 @@
 package runner
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
+	"strings"
+	"time"
 )
 
-type Powershell struct{}
+// transientErrors are reported by WMI and the Hyper-V management service
+// when they are busy or restarting. The same command tends to succeed when
+// run again shortly after.
+var transientErrors = []string{
+	"0x80041001",
+	"0x800706BA",
+	"Generic failure",
+	"The RPC server is unavailable",
+	"The operation cannot be performed while the object is in its current state",
+	"Virtual Machine Management service",
+}
+
+// Powershell runs powershell commands. Its zero value waits for each
+// command however long it takes.
+type Powershell struct {
+	// Timeout kills a command that has not finished in time.
+	Timeout time.Duration
+}
+
+// Error is a powershell command that failed or timed out.
+type Error struct {
+	Command string
+	// Stderr is what the command wrote to stderr, e.g. the powershell
+	// error record.
+	Stderr   string
+	ExitCode int
+	// Timeout is set when the command was killed for taking longer.
+	Timeout time.Duration
+	err     error
+}
+
+func (e *Error) Error() string {
+	if e.Timeout > 0 {
+		return fmt.Sprintf("failed to execute: powershell.exe -Command %q: timed out after %s", e.Command, e.Timeout)
+	}
+	return fmt.Sprintf("failed to execute: powershell.exe -Command %q: %s: %s", e.Command, e.err, e.Stderr)
+}
 
 func (p *Powershell) Output(command string) (string, error) {
-	output, err := exec.Command("powershell.exe", "-Command", command).CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("failed to execute: powershell.exe -Command %q: %s: %s", command, err, output)
+	ctx := context.Background()
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
 	}
 
-	return string(output), nil
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		runErr := &Error{Command: command, Stderr: strings.TrimSpace(stderr.String()), ExitCode: -1, err: err}
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			runErr.ExitCode = exitErr.ExitCode()
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			runErr.Timeout = p.Timeout
+		}
+		return "", runErr
+	}
+
+	return stdout.String(), nil
+}
+
+// IsTransient reports whether err is a command that failed with one of the
+// errors WMI and Hyper-V report while busy, so that running it again may
+// succeed. Only what the command wrote to stderr is looked at, not the
+// command itself.
+func IsTransient(err error) bool {
+	runErr, ok := err.(*Error)
+	if !ok || runErr.Timeout > 0 {
+		return false
+	}
+	for _, transient := range transientErrors {
+		if strings.Contains(runErr.Stderr, transient) {
+			return true
+		}
+	}
+	return false
 }
@@ -44,3 +44,15 @@ func Must(v *Version, err error) *Version {
 	}
 	return v
 }
+
+// LessThan compares the major, minor and build numbers, ignoring any
+// suffix after the build.
+func (v *Version) LessThan(other *Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	return v.Build < other.Build
+}
@@ -33,4 +33,11 @@ var _ = Describe("Semver", func() {
 		Expect(s.Build).To(Equal(0))
 		Expect(s.Original).To(Equal(""))
 	})
+
+	It("orders versions by major, minor and build", func() {
+		Expect(semver.Must(semver.New("0.0.9")).LessThan(semver.Must(semver.New("0.1.0")))).To(BeTrue())
+		Expect(semver.Must(semver.New("1.2.3")).LessThan(semver.Must(semver.New("1.10.0")))).To(BeTrue())
+		Expect(semver.Must(semver.New("2.0.0")).LessThan(semver.Must(semver.New("1.9.9")))).To(BeFalse())
+		Expect(semver.Must(semver.New("1.2.3-patch-1")).LessThan(semver.Must(semver.New("1.2.3")))).To(BeFalse())
+	})
 })
@@ -3,6 +3,7 @@ package shell
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"code.cloudfoundry.org/cfdev/bosh"
@@ -12,28 +13,46 @@ import (
 	"runtime"
 )
 
+// Shells are the shells Prepare can write for.
+var Shells = []string{"bash", "zsh", "fish", "powershell"}
+
 type Environment struct {
+	// Shell is the shell to write for, one of Shells. It defaults to the
+	// user's shell.
+	Shell string
+}
+
+// Detect guesses the user's shell: PowerShell on Windows, and otherwise the
+// shell named by $SHELL, falling back to bash.
+func Detect() string {
+	if runtime.GOOS == "windows" {
+		return "powershell"
+	}
+
+	switch name := filepath.Base(os.Getenv("SHELL")); name {
+	case "zsh", "fish":
+		return name
+	default:
+		return "bash"
+	}
 }
 
 func (e *Environment) Prepare(config bosh.Config) (string, error) {
-	order := []string{
-		"BOSH_ENVIRONMENT",
-		"BOSH_CLIENT",
-		"BOSH_CLIENT_SECRET",
-		"BOSH_CA_CERT",
-		"BOSH_GW_HOST",
-		"BOSH_GW_PRIVATE_KEY",
-		"BOSH_GW_USER",
+	shell := e.Shell
+	if shell == "" {
+		shell = Detect()
 	}
 
-	values := map[string]string{
-		"BOSH_ENVIRONMENT":    config.DirectorAddress,
-		"BOSH_CLIENT":         config.AdminUsername,
-		"BOSH_CLIENT_SECRET":  config.AdminPassword,
-		"BOSH_CA_CERT":        config.CACertificate,
-		"BOSH_GW_HOST":        config.GatewayHost,
-		"BOSH_GW_PRIVATE_KEY": config.GatewayPrivateKey,
-		"BOSH_GW_USER":        config.GatewayUsername,
+	var unset, export string
+	switch shell {
+	case "bash", "zsh":
+		unset, export = "unset %s;\n", "export %s=\"%s\";\n"
+	case "fish":
+		unset, export = "set -e %s;\n", "set -gx %s \"%s\";\n"
+	case "powershell":
+		unset, export = "Remove-Item Env:%s;\n", "$env:%s=\"%s\";\n"
+	default:
+		return "", fmt.Errorf("unsupported shell %s, expected one of %s", shell, strings.Join(Shells, ", "))
 	}
 
 	var output bytes.Buffer
@@ -41,20 +60,12 @@ func (e *Environment) Prepare(config bosh.Config) (string, error) {
 	for _, envvar := range os.Environ() {
 		if strings.HasPrefix(envvar, "BOSH_") {
 			envvar = strings.Split(envvar, "=")[0]
-			if runtime.GOOS != "windows" {
-				fmt.Fprintf(&output, "unset %s;\n", envvar)
-			} else {
-				fmt.Fprintf(&output, "Remove-Item Env:%s;\n", envvar)
-			}
+			fmt.Fprintf(&output, unset, envvar)
 		}
 	}
 
-	for _, name := range order {
-		if runtime.GOOS != "windows" {
-			fmt.Fprintf(&output, "export %s=\"%s\";\n", name, values[name])
-		} else {
-			fmt.Fprintf(&output, "$env:%s=\"%s\";\n", name, values[name])
-		}
+	for _, envVar := range config.EnvVars() {
+		fmt.Fprintf(&output, export, envVar.Name, envVar.Value)
 	}
 
 	return strings.TrimSpace(output.String()), nil
@@ -70,6 +70,62 @@ var _ = Describe("Formatting BOSH Configuration", func() {
 		}
 	})
 
+	It("formats it for fish", func() {
+		env.Shell = "fish"
+
+		exports, err := env.Prepare(config)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(exports).To(ContainSubstring(`set -gx BOSH_ENVIRONMENT "10.144.0.4";`))
+		Expect(exports).To(ContainSubstring(`set -gx BOSH_CLIENT_SECRET "admin-password";`))
+	})
+
+	It("formats it for PowerShell", func() {
+		env.Shell = "powershell"
+
+		exports, err := env.Prepare(config)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(exports).To(ContainSubstring(`$env:BOSH_ENVIRONMENT="10.144.0.4";`))
+		Expect(exports).To(ContainSubstring(`$env:BOSH_CA_CERT="ca-certificate";`))
+	})
+
+	It("formats it for zsh as for bash", func() {
+		env.Shell = "zsh"
+
+		exports, err := env.Prepare(config)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(exports).To(ContainSubstring(`export BOSH_CLIENT="admin";`))
+	})
+
+	It("returns an error for other shells", func() {
+		env.Shell = "tcsh"
+
+		_, err := env.Prepare(config)
+		Expect(err).To(MatchError("unsupported shell tcsh, expected one of bash, zsh, fish, powershell"))
+	})
+
+	Context("when fish is the user's shell", func() {
+		var oldShell string
+
+		BeforeEach(func() {
+			oldShell = os.Getenv("SHELL")
+			os.Setenv("SHELL", "/usr/local/bin/fish")
+		})
+
+		AfterEach(func() {
+			os.Setenv("SHELL", oldShell)
+		})
+
+		It("formats it for fish by default", func() {
+			if runtime.GOOS == "windows" {
+				Skip("PowerShell is the default on Windows")
+			}
+
+			exports, err := env.Prepare(config)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(exports).To(ContainSubstring(`set -gx BOSH_CLIENT "admin";`))
+		})
+	})
+
 	Context("previous BOSH environment variables are set", func() {
 		BeforeEach(func() {
 			os.Setenv("BOSH_ALL_PROXY", "something")
@@ -8,9 +8,11 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 type SSH struct {
@@ -88,6 +90,53 @@ func (s *SSH) RunSSHCommand(command string, addresses SSHAddress, privateKey []b
 	return session.Run(command)
 }
 
+// Shell runs command with the local terminal attached, or opens a login
+// shell when command is empty.
+func (s *SSH) Shell(command []string, address SSHAddress, privateKey []byte, timeout time.Duration) error {
+	client, session, err := s.newSession(address, privateKey, timeout)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	defer session.Close()
+
+	session.Stdin = os.Stdin
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
+
+	if len(command) > 0 {
+		return session.Run(strings.Join(command, " "))
+	}
+	return LoginShell(session)
+}
+
+// LoginShell runs a login shell in session, putting the local terminal in
+// raw mode so that keys such as Ctrl-C go to the remote end rather than to
+// cf dev.
+func LoginShell(session *ssh.Session) error {
+	fd := int(os.Stdin.Fd())
+	if terminal.IsTerminal(fd) {
+		state, err := terminal.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer terminal.Restore(fd, state)
+
+		width, height, err := terminal.GetSize(int(os.Stdout.Fd()))
+		if err != nil {
+			width, height = 80, 24
+		}
+		if err := session.RequestPty("xterm", height, width, ssh.TerminalModes{ssh.ECHO: 1}); err != nil {
+			return err
+		}
+	}
+
+	if err := session.Shell(); err != nil {
+		return err
+	}
+	return session.Wait()
+}
+
 func (s *SSH) WaitForSSH(addresses SSHAddress, privateKey []byte, timeout time.Duration) error {
 	client, err := s.waitForSSH(addresses, privateKey, timeout)
 	if err == nil {